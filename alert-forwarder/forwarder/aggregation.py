@@ -0,0 +1,214 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import threading
+import time
+from dataclasses import dataclass
+from typing import Literal, cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .types import KoneyAlert
+from .utils import parse_duration
+
+# group, version, plural of the Koney DeceptionPolicy CRD
+KONEY_DECEPTION_POLICIES_GVP = "research.dynatrace.com", "v1alpha1", "deceptionpolicies"
+
+# the number of seconds for which the aggregation window of a policy is cached
+AGGREGATION_CACHE_SECONDS = 60
+
+AggregationDecision = Literal["forward", "absorb"]
+AggregationKey = tuple[str, str, str, str]
+
+logger = logging.getLogger("uvicorn.error")
+
+
+@dataclass
+class AlertGroup:
+    """Near-identical alerts that were seen within the aggregation window."""
+
+    window_seconds: float
+    opened_at: float  # when the first alert was seen, by the clock of the forwarder
+    first_alert: KoneyAlert
+    last_alert: KoneyAlert
+    hits: int = 1
+
+    def is_due(self, now: float) -> bool:
+        return now >= self.opened_at + self.window_seconds
+
+
+# open groups of alerts, per aggregation key
+_groups: dict[AggregationKey, AlertGroup] = {}
+# groups whose window ended while new alerts were seen, waiting to be summarized
+_due_groups: list[AlertGroup] = []
+# cached aggregation windows as (window in seconds, time of lookup), per policy
+_window_cache: dict[str, tuple[float | None, float]] = {}
+_lock = threading.Lock()
+
+# number of alerts that were absorbed into a group, and of summaries that were emitted
+aggregated_alerts = 0
+summarized_groups = 0
+
+
+def aggregate_alert(
+    koney_alert: KoneyAlert, now: float | None = None
+) -> AggregationDecision:
+    """
+    Groups an alert with near-identical alerts of the same policy, trap, pod, and
+    binary. Returns "forward" for the first alert of a group, and "absorb" for all
+    alerts after that, until the aggregation window of the policy ends.
+    """
+    global aggregated_alerts
+
+    deception_policy_name = koney_alert["deception_policy_name"]
+    if not deception_policy_name:
+        return "forward"  # cannot attribute the alert, do not aggregate it
+
+    window_seconds = _resolve_aggregation_window(deception_policy_name)
+    if not window_seconds:
+        return "forward"
+
+    now = time.time() if now is None else now
+    key = _aggregation_key(koney_alert)
+    with _lock:
+        group = _groups.get(key)
+        if group and not group.is_due(now):
+            group.hits += 1
+            group.last_alert = koney_alert
+            aggregated_alerts += 1
+            return "absorb"
+
+        if group and group.hits > 1:
+            _due_groups.append(group)
+        _groups[key] = AlertGroup(window_seconds, now, koney_alert, koney_alert)
+        return "forward"
+
+
+def flush_aggregated_alerts(
+    now: float | None = None, force: bool = False
+) -> list[KoneyAlert]:
+    """
+    Closes the groups whose aggregation window ended (or all groups, if forced), and
+    returns one summary alert for each group that absorbed alerts.
+    """
+    global summarized_groups
+
+    now = time.time() if now is None else now
+    with _lock:
+        due_groups = _due_groups[:]
+        _due_groups.clear()
+        for key, group in list(_groups.items()):
+            if force or group.is_due(now):
+                del _groups[key]
+                if group.hits > 1:
+                    due_groups.append(group)
+        summarized_groups += len(due_groups)
+
+    return [build_summary_alert(group) for group in due_groups]
+
+
+def build_summary_alert(group: AlertGroup) -> KoneyAlert:
+    """
+    Builds the alert that summarizes a group. It is derived from the last alert of
+    the group, and records how many alerts were seen, and when.
+    """
+    summary_alert = KoneyAlert(**group.last_alert)
+    summary_alert["metadata"] = dict(group.last_alert["metadata"])
+    summary_alert["metadata"]["aggregation"] = dict(
+        hits=group.hits,
+        first_seen=group.first_alert["timestamp"],
+        last_seen=group.last_alert["timestamp"],
+        window_seconds=group.window_seconds,
+    )
+    return summary_alert
+
+
+def format_aggregation_metrics() -> str:
+    """Returns the metrics of the aggregation in the Prometheus text format."""
+    with _lock:
+        open_groups = len(_groups)
+
+    metrics = [
+        (
+            "aggregated_total",
+            "counter",
+            "Alerts that were absorbed into a summary.",
+            aggregated_alerts,
+        ),
+        (
+            "summaries_total",
+            "counter",
+            "Summaries that were emitted.",
+            summarized_groups,
+        ),
+        ("open_groups", "gauge", "Groups within their window.", open_groups),
+    ]
+
+    lines = []
+    for name, metric_type, description, value in metrics:
+        lines.append(f"# HELP koney_alert_aggregation_{name} {description}")
+        lines.append(f"# TYPE koney_alert_aggregation_{name} {metric_type}")
+        lines.append(f"koney_alert_aggregation_{name} {value}")
+    return "\n".join(lines) + "\n"
+
+
+###############################################################################
+
+
+def _aggregation_key(koney_alert: KoneyAlert) -> AggregationKey:
+    trap = koney_alert.get("trap") or {}
+    pod = koney_alert.get("pod") or {}
+    process = koney_alert.get("process") or {}
+    return (
+        koney_alert["deception_policy_name"] or "",
+        trap.get("hash") or koney_alert["trap_type"],
+        f"{pod.get('namespace', '')}/{pod.get('name', '')}",
+        process.get("binary", ""),
+    )
+
+
+def _resolve_aggregation_window(deception_policy_name: str) -> float | None:
+    now = time.time()
+    with _lock:
+        if cached := _window_cache.get(deception_policy_name):
+            window_seconds, looked_up_at = cached
+            if now - looked_up_at < AGGREGATION_CACHE_SECONDS:
+                return window_seconds
+
+    window_seconds = None
+    try:
+        api = client.CustomObjectsApi()
+        deception_policy = cast(
+            dict,
+            api.get_cluster_custom_object(
+                *KONEY_DECEPTION_POLICIES_GVP, deception_policy_name
+            ),
+        )
+        window = deception_policy.get("spec", {}).get("alertAggregationWindow")
+        if window:
+            window_seconds = parse_duration(window)
+    except ApiException as e:
+        if e.status and e.status != 404:
+            logger.warning(
+                f"Failed to resolve aggregation window for {deception_policy_name}: {e}"
+            )
+    except ValueError as e:
+        logger.warning(f"Invalid aggregation window for {deception_policy_name}: {e}")
+
+    with _lock:
+        _window_cache[deception_policy_name] = (window_seconds, now)
+    return window_seconds
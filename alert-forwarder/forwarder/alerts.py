@@ -17,9 +17,21 @@ import json
 from hashlib import md5
 from pathlib import Path
 
+from rich.markup import escape
+
 from .types import DynatraceSeverity, KoneyAlert
 
+# log levels of the severities in Dynatrace logs
+DYNATRACE_SEVERITY_LOG_LEVELS = {
+    "info": "INFO",
+    "low": "WARN",
+    "medium": "WARN",
+    "high": "ERROR",
+    "critical": "ERROR",
+}
+
 DYNATRACE_SEVERITY_RISK_SCORES = {
+    "info": 0.0,
     "low": 3.9,
     "medium": 6.9,
     "high": 8.9,
@@ -34,6 +46,33 @@ def create_alert_id(koney_alert: KoneyAlert) -> str:
 
 
 def create_alert_description(koney_alert: KoneyAlert) -> str:
+    if (koney_alert.get("metadata") or {}).get("self_test_failed"):
+        deception_policy_name = koney_alert.get("deception_policy_name") or "?"
+        return (
+            f"Self-test of deception policy ({deception_policy_name}) failed, "
+            "accesses to its traps might go unnoticed"
+        )
+
+    tampering_reason = (koney_alert.get("metadata") or {}).get("decoy_tampered")
+    if tampering_reason:
+        file_path = koney_alert.get("metadata", {}).get("file_path", "?")
+        pod_dict = koney_alert.get("pod", {}) or {}
+        owner = pod_dict.get("owner") or {}
+        kind = owner.get("kind") or "pod"
+        name = owner.get("name") or pod_dict.get("name")
+        namespace = pod_dict.get("namespace")
+        namespaced_name = f"{namespace}/{name}" if namespace and name else "?"
+        return (
+            f"Honeytoken ({file_path}) in {kind.lower()} ({namespaced_name}) "
+            f"was tampered with ({tampering_reason})"
+        )
+
+    canary_token = (koney_alert.get("metadata") or {}).get("canary_token")
+    if canary_token:
+        file_path = koney_alert.get("metadata", {}).get("file_path", "?")
+        kind = (koney_alert.get("tags") or {}).get("koney.canarytoken") or "?"
+        return f"Canary token ({kind}) of honeytoken ({file_path}) triggered"
+
     if koney_alert["trap_type"] == "filesystem_honeytoken":
         file_path = koney_alert.get("metadata", {}).get("file_path", "?")
         namespace = (koney_alert.get("pod", {}) or {}).get("namespace")
@@ -44,10 +83,158 @@ def create_alert_description(koney_alert: KoneyAlert) -> str:
     return "Koney alert triggered"
 
 
+# colors of the severities in the console output
+CONSOLE_SEVERITY_STYLES = {
+    "info": "dim",
+    "low": "blue",
+    "medium": "yellow",
+    "high": "red",
+    "critical": "bold white on red",
+}
+
+
+def format_alert_summary(koney_alert: KoneyAlert) -> str:
+    """Formats an alert as a single, colorized line for humans, using rich console markup."""
+    pod_dict = koney_alert.get("pod", {}) or {}
+    process_dict = koney_alert.get("process", {}) or {}
+
+    severity = (koney_alert.get("severity") or "").upper() or "ALERT"
+    severity_style = CONSOLE_SEVERITY_STYLES.get(severity.lower(), "bold magenta")
+
+    namespace = pod_dict.get("namespace")
+    pod = pod_dict.get("name")
+    container = (pod_dict.get("container", {}) or {}).get("name")
+    namespaced_pod_name = f"{namespace}/{pod}" if namespace and pod else "?"
+    if container:
+        namespaced_pod_name += f" ({container})"
+
+    binary = process_dict.get("binary") or "?"
+    file_path = (koney_alert.get("metadata", {}) or {}).get("file_path") or "?"
+    policy = koney_alert.get("deception_policy_name") or "?"
+
+    return (
+        f"[dim]{escape(koney_alert['timestamp'])}[/dim] "
+        f"[{severity_style}]{escape(severity)}[/] "
+        f"[bold]{escape(policy)}[/bold] "
+        f"pod=[cyan]{escape(namespaced_pod_name)}[/cyan] "
+        f"binary=[green]{escape(binary)}[/green] "
+        f"path=[yellow]{escape(file_path)}[/yellow]"
+    )
+
+
+def create_chat_facts(koney_alert: KoneyAlert) -> list[tuple[str, str]]:
+    """Returns the facts of an alert for chat messages, as (title, value) pairs."""
+    pod_dict = koney_alert.get("pod", {}) or {}
+    process_dict = koney_alert.get("process", {}) or {}
+
+    namespace = pod_dict.get("namespace")
+    pod = pod_dict.get("name")
+    container = (pod_dict.get("container", {}) or {}).get("name")
+    namespaced_pod_name = f"{namespace}/{pod}" if namespace and pod else "?"
+    if container:
+        namespaced_pod_name += f" ({container})"
+
+    binary = process_dict.get("binary") or "?"
+    command_line = " ".join(filter(None, [binary, process_dict.get("arguments")]))
+
+    facts = [
+        ("Severity", (koney_alert.get("severity") or "").upper() or "-"),
+        ("Trap type", koney_alert["trap_type"]),
+        ("Deception policy", koney_alert.get("deception_policy_name") or "?"),
+        ("Pod", namespaced_pod_name),
+        ("Process", f"{binary} (pid {process_dict.get('pid', '?')})"),
+        ("Command line", command_line),
+    ]
+    if credentials := process_dict.get("credentials"):
+        facts.append(
+            ("User", f"uid {credentials.get('euid')} (gid {credentials.get('egid')})")
+        )
+    if capabilities := process_dict.get("capabilities"):
+        facts.append(("Capabilities", ", ".join(capabilities)))
+    if ancestors := process_dict.get("ancestors"):
+        parent_binary = ancestors[0].get("binary") or "?"
+        parent_pid = ancestors[0].get("pid", "?")
+        facts.append(("Parent process", f"{parent_binary} (pid {parent_pid})"))
+    if file_path := (koney_alert.get("metadata", {}) or {}).get("file_path"):
+        facts.append(("File path", file_path))
+    if cluster := koney_alert.get("cluster"):
+        facts.insert(0, ("Cluster", cluster["name"]))
+    return facts
+
+
+def map_to_slack_message(koney_alert: KoneyAlert, suppressed: int = 0) -> dict:
+    title = f":rotating_light: {create_alert_description(koney_alert)}"
+    fields = [
+        {"type": "mrkdwn", "text": f"*{name}*\n`{value}`"}
+        for name, value in create_chat_facts(koney_alert)
+    ]
+
+    blocks: list[dict] = [
+        {"type": "section", "text": {"type": "mrkdwn", "text": f"*{title}*"}},
+        # Slack shows at most 10 fields per section
+        {"type": "section", "fields": fields[:10]},
+        create_slack_context(koney_alert["timestamp"]),
+    ]
+    if suppressed:
+        blocks.append(create_slack_context(create_suppressed_note(suppressed)))
+
+    return {"text": title, "blocks": blocks}
+
+
+def create_slack_context(text: str) -> dict:
+    return {"type": "context", "elements": [{"type": "mrkdwn", "text": text}]}
+
+
+def map_to_teams_message(koney_alert: KoneyAlert, suppressed: int = 0) -> dict:
+    body: list[dict] = [
+        {
+            "type": "TextBlock",
+            "text": create_alert_description(koney_alert),
+            "weight": "Bolder",
+            "size": "Medium",
+            "wrap": True,
+        },
+        {
+            "type": "FactSet",
+            "facts": [
+                {"title": name, "value": value}
+                for name, value in create_chat_facts(koney_alert)
+            ],
+        },
+        create_teams_note(koney_alert["timestamp"]),
+    ]
+    if suppressed:
+        body.append(create_teams_note(create_suppressed_note(suppressed)))
+
+    return {
+        "type": "message",
+        "attachments": [
+            {
+                "contentType": "application/vnd.microsoft.card.adaptive",
+                "content": {
+                    "$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+                    "type": "AdaptiveCard",
+                    "version": "1.4",
+                    "body": body,
+                },
+            }
+        ],
+    }
+
+
+def create_teams_note(text: str) -> dict:
+    return {"type": "TextBlock", "text": text, "isSubtle": True, "wrap": True}
+
+
+def create_suppressed_note(suppressed: int) -> str:
+    return f"{suppressed} more alert(s) were not posted due to the rate limit"
+
+
 def map_to_dynatrace_event(
     koney_alert: KoneyAlert,
     severity: DynatraceSeverity,
     cluster_uid: str | None = None,
+    security_context: str | None = None,
 ) -> dict:
     # create ids and descriptions
     alert_id = create_alert_id(koney_alert)
@@ -57,6 +244,8 @@ def map_to_dynatrace_event(
     pod_dict = koney_alert.get("pod", {}) or {}
     node_dict = koney_alert.get("node", {}) or {}
     process_dict = koney_alert.get("process", {}) or {}
+    trap_dict = koney_alert.get("trap", {}) or {}
+    response_dict = koney_alert.get("response", {}) or {}
 
     # split process binary into name and path (with pathlib)
     process_binary = Path(process_dict.get("binary", ""))
@@ -71,7 +260,21 @@ def map_to_dynatrace_event(
         # koney metadata (flattened)
         "koney.deception_policy_name": koney_alert["deception_policy_name"],
         "koney.trap_type": koney_alert["trap_type"],
+        "koney.confidence": koney_alert.get("confidence"),
         "koney.metadata.file_path": koney_alert.get("metadata", {}).get("file_path"),
+        **{
+            f"koney.tags.{key}": value
+            for key, value in (koney_alert.get("tags") or {}).items()
+        },
+        "koney.trap.hash": trap_dict.get("hash"),
+        "koney.trap.description": trap_dict.get("description"),
+        "koney.trap.attck_techniques": trap_dict.get("attck_techniques"),
+        "koney.trap.engage_activities": trap_dict.get("engage_activities"),
+        "koney.trap.deception_policy_generation": trap_dict.get(
+            "deception_policy_generation"
+        ),
+        "koney.response.actions": response_dict.get("actions"),
+        "koney.response.quarantine_ttl": response_dict.get("quarantine_ttl"),
         # event metadata
         "event.kind": "SECURITY_EVENT",
         "event.type": "DETECTION_FINDING",
@@ -112,4 +315,70 @@ def map_to_dynatrace_event(
         "object.id": pod_dict["container"].get("id"),
     }
 
+    if security_context:
+        payload["dt.security_context"] = security_context
+    # alerts that other clusters posted to this forwarder are attributed to them
+    if cluster := koney_alert.get("cluster"):
+        payload["k8s.cluster.name"] = cluster["name"]
+
+    return payload
+
+
+def map_to_dynatrace_log(
+    koney_alert: KoneyAlert,
+    severity: DynatraceSeverity,
+    cluster_uid: str | None = None,
+    security_context: str | None = None,
+) -> dict:
+    # log records carry the same fields as security events, and are linked
+    # to the pod and namespace by Dynatrace, based on the k8s.* attributes
+    attributes = map_to_dynatrace_event(
+        koney_alert, severity, cluster_uid, security_context
+    )
+    return {
+        **{key: value for key, value in attributes.items() if value is not None},
+        "content": create_alert_description(koney_alert),
+        "loglevel": DYNATRACE_SEVERITY_LOG_LEVELS.get(severity.lower(), "WARN"),
+        "log.source": "koney",
+    }
+
+
+def map_to_dynatrace_v2_event(
+    koney_alert: KoneyAlert,
+    severity: DynatraceSeverity,
+    cluster_uid: str | None = None,
+    security_context: str | None = None,
+) -> dict:
+    attributes = map_to_dynatrace_event(
+        koney_alert, severity, cluster_uid, security_context
+    )
+
+    # the Events API v2 only accepts string properties
+    properties = {
+        key: value if isinstance(value, str) else json.dumps(value)
+        for key, value in attributes.items()
+        if value is not None and key != "timestamp"
+    }
+
+    payload = {
+        "eventType": "CUSTOM_ALERT",
+        "title": create_alert_description(koney_alert),
+        "properties": properties,
+    }
+    if entity_selector := create_pod_entity_selector(koney_alert):
+        payload["entitySelector"] = entity_selector
+
     return payload
+
+
+def create_pod_entity_selector(koney_alert: KoneyAlert) -> str | None:
+    # link the event to the pod that accessed the trap, if known
+    pod_dict = koney_alert.get("pod", {}) or {}
+    pod, namespace = pod_dict.get("name"), pod_dict.get("namespace")
+    if not pod or not namespace:
+        return None
+    return (
+        f'type(CLOUD_APPLICATION_INSTANCE),entityName.equals("{pod}"),'
+        "toRelationships.isNamespaceOfCai("
+        f'type(CLOUD_APPLICATION_NAMESPACE),entityName.equals("{namespace}"))'
+    )
@@ -0,0 +1,64 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import hashlib
+import hmac
+import os
+
+from fastapi import Request
+
+# the token that captors and other clients authenticate with, empty to allow all requests
+WEBHOOK_TOKEN = os.environ.get("KONEY_ALERT_FORWARDER_TOKEN", "")
+# the header with the HMAC-SHA256 signature of the request body, keyed with the token
+SIGNATURE_HEADER = "X-Koney-Signature"
+
+# various error messages
+WEBHOOK_AUTH_ERROR = "missing or invalid token"
+
+
+def is_authorized_request(request: Request, body: bytes = b"") -> bool:
+    """
+    Checks that a request carries the token, either as the token query parameter
+    (which captors use, since they cannot set headers), as a bearer token, or as the
+    HMAC-SHA256 signature of the body (e.g., "sha256=<hex digest>").
+    """
+    if not WEBHOOK_TOKEN:
+        return True
+
+    if _matches_token(request.query_params.get("token")):
+        return True
+
+    authorization = request.headers.get("Authorization") or ""
+    scheme, _, credentials = authorization.partition(" ")
+    if scheme.lower() == "bearer" and _matches_token(credentials.strip()):
+        return True
+
+    return _matches(request.headers.get(SIGNATURE_HEADER), sign_body(body))
+
+
+def sign_body(body: bytes) -> str:
+    digest = hmac.new(WEBHOOK_TOKEN.encode("utf-8"), body, hashlib.sha256).hexdigest()
+    return f"sha256={digest}"
+
+
+def _matches_token(token: str | None) -> bool:
+    return _matches(token, WEBHOOK_TOKEN)
+
+
+def _matches(value: str | None, expected: str) -> bool:
+    # compare in constant time, so that the token cannot be guessed byte by byte
+    return bool(value) and hmac.compare_digest(
+        value.encode("utf-8"), expected.encode("utf-8")
+    )
@@ -0,0 +1,187 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import base64
+import logging
+from datetime import datetime, timezone
+from typing import cast
+from urllib.parse import parse_qs, urlparse
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .sink import KONEY_NAMESPACE
+from .types import *
+from .utils import build_trap_metadata
+
+# group, version, plural of the Koney DeceptionPolicy CRD
+KONEY_DECEPTION_POLICIES_GVP = "research.dynatrace.com", "v1alpha1", "deceptionpolicies"
+
+# the label of the secrets that store the provisioned canary tokens, and the label
+# that refers to their deception policy (see LabelKeyCanaryToken in constants.go)
+CANARY_TOKEN_LABEL = "koney/canary-token"
+DECEPTION_POLICY_LABEL = "koney/deception-policy"
+
+# the tag that tells receivers which kind of canary token triggered, e.g., "awsKeys"
+CANARY_TOKEN_TAG = "koney.canarytoken"
+
+# the metadata key that stores the canary token that triggered
+CANARY_TOKEN_METADATA = "canary_token"
+
+# the time format of the webhooks of canarytokens services,
+# e.g., "2025-01-02 03:04:05 (UTC)"
+CANARY_TOKEN_TIME_FORMAT = "%Y-%m-%d %H:%M:%S (UTC)"
+
+logger = logging.getLogger("uvicorn.error")
+
+
+def extract_canary_token(canary_alert: dict) -> str | None:
+    """Returns the token that triggered, which canarytokens services post either as
+    a field of their webhooks or as a parameter of the management URL."""
+    if canary_alert.get("token"):
+        return canary_alert["token"]
+    manage_url = canary_alert.get("manage_url") or ""
+    return next(iter(parse_qs(urlparse(manage_url).query).get("token", [])), None)
+
+
+def find_canary_token(token: str) -> tuple[dict, dict] | None:
+    """Returns the deception policy that provisioned the canary token, and the canary
+    token with its file path and kind, or None if no deception policy provisioned the
+    token. The canary tokens are stored in secrets in the Koney namespace."""
+    secrets = client.CoreV1Api().list_namespaced_secret(
+        KONEY_NAMESPACE, label_selector=f"{CANARY_TOKEN_LABEL}=true"
+    )
+    for secret in secrets.items:
+        data = {key: _decode(value) for key, value in (secret.data or {}).items()}
+        policy_name = (secret.metadata.labels or {}).get(DECEPTION_POLICY_LABEL)
+        if data.get("token") != token or not policy_name:
+            continue
+
+        try:
+            deception_policy = cast(
+                dict,
+                client.CustomObjectsApi().get_cluster_custom_object(
+                    *KONEY_DECEPTION_POLICIES_GVP, policy_name
+                ),
+            )
+        except ApiException as e:
+            if e.status == 404:
+                return None
+            raise
+
+        canary_token = {
+            "filePath": data.get("filePath", ""),
+            "kind": data.get("kind", ""),
+            "token": token,
+        }
+        return deception_policy, canary_token
+    return None
+
+
+def _decode(value: str | None) -> str:
+    try:
+        return base64.b64decode(value or "").decode("utf-8")
+    except ValueError:
+        return ""
+
+
+def process_canary_token_alert(
+    canary_alert: dict, deception_policy: dict, canary_token: dict
+) -> KoneyAlert:
+    """Maps the webhook of a canarytokens service to an alert of the honeytoken that
+    embeds the canary token. The pod is only known if the honeytoken was placed into
+    exactly one pod, otherwise the placements of the honeytoken are listed instead."""
+    file_path = canary_token["filePath"]
+    trap = _find_trap(deception_policy, file_path)
+    placements = _find_placements(trap, file_path) if trap else []
+
+    metadata = {
+        "file_path": file_path,
+        CANARY_TOKEN_METADATA: canary_token["token"],
+        "channel": canary_alert.get("channel"),
+        "src_ip": canary_alert.get("src_ip"),
+    }
+    if canary_alert.get("additional_data"):
+        metadata["additional_data"] = canary_alert["additional_data"]
+
+    pod, node = None, None
+    if len(placements) == 1:
+        namespace, pod_name, node_name = placements[0]
+        pod = PodMetadata(
+            name=pod_name,
+            namespace=namespace,
+            container=ContainerMetadata(id=None, name=None),
+        )
+        node = NodeMetadata(name=node_name) if node_name else None
+    elif placements:
+        metadata["placements"] = [f"{p[0]}/{p[1]}" for p in placements]
+
+    generation = (deception_policy.get("metadata") or {}).get("generation")
+    koneyAlert = KoneyAlert(
+        schema_version=SCHEMA_VERSION,
+        timestamp=_parse_time(canary_alert.get("time")),
+        deception_policy_name=(deception_policy.get("metadata") or {}).get("name"),
+        trap_type="filesystem_honeytoken",
+        severity=None,
+        # the canary token left the cluster and was used, which is no false positive
+        confidence="high",
+        tags={CANARY_TOKEN_TAG: canary_token.get("kind", "")},
+        trap=(
+            build_trap_metadata(trap.get("trapHash"), str(generation or ""), None)
+            if trap
+            else None
+        ),
+        metadata=metadata,
+        pod=pod,
+        node=node,
+        process=None,
+        response=None,
+    )
+    return koneyAlert
+
+
+def _find_trap(deception_policy: dict, file_path: str) -> dict | None:
+    for trap in (deception_policy.get("status") or {}).get("traps") or []:
+        if trap.get("trapType") != "FilesystemHoneytoken":
+            continue
+        for placement in trap.get("placements") or []:
+            if file_path in (placement.get("filePaths") or []):
+                return trap
+    return None
+
+
+def _find_placements(trap: dict, file_path: str) -> list[tuple[str, str, str | None]]:
+    # the token placements know the pods, even if the honeytoken was placed into
+    # the pod templates of deployments
+    token_placements = [
+        (p.get("namespace"), p.get("pod"), p.get("node"))
+        for p in trap.get("tokenPlacements") or []
+        if p.get("filePath") == file_path
+    ]
+    if token_placements:
+        return token_placements
+    return [
+        (p.get("namespace"), p.get("name"), None)
+        for p in trap.get("placements") or []
+        if p.get("kind") == "Pod" and file_path in (p.get("filePaths") or [])
+    ]
+
+
+def _parse_time(time: str | None) -> str:
+    try:
+        parsed = datetime.strptime(time or "", CANARY_TOKEN_TIME_FORMAT)
+        return parsed.replace(tzinfo=timezone.utc).isoformat()
+    except ValueError:
+        return datetime.now(timezone.utc).isoformat()
@@ -0,0 +1,127 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import hmac
+import json
+import logging
+import os
+
+from fastapi import Request
+
+from .alerts import create_alert_id
+from .dedup import EventCache
+from .types import KoneyAlert
+
+# how long alerts of other clusters are remembered, so that alerts which their forwarders
+# post again (e.g., after a timeout) are only forwarded once, in seconds
+CLUSTER_DEDUP_TTL_SECONDS = float(
+    os.environ.get("KONEY_CLUSTER_DEDUP_TTL_SECONDS", "3600")
+)
+# how many alerts of other clusters are remembered at most
+CLUSTER_DEDUP_MAX_SIZE = 10000
+
+# various error messages
+CLUSTER_AUTH_ERROR = "missing or invalid cluster token"
+CLUSTER_ALERT_ERROR = "invalid alerts"
+
+logger = logging.getLogger("uvicorn.error")
+
+
+def parse_cluster_tokens(value: str) -> dict[str, str]:
+    """
+    Parses the tokens that clusters authenticate with, as comma-separated
+    "<cluster name>=<token>" pairs, and returns the cluster names by their tokens.
+    """
+    cluster_names = {}
+    for pair in value.split(","):
+        name, _, token = pair.partition("=")
+        name, token = name.strip(), token.strip()
+        if not name or not token:
+            if pair.strip():
+                logger.warning("Ignoring cluster token without a cluster name or token")
+            continue
+        cluster_names[token] = name
+    return cluster_names
+
+
+# the tokens of the clusters whose alert forwarders post their alerts to this one,
+# empty to not aggregate the alerts of other clusters
+CLUSTER_TOKENS = parse_cluster_tokens(os.environ.get("KONEY_CLUSTER_TOKENS", ""))
+
+# the ids of the alerts of other clusters that were forwarded recently
+cluster_alert_cache = EventCache(CLUSTER_DEDUP_MAX_SIZE, CLUSTER_DEDUP_TTL_SECONDS)
+
+
+def is_cluster_aggregation_enabled() -> bool:
+    return bool(CLUSTER_TOKENS)
+
+
+def authenticate_cluster(request: Request) -> str | None:
+    """
+    Returns the name of the cluster whose bearer token the request carries, or None.
+    Clusters are only identified by their tokens, so that they cannot post alerts
+    in the name of other clusters.
+    """
+    authorization = request.headers.get("Authorization") or ""
+    scheme, _, credentials = authorization.partition(" ")
+    if scheme.lower() != "bearer" or not (credentials := credentials.strip()):
+        return None
+
+    # compare with all tokens in constant time, so that tokens cannot be guessed
+    cluster_name = None
+    for token, name in CLUSTER_TOKENS.items():
+        if hmac.compare_digest(credentials.encode("utf-8"), token.encode("utf-8")):
+            cluster_name = name
+    return cluster_name
+
+
+def parse_cluster_alerts(body: bytes) -> list[KoneyAlert] | None:
+    """Parses an alert or an array of alerts, as posted by webhook sinks. Returns None if invalid."""
+    try:
+        payload = json.loads(body)
+    except (json.JSONDecodeError, UnicodeDecodeError):
+        return None
+
+    alerts = payload if isinstance(payload, list) else [payload]
+    if not all(_is_alert(alert) for alert in alerts):
+        return None
+    return alerts
+
+
+def attribute_alert(koney_alert: KoneyAlert, cluster_name: str) -> None:
+    """Records the cluster in the alert, replacing the cluster that the alert might claim."""
+    koney_alert["cluster"] = {"name": cluster_name}
+
+
+def is_duplicate_cluster_alert(koney_alert: KoneyAlert) -> bool:
+    """Returns whether the alert of a cluster was forwarded recently, and remembers it otherwise."""
+    alert_id = create_alert_id(koney_alert)
+    if alert_id in cluster_alert_cache:
+        return True
+    cluster_alert_cache.add(alert_id)
+    return False
+
+
+def forget_cluster_alert(koney_alert: KoneyAlert) -> None:
+    """Forgets an alert that could not be forwarded, so that it is forwarded when it is posted again."""
+    cluster_alert_cache.difference_update({create_alert_id(koney_alert)})
+
+
+def _is_alert(alert: object) -> bool:
+    return (
+        isinstance(alert, dict)
+        and isinstance(alert.get("timestamp"), str)
+        and isinstance(alert.get("trap_type"), str)
+    )
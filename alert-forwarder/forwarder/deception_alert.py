@@ -0,0 +1,133 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import os
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .alerts import create_alert_id
+from .siem import parse_timestamp
+from .sink import KONEY_NAMESPACE
+from .types import KoneyAlert
+
+# Errors
+DECEPTION_ALERT_CREATE_ERROR = "failed to record alert as DeceptionAlert"
+
+# group, version, namespace, plural of the Koney DeceptionAlert CRD
+KONEY_DECEPTION_ALERT_GVNP = (
+    "research.dynatrace.com",
+    "v1alpha1",
+    KONEY_NAMESPACE,
+    "deceptionalerts",
+)
+
+# the labels of DeceptionAlerts, so that other controllers can select them
+DECEPTION_ALERT_DECEPTION_POLICY_LABEL = "koney/deception-policy"
+DECEPTION_ALERT_SEVERITY_LABEL = "koney/severity"
+DECEPTION_ALERT_POD_NAMESPACE_LABEL = "koney/pod-namespace"
+
+# the maximum length of label values in Kubernetes
+LABEL_VALUE_MAX_LENGTH = 63
+
+logger = logging.getLogger("uvicorn.error")
+
+
+def is_deception_alerts_enabled() -> bool:
+    """Returns whether alerts are recorded as DeceptionAlerts in the cluster,
+    which is enabled with KONEY_DECEPTION_ALERTS=true."""
+    return os.environ.get("KONEY_DECEPTION_ALERTS", "false").lower() == "true"
+
+
+def build_deception_alert(koney_alert: KoneyAlert) -> dict:
+    """Builds the DeceptionAlert that records an alert.
+    Its name is derived from the alert, so that retried alerts are recorded only once."""
+    pod_dict = koney_alert.get("pod") or {}
+    process_dict = koney_alert.get("process") or {}
+    trap_dict = koney_alert.get("trap") or {}
+    timestamp = parse_timestamp(koney_alert["timestamp"])
+
+    spec = {
+        "timestamp": timestamp.strftime("%Y-%m-%dT%H:%M:%SZ"),
+        "trapType": koney_alert["trap_type"],
+        "alert": koney_alert,
+    }
+    if deception_policy_name := koney_alert.get("deception_policy_name"):
+        spec["deceptionPolicyName"] = deception_policy_name
+    if trap_hash := trap_dict.get("hash"):
+        spec["trapHash"] = trap_hash
+    if severity := koney_alert.get("severity"):
+        spec["severity"] = severity
+    if pod_dict.get("name") and pod_dict.get("namespace"):
+        spec["pod"] = {"name": pod_dict["name"], "namespace": pod_dict["namespace"]}
+        if container_name := (pod_dict.get("container") or {}).get("name"):
+            spec["pod"]["containerName"] = container_name
+    if node_name := (koney_alert.get("node") or {}).get("name"):
+        spec["nodeName"] = node_name
+    if process_dict:
+        process = {
+            "binary": process_dict.get("binary"),
+            "arguments": process_dict.get("arguments"),
+            "pid": process_dict.get("pid"),
+            "uid": process_dict.get("uid"),
+        }
+        spec["process"] = {
+            key: value for key, value in process.items() if value not in (None, "")
+        }
+
+    labels = {
+        DECEPTION_ALERT_DECEPTION_POLICY_LABEL: deception_policy_name,
+        DECEPTION_ALERT_SEVERITY_LABEL: (koney_alert.get("severity") or "").lower(),
+        DECEPTION_ALERT_POD_NAMESPACE_LABEL: pod_dict.get("namespace"),
+    }
+
+    return {
+        "apiVersion": "research.dynatrace.com/v1alpha1",
+        "kind": "DeceptionAlert",
+        "metadata": {
+            "name": f"alert-{create_alert_id(koney_alert).lower()}",
+            "namespace": KONEY_NAMESPACE,
+            # values that are too long for labels can still be found in the spec
+            "labels": {
+                key: value
+                for key, value in labels.items()
+                if value and len(value) <= LABEL_VALUE_MAX_LENGTH
+            },
+        },
+        "spec": spec,
+    }
+
+
+def record_alert(koney_alert: KoneyAlert) -> None:
+    if not is_deception_alerts_enabled():
+        return
+
+    api = client.CustomObjectsApi()
+    try:
+        api.create_namespaced_custom_object(
+            *KONEY_DECEPTION_ALERT_GVNP, build_deception_alert(koney_alert)
+        )
+    except ApiException as e:
+        # the alert was already recorded, e.g., because it was retried
+        if e.status != 409:
+            raise
+
+
+def try_record_alert(koney_alert: KoneyAlert) -> None:
+    try:
+        record_alert(koney_alert)
+    except:
+        logger.exception(DECEPTION_ALERT_CREATE_ERROR)
@@ -0,0 +1,303 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import os
+import threading
+import time
+from collections import OrderedDict
+from typing import cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .siem import parse_timestamp
+from .types import KoneyAlert
+
+# the namespace where Koney is running
+KONEY_NAMESPACE = os.environ.get("KONEY_NAMESPACE", "koney-system")
+# how many ids of processed events are remembered at most, evicting the least recently seen
+EVENT_CACHE_MAX_SIZE = int(os.environ.get("KONEY_EVENT_CACHE_MAX_SIZE", "10000"))
+# how long the ids of processed events are remembered, in seconds
+EVENT_CACHE_TTL_SECONDS = int(os.environ.get("KONEY_EVENT_CACHE_TTL_SECONDS", "3600"))
+# where the cache is persisted across restarts, either "none", "configmap", or "file"
+EVENT_CACHE_PERSISTENCE = os.environ.get(
+    "KONEY_EVENT_CACHE_PERSISTENCE", "none"
+).lower()
+# the file that persists the cache, if the persistence is "file"
+EVENT_CACHE_FILE = os.environ.get(
+    "KONEY_EVENT_CACHE_FILE", "/var/lib/koney/event-cache.json"
+)
+# the config map that persists the cache, if the persistence is "configmap"
+EVENT_CACHE_CONFIGMAP_NAME = "koney-alert-forwarder-event-cache"
+# the key in the config map that stores the cache as JSON
+EVENT_CACHE_CONFIGMAP_KEY = "events.json"
+# how long an access is attributed to the captor that reported it first, in seconds,
+# so that traps with several captors only alert once per access (0 disables it)
+CAPTOR_DEDUP_WINDOW_SECONDS = float(
+    os.environ.get("KONEY_CAPTOR_DEDUP_WINDOW_SECONDS", "10")
+)
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class EventCache:
+    """A bounded cache of the ids of processed events, to not process events twice.
+
+    Ids expire after the TTL, and the least recently seen ids are evicted when the
+    cache is full. Lookups are counted as hits and misses.
+    """
+
+    def __init__(self, max_size: int, ttl_seconds: float):
+        self.max_size = max_size
+        self.ttl_seconds = ttl_seconds
+        self.hits = 0
+        self.misses = 0
+        self.evictions = 0
+        # the time each id was last seen, ordered from the least to the most recently seen
+        self._entries: OrderedDict[str, float] = OrderedDict()
+        self._changed = False
+        self._lock = threading.Lock()
+
+    def __contains__(self, event_id: object) -> bool:
+        now = time.time()
+        with self._lock:
+            self._expire(now)
+            if event_id not in self._entries:
+                self.misses += 1
+                return False
+
+            self.hits += 1
+            self._entries[cast(str, event_id)] = now
+            self._entries.move_to_end(cast(str, event_id))
+            return True
+
+    def __len__(self) -> int:
+        with self._lock:
+            return len(self._entries)
+
+    def add(self, event_id: str) -> None:
+        now = time.time()
+        with self._lock:
+            self._entries[event_id] = now
+            self._entries.move_to_end(event_id)
+            self._changed = True
+            self._expire(now)
+            while len(self._entries) > self.max_size:
+                self._entries.popitem(last=False)
+                self.evictions += 1
+
+    def difference_update(self, event_ids: set[str]) -> None:
+        with self._lock:
+            for event_id in event_ids:
+                if self._entries.pop(event_id, None) is not None:
+                    self._changed = True
+
+    def clear(self) -> None:
+        with self._lock:
+            self._entries.clear()
+            self._changed = True
+
+    def dump(self) -> dict[str, float] | None:
+        """Returns the ids and when they were last seen, or None if nothing changed."""
+        with self._lock:
+            if not self._changed:
+                return None
+            self._changed = False
+            return dict(self._entries)
+
+    def mark_changed(self) -> None:
+        with self._lock:
+            self._changed = True
+
+    def restore(self, entries: dict[str, float]) -> None:
+        """Adds ids with the times they were last seen, e.g., after a restart."""
+        with self._lock:
+            for event_id, seen in sorted(entries.items(), key=lambda entry: entry[1]):
+                self._entries[event_id] = max(seen, self._entries.get(event_id, 0))
+                self._entries.move_to_end(event_id)
+            self._expire(time.time())
+            while len(self._entries) > self.max_size:
+                self._entries.popitem(last=False)
+
+    def _expire(self, now: float) -> None:
+        while self._entries:
+            event_id, seen = next(iter(self._entries.items()))
+            if seen > now - self.ttl_seconds:
+                break
+            del self._entries[event_id]
+
+
+# the captor that last reported an access and when, per policy, pod, and file path
+_accesses: dict[tuple, tuple[str, float]] = {}
+_accesses_lock = threading.Lock()
+
+
+def is_duplicate_access(koney_alert: KoneyAlert, captor: str) -> bool:
+    """
+    Returns True if another captor reported the same access to a trap shortly before,
+    e.g., if a trap is monitored by both Tetragon and Kive. Accesses are the same if
+    they hit the same file in the same pod within the dedup window, since the captors
+    do not agree on the ids of processes. Alerts of the same captor are never
+    duplicates, so that traps with a single captor alert like before.
+    """
+    pod = koney_alert.get("pod") or {}
+    key = (
+        koney_alert["deception_policy_name"],
+        pod.get("namespace"),
+        pod.get("name"),
+        (koney_alert.get("metadata") or {}).get("file_path"),
+    )
+    if CAPTOR_DEDUP_WINDOW_SECONDS <= 0 or not all(key):
+        return False
+
+    # the captors report with different delays, so the time of the access counts
+    try:
+        seen = parse_timestamp(koney_alert["timestamp"]).timestamp()
+    except (TypeError, ValueError):
+        seen = time.time()
+
+    with _accesses_lock:
+        for other_key, (_, other_seen) in list(_accesses.items()):
+            if other_seen < seen - 2 * CAPTOR_DEDUP_WINDOW_SECONDS:
+                del _accesses[other_key]
+
+        if last := _accesses.get(key):
+            last_captor, last_seen = last
+            if (
+                last_captor != captor
+                and abs(seen - last_seen) <= CAPTOR_DEDUP_WINDOW_SECONDS
+            ):
+                return True
+
+        _accesses[key] = (captor, seen)
+        return False
+
+
+def load_event_cache(event_cache: EventCache) -> None:
+    """Restores the ids of processed events, if the cache is persisted."""
+    if EVENT_CACHE_PERSISTENCE == "file":
+        entries = _read_file()
+    elif EVENT_CACHE_PERSISTENCE == "configmap":
+        entries = _read_config_map()
+    else:
+        return
+
+    event_cache.restore(entries)
+
+
+def save_event_cache(event_cache: EventCache) -> bool:
+    """Persists the ids of processed events, if they changed. Returns true on success."""
+    if EVENT_CACHE_PERSISTENCE not in ("file", "configmap"):
+        return True
+    if (entries := event_cache.dump()) is None:
+        return True
+
+    data = json.dumps(entries, sort_keys=True)
+    if EVENT_CACHE_PERSISTENCE == "file":
+        saved = _write_file(data)
+    else:
+        saved = _write_config_map(data)
+
+    if not saved:
+        event_cache.mark_changed()  # try again next time
+    return saved
+
+
+def format_event_cache_metrics(cache: EventCache) -> str:
+    """Returns the metrics of the cache in the Prometheus text format."""
+    metrics = [
+        ("hits_total", "counter", "Events that were processed before.", cache.hits),
+        ("misses_total", "counter", "Events that were new.", cache.misses),
+        ("evictions_total", "counter", "Ids evicted from the cache.", cache.evictions),
+        ("size", "gauge", "Ids of processed events in the cache.", len(cache)),
+    ]
+
+    lines = []
+    for name, metric_type, description, value in metrics:
+        lines.append(f"# HELP koney_event_cache_{name} {description}")
+        lines.append(f"# TYPE koney_event_cache_{name} {metric_type}")
+        lines.append(f"koney_event_cache_{name} {value}")
+    return "\n".join(lines) + "\n"
+
+
+def _read_file() -> dict[str, float]:
+    try:
+        with open(EVENT_CACHE_FILE, encoding="utf-8") as f:
+            return json.load(f)
+    except FileNotFoundError:
+        return {}  # nothing was processed yet
+    except (OSError, json.JSONDecodeError) as e:
+        logger.warning(f"Failed to load event cache: {e}")
+        return {}
+
+
+def _write_file(data: str) -> bool:
+    try:
+        # write atomically, so that the cache is not corrupted by a crash
+        tmp_file = f"{EVENT_CACHE_FILE}.tmp"
+        with open(tmp_file, "w", encoding="utf-8") as f:
+            f.write(data)
+        os.replace(tmp_file, EVENT_CACHE_FILE)
+    except OSError as e:
+        logger.warning(f"Failed to save event cache: {e}")
+        return False
+
+    return True
+
+
+def _read_config_map() -> dict[str, float]:
+    api = client.CoreV1Api()
+    try:
+        config_map = cast(
+            client.V1ConfigMap,
+            api.read_namespaced_config_map(EVENT_CACHE_CONFIGMAP_NAME, KONEY_NAMESPACE),
+        )
+    except ApiException as e:
+        if e.status and e.status == 404:
+            return {}  # nothing was processed yet
+        logger.warning(f"Failed to load event cache: {e}")
+        return {}
+
+    try:
+        return json.loads((config_map.data or {}).get(EVENT_CACHE_CONFIGMAP_KEY, "{}"))
+    except json.JSONDecodeError:
+        return {}
+
+
+def _write_config_map(data: str) -> bool:
+    api = client.CoreV1Api()
+    config_map = client.V1ConfigMap(
+        metadata=client.V1ObjectMeta(
+            name=EVENT_CACHE_CONFIGMAP_NAME, namespace=KONEY_NAMESPACE
+        ),
+        data={EVENT_CACHE_CONFIGMAP_KEY: data},
+    )
+
+    try:
+        try:
+            api.replace_namespaced_config_map(
+                EVENT_CACHE_CONFIGMAP_NAME, KONEY_NAMESPACE, config_map
+            )
+        except ApiException as e:
+            if not e.status or e.status != 404:
+                raise
+            api.create_namespaced_config_map(KONEY_NAMESPACE, config_map)
+    except ApiException as e:
+        logger.warning(f"Failed to save event cache: {e}")
+        return False
+
+    return True
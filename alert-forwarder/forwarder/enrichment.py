@@ -0,0 +1,250 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import os
+import threading
+import time
+from collections import OrderedDict
+from collections.abc import Callable
+from functools import cached_property
+from typing import Any
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .types import CloudMetadata, KoneyAlert, NodeMetadata, OwnerMetadata
+
+# the enrichers that run on every alert before it is delivered, in this order
+DEFAULT_ENRICHERS = "owner,labels,image,node,cloud"
+ENABLED_ENRICHERS = os.environ.get("KONEY_ALERT_ENRICHERS", DEFAULT_ENRICHERS)
+
+# how long pods, their owners, and nodes are cached, so that floods of alerts cause few API calls
+ENRICHMENT_CACHE_SECONDS = 60
+# how many pods, owners, and nodes are cached at most, evicting the least recently used
+ENRICHMENT_CACHE_MAX_SIZE = 1024
+
+# annotations that are not attached to alerts, since they are large and say nothing about the pod
+EXCLUDED_ANNOTATIONS = {"kubectl.kubernetes.io/last-applied-configuration"}
+
+# well-known labels of nodes that cloud providers set
+NODE_REGION_LABEL = "topology.kubernetes.io/region"
+NODE_ZONE_LABEL = "topology.kubernetes.io/zone"
+NODE_INSTANCE_TYPE_LABEL = "node.kubernetes.io/instance-type"
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class EnrichmentContext:
+    """The Kubernetes objects of an alert, which are shared by all enrichers."""
+
+    def __init__(self, koney_alert: KoneyAlert):
+        self.koney_alert = koney_alert
+
+    @cached_property
+    def pod(self) -> Any | None:
+        pod = self.koney_alert.get("pod")
+        if not pod or not pod.get("name") or not pod.get("namespace"):
+            return None
+        name, namespace = pod["name"], pod["namespace"]
+        return _cached(
+            ("Pod", namespace, name),
+            lambda: client.CoreV1Api().read_namespaced_pod(name, namespace),
+        )
+
+    @cached_property
+    def node(self) -> Any | None:
+        node_name = (self.koney_alert.get("node") or {}).get("name")
+        if not node_name and self.pod and self.pod.spec:
+            node_name = self.pod.spec.node_name
+        if not node_name:
+            return None
+        return _cached(
+            ("Node", "", node_name), lambda: client.CoreV1Api().read_node(node_name)
+        )
+
+
+# an enricher attaches metadata to an alert in place, and may raise if it cannot
+Enricher = Callable[[KoneyAlert, EnrichmentContext], None]
+
+
+def enrich_owner(koney_alert: KoneyAlert, context: EnrichmentContext) -> None:
+    """Attaches the top-level controller of the pod, e.g., its Deployment."""
+    if not context.pod or not (owner := _controller_of(context.pod)):
+        return
+
+    # follow ReplicaSets to Deployments, and Jobs to CronJobs
+    namespace = context.pod.metadata.namespace
+    while owner.kind in ("ReplicaSet", "Job"):
+        obj = _cached(
+            (owner.kind, namespace, owner.name),
+            lambda: _read_owner(owner.kind, namespace, owner.name),
+        )
+        if not obj or not (parent := _controller_of(obj)):
+            break
+        owner = parent
+
+    koney_alert["pod"]["owner"] = OwnerMetadata(kind=owner.kind, name=owner.name)
+
+
+def enrich_labels(koney_alert: KoneyAlert, context: EnrichmentContext) -> None:
+    """Attaches the labels and annotations of the pod."""
+    if not context.pod:
+        return
+
+    annotations = context.pod.metadata.annotations or {}
+    koney_alert["pod"]["labels"] = dict(context.pod.metadata.labels or {})
+    koney_alert["pod"]["annotations"] = {
+        key: value
+        for key, value in annotations.items()
+        if key not in EXCLUDED_ANNOTATIONS
+    }
+
+
+def enrich_image(koney_alert: KoneyAlert, context: EnrichmentContext) -> None:
+    """Attaches the image of the container and the digest that it runs."""
+    if not context.pod or not context.pod.status:
+        return
+
+    container = koney_alert["pod"]["container"]
+    for status in context.pod.status.container_statuses or []:
+        if status.name != container.get("name"):
+            continue
+        if status.image:
+            container["image"] = status.image
+        # the image id is a digest reference, e.g., "docker.io/library/nginx@sha256:..."
+        if status.image_id and "@" in status.image_id:
+            container["image_digest"] = status.image_id.rsplit("@", 1)[1]
+        return
+
+
+def enrich_node(koney_alert: KoneyAlert, context: EnrichmentContext) -> None:
+    """Attaches the node that the pod is scheduled on, if the event did not tell."""
+    if koney_alert.get("node") or not context.pod or not context.pod.spec:
+        return
+    if node_name := context.pod.spec.node_name:
+        koney_alert["node"] = NodeMetadata(name=node_name)
+
+
+def enrich_cloud(koney_alert: KoneyAlert, context: EnrichmentContext) -> None:
+    """Attaches the cloud provider, region, zone, and instance type of the node."""
+    if not koney_alert.get("node") or not context.node:
+        return
+
+    labels = context.node.metadata.labels or {}
+    provider_id = (context.node.spec and context.node.spec.provider_id) or ""
+    cloud = CloudMetadata(
+        provider=provider_id.split("://", 1)[0] if "://" in provider_id else None,
+        region=labels.get(NODE_REGION_LABEL),
+        zone=labels.get(NODE_ZONE_LABEL),
+        instance_type=labels.get(NODE_INSTANCE_TYPE_LABEL),
+    )
+    if any(cloud.values()):
+        koney_alert["node"]["cloud"] = cloud
+
+
+# names of the enabled enrichers that are unknown, to warn about each of them once
+_unknown_enrichers: set[str] = set()
+
+# all known enrichers by their names, which further enrichers can be registered to
+ENRICHERS: dict[str, Enricher] = {
+    "owner": enrich_owner,
+    "labels": enrich_labels,
+    "image": enrich_image,
+    "node": enrich_node,
+    "cloud": enrich_cloud,
+}
+
+
+def register_enricher(name: str, enricher: Enricher) -> None:
+    """Registers an enricher, which runs if its name is among the enabled enrichers."""
+    ENRICHERS[name] = enricher
+
+
+def enabled_enrichers(names: str) -> list[tuple[str, Enricher]]:
+    """Returns the enrichers with the given comma-separated names, in that order."""
+    enrichers = []
+    for name in (name.strip() for name in names.split(",")):
+        if not name:
+            continue
+        if name not in ENRICHERS:
+            if name not in _unknown_enrichers:
+                _unknown_enrichers.add(name)
+                logger.warning(f"Ignoring unknown alert enricher {name}")
+            continue
+        enrichers.append((name, ENRICHERS[name]))
+    return enrichers
+
+
+def enrich_alert(koney_alert: KoneyAlert) -> None:
+    """
+    Runs the enabled enrichers on an alert, in place. An enricher that fails does
+    not keep the alert from being delivered, nor the other enrichers from running.
+    """
+    context = EnrichmentContext(koney_alert)
+    for name, enricher in enabled_enrichers(ENABLED_ENRICHERS):
+        try:
+            enricher(koney_alert, context)
+        except Exception as e:
+            logger.warning(
+                f"Failed to enrich alert with {name}: {e}", extra=dict(enricher=name)
+            )
+
+
+###############################################################################
+
+
+# cached objects as (object or None if it does not exist, time of lookup), per kind,
+# namespace, and name
+_cache: OrderedDict[tuple[str, str, str], tuple[Any | None, float]] = OrderedDict()
+_cache_lock = threading.Lock()
+
+
+def _cached(key: tuple[str, str, str], read: Callable[[], Any]) -> Any | None:
+    now = time.time()
+    with _cache_lock:
+        if cached := _cache.get(key):
+            obj, looked_up_at = cached
+            if now - looked_up_at < ENRICHMENT_CACHE_SECONDS:
+                _cache.move_to_end(key)
+                return obj
+
+    try:
+        obj = read()
+    except ApiException as e:
+        if e.status != 404:
+            raise
+        obj = None  # the object might have been deleted in the meantime
+
+    with _cache_lock:
+        _cache[key] = (obj, now)
+        _cache.move_to_end(key)
+        while len(_cache) > ENRICHMENT_CACHE_MAX_SIZE:
+            _cache.popitem(last=False)
+    return obj
+
+
+def _controller_of(obj: Any) -> Any | None:
+    owner_references = obj.metadata.owner_references or []
+    for owner_reference in owner_references:
+        if owner_reference.controller:
+            return owner_reference
+    return owner_references[0] if owner_references else None
+
+
+def _read_owner(kind: str, namespace: str, name: str) -> Any:
+    if kind == "ReplicaSet":
+        return client.AppsV1Api().read_namespaced_replica_set(name, namespace)
+    return client.BatchV1Api().read_namespaced_job(name, namespace)
@@ -0,0 +1,122 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+
+from .types import *
+from .utils import (
+    _normalize_container_id,
+    build_response_metadata,
+    build_trap_metadata,
+    resolve_confidence,
+    resolve_severity,
+)
+
+# the tag of all Falco rules of Koney, see filesystoken.FalcoTag in the controller
+FALCO_TAG = "koney"
+# the prefix of the tags that store the metadata of the trap, as "key=value"
+FALCO_METADATA_TAG_PREFIX = "koney-"
+
+# the metadata keys that store the origin and the alerting configuration of the trap
+FALCO_DECEPTION_POLICY_NAME_METADATA = "koney-deception-policy-name"
+FALCO_TRAP_TYPE_METADATA = "koney-trap-type"
+FALCO_FILE_PATH_METADATA = "koney-file-path"
+FALCO_ALERT_SEVERITY_METADATA = "koney-alert-severity"
+FALCO_ALERT_TAGS_METADATA = "koney-alert-tags"
+FALCO_SEVERITY_METADATA = "koney-severity"
+FALCO_CONFIDENCE_METADATA = "koney-confidence"
+FALCO_TRAP_HASH_METADATA = "koney-trap-hash"
+FALCO_TRAP_DESCRIPTION_METADATA = "koney-trap-description"
+FALCO_DECEPTION_POLICY_GENERATION_METADATA = "koney-deception-policy-generation"
+FALCO_ATTCK_TECHNIQUES_METADATA = "koney-attck-techniques"
+FALCO_ENGAGE_ACTIVITIES_METADATA = "koney-engage-activities"
+FALCO_RESPONSE_ACTIONS_METADATA = "koney-response-actions"
+FALCO_QUARANTINE_TTL_METADATA = "koney-quarantine-ttl"
+
+
+def is_koney_falco_alert(falcoAlert: dict) -> bool:
+    """Returns True if a falcosidekick payload comes from a Falco rule of Koney.
+    falcosidekick forwards the alerts of all rules, unless it filters them by tags."""
+    return FALCO_TAG in (falcoAlert.get("tags") or [])
+
+
+def parse_falco_metadata(tags: list[str]) -> dict[str, str]:
+    """Returns the metadata of a trap that the controller stored in the tags of its rule."""
+    metadata = {}
+    for tag in tags:
+        key, separator, value = tag.partition("=")
+        if separator and key.startswith(FALCO_METADATA_TAG_PREFIX):
+            metadata[key] = value
+    return metadata
+
+
+def process_falco_alert(falcoAlert: dict) -> KoneyAlert:
+    metadata = parse_falco_metadata(falcoAlert.get("tags") or [])
+    fields = falcoAlert.get("output_fields") or {}
+    tags_json = metadata.get(FALCO_ALERT_TAGS_METADATA)
+
+    koneyAlert = KoneyAlert(
+        schema_version=SCHEMA_VERSION,
+        timestamp=falcoAlert["time"],
+        deception_policy_name=metadata.get(FALCO_DECEPTION_POLICY_NAME_METADATA),
+        trap_type=metadata.get(FALCO_TRAP_TYPE_METADATA, "filesystem_honeytoken"),
+        severity=resolve_severity(
+            metadata.get(FALCO_ALERT_SEVERITY_METADATA),
+            metadata.get(FALCO_SEVERITY_METADATA),
+        ),
+        confidence=resolve_confidence(metadata.get(FALCO_CONFIDENCE_METADATA)),
+        tags=json.loads(tags_json) if tags_json else {},
+        trap=build_trap_metadata(
+            metadata.get(FALCO_TRAP_HASH_METADATA),
+            metadata.get(FALCO_DECEPTION_POLICY_GENERATION_METADATA),
+            metadata.get(FALCO_TRAP_DESCRIPTION_METADATA),
+            metadata.get(FALCO_ATTCK_TECHNIQUES_METADATA),
+            metadata.get(FALCO_ENGAGE_ACTIVITIES_METADATA),
+        ),
+        metadata={
+            # the accessed file, which may be matched by a path pattern of the trap
+            "file_path": (
+                fields.get("fd.name") or metadata.get(FALCO_FILE_PATH_METADATA)
+            ),
+        },
+        pod=PodMetadata(
+            name=fields.get("k8s.pod.name"),
+            namespace=fields.get("k8s.ns.name"),
+            container=ContainerMetadata(
+                id=_normalize_container_id(fields.get("container.id")),
+                name=fields.get("container.name"),
+            ),
+        ),
+        # Falco reports the node name as its hostname
+        node=NodeMetadata(
+            name=falcoAlert.get("hostname"),
+        ),
+        process=ProcessMetadata(
+            uid=fields.get("user.uid"),
+            pid=fields.get("proc.pid"),
+            cwd=fields.get("proc.cwd"),
+            binary=fields.get("proc.exepath"),
+            arguments=fields.get("proc.args") or "",
+            exec_id=None,
+            credentials=None,
+            capabilities=None,
+            namespaces=None,
+        ),
+        response=build_response_metadata(
+            metadata.get(FALCO_RESPONSE_ACTIONS_METADATA),
+            metadata.get(FALCO_QUARANTINE_TTL_METADATA),
+        ),
+    )
+    return koneyAlert
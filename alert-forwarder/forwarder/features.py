@@ -0,0 +1,91 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import threading
+import time
+from typing import cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+# group, version, plural, and name of the Koney KoneyConfig CRD
+KONEY_CONFIG_GVPN = (
+    "research.dynatrace.com",
+    "v1alpha1",
+    "koneyconfigs",
+    "koney",
+)
+
+# feature flags that gate new detection behaviors
+PROCESS_ANCESTRY_ENRICHMENT_FEATURE = "ProcessAncestryEnrichment"
+
+# the number of seconds for which the feature flags are cached, when they are read per event
+FEATURE_FLAGS_CACHE_SECONDS = 60
+
+logger = logging.getLogger("uvicorn.error")
+
+# cached feature flags as (feature flags, time of lookup)
+_feature_flags_cache: tuple[list[dict], float] | None = None
+_lock = threading.Lock()
+
+
+def read_feature_flags() -> list[dict]:
+    """Reads the feature flags of the KoneyConfig. All features are disabled if there is no KoneyConfig."""
+    api = client.CustomObjectsApi()
+    try:
+        obj = cast(dict, api.get_cluster_custom_object(*KONEY_CONFIG_GVPN))
+    except ApiException as e:
+        if e.status and e.status == 404:
+            return []  # no KoneyConfig, so all features are disabled
+        if e.status and e.status == 403:
+            # KoneyConfigs are cluster-scoped, so they cannot be read in the namespace RBAC scope
+            logger.debug(f"Not allowed to read feature flags: {e}")
+            return []
+        logger.warning(f"Failed to read feature flags: {e}")
+        return []
+
+    return obj.get("spec", {}).get("featureFlags", [])
+
+
+def read_cached_feature_flags() -> list[dict]:
+    """Reads the feature flags of the KoneyConfig, at most once per cache period."""
+    global _feature_flags_cache
+
+    now = time.time()
+    with _lock:
+        if _feature_flags_cache:
+            feature_flags, looked_up_at = _feature_flags_cache
+            if now - looked_up_at < FEATURE_FLAGS_CACHE_SECONDS:
+                return feature_flags
+
+    feature_flags = read_feature_flags()
+    with _lock:
+        _feature_flags_cache = (feature_flags, now)
+    return feature_flags
+
+
+def is_feature_enabled(feature_flags: list[dict], name: str, namespace: str) -> bool:
+    """Returns true if the feature is enabled for workloads in the given namespace.
+    If namespace is empty, returns true only if the feature is enabled for the whole cluster."""
+    for flag in feature_flags:
+        if flag.get("name") != name:
+            continue
+        if not flag.get("enabled", False):
+            return False
+        namespaces = flag.get("namespaces", [])
+        return not namespaces or (namespace != "" and namespace in namespaces)
+
+    return False
@@ -13,20 +13,89 @@
 # You should have received a copy of the GNU Affero General Public License
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
-# TODO: Randomize on startup and sync with alerting system
-KONEY_FINGERPRINT = 1337
+import base64
+import logging
+import os
+import threading
+import time
+from typing import cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+KONEY_NAMESPACE = os.environ.get("KONEY_NAMESPACE", "koney-system")
+
+# the secret with the random fingerprint of this installation, see alerts.FingerprintSecretName in pkg/alerts
+FINGERPRINT_SECRET_NAME = "koney-fingerprint"
+FINGERPRINT_SECRET_KEY = "fingerprint"
+
+# the number of seconds for which the fingerprint is cached, when it is read per event
+FINGERPRINT_CACHE_SECONDS = 60
+
+logger = logging.getLogger("uvicorn.error")
+
+# cached fingerprint as (fingerprint, time of lookup)
+_fingerprint_cache: tuple[int | None, float] | None = None
+_lock = threading.Lock()
+
+
+def read_fingerprint() -> int | None:
+    """Reads the fingerprint that the controller embeds in commands issued by Koney itself.
+    Returns None if the fingerprint secret does not exist (yet) or cannot be read."""
+    api = client.CoreV1Api()
+    try:
+        secret = cast(
+            client.V1Secret,
+            api.read_namespaced_secret(FINGERPRINT_SECRET_NAME, KONEY_NAMESPACE),
+        )
+    except ApiException as e:
+        logger.warning(f"Failed to read fingerprint: {e}")
+        return None
+
+    value = (secret.data or {}).get(FINGERPRINT_SECRET_KEY)
+    if not value:
+        logger.warning(f"Secret '{FINGERPRINT_SECRET_NAME}' has no fingerprint")
+        return None
+
+    try:
+        return int(base64.b64decode(value).decode("utf-8").strip())
+    except ValueError as e:
+        logger.warning(
+            f"Invalid fingerprint in secret '{FINGERPRINT_SECRET_NAME}': {e}"
+        )
+        return None
+
+
+def read_cached_fingerprint() -> int | None:
+    """Reads the fingerprint, at most once per cache period.
+    If the lookup fails, the last known fingerprint is kept."""
+    global _fingerprint_cache
+
+    now = time.time()
+    with _lock:
+        if _fingerprint_cache:
+            fingerprint, looked_up_at = _fingerprint_cache
+            if now - looked_up_at < FINGERPRINT_CACHE_SECONDS:
+                return fingerprint
+
+    fingerprint = read_fingerprint()
+    with _lock:
+        if fingerprint is None and _fingerprint_cache:
+            fingerprint = _fingerprint_cache[0]
+        _fingerprint_cache = (fingerprint, now)
+    return fingerprint
 
 
 def encode_fingerprint_in_echo(code: int) -> str:
     """
-    See utils.EncodeFingerprintInEcho in Go code.
+    See alerts.EncodeFingerprintInEcho in pkg/alerts.
     """
     return f"KONEY_FINGERPRINT_{code}"
 
 
 def encode_fingerprint_in_cat(code: int) -> str:
     """
-    See utils.EncodeFingerprintInCat in Go code.
+    See alerts.EncodeFingerprintInCat in pkg/alerts.
     """
     binary_code = format(code, "b")
 
@@ -0,0 +1,82 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import os
+from collections.abc import Callable
+from typing import Literal, TypedDict
+
+from kubernetes import client
+
+# the checks that must pass for the alert forwarder to be ready, any of "kubernetes",
+# "tetragon", "triggers", and "sinks" (checks that are not required are only reported)
+READINESS_CHECKS = [
+    name.strip()
+    for name in os.environ.get(
+        "KONEY_READINESS_CHECKS", "kubernetes,tetragon,triggers"
+    ).split(",")
+    if name.strip()
+]
+
+# returns why a part of the pipeline is unhealthy, or None if it is healthy
+HealthCheck = Callable[[], str | None]
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class CheckResult(TypedDict):
+    status: Literal["ok", "failing"]
+    required: bool
+    error: str | None
+
+
+def run_health_checks(
+    checks: dict[str, HealthCheck], required: list[str] = READINESS_CHECKS
+) -> tuple[bool, dict[str, CheckResult]]:
+    """Runs all checks, and returns whether the required checks passed, and the results."""
+    results: dict[str, CheckResult] = {}
+    for name, check in checks.items():
+        try:
+            error = check()
+        except Exception as e:
+            error = f"the check failed: {e}"
+        results[name] = CheckResult(
+            status="ok" if error is None else "failing",
+            required=name in required,
+            error=error,
+        )
+        if error is not None:
+            logger.debug(f"Health check {name} is failing: {error}")
+
+    healthy = all(r["status"] == "ok" for r in results.values() if r["required"])
+    return healthy, results
+
+
+def check_kubernetes_api() -> str | None:
+    """Returns why the Kubernetes API is unreachable, or None if it is reachable."""
+    try:
+        client.VersionApi().get_code()
+    except Exception as e:
+        return f"the Kubernetes API is unreachable: {e}"
+    return None
+
+
+def check_sinks(failing_sinks: dict[str, str]) -> str | None:
+    """Returns the errors of the sinks whose last delivery failed, or None if none failed."""
+    if not failing_sinks:
+        return None
+    return "; ".join(
+        f"{name}: {error}" for name, error in sorted(failing_sinks.items())
+    )
@@ -0,0 +1,173 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import threading
+from typing import cast
+
+from kubernetes import client, watch
+from kubernetes.client.exceptions import ApiException
+
+# how long one watch request lasts, before the watch is opened again from the last version
+WATCH_TIMEOUT_SECONDS = 300
+# how long to wait before listing the objects again, after the watch failed
+WATCH_RETRY_SECONDS = 5
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class CustomObjectInformer:
+    """Caches the custom objects of one kind in memory.
+
+    The objects are listed once, and kept up to date with a watch, so that lookups do not
+    call the Kubernetes API. Until the first list succeeded, lookups call the API instead.
+    Namespaced objects are cached from all namespaces, and looked up by their name only.
+    """
+
+    def __init__(self, group: str, version: str, plural: str, namespaced=False):
+        self.group = group
+        self.version = version
+        self.plural = plural
+        self.namespaced = namespaced
+        self.objects: dict[str, dict] = {}
+        self.synced = threading.Event()
+        self._lock = threading.Lock()
+        self._stopped = threading.Event()
+        self._watch: watch.Watch | None = None
+        self._thread: threading.Thread | None = None
+
+    def start(self) -> None:
+        self._thread = threading.Thread(target=self._run, daemon=True)
+        self._thread.start()
+
+    def stop(self) -> None:
+        self._stopped.set()
+        if w := self._watch:
+            w.stop()
+
+    def get(self, name: str) -> dict | None:
+        """Returns the object with the given name, or None if it does not exist.
+        Of namespaced objects with the same name, any one is returned."""
+        if self.synced.is_set():
+            with self._lock:
+                if not self.namespaced:
+                    return self.objects.get(name)
+                return next(
+                    (obj for obj in self.objects.values() if _object_name(obj) == name),
+                    None,
+                )
+
+        try:
+            api = client.CustomObjectsApi()
+            if self.namespaced:
+                obj_list = cast(
+                    dict,
+                    api.list_cluster_custom_object(
+                        self.group,
+                        self.version,
+                        self.plural,
+                        field_selector=f"metadata.name={name}",
+                    ),
+                )
+                return next(iter(obj_list.get("items", [])), None)
+            return cast(
+                dict,
+                api.get_cluster_custom_object(
+                    self.group, self.version, self.plural, name
+                ),
+            )
+        except ApiException as e:
+            if e.status == 404:
+                return None
+            raise
+
+    def list_objects(self) -> list[dict] | None:
+        """Returns all cached objects, or None if they were not listed yet."""
+        if not self.synced.is_set():
+            return None
+        with self._lock:
+            return list(self.objects.values())
+
+    def replace(self, items: list[dict]) -> None:
+        """Replaces all cached objects, e.g., with the result of a list request."""
+        objects = {key: obj for obj in items if (key := self._object_key(obj))}
+        with self._lock:
+            self.objects = objects
+        self.synced.set()
+
+    def apply(self, event_type: str, obj: dict) -> None:
+        """Updates the cache with an event of a watch request."""
+        if not (key := self._object_key(obj)):
+            return
+        with self._lock:
+            if event_type in ("ADDED", "MODIFIED"):
+                self.objects[key] = obj
+            elif event_type == "DELETED":
+                self.objects.pop(key, None)
+
+    def _object_key(self, obj: dict) -> str | None:
+        if not (name := _object_name(obj)):
+            return None
+        if self.namespaced:
+            return f"{obj['metadata'].get('namespace', '')}/{name}"
+        return name
+
+    def _run(self) -> None:
+        while not self._stopped.is_set():
+            try:
+                resource_version = self._list()
+                self._watch_from(resource_version)
+            except Exception as e:
+                if self._stopped.is_set():
+                    return
+                logger.warning(f"Failed to watch {self.plural}, listing again: {e}")
+            self._stopped.wait(WATCH_RETRY_SECONDS)
+
+    def _list(self) -> str | None:
+        api = client.CustomObjectsApi()
+        obj_list = cast(
+            dict,
+            api.list_cluster_custom_object(self.group, self.version, self.plural),
+        )
+        self.replace(obj_list.get("items", []))
+        return obj_list.get("metadata", {}).get("resourceVersion")
+
+    def _watch_from(self, resource_version: str | None) -> None:
+        api = client.CustomObjectsApi()
+        while not self._stopped.is_set():
+            self._watch = watch.Watch()
+            for event in self._watch.stream(
+                api.list_cluster_custom_object,
+                self.group,
+                self.version,
+                self.plural,
+                resource_version=resource_version,
+                timeout_seconds=WATCH_TIMEOUT_SECONDS,
+            ):
+                obj = event.get("object") or {}
+                if event.get("type") == "ERROR":
+                    # e.g., the version is too old (410 Gone), so the objects are listed again
+                    raise ApiException(reason=obj.get("message"))
+                self.apply(event.get("type", ""), obj)
+                resource_version = (
+                    obj.get("metadata", {}).get("resourceVersion") or resource_version
+                )
+
+
+###############################################################################
+
+
+def _object_name(obj: dict) -> str | None:
+    return obj.get("metadata", {}).get("name")
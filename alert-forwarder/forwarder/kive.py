@@ -13,17 +13,59 @@
 # You should have received a copy of the GNU Affero General Public License
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
+import json
+
 from .types import *
-from .utils import _normalize_container_id
+from .utils import (
+    _normalize_container_id,
+    build_response_metadata,
+    build_trap_metadata,
+    resolve_confidence,
+    resolve_severity,
+)
+
+# the custom metadata key that stores the trap type
+KIVE_TRAP_TYPE_METADATA = "koney-trap-type"
+# the custom metadata keys that store the alerting configuration of the trap
+KIVE_ALERT_SEVERITY_METADATA = "koney-alert-severity"
+KIVE_ALERT_TAGS_METADATA = "koney-alert-tags"
+# the custom metadata keys that store the severity and confidence of the trap
+KIVE_SEVERITY_METADATA = "koney-severity"
+KIVE_CONFIDENCE_METADATA = "koney-confidence"
+# the custom metadata keys that store the origin of the trap
+KIVE_TRAP_HASH_METADATA = "koney-trap-hash"
+KIVE_TRAP_DESCRIPTION_METADATA = "koney-trap-description"
+KIVE_DECEPTION_POLICY_GENERATION_METADATA = "koney-deception-policy-generation"
+# the custom metadata keys that store the MITRE ATT&CK techniques and Engage activities of the trap
+KIVE_ATTCK_TECHNIQUES_METADATA = "koney-attck-techniques"
+KIVE_ENGAGE_ACTIVITIES_METADATA = "koney-engage-activities"
+# the custom metadata keys that store the response actions of the trap
+KIVE_RESPONSE_ACTIONS_METADATA = "koney-response-actions"
+KIVE_QUARANTINE_TTL_METADATA = "koney-quarantine-ttl"
 
 
 def process_kive_alert(kiveAlert: dict) -> KoneyAlert:
+    custom_metadata = kiveAlert["custom-metadata"]
+    tags_json = custom_metadata.get(KIVE_ALERT_TAGS_METADATA)
+
     koneyAlert = KoneyAlert(
+        schema_version=SCHEMA_VERSION,
         timestamp=kiveAlert["timestamp"],
-        deception_policy_name=kiveAlert["custom-metadata"][
-            "koney-deception-policy-name"
-        ],
-        trap_type="filesystem_honeytoken",
+        deception_policy_name=custom_metadata["koney-deception-policy-name"],
+        trap_type=custom_metadata.get(KIVE_TRAP_TYPE_METADATA, "filesystem_honeytoken"),
+        severity=resolve_severity(
+            custom_metadata.get(KIVE_ALERT_SEVERITY_METADATA),
+            custom_metadata.get(KIVE_SEVERITY_METADATA),
+        ),
+        confidence=resolve_confidence(custom_metadata.get(KIVE_CONFIDENCE_METADATA)),
+        tags=json.loads(tags_json) if tags_json else {},
+        trap=build_trap_metadata(
+            custom_metadata.get(KIVE_TRAP_HASH_METADATA),
+            custom_metadata.get(KIVE_DECEPTION_POLICY_GENERATION_METADATA),
+            custom_metadata.get(KIVE_TRAP_DESCRIPTION_METADATA),
+            custom_metadata.get(KIVE_ATTCK_TECHNIQUES_METADATA),
+            custom_metadata.get(KIVE_ENGAGE_ACTIVITIES_METADATA),
+        ),
         metadata={
             "file_path": kiveAlert["metadata"]["path"],
         },
@@ -44,6 +86,15 @@ def process_kive_alert(kiveAlert: dict) -> KoneyAlert:
             cwd=kiveAlert["process"]["cwd"],
             binary=kiveAlert["process"]["binary"],
             arguments=kiveAlert["process"]["arguments"],
+            exec_id=None,
+            credentials=None,
+            capabilities=None,
+            namespaces=None,
+        ),
+        response=build_response_metadata(
+            custom_metadata.get(KIVE_RESPONSE_ACTIONS_METADATA),
+            custom_metadata.get(KIVE_QUARANTINE_TTL_METADATA),
         ),
     )
     return koneyAlert
+
@@ -0,0 +1,110 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import os
+import sys
+import uuid
+from contextlib import contextmanager
+from contextvars import ContextVar
+from datetime import datetime, timezone
+from typing import Iterator
+
+from .alerts import create_alert_id
+from .types import KoneyAlert
+
+# how diagnostic logs are written, either "json" (one object per line) or "console"
+LOG_FORMAT = os.environ.get("KONEY_LOG_FORMAT", "json").lower()
+# the loggers that are written as JSON, i.e., the logs of the forwarder and of uvicorn
+JSON_LOGGERS = ["uvicorn.error", "uvicorn.access"]
+
+# attributes of every log record, anything else was passed with the extra argument
+# (except for the colorized messages that uvicorn adds for its console logs)
+_RECORD_ATTRIBUTES = set(vars(logging.makeLogRecord({}))) | {
+    "message",
+    "asctime",
+    "color_message",
+}
+
+# fields that are added to all logs of the current request, event, or background task
+_log_fields: ContextVar[dict] = ContextVar("log_fields", default={})
+
+
+def new_correlation_id() -> str:
+    return uuid.uuid4().hex
+
+
+@contextmanager
+def log_context(**fields) -> Iterator[None]:
+    """Adds fields to all logs within the context, e.g., the correlation id or pod."""
+    fields = {key: value for key, value in fields.items() if value is not None}
+    token = _log_fields.set({**_log_fields.get(), **fields})
+    try:
+        yield
+    finally:
+        _log_fields.reset(token)
+
+
+def current_log_fields() -> dict:
+    return dict(_log_fields.get())
+
+
+def alert_log_fields(koney_alert: KoneyAlert) -> dict:
+    """Returns the fields that identify an alert, its deception policy, and its pod."""
+    pod = koney_alert.get("pod") or {}
+    return dict(
+        alert_id=create_alert_id(koney_alert),
+        deception_policy_name=koney_alert.get("deception_policy_name"),
+        trap_type=koney_alert.get("trap_type"),
+        pod_namespace=pod.get("namespace"),
+        pod_name=pod.get("name"),
+    )
+
+
+class JsonFormatter(logging.Formatter):
+    """Formats log records as JSON objects, with context and extra fields as keys."""
+
+    def format(self, record: logging.LogRecord) -> str:
+        entry = {
+            "time": datetime.fromtimestamp(record.created, timezone.utc).isoformat(),
+            "level": record.levelname,
+            "logger": record.name,
+            "message": record.getMessage(),
+        }
+        # handlers format records in the thread that logged them, within its context
+        entry.update(current_log_fields())
+        entry.update(
+            (key, value)
+            for key, value in vars(record).items()
+            if key not in _RECORD_ATTRIBUTES
+        )
+        if record.exc_info:
+            entry["exception"] = self.formatException(record.exc_info)
+        return json.dumps(entry, default=str)
+
+
+def configure_logging() -> None:
+    """Writes the logs of the forwarder and of uvicorn as JSON to stderr, if enabled."""
+    if LOG_FORMAT != "json":
+        return
+
+    # stderr keeps the logs apart from the alerts, which are written to stdout
+    handler = logging.StreamHandler(sys.stderr)
+    handler.setFormatter(JsonFormatter())
+    for name in JSON_LOGGERS:
+        json_logger = logging.getLogger(name)
+        json_logger.handlers = [handler]
+        json_logger.propagate = False
@@ -13,134 +13,944 @@
 # You should have received a copy of the GNU Affero General Public License
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
+import asyncio
 import json
 import logging
+import os
+import signal
+import threading
 import time
+from contextlib import asynccontextmanager
 
 from fastapi import BackgroundTasks, FastAPI, Request, Response, status
 from kubernetes import config
-from rich.console import Console
+from kubernetes.client.exceptions import ApiException
 
+from .aggregation import (
+    aggregate_alert,
+    flush_aggregated_alerts,
+    format_aggregation_metrics,
+)
+from .auth import WEBHOOK_AUTH_ERROR, is_authorized_request
+from .canarytoken import (
+    extract_canary_token,
+    find_canary_token,
+    process_canary_token_alert,
+)
+from .clusters import (
+    CLUSTER_ALERT_ERROR,
+    CLUSTER_AUTH_ERROR,
+    attribute_alert,
+    authenticate_cluster,
+    forget_cluster_alert,
+    is_cluster_aggregation_enabled,
+    is_duplicate_cluster_alert,
+    parse_cluster_alerts,
+)
+from .deception_alert import try_record_alert
+from .dedup import (
+    EVENT_CACHE_PERSISTENCE,
+    format_event_cache_metrics,
+    is_duplicate_access,
+    load_event_cache,
+    save_event_cache,
+)
+from .enrichment import enrich_alert
+from .falco import is_koney_falco_alert, process_falco_alert
+from .health import check_kubernetes_api, check_sinks, run_health_checks
 from .kive import process_kive_alert
-from .sink import K8S_SINK_READ_ERROR, SINK_SEND_ERROR, send_alert, try_read_alert_sinks
+from .log import (
+    alert_log_fields,
+    configure_logging,
+    log_context,
+    new_correlation_id,
+)
+from .offsets import advance, load_watermarks, save_watermarks
+from .otel import try_export_alert
+from .output import AlertWriter
+from .quota import build_quota_exceeded_alert, check_quota
+from .ratelimit import TriggerCoalescer, TriggerLimiter, format_trigger_metrics
+from .redelivery import (
+    enqueue_failed_delivery,
+    failing_sinks,
+    has_pending_work,
+    record_delivery,
+    redeliver_due_alerts,
+    report_delivery_status,
+)
+from .response import try_request_response
+from .selftest import (
+    SelfTestResult,
+    build_self_test_failed_alert,
+    find_self_test_id,
+    is_pending_self_test,
+    is_self_test_alert,
+    mark_self_test_alert,
+    start_self_test_job,
+    try_report_self_test_passed,
+)
+from .sidecar import process_sidecar_alert
+from .sink import (
+    K8S_SINK_READ_ERROR,
+    SINK_SEND_ERROR,
+    alert_matches_sink,
+    send_alert_once,
+    try_read_alert_sinks,
+)
+from .sources import EventSource
+from .tampering import process_tampering_alert
 from .tetragon import (
+    LOG_WINDOW_SECONDS,
+    KubernetesLogEventSource,
+    LogWindow,
+    build_tracing_policy_matcher,
     container_matches_selectors,
+    event_cache,
+    extract_container_image,
+    forget_tetragon_events,
     is_filtered_alert,
     map_tetragon_event,
+    parse_tetragon_event,
     read_tetragon_events,
+    resolve_alerting,
     resolve_container_selectors,
+    tracing_policy_informer,
+    tracing_policy_namespaced_informer,
 )
+from .tetragon_grpc import TetragonEventStreams, list_tetragon_pod_addresses
+from .types import AlertingMetadata, AlertSink, KoneyAlert
 
 # various error messages
 K8S_AUTH_ERROR = "failed to authenticate with Kubernetes API"
+CANARY_TOKEN_UNKNOWN_ERROR = "canary token was not provisioned by koney"
+
+# the header with the correlation id of a request, which is generated if missing
+CORRELATION_ID_HEADER = "X-Request-ID"
+
+# the permissions of the alert forwarder, either "cluster" (e.g., to read the logs of the
+# Tetragon pods) or "namespace" (only the Koney namespace, so logs are never read)
+RBAC_SCOPE = os.environ.get("KONEY_RBAC_SCOPE", "cluster").lower()
+
+# how Tetragon events are read, either "grpc" (streamed with the GetEvents API of Tetragon),
+# "logs" (read from the export-stdout logs of Tetragon, when tracing policies trigger),
+# or "webhook" (only the events that tracing policies post to the handler)
+TETRAGON_EVENT_SOURCE = os.environ.get("KONEY_TETRAGON_EVENT_SOURCE", "grpc").lower()
+if TETRAGON_EVENT_SOURCE == "logs" and RBAC_SCOPE == "namespace":
+    TETRAGON_EVENT_SOURCE = "webhook"  # the logs cannot be read without cluster access
+
+# whether events are read from the logs of Tetragon, e.g., to catch up after restarts
+READ_TETRAGON_LOGS = TETRAGON_EVENT_SOURCE != "webhook" and RBAC_SCOPE != "namespace"
 
 # the delay after receiving a (possibly multiple) triggers until we start loading alerts (once)
-DEBOUNCE_SECONDS = 5
+DEBOUNCE_SECONDS = float(os.environ.get("KONEY_DEBOUNCE_SECONDS", "5"))
+
+# the delay after receiving a (possibly multiple) triggers until the event streams are updated
+RESYNC_DEBOUNCE_SECONDS = float(os.environ.get("KONEY_RESYNC_DEBOUNCE_SECONDS", "1"))
+
+# how often alerts that failed to deliver are redelivered, and delivery statistics are reported
+REDELIVERY_INTERVAL_SECONDS = 15
+
+# how often summaries of aggregated alerts are emitted, once their aggregation window ended
+AGGREGATION_FLUSH_INTERVAL_SECONDS = 5
+
+# how often the ids of processed events are persisted, if enabled
+EVENT_CACHE_SAVE_INTERVAL_SECONDS = 30
+
+# how long background work may take to finish on shutdown, before it is abandoned
+SHUTDOWN_TIMEOUT_SECONDS = 5
+
+
+@asynccontextmanager
+async def lifespan(_: FastAPI):
+    periodic_threads[:] = [
+        threading.Thread(target=redeliver_alerts_periodically, daemon=True),
+        threading.Thread(target=forward_aggregated_alerts_periodically, daemon=True),
+    ]
+    if EVENT_CACHE_PERSISTENCE != "none":
+        periodic_threads.append(
+            threading.Thread(target=persist_event_cache_periodically, daemon=True)
+        )
+    threads = [
+        *periodic_threads,
+        threading.Thread(target=ingest_tetragon_events, daemon=True),
+    ]
+    for thread in threads:
+        thread.start()
+    tracing_policy_informer.start()
+    tracing_policy_namespaced_informer.start()
+
+    request_shutdown_on_signals()
+    yield
+    shutdown(threads)
+
+
+def request_shutdown_on_signals():
+    # uvicorn waits for in-flight requests and their background tasks before the lifespan
+    # ends, so debounced triggers must learn about the shutdown as soon as it is requested
+    if threading.current_thread() is not threading.main_thread():
+        return  # signal handlers can only be installed in the main thread
+
+    for signum in (signal.SIGINT, signal.SIGTERM):
+        previous_handler = signal.getsignal(signum)
+        if not callable(previous_handler):
+            continue
+
+        def handle_signal(signum, frame, previous_handler=previous_handler):
+            shutdown_requested.set()
+            previous_handler(signum, frame)
+
+        signal.signal(signum, handle_signal)
+
+
+def shutdown(threads: list[threading.Thread]):
+    """Stops background work, and flushes everything that would be lost otherwise."""
+    shutdown_requested.set()
+
+    # let streamed events that are being handled finish, but do not accept new ones
+    tetragon_streams.stop()
+    tracing_policy_informer.stop()
+    tracing_policy_namespaced_informer.stop()
+    deadline = time.monotonic() + SHUTDOWN_TIMEOUT_SECONDS
+    for thread in threads:
+        thread.join(max(0.0, deadline - time.monotonic()))
 
-app = FastAPI(docs_url=None, redoc_url=None, openapi_url=None)
+    # summarize aggregated alerts now, since their groups do not survive a restart
+    try_forward_aggregated_alerts(force=True)
+
+    # deliver alerts that are due, the others are delivered by the next instance
+    try_redeliver_alerts()
+    save_event_cache(event_cache)
+    alert_writer.close()
+
+
+configure_logging()
+app = FastAPI(docs_url=None, redoc_url=None, openapi_url=None, lifespan=lifespan)
 logger = logging.getLogger("uvicorn.error")
-console = Console()
+# alerts are written to stdout, or to a file or named pipe, in the configured output format
+alert_writer = AlertWriter()
+
+# triggers are coalesced, so that floods of triggers cause a bounded number of log reads
+log_triggers = TriggerCoalescer(DEBOUNCE_SECONDS)
+resync_triggers = TriggerCoalescer(RESYNC_DEBOUNCE_SECONDS)
+# passes over the logs of Tetragon read back to the start of the last complete pass
+log_window = LogWindow()
+# posted events are rate limited per source, since each of them is processed on its own
+trigger_limiter = TriggerLimiter()
+
+# set once the server is shutting down, to finish pending work instead of waiting
+shutdown_requested = threading.Event()
+
+# the high-watermarks of streamed Tetragon events are updated one event at a time
+watermarks_lock = threading.Lock()
+
+# the background threads that run until shutdown, which must stay alive
+periodic_threads: list[threading.Thread] = []
 
-# global variable to remember when any handler was last triggered
-most_recent_trigger = 0
+
+@app.middleware("http")
+async def bind_correlation_id(request: Request, call_next):
+    # all logs of a request and its background tasks share the correlation id
+    correlation_id = request.headers.get(CORRELATION_ID_HEADER) or new_correlation_id()
+    with log_context(correlation_id=correlation_id):
+        response = await call_next(request)
+    response.headers[CORRELATION_ID_HEADER] = correlation_id
+    return response
 
 
 @app.get("/handlers/tetragon", status_code=status.HTTP_202_ACCEPTED)
-def handle_tetragon(response: Response, background_tasks: BackgroundTasks):
-    global most_recent_trigger
-    trigger_time = time.time()
+def handle_tetragon(
+    response: Response, request: Request, background_tasks: BackgroundTasks
+):
+    if not is_authorized_request(request):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
+
+    trigger_tetragon_events(background_tasks)
+
 
+@app.post("/handlers/tetragon", status_code=status.HTTP_202_ACCEPTED)
+async def handle_tetragon_events(
+    response: Response, request: Request, background_tasks: BackgroundTasks
+):
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
     if not authenticate_kubernetes():
         response.status_code = status.HTTP_401_UNAUTHORIZED
         return dict(message=K8S_AUTH_ERROR)
 
-    # enqueue a background task to load new alerts,
-    # which will be debounced automatically
-    most_recent_trigger = trigger_time
-    background_tasks.add_task(load_new_alerts, timestamp=trigger_time)
+    # without the triggering events, fall back to reading them like on GET requests
+    lines = split_tetragon_events(body.decode("utf-8"))
+    if not lines:
+        trigger_tetragon_events(background_tasks)
+        return
+
+    # the events are in the logs and streams, too, so a flood can be read there at once,
+    # unless events are only posted, then all of them must be processed one by one
+    source = request.client.host if request.client else "unknown"
+    if TETRAGON_EVENT_SOURCE != "webhook" and not trigger_limiter.allow(source):
+        logger.debug(f"Coalescing events of {source} (rate limit)")
+        trigger_tetragon_events(background_tasks)
+        return
+
+    background_tasks.add_task(process_posted_tetragon_events, lines)
+
+
+def trigger_tetragon_events(background_tasks: BackgroundTasks):
+    if TETRAGON_EVENT_SOURCE == "grpc":
+        # events are streamed already, but the tracing policy may be too new to be subscribed
+        if resync_triggers.trigger():
+            background_tasks.add_task(resync_tetragon_streams)
+        return
+    if not READ_TETRAGON_LOGS:
+        logger.debug("Skipping trigger without events (logs are not read)")
+        return
+
+    # enqueue a background task to load new alerts, unless one is pending already
+    if log_triggers.trigger():
+        background_tasks.add_task(load_new_alerts)
 
 
 @app.post("/handlers/kive", status_code=status.HTTP_202_ACCEPTED)
 async def handle_kive(response: Response, request: Request):
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
     if not authenticate_kubernetes():
         response.status_code = status.HTTP_401_UNAUTHORIZED
         return dict(message=K8S_AUTH_ERROR)
 
-    koney_alert = process_kive_alert(await request.json())
-    koney_alert_str = json.dumps(koney_alert)
-    console.print(koney_alert_str, soft_wrap=True)
+    koney_alert = process_kive_alert(json.loads(body))
+    if is_duplicate_access(koney_alert, "kive"):
+        logger.debug("Skipping event (reported by another captor)")
+        return
 
+    enrich_alert(koney_alert)
     alert_sinks = try_read_alert_sinks()
+    forward_alert(koney_alert, alert_sinks)
 
-    # send to external systems
-    for sink in alert_sinks:
-        try:
-            send_alert(koney_alert, sink)
-        except:
-            if logger.level <= logging.ERROR:
-                console.print(SINK_SEND_ERROR, style="bold red")
-                console.print_exception()
+    # let the controller contain the attacker, if the trap has response actions
+    if koney_alert["response"]:
+        try_request_response(koney_alert)
+
+
+@app.post("/handlers/falco", status_code=status.HTTP_202_ACCEPTED)
+async def handle_falco(response: Response, request: Request):
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
+
+    # falcosidekick posts the alerts of all Falco rules, but only the rules of Koney are traps
+    falco_alert = json.loads(body)
+    if not is_koney_falco_alert(falco_alert):
+        logger.debug(f"Ignoring alert of Falco rule '{falco_alert.get('rule')}'")
+        return
+
+    # Falco sees the accesses of the controller, too, when it deploys decoys
+    koney_alert = process_falco_alert(falco_alert)
+    if is_filtered_alert(koney_alert):
+        return
+    if is_duplicate_access(koney_alert, "falco"):
+        logger.debug("Skipping event (reported by another captor)")
+        return
+
+    enrich_alert(koney_alert)
+    alert_sinks = try_read_alert_sinks()
+    forward_alert(koney_alert, alert_sinks)
 
+    # let the controller contain the attacker, if the trap has response actions
+    if koney_alert["response"]:
+        try_request_response(koney_alert)
 
-def load_new_alerts(timestamp: float):
-    global most_recent_trigger
-    time.sleep(DEBOUNCE_SECONDS)
-    if timestamp < most_recent_trigger:
-        return  # another trigger was received in the meantime
 
-    # TODO (#29): if we are spammed with triggers, we never ever execute this code, fix that
+@app.post("/handlers/sidecar", status_code=status.HTTP_202_ACCEPTED)
+async def handle_sidecar(response: Response, request: Request):
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
 
-    # resolve tetragon events
-    events_per_policy = read_tetragon_events()
-    if not events_per_policy:
+    # watcher sidecars cannot tell which process opened the decoy, so there is
+    # no fingerprint of the controller to filter its own accesses by
+    koney_alert = process_sidecar_alert(json.loads(body))
+    if is_duplicate_access(koney_alert, "sidecar"):
+        logger.debug("Skipping event (reported by another captor)")
         return
 
+    enrich_alert(koney_alert)
+    alert_sinks = try_read_alert_sinks()
+    forward_alert(koney_alert, alert_sinks)
+
+    # let the controller contain the attacker, if the trap has response actions
+    if koney_alert["response"]:
+        try_request_response(koney_alert)
+
+
+@app.post("/handlers/tampering", status_code=status.HTTP_202_ACCEPTED)
+async def handle_tampering(response: Response, request: Request):
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
+
+    # the controller found a decoy that was changed or removed, which hints at an
+    # attacker covering their tracks, so it is alerted on like an access of the trap
+    koney_alert = process_tampering_alert(json.loads(body))
+    enrich_alert(koney_alert)
+    alert_sinks = try_read_alert_sinks()
+
+    # let the controller report the tampering again, if the alert cannot be queued
+    if not forward_alert(koney_alert, alert_sinks):
+        response.status_code = status.HTTP_503_SERVICE_UNAVAILABLE
+        return dict(message=SINK_SEND_ERROR)
+
+
+@app.post("/handlers/canarytoken", status_code=status.HTTP_202_ACCEPTED)
+async def handle_canary_token(response: Response, request: Request):
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
+
+    # canarytokens services only know the token that triggered, which the secret
+    # of the canary token correlates to the deception policy and the honeytoken
+    canary_alert = json.loads(body)
+    token = extract_canary_token(canary_alert)
+    found = find_canary_token(token) if token else None
+    if not found:
+        logger.debug(f"Ignoring trigger of unknown canary token '{token}'")
+        response.status_code = status.HTTP_404_NOT_FOUND
+        return dict(message=CANARY_TOKEN_UNKNOWN_ERROR)
+
+    # the canary token is used outside of the cluster, so there are no
+    # accesses of other captors or of the controller to filter
+    koney_alert = process_canary_token_alert(canary_alert, *found)
+    enrich_alert(koney_alert)
+    alert_sinks = try_read_alert_sinks()
+    forward_alert(koney_alert, alert_sinks)
+
+
+@app.post("/handlers/cluster", status_code=status.HTTP_202_ACCEPTED)
+async def handle_cluster(response: Response, request: Request):
+    if not is_cluster_aggregation_enabled():
+        response.status_code = status.HTTP_404_NOT_FOUND
+        return dict(message="alerts of other clusters are not aggregated")
+
+    # clusters authenticate with their own tokens, which attribute the alerts to them
+    body = await request.body()
+    cluster_name = authenticate_cluster(request)
+    if not cluster_name:
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=CLUSTER_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
+
+    koney_alerts = parse_cluster_alerts(body)
+    if koney_alerts is None:
+        response.status_code = status.HTTP_400_BAD_REQUEST
+        return dict(message=CLUSTER_ALERT_ERROR)
+
+    # the alerts were enriched, aggregated, and limited by the quota in their cluster
+    # already, and the pods are not in this cluster, so they are forwarded as they are
+    alert_sinks = try_read_alert_sinks()
+    forwarded = True
+    for koney_alert in koney_alerts:
+        attribute_alert(koney_alert, cluster_name)
+        if is_duplicate_cluster_alert(koney_alert):
+            logger.debug(
+                "Skipping alert (posted by the cluster before)",
+                extra=dict(cluster_name=cluster_name),
+            )
+            continue
+        if not forward_alert(koney_alert, alert_sinks, aggregate=False):
+            forget_cluster_alert(koney_alert)
+            forwarded = False
+
+    # let the forwarder of the cluster post the alerts again, which its sink retries
+    if not forwarded:
+        response.status_code = status.HTTP_503_SERVICE_UNAVAILABLE
+        return dict(message=SINK_SEND_ERROR)
+
+
+@app.post("/selftest", status_code=status.HTTP_202_ACCEPTED)
+async def handle_self_test(response: Response, request: Request):
+    # the self-tests run in the test pod of a Job, which reports the results in the status
+    # of the deception policies, so the request does not wait for them
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+    if not authenticate_kubernetes():
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=K8S_AUTH_ERROR)
+
+    # without a deception policy, the CronJob of the Helm chart tests all deception policies
+    try:
+        payload = json.loads(body) if body.strip() else {}
+        name = payload.get("deceptionPolicyName")
+        if name is not None and not isinstance(name, str):
+            raise TypeError("deceptionPolicyName is not a string")
+    except (json.JSONDecodeError, AttributeError, TypeError):
+        response.status_code = status.HTTP_400_BAD_REQUEST
+        return dict(message="invalid self-test request")
+
+    try:
+        job_name = await asyncio.to_thread(start_self_test_job, name)
+    except ApiException:
+        logger.exception("Failed to start the self-test job")
+        response.status_code = status.HTTP_500_INTERNAL_SERVER_ERROR
+        return dict(message="failed to start the self-test job")
+    if job_name is None:
+        response.status_code = status.HTTP_404_NOT_FOUND
+        return dict(message="no self-test CronJob to start the self-test from")
+    return dict(jobName=job_name)
+
+
+@app.post("/selftest/failures", status_code=status.HTTP_202_ACCEPTED)
+async def handle_self_test_failures(response: Response, request: Request):
+    # the self-tests run in the test pods of the self-test CronJobs, which only hand over their failures
+    body = await request.body()
+    if not is_authorized_request(request, body):
+        response.status_code = status.HTTP_401_UNAUTHORIZED
+        return dict(message=WEBHOOK_AUTH_ERROR)
+
+    failed_results = parse_self_test_failures(body)
+    if failed_results is None:
+        response.status_code = status.HTTP_400_BAD_REQUEST
+        return dict(message="invalid self-test failures")
+
+    await asyncio.to_thread(forward_self_test_failures, failed_results)
+
+
+def parse_self_test_failures(body: bytes) -> list[SelfTestResult] | None:
+    """Returns the failed self-tests of a request body, or None if it is invalid."""
+    try:
+        payload = json.loads(body)
+    except json.JSONDecodeError:
+        return None
+    if not isinstance(payload, dict) or not isinstance(payload.get("results"), list):
+        return None
+
+    failed_results = []
+    for result in payload["results"]:
+        if (
+            not isinstance(result, dict)
+            or not isinstance(result.get("deceptionPolicyName"), str)
+            or not isinstance(result.get("message"), str)
+        ):
+            return None
+        failed_results.append(
+            SelfTestResult(
+                deceptionPolicyName=result["deceptionPolicyName"],
+                passed=False,
+                message=result["message"],
+            )
+        )
+    return failed_results
+
+
+def forward_self_test_failures(failed_results: list[SelfTestResult]):
+    """Alerts the sinks that the detection chain of deception policies is broken."""
+    with log_context(correlation_id=new_correlation_id()):
+        alert_sinks = try_read_alert_sinks()
+        for result in failed_results:
+            koney_alert = build_self_test_failed_alert(result)
+            if not forward_alert(koney_alert, alert_sinks, aggregate=False):
+                logger.warning("Failed to forward the alert of a failed self-test")
+
+
+def split_tetragon_events(body: str) -> list[str]:
+    """Splits a request body with a Tetragon event, an array of events, or JSON lines."""
+    try:
+        payload = json.loads(body)
+    except json.JSONDecodeError:
+        return [line for line in body.splitlines() if line.strip()]
+
+    events = payload if isinstance(payload, list) else [payload]
+    return [json.dumps(event) for event in events if isinstance(event, dict)]
+
+
+def process_posted_tetragon_events(lines: list[str]):
+    matcher = build_tracing_policy_matcher()
     alert_sinks = try_read_alert_sinks()
 
+    for line in lines:
+        if not (parsed := parse_tetragon_event(line, matcher)):
+            continue  # not an event of a tracing policy created by Koney
+        policy_name, event, event_hash = parsed
+
+        # the event might be read from the logs or streamed, too
+        if event_hash in event_cache:
+            continue
+        event_cache.add(event_hash)
+
+        if not process_tetragon_event(
+            event,
+            resolve_alerting(policy_name),
+            resolve_container_selectors(policy_name),
+            alert_sinks,
+        ):
+            # read the event again from the logs or streams, if possible
+            forget_tetragon_events({event_hash})
+
+
+def load_new_alerts():
+    # on shutdown, process the events right away instead of waiting for more triggers
+    log_triggers.wait(shutdown_requested)
+    try_process_recent_alerts()
+
+
+def process_recent_alerts(
+    source: EventSource, since_seconds: int = LOG_WINDOW_SECONDS
+) -> bool:
+    """Processes the events of the last seconds. Returns whether all logs could be read."""
+    # resolve tetragon events that were not processed yet, even before a restart
+    tetragon_events = read_tetragon_events(
+        since_seconds, watermarks=load_watermarks(), source=source
+    )
+    if not tetragon_events.events_per_policy:
+        return tetragon_events.complete
+
+    alert_sinks = try_read_alert_sinks()
+    forwarded = True
+
     # iterate over Tetragon events, map, log, and send alerts
-    for policy_name, events in events_per_policy.items():
-        if logger.level <= logging.DEBUG:
-            console.print(f"Transforming {len(events)} alerts for policy {policy_name}")
+    for policy_name, events in tetragon_events.events_per_policy.items():
+        logger.debug(
+            f"Transforming {len(events)} alerts for policy {policy_name}",
+            extra=dict(tracing_policy_name=policy_name),
+        )
 
         # resolve container selectors once per policy for client-side filtering (if needed)
         container_selectors = resolve_container_selectors(policy_name)
+        # resolve the alerting configuration of the trap once per policy
+        alerting = resolve_alerting(policy_name)
 
         for event in events:
-            koney_alert = map_tetragon_event(event)
-            if is_filtered_alert(koney_alert):
-                if logger.level <= logging.DEBUG:
-                    console.print("Skipping event (filtered) ", koney_alert)
-                continue
-
-            # filter by container selector when Tetragon matched all containers due to wildcards.
-            if container_selectors is not None:
-                container_name = (
-                    (koney_alert.get("pod") or {}).get("container", {}).get("name")
-                )
-                if not container_matches_selectors(container_name, container_selectors):
-                    if logger.level <= logging.DEBUG:
-                        console.print("Skipping event (container filter) ", koney_alert)
-                    continue
-
-            # write to stdout
-            koney_alert_str = json.dumps(koney_alert)
-            console.print(koney_alert_str, soft_wrap=True)
-
-            # send to external systems
-            for sink in alert_sinks:
-                try:
-                    send_alert(koney_alert, sink)
-                except:
-                    if logger.level <= logging.ERROR:
-                        console.print(SINK_SEND_ERROR, style="bold red")
-                        console.print_exception()
-
-
-@app.get("/healthz", status_code=status.HTTP_204_NO_CONTENT)
+            with log_context(tracing_policy_name=policy_name):
+                if not process_tetragon_event(
+                    event, alerting, container_selectors, alert_sinks
+                ):
+                    forwarded = False
+
+    # only advance the watermarks once all sinks received the alerts,
+    # otherwise, read the events again on the next trigger to retry them
+    if forwarded:
+        save_watermarks(tetragon_events.watermarks, tetragon_events.streams)
+    else:
+        forget_tetragon_events(tetragon_events.event_hashes)
+    return tetragon_events.complete
+
+
+def process_tetragon_event(
+    event: dict,
+    alerting: AlertingMetadata | None,
+    container_selectors: list | None,
+    alert_sinks: list[AlertSink],
+) -> bool:
+    """Maps a Tetragon event to an alert and forwards it. Returns false to retry it."""
+    koney_alert = map_tetragon_event(event, alerting)
+    if is_filtered_alert(koney_alert):
+        logger.debug("Skipping event (filtered)", extra=alert_log_fields(koney_alert))
+        return True
+
+    # filter by container selector when Tetragon matched all containers due to wildcards.
+    if container_selectors is not None:
+        container_name = (koney_alert.get("pod") or {}).get("container", {}).get("name")
+        container_image = extract_container_image(event)
+        if not container_matches_selectors(
+            container_name, container_selectors, container_image
+        ):
+            logger.debug(
+                "Skipping event (container filter)",
+                extra=alert_log_fields(koney_alert),
+            )
+            return True
+
+    # alerts of self-tests are forwarded like all others, but marked as such
+    self_test_id = find_self_test_id(koney_alert)
+    if self_test_id and is_pending_self_test(
+        koney_alert["deception_policy_name"], self_test_id
+    ):
+        mark_self_test_alert(koney_alert)
+    else:
+        self_test_id = None
+        if is_duplicate_access(koney_alert, "tetragon"):
+            logger.debug(
+                "Skipping event (reported by another captor)",
+                extra=alert_log_fields(koney_alert),
+            )
+            return True
+
+    enrich_alert(koney_alert)
+    forwarded = forward_alert(koney_alert, alert_sinks)
+
+    if self_test_id:
+        if forwarded:
+            try_report_self_test_passed(koney_alert, self_test_id)
+        return forwarded
+
+    # let the controller contain the attacker, even if the alert was suppressed
+    if koney_alert["response"]:
+        try_request_response(koney_alert)
+
+    return forwarded
+
+
+def ingest_tetragon_events():
+    while not authenticate_kubernetes():
+        if shutdown_requested.wait(DEBOUNCE_SECONDS):
+            return
+
+    if not READ_TETRAGON_LOGS:
+        logger.info(
+            f"Reading {TETRAGON_EVENT_SOURCE} events only, the logs of Tetragon are not "
+            f"read ({RBAC_SCOPE} RBAC scope), so events that are missed are lost"
+        )
+
+    # catch up on events that happened while the forwarder was down, i.e., read the
+    # logs of Tetragon back to the high-watermarks, instead of waiting for a trigger
+    with log_context(correlation_id=new_correlation_id()):
+        try_process_recent_alerts()
+
+    # the streams are stopped on shutdown, which also returns from here
+    if TETRAGON_EVENT_SOURCE == "grpc":
+        tetragon_streams.run_forever()
+
+
+def resync_tetragon_streams():
+    resync_triggers.wait(shutdown_requested)
+    # catch up on events of tracing policies that were not subscribed yet
+    if tetragon_streams.resync():
+        try_process_recent_alerts()
+
+
+def try_process_recent_alerts():
+    if not READ_TETRAGON_LOGS:
+        return  # events that were missed cannot be read again
+
+    try:
+        # do not overwrite the high-watermarks of events that are streamed meanwhile
+        with watermarks_lock:
+            started_at, since_seconds = log_window.start_pass()
+            if process_recent_alerts(KubernetesLogEventSource(), since_seconds):
+                # the next pass only reads back to the start of this one
+                log_window.complete_pass(started_at)
+    except:
+        logger.warning("Failed to read Tetragon logs")
+
+
+def handle_streamed_tetragon_event(
+    pod_name: str, policy_name: str, event: dict, event_hash: str
+):
+    alert_sinks = try_read_alert_sinks()
+    forwarded = process_tetragon_event(
+        event,
+        resolve_alerting(policy_name),
+        resolve_container_selectors(policy_name),
+        alert_sinks,
+    )
+
+    # advance the high-watermark, so that the event is not read from the logs again
+    if forwarded and (event_time := event.get("time")):
+        with watermarks_lock:
+            watermark = load_watermarks().get(pod_name)
+            save_watermarks({pod_name: advance(watermark, event_time, event_hash)})
+
+
+tetragon_streams = TetragonEventStreams(handle_streamed_tetragon_event)
+
+
+def forward_alert(
+    koney_alert: KoneyAlert, alert_sinks: list[AlertSink], aggregate: bool = True
+) -> bool:
+    with log_context(**alert_log_fields(koney_alert)):
+        return _forward_alert(koney_alert, alert_sinks, aggregate)
+
+
+def _forward_alert(
+    koney_alert: KoneyAlert, alert_sinks: list[AlertSink], aggregate: bool
+) -> bool:
+    # alerts of self-tests must reach the sinks, so they are neither aggregated nor limited
+    is_self_test = is_self_test_alert(koney_alert)
+
+    # near-identical alerts are summarized later, once the aggregation window ended
+    if aggregate and not is_self_test and aggregate_alert(koney_alert) == "absorb":
+        logger.debug("Skipping event (aggregated)")
+        return True
+
+    # respect the alert quota of the deception policy
+    decision = (
+        "allow" if is_self_test else check_quota(koney_alert["deception_policy_name"])
+    )
+    if decision == "suppress":
+        logger.debug("Skipping event (quota exceeded)")
+        return True
+    elif decision == "exceeded":
+        koney_alert = build_quota_exceeded_alert(koney_alert)
+
+    # write to stdout (or the configured output file)
+    print_alert(koney_alert)
+
+    # export as OpenTelemetry logs and spans, if enabled
+    try_export_alert(koney_alert)
+
+    # record as DeceptionAlert in the cluster, if enabled
+    try_record_alert(koney_alert)
+
+    # send to external systems
+    sent = True
+    for sink in alert_sinks:
+        if not alert_matches_sink(koney_alert, sink):
+            logger.debug(f"Skipping sink {sink['name']} (no match)")
+            continue
+        with log_context(alert_sink_name=sink["name"]):
+            try:
+                if send_alert_once(koney_alert, sink):
+                    record_delivery(sink["name"], koney_alert)
+            except Exception as e:
+                logger.exception(SINK_SEND_ERROR)
+                # if the alert cannot be queued, read the events again on the next trigger
+                error = str(e) or type(e).__name__
+                if not enqueue_failed_delivery(koney_alert, sink, error):
+                    sent = False
+
+    return sent
+
+
+def forward_aggregated_alerts_periodically():
+    while not shutdown_requested.wait(AGGREGATION_FLUSH_INTERVAL_SECONDS):
+        try_forward_aggregated_alerts()
+
+
+def try_forward_aggregated_alerts(force: bool = False):
+    try:
+        summary_alerts = flush_aggregated_alerts(force=force)
+        if not summary_alerts or not authenticate_kubernetes():
+            return
+
+        with log_context(correlation_id=new_correlation_id()):
+            alert_sinks = try_read_alert_sinks()
+            for summary_alert in summary_alerts:
+                if not forward_alert(summary_alert, alert_sinks, aggregate=False):
+                    logger.warning("Failed to forward summary of aggregated alerts")
+    except:
+        logger.exception("failed to forward aggregated alerts")
+
+
+def redeliver_alerts_periodically():
+    while not shutdown_requested.wait(REDELIVERY_INTERVAL_SECONDS):
+        try_redeliver_alerts()
+
+
+def try_redeliver_alerts():
+    try:
+        if not authenticate_kubernetes() or not has_pending_work():
+            return
+
+        with log_context(correlation_id=new_correlation_id()):
+            alert_sinks = try_read_alert_sinks()
+            redeliver_due_alerts(alert_sinks)
+            report_delivery_status(alert_sinks)
+    except:
+        logger.exception("failed to redeliver alerts")
+
+
+def print_alert(koney_alert: KoneyAlert) -> None:
+    try:
+        alert_writer.write(koney_alert)
+    except:
+        # the alert is still sent to the alert sinks
+        logger.exception("failed to write alert")
+
+
+@app.get("/metrics")
+def metrics():
+    return Response(
+        content=format_event_cache_metrics(event_cache)
+        + format_trigger_metrics(trigger_limiter, [log_triggers, resync_triggers])
+        + format_aggregation_metrics(),
+        media_type="text/plain; version=0.0.4",
+    )
+
+
+def persist_event_cache_periodically():
+    while EVENT_CACHE_PERSISTENCE == "configmap" and not authenticate_kubernetes():
+        if shutdown_requested.wait(EVENT_CACHE_SAVE_INTERVAL_SECONDS):
+            return
+
+    load_event_cache(event_cache)
+    # the cache is saved one last time on shutdown
+    while not shutdown_requested.wait(EVENT_CACHE_SAVE_INTERVAL_SECONDS):
+        save_event_cache(event_cache)
+
+
+@app.get("/livez", status_code=status.HTTP_204_NO_CONTENT)
+def livez(response: Response):
+    # only failures that a restart resolves, i.e., background threads that crashed
+    if shutdown_requested.is_set():
+        return None
+    if dead_threads := [t.name for t in periodic_threads if not t.is_alive()]:
+        response.status_code = status.HTTP_503_SERVICE_UNAVAILABLE
+        return dict(message=f"background threads stopped: {', '.join(dead_threads)}")
+    return None
+
+
+@app.get("/healthz")
+@app.get("/readyz")
 def readyz(response: Response):
-    if not authenticate_kubernetes():
+    ready, checks = run_health_checks(
+        {
+            "kubernetes": check_kubernetes,
+            "tetragon": check_tetragon,
+            "triggers": check_triggers,
+            "sinks": lambda: check_sinks(failing_sinks()),
+        }
+    )
+    if not ready:
         response.status_code = status.HTTP_503_SERVICE_UNAVAILABLE
-        return dict(message=K8S_AUTH_ERROR)
+    return dict(ready=ready, checks=checks)
+
+
+def check_kubernetes() -> str | None:
+    if not authenticate_kubernetes():
+        return K8S_AUTH_ERROR
+    return check_kubernetes_api()
+
+
+def check_tetragon() -> str | None:
+    if TETRAGON_EVENT_SOURCE == "grpc":
+        return tetragon_streams.check_health()
+    if TETRAGON_EVENT_SOURCE == "webhook":
+        return None  # events are posted, so Tetragon is never called
+    if list_tetragon_pod_addresses() is None:
+        return "failed to list Tetragon pods"
+    return None
+
+
+def check_triggers() -> str | None:
+    stalled = [c for c in (log_triggers, resync_triggers) if c.is_stalled()]
+    if stalled:
+        return "triggered runs did not start, background tasks might be stuck"
     return None
 
 
@@ -149,7 +959,5 @@ def authenticate_kubernetes() -> bool:
         config.load_incluster_config()
         return True
     except config.config_exception.ConfigException:
-        if logger.level <= logging.ERROR:
-            console.print(K8S_AUTH_ERROR, style="bold red")
-            console.print_exception()
+        logger.exception(K8S_AUTH_ERROR)
         return False
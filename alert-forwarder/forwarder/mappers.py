@@ -0,0 +1,129 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+from collections.abc import Callable
+from typing import NamedTuple
+
+from .types import TrapType
+
+# the kinds of Tetragon events that tracing policies raise, i.e., the keys of the events
+EVENT_KINDS = ("process_kprobe", "process_tracepoint", "process_uprobe", "process_lsm")
+
+# the kernel functions and LSM hooks that access files
+FILE_ACCESS_KPROBES = ("security_file_permission", "security_mmap_file")
+FILE_ACCESS_LSM_HOOKS = ("file_open", "file_permission", "mmap_file")
+# the tracepoints of syscalls that open files, with the index of their path argument
+FILE_OPEN_TRACEPOINTS = {
+    "sys_enter_open": 0,
+    "sys_enter_openat": 1,
+    "sys_enter_openat2": 1,
+}
+
+# an event mapper interprets the event body of one kind (e.g., the "process_lsm" object)
+# and returns the trap-specific metadata, or None if the event does not belong to the trap
+EventMapper = Callable[[dict], dict | None]
+
+
+class MappedEvent(NamedTuple):
+    trap_type: TrapType
+    metadata: dict
+
+
+def map_file_access_kprobe(kprobe: dict) -> dict | None:
+    if kprobe.get("function_name") in FILE_ACCESS_KPROBES:
+        return dict(file_path=_first_file_path(kprobe))
+    return None
+
+
+def map_file_access_lsm(lsm: dict) -> dict | None:
+    if lsm.get("function_name") in FILE_ACCESS_LSM_HOOKS:
+        return dict(file_path=_first_file_path(lsm))
+    return None
+
+
+def map_file_open_tracepoint(tracepoint: dict) -> dict | None:
+    if tracepoint.get("subsys") != "syscalls":
+        return None
+    if (index := FILE_OPEN_TRACEPOINTS.get(tracepoint.get("event", ""))) is None:
+        return None
+
+    args = tracepoint.get("args") or []
+    path = args[index].get("string_arg") if len(args) > index else None
+    return dict(file_path=path)
+
+
+# the event mappers of each trap type, by the kinds of events that captors of the trap
+# type raise; captors declare their trap type with the koney/trap-type annotation, and the
+# trap type of other events is inferred by trying the mappers in this order
+EVENT_MAPPERS: dict[TrapType, dict[str, EventMapper]] = {
+    "filesystem_honeytoken": {
+        "process_kprobe": map_file_access_kprobe,
+        "process_lsm": map_file_access_lsm,
+        "process_tracepoint": map_file_open_tracepoint,
+    },
+}
+
+
+def register_event_mapper(
+    trap_type: TrapType, event_kind: str, mapper: EventMapper
+) -> None:
+    """Registers how the events of a kind are interpreted for a trap type."""
+    EVENT_MAPPERS.setdefault(trap_type, {})[event_kind] = mapper
+
+
+def map_event(event: dict, declared_trap_type: str | None = None) -> MappedEvent:
+    """
+    Infers the trap type and the trap-specific metadata of a Tetragon event. If the
+    captor declared its trap type, only the mappers of that trap type are tried.
+    Events that no mapper understands are of the "unknown" trap type, with metadata
+    that describes the hook that raised them.
+    """
+    if not (kind := _event_kind(event)):
+        return MappedEvent("unknown", {})
+
+    for trap_type, mappers in EVENT_MAPPERS.items():
+        if declared_trap_type and trap_type != declared_trap_type:
+            continue
+        mapper = mappers.get(kind)
+        if mapper and (metadata := mapper(event[kind])) is not None:
+            return MappedEvent(trap_type, metadata)
+
+    return MappedEvent("unknown", dict(hook=_describe_hook(kind, event[kind])))
+
+
+###############################################################################
+
+
+def _event_kind(event: dict) -> str | None:
+    for kind in EVENT_KINDS:
+        if isinstance(event.get(kind), dict):
+            return kind
+    return None
+
+
+def _first_file_path(body: dict) -> str | None:
+    for arg in body.get("args") or []:
+        if path := (arg.get("file_arg") or {}).get("path"):
+            return path
+    return None
+
+
+def _describe_hook(kind: str, body: dict) -> str:
+    # e.g., "kprobe:security_file_permission", or "uprobe:/usr/bin/bash:readline"
+    if kind == "process_tracepoint":
+        return f"tracepoint:{body.get('subsys')}/{body.get('event')}"
+    if kind == "process_uprobe":
+        return f"uprobe:{body.get('path')}:{body.get('symbol')}"
+    return f"{kind.removeprefix('process_')}:{body.get('function_name')}"
@@ -0,0 +1,210 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import os
+from datetime import datetime, timezone
+from hashlib import md5
+from collections.abc import Callable, Collection
+from typing import TypedDict, cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+# the namespace where Koney is running
+KONEY_NAMESPACE = os.environ.get("KONEY_NAMESPACE", "koney-system")
+# the config map that persists the high-watermarks of processed events
+OFFSETS_CONFIGMAP_NAME = "koney-alert-forwarder-offsets"
+# the key in the config map that stores the high-watermarks as JSON
+OFFSETS_CONFIGMAP_KEY = "watermarks.json"
+# the key in the config map that stores the ids of the alerts that were delivered, per sink, as JSON
+DELIVERED_CONFIGMAP_KEY = "delivered.json"
+# number of delivered alert ids that are persisted per sink (config maps are limited to 1 MiB)
+DELIVERED_ALERT_IDS_PER_SINK = 1000
+# how often saving is attempted, if other replicas save their watermarks at the same time
+OFFSETS_SAVE_ATTEMPTS = 3
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class Watermark(TypedDict):
+    time: str  # ISO 8601, the time of the most recent processed event
+    hashes: list[str]  # hashes of the processed events at exactly that time
+
+
+def hash_event(line: str) -> str:
+    # unlike hash(), this is stable across restarts of the forwarder
+    return md5(line.encode("utf-8")).hexdigest()
+
+
+def parse_event_time(time: str) -> datetime:
+    return datetime.fromisoformat(time.replace("Z", "+00:00"))
+
+
+def is_processed(watermark: Watermark | None, time: str, event_hash: str) -> bool:
+    """Returns true if an event is at or before the high-watermark, i.e., it was processed before."""
+    if not watermark:
+        return False
+
+    event_time = parse_event_time(time)
+    watermark_time = parse_event_time(watermark["time"])
+    if event_time == watermark_time:
+        return event_hash in watermark["hashes"]
+    return event_time < watermark_time
+
+
+def advance(watermark: Watermark | None, time: str, event_hash: str) -> Watermark:
+    """Returns the high-watermark after processing an event."""
+    if not watermark or parse_event_time(time) > parse_event_time(watermark["time"]):
+        return Watermark(time=time, hashes=[event_hash])
+    if parse_event_time(time) == parse_event_time(watermark["time"]):
+        if event_hash not in watermark["hashes"]:
+            return Watermark(time=time, hashes=[*watermark["hashes"], event_hash])
+    return watermark
+
+
+def seconds_since(watermark: Watermark, now: datetime | None = None) -> int:
+    """Returns the number of seconds to read logs for, so that nothing after the high-watermark is missed."""
+    now = now or datetime.now(timezone.utc)
+    return max(1, int((now - parse_event_time(watermark["time"])).total_seconds()) + 1)
+
+
+def load_watermarks() -> dict[str, Watermark]:
+    """Loads the high-watermarks of processed events per Tetragon pod."""
+    return _load(OFFSETS_CONFIGMAP_KEY, "watermarks")
+
+
+def load_delivered_alert_ids() -> dict[str, list[str]]:
+    """Loads the ids of the alerts that were delivered recently per sink, oldest first."""
+    return _load(DELIVERED_CONFIGMAP_KEY, "delivered alert ids")
+
+
+def save_watermarks(
+    watermarks: dict[str, Watermark], streams: Collection[str] | None = None
+) -> bool:
+    """
+    Persists the high-watermarks of processed events per Tetragon pod. Returns true on success.
+    The watermarks of other Tetragon pods (e.g., saved by other replicas) are kept, unless
+    the existing Tetragon pods are given as streams, and they no longer exist.
+    """
+
+    def merge(saved_watermarks: dict[str, Watermark]) -> dict[str, Watermark]:
+        merged_watermarks = {
+            stream: watermark
+            for stream, watermark in saved_watermarks.items()
+            if streams is None or stream in streams
+        }
+        merged_watermarks.update(watermarks)
+        return merged_watermarks
+
+    return _save(OFFSETS_CONFIGMAP_KEY, "watermarks", merge)
+
+
+def save_delivered_alert_ids(sink_name: str, alert_ids: list[str]) -> bool:
+    """
+    Persists the ids of alerts that were just delivered to a sink, so that they are not
+    delivered again after a restart. Returns true on success. The ids that were saved
+    before (e.g., by other replicas) are kept, up to the most recent ones per sink.
+    """
+
+    def merge(saved_alert_ids: dict[str, list[str]]) -> dict[str, list[str]]:
+        kept = [i for i in saved_alert_ids.get(sink_name, []) if i not in alert_ids]
+        recent = [*kept, *alert_ids][-DELIVERED_ALERT_IDS_PER_SINK:]
+        return {**saved_alert_ids, sink_name: recent}
+
+    return _save(DELIVERED_CONFIGMAP_KEY, "delivered alert ids", merge)
+
+
+###############################################################################
+
+
+def _load(key: str, description: str) -> dict:
+    api = client.CoreV1Api()
+    try:
+        config_map = cast(
+            client.V1ConfigMap,
+            api.read_namespaced_config_map(OFFSETS_CONFIGMAP_NAME, KONEY_NAMESPACE),
+        )
+    except ApiException as e:
+        if e.status and e.status == 404:
+            return {}  # nothing was processed yet
+        logger.warning(f"Failed to load {description}: {e}")
+        return {}
+
+    try:
+        return json.loads((config_map.data or {}).get(key, "{}"))
+    except json.JSONDecodeError:
+        return {}
+
+
+def _save(key: str, description: str, merge: Callable[[dict], dict]) -> bool:
+    try:
+        for _ in range(OFFSETS_SAVE_ATTEMPTS):
+            if _try_save(key, merge):
+                return True
+    except ApiException as e:
+        logger.warning(f"Failed to save {description}: {e}")
+        return False
+
+    logger.warning(f"Failed to save {description}, they were changed concurrently")
+    return False
+
+
+def _try_save(key: str, merge: Callable[[dict], dict]) -> bool:
+    # read the current config map, to only replace it if nobody changed it meanwhile
+    api = client.CoreV1Api()
+    resource_version = None
+    data: dict[str, str] = {}
+    try:
+        current = cast(
+            client.V1ConfigMap,
+            api.read_namespaced_config_map(OFFSETS_CONFIGMAP_NAME, KONEY_NAMESPACE),
+        )
+        resource_version = current.metadata.resource_version
+        data = dict(current.data or {})
+    except ApiException as e:
+        if not e.status or e.status != 404:
+            raise
+
+    try:
+        saved = json.loads(data.get(key, "{}"))
+    except json.JSONDecodeError:
+        saved = {}
+
+    # the other keys of the config map are kept as they are
+    data[key] = json.dumps(merge(saved), sort_keys=True)
+    config_map = client.V1ConfigMap(
+        metadata=client.V1ObjectMeta(
+            name=OFFSETS_CONFIGMAP_NAME,
+            namespace=KONEY_NAMESPACE,
+            resource_version=resource_version,
+        ),
+        data=data,
+    )
+
+    try:
+        if resource_version:
+            api.replace_namespaced_config_map(
+                OFFSETS_CONFIGMAP_NAME, KONEY_NAMESPACE, config_map
+            )
+        else:
+            api.create_namespaced_config_map(KONEY_NAMESPACE, config_map)
+    except ApiException as e:
+        if e.status and e.status == 409:
+            return False  # saved by another replica meanwhile, so try again
+        raise
+
+    return True
@@ -0,0 +1,279 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import os
+import secrets
+from urllib.parse import unquote
+
+import requests
+
+from .alerts import create_alert_description, create_alert_id
+from .siem import parse_timestamp
+from .sink import _get_cluster_uid
+from .types import KoneyAlert
+
+# Errors
+OTEL_EXPORT_ERROR = "failed to export alert with OTLP"
+
+# the default endpoint of OTLP over HTTP
+OTEL_DEFAULT_ENDPOINT = "http://localhost:4318"
+# the default service name of the alert forwarder
+OTEL_DEFAULT_SERVICE_NAME = "koney-alert-forwarder"
+# the instrumentation scope of the exported logs and spans
+OTEL_SCOPE = {"name": "koney.alert-forwarder"}
+
+# severity numbers of the OpenTelemetry log data model
+OTEL_SEVERITY_NUMBERS = {
+    "info": 9,  # INFO
+    "low": 10,  # INFO2
+    "medium": 13,  # WARN
+    "high": 17,  # ERROR
+    "critical": 21,  # FATAL
+}
+OTEL_DEFAULT_SEVERITY_NUMBER = 13
+
+# kinds of pod owners that have a resource attribute in the semantic conventions
+OTEL_OWNER_KINDS = {
+    "Deployment",
+    "StatefulSet",
+    "DaemonSet",
+    "ReplicaSet",
+    "Job",
+    "CronJob",
+}
+
+logger = logging.getLogger("uvicorn.error")
+
+
+def is_otel_enabled(signal: str) -> bool:
+    """
+    Returns whether alerts are exported as the given signal ("logs" or "traces"),
+    which is enabled with OTEL_LOGS_EXPORTER=otlp or OTEL_TRACES_EXPORTER=otlp.
+    """
+    exporters = os.environ.get(f"OTEL_{signal.upper()}_EXPORTER", "none")
+    return "otlp" in [exporter.strip().lower() for exporter in exporters.split(",")]
+
+
+def try_export_alert(koney_alert: KoneyAlert) -> None:
+    try:
+        export_alert(koney_alert)
+    except:
+        logger.exception(OTEL_EXPORT_ERROR)
+
+
+def export_alert(koney_alert: KoneyAlert) -> None:
+    export_logs, export_traces = is_otel_enabled("logs"), is_otel_enabled("traces")
+    if not export_logs and not export_traces:
+        return
+
+    resource = {"attributes": _to_otel_attributes(_resource_attributes(koney_alert))}
+    trace_id, span_id = secrets.token_hex(16), secrets.token_hex(8)
+
+    # the span is exported first, so that the log record can link to it
+    if export_traces:
+        span = build_otel_span(koney_alert, trace_id, span_id)
+        payload = {
+            "resourceSpans": [
+                {
+                    "resource": resource,
+                    "scopeSpans": [{"scope": OTEL_SCOPE, "spans": [span]}],
+                }
+            ]
+        }
+        _post_otlp("traces", payload)
+
+    if export_logs:
+        log_record = build_otel_log_record(koney_alert)
+        if export_traces:
+            log_record["traceId"], log_record["spanId"] = trace_id, span_id
+        payload = {
+            "resourceLogs": [
+                {
+                    "resource": resource,
+                    "scopeLogs": [{"scope": OTEL_SCOPE, "logRecords": [log_record]}],
+                }
+            ]
+        }
+        _post_otlp("logs", payload)
+
+
+def build_otel_log_record(koney_alert: KoneyAlert) -> dict:
+    severity = (koney_alert.get("severity") or "").lower()
+    time_unix_nano = _time_unix_nano(koney_alert["timestamp"])
+
+    return {
+        "timeUnixNano": time_unix_nano,
+        "observedTimeUnixNano": time_unix_nano,
+        "severityNumber": OTEL_SEVERITY_NUMBERS.get(
+            severity, OTEL_DEFAULT_SEVERITY_NUMBER
+        ),
+        "severityText": severity.upper() or "WARN",
+        "body": {"stringValue": create_alert_description(koney_alert)},
+        "attributes": _to_otel_attributes(_alert_attributes(koney_alert)),
+    }
+
+
+def build_otel_span(koney_alert: KoneyAlert, trace_id: str, span_id: str) -> dict:
+    time_unix_nano = _time_unix_nano(koney_alert["timestamp"])
+
+    return {
+        "traceId": trace_id,
+        "spanId": span_id,
+        "name": f"koney.alert {koney_alert['trap_type']}",
+        "kind": 1,  # SPAN_KIND_INTERNAL
+        "startTimeUnixNano": time_unix_nano,
+        "endTimeUnixNano": time_unix_nano,
+        "attributes": _to_otel_attributes(_alert_attributes(koney_alert)),
+    }
+
+
+###############################################################################
+
+
+def _resource_attributes(koney_alert: KoneyAlert) -> dict:
+    pod_dict = koney_alert.get("pod", {}) or {}
+    node_dict = koney_alert.get("node", {}) or {}
+    container_dict = pod_dict.get("container", {}) or {}
+    owner_dict = pod_dict.get("owner", {}) or {}
+    cloud_dict = node_dict.get("cloud", {}) or {}
+    cluster_dict = koney_alert.get("cluster", {}) or {}
+
+    attributes = {
+        "service.name": os.environ.get("OTEL_SERVICE_NAME", OTEL_DEFAULT_SERVICE_NAME),
+        # alerts that other clusters posted to this forwarder are attributed to them
+        "k8s.cluster.uid": None if cluster_dict else _get_cluster_uid(),
+        "k8s.cluster.name": cluster_dict.get("name"),
+        "k8s.node.name": node_dict.get("name"),
+        "k8s.namespace.name": pod_dict.get("namespace"),
+        "k8s.pod.name": pod_dict.get("name"),
+        "k8s.container.name": container_dict.get("name"),
+        "koney.deception_policy.name": koney_alert.get("deception_policy_name"),
+        # attributes of enriched alerts, e.g., "k8s.deployment.name=nginx"
+        "container.image.name": container_dict.get("image"),
+        "cloud.provider": cloud_dict.get("provider"),
+        "cloud.region": cloud_dict.get("region"),
+        "cloud.availability_zone": cloud_dict.get("zone"),
+        "host.type": cloud_dict.get("instance_type"),
+    }
+    if owner_dict.get("kind") in OTEL_OWNER_KINDS:
+        attributes[f"k8s.{owner_dict['kind'].lower()}.name"] = owner_dict["name"]
+
+    # standard resource attributes, e.g., "deployment.environment=prod"
+    for key, value in _parse_key_values(
+        os.environ.get("OTEL_RESOURCE_ATTRIBUTES", "")
+    ).items():
+        attributes.setdefault(key, value)
+
+    return attributes
+
+
+def _alert_attributes(koney_alert: KoneyAlert) -> dict:
+    pod_dict = koney_alert.get("pod", {}) or {}
+    process_dict = koney_alert.get("process", {}) or {}
+    credentials_dict = process_dict.get("credentials", {}) or {}
+    trap_dict = koney_alert.get("trap", {}) or {}
+
+    return {
+        "event.name": "koney.alert",
+        "koney.alert.id": create_alert_id(koney_alert),
+        "koney.trap_type": koney_alert["trap_type"],
+        "koney.severity": koney_alert.get("severity"),
+        "koney.confidence": koney_alert.get("confidence"),
+        "koney.metadata.file_path": (koney_alert.get("metadata", {}) or {}).get(
+            "file_path"
+        ),
+        **{
+            f"koney.tags.{key}": value
+            for key, value in (koney_alert.get("tags") or {}).items()
+        },
+        "koney.trap.hash": trap_dict.get("hash"),
+        "koney.trap.description": trap_dict.get("description"),
+        "koney.trap.attck_techniques": trap_dict.get("attck_techniques") or None,
+        "koney.trap.engage_activities": trap_dict.get("engage_activities") or None,
+        "k8s.container.id": (pod_dict.get("container", {}) or {}).get("id"),
+        "process.executable.path": process_dict.get("binary"),
+        "process.command_args": process_dict.get("arguments"),
+        "process.pid": process_dict.get("pid"),
+        "process.parent_pid": (process_dict.get("ancestors") or [{}])[0].get("pid"),
+        "process.owner": process_dict.get("uid"),
+        "process.user.id": credentials_dict.get("euid"),
+        "process.real_user.id": credentials_dict.get("uid"),
+        "koney.process.capabilities": process_dict.get("capabilities") or None,
+        "process.working_directory": process_dict.get("cwd"),
+    }
+
+
+def _to_otel_attributes(attributes: dict) -> list[dict]:
+    return [
+        {"key": key, "value": _to_otel_value(value)}
+        for key, value in attributes.items()
+        if value is not None
+    ]
+
+
+def _to_otel_value(value) -> dict:
+    if isinstance(value, bool):
+        return {"boolValue": value}
+    elif isinstance(value, int):
+        return {"intValue": str(value)}  # 64-bit integers are strings in OTLP/JSON
+    elif isinstance(value, float):
+        return {"doubleValue": value}
+    elif isinstance(value, list):
+        return {"arrayValue": {"values": [_to_otel_value(item) for item in value]}}
+    return {"stringValue": str(value)}
+
+
+def _time_unix_nano(timestamp: str) -> str:
+    # keep the nanoseconds that Tetragon reports, which datetime cannot represent
+    parsed = parse_timestamp(timestamp)
+    nanoseconds = int(parsed.timestamp()) * 1_000_000_000
+    if fraction := timestamp.partition(".")[2].rstrip("Z").split("+")[0]:
+        nanoseconds += int(fraction[:9].ljust(9, "0"))
+    return str(nanoseconds)
+
+
+def _post_otlp(signal: str, payload: dict) -> None:
+    specific_endpoint = os.environ.get(f"OTEL_EXPORTER_OTLP_{signal.upper()}_ENDPOINT")
+    if specific_endpoint:
+        url = specific_endpoint
+    else:
+        endpoint = os.environ.get("OTEL_EXPORTER_OTLP_ENDPOINT", OTEL_DEFAULT_ENDPOINT)
+        url = f"{endpoint.rstrip('/')}/v1/{signal}"
+
+    headers = {
+        **_parse_key_values(os.environ.get("OTEL_EXPORTER_OTLP_HEADERS", "")),
+        **_parse_key_values(
+            os.environ.get(f"OTEL_EXPORTER_OTLP_{signal.upper()}_HEADERS", "")
+        ),
+        "Content-Type": "application/json",
+    }
+    timeout_ms = int(os.environ.get("OTEL_EXPORTER_OTLP_TIMEOUT", "10000"))
+
+    resp = requests.post(url, json=payload, headers=headers, timeout=timeout_ms / 1000)
+    if not 200 <= resp.status_code < 300:
+        raise RuntimeError(
+            f"failed to export {signal} with OTLP: {resp.status_code} {resp.text}"
+        )
+
+
+def _parse_key_values(value: str) -> dict[str, str]:
+    # the W3C baggage format of OTEL_RESOURCE_ATTRIBUTES and OTEL_EXPORTER_OTLP_HEADERS
+    pairs = {}
+    for item in value.split(","):
+        key, separator, item_value = item.partition("=")
+        if separator and key.strip():
+            pairs[unquote(key.strip())] = unquote(item_value.strip())
+    return pairs
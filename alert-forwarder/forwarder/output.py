@@ -0,0 +1,168 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import os
+import sys
+import threading
+from collections.abc import Callable
+from typing import BinaryIO
+
+from rich.console import Console
+
+from .alerts import format_alert_summary
+from .types import KoneyAlert
+
+# how alerts are written, either "ndjson" (one JSON object per line, also called "json"),
+# "logfmt" (one line of key=value pairs), "proto" (length-delimited protobuf messages),
+# or "console" (colorized summaries for development)
+ALERT_OUTPUT_FORMAT = os.environ.get("KONEY_ALERT_OUTPUT_FORMAT", "ndjson").lower()
+
+# where alerts are written, either a file or a named pipe (e.g., read by a log shipper),
+# or stdout if empty
+ALERT_OUTPUT_PATH = os.environ.get("KONEY_ALERT_OUTPUT_PATH", "")
+
+logger = logging.getLogger("uvicorn.error")
+
+# alert summaries are colorized even if stdout is not a terminal, e.g., for kubectl logs
+summary_console = Console(force_terminal=True)
+
+
+def format_alert_ndjson(koney_alert: KoneyAlert) -> bytes:
+    return json.dumps(koney_alert).encode("utf-8") + b"\n"
+
+
+def format_alert_logfmt(koney_alert: KoneyAlert) -> bytes:
+    """Formats an alert as key=value pairs, with the keys of nested fields joined by dots.
+    Fields that are null are left out, lists are formatted as JSON."""
+    pairs = [
+        f"{key}={_format_logfmt_value(value)}"
+        for key, value in _flatten(koney_alert)
+        if value is not None
+    ]
+    return (" ".join(pairs) + "\n").encode("utf-8")
+
+
+def format_alert_proto(koney_alert: KoneyAlert) -> bytes:
+    """Formats an alert as a google.protobuf.Struct message, prefixed with its length as a
+    varint, like writeDelimitedTo in Java and protodelim in Go."""
+    # imported lazily, like the gRPC API of Tetragon, since few installations need it
+    from google.protobuf import struct_pb2
+
+    message = struct_pb2.Struct()
+    message.update(koney_alert)
+    payload = message.SerializeToString()
+    return encode_varint(len(payload)) + payload
+
+
+def format_alert_console(koney_alert: KoneyAlert) -> bytes:
+    with summary_console.capture() as capture:
+        summary_console.print(format_alert_summary(koney_alert), soft_wrap=True)
+    return capture.get().encode("utf-8")
+
+
+ALERT_OUTPUT_FORMATTERS: dict[str, Callable[[KoneyAlert], bytes]] = {
+    "ndjson": format_alert_ndjson,
+    "json": format_alert_ndjson,
+    "logfmt": format_alert_logfmt,
+    "proto": format_alert_proto,
+    "console": format_alert_console,
+}
+
+
+def encode_varint(value: int) -> bytes:
+    encoded = bytearray()
+    while value > 0x7F:
+        encoded.append((value & 0x7F) | 0x80)
+        value >>= 7
+    encoded.append(value)
+    return bytes(encoded)
+
+
+class AlertWriter:
+    """Writes alerts in one of the output formats, either to stdout or to a file or named
+    pipe. Opening a named pipe blocks until it has a reader, so it is opened on first use."""
+
+    def __init__(
+        self,
+        output_format: str = ALERT_OUTPUT_FORMAT,
+        output_path: str = ALERT_OUTPUT_PATH,
+    ):
+        if output_format not in ALERT_OUTPUT_FORMATTERS:
+            logger.warning(
+                f"unknown alert output format {output_format}, falling back to ndjson"
+            )
+        self._format = ALERT_OUTPUT_FORMATTERS.get(output_format, format_alert_ndjson)
+        self._path = output_path
+        self._file: BinaryIO | None = None
+        self._lock = threading.Lock()
+
+    def write(self, koney_alert: KoneyAlert) -> None:
+        data = self._format(koney_alert)
+        with self._lock:
+            try:
+                self._write(data)
+            except BrokenPipeError:
+                # the reader of the named pipe went away, so wait for the next one
+                self._close()
+                self._write(data)
+
+    def close(self) -> None:
+        with self._lock:
+            self._close()
+
+    def _write(self, data: bytes) -> None:
+        if self._file is None:
+            self._file = self._open()
+        self._file.write(data)
+        self._file.flush()
+
+    def _open(self) -> BinaryIO:
+        if not self._path:
+            sys.stdout.flush()
+            return sys.stdout.buffer
+        return open(self._path, "ab")
+
+    def _close(self) -> None:
+        if self._file is not None and self._path:
+            try:
+                self._file.close()
+            except OSError:
+                pass  # the buffer could not be flushed, e.g., if the reader went away
+        self._file = None
+
+
+###############################################################################
+
+
+def _flatten(value, prefix: str = ""):
+    if isinstance(value, dict):
+        for key, nested_value in value.items():
+            yield from _flatten(nested_value, f"{prefix}.{key}" if prefix else key)
+    else:
+        yield prefix, value
+
+
+def _format_logfmt_value(value) -> str:
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    if isinstance(value, (list, tuple)):
+        value = json.dumps(value)
+
+    text = str(value)
+    if not text or not text.isprintable() or any(c in text for c in ' ="\\'):
+        return json.dumps(text, ensure_ascii=False)
+    return text
@@ -0,0 +1,126 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import threading
+import time
+from collections import defaultdict, deque
+from typing import Literal, cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .types import KoneyAlert
+
+# group, version, plural of the Koney DeceptionPolicy CRD
+KONEY_DECEPTION_POLICIES_GVP = "research.dynatrace.com", "v1alpha1", "deceptionpolicies"
+
+# the length of the window in which alerts are counted against the quota
+QUOTA_WINDOW_SECONDS = 3600
+# the number of seconds for which the quota of a policy is cached
+QUOTA_CACHE_SECONDS = 60
+
+QuotaDecision = Literal["allow", "exceeded", "suppress"]
+
+logger = logging.getLogger("uvicorn.error")
+
+# timestamps of the alerts that were forwarded within the window, per policy
+_forwarded_alerts: dict[str, deque[float]] = defaultdict(deque)
+# number of alerts that were suppressed since the quota was exceeded, per policy
+_suppressed_alerts: dict[str, int] = defaultdict(int)
+# cached quotas as (quota, time of lookup), per policy
+_quota_cache: dict[str, tuple[int | None, float]] = {}
+_lock = threading.Lock()
+
+
+def check_quota(deception_policy_name: str | None) -> QuotaDecision:
+    """
+    Counts an alert against the quota of its deception policy. Returns "exceeded"
+    for the first alert over the quota, and "suppress" for all alerts after that,
+    until the quota is available again.
+    """
+    if not deception_policy_name:
+        return "allow"  # cannot attribute the alert, do not limit it
+
+    max_alerts_per_hour = _resolve_max_alerts_per_hour(deception_policy_name)
+    if not max_alerts_per_hour:
+        return "allow"
+
+    now = time.time()
+    with _lock:
+        forwarded = _forwarded_alerts[deception_policy_name]
+        while forwarded and forwarded[0] <= now - QUOTA_WINDOW_SECONDS:
+            forwarded.popleft()
+
+        if len(forwarded) < max_alerts_per_hour:
+            if suppressed := _suppressed_alerts.pop(deception_policy_name, 0):
+                logger.warning(
+                    f"Alert quota of policy {deception_policy_name} available "
+                    f"again, suppressed {suppressed} alerts in the meantime"
+                )
+            forwarded.append(now)
+            return "allow"
+
+        _suppressed_alerts[deception_policy_name] += 1
+        if _suppressed_alerts[deception_policy_name] == 1:
+            return "exceeded"
+        return "suppress"
+
+
+def build_quota_exceeded_alert(koney_alert: KoneyAlert) -> KoneyAlert:
+    """
+    Builds the meta-alert that is emitted once when the quota of a policy is
+    exceeded. It is derived from the first alert that was suppressed.
+    """
+    deception_policy_name = koney_alert["deception_policy_name"] or ""
+    meta_alert = KoneyAlert(**koney_alert)
+    meta_alert["metadata"] = dict(
+        quota_exceeded=True,
+        max_alerts_per_hour=_resolve_max_alerts_per_hour(deception_policy_name),
+        message="quota exceeded, suppressing further alerts",
+    )
+    return meta_alert
+
+
+###############################################################################
+
+
+def _resolve_max_alerts_per_hour(deception_policy_name: str) -> int | None:
+    now = time.time()
+    with _lock:
+        if cached := _quota_cache.get(deception_policy_name):
+            quota, looked_up_at = cached
+            if now - looked_up_at < QUOTA_CACHE_SECONDS:
+                return quota
+
+    quota = None
+    try:
+        api = client.CustomObjectsApi()
+        deception_policy = cast(
+            dict,
+            api.get_cluster_custom_object(
+                *KONEY_DECEPTION_POLICIES_GVP, deception_policy_name
+            ),
+        )
+        quota = deception_policy.get("spec", {}).get("maxAlertsPerHour")
+    except ApiException as e:
+        if e.status and e.status != 404:
+            logger.warning(
+                f"Failed to resolve alert quota for {deception_policy_name}: {e}"
+            )
+
+    with _lock:
+        _quota_cache[deception_policy_name] = (quota, now)
+    return quota
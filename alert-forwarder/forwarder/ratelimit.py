@@ -0,0 +1,131 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import os
+import threading
+import time
+from collections import OrderedDict
+
+# how many triggers per second each source may send on average, and in a burst
+TRIGGER_RATE_PER_SECOND = float(os.environ.get("KONEY_TRIGGER_RATE_PER_SECOND", "2"))
+TRIGGER_BURST = int(os.environ.get("KONEY_TRIGGER_BURST", "20"))
+# how many sources are tracked at most, forgetting the least recently seen sources
+MAX_TRIGGER_SOURCES = 1024
+# how long a scheduled run may be overdue, before the triggers are considered stalled
+TRIGGER_STALL_SECONDS = 60
+
+
+class TriggerLimiter:
+    """Limits the triggers of each source (e.g., a Tetragon pod) with a token bucket."""
+
+    def __init__(
+        self,
+        rate: float = TRIGGER_RATE_PER_SECOND,
+        burst: int = TRIGGER_BURST,
+        max_sources: int = MAX_TRIGGER_SOURCES,
+    ):
+        self.rate = rate
+        self.burst = burst
+        self.max_sources = max_sources
+        self.accepted = 0
+        self.rate_limited = 0
+        # the tokens left per source, and when they were last refilled
+        self._buckets: OrderedDict[str, tuple[float, float]] = OrderedDict()
+        self._lock = threading.Lock()
+
+    def allow(self, source: str, now: float | None = None) -> bool:
+        """Returns true if the source may trigger again, and takes a token if so."""
+        now = time.monotonic() if now is None else now
+        with self._lock:
+            tokens, updated = self._buckets.pop(source, (float(self.burst), now))
+            tokens = min(float(self.burst), tokens + (now - updated) * self.rate)
+            allowed = tokens >= 1.0
+            if allowed:
+                tokens -= 1.0
+                self.accepted += 1
+            else:
+                self.rate_limited += 1
+
+            self._buckets[source] = (tokens, now)
+            while len(self._buckets) > self.max_sources:
+                self._buckets.popitem(last=False)
+            return allowed
+
+
+class TriggerCoalescer:
+    """
+    Coalesces triggers into runs: the first trigger schedules a run after a delay, and
+    all triggers until then join that run. Unlike a debounce, a flood of triggers cannot
+    postpone the run forever, and triggers during a run schedule the next run.
+    """
+
+    def __init__(self, delay: float):
+        self.delay = delay
+        self.runs = 0
+        self.coalesced = 0
+        self._pending_since: float | None = None
+        self._lock = threading.Lock()
+
+    def trigger(self, now: float | None = None) -> bool:
+        """Returns true if the caller must schedule a run, false if one is pending."""
+        with self._lock:
+            if self._pending_since is not None:
+                self.coalesced += 1
+                return False
+            self._pending_since = time.monotonic() if now is None else now
+            return True
+
+    def wait(self, stopped: threading.Event) -> None:
+        """Waits for more triggers (unless stopped), then starts the run."""
+        stopped.wait(self.delay)
+        with self._lock:
+            self._pending_since = None
+            self.runs += 1
+
+    def is_stalled(self, now: float | None = None) -> bool:
+        """Returns true if a scheduled run did not start long after it was due."""
+        now = time.monotonic() if now is None else now
+        with self._lock:
+            return (
+                self._pending_since is not None
+                and now - self._pending_since > self.delay + TRIGGER_STALL_SECONDS
+            )
+
+
+def format_trigger_metrics(
+    limiter: TriggerLimiter, coalescers: list[TriggerCoalescer]
+) -> str:
+    """Returns the metrics of the triggers in the Prometheus text format."""
+    metrics = [
+        ("accepted_total", "Posted events within the rate limits.", limiter.accepted),
+        (
+            "rate_limited_total",
+            "Posted events over the rate limits, which were read again instead.",
+            limiter.rate_limited,
+        ),
+        (
+            "coalesced_total",
+            "Triggers that joined a pending run.",
+            sum(c.coalesced for c in coalescers),
+        ),
+        ("runs_total", "Runs started by triggers.", sum(c.runs for c in coalescers)),
+    ]
+
+    lines = []
+    for name, description, value in metrics:
+        lines.append(f"# HELP koney_triggers_{name} {description}")
+        lines.append(f"# TYPE koney_triggers_{name} counter")
+        lines.append(f"koney_triggers_{name} {value}")
+    return "\n".join(lines) + "\n"
@@ -0,0 +1,396 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import os
+import threading
+import time
+from datetime import datetime, timezone
+from typing import TypedDict, cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .alerts import create_alert_id
+from .log import log_context
+from .sink import KONEY_DECEPTION_ALERT_SINK_GVNP, send_alert_once
+from .types import AlertSink, KoneyAlert, RedeliveryPolicy
+
+# the namespace where Koney is running
+KONEY_NAMESPACE = os.environ.get("KONEY_NAMESPACE", "koney-system")
+# the config map that persists the alerts that are queued for redelivery
+REDELIVERY_CONFIGMAP_NAME = "koney-alert-forwarder-redelivery"
+# the key in the config map that stores the queue as JSON
+REDELIVERY_CONFIGMAP_KEY = "queue.json"
+
+# config maps are limited to 1 MiB, so only this many alerts are queued per sink (dropping the oldest)
+MAX_PENDING_ALERTS_PER_SINK = 500
+# number of dead-lettered alerts that are kept in the config map for inspection, for all sinks
+MAX_DEAD_LETTERS = 100
+# number of timestamps of delivered alerts that are kept in the status, per sink,
+# so that the delivery of a specific alert can be confirmed
+RECENT_ALERTS_PER_SINK = 10
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class PendingAlert(TypedDict):
+    sink_name: str
+    alert_id: str
+    alert: KoneyAlert
+    attempts: int  # number of failed deliveries so far
+    next_attempt: float  # unix time of the next redelivery
+    last_error: str
+
+
+class RedeliveryQueue(TypedDict):
+    pending: list[PendingAlert]
+    # the most recent alerts that could not be delivered after the last attempt
+    dead_letters: list[PendingAlert]
+
+
+class DeliveryStats(TypedDict):
+    delivered: int
+    failed: int
+    dead_lettered: int
+    last_delivery_time: str | None  # RFC 3339
+    last_failure_time: str | None  # RFC 3339
+    last_error: str | None
+    recent_alert_timestamps: list[str]  # of the delivered alerts, oldest first
+
+
+# the queue, which is loaded from the config map on first use
+_queue: RedeliveryQueue | None = None
+# delivery statistics that were not reported in the status yet, per sink
+_stats: dict[str, DeliveryStats] = {}
+# number of pending alerts that were last reported in the status, per sink
+_reported_pending: dict[str, int] = {}
+# the error of the last delivery, per sink whose last delivery failed
+_failing_sinks: dict[str, str] = {}
+_lock = threading.Lock()
+
+
+def record_delivery(sink_name: str, koney_alert: KoneyAlert) -> None:
+    with _lock:
+        _record_delivery(sink_name, koney_alert)
+
+
+def enqueue_failed_delivery(
+    koney_alert: KoneyAlert, sink: AlertSink, error: str
+) -> bool:
+    """
+    Queues an alert for redelivery after it failed to deliver to a sink.
+    Returns true if the queue was persisted, i.e., the alert will not be lost.
+    """
+    alert_id = create_alert_id(koney_alert)
+    pending_alert = PendingAlert(
+        sink_name=sink["name"],
+        alert_id=alert_id,
+        alert=koney_alert,
+        attempts=1,
+        next_attempt=time.time() + compute_backoff(sink["redelivery"], 1),
+        last_error=error,
+    )
+
+    with _lock:
+        _record_failure(sink["name"], error)
+        if not (queue := _load_queue()):
+            return False
+
+        # the alert may be queued already, e.g., because the events were read again
+        if not any(
+            p["sink_name"] == sink["name"] and p["alert_id"] == alert_id
+            for p in queue["pending"]
+        ):
+            if sink["redelivery"]["max_attempts"] <= 1:
+                _dead_letter(queue, pending_alert)
+            else:
+                queue["pending"].append(pending_alert)
+                _drop_oldest_pending_alerts(queue, sink["name"])
+        return save_queue(queue)
+
+
+def failing_sinks() -> dict[str, str]:
+    """Returns the error of the last delivery of all sinks whose last delivery failed."""
+    with _lock:
+        return dict(_failing_sinks)
+
+
+def has_pending_work() -> bool:
+    """Returns true if alerts are queued or statistics were not reported yet."""
+    with _lock:
+        queue = _load_queue()
+        # sinks with pending alerts need a final report once their queue is empty
+        return (
+            bool(_stats)
+            or bool(queue and queue["pending"])
+            or any(_reported_pending.values())
+        )
+
+
+def redeliver_due_alerts(alert_sinks: list[AlertSink], now: float | None = None):
+    """
+    Delivers all queued alerts whose backoff expired.
+    Alerts are dead-lettered after the last attempt, i.e., dropped from the queue.
+    """
+    now = now or time.time()
+    sinks = {sink["name"]: sink for sink in alert_sinks}
+
+    with _lock:
+        if not (queue := _load_queue()):
+            return
+        due = [p for p in queue["pending"] if p["next_attempt"] <= now]
+    if not due:
+        return
+
+    # deliver without holding the lock, so that new alerts can be queued in the meantime
+    results: list[tuple[PendingAlert, str | None]] = []
+    for pending_alert in due:
+        if not (sink := sinks.get(pending_alert["sink_name"])):
+            results.append((pending_alert, None))  # the sink was deleted, drop the alert
+            continue
+        with log_context(
+            alert_id=pending_alert["alert_id"], alert_sink_name=sink["name"]
+        ):
+            try:
+                send_alert_once(pending_alert["alert"], sink)
+                results.append((pending_alert, None))
+            except Exception as e:
+                results.append((pending_alert, str(e) or type(e).__name__))
+
+    with _lock:
+        for pending_alert, error in results:
+            if pending_alert not in queue["pending"]:
+                continue  # dropped in the meantime, because too many alerts were queued
+            queue["pending"].remove(pending_alert)
+            sink_name = pending_alert["sink_name"]
+            if sink_name not in sinks:
+                continue
+            if error is None:
+                _record_delivery(sink_name, pending_alert["alert"])
+                continue
+
+            _record_failure(sink_name, error)
+            pending_alert["attempts"] += 1
+            pending_alert["last_error"] = error
+            redelivery = sinks[sink_name]["redelivery"]
+            if pending_alert["attempts"] >= redelivery["max_attempts"]:
+                _dead_letter(queue, pending_alert)
+            else:
+                backoff = compute_backoff(redelivery, pending_alert["attempts"])
+                pending_alert["next_attempt"] = now + backoff
+                queue["pending"].append(pending_alert)
+        save_queue(queue)
+
+
+def compute_backoff(redelivery: RedeliveryPolicy, attempts: int) -> float:
+    """Returns the seconds to wait after the given number of failed deliveries."""
+    backoff = redelivery["initial_backoff"] * 2 ** (attempts - 1)
+    return min(backoff, redelivery["max_backoff"])
+
+
+def report_delivery_status(alert_sinks: list[AlertSink]) -> None:
+    """Adds the delivery statistics since the last report to the status of the sinks."""
+    with _lock:
+        queue = _load_queue()
+        pending = {sink["name"]: 0 for sink in alert_sinks}
+        for pending_alert in queue["pending"] if queue else []:
+            if pending_alert["sink_name"] in pending:
+                pending[pending_alert["sink_name"]] += 1
+
+        updates = {
+            name: (_stats.pop(name, None), count)
+            for name, count in pending.items()
+            if name in _stats or _reported_pending.get(name) != count
+        }
+        # statistics of sinks that were deleted are not reported anymore
+        _stats.clear()
+        _reported_pending.clear()
+        _reported_pending.update(pending)
+
+    api = client.CustomObjectsApi()
+    for name, (stats, pending_count) in updates.items():
+        try:
+            obj = api.get_namespaced_custom_object_status(
+                *KONEY_DECEPTION_ALERT_SINK_GVNP, name
+            )
+            status = _build_status(obj.get("status") or {}, stats, pending_count)
+            api.patch_namespaced_custom_object_status(
+                *KONEY_DECEPTION_ALERT_SINK_GVNP, name, {"status": status}
+            )
+        except ApiException as e:
+            if e.status and e.status == 404:
+                continue  # the sink was deleted in the meantime
+            logger.warning(f"Failed to report the delivery status of sink {name}: {e}")
+            # report the statistics again next time
+            with _lock:
+                _reported_pending.pop(name, None)
+                if stats:
+                    _merge_stats(_get_stats(name), stats)
+
+
+def load_queue() -> RedeliveryQueue | None:
+    """Loads the alerts that are queued for redelivery. Returns None on failure."""
+    api = client.CoreV1Api()
+    try:
+        config_map = cast(
+            client.V1ConfigMap,
+            api.read_namespaced_config_map(REDELIVERY_CONFIGMAP_NAME, KONEY_NAMESPACE),
+        )
+    except ApiException as e:
+        if e.status and e.status == 404:
+            return RedeliveryQueue(pending=[], dead_letters=[])  # nothing failed yet
+        logger.warning(f"Failed to load redelivery queue: {e}")
+        return None
+
+    try:
+        data = json.loads((config_map.data or {}).get(REDELIVERY_CONFIGMAP_KEY, "{}"))
+    except json.JSONDecodeError:
+        data = {}
+    return RedeliveryQueue(
+        pending=data.get("pending", []), dead_letters=data.get("dead_letters", [])
+    )
+
+
+def save_queue(queue: RedeliveryQueue) -> bool:
+    """Persists the alerts that are queued for redelivery. Returns true on success."""
+    api = client.CoreV1Api()
+    config_map = client.V1ConfigMap(
+        metadata=client.V1ObjectMeta(
+            name=REDELIVERY_CONFIGMAP_NAME, namespace=KONEY_NAMESPACE
+        ),
+        data={REDELIVERY_CONFIGMAP_KEY: json.dumps(queue, sort_keys=True)},
+    )
+
+    try:
+        try:
+            api.replace_namespaced_config_map(
+                REDELIVERY_CONFIGMAP_NAME, KONEY_NAMESPACE, config_map
+            )
+        except ApiException as e:
+            if not e.status or e.status != 404:
+                raise
+            api.create_namespaced_config_map(KONEY_NAMESPACE, config_map)
+    except ApiException as e:
+        logger.warning(f"Failed to save redelivery queue: {e}")
+        return False
+
+    return True
+
+
+def _load_queue() -> RedeliveryQueue | None:
+    global _queue
+    if _queue is None:
+        _queue = load_queue()
+    return _queue
+
+
+def _dead_letter(queue: RedeliveryQueue, pending_alert: PendingAlert) -> None:
+    logger.error(
+        f"Dropping alert {pending_alert['alert_id']} for sink "
+        f"{pending_alert['sink_name']} after {pending_alert['attempts']} failed "
+        f"deliveries: {pending_alert['last_error']}",
+        extra=dict(
+            alert_id=pending_alert["alert_id"],
+            alert_sink_name=pending_alert["sink_name"],
+        ),
+    )
+    # the alert itself was already written to stdout when it was forwarded
+    queue["dead_letters"] = [*queue["dead_letters"], pending_alert][-MAX_DEAD_LETTERS:]
+    _get_stats(pending_alert["sink_name"])["dead_lettered"] += 1
+
+
+def _drop_oldest_pending_alerts(queue: RedeliveryQueue, sink_name: str) -> None:
+    pending_of_sink = [p for p in queue["pending"] if p["sink_name"] == sink_name]
+    for pending_alert in pending_of_sink[:-MAX_PENDING_ALERTS_PER_SINK]:
+        queue["pending"].remove(pending_alert)
+        _dead_letter(queue, pending_alert)
+
+
+def _record_delivery(sink_name: str, koney_alert: KoneyAlert) -> None:
+    stats = _get_stats(sink_name)
+    stats["delivered"] += 1
+    stats["last_delivery_time"] = _now()
+    stats["recent_alert_timestamps"] = [
+        *stats["recent_alert_timestamps"],
+        koney_alert["timestamp"],
+    ][-RECENT_ALERTS_PER_SINK:]
+    _failing_sinks.pop(sink_name, None)
+
+
+def _record_failure(sink_name: str, error: str) -> None:
+    stats = _get_stats(sink_name)
+    stats["failed"] += 1
+    stats["last_failure_time"] = _now()
+    stats["last_error"] = error
+    _failing_sinks[sink_name] = error
+
+
+def _get_stats(sink_name: str) -> DeliveryStats:
+    return _stats.setdefault(
+        sink_name,
+        DeliveryStats(
+            delivered=0,
+            failed=0,
+            dead_lettered=0,
+            last_delivery_time=None,
+            last_failure_time=None,
+            last_error=None,
+            recent_alert_timestamps=[],
+        ),
+    )
+
+
+def _merge_stats(stats: DeliveryStats, other: DeliveryStats) -> None:
+    stats["delivered"] += other["delivered"]
+    stats["failed"] += other["failed"]
+    stats["dead_lettered"] += other["dead_lettered"]
+    for key in ("last_delivery_time", "last_failure_time", "last_error"):
+        stats[key] = stats[key] or other[key]
+    # the other statistics were recorded before
+    stats["recent_alert_timestamps"] = [
+        *other["recent_alert_timestamps"],
+        *stats["recent_alert_timestamps"],
+    ][-RECENT_ALERTS_PER_SINK:]
+
+
+def _build_status(
+    status: dict, stats: DeliveryStats | None, pending_count: int
+) -> dict:
+    status = {**status, "pendingAlerts": pending_count}
+    if not stats:
+        return status
+
+    status["deliveredAlerts"] = status.get("deliveredAlerts", 0) + stats["delivered"]
+    status["failedDeliveries"] = status.get("failedDeliveries", 0) + stats["failed"]
+    status["deadLetteredAlerts"] = (
+        status.get("deadLetteredAlerts", 0) + stats["dead_lettered"]
+    )
+    if stats["last_delivery_time"]:
+        status["lastDeliveryTime"] = stats["last_delivery_time"]
+    if stats["recent_alert_timestamps"]:
+        status["recentAlertTimestamps"] = [
+            *status.get("recentAlertTimestamps", []),
+            *stats["recent_alert_timestamps"],
+        ][-RECENT_ALERTS_PER_SINK:]
+    if stats["last_failure_time"]:
+        status["lastFailureTime"] = stats["last_failure_time"]
+        status["lastError"] = stats["last_error"]
+    return status
+
+
+def _now() -> str:
+    return datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ")
@@ -0,0 +1,94 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import threading
+from collections import OrderedDict
+
+from kubernetes import client
+
+from .alerts import create_alert_id
+from .types import KoneyAlert
+
+# Errors
+RESPONSE_REQUEST_ERROR = "failed to request a response to an alert"
+
+# the annotation key on pods that requests the controller to take the response actions of a trap
+RESPONSE_REQUEST_ANNOTATION = "koney/response-request"
+
+# number of alert ids that are remembered to not request a response twice when alerts are retried
+RESPONSE_REQUESTED_CACHE_SIZE = 10000
+
+logger = logging.getLogger("uvicorn.error")
+
+# ids of the alerts for which a response was requested
+_requested_alerts: OrderedDict[str, None] = OrderedDict()
+_requested_lock = threading.Lock()
+
+
+def build_response_request(koney_alert: KoneyAlert) -> dict | None:
+    """Builds the response request for an alert, or returns None if the alert cannot be responded to.
+    The controller resolves the response actions from the trap, so the request only identifies the access."""
+    pod_dict = koney_alert.get("pod") or {}
+    trap_dict = koney_alert.get("trap") or {}
+    if not pod_dict.get("name") or not pod_dict.get("namespace"):
+        return None
+    if not koney_alert.get("deception_policy_name") or not trap_dict.get("hash"):
+        return None
+
+    request = {
+        "deceptionPolicyName": koney_alert["deception_policy_name"],
+        "trapHash": trap_dict["hash"],
+    }
+    if container_name := (pod_dict.get("container") or {}).get("name"):
+        request["containerName"] = container_name
+    if binary := (koney_alert.get("process") or {}).get("binary"):
+        request["binary"] = binary
+
+    return request
+
+
+def request_response_once(koney_alert: KoneyAlert) -> None:
+    """Requests a response to an alert by annotating the accessing pod, unless it was already requested."""
+    request = build_response_request(koney_alert)
+    if request is None:
+        return
+
+    alert_id = create_alert_id(koney_alert)
+    with _requested_lock:
+        if alert_id in _requested_alerts:
+            return
+
+    pod_dict = koney_alert["pod"] or {}
+    annotations = {RESPONSE_REQUEST_ANNOTATION: json.dumps(request)}
+    api = client.CoreV1Api()
+    api.patch_namespaced_pod(
+        pod_dict["name"],
+        pod_dict["namespace"],
+        {"metadata": {"annotations": annotations}},
+    )
+
+    with _requested_lock:
+        _requested_alerts[alert_id] = None
+        if len(_requested_alerts) > RESPONSE_REQUESTED_CACHE_SIZE:
+            _requested_alerts.popitem(last=False)
+
+
+def try_request_response(koney_alert: KoneyAlert) -> None:
+    try:
+        request_response_once(koney_alert)
+    except:
+        logger.exception(RESPONSE_REQUEST_ERROR)
@@ -0,0 +1,600 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import os
+import re
+import secrets
+import sys
+import time
+from concurrent.futures import ThreadPoolExecutor
+from datetime import datetime, timezone
+from typing import TypedDict, cast
+
+import requests
+from kubernetes import client, config
+from kubernetes.client.exceptions import ApiException
+from kubernetes.stream import stream
+
+from .log import configure_logging
+from .siem import parse_timestamp
+from .types import SCHEMA_VERSION, KoneyAlert
+
+# group, version, plural of the Koney DeceptionPolicy CRD
+KONEY_DECEPTION_POLICIES_GVP = "research.dynatrace.com", "v1alpha1", "deceptionpolicies"
+KONEY_NAMESPACE = os.environ.get("KONEY_NAMESPACE", "koney-system")
+
+# the CronJob of the Helm chart that self-tests all deception policies (selfTest.enable)
+SELF_TEST_CRONJOB_NAME = "koney-self-test"
+# the label of the CronJobs that self-test a single deception policy (spec.selfTest)
+DECEPTION_POLICY_LABEL = "koney/deception-policy"
+
+# the status condition of deception policies that reports the result of the latest self-test
+LAST_SELF_TEST_CONDITION = "LastSelfTest"
+SELF_TEST_REASON_RUNNING = "SelfTestRunning"
+SELF_TEST_REASON_PASSED = "SelfTestPassed"
+SELF_TEST_REASON_FAILED = "SelfTestFailed"
+SELF_TEST_REASON_TIMED_OUT = "SelfTestTimedOut"
+
+# the marker in the command that touches the honeytoken, which identifies the self-test
+SELF_TEST_MARKER_PREFIX = "KONEY_SELFTEST_"
+SELF_TEST_MARKER_PATTERN = re.compile(SELF_TEST_MARKER_PREFIX + r"([0-9a-f]{16})")
+
+# the tag that marks alerts of self-tests, so that receivers can tell them apart from real accesses
+SELF_TEST_TAG = "koney.selftest"
+
+# the metadata key that marks the alerts that report a failed self-test, i.e., a broken detection chain
+SELF_TEST_FAILED_METADATA = "self_test_failed"
+
+# the number of seconds to wait for the alert of a self-test to be forwarded
+SELF_TEST_TIMEOUT_SECONDS = int(os.environ.get("KONEY_SELF_TEST_TIMEOUT_SECONDS", "60"))
+# the number of seconds between two lookups of the result of a self-test
+SELF_TEST_POLL_SECONDS = 1
+# the number of deception policies that are self-tested at the same time
+SELF_TEST_MAX_WORKERS = 8
+# how often updating the status is attempted, if it is changed concurrently
+STATUS_UPDATE_ATTEMPTS = 3
+# the number of seconds to wait for the alert forwarder to accept the failures of self-tests
+REPORT_FAILURES_TIMEOUT_SECONDS = 30
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class SelfTestTarget(TypedDict):
+    kind: str  # Pod or Deployment
+    namespace: str
+    name: str
+    container: str
+    file_path: str
+
+
+class SelfTestResult(TypedDict):
+    deceptionPolicyName: str
+    passed: bool
+    message: str
+
+
+def run_self_tests(
+    deception_policy_names: list[str] | None = None,
+) -> list[SelfTestResult]:
+    """
+    Self-tests the given deception policies, or all deception policies with a placed honeytoken
+    that are not self-tested by their own CronJob (i.e., that do not set spec.selfTest).
+    Each self-test touches a honeytoken without the fingerprint of Koney, waits until the alert
+    was captured, mapped, and forwarded to the sinks, and reports the result in the status of
+    the deception policy as the LastSelfTest condition.
+    """
+    if deception_policy_names is None:
+        api = client.CustomObjectsApi()
+        deception_policies = cast(
+            dict, api.list_cluster_custom_object(*KONEY_DECEPTION_POLICIES_GVP)
+        )
+        deception_policy_names = [
+            policy["metadata"]["name"]
+            for policy in deception_policies.get("items", [])
+            if not has_own_self_test(policy) and find_self_test_target(policy)
+        ]
+
+    if not deception_policy_names:
+        return []
+
+    workers = min(SELF_TEST_MAX_WORKERS, len(deception_policy_names))
+    with ThreadPoolExecutor(max_workers=workers) as executor:
+        return list(executor.map(run_self_test, deception_policy_names))
+
+
+def run_self_test(deception_policy_name: str) -> SelfTestResult:
+    """Self-tests a single deception policy, see run_self_tests."""
+    try:
+        return _run_self_test(deception_policy_name)
+    except Exception as e:
+        logger.exception(f"Failed to self-test {deception_policy_name}")
+        return SelfTestResult(
+            deceptionPolicyName=deception_policy_name, passed=False, message=str(e)
+        )
+
+
+def has_own_self_test(deception_policy: dict) -> bool:
+    """Returns true if the deception policy is self-tested by its own CronJob."""
+    return (deception_policy.get("spec") or {}).get("selfTest") is not None
+
+
+def find_self_test_target(deception_policy: dict) -> SelfTestTarget | None:
+    """
+    Returns the designated honeytoken of a self-test, i.e., the first filesystem honeytoken
+    that the status of the deception policy reports as placed in a pod (or deployment).
+    """
+    for trap in deception_policy.get("status", {}).get("traps", []):
+        if trap.get("trapType") != "FilesystemHoneytoken" or trap.get("expired"):
+            continue
+        for placement in trap.get("placements", []):
+            if placement.get("kind") not in ("Pod", "Deployment"):
+                continue
+            if not placement.get("containers") or not placement.get("filePaths"):
+                continue
+            return SelfTestTarget(
+                kind=placement["kind"],
+                namespace=placement["namespace"],
+                name=placement["name"],
+                container=placement["containers"][0],
+                file_path=placement["filePaths"][0],
+            )
+    return None
+
+
+def find_self_test_id(koney_alert: KoneyAlert) -> str | None:
+    """Returns the id of the self-test that caused an alert, if any."""
+    arguments = (koney_alert.get("process") or {}).get("arguments") or ""
+    if match := SELF_TEST_MARKER_PATTERN.search(arguments):
+        return match.group(1)
+    return None
+
+
+def is_pending_self_test(deception_policy_name: str | None, self_test_id: str) -> bool:
+    """
+    Returns true if the deception policy waits for the result of the self-test, so that
+    commands that only pretend to be self-tests are not reported as such.
+    """
+    if not deception_policy_name:
+        return False
+
+    condition = _read_self_test_condition(deception_policy_name)
+    return (
+        condition is not None
+        and condition.get("reason") == SELF_TEST_REASON_RUNNING
+        and self_test_id in condition.get("message", "")
+    )
+
+
+def mark_self_test_alert(koney_alert: KoneyAlert) -> None:
+    koney_alert["tags"] = {**(koney_alert.get("tags") or {}), SELF_TEST_TAG: "true"}
+
+
+def is_self_test_alert(koney_alert: KoneyAlert) -> bool:
+    return (koney_alert.get("tags") or {}).get(SELF_TEST_TAG) == "true"
+
+
+def try_report_self_test_passed(koney_alert: KoneyAlert, self_test_id: str) -> None:
+    """
+    Reports that the alert of a self-test was forwarded to the sinks, and how long it took
+    since the honeytoken was touched.
+    """
+    deception_policy_name = koney_alert["deception_policy_name"]
+    if not deception_policy_name:
+        return
+
+    latency_seconds = _measure_latency(koney_alert)
+    message = (
+        f"Self-test {self_test_id} passed: "
+        "the alert was captured, mapped, and forwarded"
+    )
+    if latency_seconds is not None:
+        message += f" within {latency_seconds:.3f} seconds"
+    try:
+        _put_self_test_condition(
+            deception_policy_name,
+            self_test_id,
+            "True",
+            SELF_TEST_REASON_PASSED,
+            message,
+            latency_seconds,
+        )
+    except:
+        logger.exception(f"Failed to report the result of self-test {self_test_id}")
+
+
+def build_self_test_failed_alert(result: SelfTestResult) -> KoneyAlert:
+    """
+    Builds the alert that reports a failed self-test, because accesses to the traps of the
+    deception policy might go unnoticed while its detection chain is broken.
+    """
+    return KoneyAlert(
+        schema_version=SCHEMA_VERSION,
+        timestamp=datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ"),
+        deception_policy_name=result["deceptionPolicyName"],
+        trap_type="filesystem_honeytoken",
+        severity="HIGH",
+        confidence="high",
+        tags={SELF_TEST_TAG: "true"},
+        trap=None,
+        metadata={SELF_TEST_FAILED_METADATA: True, "message": result["message"]},
+        pod=None,
+        node=None,
+        process=None,
+        response=None,
+    )
+
+
+def build_self_test_status(
+    existing: dict | None, passed: bool, latency_seconds: float | None, now: str
+) -> dict:
+    """Returns the selfTest field of the status of a deception policy after a self-test finished."""
+    status = {
+        "lastRunTime": now,
+        "passed": passed,
+        "consecutiveFailures": (
+            0 if passed else int((existing or {}).get("consecutiveFailures", 0)) + 1
+        ),
+    }
+    if passed and latency_seconds is not None:
+        # like metav1.Duration, the latency is formatted like a Go duration
+        status["latency"] = f"{latency_seconds:.3f}s"
+    if passed:
+        status["lastSuccessTime"] = now
+    elif last_success_time := (existing or {}).get("lastSuccessTime"):
+        status["lastSuccessTime"] = last_success_time
+    return status
+
+
+def report_self_test_failures(failed_results: list[SelfTestResult]) -> None:
+    """
+    Hands the failed self-tests to the alert forwarder, which alerts the sinks that the
+    detection chain of the deception policies is broken.
+    """
+    headers = {}
+    if token := os.environ.get("KONEY_ALERT_FORWARDER_TOKEN"):
+        headers["Authorization"] = f"Bearer {token}"
+    resp = requests.post(
+        os.environ["KONEY_ALERT_FORWARDER_URL"].rstrip("/") + "/selftest/failures",
+        json=dict(results=failed_results),
+        headers=headers,
+        verify=os.environ.get("KONEY_ALERT_FORWARDER_CA_FILE") or True,
+        timeout=REPORT_FAILURES_TIMEOUT_SECONDS,
+    )
+    resp.raise_for_status()
+
+
+def start_self_test_job(deception_policy_name: str | None = None) -> str | None:
+    """
+    Starts a Job from a self-test CronJob, like `kubectl create job --from=cronjob/...`,
+    and returns its name, or None if there is no CronJob to start it from. Its test pod
+    runs the self-tests and reports the results in the status of the deception policies.
+    A single deception policy is self-tested with its own CronJob if it has one, and
+    otherwise with the CronJob of the Helm chart, which then only tests this policy.
+    """
+    api = client.BatchV1Api()
+    cron_job = None
+    if deception_policy_name:
+        cron_jobs = api.list_namespaced_cron_job(
+            KONEY_NAMESPACE,
+            label_selector=f"{DECEPTION_POLICY_LABEL}={deception_policy_name}",
+        )
+        cron_job = next(iter(cron_jobs.items), None)
+    if cron_job is None:
+        try:
+            cron_job = api.read_namespaced_cron_job(
+                SELF_TEST_CRONJOB_NAME, KONEY_NAMESPACE
+            )
+        except ApiException as e:
+            if e.status == 404:
+                return None
+            raise
+
+    job_template = cron_job.spec.job_template
+    if deception_policy_name:
+        for container in job_template.spec.template.spec.containers:
+            env = [
+                var
+                for var in container.env or []
+                if var.name != "KONEY_DECEPTION_POLICY_NAME"
+            ]
+            container.env = [
+                *env,
+                client.V1EnvVar(
+                    name="KONEY_DECEPTION_POLICY_NAME", value=deception_policy_name
+                ),
+            ]
+
+    job = client.V1Job(
+        metadata=client.V1ObjectMeta(
+            name=f"{cron_job.metadata.name}-manual-{secrets.token_hex(3)}",
+            namespace=KONEY_NAMESPACE,
+            labels=job_template.metadata.labels if job_template.metadata else None,
+            annotations={"cronjob.kubernetes.io/instantiate": "manual"},
+            owner_references=[
+                client.V1OwnerReference(
+                    api_version="batch/v1",
+                    kind="CronJob",
+                    name=cron_job.metadata.name,
+                    uid=cron_job.metadata.uid,
+                    controller=True,
+                )
+            ],
+        ),
+        spec=job_template.spec,
+    )
+    api.create_namespaced_job(KONEY_NAMESPACE, job)
+    return job.metadata.name
+
+
+def main() -> int:
+    """
+    Runs the self-tests in the dedicated test pod of a self-test CronJob, which touches the honeytokens
+    itself. The alert forwarder reports that a self-test passed when its alert arrives, so the test pod
+    only waits for the result. Without KONEY_DECEPTION_POLICY_NAME, all deception policies without
+    their own self-test CronJob are tested. Returns a non-zero exit code if any self-test failed.
+    """
+    logging.basicConfig(level=logging.INFO)
+    configure_logging()
+    config.load_incluster_config()
+
+    name = os.environ.get("KONEY_DECEPTION_POLICY_NAME")
+    results = run_self_tests([name] if name else None)
+    for result in results:
+        logger.info(f"{result['deceptionPolicyName']}: {result['message']}")
+
+    failed_results = [result for result in results if not result["passed"]]
+    if not failed_results:
+        return 0
+
+    if os.environ.get("KONEY_SELF_TEST_ALERT_ON_FAILURE", "false").lower() == "true":
+        try:
+            report_self_test_failures(failed_results)
+        except requests.RequestException:
+            logger.exception(
+                "Failed to report the failed self-tests to the alert forwarder"
+            )
+    return 1
+
+
+###############################################################################
+
+
+def _measure_latency(koney_alert: KoneyAlert) -> float | None:
+    """Returns the seconds since the access of an alert, or None if its time is unknown."""
+    try:
+        accessed_at = parse_timestamp(koney_alert["timestamp"])
+    except (KeyError, TypeError, ValueError):
+        return None
+    return max(0.0, (datetime.now(timezone.utc) - accessed_at).total_seconds())
+
+
+def _run_self_test(deception_policy_name: str) -> SelfTestResult:
+    api = client.CustomObjectsApi()
+    deception_policy = cast(
+        dict,
+        api.get_cluster_custom_object(
+            *KONEY_DECEPTION_POLICIES_GVP, deception_policy_name
+        ),
+    )
+
+    target = find_self_test_target(deception_policy)
+    if target is None:
+        return SelfTestResult(
+            deceptionPolicyName=deception_policy_name,
+            passed=False,
+            message="No filesystem honeytoken is placed that can be self-tested",
+        )
+    pod_name = _resolve_pod_name(target)
+
+    self_test_id = secrets.token_hex(8)
+    location = (
+        f"{target['file_path']} in {target['namespace']}/{pod_name} "
+        f"({target['container']})"
+    )
+    _put_self_test_condition(
+        deception_policy_name,
+        None,
+        "Unknown",
+        SELF_TEST_REASON_RUNNING,
+        f"Self-test {self_test_id} touched {location}",
+    )
+
+    try:
+        _touch_honeytoken(target, pod_name, self_test_id)
+    except Exception as e:
+        message = f"Self-test {self_test_id} could not touch {location}: {e}"
+        _put_self_test_condition(
+            deception_policy_name,
+            self_test_id,
+            "False",
+            SELF_TEST_REASON_FAILED,
+            message,
+        )
+        return SelfTestResult(
+            deceptionPolicyName=deception_policy_name, passed=False, message=message
+        )
+
+    # the alert may be forwarded by another replica, which reports the result in the status
+    deadline = time.monotonic() + SELF_TEST_TIMEOUT_SECONDS
+    while time.monotonic() < deadline:
+        time.sleep(SELF_TEST_POLL_SECONDS)
+        condition = _read_self_test_condition(deception_policy_name) or {}
+        if condition.get(
+            "reason"
+        ) == SELF_TEST_REASON_PASSED and self_test_id in condition.get("message", ""):
+            return SelfTestResult(
+                deceptionPolicyName=deception_policy_name,
+                passed=True,
+                message=condition["message"],
+            )
+
+    message = (
+        f"Self-test {self_test_id} touched {location}, "
+        f"but no alert was forwarded within {SELF_TEST_TIMEOUT_SECONDS} seconds"
+    )
+    _put_self_test_condition(
+        deception_policy_name,
+        self_test_id,
+        "False",
+        SELF_TEST_REASON_TIMED_OUT,
+        message,
+    )
+    return SelfTestResult(
+        deceptionPolicyName=deception_policy_name, passed=False, message=message
+    )
+
+
+def _resolve_pod_name(target: SelfTestTarget) -> str:
+    if target["kind"] == "Pod":
+        return target["name"]
+
+    # decoys in deployments are placed in the pod template, so any running pod has them
+    apps_api = client.AppsV1Api()
+    deployment = apps_api.read_namespaced_deployment(
+        target["name"], target["namespace"]
+    )
+    match_labels = deployment.spec.selector.match_labels or {}
+    label_selector = ",".join(f"{k}={v}" for k, v in match_labels.items())
+
+    core_api = client.CoreV1Api()
+    pods = core_api.list_namespaced_pod(
+        target["namespace"], label_selector=label_selector
+    )
+    for pod in pods.items:
+        if pod.status.phase == "Running" and not pod.metadata.deletion_timestamp:
+            return pod.metadata.name
+
+    raise RuntimeError(f"Deployment {target['name']} has no running pod")
+
+
+def _touch_honeytoken(
+    target: SelfTestTarget, pod_name: str, self_test_id: str
+) -> None:
+    # the shell opens the file itself, so its arguments (with the marker, but without the
+    # fingerprint of Koney) are captured, and the path is passed as an argument to not inject it
+    script = f': < "$0" # {SELF_TEST_MARKER_PREFIX}{self_test_id}'
+    api = client.CoreV1Api()
+    stream(
+        api.connect_get_namespaced_pod_exec,
+        pod_name,
+        target["namespace"],
+        container=target["container"],
+        command=["sh", "-c", script, target["file_path"]],
+        stderr=True,
+        stdin=False,
+        stdout=True,
+        tty=False,
+    )
+
+
+def _read_self_test_condition(deception_policy_name: str) -> dict | None:
+    api = client.CustomObjectsApi()
+    try:
+        deception_policy = cast(
+            dict,
+            api.get_cluster_custom_object(
+                *KONEY_DECEPTION_POLICIES_GVP, deception_policy_name
+            ),
+        )
+    except ApiException as e:
+        logger.warning(f"Failed to read {deception_policy_name}: {e}")
+        return None
+
+    return _find_condition(deception_policy, LAST_SELF_TEST_CONDITION)
+
+
+def _find_condition(deception_policy: dict, condition_type: str) -> dict | None:
+    for condition in deception_policy.get("status", {}).get("conditions") or []:
+        if condition.get("type") == condition_type:
+            return condition
+    return None
+
+
+def _put_self_test_condition(
+    deception_policy_name: str,
+    self_test_id: str | None,
+    status: str,
+    reason: str,
+    message: str,
+    latency_seconds: float | None = None,
+) -> bool:
+    """
+    Sets the LastSelfTest condition of a deception policy, and records the result in the selfTest
+    field of its status once the self-test finished. If a self-test id is given, the condition is only
+    set while that self-test is running, so that a result is not overwritten by a late one.
+    Returns true if the condition was set.
+    """
+    for _ in range(STATUS_UPDATE_ATTEMPTS):
+        api = client.CustomObjectsApi()
+        deception_policy = cast(
+            dict,
+            api.get_cluster_custom_object(
+                *KONEY_DECEPTION_POLICIES_GVP, deception_policy_name
+            ),
+        )
+
+        existing = _find_condition(deception_policy, LAST_SELF_TEST_CONDITION)
+        if self_test_id is not None and (
+            existing is None
+            or existing.get("reason") != SELF_TEST_REASON_RUNNING
+            or self_test_id not in existing.get("message", "")
+        ):
+            return False
+
+        now = datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ")
+        condition = {
+            "type": LAST_SELF_TEST_CONDITION,
+            "status": status,
+            "observedGeneration": deception_policy["metadata"].get("generation", 0),
+            # like for metav1.Condition, the transition time only changes with the status
+            "lastTransitionTime": (
+                existing["lastTransitionTime"]
+                if existing and existing.get("status") == status
+                else now
+            ),
+            "reason": reason,
+            "message": message,
+        }
+        status_dict = deception_policy.setdefault("status", {})
+        status_dict["conditions"] = [
+            c
+            for c in status_dict.get("conditions") or []
+            if c.get("type") != LAST_SELF_TEST_CONDITION
+        ] + [condition]
+        if status != "Unknown":
+            status_dict["selfTest"] = build_self_test_status(
+                status_dict.get("selfTest"), status == "True", latency_seconds, now
+            )
+
+        try:
+            api.replace_cluster_custom_object_status(
+                *KONEY_DECEPTION_POLICIES_GVP, deception_policy_name, deception_policy
+            )
+            return True
+        except ApiException as e:
+            if e.status and e.status == 409:
+                continue  # updated by the controller meanwhile, so try again
+            raise
+
+    logger.warning(
+        f"Failed to set the self-test condition of {deception_policy_name}, "
+        "it was changed concurrently"
+    )
+    return False
+
+
+if __name__ == "__main__":
+    sys.exit(main())
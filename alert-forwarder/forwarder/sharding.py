@@ -0,0 +1,111 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import logging
+import os
+import threading
+import time
+from hashlib import md5
+from typing import cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+# the namespace where Koney is running
+KONEY_NAMESPACE = os.environ.get("KONEY_NAMESPACE", "koney-system")
+# the name of the pod of this replica (set with the downward API), sharding is disabled without it
+KONEY_POD_NAME = os.environ.get("KONEY_POD_NAME", "")
+# the label selector of the pods of all replicas of the alert forwarder
+KONEY_REPLICA_LABEL_SELECTOR = os.environ.get(
+    "KONEY_REPLICA_LABEL_SELECTOR", "control-plane=controller-manager"
+)
+
+# the number of seconds for which the replicas are cached, before they are listed again
+REPLICAS_CACHE_SECONDS = 15
+
+logger = logging.getLogger("uvicorn.error")
+
+# cached replicas as (pod names, time of lookup)
+_replicas_cache: tuple[list[str], float] | None = None
+_lock = threading.Lock()
+
+
+def owns_shard(key: str) -> bool:
+    """Returns true if this replica is responsible for the given key, e.g., a Tetragon pod.
+
+    Keys are assigned to replicas with rendezvous hashing, so that only the keys of
+    replicas that were added or removed move to another replica.
+    """
+    if not KONEY_POD_NAME:
+        return True  # a single replica does all the work
+    return shard_owner(key, read_cached_replicas()) == KONEY_POD_NAME
+
+
+def shard_owner(key: str, replicas: list[str]) -> str:
+    """Returns the replica that is responsible for the given key."""
+    return max(replicas, key=lambda replica: _shard_weight(replica, key))
+
+
+def read_cached_replicas() -> list[str]:
+    """Returns the pod names of all ready replicas, at most once per cache period."""
+    global _replicas_cache
+
+    now = time.time()
+    with _lock:
+        if _replicas_cache:
+            replicas, looked_up_at = _replicas_cache
+            if now - looked_up_at < REPLICAS_CACHE_SECONDS:
+                return replicas
+
+    replicas = list_replicas()
+    with _lock:
+        if replicas is None:
+            # keep the last known replicas, or do all the work instead of missing events
+            replicas = _replicas_cache[0] if _replicas_cache else [KONEY_POD_NAME]
+        _replicas_cache = (replicas, now)
+    return replicas
+
+
+def list_replicas() -> list[str] | None:
+    """Lists the pod names of all ready replicas, or returns None if they cannot be listed."""
+    v1 = client.CoreV1Api()
+    try:
+        pod_list = cast(
+            client.V1PodList,
+            v1.list_namespaced_pod(
+                namespace=KONEY_NAMESPACE, label_selector=KONEY_REPLICA_LABEL_SELECTOR
+            ),
+        )
+    except ApiException as e:
+        logger.warning(f"Failed to list the replicas of the alert forwarder: {e}")
+        return None
+
+    replicas = {pod.metadata.name for pod in pod_list.items if _is_ready(pod)}
+    replicas.add(KONEY_POD_NAME)  # this replica might not be ready yet
+    return sorted(replicas)
+
+
+###############################################################################
+
+
+def _shard_weight(replica: str, key: str) -> int:
+    return int(md5(f"{replica}/{key}".encode("utf-8")).hexdigest(), 16)
+
+
+def _is_ready(pod: "client.V1Pod") -> bool:
+    if pod.metadata.deletion_timestamp:
+        return False  # terminating replicas hand over their work
+    conditions = (pod.status and pod.status.conditions) or []
+    return any(c.type == "Ready" and c.status == "True" for c in conditions)
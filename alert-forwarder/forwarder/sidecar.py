@@ -0,0 +1,85 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+
+from .types import *
+from .utils import (
+    build_response_metadata,
+    build_trap_metadata,
+    resolve_confidence,
+    resolve_severity,
+)
+
+# the metadata keys that store the origin and the alerting configuration of the trap
+SIDECAR_DECEPTION_POLICY_NAME_METADATA = "koney-deception-policy-name"
+SIDECAR_TRAP_TYPE_METADATA = "koney-trap-type"
+SIDECAR_FILE_PATH_METADATA = "koney-file-path"
+SIDECAR_ALERT_SEVERITY_METADATA = "koney-alert-severity"
+SIDECAR_ALERT_TAGS_METADATA = "koney-alert-tags"
+SIDECAR_SEVERITY_METADATA = "koney-severity"
+SIDECAR_CONFIDENCE_METADATA = "koney-confidence"
+SIDECAR_TRAP_HASH_METADATA = "koney-trap-hash"
+SIDECAR_TRAP_DESCRIPTION_METADATA = "koney-trap-description"
+SIDECAR_ATTCK_TECHNIQUES_METADATA = "koney-attck-techniques"
+SIDECAR_ENGAGE_ACTIVITIES_METADATA = "koney-engage-activities"
+SIDECAR_RESPONSE_ACTIONS_METADATA = "koney-response-actions"
+SIDECAR_QUARANTINE_TTL_METADATA = "koney-quarantine-ttl"
+
+
+def process_sidecar_alert(sidecarAlert: dict) -> KoneyAlert:
+    """Maps the report of a watcher sidecar to an alert. inotify does not tell which
+    process (or container of the pod) opened the decoy, so these are always unknown."""
+    metadata = sidecarAlert.get("metadata") or {}
+    pod = sidecarAlert.get("pod") or {}
+    tags_json = metadata.get(SIDECAR_ALERT_TAGS_METADATA)
+
+    koneyAlert = KoneyAlert(
+        schema_version=SCHEMA_VERSION,
+        timestamp=sidecarAlert["time"],
+        deception_policy_name=metadata.get(SIDECAR_DECEPTION_POLICY_NAME_METADATA),
+        trap_type=metadata.get(SIDECAR_TRAP_TYPE_METADATA, "filesystem_honeytoken"),
+        severity=resolve_severity(
+            metadata.get(SIDECAR_ALERT_SEVERITY_METADATA),
+            metadata.get(SIDECAR_SEVERITY_METADATA),
+        ),
+        confidence=resolve_confidence(metadata.get(SIDECAR_CONFIDENCE_METADATA)),
+        tags=json.loads(tags_json) if tags_json else {},
+        # the sidecar omits the generation, so that the pods are not rolled out on every change of the policy
+        trap=build_trap_metadata(
+            metadata.get(SIDECAR_TRAP_HASH_METADATA),
+            None,
+            metadata.get(SIDECAR_TRAP_DESCRIPTION_METADATA),
+            metadata.get(SIDECAR_ATTCK_TECHNIQUES_METADATA),
+            metadata.get(SIDECAR_ENGAGE_ACTIVITIES_METADATA),
+        ),
+        metadata={
+            "file_path": metadata.get(SIDECAR_FILE_PATH_METADATA),
+        },
+        pod=PodMetadata(
+            name=pod.get("name"),
+            namespace=pod.get("namespace"),
+            container=ContainerMetadata(id=None, name=None),
+        ),
+        node=NodeMetadata(
+            name=sidecarAlert.get("node"),
+        ),
+        process=None,
+        response=build_response_metadata(
+            metadata.get(SIDECAR_RESPONSE_ACTIONS_METADATA),
+            metadata.get(SIDECAR_QUARANTINE_TTL_METADATA),
+        ),
+    )
+    return koneyAlert
@@ -0,0 +1,236 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import re
+from datetime import datetime, timezone
+from pathlib import Path
+
+from .alerts import create_alert_description, create_alert_id
+from .types import KoneyAlert
+
+# the device that is reported in CEF and LEEF headers
+SIEM_DEVICE_VENDOR = "Dynatrace Research"
+SIEM_DEVICE_PRODUCT = "Koney"
+SIEM_DEVICE_VERSION = "1.0"
+
+# severities of alerts on the scale of CEF and LEEF (0 to 10)
+SIEM_SEVERITIES = {
+    "info": 1,
+    "low": 3,
+    "medium": 5,
+    "high": 8,
+    "critical": 10,
+}
+SIEM_DEFAULT_SEVERITY = 5
+
+# syslog facilities (RFC 5424) and the syslog severities of alerts
+SYSLOG_FACILITIES = {
+    "kern": 0,
+    "user": 1,
+    "mail": 2,
+    "daemon": 3,
+    "auth": 4,
+    "syslog": 5,
+    "lpr": 6,
+    "news": 7,
+    "uucp": 8,
+    "cron": 9,
+    "authpriv": 10,
+    "ftp": 11,
+    **{f"local{i}": 16 + i for i in range(8)},
+}
+SYSLOG_SEVERITIES = {
+    "info": 6,  # informational
+    "low": 5,  # notice
+    "medium": 4,  # warning
+    "high": 3,  # error
+    "critical": 2,  # critical
+}
+SYSLOG_DEFAULT_SEVERITY = 4
+SYSLOG_APP_NAME = "koney"
+
+
+def format_cef(koney_alert: KoneyAlert) -> str:
+    """Formats an alert in the Common Event Format (CEF) of ArcSight."""
+    fields = _extract_siem_fields(koney_alert)
+
+    header = "|".join(
+        [
+            "CEF:0",
+            _escape_cef_header(SIEM_DEVICE_VENDOR),
+            _escape_cef_header(SIEM_DEVICE_PRODUCT),
+            _escape_cef_header(SIEM_DEVICE_VERSION),
+            _escape_cef_header(koney_alert["trap_type"]),
+            _escape_cef_header(create_alert_description(koney_alert)),
+            str(_siem_severity(koney_alert)),
+        ]
+    )
+
+    extensions = {
+        "rt": fields["time_ms"],
+        "externalId": create_alert_id(koney_alert),
+        "msg": create_alert_description(koney_alert),
+        "dvchost": fields["node"],
+        "shost": fields["pod"],
+        "sproc": fields["process_name"],
+        "spid": fields["pid"],
+        "suid": fields["uid"],
+        "filePath": fields["file_path"],
+        "cs1Label": "deceptionPolicy",
+        "cs1": fields["deception_policy_name"],
+        "cs2Label": "namespace",
+        "cs2": fields["namespace"],
+        "cs3Label": "container",
+        "cs3": fields["container"],
+        "cs4Label": "commandLine",
+        "cs4": fields["command_line"],
+        "cs5Label": "attckTechniques",
+        "cs5": fields["attck_techniques"],
+        "cs6Label": "confidence",
+        "cs6": fields["confidence"],
+    }
+    extension = " ".join(
+        f"{key}={_escape_cef_extension(str(value))}"
+        for key, value in extensions.items()
+        if value not in (None, "")
+    )
+
+    return f"{header}|{extension}"
+
+
+def format_leef(koney_alert: KoneyAlert) -> str:
+    """Formats an alert in the Log Event Extended Format (LEEF 1.0) of QRadar."""
+    fields = _extract_siem_fields(koney_alert)
+
+    header = "|".join(
+        [
+            "LEEF:1.0",
+            _escape_leef_header(SIEM_DEVICE_VENDOR),
+            _escape_leef_header(SIEM_DEVICE_PRODUCT),
+            _escape_leef_header(SIEM_DEVICE_VERSION),
+            _escape_leef_header(koney_alert["trap_type"]),
+        ]
+    )
+
+    attributes = {
+        "devTime": _format_leef_time(fields["time"]),
+        "cat": koney_alert["trap_type"],
+        "sev": _siem_severity(koney_alert),
+        "externalId": create_alert_id(koney_alert),
+        "msg": create_alert_description(koney_alert),
+        "identHostName": fields["node"],
+        "deceptionPolicy": fields["deception_policy_name"],
+        "namespace": fields["namespace"],
+        "pod": fields["pod"],
+        "container": fields["container"],
+        "process": fields["process_name"],
+        "pid": fields["pid"],
+        "uid": fields["uid"],
+        "commandLine": fields["command_line"],
+        "filePath": fields["file_path"],
+        "attckTechniques": fields["attck_techniques"],
+        "confidence": fields["confidence"],
+    }
+    attribute = "\t".join(
+        f"{key}={_escape_leef_attribute(str(value))}"
+        for key, value in attributes.items()
+        if value not in (None, "")
+    )
+
+    return f"{header}|{attribute}"
+
+
+def format_syslog_message(
+    koney_alert: KoneyAlert, message: str, facility: str, hostname: str
+) -> str:
+    """Wraps a message in a BSD syslog frame (RFC 3164), which classic SIEMs expect."""
+    severity = (koney_alert.get("severity") or "").lower()
+    priority = SYSLOG_FACILITIES.get(facility, SYSLOG_FACILITIES["local0"]) * 8
+    priority += SYSLOG_SEVERITIES.get(severity, SYSLOG_DEFAULT_SEVERITY)
+
+    timestamp = parse_timestamp(koney_alert["timestamp"])
+    # RFC 3164 pads the day with a space instead of a zero
+    syslog_time = f"{timestamp:%b} {timestamp.day:2d} {timestamp:%H:%M:%S}"
+
+    return f"<{priority}>{syslog_time} {hostname} {SYSLOG_APP_NAME}: {message}"
+
+
+def parse_timestamp(timestamp: str) -> datetime:
+    # Tetragon reports nanoseconds, but Python only parses up to microseconds
+    timestamp = re.sub(r"(\.\d{6})\d+", r"\1", timestamp).replace("Z", "+00:00")
+    parsed = datetime.fromisoformat(timestamp)
+    if parsed.tzinfo is None:
+        parsed = parsed.replace(tzinfo=timezone.utc)
+    return parsed.astimezone(timezone.utc)
+
+
+###############################################################################
+
+
+def _extract_siem_fields(koney_alert: KoneyAlert) -> dict:
+    pod_dict = koney_alert.get("pod", {}) or {}
+    node_dict = koney_alert.get("node", {}) or {}
+    process_dict = koney_alert.get("process", {}) or {}
+    trap_dict = koney_alert.get("trap", {}) or {}
+
+    binary = process_dict.get("binary") or ""
+    command_line = " ".join(filter(None, [binary, process_dict.get("arguments")]))
+    timestamp = parse_timestamp(koney_alert["timestamp"])
+
+    return {
+        "time": timestamp,
+        "time_ms": int(timestamp.timestamp() * 1000),
+        "deception_policy_name": koney_alert.get("deception_policy_name"),
+        "namespace": pod_dict.get("namespace"),
+        "pod": pod_dict.get("name"),
+        "container": (pod_dict.get("container", {}) or {}).get("name"),
+        "node": node_dict.get("name"),
+        "process_name": Path(binary).name if binary else None,
+        "pid": process_dict.get("pid"),
+        "uid": process_dict.get("uid"),
+        "command_line": command_line,
+        "file_path": (koney_alert.get("metadata", {}) or {}).get("file_path"),
+        "attck_techniques": ",".join(trap_dict.get("attck_techniques") or []),
+        "confidence": koney_alert.get("confidence"),
+    }
+
+
+def _siem_severity(koney_alert: KoneyAlert) -> int:
+    severity = (koney_alert.get("severity") or "").lower()
+    return SIEM_SEVERITIES.get(severity, SIEM_DEFAULT_SEVERITY)
+
+
+def _escape_cef_header(value: str) -> str:
+    return value.replace("\\", "\\\\").replace("|", "\\|")
+
+
+def _escape_cef_extension(value: str) -> str:
+    value = value.replace("\\", "\\\\").replace("=", "\\=")
+    return value.replace("\r", "\\r").replace("\n", "\\n")
+
+
+def _format_leef_time(timestamp: datetime) -> str:
+    # the default time format of LEEF is "MMM dd yyyy HH:mm:ss.SSS zzz"
+    milliseconds = timestamp.microsecond // 1000
+    return f"{timestamp:%b %d %Y %H:%M:%S}.{milliseconds:03d} UTC"
+
+
+def _escape_leef_header(value: str) -> str:
+    return value.replace("|", "\\|")
+
+
+def _escape_leef_attribute(value: str) -> str:
+    # tabs separate attributes and line breaks separate syslog messages
+    return re.sub(r"[\t\r\n]", " ", value)
@@ -14,18 +14,43 @@
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
 import base64
+import fnmatch
 import logging
 import os
+import socket
+import ssl
+import threading
+import time
+from collections import OrderedDict, deque
 from functools import cache
 from typing import cast
 
 import requests
 from kubernetes import client
 from kubernetes.client.exceptions import ApiException
-from rich.console import Console
 
-from .alerts import map_to_dynatrace_event
-from .types import AlertSink, DynatraceSink, KoneyAlert
+from .alerts import (
+    create_alert_id,
+    map_to_dynatrace_event,
+    map_to_dynatrace_log,
+    map_to_dynatrace_v2_event,
+    map_to_slack_message,
+    map_to_teams_message,
+)
+from .offsets import load_delivered_alert_ids, save_delivered_alert_ids
+from .siem import format_cef, format_leef, format_syslog_message
+from .types import (
+    AlertSink,
+    AlertSinkMatch,
+    ChatSink,
+    DynatraceSink,
+    KoneyAlert,
+    RedeliveryPolicy,
+    SyslogSink,
+    WebhookRetryPolicy,
+    WebhookSink,
+)
+from .utils import parse_duration
 
 # Errors
 K8S_SINK_READ_ERROR = "failed to read DeceptionAlertSink objects"
@@ -42,11 +67,56 @@ KONEY_DECEPTION_ALERT_SINK_GVNP = (
     "deceptionalertsinks",
 )
 
+# the endpoints of the Dynatrace APIs, and how alerts are mapped to their payloads
+DYNATRACE_APIS = {
+    "securityEvents": ("/platform/ingest/v1/security.events", map_to_dynatrace_event),
+    "events": ("/api/v2/events/ingest", map_to_dynatrace_v2_event),
+    "logs": ("/api/v2/logs/ingest", map_to_dynatrace_log),
+}
+
+# how chat messages are formatted, per provider
+CHAT_PROVIDERS = {
+    "slack": map_to_slack_message,
+    "teams": map_to_teams_message,
+}
+# how many messages are posted to a chat per minute, unless the sink configures it
+CHAT_DEFAULT_MAX_MESSAGES_PER_MINUTE = 10
+
+# how alerts are formatted in syslog messages, per format
+SYSLOG_FORMATS = {
+    "cef": format_cef,
+    "leef": format_leef,
+}
+# the default ports of the syslog protocols
+SYSLOG_DEFAULT_PORTS = {
+    "udp": 514,
+    "tcp": 514,
+    "tls": 6514,
+}
+
 # number of seconds after we timeout requests to external systems
 SINK_REQUEST_TIMEOUT = 25
+# how often and how fast requests to webhooks are retried, unless the sink configures it
+WEBHOOK_DEFAULT_MAX_RETRIES = 3
+WEBHOOK_DEFAULT_INITIAL_BACKOFF = "1s"
+WEBHOOK_DEFAULT_MAX_BACKOFF = "30s"
+# how often and how fast alerts that failed to deliver are redelivered, unless the sink configures it
+REDELIVERY_DEFAULT_MAX_ATTEMPTS = 10
+REDELIVERY_DEFAULT_INITIAL_BACKOFF = "30s"
+REDELIVERY_DEFAULT_MAX_BACKOFF = "1h"
+# number of alert ids that are remembered per sink to not deliver alerts twice when they are retried
+SINK_DELIVERED_CACHE_SIZE = 10000
 
 logger = logging.getLogger("uvicorn.error")
-console = Console()
+
+# ids of the alerts that were delivered, per sink, which are loaded from the config map on first use
+_delivered_alerts: dict[str, OrderedDict[str, None]] | None = None
+_delivered_lock = threading.Lock()
+
+# times of the recently posted messages and number of suppressed alerts, per chat sink
+_chat_messages: dict[str, deque[float]] = {}
+_chat_suppressed: dict[str, int] = {}
+_chat_lock = threading.Lock()
 
 
 def read_alert_sinks() -> list[AlertSink]:
@@ -57,7 +127,12 @@ def read_alert_sinks() -> list[AlertSink]:
     for obj in objs.get("items", []):
         alert_sink = AlertSink(
             name=obj.get("metadata", {}).get("name"),
+            match=_extract_alert_sink_match(obj),
+            redelivery=_extract_redelivery_policy(obj),
             dynatrace_sink=_extract_dynatrace_sink(obj),
+            webhook_sink=_extract_webhook_sink(obj),
+            chat_sink=_extract_chat_sink(obj),
+            syslog_sink=_extract_syslog_sink(obj),
         )
         alert_sinks.append(alert_sink)
 
@@ -69,26 +144,81 @@ def try_read_alert_sinks():
         alert_sinks = read_alert_sinks()
         return alert_sinks
     except:
-        if logger.level <= logging.ERROR:
-            console.print(K8S_SINK_READ_ERROR, style="bold red")
-            console.print_exception()
+        logger.exception(K8S_SINK_READ_ERROR)
         return []
 
 
+def alert_matches_sink(koney_alert: KoneyAlert, sink: AlertSink) -> bool:
+    """
+    Checks if an alert is routed to a sink. An alert matches if it matches all fields
+    of the sink's match, and it matches a field if it matches any of its values.
+    """
+    match = sink["match"]
+    if not match:
+        return True
+
+    pod = koney_alert.get("pod") or {}
+    policy_name = koney_alert["deception_policy_name"]
+    return (
+        _matches_any(policy_name, match["deception_policy_names"])
+        and _matches_any(koney_alert.get("trap_type"), match["trap_types"])
+        and _matches_any(pod.get("namespace"), match["namespaces"])
+        and _matches_any(koney_alert.get("severity"), match["severities"])
+    )
+
+
+def _matches_any(value: str | None, patterns: list[str]) -> bool:
+    if not patterns:
+        return True
+    if value is None:
+        return False
+    return any(fnmatch.fnmatchcase(value, pattern) for pattern in patterns)
+
+
+def send_alert_once(koney_alert: KoneyAlert, sink: AlertSink) -> bool:
+    """
+    Sends an alert to a sink, unless the sink already received an alert with the same id.
+    Alert ids are derived from the alert content, so retried alerts are not delivered twice.
+    The ids are persisted right after the delivery, so this also holds across restarts,
+    except if the forwarder stops between the delivery and persisting its id (at-least-once).
+    Returns true if the alert was sent, and false if it was delivered before.
+    """
+    alert_id = create_alert_id(koney_alert)
+    with _delivered_lock:
+        if alert_id in _get_delivered_alerts().get(sink["name"], {}):
+            return False
+
+    send_alert(koney_alert, sink)
+
+    with _delivered_lock:
+        delivered = _get_delivered_alerts().setdefault(sink["name"], OrderedDict())
+        delivered[alert_id] = None
+        if len(delivered) > SINK_DELIVERED_CACHE_SIZE:
+            delivered.popitem(last=False)
+    save_delivered_alert_ids(sink["name"], [alert_id])
+
+    return True
+
+
 def send_alert(koney_alert: KoneyAlert, sink: AlertSink) -> None:
-    cluster_uid = _get_cluster_uid()
+    # the uid of this cluster does not identify the clusters that posted alerts to it
+    cluster_uid = None if koney_alert.get("cluster") else _get_cluster_uid()
 
     if sink["dynatrace_sink"]:
-        api_url = sink["dynatrace_sink"]["api_url"]
-        api_token = sink["dynatrace_sink"]["api_token"]
-        severity = sink["dynatrace_sink"]["severity"]
+        dynatrace_sink = sink["dynatrace_sink"]
+        api_url = dynatrace_sink["api_url"]
+        api_token = dynatrace_sink["api_token"]
+        # the severity of the trap takes precedence over the one of the sink
+        severity = koney_alert.get("severity") or dynatrace_sink["severity"]
 
-        payload = map_to_dynatrace_event(koney_alert, severity, cluster_uid)
-        if logger.level <= logging.DEBUG:
-            console.print("Sending alert to Dynatrace:", payload)
+        endpoint, map_to_payload = DYNATRACE_APIS[dynatrace_sink["api"]]
+        payload = map_to_payload(
+            koney_alert, severity, cluster_uid, dynatrace_sink["security_context"]
+        )
+        logger.debug("Sending alert to Dynatrace", extra=dict(payload=payload))
 
         resp = requests.post(
-            f"{api_url}/platform/ingest/v1/security.events",
+            f"{api_url}{endpoint}",
             json=payload,
             timeout=SINK_REQUEST_TIMEOUT,
             headers={
@@ -98,15 +228,173 @@ def send_alert(koney_alert: KoneyAlert, sink: AlertSink) -> None:
         )
 
         # check response status
-        if resp.status_code != 202:
+        if not 200 <= resp.status_code < 300:
             raise RuntimeError(
                 f"failed to send alert to Dynatrace: {resp.status_code} {resp.text}"
             )
 
+    if sink["webhook_sink"]:
+        logger.debug(
+            "Sending alert to webhook", extra=dict(url=sink["webhook_sink"]["url"])
+        )
+
+        _send_to_webhook(koney_alert, sink["webhook_sink"])
+
+    if sink["chat_sink"]:
+        _send_to_chat(koney_alert, sink["name"], sink["chat_sink"])
+
+    if sink["syslog_sink"]:
+        _send_to_syslog(koney_alert, sink["syslog_sink"])
+
+
+def _send_to_syslog(koney_alert: KoneyAlert, syslog_sink: SyslogSink) -> None:
+    message = SYSLOG_FORMATS[syslog_sink["format"]](koney_alert)
+    frame = format_syslog_message(
+        koney_alert, message, syslog_sink["facility"], socket.gethostname()
+    )
+    data = frame.encode("utf-8")
+    address = (syslog_sink["host"], syslog_sink["port"])
+
+    logger.debug("Sending alert to syslog", extra=dict(frame=frame))
+
+    if syslog_sink["protocol"] == "udp":
+        with socket.socket(socket.AF_INET, socket.SOCK_DGRAM) as sock:
+            sock.sendto(data, address)
+        return
+
+    # messages over TCP are separated by line breaks (non-transparent framing)
+    with socket.create_connection(address, timeout=SINK_REQUEST_TIMEOUT) as sock:
+        if syslog_sink["protocol"] == "tls":
+            context = ssl.create_default_context(cadata=syslog_sink["ca_cert"])
+            with context.wrap_socket(sock, server_hostname=syslog_sink["host"]) as tls:
+                tls.sendall(data + b"\n")
+        else:
+            sock.sendall(data + b"\n")
+
+
+def _send_to_chat(koney_alert: KoneyAlert, sink_name: str, chat_sink: ChatSink):
+    suppressed = _acquire_chat_message(sink_name, chat_sink["max_messages_per_minute"])
+    if suppressed is None:
+        logger.debug("Skipping chat message (rate limit)")
+        return
+
+    payload = CHAT_PROVIDERS[chat_sink["provider"]](koney_alert, suppressed)
+    try:
+        resp = requests.post(
+            chat_sink["webhook_url"], json=payload, timeout=SINK_REQUEST_TIMEOUT
+        )
+        if not 200 <= resp.status_code < 300:
+            raise RuntimeError(
+                f"failed to send alert to chat: {resp.status_code} {resp.text}"
+            )
+    except:
+        _release_chat_message(sink_name, suppressed)
+        raise
+
+
+def _acquire_chat_message(sink_name: str, max_messages_per_minute: int) -> int | None:
+    """
+    Reserves a message in the rate limit of a chat sink (a sliding window of one minute).
+    Returns the number of alerts that were suppressed since the last message,
+    or None if the rate limit is exceeded and the alert must be suppressed.
+    """
+    now = time.monotonic()
+    with _chat_lock:
+        messages = _chat_messages.setdefault(sink_name, deque())
+        while messages and now - messages[0] >= 60:
+            messages.popleft()
+
+        if len(messages) >= max_messages_per_minute:
+            _chat_suppressed[sink_name] = _chat_suppressed.get(sink_name, 0) + 1
+            return None
+
+        messages.append(now)
+        return _chat_suppressed.pop(sink_name, 0)
+
+
+def _release_chat_message(sink_name: str, suppressed: int) -> None:
+    # the message was not posted, so it neither counts against the rate limit
+    # nor did it report the suppressed alerts
+    with _chat_lock:
+        if messages := _chat_messages.get(sink_name):
+            messages.pop()
+        _chat_suppressed[sink_name] = _chat_suppressed.get(sink_name, 0) + suppressed
+
+
+def _send_to_webhook(koney_alert: KoneyAlert, webhook_sink: WebhookSink) -> None:
+    retry = webhook_sink["retry"]
+    backoff = retry["initial_backoff"]
+
+    for attempt in range(retry["max_retries"] + 1):
+        if attempt > 0:
+            time.sleep(min(backoff, retry["max_backoff"]))
+            backoff *= 2
+
+        try:
+            # receivers that support idempotency keys drop alerts that are delivered again
+            # (e.g., if the forwarder stopped before it persisted their delivery)
+            resp = requests.post(
+                webhook_sink["url"],
+                json=koney_alert,
+                timeout=SINK_REQUEST_TIMEOUT,
+                headers={
+                    "Content-Type": "application/json",
+                    "Idempotency-Key": create_alert_id(koney_alert),
+                    **webhook_sink["headers"],
+                },
+            )
+        except requests.RequestException as e:
+            error = str(e)
+            continue
+
+        if 200 <= resp.status_code < 300:
+            return
+        error = f"{resp.status_code} {resp.text}"
+        if resp.status_code != 429 and resp.status_code < 500:
+            break  # the request is invalid, so retrying it does not help
+
+    raise RuntimeError(f"failed to send alert to webhook: {error}")
+
 
 ###############################################################################
 
 
+def _get_delivered_alerts() -> dict[str, OrderedDict[str, None]]:
+    global _delivered_alerts
+    if _delivered_alerts is None:
+        _delivered_alerts = {
+            sink_name: OrderedDict.fromkeys(alert_ids)
+            for sink_name, alert_ids in load_delivered_alert_ids().items()
+        }
+    return _delivered_alerts
+
+
+def _extract_alert_sink_match(obj: dict) -> AlertSinkMatch | None:
+    spec = obj.get("spec", {}).get("match")
+    if not spec:
+        return None
+
+    return AlertSinkMatch(
+        deception_policy_names=spec.get("deceptionPolicyNames", []),
+        trap_types=spec.get("trapTypes", []),
+        namespaces=spec.get("namespaces", []),
+        severities=spec.get("severities", []),
+    )
+
+
+def _extract_redelivery_policy(obj: dict) -> RedeliveryPolicy:
+    spec = obj.get("spec", {}).get("redelivery") or {}
+    return RedeliveryPolicy(
+        max_attempts=spec.get("maxAttempts", REDELIVERY_DEFAULT_MAX_ATTEMPTS),
+        initial_backoff=parse_duration(
+            spec.get("initialBackoff", REDELIVERY_DEFAULT_INITIAL_BACKOFF)
+        ),
+        max_backoff=parse_duration(
+            spec.get("maxBackoff", REDELIVERY_DEFAULT_MAX_BACKOFF)
+        ),
+    )
+
+
 def _extract_dynatrace_sink(obj: dict) -> DynatraceSink | None:
     if spec := obj.get("spec", {}).get("dynatrace"):
         if secret_name := spec.get("secretName"):
@@ -114,10 +402,85 @@ def _extract_dynatrace_sink(obj: dict) -> DynatraceSink | None:
                 return DynatraceSink(
                     api_url=secret["apiUrl"],
                     api_token=secret["apiToken"],
-                    severity=obj["spec"]["dynatrace"]["severity"],
+                    severity=spec.get("severity", "HIGH"),
+                    api=spec.get("api", "securityEvents"),
+                    security_context=spec.get("securityContext"),
                 )
 
 
+def _extract_webhook_sink(obj: dict) -> WebhookSink | None:
+    spec = obj.get("spec", {}).get("webhook")
+    if not spec or not spec.get("url"):
+        return None
+
+    headers = dict(spec.get("headers") or {})
+    if secret_name := spec.get("secretName"):
+        secret = _get_decoded_secret_data(secret_name)
+        if not secret:
+            return None  # do not send alerts without the configured credentials
+        if token := secret.get("token"):
+            headers["Authorization"] = f"Bearer {token}"
+        elif "username" in secret and "password" in secret:
+            credentials = f"{secret['username']}:{secret['password']}".encode("utf-8")
+            headers["Authorization"] = f"Basic {base64.b64encode(credentials).decode()}"
+
+    retry = spec.get("retry") or {}
+    return WebhookSink(
+        url=spec["url"],
+        headers=headers,
+        retry=WebhookRetryPolicy(
+            max_retries=retry.get("maxRetries", WEBHOOK_DEFAULT_MAX_RETRIES),
+            initial_backoff=parse_duration(
+                retry.get("initialBackoff", WEBHOOK_DEFAULT_INITIAL_BACKOFF)
+            ),
+            max_backoff=parse_duration(
+                retry.get("maxBackoff", WEBHOOK_DEFAULT_MAX_BACKOFF)
+            ),
+        ),
+    )
+
+
+def _extract_chat_sink(obj: dict) -> ChatSink | None:
+    spec = obj.get("spec", {}).get("chat")
+    if not spec or spec.get("provider") not in CHAT_PROVIDERS:
+        return None
+
+    if secret_name := spec.get("secretName"):
+        if secret := _get_decoded_secret_data(secret_name):
+            if webhook_url := secret.get("webhookUrl"):
+                return ChatSink(
+                    provider=spec["provider"],
+                    webhook_url=webhook_url,
+                    max_messages_per_minute=spec.get(
+                        "maxMessagesPerMinute", CHAT_DEFAULT_MAX_MESSAGES_PER_MINUTE
+                    ),
+                )
+    return None
+
+
+def _extract_syslog_sink(obj: dict) -> SyslogSink | None:
+    spec = obj.get("spec", {}).get("syslog")
+    if not spec or not spec.get("host"):
+        return None
+
+    protocol = spec.get("protocol", "udp")
+    ca_cert = None
+    if protocol == "tls" and (secret_name := spec.get("secretName")):
+        secret = _get_decoded_secret_data(secret_name)
+        if not secret or not secret.get("ca.crt"):
+            return None  # do not send alerts to servers that cannot be verified
+        ca_cert = secret["ca.crt"]
+
+    return SyslogSink(
+        host=spec["host"],
+        port=spec.get("port", SYSLOG_DEFAULT_PORTS[protocol]),
+        protocol=protocol,
+        format=spec.get("format", "cef"),
+        facility=spec.get("facility", "local0"),
+        ca_cert=ca_cert,
+    )
+
+
 def _get_decoded_secret_data(secret_name: str) -> dict | None:
     api = client.CoreV1Api()
     try:
@@ -127,18 +490,12 @@ def _get_decoded_secret_data(secret_name: str) -> dict | None:
         )
     except ApiException as e:
         if e.status and e.status == 404:
-            if logger.level <= logging.ERROR:
-                console.print(
-                    f"Secret '{secret_name}' not found in namespace '{KONEY_NAMESPACE}': {e}",
-                    style="bold red",
-                )
+            logger.error(
+                f"Secret '{secret_name}' not found in namespace '{KONEY_NAMESPACE}': {e}"
+            )
             return None
         elif e.status and e.status >= 500:
-            if logger.level <= logging.WARNING:
-                console.print(
-                    f"Failed to read secret '{secret_name}': {e}",
-                    style="bold yellow",
-                )
+            logger.warning(f"Failed to read secret '{secret_name}': {e}")
             return None
         raise
 
@@ -161,11 +518,7 @@ def _get_cluster_uid() -> str | None:
         namespace = cast(client.V1Namespace, api.read_namespace("kube-system"))
     except ApiException as e:
         if e.status and e.status >= 500:
-            if logger.level <= logging.WARNING:
-                console.print(
-                    f"Failed to read kube-system namespace: {e}",
-                    style="bold yellow",
-                )
+            logger.warning(f"Failed to read kube-system namespace: {e}")
             return None
         raise
     if not namespace.metadata or not namespace.metadata.uid:
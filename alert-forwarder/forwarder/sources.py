@@ -0,0 +1,54 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+from typing import Protocol
+
+
+class EventSource(Protocol):
+    """A source of raw Tetragon events, as JSON lines, that are split into streams.
+
+    Each stream (e.g., the logs of one Tetragon pod) has its own high-watermark,
+    so the same pipeline can process events from logs, files, or other exporters.
+    """
+
+    def list_streams(self) -> list[str]:
+        """Returns the names of all streams that currently exist."""
+        ...
+
+    def read_lines(self, stream: str, since_seconds: int) -> list[str] | None:
+        """Returns the lines of a stream from the last seconds, or None if the stream cannot be read right now."""
+        ...
+
+
+class InMemoryEventSource:
+    """An event source that serves lines from memory, e.g., for tests.
+
+    Lines are returned regardless of their age, i.e., since_seconds is ignored,
+    so the high-watermarks alone decide whether events were processed before.
+    """
+
+    def __init__(self, streams: dict[str, list[str]] | None = None):
+        self.streams: dict[str, list[str]] = streams or {}
+
+    def append(self, stream: str, *lines: str) -> None:
+        self.streams.setdefault(stream, []).extend(lines)
+
+    def list_streams(self) -> list[str]:
+        return list(self.streams)
+
+    def read_lines(self, stream: str, since_seconds: int) -> list[str] | None:
+        if stream not in self.streams:
+            return None
+        return list(self.streams[stream])
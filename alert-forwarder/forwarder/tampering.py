@@ -0,0 +1,51 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+from .sidecar import process_sidecar_alert
+from .types import *
+
+# the metadata key of alerts about tampered decoys, which holds the reason
+TAMPERING_METADATA = "decoy_tampered"
+
+
+def process_tampering_alert(tampering: dict) -> KoneyAlert:
+    """
+    Maps the report of the controller about a tampered decoy (i.e., a decoy that was
+    changed or removed from a container) to an alert. The controller passes the metadata
+    of the trap with the same keys as to watcher sidecars, so that the alert is routed
+    like the accesses of the trap. The pod is only known for decoys in pods.
+    """
+    kind = tampering.get("kind")
+    name = tampering.get("name")
+    koney_alert = process_sidecar_alert(
+        {
+            "time": tampering["time"],
+            "metadata": tampering.get("metadata"),
+            "pod": {
+                "name": name if kind == "Pod" else None,
+                "namespace": tampering.get("namespace"),
+            },
+            "node": None,
+        }
+    )
+
+    pod = koney_alert["pod"]
+    pod["container"]["name"] = tampering.get("container")
+    if kind != "Pod" and kind and name:
+        pod["owner"] = OwnerMetadata(kind=kind, name=name)
+    koney_alert["metadata"][TAMPERING_METADATA] = tampering.get("reason")
+    # the decoy is deployed again, and the pod that tampered with it is unknown
+    koney_alert["response"] = None
+    return koney_alert
@@ -16,80 +16,279 @@
 import fnmatch
 import json
 import logging
+import math
+import os
 import re
+import threading
+import time
 from collections import defaultdict
-from typing import cast
+from datetime import datetime, timedelta, timezone
+from typing import NamedTuple, cast
 
 from kubernetes import client
 from kubernetes.client.exceptions import ApiException
-from rich.console import Console
 
+from .dedup import EVENT_CACHE_MAX_SIZE, EVENT_CACHE_TTL_SECONDS, EventCache
+from .features import (
+    PROCESS_ANCESTRY_ENRICHMENT_FEATURE,
+    is_feature_enabled,
+    read_cached_feature_flags,
+)
 from .fingerprint import (
-    KONEY_FINGERPRINT,
     encode_fingerprint_in_cat,
     encode_fingerprint_in_echo,
+    read_cached_fingerprint,
 )
+from .informer import CustomObjectInformer
+from .mappers import map_event
+from .offsets import Watermark, advance, hash_event, is_processed, seconds_since
+from .sharding import owns_shard
+from .sources import EventSource
 from .types import (
+    SCHEMA_VERSION,
+    AlertingMetadata,
+    AncestorProcessMetadata,
     ContainerMetadata,
+    CredentialsMetadata,
     KoneyAlert,
+    NamespaceMetadata,
     NodeMetadata,
     PodMetadata,
     ProcessMetadata,
 )
-from .utils import _normalize_container_id
+from .utils import (
+    _normalize_container_id,
+    build_response_metadata,
+    build_trap_metadata,
+    resolve_confidence,
+    resolve_severity,
+)
 
 # group, version, plural of the Tetragon TracingPolicy CRD
 TETRAGON_TRACING_POLICIES_GVP = "cilium.io", "v1alpha1", "tracingpolicies"
+# group, version, plural of the Tetragon TracingPolicyNamespaced CRD, which Koney uses
+# for traps that only target specific namespaces (with the same name in each namespace)
+TETRAGON_TRACING_POLICIES_NAMESPACED_GVP = (
+    "cilium.io",
+    "v1alpha1",
+    "tracingpoliciesnamespaced",
+)
 
 # the namespace where Tetragon is assumed to be running
 TETRAGON_NAMESPACE = "kube-system"
-# all tracing policies created by Koney have this prefix
+# all tracing policies created by Koney have this prefix (by default)
 TETRAGON_POLICY_PREFIX = "koney-tracing-policy-"
+# additional, comma-separated prefixes of tracing policies that should be considered
+TETRAGON_EXTRA_POLICY_PREFIXES = [
+    prefix.strip()
+    for prefix in os.environ.get("KONEY_TRACING_POLICY_PREFIXES", "").split(",")
+    if prefix.strip()
+]
 # the label selector to find Tetragon pods
 TETRAGON_POD_LABEL_SELECTOR = "app.kubernetes.io/name=tetragon"
 # the container name where Tetragon logs are written
 TETRAGON_POD_CONTAINER_NAME = "export-stdout"
 # the label key that references the deception policy in a tracing policy
 TETRAGON_DECEPTION_POLICY_REF = "koney/deception-policy"
+# the label keys that store the severity and confidence of the trap
+TETRAGON_SEVERITY_LABEL = "koney/severity"
+TETRAGON_CONFIDENCE_LABEL = "koney/confidence"
 # the annotation key that stores the original container selectors for client-side filtering
 TETRAGON_CONTAINER_SELECTORS_ANNOTATION = "koney/container-selectors"
+# the annotation keys that store the alerting configuration of the trap
+TETRAGON_ALERT_SEVERITY_ANNOTATION = "koney/alert-severity"
+TETRAGON_ALERT_TAGS_ANNOTATION = "koney/alert-tags"
+# the annotation keys that store the trap type, which decides how the events are interpreted,
+# and the file path of the trap, which attributes alerts to the exact trap of the policy
+TETRAGON_TRAP_TYPE_ANNOTATION = "koney/trap-type"
+TETRAGON_FILE_PATH_ANNOTATION = "koney/file-path"
+# the annotation keys that store the origin of the trap
+TETRAGON_TRAP_HASH_ANNOTATION = "koney/trap-hash"
+TETRAGON_TRAP_DESCRIPTION_ANNOTATION = "koney/trap-description"
+TETRAGON_DECEPTION_POLICY_GENERATION_ANNOTATION = "koney/deception-policy-generation"
+# the annotation keys that store the MITRE ATT&CK techniques and Engage activities of the trap
+TETRAGON_ATTCK_TECHNIQUES_ANNOTATION = "koney/attck-techniques"
+TETRAGON_ENGAGE_ACTIVITIES_ANNOTATION = "koney/engage-activities"
+# the annotation keys that store the response actions of the trap
+TETRAGON_RESPONSE_ACTIONS_ANNOTATION = "koney/response-actions"
+TETRAGON_QUARANTINE_TTL_ANNOTATION = "koney/quarantine-ttl"
+
+# how far the logs of Tetragon are read into the past on the first pass, later passes read
+# back to the start of the last complete pass, but at least and at most as far as follows
+LOG_WINDOW_SECONDS = int(os.environ.get("KONEY_LOG_WINDOW_SECONDS", "60"))
+# the minimum also covers log lines that Tetragon writes late, e.g., under load
+LOG_WINDOW_MIN_SECONDS = int(os.environ.get("KONEY_LOG_WINDOW_MIN_SECONDS", "15"))
+LOG_WINDOW_MAX_SECONDS = int(os.environ.get("KONEY_LOG_WINDOW_MAX_SECONDS", "3600"))
 
 logger = logging.getLogger("uvicorn.error")
-console = Console()
 
 # stores hashes of already processed events to prevent duplicates
-event_cache = set()
+event_cache = EventCache(EVENT_CACHE_MAX_SIZE, EVENT_CACHE_TTL_SECONDS)
+
+# caches the tracing policies, so that events are mapped without calling the Kubernetes API
+tracing_policy_informer = CustomObjectInformer(*TETRAGON_TRACING_POLICIES_GVP)
+tracing_policy_namespaced_informer = CustomObjectInformer(
+    *TETRAGON_TRACING_POLICIES_NAMESPACED_GVP, namespaced=True
+)
+
+
+class TetragonEvents(NamedTuple):
+    # the list of events (value) grouped by their policy name (key)
+    events_per_policy: dict[str, list[dict]]
+    # the high-watermarks per Tetragon pod after all events, to commit once they were forwarded
+    watermarks: dict[str, Watermark]
+    # the hashes of all events, to retry them if they could not be forwarded
+    event_hashes: set[str]
+    # all existing Tetragon pods, including the ones whose events other replicas read
+    streams: list[str] = []
+    # whether the logs of all Tetragon pods of this replica could be read
+    complete: bool = True
+
+
+class LogWindow:
+    """Decides how far the logs of Tetragon are read into the past. Passes read back to the
+    start of the last complete pass, so that they neither miss events when passes are far
+    apart (e.g., under load) nor read the same lines over and over when they are close."""
+
+    def __init__(
+        self,
+        initial_seconds: int = LOG_WINDOW_SECONDS,
+        min_seconds: int = LOG_WINDOW_MIN_SECONDS,
+        max_seconds: int = LOG_WINDOW_MAX_SECONDS,
+        clock=time.monotonic,
+    ):
+        self.initial_seconds = initial_seconds
+        self.min_seconds = min_seconds
+        self.max_seconds = max(min_seconds, max_seconds)
+        self._clock = clock
+        self._last_pass_start: float | None = None
+        self._lock = threading.Lock()
+
+    def start_pass(self) -> tuple[float, int]:
+        """Returns the start of a pass and how many seconds it should read into the past."""
+        now = self._clock()
+        with self._lock:
+            if self._last_pass_start is None:
+                seconds = self.initial_seconds
+            else:
+                seconds = now - self._last_pass_start + self.min_seconds
+        seconds = min(max(seconds, self.min_seconds), self.max_seconds)
+        return now, math.ceil(seconds)
+
+    def complete_pass(self, started_at: float) -> None:
+        """Records that all events since the window of a pass that started then were read."""
+        with self._lock:
+            if self._last_pass_start is None or started_at > self._last_pass_start:
+                self._last_pass_start = started_at
+
+
+class TracingPolicyMatcher:
+    """Decides whether Tetragon events originate from tracing policies created by Koney.
+
+    Tracing policies are matched by their exact names (as listed from the cluster)
+    or by any of the configured prefixes.
+    """
+
+    def __init__(self, names: set[str], prefixes: list[str]):
+        self.names = names
+        self.prefixes = prefixes
+
+    def could_match(self, line: str) -> bool:
+        # quick substring check on raw log lines before parsing them
+        return any(name in line for name in self.names) or any(
+            prefix in line for prefix in self.prefixes
+        )
+
+    def matches(self, policy_name: str) -> bool:
+        return policy_name in self.names or any(
+            policy_name.startswith(prefix) for prefix in self.prefixes
+        )
+
+
+def build_tracing_policy_matcher() -> TracingPolicyMatcher:
+    prefixes = [TETRAGON_POLICY_PREFIX, *TETRAGON_EXTRA_POLICY_PREFIXES]
+    return TracingPolicyMatcher(list_koney_tracing_policy_names(), prefixes)
 
 
-def read_tetragon_events(since_seconds=60) -> dict[str, list[dict]]:
-    v1 = client.CoreV1Api()
+def list_koney_tracing_policy_names() -> set[str]:
+    return _list_koney_tracing_policy_names(
+        tracing_policy_informer, TETRAGON_TRACING_POLICIES_GVP
+    ) | _list_koney_tracing_policy_names(
+        tracing_policy_namespaced_informer, TETRAGON_TRACING_POLICIES_NAMESPACED_GVP
+    )
+
+
+def _list_koney_tracing_policy_names(
+    informer: CustomObjectInformer, gvp: tuple[str, str, str]
+) -> set[str]:
+    # all tracing policies created by Koney reference their deception policy with a label
+    if (tracing_policies := informer.list_objects()) is not None:
+        return {
+            metadata["name"]
+            for obj in tracing_policies
+            if TETRAGON_DECEPTION_POLICY_REF
+            in ((metadata := obj["metadata"]).get("labels") or {})
+        }
 
     try:
-        pod_list = cast(
-            client.V1PodList,
-            v1.list_namespaced_pod(
-                namespace=TETRAGON_NAMESPACE,
-                label_selector=TETRAGON_POD_LABEL_SELECTOR,
+        api = client.CustomObjectsApi()
+        objs = cast(
+            dict,
+            api.list_cluster_custom_object(
+                *gvp,
+                label_selector=TETRAGON_DECEPTION_POLICY_REF,
             ),
         )
     except ApiException as e:
-        if e.status and e.status >= 500:
-            if logger.level <= logging.WARNING:
-                console.print(
-                    f"Failed to list Tetragon pods: {e}",
-                    style="bold yellow",
-                )
-            return {}
-        raise
+        logger.warning(f"Failed to list {gvp[2]}, matching by prefix only: {e}")
+        return set()
 
-    if not pod_list.items:
-        return {}  # no Tetragon pods found
+    return {
+        name
+        for obj in objs.get("items", [])
+        if (name := obj.get("metadata", {}).get("name"))
+    }
 
-    events_per_policy = defaultdict(list)
-    for pod in pod_list.items:
+
+def get_tracing_policy(tracing_policy_name: str) -> dict | None:
+    """Returns the tracing policy with the given name, which is either cluster-scoped or
+    namespaced. Namespaced tracing policies of the same trap are equal in all namespaces."""
+    if tracing_policy := tracing_policy_informer.get(tracing_policy_name):
+        return tracing_policy
+    return tracing_policy_namespaced_informer.get(tracing_policy_name)
+
+
+class KubernetesLogEventSource:
+    """Reads Tetragon events from the logs of the Tetragon pods, with one stream per pod."""
+
+    def list_streams(self) -> list[str]:
+        v1 = client.CoreV1Api()
         try:
+            pod_list = cast(
+                client.V1PodList,
+                v1.list_namespaced_pod(
+                    namespace=TETRAGON_NAMESPACE,
+                    label_selector=TETRAGON_POD_LABEL_SELECTOR,
+                ),
+            )
+        except ApiException as e:
+            if e.status and e.status >= 500:
+                logger.warning(f"Failed to list Tetragon pods: {e}")
+                return []
+            raise
+
+        return [pod.metadata.name for pod in pod_list.items]
+
+    def read_lines(self, stream: str, since_seconds: int) -> list[str] | None:
+        v1 = client.CoreV1Api()
+        try:
+            # if the container restarted within the window, its previous logs are needed, too
+            previous_lines = []
+            if self._restarted_within(stream, since_seconds):
+                previous_lines = self._read_previous_lines(stream, since_seconds)
+
             loglines = v1.read_namespaced_pod_log(
-                name=pod.metadata.name,
+                name=stream,
                 namespace=TETRAGON_NAMESPACE,
                 container=TETRAGON_POD_CONTAINER_NAME,
                 since_seconds=since_seconds,
@@ -97,90 +296,195 @@ def read_tetragon_events(since_seconds=60) -> dict[str, list[dict]]:
         except ApiException as e:
             if e.status and e.status == 404:
                 # pod might have been deleted in the meantime
-                continue
+                return None
             elif e.status and e.status >= 500:
-                if logger.level <= logging.WARNING:
-                    console.print(
-                        f"Failed to read logs from pod {pod.metadata.name}: {e}",
-                        style="bold yellow",
-                    )
-                continue
+                logger.warning(
+                    f"Failed to read logs from pod {stream}: {e}",
+                    extra=dict(tetragon_pod_name=stream),
+                )
+                return None
             raise
 
-        for line in loglines.splitlines():
-            # quickly filter-out lines that cannot match
-            if TETRAGON_POLICY_PREFIX not in line:
+        return previous_lines + loglines.splitlines()
+
+    def _restarted_within(self, stream: str, since_seconds: int) -> bool:
+        v1 = client.CoreV1Api()
+        pod = cast(client.V1Pod, v1.read_namespaced_pod(stream, TETRAGON_NAMESPACE))
+        window_start = datetime.now(timezone.utc) - timedelta(seconds=since_seconds)
+        for status in (pod.status and pod.status.container_statuses) or []:
+            if status.name != TETRAGON_POD_CONTAINER_NAME or not status.restart_count:
                 continue
+            running = status.state and status.state.running
+            if running and running.started_at:
+                return running.started_at > window_start
+        return False
+
+    def _read_previous_lines(self, stream: str, since_seconds: int) -> list[str]:
+        v1 = client.CoreV1Api()
+        try:
+            loglines = v1.read_namespaced_pod_log(
+                name=stream,
+                namespace=TETRAGON_NAMESPACE,
+                container=TETRAGON_POD_CONTAINER_NAME,
+                since_seconds=since_seconds,
+                previous=True,
+            )
+        except ApiException as e:
+            # the logs of the previous container might be gone already
+            logger.warning(
+                f"Failed to read previous logs from pod {stream}, "
+                f"events before the restart of Tetragon might be lost: {e}",
+                extra=dict(tetragon_pod_name=stream),
+            )
+            return []
+
+        return loglines.splitlines()
+
 
-            # events are often duplicated because kprobes can trigger multiple times.
-            # as a simple de-duplication strategy, we remove the milliseconds from the timestamp.
-            # this filters events that are completely identical and occurred within the same second.
-            time_pattern = r'("time":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2})\.\d{9}(Z")'
-            line = re.sub(time_pattern, r"\1\2", line)
+def read_tetragon_events(
+    since_seconds=LOG_WINDOW_SECONDS,
+    watermarks: dict[str, Watermark] | None = None,
+    source: EventSource | None = None,
+    matcher: TracingPolicyMatcher | None = None,
+) -> TetragonEvents:
+    source = source or KubernetesLogEventSource()
+    watermarks = watermarks or {}
 
-            try:
-                event = json.loads(line)
-            except json.JSONDecodeError:
-                continue  # skip non-json lines in the logs
+    streams = source.list_streams()
+    if not streams:
+        return TetragonEvents({}, {}, set())  # no Tetragon pods found
 
-            # parse and check the referenced policy name
-            if policy_name := _extract_tracing_policy_name(event):
-                if not policy_name.startswith(TETRAGON_POLICY_PREFIX):
-                    continue
+    matcher = matcher or build_tracing_policy_matcher()
 
-                # avoid duplicates
-                event_hash = hash(line)
-                if event_hash in event_cache:
-                    continue
+    complete = True
+    events_per_policy = defaultdict(list)
+    event_hashes = set()
+    # watermarks of streams (Tetragon pods) that no longer exist are dropped
+    for stream in watermarks.keys() - set(streams):
+        logger.warning(
+            f"Tetragon pod {stream} no longer exists, events after "
+            f"{watermarks[stream]['time']} that were not processed yet are lost",
+            extra=dict(tetragon_pod_name=stream),
+        )
+
+    # with multiple replicas, every replica reads the events of its share of Tetragon pods
+    owned_streams = [stream for stream in streams if owns_shard(stream)]
+    new_watermarks = {
+        stream: watermarks[stream] for stream in owned_streams if stream in watermarks
+    }
+    for stream in owned_streams:
+        # read far enough into the past to not miss events after the watermark (e.g., after restarts)
+        watermark = watermarks.get(stream)
+        stream_since_seconds = since_seconds
+        if watermark:
+            stream_since_seconds = max(since_seconds, seconds_since(watermark))
+
+        lines = source.read_lines(stream, stream_since_seconds)
+        if lines is None:
+            complete = False
+            continue
+
+        for line in lines:
+            if not (parsed := parse_tetragon_event(line, matcher)):
+                continue
+            policy_name, event, event_hash = parsed
+
+            # avoid duplicates, also of events that were processed before a restart
+            if event_hash in event_cache:
+                continue
+            if (event_time := event.get("time")) and is_processed(
+                watermark, event_time, event_hash
+            ):
+                continue
+
+            event_cache.add(event_hash)
+            event_hashes.add(event_hash)
+            events_per_policy[policy_name].append(event)
+            if event_time:
+                new_watermarks[stream] = advance(
+                    new_watermarks.get(stream), event_time, event_hash
+                )
 
-                event_cache.add(event_hash)
-                events_per_policy[policy_name].append(event)
+    return TetragonEvents(
+        events_per_policy, new_watermarks, event_hashes, streams, complete
+    )
 
-    # returns the list of events (value) grouped by their policy name (key)
-    return events_per_policy
 
+def parse_tetragon_event(
+    line: str, matcher: TracingPolicyMatcher
+) -> tuple[str, dict, str] | None:
+    """
+    Parses a Tetragon event (as JSON line) of a tracing policy created by Koney.
+    Returns the name of the tracing policy, the event, and the hash of the event.
+    """
+    # quickly filter-out lines that cannot match
+    if not matcher.could_match(line):
+        return None
 
-def map_tetragon_event(event: dict) -> KoneyAlert:
-    tracing_policy_name = None
-    deception_policy_name = None
-    trap_type = "unknown"
-    metadata = dict()
+    # events are often duplicated because kprobes can trigger multiple times.
+    # as a simple de-duplication strategy, we remove the milliseconds from the timestamp.
+    # this filters events that are completely identical and occurred within the same second.
+    time_pattern = r'("time":\s*"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2})(\.\d+)?(Z")'
+    line = re.sub(time_pattern, r"\1\3", line)
 
     try:
-        # attempt to resolve the DeceptionPolicy name (calls Kubernetes API)
-        if tracing_policy_name := _extract_tracing_policy_name(event):
-            deception_policy_name = _resolve_deception_policy_name(tracing_policy_name)
-    except ApiException as e:
-        if e.status and e.status == 404:
-            pass  # tracing policy might have been deleted in the meantime
-        elif e.status and e.status >= 500:
-            if logger.level <= logging.WARNING:
-                console.print(
-                    f"Failed to resolve DeceptionPolicy name for {tracing_policy_name}: {e}",
-                    style="bold yellow",
-                )
-        else:
-            raise
+        event = json.loads(line)
+    except json.JSONDecodeError:
+        return None  # skip non-json lines in the logs
+
+    # parse and check the referenced policy name
+    policy_name = _extract_tracing_policy_name(event)
+    if not policy_name or not matcher.matches(policy_name):
+        return None
+
+    # hash the event in a canonical form, so that the same events from logs and streams are equal
+    canonical_line = json.dumps(event, sort_keys=True, separators=(",", ":"))
+    return policy_name, event, hash_event(canonical_line)
+
+
+def forget_tetragon_events(event_hashes: set[str]):
+    # allows events to be read again, e.g., because they could not be forwarded
+    event_cache.difference_update(event_hashes)
+
+
+def map_tetragon_event(
+    event: dict, alerting: AlertingMetadata | None = None
+) -> KoneyAlert:
+    if alerting is None:
+        # resolve the trap of the event from its TracingPolicy (calls Kubernetes API)
+        tracing_policy_name = _extract_tracing_policy_name(event)
+        alerting = (
+            resolve_alerting(tracing_policy_name)
+            if tracing_policy_name
+            else _empty_alerting()
+        )
 
-    # infer trap type and metadata by inspecting the event
-    if kprobe := event.get("process_kprobe"):
-        if meta := _extract_metadata_for_filesystem_honeytoken(kprobe):
-            trap_type = "filesystem_honeytoken"
-            metadata = meta
+    # interpret the event as declared by the captor, or infer its trap type
+    trap_type, metadata = map_event(event, alerting["trap_type"])
+    if "file_path" in metadata and not metadata["file_path"]:
+        metadata["file_path"] = alerting["file_path"]
 
     pod = _extract_pod_metadata(event)
     node = _extract_node_metadata(event)
-    process = _extract_process_metadata(event)
+    process = _extract_process_metadata(
+        event, with_ancestors=_is_process_ancestry_enabled(pod)
+    )
 
     # TODO: emit errors if we fail to resolve fields
     return KoneyAlert(
+        schema_version=SCHEMA_VERSION,
         timestamp=event["time"],
-        deception_policy_name=deception_policy_name,
+        deception_policy_name=alerting["deception_policy_name"],
         trap_type=trap_type,
+        severity=alerting["severity"],
+        confidence=alerting["confidence"],
+        tags=alerting["tags"],
+        trap=alerting["trap"],
         metadata=metadata,
         pod=pod,
         node=node,
         process=process,
+        response=alerting["response"],
     )
 
 
@@ -188,25 +492,24 @@ def is_filtered_alert(alert: KoneyAlert) -> bool:
     if not alert["process"] or not alert["process"]["arguments"]:
         return False  # cannot decide, assume not filtered
 
+    code = read_cached_fingerprint()
+    if code is None:
+        return False  # fingerprint unknown, assume not filtered
+
     arguments = alert["process"]["arguments"]
     fingerprints = [
-        encode_fingerprint_in_echo(KONEY_FINGERPRINT),
-        encode_fingerprint_in_cat(KONEY_FINGERPRINT),
+        encode_fingerprint_in_echo(code),
+        encode_fingerprint_in_cat(code),
     ]
 
     # if any fingerprint is present, filter this event
     return any(fp in arguments for fp in fingerprints)
 
 
-def resolve_container_selectors(tracing_policy_name: str) -> list[str] | None:
+def resolve_container_selectors(tracing_policy_name: str) -> list | None:
     try:
-        api = client.CustomObjectsApi()
-        tracing_policy = cast(
-            dict,
-            api.get_cluster_custom_object(
-                *TETRAGON_TRACING_POLICIES_GVP, tracing_policy_name
-            ),
-        )
+        if not (tracing_policy := get_tracing_policy(tracing_policy_name)):
+            return None
         selectors_json = (
             tracing_policy.get("metadata", {})
             .get("annotations", {})
@@ -219,24 +522,96 @@ def resolve_container_selectors(tracing_policy_name: str) -> list[str] | None:
     return None
 
 
+def resolve_alerting(tracing_policy_name: str) -> AlertingMetadata:
+    alerting = _empty_alerting()
+    try:
+        if not (tracing_policy := get_tracing_policy(tracing_policy_name)):
+            return alerting  # tracing policy might have been deleted in the meantime
+        annotations = tracing_policy.get("metadata", {}).get("annotations", {}) or {}
+        labels = tracing_policy.get("metadata", {}).get("labels", {}) or {}
+        alerting["deception_policy_name"] = labels.get(TETRAGON_DECEPTION_POLICY_REF)
+        alerting["trap_type"] = annotations.get(TETRAGON_TRAP_TYPE_ANNOTATION)
+        alerting["file_path"] = annotations.get(TETRAGON_FILE_PATH_ANNOTATION)
+        alerting["severity"] = resolve_severity(
+            annotations.get(TETRAGON_ALERT_SEVERITY_ANNOTATION),
+            labels.get(TETRAGON_SEVERITY_LABEL),
+        )
+        alerting["confidence"] = resolve_confidence(
+            labels.get(TETRAGON_CONFIDENCE_LABEL)
+        )
+        if tags_json := annotations.get(TETRAGON_ALERT_TAGS_ANNOTATION):
+            alerting["tags"] = json.loads(tags_json)
+        alerting["trap"] = build_trap_metadata(
+            annotations.get(TETRAGON_TRAP_HASH_ANNOTATION),
+            annotations.get(TETRAGON_DECEPTION_POLICY_GENERATION_ANNOTATION),
+            annotations.get(TETRAGON_TRAP_DESCRIPTION_ANNOTATION),
+            annotations.get(TETRAGON_ATTCK_TECHNIQUES_ANNOTATION),
+            annotations.get(TETRAGON_ENGAGE_ACTIVITIES_ANNOTATION),
+        )
+        alerting["response"] = build_response_metadata(
+            annotations.get(TETRAGON_RESPONSE_ACTIONS_ANNOTATION),
+            annotations.get(TETRAGON_QUARANTINE_TTL_ANNOTATION),
+        )
+    except Exception as e:
+        logger.warning(
+            f"Failed to resolve the trap of {tracing_policy_name}: {e}",
+            extra=dict(tracing_policy_name=tracing_policy_name),
+        )
+    return alerting
+
+
+def container_name_matches(container_name: str, pattern: str) -> bool:
+    if not pattern:
+        return True  # empty pattern matches all
+    elif pattern.startswith("regex:"):
+        return re.search(pattern[len("regex:") :], container_name) is not None
+    elif pattern.startswith("glob:"):
+        return fnmatch.fnmatch(container_name, pattern[len("glob:") :])
+    return pattern == container_name
+
+
+def image_matches(image: str | None, pattern: str) -> bool:
+    if not pattern:
+        return True  # empty pattern matches all
+    if not image:
+        return False
+    if pattern.startswith("regex:"):
+        return container_name_matches(image, pattern)
+    if pattern.startswith("glob:"):
+        # like Go's filepath.Match, wildcards do not match the "/" between path components
+        pattern_parts = pattern[len("glob:") :].split("/")
+        image_parts = image.split("/")
+        return len(pattern_parts) == len(image_parts) and all(
+            fnmatch.fnmatchcase(i, p) for i, p in zip(image_parts, pattern_parts)
+        )
+
+    # exact patterns match the image reference, or its repository without tag and digest
+    repository = image.split("@", 1)[0]
+    if repository.rfind(":") > repository.rfind("/"):
+        repository = repository[: repository.rfind(":")]
+    return pattern in (image, repository)
+
+
 def container_matches_selectors(
-    container_name: str | None, selectors: list[str]
+    container_name: str | None, selectors: list, container_image: str | None = None
 ) -> bool:
     if not container_name:
         return False
 
     for selector in selectors:
-        if not selector:
-            return True  # empty selector matches all
-        elif selector.startswith("regex:"):
-            pattern = selector[len("regex:") :]
-            if re.search(pattern, container_name):
-                return True
-        elif selector.startswith("glob:"):
-            pattern = selector[len("glob:") :]
-            if fnmatch.fnmatch(container_name, pattern):
+        # selectors are either plain patterns, or objects with included and excluded patterns
+        if isinstance(selector, str):
+            if container_name_matches(container_name, selector):
                 return True
-        elif selector == container_name:
+            continue
+
+        includes = selector.get("include") or []
+        excludes = selector.get("exclude") or []
+        if any(container_name_matches(container_name, p) for p in excludes):
+            continue
+        if not image_matches(container_image, selector.get("image", "")):
+            continue
+        if not includes or any(container_name_matches(container_name, p) for p in includes):
             return True
 
     return False
@@ -245,29 +620,41 @@ def container_matches_selectors(
 ###############################################################################
 
 
-def _resolve_deception_policy_name(tracing_policy_name: str) -> str | None:
-    api = client.CustomObjectsApi()
-    tracing_policy = cast(
-        dict,
-        api.get_cluster_custom_object(
-            *TETRAGON_TRACING_POLICIES_GVP, tracing_policy_name
-        ),
+def _is_process_ancestry_enabled(pod: PodMetadata | None) -> bool:
+    namespace = (pod or {}).get("namespace") or ""
+    return is_feature_enabled(
+        read_cached_feature_flags(), PROCESS_ANCESTRY_ENRICHMENT_FEATURE, namespace
     )
 
-    return (
-        tracing_policy.get("metadata", {})
-        .get("labels", {})
-        .get(TETRAGON_DECEPTION_POLICY_REF)
+
+def _empty_alerting() -> AlertingMetadata:
+    return AlertingMetadata(
+        deception_policy_name=None,
+        trap_type=None,
+        file_path=None,
+        severity=None,
+        confidence=None,
+        tags={},
+        trap=None,
+        response=None,
     )
 
 
 def _extract_tracing_policy_name(event: dict) -> str | None:
     # keys might be process_kprobe, process_uprobe, ...
     for value in event.values():
-        if policy_name := value.get("policy_name"):
+        if isinstance(value, dict) and (policy_name := value.get("policy_name")):
             return policy_name
 
 
+def extract_container_image(event: dict) -> str | None:
+    # keys might be process_kprobe, process_uprobe, ...
+    for value in event.values():
+        if isinstance(value, dict) and (pod := value.get("process", {}).get("pod")):
+            return pod.get("container", {}).get("image", {}).get("name")
+    return None
+
+
 def _extract_pod_metadata(event: dict) -> PodMetadata | None:
     # keys might be process_kprobe, process_uprobe, ...
     for value in event.values():
@@ -288,21 +675,70 @@ def _extract_node_metadata(event: dict) -> NodeMetadata | None:
     return None
 
 
-def _extract_process_metadata(event: dict) -> ProcessMetadata | None:
+def _extract_process_metadata(
+    event: dict, with_ancestors: bool = False
+) -> ProcessMetadata | None:
     # keys might be process_kprobe, process_uprobe, ...
     for value in event.values():
         if process := value.get("process"):
-            return ProcessMetadata(
+            process_metadata = ProcessMetadata(
                 uid=process.get("uid"),
                 pid=process.get("pid"),
                 cwd=process.get("cwd"),
                 binary=process.get("binary"),
                 arguments=process.get("arguments"),
+                exec_id=process.get("exec_id"),
+                credentials=_extract_credentials(process),
+                capabilities=_extract_capabilities(process),
+                namespaces=_extract_namespaces(process),
             )
+            if with_ancestors:
+                process_metadata["ancestors"] = _extract_ancestors(value)
+            return process_metadata
+
+
+def _extract_credentials(process: dict) -> CredentialsMetadata | None:
+    if not (credentials := process.get("process_credentials")):
+        return None  # Tetragon runs without --enable-process-cred
+    return CredentialsMetadata(
+        uid=credentials.get("uid"),
+        gid=credentials.get("gid"),
+        euid=credentials.get("euid"),
+        egid=credentials.get("egid"),
+    )
+
+
+def _extract_capabilities(process: dict) -> list[str] | None:
+    # newer Tetragon versions report the capabilities with the credentials
+    credentials = process.get("process_credentials") or {}
+    if not (capabilities := credentials.get("caps") or process.get("cap")):
+        return None
+    return capabilities.get("effective") or []
 
 
-def _extract_metadata_for_filesystem_honeytoken(kprobe: dict) -> dict | None:
-    file_access_fn = ("security_file_permission", "security_mmap_file")
-    if kprobe.get("function_name") in file_access_fn:
-        file_path = kprobe.get("args", [{}])[0].get("file_arg", {}).get("path")
-        return dict(file_path=file_path)
+def _extract_namespaces(process: dict) -> dict[str, NamespaceMetadata] | None:
+    if not (namespaces := process.get("ns")):
+        return None  # Tetragon runs without --enable-process-ns
+    return {
+        name: NamespaceMetadata(
+            inum=namespace.get("inum"), is_host=namespace.get("is_host", False)
+        )
+        for name, namespace in namespaces.items()
+        if isinstance(namespace, dict)
+    }
+
+
+def _extract_ancestors(value: dict) -> list[AncestorProcessMetadata]:
+    # Tetragon reports the parent on its own, and further ancestors only if enabled
+    # (with --enable-ancestors), starting from the parent of the parent
+    ancestors = [value["parent"]] if value.get("parent") else []
+    ancestors.extend(value.get("ancestors") or [])
+    return [
+        AncestorProcessMetadata(
+            pid=ancestor.get("pid"),
+            binary=ancestor.get("binary"),
+            arguments=ancestor.get("arguments"),
+            exec_id=ancestor.get("exec_id"),
+        )
+        for ancestor in ancestors
+    ]
@@ -0,0 +1,289 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import logging
+import os
+import threading
+import time
+from collections.abc import Callable, Iterator
+from typing import cast
+
+from kubernetes import client
+from kubernetes.client.exceptions import ApiException
+
+from .log import log_context, new_correlation_id
+from .sharding import owns_shard
+from .tetragon import (
+    TETRAGON_EXTRA_POLICY_PREFIXES,
+    TETRAGON_NAMESPACE,
+    TETRAGON_POD_LABEL_SELECTOR,
+    TracingPolicyMatcher,
+    build_tracing_policy_matcher,
+    event_cache,
+    parse_tetragon_event,
+)
+
+# the port of the gRPC API of the Tetragon pods, see the tetragon.grpc.address Helm value
+TETRAGON_GRPC_PORT = int(os.environ.get("KONEY_TETRAGON_GRPC_PORT", "54321"))
+# how often Tetragon pods and tracing policies are listed to update the event streams
+STREAM_RESYNC_SECONDS = 30
+# how long to wait before reconnecting to a Tetragon pod after the stream failed
+STREAM_RECONNECT_SECONDS = 5
+# how long to wait for events that are being handled when the streams are stopped
+STREAM_STOP_TIMEOUT_SECONDS = 5
+
+# called with the Tetragon pod name, the tracing policy name, the event, and its hash
+EventHandler = Callable[[str, str, dict, str], None]
+
+logger = logging.getLogger("uvicorn.error")
+
+
+class TetragonEventStream:
+    """Streams the events of one Tetragon pod with the GetEvents API.
+
+    The stream reconnects on failures, and subscribes again when tracing policies change.
+    """
+
+    def __init__(self, pod_name: str, address: str, handle_event: EventHandler):
+        self.pod_name = pod_name
+        self.address = address
+        self.handle_event = handle_event
+        self.matcher: TracingPolicyMatcher | None = None
+        # false while the stream is (re)connecting after a failure
+        self.connected = False
+        self._call = None
+        self._stopped = threading.Event()
+        self._thread = threading.Thread(target=self._run, daemon=True)
+
+    def start(self, matcher: TracingPolicyMatcher) -> None:
+        self.matcher = matcher
+        self._thread.start()
+
+    def stop(self) -> None:
+        self._stopped.set()
+        self._cancel()
+
+    def join(self, timeout: float | None = None) -> None:
+        """Waits until the stream stopped, i.e., the current event was handled."""
+        if self._thread.is_alive():
+            self._thread.join(timeout)
+
+    def resubscribe(self, matcher: TracingPolicyMatcher) -> None:
+        """Subscribes again with the filters of another matcher."""
+        self.matcher = matcher
+        self._cancel()
+
+    def _cancel(self) -> None:
+        if call := self._call:
+            call.cancel()
+
+    def _run(self) -> None:
+        with log_context(tetragon_pod_name=self.pod_name):
+            self._stream_events()
+
+    def _stream_events(self) -> None:
+        while not self._stopped.is_set():
+            matcher = cast(TracingPolicyMatcher, self.matcher)
+            try:
+                policy_names = build_policy_filter(matcher)
+                self._call = open_event_stream(self.address, policy_names)
+                self.connected = True
+                for line in self._call:
+                    self._handle_line(line, matcher)
+            except Exception as e:
+                if self._stopped.is_set() or matcher is not self.matcher:
+                    continue  # stopped or resubscribed on purpose
+                self.connected = False
+                logger.warning(f"Failed to stream events from pod {self.pod_name}: {e}")
+            self._stopped.wait(STREAM_RECONNECT_SECONDS)
+
+    def _handle_line(self, line: str, matcher: TracingPolicyMatcher) -> None:
+        if not (parsed := parse_tetragon_event(line, matcher)):
+            return
+        policy_name, event, event_hash = parsed
+        # events of the same second are often identical, see parse_tetragon_event
+        if event_hash in event_cache:
+            return
+        event_cache.add(event_hash)
+
+        # every streamed event is handled on its own, so it gets its own correlation id
+        with log_context(
+            correlation_id=new_correlation_id(), tracing_policy_name=policy_name
+        ):
+            try:
+                self.handle_event(self.pod_name, policy_name, event, event_hash)
+            except:
+                logger.exception("failed to handle Tetragon event")
+
+
+class TetragonEventStreams:
+    """Keeps one event stream per Tetragon pod, and updates them when pods come and go."""
+
+    def __init__(self, handle_event: EventHandler):
+        self.handle_event = handle_event
+        self.streams: dict[str, TetragonEventStream] = {}
+        self.matcher: TracingPolicyMatcher | None = None
+        self._lock = threading.Lock()
+        self._stopped = threading.Event()
+
+    def run_forever(self) -> None:
+        """Keeps the streams up to date, until they are stopped."""
+        while not self._stopped.is_set():
+            try:
+                self.resync()
+            except:
+                logger.exception("failed to update Tetragon streams")
+            self._stopped.wait(STREAM_RESYNC_SECONDS)
+
+    def check_health(self) -> str | None:
+        """Returns why events cannot be streamed, or None if they can."""
+        if self.matcher is None:
+            return "the Tetragon pods were not listed yet"
+        with self._lock:
+            streams = list(self.streams.values())
+        if streams and not any(stream.connected for stream in streams):
+            return f"not connected to any of {len(streams)} Tetragon pods"
+        return None
+
+    def stop(self, timeout: float = STREAM_STOP_TIMEOUT_SECONDS) -> None:
+        """Stops all streams, and waits for the events that are being handled."""
+        with self._lock:
+            self._stopped.set()
+            streams = list(self.streams.values())
+            self.streams.clear()
+
+        for stream in streams:
+            stream.stop()
+        deadline = time.monotonic() + timeout
+        for stream in streams:
+            stream.join(max(0.0, deadline - time.monotonic()))
+
+    def resync(self) -> bool:
+        """
+        Starts and stops streams of Tetragon pods, and subscribes again if tracing
+        policies changed. Returns true if streams were started or subscribed again.
+        """
+        with self._lock:
+            if self._stopped.is_set():
+                return False
+            return self._resync()
+
+    def _resync(self) -> bool:
+        pod_addresses = list_tetragon_pod_addresses()
+        if pod_addresses is None:
+            return False  # keep the current streams until the pods can be listed again
+        # with multiple replicas, every replica streams the events of its share of pods
+        pod_addresses = {
+            pod_name: address
+            for pod_name, address in pod_addresses.items()
+            if owns_shard(pod_name)
+        }
+
+        matcher = build_tracing_policy_matcher()
+        changed = self.matcher is None or matcher.names != self.matcher.names
+        if changed:
+            self.matcher = matcher
+
+        for pod_name in list(self.streams):
+            stream = self.streams[pod_name]
+            if pod_addresses.get(pod_name) != stream.address:
+                stream.stop()  # the pod was deleted or restarted with another address
+                del self.streams[pod_name]
+            elif changed:
+                stream.resubscribe(matcher)
+
+        for pod_name, address in pod_addresses.items():
+            if pod_name not in self.streams:
+                stream = TetragonEventStream(pod_name, address, self.handle_event)
+                stream.start(cast(TracingPolicyMatcher, self.matcher))
+                self.streams[pod_name] = stream
+                changed = True
+
+        return changed
+
+
+def list_tetragon_pod_addresses() -> dict[str, str] | None:
+    """Returns the gRPC addresses of all Tetragon pods, or None if they cannot be listed."""
+    v1 = client.CoreV1Api()
+    try:
+        pod_list = cast(
+            client.V1PodList,
+            v1.list_namespaced_pod(
+                namespace=TETRAGON_NAMESPACE,
+                label_selector=TETRAGON_POD_LABEL_SELECTOR,
+            ),
+        )
+    except ApiException as e:
+        logger.warning(f"Failed to list Tetragon pods: {e}")
+        return None
+
+    return {
+        pod.metadata.name: f"{pod.status.pod_ip}:{TETRAGON_GRPC_PORT}"
+        for pod in pod_list.items
+        if pod.status and pod.status.pod_ip
+    }
+
+
+def build_policy_filter(matcher: TracingPolicyMatcher) -> list[str]:
+    """
+    Returns the names of the tracing policies to filter events by, on the Tetragon side.
+    Tetragon cannot filter by name prefixes, so events are only filtered by the client
+    if additional prefixes are configured, or if no tracing policies exist yet.
+    """
+    if TETRAGON_EXTRA_POLICY_PREFIXES:
+        return []
+    return sorted(matcher.names)
+
+
+def open_event_stream(address: str, policy_names: list[str]) -> Iterator[str]:
+    """
+    Subscribes to the events of a Tetragon pod and returns them as JSON lines,
+    in the same format as Tetragon writes them to its export-stdout logs.
+    The returned stream can be cancelled with its cancel() method.
+    """
+    # the gRPC client and the Tetragon API are only needed in the container image
+    import grpc
+    from google.protobuf.json_format import MessageToDict
+    from tetragon import events_pb2, sensors_pb2_grpc
+
+    channel = grpc.insecure_channel(address)
+    stub = sensors_pb2_grpc.FineGuidanceSensorsStub(channel)
+
+    # only events of tracing policies are needed, not the (many) process executions
+    event_filter = events_pb2.Filter(
+        event_set=[
+            events_pb2.PROCESS_KPROBE,
+            events_pb2.PROCESS_TRACEPOINT,
+            events_pb2.PROCESS_UPROBE,
+            events_pb2.PROCESS_LSM,
+        ],
+        policy_names=policy_names,
+    )
+    call = stub.GetEvents(events_pb2.GetEventsRequest(allow_list=[event_filter]))
+
+    class EventLines:
+        def __iter__(self):
+            try:
+                for response in call:
+                    event = MessageToDict(response, preserving_proto_field_name=True)
+                    yield json.dumps(event, separators=(",", ":"))
+            finally:
+                channel.close()
+
+        def cancel(self):
+            call.cancel()
+
+    return EventLines()
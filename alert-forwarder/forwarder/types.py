@@ -13,22 +13,69 @@
 # You should have received a copy of the GNU Affero General Public License
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
-from typing import Literal, TypedDict
+# The alert types in this module mirror the Go package pkg/alerts, which is the
+# source of truth. Tests in pkg/alerts check that both did not drift apart.
+
+from typing import Literal, NotRequired, TypedDict
+
+# The version of the alert format that the forwarder emits. Minor versions only add
+# optional fields, so consumers can pin to the major version (see docs/schemas).
+SCHEMA_VERSION = "1.1"
 
 
 class ContainerMetadata(TypedDict):
     id: str
     name: str
+    # optional fields that are added by the enrichers of the alert forwarder
+    image: NotRequired[str]  # e.g., "docker.io/library/nginx:1.27"
+    image_digest: NotRequired[str]  # e.g., "sha256:5f4e0b1c..."
+
+
+class OwnerMetadata(TypedDict):
+    kind: str  # the top-level controller, e.g., "Deployment" instead of "ReplicaSet"
+    name: str
 
 
 class PodMetadata(TypedDict):
     name: str
     namespace: str
     container: ContainerMetadata
+    # optional fields that are added by the enrichers of the alert forwarder
+    owner: NotRequired[OwnerMetadata]
+    labels: NotRequired[dict[str, str]]
+    annotations: NotRequired[dict[str, str]]
+
+
+class CloudMetadata(TypedDict):
+    provider: str | None  # e.g., "aws", from the provider ID of the node
+    region: str | None
+    zone: str | None
+    instance_type: str | None
 
 
 class NodeMetadata(TypedDict):
     name: str
+    # optional field that is added by the enrichers of the alert forwarder
+    cloud: NotRequired[CloudMetadata]
+
+
+class AncestorProcessMetadata(TypedDict):
+    pid: int
+    binary: str
+    arguments: str
+    exec_id: str | None  # unique id of the process in Tetragon
+
+
+class CredentialsMetadata(TypedDict):
+    uid: int | None
+    gid: int | None
+    euid: int | None  # effective user id, which decides about file access
+    egid: int | None
+
+
+class NamespaceMetadata(TypedDict):
+    inum: int | None  # inode number of the namespace
+    is_host: bool  # whether the process shares the namespace with the host
 
 
 class ProcessMetadata(TypedDict):
@@ -37,17 +84,68 @@ class ProcessMetadata(TypedDict):
     cwd: str
     binary: str
     arguments: str
+    exec_id: str | None  # unique id of the process in Tetragon, if known
+    # optional context that Tetragon only reports with --enable-process-cred and --enable-process-ns
+    credentials: CredentialsMetadata | None
+    capabilities: list[str] | None  # effective capabilities, e.g., "CAP_SYS_ADMIN"
+    namespaces: dict[str, NamespaceMetadata] | None  # by their names, e.g., "pid"
+    # optional parent process chain, from the parent to the oldest known ancestor
+    ancestors: NotRequired[list[AncestorProcessMetadata]]
+
+
+TrapType = Literal[
+    "unknown",
+    "filesystem_honeytoken",
+    "http_endpoint",
+    "http_payload",
+]
+
+Severity = Literal["CRITICAL", "HIGH", "MEDIUM", "LOW", "INFO"]
+
+Confidence = Literal["high", "medium", "low"]
+
+
+class TrapMetadata(TypedDict):
+    hash: str | None  # hash of the originating trap spec
+    deception_policy_generation: int | None
+    description: str | None
+    attck_techniques: list[str]  # MITRE ATT&CK technique IDs, e.g., "T1552.001"
+    engage_activities: list[str]  # MITRE Engage activity IDs, e.g., "EAC0005"
+
+
+class ResponseMetadata(TypedDict):
+    actions: list[str]  # the response actions of the trap, e.g., "networkIsolate"
+    quarantine_ttl: str | None  # how long the pod is quarantined, e.g., "1h0m0s"
+
+
+class AlertingMetadata(TypedDict):
+    deception_policy_name: str | None
+    trap_type: TrapType | None  # as declared by the captor, inferred from events otherwise
+    file_path: str | None  # of the trap, if events do not carry it
+    severity: Severity | None  # overrides the severity of the sink
+    confidence: Confidence | None
+    tags: dict[str, str]
+    trap: TrapMetadata | None
+    response: ResponseMetadata | None
+
+
+class ClusterMetadata(TypedDict):
+    name: str  # as configured for the token of the cluster in the aggregating forwarder
 
 
 class KoneyAlert(TypedDict):
+    schema_version: str  # e.g., "1.0"
     timestamp: str  # ISO 8601
     deception_policy_name: str | None
-    trap_type: Literal[
-        "unknown",
-        "filesystem_honeytoken",
-        "http_endpoint",
-        "http_payload",
-    ]
+    trap_type: TrapType
+
+    # optional alerting configuration of the trap
+    severity: Severity | None
+    confidence: Confidence | None
+    tags: dict[str, str]
+
+    # optional origin of the trap, as recorded by the controller
+    trap: TrapMetadata | None
 
     # optional metadata that can be present depending on the trap type
     metadata: dict
@@ -55,17 +153,80 @@ class KoneyAlert(TypedDict):
     node: NodeMetadata | None
     process: ProcessMetadata | None
 
+    # optional automated response that was requested for the alert
+    response: ResponseMetadata | None
+
+    # optional origin cluster, added when the alert is forwarded by another cluster (since 1.1)
+    cluster: NotRequired[ClusterMetadata]
+
 
-DynatraceSeverity = Literal["CRITICAL", "HIGH", "MEDIUM", "LOW"]
+DynatraceSeverity = Severity
+
+
+# the Dynatrace API that alerts are ingested with
+DynatraceApi = Literal["securityEvents", "events", "logs"]
 
 
 class DynatraceSink(TypedDict):
     api_url: str
     api_token: str
-    # TODO (#27): specify severity in the policy, not in the sink!
+    # the default severity, unless a trap overrides it
     severity: DynatraceSeverity
+    api: DynatraceApi
+    # stored as dt.security_context in all ingested records, if set
+    security_context: str | None
+
+
+class WebhookRetryPolicy(TypedDict):
+    max_retries: int
+    initial_backoff: float  # seconds
+    max_backoff: float  # seconds
+
+
+class WebhookSink(TypedDict):
+    url: str
+    # additional headers, including the authorization header, if any
+    headers: dict[str, str]
+    retry: WebhookRetryPolicy
+
+
+class ChatSink(TypedDict):
+    provider: Literal["slack", "teams"]
+    webhook_url: str
+    max_messages_per_minute: int
+
+
+class SyslogSink(TypedDict):
+    host: str
+    port: int
+    protocol: Literal["udp", "tcp", "tls"]
+    format: Literal["cef", "leef"]
+    facility: str
+    # the CA certificate to verify the server with (PEM), if any
+    ca_cert: str | None
+
+
+class AlertSinkMatch(TypedDict):
+    # glob patterns, an empty list matches all alerts
+    deception_policy_names: list[str]
+    trap_types: list[str]
+    # glob patterns, an empty list matches all alerts
+    namespaces: list[str]
+    severities: list[str]
+
+
+class RedeliveryPolicy(TypedDict):
+    max_attempts: int
+    initial_backoff: float  # seconds
+    max_backoff: float  # seconds
 
 
 class AlertSink(TypedDict):
     name: str
+    # which alerts are sent to the sink, all alerts if not set
+    match: AlertSinkMatch | None
+    redelivery: RedeliveryPolicy
     dynatrace_sink: DynatraceSink | None
+    webhook_sink: WebhookSink | None
+    chat_sink: ChatSink | None
+    syslog_sink: SyslogSink | None
@@ -13,11 +13,80 @@
 # You should have received a copy of the GNU Affero General Public License
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
+import json
 import re
 
+from .types import Confidence, ResponseMetadata, Severity, TrapMetadata
+
 
 def _normalize_container_id(container_id: str) -> str:
     if container_id is None:
         return None
     scheme_pattern = r"^\w+://"  # remove prefixes such as "docker://"
     return re.sub(scheme_pattern, "", container_id).strip()
+
+
+def build_trap_metadata(
+    trap_hash: str | None,
+    generation: str | None,
+    description: str | None,
+    techniques_json: str | None = None,
+    activities_json: str | None = None,
+) -> TrapMetadata | None:
+    if not trap_hash and not generation and not description:
+        return None  # captor was deployed by an older controller
+    return TrapMetadata(
+        hash=trap_hash,
+        deception_policy_generation=(
+            int(generation) if generation and generation.isdigit() else None
+        ),
+        description=description,
+        attck_techniques=json.loads(techniques_json) if techniques_json else [],
+        engage_activities=json.loads(activities_json) if activities_json else [],
+    )
+
+
+# units of Go durations (e.g., "1m30s"), in seconds
+_DURATION_UNITS = {
+    "h": 3600.0,
+    "m": 60.0,
+    "s": 1.0,
+    "ms": 1e-3,
+    "us": 1e-6,
+    "µs": 1e-6,
+    "ns": 1e-9,
+}
+
+
+def parse_duration(duration: str) -> float:
+    """
+    Parses a Go duration (e.g., "1m30s", as used by metav1.Duration) into seconds.
+    """
+    parts = re.findall(r"(\d+(?:\.\d+)?)(h|ms|m|s|us|µs|ns)", duration)
+    if not parts or "".join(value + unit for value, unit in parts) != duration:
+        raise ValueError(f"invalid duration: {duration}")
+    return sum(float(value) * _DURATION_UNITS[unit] for value, unit in parts)
+
+
+def resolve_severity(
+    alert_severity: str | None, trap_severity: str | None
+) -> Severity | None:
+    # the severity override of the alerting configuration takes precedence
+    if alert_severity:
+        return alert_severity.upper()
+    return trap_severity.upper() if trap_severity else None
+
+
+def resolve_confidence(confidence: str | None) -> Confidence | None:
+    return confidence.lower() if confidence else None
+
+
+def build_response_metadata(
+    actions_json: str | None, quarantine_ttl: str | None
+) -> ResponseMetadata | None:
+    if not actions_json:
+        return None  # trap has no response actions
+    return ResponseMetadata(
+        actions=json.loads(actions_json),
+        quarantine_ttl=quarantine_ttl or None,
+    )
@@ -0,0 +1,111 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from forwarder import aggregation, main
+from forwarder.types import SCHEMA_VERSION, KoneyAlert
+
+
+def alert(timestamp: str, binary: str = "/usr/bin/cat") -> KoneyAlert:
+    return KoneyAlert(
+        schema_version=SCHEMA_VERSION,
+        timestamp=timestamp,
+        deception_policy_name="dp",
+        trap_type="filesystem_honeytoken",
+        severity=None,
+        confidence=None,
+        tags={},
+        trap=None,
+        metadata={"file_path": "/run/secrets/koney/service_token"},
+        pod=None,
+        node=None,
+        process=dict(uid=0, pid=1, cwd="/", binary=binary, arguments=""),
+        response=None,
+    )
+
+
+@mock.patch.object(aggregation, "_resolve_aggregation_window", return_value=60.0)
+class AggregateAlertTest(unittest.TestCase):
+    def setUp(self):
+        aggregation._groups.clear()
+        aggregation._due_groups.clear()
+
+    def test_forwards_the_first_alert_and_absorbs_the_others(self, _):
+        self.assertEqual(aggregation.aggregate_alert(alert("t1"), now=0.0), "forward")
+        self.assertEqual(aggregation.aggregate_alert(alert("t2"), now=10.0), "absorb")
+        self.assertEqual(aggregation.aggregate_alert(alert("t3"), now=20.0), "absorb")
+        # other binaries are grouped on their own
+        self.assertEqual(
+            aggregation.aggregate_alert(alert("t4", "/bin/sh"), now=30.0), "forward"
+        )
+
+    def test_summarizes_groups_once_their_window_ended(self, _):
+        aggregation.aggregate_alert(alert("t1"), now=0.0)
+        aggregation.aggregate_alert(alert("t2"), now=10.0)
+        aggregation.aggregate_alert(alert("t3"), now=20.0)
+
+        self.assertEqual(aggregation.flush_aggregated_alerts(now=30.0), [])
+        (summary_alert,) = aggregation.flush_aggregated_alerts(now=60.0)
+        self.assertEqual(summary_alert["timestamp"], "t3")
+        self.assertEqual(
+            summary_alert["metadata"],
+            {
+                "file_path": "/run/secrets/koney/service_token",
+                "aggregation": dict(
+                    hits=3, first_seen="t1", last_seen="t3", window_seconds=60.0
+                ),
+            },
+        )
+        # the next alert opens a new group
+        self.assertEqual(aggregation.aggregate_alert(alert("t4"), now=61.0), "forward")
+
+    def test_does_not_summarize_single_alerts(self, _):
+        aggregation.aggregate_alert(alert("t1"), now=0.0)
+        self.assertEqual(aggregation.flush_aggregated_alerts(force=True), [])
+
+    def test_summarizes_groups_that_ended_before_the_flush(self, _):
+        aggregation.aggregate_alert(alert("t1"), now=0.0)
+        aggregation.aggregate_alert(alert("t2"), now=10.0)
+        self.assertEqual(aggregation.aggregate_alert(alert("t3"), now=70.0), "forward")
+
+        (summary_alert,) = aggregation.flush_aggregated_alerts(now=80.0)
+        self.assertEqual(summary_alert["metadata"]["aggregation"]["hits"], 2)
+
+    def test_does_not_aggregate_without_a_window(self, resolve_window):
+        resolve_window.return_value = None
+        aggregation.aggregate_alert(alert("t1"), now=0.0)
+        self.assertEqual(aggregation.aggregate_alert(alert("t2"), now=1.0), "forward")
+
+
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+@mock.patch.object(main, "try_read_alert_sinks", return_value=[])
+class ForwardAggregatedAlertsTest(unittest.TestCase):
+    def test_forwards_summaries_without_aggregating_them_again(self, *_):
+        summary_alert = alert("t2")
+        with (
+            mock.patch.object(
+                main, "flush_aggregated_alerts", return_value=[summary_alert]
+            ),
+            mock.patch.object(main, "aggregate_alert") as aggregate_alert,
+            mock.patch.object(main, "check_quota", return_value="allow"),
+            mock.patch.object(main, "try_export_alert"),
+            mock.patch.object(main, "print_alert") as print_alert,
+        ):
+            main.try_forward_aggregated_alerts(force=True)
+
+        aggregate_alert.assert_not_called()
+        print_alert.assert_called_once_with(summary_alert)
@@ -0,0 +1,85 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import unittest
+from unittest import mock
+
+from forwarder import auth, main
+from forwarder.auth import is_authorized_request, sign_body
+
+
+def request(token: str | None = None, headers: dict | None = None) -> mock.Mock:
+    query_params = {"token": token} if token is not None else {}
+    return mock.Mock(query_params=query_params, headers=headers or {})
+
+
+@mock.patch.object(auth, "WEBHOOK_TOKEN", "s3cr3t")
+class IsAuthorizedRequestTest(unittest.TestCase):
+    def test_accepts_the_token_as_query_parameter(self):
+        self.assertTrue(is_authorized_request(request("s3cr3t")))
+        self.assertFalse(is_authorized_request(request("wrong")))
+        self.assertFalse(is_authorized_request(request()))
+
+    def test_accepts_the_token_as_bearer_token(self):
+        headers = {"Authorization": "Bearer s3cr3t"}
+        self.assertTrue(is_authorized_request(request(headers=headers)))
+
+        headers = {"Authorization": "Basic s3cr3t"}
+        self.assertFalse(is_authorized_request(request(headers=headers)))
+
+    def test_accepts_signed_bodies(self):
+        body = b'{"time": "2025-01-03T18:47:56Z"}'
+        headers = {auth.SIGNATURE_HEADER: sign_body(body)}
+        self.assertTrue(is_authorized_request(request(headers=headers), body))
+
+        # the signature does not match a modified body
+        self.assertFalse(is_authorized_request(request(headers=headers), b"{}"))
+
+    def test_rejects_non_ascii_tokens(self):
+        self.assertFalse(is_authorized_request(request("s3cr3tä")))
+
+    def test_accepts_all_requests_without_a_token(self):
+        with mock.patch.object(auth, "WEBHOOK_TOKEN", ""):
+            self.assertTrue(is_authorized_request(request()))
+
+
+@mock.patch.object(auth, "WEBHOOK_TOKEN", "s3cr3t")
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+class HandlerAuthenticationTest(unittest.TestCase):
+    def test_rejects_unauthorized_events(self, *_):
+        response, background_tasks = mock.Mock(), mock.Mock()
+        unauthorized = request("wrong")
+        unauthorized.body = mock.AsyncMock(return_value=b"{}")
+
+        result = asyncio.run(
+            main.handle_tetragon_events(response, unauthorized, background_tasks)
+        )
+
+        self.assertEqual(response.status_code, 401)
+        self.assertEqual(result, dict(message=auth.WEBHOOK_AUTH_ERROR))
+        background_tasks.add_task.assert_not_called()
+
+    def test_rejects_unauthorized_triggers(self, *_):
+        response, background_tasks = mock.Mock(), mock.Mock()
+
+        main.handle_tetragon(response, request(), background_tasks)
+
+        self.assertEqual(response.status_code, 401)
+        background_tasks.add_task.assert_not_called()
+
+
+if __name__ == "__main__":
+    unittest.main()
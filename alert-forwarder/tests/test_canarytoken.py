@@ -0,0 +1,237 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import base64
+import json
+import unittest
+from unittest import mock
+
+from kubernetes.client.exceptions import ApiException
+
+from forwarder import canarytoken, main
+from forwarder.alerts import create_alert_description
+
+
+def canary_alert(**overrides) -> dict:
+    payload = {
+        "manage_url": "https://canary.example.com/manage?token=abc123&auth=secret",
+        "memo": "Koney honeytoken /root/.aws/credentials of DeceptionPolicy dp",
+        "channel": "AWS API Key Token",
+        "time": "2025-01-03 18:47:56 (UTC)",
+        "src_ip": "203.0.113.7",
+        "additional_data": {"eventName": "GetCallerIdentity"},
+    }
+    payload.update(overrides)
+    return payload
+
+
+CANARY_TOKEN = {
+    "filePath": "/root/.aws/credentials",
+    "kind": "awsKeys",
+    "token": "abc123",
+}
+
+
+def deception_policy(token_placements: list[dict] | None = None) -> dict:
+    return {
+        "metadata": {"name": "dp", "generation": 3},
+        "status": {
+            "canaryTokens": [
+                {
+                    "filePath": "/root/.aws/credentials",
+                    "kind": "awsKeys",
+                    "secretName": "koney-canarytoken-1",
+                },
+            ],
+            "traps": [
+                {
+                    "index": 0,
+                    "trapType": "FilesystemHoneytoken",
+                    "trapHash": "hash",
+                    "placements": [
+                        {
+                            "kind": "Deployment",
+                            "namespace": "payments",
+                            "name": "api",
+                            "filePaths": ["/root/.aws/credentials"],
+                        }
+                    ],
+                    "tokenPlacements": token_placements or [],
+                }
+            ],
+        },
+    }
+
+
+def token_placement(pod: str) -> dict:
+    return {
+        "filePath": "/root/.aws/credentials",
+        "namespace": "payments",
+        "pod": pod,
+        "node": "node-1",
+    }
+
+
+def canary_token_secret(policy_name: str, **data: str) -> mock.Mock:
+    secret = mock.Mock()
+    secret.metadata.labels = {
+        "koney/canary-token": "true",
+        "koney/deception-policy": policy_name,
+    }
+    secret.data = {
+        key: base64.b64encode(value.encode()).decode() for key, value in data.items()
+    }
+    return secret
+
+
+class FakeCoreV1Api:
+    def __init__(self, secrets: list[mock.Mock]):
+        self.secrets = secrets
+        self.label_selectors = []
+
+    def list_namespaced_secret(self, namespace, label_selector=None):
+        self.label_selectors.append(label_selector)
+        return mock.Mock(items=self.secrets)
+
+
+class FakeCustomObjectsApi:
+    def __init__(self, policies: list[dict]):
+        self.policies = policies
+
+    def get_cluster_custom_object(self, *args):
+        for policy in self.policies:
+            if policy["metadata"]["name"] == args[-1]:
+                return policy
+        not_found = ApiException()
+        not_found.status = 404
+        raise not_found
+
+
+class FindCanaryTokenTest(unittest.TestCase):
+    def test_extracts_the_token(self):
+        self.assertEqual(canarytoken.extract_canary_token(canary_alert()), "abc123")
+        self.assertEqual(
+            canarytoken.extract_canary_token(canary_alert(token="def456")), "def456"
+        )
+        self.assertIsNone(canarytoken.extract_canary_token({}))
+
+    def test_finds_the_deception_policy_that_provisioned_the_token(self):
+        policy = deception_policy()
+        core_api = FakeCoreV1Api(
+            [
+                canary_token_secret("other", token="def456", kind="dns"),
+                canary_token_secret("dp", **CANARY_TOKEN),
+                canary_token_secret("deleted", token="ghi789", kind="dns"),
+            ]
+        )
+        custom_api = FakeCustomObjectsApi([{"metadata": {"name": "other"}}, policy])
+        with (
+            mock.patch.object(
+                canarytoken.client, "CoreV1Api", return_value=core_api, create=True
+            ),
+            mock.patch.object(
+                canarytoken.client,
+                "CustomObjectsApi",
+                return_value=custom_api,
+                create=True,
+            ),
+        ):
+            found = canarytoken.find_canary_token("abc123")
+            self.assertIsNotNone(found)
+            self.assertEqual(found[0], policy)
+            self.assertEqual(found[1], CANARY_TOKEN)
+            self.assertIsNone(canarytoken.find_canary_token("unknown"))
+            self.assertIsNone(canarytoken.find_canary_token("ghi789"))
+        self.assertEqual(core_api.label_selectors[0], "koney/canary-token=true")
+
+
+class ProcessCanaryTokenAlertTest(unittest.TestCase):
+    def process(self, policy: dict) -> dict:
+        return canarytoken.process_canary_token_alert(
+            canary_alert(), policy, CANARY_TOKEN
+        )
+
+    def test_maps_triggers_to_the_pod_of_the_honeytoken(self):
+        alert = self.process(deception_policy([token_placement("api-7d9f")]))
+
+        self.assertEqual(alert["timestamp"], "2025-01-03T18:47:56+00:00")
+        self.assertEqual(alert["deception_policy_name"], "dp")
+        self.assertEqual(alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(alert["confidence"], "high")
+        self.assertEqual(alert["tags"], {"koney.canarytoken": "awsKeys"})
+        self.assertEqual(alert["metadata"]["file_path"], "/root/.aws/credentials")
+        self.assertEqual(alert["metadata"]["canary_token"], "abc123")
+        self.assertEqual(alert["metadata"]["src_ip"], "203.0.113.7")
+        self.assertEqual(alert["pod"]["name"], "api-7d9f")
+        self.assertEqual(alert["pod"]["namespace"], "payments")
+        self.assertEqual(alert["node"]["name"], "node-1")
+        self.assertEqual(alert["trap"]["hash"], "hash")
+        self.assertEqual(alert["trap"]["deception_policy_generation"], 3)
+        self.assertIsNone(alert["process"])
+        self.assertIn("Canary token (awsKeys)", create_alert_description(alert))
+
+    def test_lists_the_placements_if_the_pod_is_ambiguous(self):
+        alert = self.process(
+            deception_policy([token_placement("api-7d9f"), token_placement("api-8c2e")])
+        )
+
+        self.assertIsNone(alert["pod"])
+        self.assertEqual(
+            alert["metadata"]["placements"], ["payments/api-7d9f", "payments/api-8c2e"]
+        )
+
+    def test_leaves_the_pod_unknown_without_placements(self):
+        alert = self.process(deception_policy())
+
+        self.assertIsNone(alert["pod"])
+        self.assertNotIn("placements", alert["metadata"])
+        self.assertEqual(alert["trap"]["hash"], "hash")
+
+
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+class HandleCanaryTokenTest(unittest.TestCase):
+    def handle(self, payload: dict, found: tuple | None) -> tuple[mock.Mock, mock.Mock]:
+        request = mock.Mock()
+        request.body = mock.AsyncMock(return_value=json.dumps(payload).encode())
+        response = mock.Mock()
+        with (
+            mock.patch.object(main, "is_authorized_request", return_value=True),
+            mock.patch.object(main, "find_canary_token", return_value=found),
+            mock.patch.object(main, "enrich_alert"),
+            mock.patch.object(main, "try_read_alert_sinks", return_value=[]),
+            mock.patch.object(main, "forward_alert") as forward_alert,
+        ):
+            asyncio.run(main.handle_canary_token(response, request))
+        return forward_alert, response
+
+    def test_forwards_triggers_of_provisioned_canary_tokens(self, *_):
+        policy = deception_policy([token_placement("api-7d9f")])
+        found = (policy, CANARY_TOKEN)
+        forward_alert, _ = self.handle(canary_alert(), found)
+
+        forward_alert.assert_called_once()
+        alert = forward_alert.call_args.args[0]
+        self.assertEqual(alert["pod"]["name"], "api-7d9f")
+
+    def test_rejects_triggers_of_unknown_canary_tokens(self, *_):
+        forward_alert, response = self.handle(canary_alert(), None)
+
+        forward_alert.assert_not_called()
+        self.assertEqual(response.status_code, 404)
+
+
+if __name__ == "__main__":
+    unittest.main()
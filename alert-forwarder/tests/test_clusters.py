@@ -0,0 +1,151 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import json
+import unittest
+from unittest import mock
+
+from forwarder import clusters, main
+from forwarder.clusters import (
+    authenticate_cluster,
+    parse_cluster_alerts,
+    parse_cluster_tokens,
+)
+from forwarder.dedup import EventCache
+
+TOKENS = {"t0k3n-eu": "prod-eu", "t0k3n-us": "prod-us"}
+
+
+def request(token: str | None = None, body: bytes = b"") -> mock.Mock:
+    headers = {"Authorization": f"Bearer {token}"} if token is not None else {}
+    return mock.Mock(headers=headers, body=mock.AsyncMock(return_value=body))
+
+
+def alert(**overrides) -> dict:
+    return {
+        "schema_version": "1.1",
+        "timestamp": "2025-01-03T18:47:56Z",
+        "deception_policy_name": "deceptionpolicy-servicetoken",
+        "trap_type": "filesystem_honeytoken",
+        "pod": {"name": "nginx", "namespace": "koney-demo"},
+        **overrides,
+    }
+
+
+class ParseClusterTokensTest(unittest.TestCase):
+    def test_parses_pairs_of_cluster_names_and_tokens(self):
+        self.assertEqual(
+            parse_cluster_tokens(" prod-eu=t0k3n-eu, prod-us = t0k3n-us "), TOKENS
+        )
+
+    def test_ignores_incomplete_pairs(self):
+        self.assertEqual(parse_cluster_tokens("prod-eu=,=t0k3n,prod-us"), {})
+        self.assertEqual(parse_cluster_tokens(""), {})
+
+
+@mock.patch.object(clusters, "CLUSTER_TOKENS", TOKENS)
+class AuthenticateClusterTest(unittest.TestCase):
+    def test_identifies_the_cluster_by_its_token(self):
+        self.assertEqual(authenticate_cluster(request("t0k3n-us")), "prod-us")
+
+    def test_rejects_unknown_tokens(self):
+        self.assertIsNone(authenticate_cluster(request("wrong")))
+        self.assertIsNone(authenticate_cluster(request("")))
+        self.assertIsNone(authenticate_cluster(request()))
+
+
+class ParseClusterAlertsTest(unittest.TestCase):
+    def test_parses_single_alerts_and_arrays(self):
+        self.assertEqual(parse_cluster_alerts(json.dumps(alert()).encode()), [alert()])
+        self.assertEqual(
+            parse_cluster_alerts(json.dumps([alert(), alert()]).encode()),
+            [alert(), alert()],
+        )
+
+    def test_rejects_invalid_alerts(self):
+        self.assertIsNone(parse_cluster_alerts(b"not json"))
+        self.assertIsNone(parse_cluster_alerts(b'{"timestamp": "2025-01-03"}'))
+        self.assertIsNone(parse_cluster_alerts(b'["alert"]'))
+
+
+@mock.patch.object(clusters, "CLUSTER_TOKENS", TOKENS)
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+@mock.patch.object(main, "try_read_alert_sinks", return_value=[])
+@mock.patch.object(main, "forward_alert", return_value=True)
+class HandleClusterTest(unittest.TestCase):
+    def setUp(self):
+        cache = EventCache(max_size=10, ttl_seconds=60)
+        patcher = mock.patch.object(clusters, "cluster_alert_cache", cache)
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+    def handle(self, token: str, body: dict | list) -> tuple[mock.Mock, dict | None]:
+        response = mock.Mock()
+        req = request(token, json.dumps(body).encode())
+        return response, asyncio.run(main.handle_cluster(response, req))
+
+    def test_forwards_alerts_attributed_to_the_cluster(self, forward_alert, *_):
+        _, result = self.handle("t0k3n-eu", alert(cluster={"name": "prod-us"}))
+
+        self.assertIsNone(result)
+        forwarded = forward_alert.call_args.args[0]
+        self.assertEqual(forwarded["cluster"], {"name": "prod-eu"})
+        self.assertFalse(forward_alert.call_args.kwargs["aggregate"])
+
+    def test_forwards_alerts_that_are_posted_again_once(self, forward_alert, *_):
+        self.handle("t0k3n-eu", [alert(), alert()])
+        self.handle("t0k3n-eu", alert())
+        self.assertEqual(forward_alert.call_count, 1)
+
+        # the same access in another cluster is another alert
+        self.handle("t0k3n-us", alert())
+        self.assertEqual(forward_alert.call_count, 2)
+
+    def test_lets_clusters_retry_alerts_that_were_not_forwarded(
+        self, forward_alert, *_
+    ):
+        forward_alert.return_value = False
+        response, _ = self.handle("t0k3n-eu", alert())
+        self.assertEqual(response.status_code, 503)
+
+        forward_alert.return_value = True
+        response, result = self.handle("t0k3n-eu", alert())
+        self.assertIsNone(result)
+        self.assertEqual(forward_alert.call_count, 2)
+
+    def test_rejects_unauthorized_alerts(self, forward_alert, *_):
+        response, result = self.handle("wrong", alert())
+
+        self.assertEqual(response.status_code, 401)
+        self.assertEqual(result, dict(message=clusters.CLUSTER_AUTH_ERROR))
+        forward_alert.assert_not_called()
+
+    def test_rejects_invalid_alerts(self, forward_alert, *_):
+        response, _ = self.handle("t0k3n-eu", {"message": "hello"})
+
+        self.assertEqual(response.status_code, 400)
+        forward_alert.assert_not_called()
+
+    def test_is_disabled_without_cluster_tokens(self, forward_alert, *_):
+        with mock.patch.object(clusters, "CLUSTER_TOKENS", {}):
+            response, _ = self.handle("t0k3n-eu", alert())
+
+        self.assertEqual(response.status_code, 404)
+        forward_alert.assert_not_called()
+
+
+if __name__ == "__main__":
+    unittest.main()
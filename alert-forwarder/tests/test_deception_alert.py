@@ -0,0 +1,120 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import os
+import unittest
+from unittest import mock
+
+from kubernetes.client.exceptions import ApiException
+
+from forwarder import deception_alert
+
+ALERT = {
+    "timestamp": "2025-01-03T18:47:56.123456789Z",
+    "deception_policy_name": "deceptionpolicy-servicetoken",
+    "trap_type": "filesystem_honeytoken",
+    "severity": "HIGH",
+    "confidence": None,
+    "tags": {},
+    "trap": {"hash": "0b4a1bd5"},
+    "metadata": {"file_path": "/run/secrets/koney/service_token"},
+    "pod": {
+        "name": "nginx-1",
+        "namespace": "koney-demo",
+        "container": {"id": "e19c", "name": "nginx"},
+    },
+    "node": {"name": "minikube"},
+    "process": {"uid": 0, "pid": 148373, "cwd": "/", "binary": "/usr/bin/cat"},
+    "response": None,
+}
+
+
+class BuildDeceptionAlertTest(unittest.TestCase):
+    def test_records_the_alert(self):
+        obj = deception_alert.build_deception_alert(ALERT)
+
+        self.assertEqual(obj["kind"], "DeceptionAlert")
+        self.assertTrue(obj["metadata"]["name"].startswith("alert-"))
+        self.assertEqual(obj["spec"]["timestamp"], "2025-01-03T18:47:56Z")
+        self.assertEqual(obj["spec"]["trapHash"], "0b4a1bd5")
+        self.assertEqual(
+            obj["spec"]["pod"],
+            {"name": "nginx-1", "namespace": "koney-demo", "containerName": "nginx"},
+        )
+        self.assertEqual(
+            obj["spec"]["process"], {"binary": "/usr/bin/cat", "pid": 148373, "uid": 0}
+        )
+        self.assertIs(obj["spec"]["alert"], ALERT)
+
+    def test_labels_the_alert(self):
+        labels = deception_alert.build_deception_alert(ALERT)["metadata"]["labels"]
+
+        self.assertEqual(
+            labels,
+            {
+                "koney/deception-policy": "deceptionpolicy-servicetoken",
+                "koney/severity": "high",
+                "koney/pod-namespace": "koney-demo",
+            },
+        )
+
+    def test_omits_unknown_fields_and_long_labels(self):
+        alert = dict(ALERT, deception_policy_name="p" * 64, pod=None, process=None)
+        obj = deception_alert.build_deception_alert(alert)
+
+        self.assertNotIn("pod", obj["spec"])
+        self.assertNotIn("process", obj["spec"])
+        self.assertEqual(obj["spec"]["deceptionPolicyName"], "p" * 64)
+        self.assertNotIn("koney/deception-policy", obj["metadata"]["labels"])
+
+    def test_names_the_same_alert_the_same(self):
+        self.assertEqual(
+            deception_alert.build_deception_alert(ALERT)["metadata"]["name"],
+            deception_alert.build_deception_alert(dict(ALERT))["metadata"]["name"],
+        )
+
+
+@mock.patch.object(deception_alert.client, "CustomObjectsApi", create=True)
+class RecordAlertTest(unittest.TestCase):
+    def test_does_not_record_by_default(self, api):
+        with mock.patch.dict(os.environ, {}, clear=True):
+            deception_alert.record_alert(ALERT)
+
+        api.return_value.create_namespaced_custom_object.assert_not_called()
+
+    def test_records_once(self, api):
+        conflict = ApiException()
+        conflict.status = 409
+        create = api.return_value.create_namespaced_custom_object
+        create.side_effect = [None, conflict]
+        with mock.patch.dict(os.environ, {"KONEY_DECEPTION_ALERTS": "true"}):
+            deception_alert.record_alert(ALERT)
+            deception_alert.record_alert(ALERT)
+
+        self.assertEqual(create.call_count, 2)
+        self.assertEqual(create.call_args.args[4]["kind"], "DeceptionAlert")
+
+    def test_raises_other_errors(self, api):
+        forbidden = ApiException()
+        forbidden.status = 403
+        create = api.return_value.create_namespaced_custom_object
+        create.side_effect = forbidden
+        with mock.patch.dict(os.environ, {"KONEY_DECEPTION_ALERTS": "true"}):
+            with self.assertRaises(ApiException):
+                deception_alert.record_alert(ALERT)
+
+
+if __name__ == "__main__":
+    unittest.main()
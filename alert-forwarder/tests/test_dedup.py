@@ -0,0 +1,167 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import os
+import tempfile
+import unittest
+from unittest import mock
+
+from forwarder import dedup, main
+from forwarder.dedup import EventCache
+
+
+@mock.patch.object(dedup, "time")
+class EventCacheTest(unittest.TestCase):
+    def test_counts_hits_and_misses(self, time):
+        time.time.return_value = 1000.0
+        cache = EventCache(max_size=10, ttl_seconds=60)
+
+        self.assertNotIn("a", cache)
+        cache.add("a")
+        self.assertIn("a", cache)
+        self.assertEqual((cache.hits, cache.misses), (1, 1))
+
+    def test_evicts_the_least_recently_seen_ids(self, time):
+        time.time.return_value = 1000.0
+        cache = EventCache(max_size=2, ttl_seconds=60)
+        cache.add("a")
+        cache.add("b")
+        self.assertIn("a", cache)  # b is now the least recently seen
+
+        cache.add("c")
+        self.assertIn("a", cache)
+        self.assertNotIn("b", cache)
+        self.assertEqual((len(cache), cache.evictions), (2, 1))
+
+    def test_expires_ids_after_the_ttl(self, time):
+        time.time.return_value = 1000.0
+        cache = EventCache(max_size=10, ttl_seconds=60)
+        cache.add("a")
+
+        time.time.return_value = 1059.0
+        self.assertIn("a", cache)  # seen again, so it expires later
+
+        time.time.return_value = 1118.0
+        self.assertIn("a", cache)
+
+        time.time.return_value = 1178.0
+        self.assertNotIn("a", cache)
+        self.assertEqual(len(cache), 0)
+
+    def test_forgets_ids(self, time):
+        time.time.return_value = 1000.0
+        cache = EventCache(max_size=10, ttl_seconds=60)
+        cache.add("a")
+        cache.add("b")
+
+        cache.difference_update({"a", "c"})
+        self.assertNotIn("a", cache)
+        self.assertIn("b", cache)
+
+    def test_dumps_and_restores_ids(self, time):
+        time.time.return_value = 1000.0
+        cache = EventCache(max_size=10, ttl_seconds=60)
+        self.assertIsNone(cache.dump())  # nothing changed
+        cache.add("a")
+        entries = cache.dump()
+        self.assertEqual(entries, {"a": 1000.0})
+        self.assertIsNone(cache.dump())
+
+        time.time.return_value = 1030.0
+        restored = EventCache(max_size=10, ttl_seconds=60)
+        restored.restore({**entries, "expired": 900.0})
+        self.assertIn("a", restored)
+        self.assertNotIn("expired", restored)
+
+
+class PersistEventCacheTest(unittest.TestCase):
+    def test_persists_the_cache_in_a_file(self):
+        with tempfile.TemporaryDirectory() as tmp_dir:
+            cache_file = os.path.join(tmp_dir, "event-cache.json")
+            with (
+                mock.patch.object(dedup, "EVENT_CACHE_PERSISTENCE", "file"),
+                mock.patch.object(dedup, "EVENT_CACHE_FILE", cache_file),
+            ):
+                cache = EventCache(max_size=10, ttl_seconds=60)
+                cache.add("a")
+                self.assertTrue(dedup.save_event_cache(cache))
+
+                restored = EventCache(max_size=10, ttl_seconds=60)
+                dedup.load_event_cache(restored)
+                self.assertIn("a", restored)
+
+    def test_does_not_persist_the_cache_by_default(self):
+        cache = EventCache(max_size=10, ttl_seconds=60)
+        cache.add("a")
+        with mock.patch.object(dedup, "_write_config_map") as write_config_map:
+            self.assertTrue(dedup.save_event_cache(cache))
+        write_config_map.assert_not_called()
+
+
+def access_alert(timestamp: str, pod_name: str = "web-1") -> dict:
+    return {
+        "timestamp": timestamp,
+        "deception_policy_name": "deceptionpolicy-sample",
+        "metadata": {"file_path": "/run/secrets/koney/token"},
+        "pod": {"name": pod_name, "namespace": "default"},
+    }
+
+
+@mock.patch.dict(dedup._accesses, clear=True)
+class DuplicateAccessTest(unittest.TestCase):
+    def test_skips_accesses_that_another_captor_reported(self):
+        alert = access_alert("2025-01-03T18:47:56.123456789Z")
+        self.assertFalse(dedup.is_duplicate_access(alert, "tetragon"))
+
+        later = access_alert("2025-01-03T18:47:57Z")
+        self.assertTrue(dedup.is_duplicate_access(later, "kive"))
+
+    def test_keeps_accesses_of_the_same_captor(self):
+        alert = access_alert("2025-01-03T18:47:56Z")
+        self.assertFalse(dedup.is_duplicate_access(alert, "tetragon"))
+        self.assertFalse(dedup.is_duplicate_access(alert, "tetragon"))
+
+    def test_keeps_other_pods_and_later_accesses(self):
+        alert = access_alert("2025-01-03T18:47:56Z")
+        self.assertFalse(dedup.is_duplicate_access(alert, "tetragon"))
+
+        other_pod = access_alert("2025-01-03T18:47:56Z", pod_name="web-2")
+        self.assertFalse(dedup.is_duplicate_access(other_pod, "kive"))
+        later = access_alert("2025-01-03T18:48:56Z")
+        self.assertFalse(dedup.is_duplicate_access(later, "kive"))
+
+    def test_can_be_disabled(self):
+        alert = access_alert("2025-01-03T18:47:56Z")
+        with mock.patch.object(dedup, "CAPTOR_DEDUP_WINDOW_SECONDS", 0):
+            self.assertFalse(dedup.is_duplicate_access(alert, "tetragon"))
+            self.assertFalse(dedup.is_duplicate_access(alert, "kive"))
+
+
+class MetricsTest(unittest.TestCase):
+    def test_exposes_cache_metrics(self):
+        cache = EventCache(max_size=10, ttl_seconds=60)
+        cache.add("a")
+        self.assertIn("a", cache)
+
+        with mock.patch.object(main, "event_cache", cache):
+            response = main.metrics()
+
+        self.assertIn("koney_event_cache_hits_total 1\n", response.body)
+        self.assertIn("koney_event_cache_misses_total 0\n", response.body)
+        self.assertIn("# TYPE koney_event_cache_size gauge\n", response.body)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,150 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+from forwarder import enrichment
+from forwarder.types import SCHEMA_VERSION, KoneyAlert
+
+
+def alert(node: dict | None = None) -> KoneyAlert:
+    return KoneyAlert(
+        schema_version=SCHEMA_VERSION,
+        timestamp="2025-01-03T18:47:56Z",
+        deception_policy_name="dp",
+        trap_type="filesystem_honeytoken",
+        severity=None,
+        confidence=None,
+        tags={},
+        trap=None,
+        metadata={},
+        pod={
+            "name": "nginx-7c5b-x2kq",
+            "namespace": "koney-demo",
+            "container": {"id": "e19c", "name": "nginx"},
+        },
+        node=node,
+        process=None,
+        response=None,
+    )
+
+
+def owner_reference(kind: str, name: str) -> SimpleNamespace:
+    return SimpleNamespace(kind=kind, name=name, controller=True)
+
+
+POD = SimpleNamespace(
+    metadata=SimpleNamespace(
+        namespace="koney-demo",
+        labels={"app": "nginx"},
+        annotations={
+            "team": "blue",
+            "kubectl.kubernetes.io/last-applied-configuration": "{}",
+        },
+        owner_references=[owner_reference("ReplicaSet", "nginx-7c5b")],
+    ),
+    spec=SimpleNamespace(node_name="worker-1"),
+    status=SimpleNamespace(
+        container_statuses=[
+            SimpleNamespace(
+                name="nginx",
+                image="docker.io/library/nginx:1.27",
+                image_id="docker.io/library/nginx@sha256:5f4e",
+            )
+        ]
+    ),
+)
+
+REPLICA_SET = SimpleNamespace(
+    metadata=SimpleNamespace(owner_references=[owner_reference("Deployment", "nginx")])
+)
+
+NODE = SimpleNamespace(
+    metadata=SimpleNamespace(
+        labels={
+            "topology.kubernetes.io/region": "eu-west-1",
+            "topology.kubernetes.io/zone": "eu-west-1a",
+        }
+    ),
+    spec=SimpleNamespace(provider_id="aws:///eu-west-1a/i-0abc"),
+)
+
+
+def read(kind: str, namespace: str, name: str):
+    return {"Pod": POD, "ReplicaSet": REPLICA_SET, "Node": NODE}[kind]
+
+
+@mock.patch.object(
+    enrichment, "_cached", side_effect=lambda key, _: read(*key), autospec=True
+)
+class EnrichAlertTest(unittest.TestCase):
+    def enrich(self, koney_alert: KoneyAlert, names: str) -> None:
+        with mock.patch.object(enrichment, "ENABLED_ENRICHERS", names):
+            enrichment.enrich_alert(koney_alert)
+
+    def test_resolves_the_top_level_owner(self, _):
+        koney_alert = alert()
+        self.enrich(koney_alert, "owner")
+        self.assertEqual(
+            koney_alert["pod"]["owner"], {"kind": "Deployment", "name": "nginx"}
+        )
+
+    def test_attaches_labels_and_annotations(self, _):
+        koney_alert = alert()
+        self.enrich(koney_alert, "labels")
+        self.assertEqual(koney_alert["pod"]["labels"], {"app": "nginx"})
+        self.assertEqual(koney_alert["pod"]["annotations"], {"team": "blue"})
+
+    def test_attaches_the_image_and_its_digest(self, _):
+        koney_alert = alert()
+        self.enrich(koney_alert, "image")
+        container = koney_alert["pod"]["container"]
+        self.assertEqual(container["image"], "docker.io/library/nginx:1.27")
+        self.assertEqual(container["image_digest"], "sha256:5f4e")
+
+    def test_attaches_the_node_and_its_cloud_metadata(self, _):
+        koney_alert = alert()
+        self.enrich(koney_alert, "node,cloud")
+        self.assertEqual(
+            koney_alert["node"],
+            {
+                "name": "worker-1",
+                "cloud": {
+                    "provider": "aws",
+                    "region": "eu-west-1",
+                    "zone": "eu-west-1a",
+                    "instance_type": None,
+                },
+            },
+        )
+
+    def test_runs_only_the_enabled_enrichers(self, _):
+        koney_alert = alert(node={"name": "worker-1"})
+        self.enrich(koney_alert, "image, unknown")
+        self.assertNotIn("owner", koney_alert["pod"])
+        self.assertNotIn("labels", koney_alert["pod"])
+        self.assertNotIn("cloud", koney_alert["node"])
+
+    def test_keeps_enriching_if_an_enricher_fails(self, cached):
+        cached.side_effect = lambda key, _: NODE if key[0] == "Node" else POD
+        koney_alert = alert(node={"name": "worker-1"})
+        with mock.patch.dict(
+            enrichment.ENRICHERS, owner=mock.Mock(side_effect=RuntimeError("boom"))
+        ):
+            with self.assertLogs(enrichment.logger, "WARNING"):
+                self.enrich(koney_alert, "owner,cloud")
+        self.assertEqual(koney_alert["node"]["cloud"]["provider"], "aws")
@@ -0,0 +1,123 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import json
+import unittest
+from unittest import mock
+
+from forwarder import main
+from forwarder.falco import is_koney_falco_alert, process_falco_alert
+
+
+def falco_alert(**overrides) -> dict:
+    payload = {
+        "uuid": "2f3bb4b6-0b1c-4c2e-9d3a-6f7c1d2e3f40",
+        "output": "Koney filesystem honeytoken accessed (...)",
+        "priority": "Error",
+        "rule": "koney-tracing-policy-0123456789abcdef0123456789abcdef",
+        "time": "2025-01-03T18:47:56.123456789Z",
+        "source": "syscall",
+        "hostname": "node-1",
+        "output_fields": {
+            "fd.name": "/run/secrets/koney/token",
+            "k8s.ns.name": "default",
+            "k8s.pod.name": "web-7d4b9c8f6-abcde",
+            "container.name": "app",
+            "container.id": "3ad6e8f0c2b1",
+            "proc.pid": 4242,
+            "proc.exepath": "/usr/bin/cat",
+            "proc.args": "/run/secrets/koney/token",
+            "proc.cwd": "/app/",
+            "user.uid": 1000,
+        },
+        "tags": [
+            "koney",
+            "koney-deception-policy-name=deceptionpolicy-sample",
+            "koney-deception-policy-generation=3",
+            "koney-file-path=/run/secrets/koney/token",
+            "koney-severity=high",
+            'koney-alert-tags={"team": "payments"}',
+            'koney-attck-techniques=["T1552.001"]',
+            "koney-trap-hash=abc123",
+            "koney-trap-description=a token with = signs",
+        ],
+    }
+    payload.update(overrides)
+    return payload
+
+
+class ProcessFalcoAlertTest(unittest.TestCase):
+    def test_maps_falcosidekick_payloads(self):
+        alert = process_falco_alert(falco_alert())
+
+        self.assertEqual(alert["timestamp"], "2025-01-03T18:47:56.123456789Z")
+        self.assertEqual(alert["deception_policy_name"], "deceptionpolicy-sample")
+        self.assertEqual(alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(alert["severity"], "HIGH")
+        self.assertEqual(alert["tags"], {"team": "payments"})
+        self.assertEqual(alert["metadata"], {"file_path": "/run/secrets/koney/token"})
+        self.assertEqual(alert["pod"]["name"], "web-7d4b9c8f6-abcde")
+        self.assertEqual(alert["pod"]["container"]["id"], "3ad6e8f0c2b1")
+        self.assertEqual(alert["node"]["name"], "node-1")
+        self.assertEqual(alert["process"]["binary"], "/usr/bin/cat")
+        self.assertEqual(alert["process"]["uid"], 1000)
+        self.assertIsNone(alert["response"])
+
+    def test_reads_the_trap_from_the_tags(self):
+        trap = process_falco_alert(falco_alert())["trap"]
+
+        self.assertEqual(trap["hash"], "abc123")
+        self.assertEqual(trap["deception_policy_generation"], 3)
+        self.assertEqual(trap["description"], "a token with = signs")
+        self.assertEqual(trap["attck_techniques"], ["T1552.001"])
+
+    def test_only_accepts_alerts_of_koney_rules(self):
+        self.assertTrue(is_koney_falco_alert(falco_alert()))
+        self.assertFalse(is_koney_falco_alert(falco_alert(tags=["filesystem"])))
+        self.assertFalse(is_koney_falco_alert(falco_alert(tags=None)))
+
+
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+@mock.patch.object(main, "is_authorized_request", return_value=True)
+class HandleFalcoTest(unittest.TestCase):
+    def handle(self, payload: dict) -> mock.Mock:
+        request = mock.Mock()
+        request.body = mock.AsyncMock(return_value=json.dumps(payload).encode())
+        with (
+            mock.patch.object(main, "is_filtered_alert", return_value=False),
+            mock.patch.object(main, "is_duplicate_access", return_value=False),
+            mock.patch.object(main, "enrich_alert"),
+            mock.patch.object(main, "try_read_alert_sinks", return_value=[]),
+            mock.patch.object(main, "forward_alert") as forward_alert,
+        ):
+            asyncio.run(main.handle_falco(mock.Mock(), request))
+        return forward_alert
+
+    def test_forwards_alerts_of_koney_rules(self, *_):
+        forward_alert = self.handle(falco_alert())
+
+        forward_alert.assert_called_once()
+        alert = forward_alert.call_args.args[0]
+        self.assertEqual(alert["deception_policy_name"], "deceptionpolicy-sample")
+
+    def test_ignores_alerts_of_other_rules(self, *_):
+        forward_alert = self.handle(falco_alert(rule="Terminal shell", tags=[]))
+
+        forward_alert.assert_not_called()
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,88 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from kubernetes.client.exceptions import ApiException
+
+from forwarder import features
+
+
+class IsFeatureEnabledTest(unittest.TestCase):
+    def test_disables_unlisted_features(self):
+        self.assertFalse(
+            features.is_feature_enabled(
+                [], features.PROCESS_ANCESTRY_ENRICHMENT_FEATURE, "koney-demo"
+            )
+        )
+
+    def test_enables_features_for_the_whole_cluster(self):
+        flags = [{"name": "ProcessAncestryEnrichment", "enabled": True}]
+        for namespace in ["koney-demo", ""]:
+            self.assertTrue(
+                features.is_feature_enabled(
+                    flags, features.PROCESS_ANCESTRY_ENRICHMENT_FEATURE, namespace
+                )
+            )
+
+        flags = [{"name": "ProcessAncestryEnrichment", "enabled": False}]
+        self.assertFalse(
+            features.is_feature_enabled(
+                flags, features.PROCESS_ANCESTRY_ENRICHMENT_FEATURE, "koney-demo"
+            )
+        )
+
+    def test_enables_features_only_for_the_listed_namespaces(self):
+        flags = [
+            {
+                "name": "ProcessAncestryEnrichment",
+                "enabled": True,
+                "namespaces": ["koney-demo"],
+            }
+        ]
+        feature = features.PROCESS_ANCESTRY_ENRICHMENT_FEATURE
+        self.assertTrue(features.is_feature_enabled(flags, feature, "koney-demo"))
+        self.assertFalse(features.is_feature_enabled(flags, feature, "default"))
+        self.assertFalse(features.is_feature_enabled(flags, feature, ""))
+
+
+class ReadFeatureFlagsTest(unittest.TestCase):
+    def read(self, result=None, status: int | None = None) -> list[dict]:
+        api = mock.Mock()
+        if status:
+            e = ApiException()
+            e.status = status
+            api.get_cluster_custom_object.side_effect = e
+        else:
+            api.get_cluster_custom_object.return_value = result
+        with mock.patch.object(
+            features.client, "CustomObjectsApi", return_value=api, create=True
+        ):
+            return features.read_feature_flags()
+
+    def test_reads_the_feature_flags_of_the_koney_config(self):
+        flags = [{"name": "ProcessAncestryEnrichment", "enabled": True}]
+        self.assertEqual(self.read({"spec": {"featureFlags": flags}}), flags)
+        self.assertEqual(self.read({"spec": {}}), [])
+
+    def test_disables_all_features_without_a_readable_koney_config(self):
+        self.assertEqual(self.read(status=404), [])
+        self.assertEqual(self.read(status=403), [])
+        self.assertEqual(self.read(status=500), [])
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,74 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import base64
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+from kubernetes.client.exceptions import ApiException
+
+from forwarder import fingerprint
+
+
+def fingerprint_secret(value: str | None):
+    data = None
+    if value is not None:
+        encoded = base64.b64encode(value.encode("utf-8")).decode("utf-8")
+        data = {fingerprint.FINGERPRINT_SECRET_KEY: encoded}
+    return SimpleNamespace(data=data)
+
+
+@mock.patch.object(fingerprint.client, "V1Secret", create=True)
+@mock.patch.object(fingerprint.client, "CoreV1Api", create=True)
+class ReadFingerprintTest(unittest.TestCase):
+    def setUp(self):
+        fingerprint._fingerprint_cache = None
+
+    def test_reads_the_fingerprint_from_the_secret(self, core_v1_api, _):
+        read_secret = core_v1_api.return_value.read_namespaced_secret
+        read_secret.return_value = fingerprint_secret("424242\n")
+
+        self.assertEqual(fingerprint.read_fingerprint(), 424242)
+        read_secret.assert_called_once_with(
+            fingerprint.FINGERPRINT_SECRET_NAME, fingerprint.KONEY_NAMESPACE
+        )
+
+    def test_returns_none_without_a_valid_fingerprint(self, core_v1_api, _):
+        read_secret = core_v1_api.return_value.read_namespaced_secret
+        for secret in [fingerprint_secret(None), fingerprint_secret("not-a-number")]:
+            read_secret.return_value = secret
+            self.assertIsNone(fingerprint.read_fingerprint())
+
+    def test_caches_the_fingerprint(self, core_v1_api, _):
+        read_secret = core_v1_api.return_value.read_namespaced_secret
+        read_secret.return_value = fingerprint_secret("424242")
+
+        self.assertEqual(fingerprint.read_cached_fingerprint(), 424242)
+        self.assertEqual(fingerprint.read_cached_fingerprint(), 424242)
+        read_secret.assert_called_once()
+
+    def test_keeps_the_last_known_fingerprint_if_the_lookup_fails(self, core_v1_api, _):
+        read_secret = core_v1_api.return_value.read_namespaced_secret
+        read_secret.return_value = fingerprint_secret("424242")
+        self.assertEqual(fingerprint.read_cached_fingerprint(), 424242)
+
+        e = ApiException()
+        e.status = 503
+        read_secret.side_effect = e
+        with mock.patch.object(fingerprint.time, "time", return_value=2e10):
+            self.assertEqual(fingerprint.read_cached_fingerprint(), 424242)
+        read_secret.assert_called()
+        self.assertEqual(read_secret.call_count, 2)
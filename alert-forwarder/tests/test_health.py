@@ -0,0 +1,103 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import threading
+import unittest
+from unittest import mock
+
+from forwarder import health, main
+
+
+class RunHealthChecksTest(unittest.TestCase):
+    def test_is_healthy_if_all_required_checks_pass(self):
+        healthy, results = health.run_health_checks(
+            {"a": lambda: None, "b": lambda: "unreachable"}, required=["a"]
+        )
+
+        self.assertTrue(healthy)
+        self.assertEqual(
+            results,
+            {
+                "a": {"status": "ok", "required": True, "error": None},
+                "b": {"status": "failing", "required": False, "error": "unreachable"},
+            },
+        )
+
+    def test_reports_checks_that_raise(self):
+        def check():
+            raise RuntimeError("boom")
+
+        healthy, results = health.run_health_checks({"a": check}, required=["a"])
+
+        self.assertFalse(healthy)
+        self.assertEqual(results["a"]["error"], "the check failed: boom")
+
+    def test_lists_the_errors_of_failing_sinks(self):
+        self.assertIsNone(health.check_sinks({}))
+        self.assertEqual(
+            health.check_sinks({"webhook": "503", "dynatrace": "timeout"}),
+            "dynatrace: timeout; webhook: 503",
+        )
+
+
+@mock.patch.object(main, "check_kubernetes", return_value=None)
+@mock.patch.object(main, "check_tetragon", return_value=None)
+@mock.patch.object(main, "failing_sinks", return_value={"webhook": "503"})
+class ReadyzTest(unittest.TestCase):
+    def test_is_ready_although_sinks_fail_unless_required(self, *_):
+        response = mock.Mock(status_code=200)
+
+        body = main.readyz(response)
+
+        self.assertTrue(body["ready"])
+        self.assertEqual(response.status_code, 200)
+        self.assertEqual(body["checks"]["sinks"]["status"], "failing")
+
+    def test_is_not_ready_if_a_required_check_fails(self, _, check_tetragon, __):
+        check_tetragon.return_value = "failed to list Tetragon pods"
+        response = mock.Mock(status_code=200)
+
+        body = main.readyz(response)
+
+        self.assertFalse(body["ready"])
+        self.assertEqual(response.status_code, 503)
+
+    def test_is_not_ready_if_triggered_runs_stall(self, *_):
+        response = mock.Mock(status_code=200)
+        with mock.patch.object(main.log_triggers, "is_stalled", return_value=True):
+            body = main.readyz(response)
+
+        self.assertFalse(body["ready"])
+        self.assertEqual(response.status_code, 503)
+
+
+class LivezTest(unittest.TestCase):
+    def test_is_not_live_if_a_background_thread_stopped(self):
+        thread = threading.Thread(target=lambda: None, name="redelivery")
+        thread.start()
+        thread.join()
+        response = mock.Mock(status_code=204)
+
+        with mock.patch.object(main, "periodic_threads", [thread]):
+            body = main.livez(response)
+
+        self.assertEqual(response.status_code, 503)
+        self.assertIn("redelivery", body["message"])
+
+    def test_is_live_while_background_threads_run(self):
+        response = mock.Mock(status_code=204)
+        with mock.patch.object(main, "periodic_threads", [threading.current_thread()]):
+            self.assertIsNone(main.livez(response))
+        self.assertEqual(response.status_code, 204)
@@ -0,0 +1,184 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from forwarder import informer, tetragon
+from forwarder.informer import CustomObjectInformer
+
+
+def tracing_policy(name: str, labels: dict | None = None) -> dict:
+    return {"metadata": {"name": name, "labels": labels or {}}}
+
+
+def namespaced_tracing_policy(namespace: str, name: str) -> dict:
+    return {"metadata": {"namespace": namespace, "name": name, "labels": {}}}
+
+
+class CustomObjectInformerTest(unittest.TestCase):
+    def setUp(self):
+        self.informer = CustomObjectInformer("cilium.io", "v1alpha1", "tracingpolicies")
+
+    def test_reads_objects_from_the_api_until_synced(self):
+        api = mock.Mock()
+        api.get_cluster_custom_object.return_value = tracing_policy("a")
+        with mock.patch.object(
+            informer.client, "CustomObjectsApi", return_value=api, create=True
+        ):
+            self.assertEqual(self.informer.get("a"), tracing_policy("a"))
+            self.assertIsNone(self.informer.list_objects())
+
+            self.informer.replace([tracing_policy("b")])
+            self.assertIsNone(self.informer.get("a"))
+            self.assertEqual(self.informer.get("b"), tracing_policy("b"))
+
+        api.get_cluster_custom_object.assert_called_once()
+
+    def test_returns_none_for_missing_objects(self):
+        not_found = informer.ApiException()
+        not_found.status = 404
+        api = mock.Mock()
+        api.get_cluster_custom_object.side_effect = not_found
+        with mock.patch.object(
+            informer.client, "CustomObjectsApi", return_value=api, create=True
+        ):
+            self.assertIsNone(self.informer.get("a"))
+
+    def test_applies_watch_events(self):
+        self.informer.replace([tracing_policy("a"), tracing_policy("b")])
+
+        self.informer.apply("ADDED", tracing_policy("c"))
+        self.informer.apply("MODIFIED", tracing_policy("a", {"koney/severity": "high"}))
+        self.informer.apply("DELETED", tracing_policy("b"))
+        self.informer.apply("BOOKMARK", {"metadata": {"resourceVersion": "42"}})
+
+        self.assertEqual(
+            self.informer.list_objects(),
+            [tracing_policy("a", {"koney/severity": "high"}), tracing_policy("c")],
+        )
+
+    def test_lists_the_objects_then_watches_them(self):
+        api = mock.Mock()
+        api.list_cluster_custom_object.return_value = {
+            "metadata": {"resourceVersion": "1"},
+            "items": [tracing_policy("a")],
+        }
+        events = [
+            {"type": "ADDED", "object": tracing_policy("b")},
+            {"type": "DELETED", "object": tracing_policy("a")},
+        ]
+
+        def stream(*args, **kwargs):
+            self.assertEqual(kwargs["resource_version"], "1")
+            yield from events
+            self.informer.stop()
+
+        with (
+            mock.patch.object(
+                informer.client, "CustomObjectsApi", return_value=api, create=True
+            ),
+            mock.patch.object(informer.watch, "Watch") as Watch,
+        ):
+            Watch.return_value.stream.side_effect = stream
+            self.informer._run()
+
+        self.assertTrue(self.informer.synced.is_set())
+        self.assertEqual(self.informer.list_objects(), [tracing_policy("b")])
+
+
+class NamespacedCustomObjectInformerTest(unittest.TestCase):
+    def setUp(self):
+        self.informer = CustomObjectInformer(
+            "cilium.io", "v1alpha1", "tracingpoliciesnamespaced", namespaced=True
+        )
+
+    def test_keeps_objects_with_the_same_name_in_all_namespaces(self):
+        self.informer.replace(
+            [
+                namespaced_tracing_policy("a", "koney"),
+                namespaced_tracing_policy("b", "koney"),
+            ]
+        )
+
+        self.informer.apply("DELETED", namespaced_tracing_policy("a", "koney"))
+
+        self.assertEqual(
+            self.informer.get("koney"), namespaced_tracing_policy("b", "koney")
+        )
+        self.assertIsNone(self.informer.get("other"))
+
+    def test_looks_up_objects_by_name_until_synced(self):
+        api = mock.Mock()
+        api.list_cluster_custom_object.return_value = {
+            "items": [namespaced_tracing_policy("a", "koney")]
+        }
+        with mock.patch.object(
+            informer.client, "CustomObjectsApi", return_value=api, create=True
+        ):
+            self.assertEqual(
+                self.informer.get("koney"), namespaced_tracing_policy("a", "koney")
+            )
+
+        self.assertEqual(
+            api.list_cluster_custom_object.call_args.kwargs["field_selector"],
+            "metadata.name=koney",
+        )
+
+
+class ListKoneyTracingPolicyNamesTest(unittest.TestCase):
+    def test_lists_the_labeled_tracing_policies_from_the_cache(self):
+        cache = CustomObjectInformer("cilium.io", "v1alpha1", "tracingpolicies")
+        cache.replace(
+            [
+                tracing_policy("koney-a", {"koney/deception-policy": "dp"}),
+                tracing_policy("other"),
+            ]
+        )
+        namespaced_cache = CustomObjectInformer(
+            "cilium.io", "v1alpha1", "tracingpoliciesnamespaced", namespaced=True
+        )
+        namespaced_cache.replace([])
+        with (
+            mock.patch.object(tetragon, "tracing_policy_informer", cache),
+            mock.patch.object(
+                tetragon, "tracing_policy_namespaced_informer", namespaced_cache
+            ),
+        ):
+            self.assertEqual(tetragon.list_koney_tracing_policy_names(), {"koney-a"})
+
+    def test_lists_namespaced_tracing_policies_once(self):
+        cache = CustomObjectInformer("cilium.io", "v1alpha1", "tracingpolicies")
+        cache.replace([])
+        namespaced_cache = CustomObjectInformer(
+            "cilium.io", "v1alpha1", "tracingpoliciesnamespaced", namespaced=True
+        )
+        labels = {"koney/deception-policy": "dp"}
+        namespaced_cache.replace(
+            [
+                {"metadata": {"namespace": ns, "name": "koney-b", "labels": labels}}
+                for ns in ("a", "b")
+            ]
+        )
+        with (
+            mock.patch.object(tetragon, "tracing_policy_informer", cache),
+            mock.patch.object(
+                tetragon, "tracing_policy_namespaced_informer", namespaced_cache
+            ),
+        ):
+            self.assertEqual(tetragon.list_koney_tracing_policy_names(), {"koney-b"})
+            self.assertEqual(
+                tetragon.get_tracing_policy("koney-b")["metadata"]["name"], "koney-b"
+            )
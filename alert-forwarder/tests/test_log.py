@@ -0,0 +1,118 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import json
+import logging
+import sys
+import unittest
+from unittest import mock
+
+from forwarder import main
+from forwarder.log import JsonFormatter, alert_log_fields, log_context
+
+
+def format_record(msg: str, **extra) -> dict:
+    record = logging.makeLogRecord(
+        dict(name="uvicorn.error", levelname="WARNING", msg=msg, **extra)
+    )
+    return json.loads(JsonFormatter().format(record))
+
+
+class JsonFormatterTest(unittest.TestCase):
+    def test_formats_records_as_json(self):
+        entry = format_record("Failed to load watermarks")
+
+        self.assertEqual(entry["level"], "WARNING")
+        self.assertEqual(entry["logger"], "uvicorn.error")
+        self.assertEqual(entry["message"], "Failed to load watermarks")
+        self.assertIn("time", entry)
+
+    def test_adds_context_and_extra_fields(self):
+        with log_context(correlation_id="abc", deception_policy_name="policy"):
+            entry = format_record("Sending alert to webhook", url="https://example.com")
+
+        self.assertEqual(entry["correlation_id"], "abc")
+        self.assertEqual(entry["deception_policy_name"], "policy")
+        self.assertEqual(entry["url"], "https://example.com")
+
+    def test_adds_exceptions(self):
+        try:
+            raise RuntimeError("boom")
+        except RuntimeError:
+            record = logging.makeLogRecord(dict(msg="failed", exc_info=sys.exc_info()))
+        entry = json.loads(JsonFormatter().format(record))
+
+        self.assertIn("RuntimeError: boom", entry["exception"])
+
+
+class LogContextTest(unittest.TestCase):
+    def test_nests_and_resets_fields(self):
+        with log_context(correlation_id="abc", pod_name=None):
+            with log_context(alert_sink_name="sink"):
+                entry = format_record("inner")
+            outer = format_record("outer")
+        after = format_record("after")
+
+        self.assertEqual(entry["correlation_id"], "abc")
+        self.assertEqual(entry["alert_sink_name"], "sink")
+        self.assertNotIn("pod_name", entry)  # fields without values are omitted
+        self.assertNotIn("alert_sink_name", outer)
+        self.assertNotIn("correlation_id", after)
+
+    def test_extracts_alert_fields(self):
+        koney_alert = dict(
+            timestamp="2025-01-03T18:47:56Z",
+            deception_policy_name="policy",
+            trap_type="filesystem_honeytoken",
+            pod=dict(name="pod", namespace="default", container={}),
+        )
+
+        fields = alert_log_fields(koney_alert)
+
+        self.assertEqual(fields["deception_policy_name"], "policy")
+        self.assertEqual(fields["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(fields["pod_namespace"], "default")
+        self.assertEqual(fields["pod_name"], "pod")
+        self.assertTrue(fields["alert_id"])
+
+
+class CorrelationIdTest(unittest.TestCase):
+    def call_middleware(self, headers: dict) -> tuple[dict, dict]:
+        seen = {}
+
+        async def call_next(_):
+            seen.update(format_record("handled"))
+            return mock.Mock(headers={})
+
+        request = mock.Mock(headers=headers)
+        response = asyncio.run(main.bind_correlation_id(request, call_next))
+        return seen, response.headers
+
+    def test_keeps_the_correlation_id_of_the_caller(self):
+        entry, headers = self.call_middleware({"X-Request-ID": "abc"})
+
+        self.assertEqual(entry["correlation_id"], "abc")
+        self.assertEqual(headers["X-Request-ID"], "abc")
+
+    def test_generates_missing_correlation_ids(self):
+        entry, headers = self.call_middleware({})
+
+        self.assertEqual(len(entry["correlation_id"]), 32)
+        self.assertEqual(headers["X-Request-ID"], entry["correlation_id"])
+
+
+if __name__ == "__main__":
+    unittest.main()
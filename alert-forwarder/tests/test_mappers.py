@@ -0,0 +1,80 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from forwarder import mappers
+
+
+class MapEventTest(unittest.TestCase):
+    def test_maps_file_accesses_of_kprobes(self):
+        event = {
+            "process_kprobe": {
+                "function_name": "security_file_permission",
+                "args": [{"file_arg": {"path": "/run/secrets/koney/token"}}],
+            }
+        }
+        self.assertEqual(
+            mappers.map_event(event),
+            ("filesystem_honeytoken", {"file_path": "/run/secrets/koney/token"}),
+        )
+
+    def test_maps_file_accesses_of_lsm_hooks(self):
+        event = {
+            "process_lsm": {
+                "function_name": "file_open",
+                "args": [{"file_arg": {"path": "/run/secrets/koney/token"}}],
+            }
+        }
+        self.assertEqual(
+            mappers.map_event(event, "filesystem_honeytoken"),
+            ("filesystem_honeytoken", {"file_path": "/run/secrets/koney/token"}),
+        )
+
+    def test_maps_file_opens_of_tracepoints(self):
+        event = {
+            "process_tracepoint": {
+                "subsys": "syscalls",
+                "event": "sys_enter_openat",
+                "args": [{"int_arg": -100}, {"string_arg": "/run/secrets/koney/token"}],
+            }
+        }
+        self.assertEqual(
+            mappers.map_event(event),
+            ("filesystem_honeytoken", {"file_path": "/run/secrets/koney/token"}),
+        )
+
+    def test_describes_the_hook_of_events_that_no_mapper_understands(self):
+        event = {"process_uprobe": {"path": "/usr/bin/bash", "symbol": "readline"}}
+        self.assertEqual(
+            mappers.map_event(event),
+            ("unknown", {"hook": "uprobe:/usr/bin/bash:readline"}),
+        )
+
+    def test_only_tries_the_mappers_of_the_declared_trap_type(self):
+        event = {"process_kprobe": {"function_name": "security_file_permission"}}
+        self.assertEqual(mappers.map_event(event, "http_endpoint").trap_type, "unknown")
+
+    def test_uses_registered_mappers(self):
+        event = {"process_uprobe": {"path": "/usr/bin/bash", "symbol": "readline"}}
+        with mock.patch.dict(mappers.EVENT_MAPPERS, clear=False):
+            mappers.register_event_mapper(
+                "http_payload", "process_uprobe", lambda uprobe: dict(uprobe)
+            )
+            self.assertEqual(
+                mappers.map_event(event, "http_payload"),
+                ("http_payload", {"path": "/usr/bin/bash", "symbol": "readline"}),
+            )
@@ -0,0 +1,158 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import os
+import unittest
+from unittest import mock
+
+from forwarder import otel
+
+ALERT = {
+    "timestamp": "2025-01-03T18:47:56.123456789Z",
+    "deception_policy_name": "deceptionpolicy-servicetoken",
+    "trap_type": "filesystem_honeytoken",
+    "severity": "HIGH",
+    "confidence": None,
+    "tags": {"team": "blue"},
+    "trap": {"hash": "0b4a1bd5", "attck_techniques": ["T1552.001"]},
+    "metadata": {"file_path": "/run/secrets/koney/service_token"},
+    "pod": {
+        "name": "nginx-1",
+        "namespace": "koney-demo",
+        "container": {"id": "e19c", "name": "nginx"},
+    },
+    "node": {"name": "minikube"},
+    "process": {"uid": 0, "pid": 148373, "cwd": "/", "binary": "/usr/bin/cat"},
+    "response": None,
+}
+
+
+def attributes(otel_attributes: list[dict]) -> dict:
+    return {attribute["key"]: attribute["value"] for attribute in otel_attributes}
+
+
+@mock.patch.object(otel, "_get_cluster_uid", return_value="c1")
+class ExportAlertTest(unittest.TestCase):
+    def export(self, **env) -> list:
+        with (
+            mock.patch.dict(os.environ, env, clear=True),
+            mock.patch.object(
+                otel.requests, "post", return_value=mock.Mock(status_code=200)
+            ) as post,
+        ):
+            otel.export_alert(ALERT)
+        return post.call_args_list
+
+    def test_does_not_export_by_default(self, _):
+        self.assertEqual(self.export(), [])
+
+    def test_exports_a_log_record(self, _):
+        calls = self.export(
+            OTEL_LOGS_EXPORTER="otlp",
+            OTEL_EXPORTER_OTLP_ENDPOINT="http://collector:4318/",
+            OTEL_EXPORTER_OTLP_HEADERS="Authorization=Api-Token%20abc",
+            OTEL_RESOURCE_ATTRIBUTES="deployment.environment=prod",
+        )
+
+        self.assertEqual(len(calls), 1)
+        self.assertEqual(calls[0].args[0], "http://collector:4318/v1/logs")
+        self.assertEqual(calls[0].kwargs["headers"]["Authorization"], "Api-Token abc")
+
+        resource_logs = calls[0].kwargs["json"]["resourceLogs"][0]
+        resource = attributes(resource_logs["resource"]["attributes"])
+        self.assertEqual(resource["k8s.pod.name"], {"stringValue": "nginx-1"})
+        self.assertEqual(resource["k8s.cluster.uid"], {"stringValue": "c1"})
+        self.assertEqual(
+            resource["koney.deception_policy.name"],
+            {"stringValue": "deceptionpolicy-servicetoken"},
+        )
+        self.assertEqual(resource["deployment.environment"], {"stringValue": "prod"})
+
+        log_record = resource_logs["scopeLogs"][0]["logRecords"][0]
+        self.assertEqual(log_record["timeUnixNano"], "1735930076123456789")
+        self.assertEqual(log_record["severityNumber"], 17)
+        self.assertNotIn("traceId", log_record)
+        record = attributes(log_record["attributes"])
+        self.assertEqual(record["process.pid"], {"intValue": "148373"})
+        self.assertEqual(record["koney.tags.team"], {"stringValue": "blue"})
+        self.assertNotIn("koney.confidence", record)
+
+    def test_exports_the_metadata_of_enriched_alerts(self, _):
+        enriched_alert = {
+            **ALERT,
+            "pod": {**ALERT["pod"], "owner": {"kind": "Deployment", "name": "nginx"}},
+            "node": {"name": "minikube", "cloud": {"provider": "aws", "zone": None}},
+        }
+        with (
+            mock.patch.dict(os.environ, {"OTEL_LOGS_EXPORTER": "otlp"}, clear=True),
+            mock.patch.object(
+                otel.requests, "post", return_value=mock.Mock(status_code=200)
+            ) as post,
+        ):
+            otel.export_alert(enriched_alert)
+
+        resource_logs = post.call_args.kwargs["json"]["resourceLogs"][0]
+        resource = attributes(resource_logs["resource"]["attributes"])
+        self.assertEqual(resource["k8s.deployment.name"], {"stringValue": "nginx"})
+        self.assertEqual(resource["cloud.provider"], {"stringValue": "aws"})
+        self.assertNotIn("cloud.availability_zone", resource)
+
+    def test_attributes_alerts_of_other_clusters(self, _):
+        cluster_alert = {**ALERT, "cluster": {"name": "prod-eu"}}
+        with (
+            mock.patch.dict(os.environ, {"OTEL_LOGS_EXPORTER": "otlp"}, clear=True),
+            mock.patch.object(
+                otel.requests, "post", return_value=mock.Mock(status_code=200)
+            ) as post,
+        ):
+            otel.export_alert(cluster_alert)
+
+        resource_logs = post.call_args.kwargs["json"]["resourceLogs"][0]
+        resource = attributes(resource_logs["resource"]["attributes"])
+        self.assertEqual(resource["k8s.cluster.name"], {"stringValue": "prod-eu"})
+        self.assertNotIn("k8s.cluster.uid", resource)
+
+    def test_links_the_log_record_to_the_span(self, _):
+        calls = self.export(
+            OTEL_LOGS_EXPORTER="otlp",
+            OTEL_TRACES_EXPORTER="otlp",
+            OTEL_EXPORTER_OTLP_TRACES_ENDPOINT="http://tempo:4318/v1/traces",
+        )
+
+        self.assertEqual(
+            [call.args[0] for call in calls],
+            ["http://tempo:4318/v1/traces", "http://localhost:4318/v1/logs"],
+        )
+        span = calls[0].kwargs["json"]["resourceSpans"][0]["scopeSpans"][0]["spans"][0]
+        log_record = calls[1].kwargs["json"]["resourceLogs"][0]["scopeLogs"][0][
+            "logRecords"
+        ][0]
+        self.assertEqual(span["name"], "koney.alert filesystem_honeytoken")
+        self.assertEqual(log_record["traceId"], span["traceId"])
+        self.assertEqual(log_record["spanId"], span["spanId"])
+
+    def test_fails_on_errors(self, _):
+        with (
+            mock.patch.dict(os.environ, {"OTEL_LOGS_EXPORTER": "otlp"}, clear=True),
+            mock.patch.object(
+                otel.requests, "post", return_value=mock.Mock(status_code=503, text="")
+            ),
+        ):
+            with self.assertRaises(RuntimeError):
+                otel.export_alert(ALERT)
+
+
+if __name__ == "__main__":
+    unittest.main()
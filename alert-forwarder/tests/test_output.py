@@ -0,0 +1,181 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import io
+import json
+import os
+import tempfile
+import threading
+import unittest
+from unittest import mock
+
+from forwarder import output
+from forwarder.output import (
+    AlertWriter,
+    encode_varint,
+    format_alert_logfmt,
+    format_alert_ndjson,
+    format_alert_proto,
+)
+
+try:
+    from google.protobuf import json_format, struct_pb2
+except ImportError:  # protobuf is only needed for the proto output format
+    struct_pb2 = None
+
+ALERT = {
+    "schema_version": "1.0",
+    "timestamp": "2025-01-03T18:47:56Z",
+    "deception_policy_name": "dp",
+    "trap_type": "filesystem_honeytoken",
+    "severity": None,
+    "tags": {},
+    "metadata": {"file_path": "/run/secrets/koney/service token"},
+    "pod": {"name": "nginx-1", "container": {"name": "nginx"}},
+    "process": {"pid": 42, "capabilities": ["CAP_SYS_ADMIN"], "is_host": True},
+}
+
+
+class FormatTest(unittest.TestCase):
+    def test_formats_ndjson_as_one_line(self):
+        line = format_alert_ndjson(ALERT)
+
+        self.assertTrue(line.endswith(b"\n"))
+        self.assertEqual(line.count(b"\n"), 1)
+        self.assertEqual(json.loads(line), ALERT)
+
+    def test_formats_logfmt_with_dotted_keys(self):
+        line = format_alert_logfmt(ALERT).decode("utf-8")
+
+        self.assertTrue(line.endswith("\n"))
+        self.assertIn(" trap_type=filesystem_honeytoken ", line)
+        self.assertIn(" pod.container.name=nginx ", line)
+        self.assertIn(" process.pid=42 ", line)
+        self.assertIn(" process.is_host=true", line)
+        self.assertIn(' process.capabilities="[\\"CAP_SYS_ADMIN\\"]" ', line)
+
+    def test_quotes_logfmt_values_with_spaces(self):
+        line = format_alert_logfmt(ALERT).decode("utf-8")
+
+        self.assertIn(' metadata.file_path="/run/secrets/koney/service token" ', line)
+
+    def test_leaves_out_null_and_empty_fields_in_logfmt(self):
+        line = format_alert_logfmt(ALERT).decode("utf-8")
+
+        self.assertNotIn("severity", line)
+        self.assertNotIn("tags", line)
+
+    def test_encodes_varints(self):
+        self.assertEqual(encode_varint(0), b"\x00")
+        self.assertEqual(encode_varint(127), b"\x7f")
+        self.assertEqual(encode_varint(300), b"\xac\x02")
+        self.assertEqual(decode_varint(encode_varint(123456789)), (123456789, 4))
+
+    @unittest.skipIf(struct_pb2 is None, "protobuf is not installed")
+    def test_formats_proto_with_a_length_prefix(self):
+        data = format_alert_proto(ALERT)
+
+        length, offset = decode_varint(data)
+        self.assertEqual(len(data), offset + length)
+        message = struct_pb2.Struct.FromString(data[offset:])
+        decoded = json_format.MessageToDict(message)
+        self.assertEqual(decoded["deception_policy_name"], "dp")
+        self.assertIsNone(decoded["severity"])
+
+
+def decode_varint(data: bytes) -> tuple[int, int]:
+    value, shift, offset = 0, 0, 0
+    while True:
+        byte = data[offset]
+        value |= (byte & 0x7F) << shift
+        shift, offset = shift + 7, offset + 1
+        if byte < 0x80:
+            return value, offset
+
+
+class AlertWriterTest(unittest.TestCase):
+    def setUp(self):
+        self.tmpdir = tempfile.TemporaryDirectory()
+        self.path = os.path.join(self.tmpdir.name, "alerts.log")
+
+    def tearDown(self):
+        self.tmpdir.cleanup()
+
+    def test_writes_to_stdout_by_default(self):
+        stdout = mock.Mock(buffer=io.BytesIO())
+        writer = AlertWriter("ndjson", "")
+
+        with mock.patch.object(output.sys, "stdout", stdout):
+            writer.write(ALERT)
+
+        self.assertEqual(json.loads(stdout.buffer.getvalue()), ALERT)
+
+    def test_appends_to_a_file(self):
+        with open(self.path, "wb") as f:
+            f.write(b"existing\n")
+
+        writer = AlertWriter("logfmt", self.path)
+        writer.write(ALERT)
+        writer.write(ALERT)
+        writer.close()
+
+        with open(self.path, "rb") as f:
+            lines = f.read().splitlines()
+        self.assertEqual(len(lines), 3)
+        self.assertEqual(lines[0], b"existing")
+        self.assertIn(b"deception_policy_name=dp", lines[1])
+
+    def test_falls_back_to_ndjson_for_unknown_formats(self):
+        writer = AlertWriter("yaml", self.path)
+        writer.write(ALERT)
+        writer.close()
+
+        with open(self.path, "rb") as f:
+            self.assertEqual(json.loads(f.read()), ALERT)
+
+    @unittest.skipUnless(hasattr(os, "mkfifo"), "named pipes are not supported")
+    def test_writes_to_a_named_pipe(self):
+        os.mkfifo(self.path)
+        received = []
+
+        def read_pipe():
+            with open(self.path, "rb") as f:
+                received.append(f.read())
+
+        reader = threading.Thread(target=read_pipe)
+        reader.start()
+        writer = AlertWriter("ndjson", self.path)
+        writer.write(ALERT)
+        writer.close()
+        reader.join(timeout=5)
+
+        self.assertEqual(json.loads(received[0]), ALERT)
+
+    def test_reopens_the_output_if_the_reader_went_away(self):
+        broken = mock.Mock()
+        broken.write.side_effect = BrokenPipeError()
+        writer = AlertWriter("ndjson", self.path)
+        writer._file = broken
+
+        writer.write(ALERT)
+        writer.close()
+
+        broken.close.assert_called_once()
+        with open(self.path, "rb") as f:
+            self.assertEqual(json.loads(f.read()), ALERT)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,956 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import json
+import unittest
+from datetime import datetime, timedelta, timezone
+from unittest import mock
+
+from forwarder import main, response, tetragon
+from forwarder.sources import InMemoryEventSource
+from forwarder.tetragon import LogWindow, TracingPolicyMatcher, read_tetragon_events
+from forwarder.types import SCHEMA_VERSION, AlertingMetadata
+from forwarder.utils import (
+    build_response_metadata,
+    build_trap_metadata,
+    resolve_confidence,
+    resolve_severity,
+)
+
+POLICY_NAME = "koney-tracing-policy-0b4a1bd5ebfa3b1d1b4c2da4c8ba4ea4"
+FINGERPRINT = 424242
+
+
+def tetragon_event(time: str, arguments: str, policy_name: str = POLICY_NAME) -> str:
+    return json.dumps(
+        {
+            "process_kprobe": {
+                "process": {
+                    "uid": 0,
+                    "pid": 148373,
+                    "cwd": "/",
+                    "binary": "/usr/bin/cat",
+                    "arguments": arguments,
+                    "pod": {
+                        "name": "koney-demo-deployment-5bcbd78875-45qpn",
+                        "namespace": "koney-demo",
+                        "container": {"id": "containerd://e19c1827", "name": "nginx"},
+                    },
+                },
+                "function_name": "security_file_permission",
+                "args": [{"file_arg": {"path": "/run/secrets/koney/service_token"}}],
+                "policy_name": policy_name,
+            },
+            "node_name": "minikube",
+            "time": time,
+        },
+        separators=(",", ":"),  # like Tetragon, which writes compact JSON lines
+    )
+
+
+def alerting_metadata(**fields) -> AlertingMetadata:
+    alerting = AlertingMetadata(
+        deception_policy_name="dp",
+        trap_type=None,
+        file_path=None,
+        severity=None,
+        confidence=None,
+        tags={},
+        trap=None,
+        response=None,
+    )
+    alerting.update(fields)  # type: ignore
+    return alerting
+
+
+class ReadTetragonEventsTest(unittest.TestCase):
+    def setUp(self):
+        tetragon.event_cache.clear()
+        self.matcher = TracingPolicyMatcher(set(), [tetragon.TETRAGON_POLICY_PREFIX])
+
+    def test_groups_events_by_policy(self):
+        source = InMemoryEventSource(
+            {
+                "tetragon-a": [
+                    tetragon_event("2025-01-03T18:47:56.000000001Z", "token"),
+                    "not a json line koney-tracing-policy-",
+                    tetragon_event("2025-01-03T18:47:57.000000001Z", "x", "other"),
+                ],
+            }
+        )
+
+        events = read_tetragon_events(source=source, matcher=self.matcher)
+        self.assertEqual(list(events.events_per_policy), [POLICY_NAME])
+        self.assertEqual(len(events.events_per_policy[POLICY_NAME]), 1)
+        self.assertEqual(
+            events.watermarks["tetragon-a"]["time"], "2025-01-03T18:47:56Z"
+        )
+
+    def test_deduplicates_events_within_the_same_second(self):
+        source = InMemoryEventSource(
+            {
+                "tetragon-a": [
+                    tetragon_event("2025-01-03T18:47:56.000000001Z", "token"),
+                    tetragon_event("2025-01-03T18:47:56.000000002Z", "token"),
+                ],
+            }
+        )
+
+        events = read_tetragon_events(source=source, matcher=self.matcher)
+        self.assertEqual(len(events.events_per_policy[POLICY_NAME]), 1)
+
+        # the same events are not returned twice
+        events = read_tetragon_events(source=source, matcher=self.matcher)
+        self.assertEqual(events.events_per_policy, {})
+
+    def test_hashes_events_regardless_of_their_formatting(self):
+        line = tetragon_event("2025-01-03T18:47:56.000000001Z", "token")
+        # e.g., streamed events have other key orders and fewer fractional digits
+        streamed_line = json.dumps(
+            dict(reversed(json.loads(line).items())), indent=1
+        ).replace("56.000000001Z", "56.000Z")
+
+        _, _, event_hash = tetragon.parse_tetragon_event(line, self.matcher)
+        _, _, streamed_hash = tetragon.parse_tetragon_event(streamed_line, self.matcher)
+        self.assertEqual(event_hash, streamed_hash)
+
+    def test_skips_events_before_the_watermark(self):
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "a")]}
+        )
+        watermarks = read_tetragon_events(source=source, matcher=self.matcher).watermarks
+
+        # after a restart, the cache is empty but the watermark is persisted
+        tetragon.event_cache.clear()
+        source.append(
+            "tetragon-a", tetragon_event("2025-01-03T18:47:58.000000001Z", "b")
+        )
+        events = read_tetragon_events(
+            source=source, matcher=self.matcher, watermarks=watermarks
+        )
+        self.assertEqual(len(events.events_per_policy[POLICY_NAME]), 1)
+        self.assertEqual(
+            events.events_per_policy[POLICY_NAME][0]["process_kprobe"]["process"]["arguments"],
+            "b",
+        )
+
+    def test_drops_watermarks_of_streams_that_no_longer_exist(self):
+        source = InMemoryEventSource({"tetragon-b": []})
+        watermarks = {"tetragon-a": {"time": "2025-01-03T18:47:56Z", "hashes": []}}
+
+        events = read_tetragon_events(
+            source=source, matcher=self.matcher, watermarks=watermarks
+        )
+        self.assertEqual(events.watermarks, {})
+
+    def test_reads_only_the_streams_of_this_replica(self):
+        source = InMemoryEventSource(
+            {
+                "tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "a")],
+                "tetragon-b": [tetragon_event("2025-01-03T18:47:56.000000001Z", "b")],
+            }
+        )
+
+        with mock.patch.object(
+            tetragon, "owns_shard", side_effect=lambda s: s == "tetragon-b"
+        ):
+            events = read_tetragon_events(source=source, matcher=self.matcher)
+
+        self.assertEqual(len(events.events_per_policy[POLICY_NAME]), 1)
+        self.assertEqual(list(events.watermarks), ["tetragon-b"])
+        self.assertEqual(events.streams, ["tetragon-a", "tetragon-b"])
+
+    def test_retries_forgotten_events(self):
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "a")]}
+        )
+        events = read_tetragon_events(source=source, matcher=self.matcher)
+        tetragon.forget_tetragon_events(events.event_hashes)
+
+        events = read_tetragon_events(source=source, matcher=self.matcher)
+        self.assertEqual(len(events.events_per_policy[POLICY_NAME]), 1)
+
+    def test_reports_streams_that_cannot_be_read(self):
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "a")]}
+        )
+        events = read_tetragon_events(source=source, matcher=self.matcher)
+        self.assertTrue(events.complete)
+
+        with mock.patch.object(source, "read_lines", return_value=None):
+            events = read_tetragon_events(source=source, matcher=self.matcher)
+        self.assertFalse(events.complete)
+
+
+class LogWindowTest(unittest.TestCase):
+    def setUp(self):
+        self.now = 1000.0
+        self.window = LogWindow(60, 15, 3600, clock=lambda: self.now)
+
+    def test_reads_the_initial_window_on_the_first_pass(self):
+        self.assertEqual(self.window.start_pass(), (1000.0, 60))
+
+    def test_reads_back_to_the_start_of_the_last_complete_pass(self):
+        started_at, _ = self.window.start_pass()
+        self.window.complete_pass(started_at)
+
+        self.now += 100
+        self.assertEqual(self.window.start_pass(), (1100.0, 115))
+
+    def test_reads_at_least_the_minimum_window(self):
+        started_at, _ = self.window.start_pass()
+        self.window.complete_pass(started_at)
+
+        self.now += 0.5
+        self.assertEqual(self.window.start_pass()[1], 16)
+
+    def test_reads_at_most_the_maximum_window(self):
+        started_at, _ = self.window.start_pass()
+        self.window.complete_pass(started_at)
+
+        self.now += 86400
+        self.assertEqual(self.window.start_pass()[1], 3600)
+
+    def test_keeps_reading_back_to_the_last_complete_pass(self):
+        started_at, _ = self.window.start_pass()
+        self.window.complete_pass(started_at)
+
+        # the second pass is incomplete, e.g., because the logs could not be read
+        self.now += 100
+        self.window.start_pass()
+        self.now += 100
+        self.assertEqual(self.window.start_pass()[1], 215)
+
+        # a pass that completes late does not move the window back
+        later_start, _ = self.window.start_pass()
+        self.window.complete_pass(later_start)
+        self.window.complete_pass(started_at)
+        self.now += 10
+        self.assertEqual(self.window.start_pass()[1], 25)
+
+
+@mock.patch.object(tetragon.client, "V1Pod", create=True)
+@mock.patch.object(tetragon.client, "CoreV1Api", create=True)
+class KubernetesLogEventSourceTest(unittest.TestCase):
+    def pod(self, restart_count: int, started_seconds_ago: int) -> mock.Mock:
+        started_at = datetime.now(timezone.utc) - timedelta(seconds=started_seconds_ago)
+        status = mock.Mock(restart_count=restart_count)
+        status.name = tetragon.TETRAGON_POD_CONTAINER_NAME
+        status.state.running.started_at = started_at
+        return mock.Mock(status=mock.Mock(container_statuses=[status]))
+
+    def test_reads_the_previous_logs_after_a_restart_within_the_window(self, api, _):
+        api.return_value.read_namespaced_pod.return_value = self.pod(1, 30)
+        api.return_value.read_namespaced_pod_log.side_effect = ["old", "new"]
+
+        lines = tetragon.KubernetesLogEventSource().read_lines("tetragon-a", 60)
+
+        self.assertEqual(lines, ["old", "new"])
+        previous = api.return_value.read_namespaced_pod_log.call_args_list[0]
+        self.assertTrue(previous.kwargs["previous"])
+
+    def test_reads_the_current_logs_only_without_a_recent_restart(self, api, _):
+        api.return_value.read_namespaced_pod.return_value = self.pod(1, 120)
+        api.return_value.read_namespaced_pod_log.return_value = "new"
+
+        lines = tetragon.KubernetesLogEventSource().read_lines("tetragon-a", 60)
+
+        self.assertEqual(lines, ["new"])
+        api.return_value.read_namespaced_pod_log.assert_called_once()
+
+
+@mock.patch.object(tetragon, "read_cached_fingerprint", return_value=FINGERPRINT)
+@mock.patch.object(tetragon, "read_cached_feature_flags", return_value=[])
+@mock.patch.object(main, "enrich_alert")
+@mock.patch.object(main, "aggregate_alert", return_value="forward")
+@mock.patch.object(main, "check_quota", return_value="forward")
+@mock.patch.object(main, "try_read_alert_sinks", return_value=[])
+@mock.patch.object(main, "resolve_alerting", return_value=None)
+@mock.patch.object(main, "resolve_container_selectors", return_value=None)
+@mock.patch.object(tetragon, "resolve_alerting", return_value=alerting_metadata())
+@mock.patch.object(tetragon, "build_tracing_policy_matcher")
+@mock.patch.object(main, "save_watermarks")
+@mock.patch.object(main, "load_watermarks", return_value={})
+class ProcessRecentAlertsTest(unittest.TestCase):
+    def setUp(self):
+        tetragon.event_cache.clear()
+
+    def test_forwards_alerts_and_saves_watermarks(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "token")]}
+        )
+
+        with mock.patch.object(main, "print_alert") as print_alert:
+            main.process_recent_alerts(source)
+
+        print_alert.assert_called_once()
+        koney_alert = print_alert.call_args.args[0]
+        self.assertEqual(koney_alert["deception_policy_name"], "dp")
+        self.assertEqual(koney_alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(koney_alert["pod"]["namespace"], "koney-demo")
+        save_watermarks.assert_called_once()
+
+    def test_filters_events_of_koney_itself(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        fingerprint = tetragon.encode_fingerprint_in_cat(FINGERPRINT)
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", fingerprint)]}
+        )
+
+        with mock.patch.object(main, "print_alert") as print_alert:
+            main.process_recent_alerts(source)
+
+        print_alert.assert_not_called()
+
+    def test_does_not_filter_events_with_the_fingerprint_of_another_installation(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        fingerprint = tetragon.encode_fingerprint_in_cat(1337)
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", fingerprint)]}
+        )
+
+        with mock.patch.object(main, "print_alert") as print_alert:
+            main.process_recent_alerts(source)
+
+        print_alert.assert_called_once()
+
+    def test_requests_a_response_for_traps_with_response_actions(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "token")]}
+        )
+
+        alerting = alerting_metadata(
+            response=build_response_metadata('["networkIsolate"]', "1h0m0s"),
+        )
+
+        with (
+            mock.patch.object(main, "print_alert") as print_alert,
+            mock.patch.object(main, "resolve_alerting", return_value=alerting),
+            mock.patch.object(main, "try_request_response") as try_request_response,
+        ):
+            main.process_recent_alerts(source)
+
+        # the requested response is recorded in the alert
+        koney_alert = print_alert.call_args.args[0]
+        self.assertEqual(
+            koney_alert["response"],
+            {"actions": ["networkIsolate"], "quarantine_ttl": "1h0m0s"},
+        )
+        try_request_response.assert_called_once_with(koney_alert)
+
+    def test_does_not_request_a_response_for_traps_without_response_actions(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", "token")]}
+        )
+
+        with (
+            mock.patch.object(main, "print_alert") as print_alert,
+            mock.patch.object(main, "try_request_response") as try_request_response,
+        ):
+            main.process_recent_alerts(source)
+
+        self.assertIsNone(print_alert.call_args.args[0]["response"])
+        try_request_response.assert_not_called()
+
+    def test_marks_alerts_of_self_tests_and_reports_them_as_passed(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        arguments = '-c ": < \\"$0\\" # KONEY_SELFTEST_0123456789abcdef" /token'
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", arguments)]}
+        )
+
+        alerting = alerting_metadata(
+            response=build_response_metadata('["networkIsolate"]', "1h0m0s"),
+        )
+
+        with (
+            mock.patch.object(main, "print_alert") as print_alert,
+            mock.patch.object(main, "resolve_alerting", return_value=alerting),
+            mock.patch.object(main, "is_pending_self_test", return_value=True),
+            mock.patch.object(main, "try_report_self_test_passed") as report_passed,
+            mock.patch.object(main, "try_request_response") as try_request_response,
+        ):
+            main.process_recent_alerts(source)
+
+        koney_alert = print_alert.call_args.args[0]
+        self.assertEqual(koney_alert["tags"], {"koney.selftest": "true"})
+        report_passed.assert_called_once_with(koney_alert, "0123456789abcdef")
+        # self-tests must not contain the pod that was touched
+        try_request_response.assert_not_called()
+
+    def test_does_not_mark_alerts_of_self_tests_that_are_not_pending(
+        self, load_watermarks, save_watermarks, build_matcher, *_
+    ):
+        build_matcher.return_value = TracingPolicyMatcher(
+            set(), [tetragon.TETRAGON_POLICY_PREFIX]
+        )
+        arguments = "/token # KONEY_SELFTEST_0123456789abcdef"
+        source = InMemoryEventSource(
+            {"tetragon-a": [tetragon_event("2025-01-03T18:47:56.000000001Z", arguments)]}
+        )
+
+        with (
+            mock.patch.object(main, "print_alert") as print_alert,
+            mock.patch.object(main, "is_pending_self_test", return_value=False),
+            mock.patch.object(main, "try_report_self_test_passed") as report_passed,
+        ):
+            main.process_recent_alerts(source)
+
+        self.assertEqual(print_alert.call_args.args[0]["tags"], {})
+        report_passed.assert_not_called()
+
+
+@mock.patch.object(tetragon, "read_cached_fingerprint", return_value=FINGERPRINT)
+@mock.patch.object(tetragon, "read_cached_feature_flags", return_value=[])
+@mock.patch.object(main, "enrich_alert")
+@mock.patch.object(main, "aggregate_alert", return_value="forward")
+@mock.patch.object(main, "check_quota", return_value="forward")
+@mock.patch.object(main, "try_read_alert_sinks", return_value=[])
+@mock.patch.object(main, "resolve_alerting", return_value=None)
+@mock.patch.object(main, "resolve_container_selectors", return_value=None)
+@mock.patch.object(tetragon, "resolve_alerting", return_value=alerting_metadata())
+@mock.patch.object(
+    main,
+    "build_tracing_policy_matcher",
+    return_value=TracingPolicyMatcher(set(), [tetragon.TETRAGON_POLICY_PREFIX]),
+)
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+class PostTetragonEventsTest(unittest.TestCase):
+    def setUp(self):
+        tetragon.event_cache.clear()
+
+    def post(self, body: str) -> mock.Mock:
+        request = mock.Mock(body=mock.AsyncMock(return_value=body.encode("utf-8")))
+        background_tasks = mock.Mock()
+        asyncio.run(main.handle_tetragon_events(mock.Mock(), request, background_tasks))
+        return background_tasks
+
+    def test_processes_the_posted_events(self, *_):
+        event = tetragon_event("2025-01-03T18:47:56.000000001Z", "token")
+        background_tasks = self.post(event)
+
+        background_tasks.add_task.assert_called_once()
+        task, lines = background_tasks.add_task.call_args.args
+        self.assertEqual(task, main.process_posted_tetragon_events)
+
+        with mock.patch.object(main, "print_alert") as print_alert:
+            task(lines)
+            # the same event is not processed twice
+            task(lines)
+
+        print_alert.assert_called_once()
+        self.assertEqual(print_alert.call_args.args[0]["deception_policy_name"], "dp")
+
+    def test_falls_back_to_reading_events_without_a_body(self, *_):
+        with mock.patch.object(main, "trigger_tetragon_events") as trigger:
+            self.post("")
+        trigger.assert_called_once()
+
+    def test_processes_all_posted_events_if_they_are_only_posted(self, *_):
+        event = tetragon_event("2025-01-03T18:47:56.000000001Z", "token")
+        with (
+            mock.patch.object(main, "TETRAGON_EVENT_SOURCE", "webhook"),
+            mock.patch.object(main.trigger_limiter, "allow", return_value=False),
+        ):
+            background_tasks = self.post(event)
+
+        task, _ = background_tasks.add_task.call_args.args
+        self.assertEqual(task, main.process_posted_tetragon_events)
+
+    def test_splits_events(self, *_):
+        event = {"time": "2025-01-03T18:47:56Z"}
+        self.assertEqual(
+            main.split_tetragon_events(json.dumps([event, event, "x"])),
+            [json.dumps(event), json.dumps(event)],
+        )
+        self.assertEqual(
+            main.split_tetragon_events('{"a":1}\n\n{"b":2}\n'), ['{"a":1}', '{"b":2}']
+        )
+        self.assertEqual(main.split_tetragon_events(" \n"), [])
+
+
+class TryProcessRecentAlertsTest(unittest.TestCase):
+    def setUp(self):
+        self.window = LogWindow(60, 15, 3600)
+        patcher = mock.patch.object(main, "log_window", self.window)
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+    @mock.patch.object(main, "KubernetesLogEventSource")
+    def test_reads_the_log_window(self, _):
+        with mock.patch.object(
+            main, "process_recent_alerts", return_value=True
+        ) as process:
+            main.try_process_recent_alerts()
+        self.assertEqual(process.call_args.args[1], 60)
+
+        # the next pass reads back to the start of the first one only
+        with mock.patch.object(
+            main, "process_recent_alerts", return_value=True
+        ) as process:
+            main.try_process_recent_alerts()
+        self.assertLessEqual(process.call_args.args[1], 16)
+
+    @mock.patch.object(main, "KubernetesLogEventSource")
+    def test_keeps_the_log_window_after_incomplete_passes(self, _):
+        with mock.patch.object(main, "process_recent_alerts", return_value=False):
+            main.try_process_recent_alerts()
+
+        with mock.patch.object(
+            main, "process_recent_alerts", return_value=True
+        ) as process:
+            main.try_process_recent_alerts()
+        self.assertEqual(process.call_args.args[1], 60)
+
+
+class ReadTetragonLogsTest(unittest.TestCase):
+    def setUp(self):
+        patcher = mock.patch.object(main, "READ_TETRAGON_LOGS", False)
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+    def test_does_not_read_logs_on_triggers(self):
+        background_tasks = mock.Mock()
+        with mock.patch.object(main, "TETRAGON_EVENT_SOURCE", "webhook"):
+            main.trigger_tetragon_events(background_tasks)
+        background_tasks.add_task.assert_not_called()
+
+    def test_does_not_catch_up_on_events_from_the_logs(self):
+        with mock.patch.object(main, "process_recent_alerts") as process:
+            main.try_process_recent_alerts()
+        process.assert_not_called()
+
+    def test_is_ready_without_checking_tetragon(self):
+        with (
+            mock.patch.object(main, "TETRAGON_EVENT_SOURCE", "webhook"),
+            mock.patch.object(main, "list_tetragon_pod_addresses") as list_pods,
+        ):
+            self.assertIsNone(main.check_tetragon())
+        list_pods.assert_not_called()
+
+
+class ShutdownTest(unittest.TestCase):
+    def tearDown(self):
+        main.shutdown_requested.clear()
+
+    def test_processes_debounced_triggers_right_away(self):
+        main.shutdown_requested.set()
+        with mock.patch.object(main, "try_process_recent_alerts") as process:
+            main.load_new_alerts()
+        process.assert_called_once()
+
+    @mock.patch.object(main, "save_event_cache")
+    @mock.patch.object(main, "try_redeliver_alerts")
+    @mock.patch.object(main, "try_forward_aggregated_alerts")
+    @mock.patch.object(main, "tetragon_streams")
+    def test_stops_and_flushes_background_work(
+        self,
+        tetragon_streams,
+        try_forward_aggregated_alerts,
+        try_redeliver_alerts,
+        save_event_cache,
+    ):
+        thread = mock.Mock()
+        main.shutdown([thread])
+
+        self.assertTrue(main.shutdown_requested.is_set())
+        tetragon_streams.stop.assert_called_once()
+        thread.join.assert_called_once()
+        try_forward_aggregated_alerts.assert_called_once_with(force=True)
+        try_redeliver_alerts.assert_called_once()
+        save_event_cache.assert_called_once_with(main.event_cache)
+
+
+class ForwardSelfTestFailuresTest(unittest.TestCase):
+    @mock.patch.object(main, "try_read_alert_sinks", return_value=[])
+    @mock.patch.object(main, "forward_alert", return_value=True)
+    def test_alerts_the_sinks_without_aggregating(self, forward_alert, _):
+        main.forward_self_test_failures(
+            [{"deceptionPolicyName": "dp", "passed": False, "message": "timed out"}]
+        )
+
+        koney_alert = forward_alert.call_args.args[0]
+        self.assertEqual(koney_alert["deception_policy_name"], "dp")
+        self.assertEqual(koney_alert["tags"], {"koney.selftest": "true"})
+        self.assertEqual(forward_alert.call_args.kwargs, {"aggregate": False})
+
+
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+@mock.patch.object(main, "is_authorized_request", return_value=True)
+class HandleSelfTestTest(unittest.TestCase):
+    def handle(self, body: bytes, job_name: str | None = "koney-self-test-manual-1"):
+        request = mock.Mock()
+        request.body = mock.AsyncMock(return_value=body)
+        response = mock.Mock()
+        with mock.patch.object(
+            main, "start_self_test_job", return_value=job_name
+        ) as start_self_test_job:
+            result = asyncio.run(main.handle_self_test(response, request))
+        return result, response, start_self_test_job
+
+    def test_starts_a_self_test_job(self, *_):
+        result, _, start_self_test_job = self.handle(b"")
+
+        start_self_test_job.assert_called_once_with(None)
+        self.assertEqual(result, {"jobName": "koney-self-test-manual-1"})
+
+    def test_starts_a_self_test_job_of_a_single_deception_policy(self, *_):
+        _, _, start_self_test_job = self.handle(b'{"deceptionPolicyName": "dp"}')
+
+        start_self_test_job.assert_called_once_with("dp")
+
+    def test_rejects_invalid_requests(self, *_):
+        for body in [b"not json", b"[]", b'{"deceptionPolicyName": 1}']:
+            with self.subTest(body=body):
+                _, response, start_self_test_job = self.handle(body)
+
+                start_self_test_job.assert_not_called()
+                self.assertEqual(response.status_code, 400)
+
+    def test_responds_not_found_without_a_cron_job(self, *_):
+        _, response, _ = self.handle(b"", job_name=None)
+
+        self.assertEqual(response.status_code, 404)
+
+
+class ParseSelfTestFailuresTest(unittest.TestCase):
+    def test_parses_the_failed_self_tests(self):
+        body = (
+            b'{"results": [{"deceptionPolicyName": "dp", '
+            b'"passed": true, "message": "timed out"}]}'
+        )
+        self.assertEqual(
+            main.parse_self_test_failures(body),
+            [{"deceptionPolicyName": "dp", "passed": False, "message": "timed out"}],
+        )
+
+    def test_rejects_invalid_bodies(self):
+        for body in [
+            b"not json",
+            b"[]",
+            b'{"results": {}}',
+            b'{"results": [{"deceptionPolicyName": 1, "message": ""}]}',
+            b'{"results": [{"deceptionPolicyName": "dp"}]}',
+        ]:
+            with self.subTest(body=body):
+                self.assertIsNone(main.parse_self_test_failures(body))
+
+
+class BuildResponseRequestTest(unittest.TestCase):
+    def alert(self, **overrides) -> dict:
+        koney_alert = {
+            "deception_policy_name": "dp",
+            "trap": {"hash": "0b4a1bd5", "deception_policy_generation": 1},
+            "pod": {
+                "name": "nginx-1",
+                "namespace": "koney-demo",
+                "container": {"id": "e19c", "name": "nginx"},
+            },
+            "process": {"binary": "/usr/bin/cat"},
+        }
+        koney_alert.update(overrides)
+        return koney_alert
+
+    def test_identifies_the_access(self):
+        self.assertEqual(
+            response.build_response_request(self.alert()),
+            {
+                "deceptionPolicyName": "dp",
+                "trapHash": "0b4a1bd5",
+                "containerName": "nginx",
+                "binary": "/usr/bin/cat",
+            },
+        )
+
+    def test_requires_the_trap_and_the_pod(self):
+        self.assertIsNone(response.build_response_request(self.alert(trap=None)))
+        self.assertIsNone(response.build_response_request(self.alert(pod=None)))
+        self.assertIsNone(
+            response.build_response_request(self.alert(deception_policy_name=None))
+        )
+
+    def test_requests_a_response_only_once(self):
+        response._requested_alerts.clear()
+        with mock.patch.object(response, "client") as k8s_client:
+            response.request_response_once(self.alert())
+            response.request_response_once(self.alert())
+
+        patch = k8s_client.CoreV1Api.return_value.patch_namespaced_pod
+        patch.assert_called_once()
+        name, namespace, body = patch.call_args.args
+        self.assertEqual((name, namespace), ("nginx-1", "koney-demo"))
+        request = body["metadata"]["annotations"][response.RESPONSE_REQUEST_ANNOTATION]
+        self.assertEqual(json.loads(request)["trapHash"], "0b4a1bd5")
+
+
+if __name__ == "__main__":
+    unittest.main()
+
+
+class ContainerMatchesSelectorsTest(unittest.TestCase):
+    def test_matches_plain_patterns(self):
+        self.assertTrue(tetragon.container_matches_selectors("nginx", ["glob:ng*"]))
+        self.assertTrue(tetragon.container_matches_selectors("nginx", ["regex:x$"]))
+        self.assertFalse(tetragon.container_matches_selectors("nginx", ["redis"]))
+
+    def test_excluded_patterns_take_precedence(self):
+        selectors = [{"include": ["regex:.*"], "exclude": ["istio-proxy"]}]
+        self.assertTrue(tetragon.container_matches_selectors("nginx", selectors))
+        self.assertFalse(
+            tetragon.container_matches_selectors("istio-proxy", selectors)
+        )
+
+    def test_matches_any_selector(self):
+        selectors = [
+            {"exclude": ["glob:*-proxy"]},
+            {"include": ["istio-proxy"]},
+        ]
+        self.assertTrue(tetragon.container_matches_selectors("istio-proxy", selectors))
+        self.assertFalse(
+            tetragon.container_matches_selectors("linkerd-proxy", selectors)
+        )
+
+    def test_matches_the_image_of_the_container(self):
+        selectors = [{"image": "postgres"}]
+        self.assertFalse(
+            tetragon.container_matches_selectors(
+                "db", selectors, "docker.io/library/postgres:16"
+            )
+        )
+        self.assertTrue(
+            tetragon.container_matches_selectors("db", selectors, "postgres:16")
+        )
+        self.assertTrue(
+            tetragon.container_matches_selectors(
+                "db", [{"image": "glob:*/*/postgres:*"}], "docker.io/library/postgres:16"
+            )
+        )
+        self.assertFalse(
+            tetragon.container_matches_selectors(
+                "db", [{"image": "glob:*/postgres:*"}], "docker.io/library/postgres:16"
+            )
+        )
+        self.assertFalse(
+            tetragon.container_matches_selectors("db", selectors, "redis:7")
+        )
+        self.assertFalse(tetragon.container_matches_selectors("db", selectors))
+
+    def test_extracts_the_image_of_the_container(self):
+        event = json.loads(tetragon_event("2025-01-01T00:00:00Z", ""))
+        event["process_kprobe"]["process"]["pod"]["container"]["image"] = {
+            "name": "docker.io/library/nginx:latest"
+        }
+        self.assertEqual(
+            tetragon.extract_container_image(event), "docker.io/library/nginx:latest"
+        )
+
+
+@mock.patch.object(tetragon, "resolve_alerting", return_value=alerting_metadata())
+class MapTetragonEventTest(unittest.TestCase):
+    def event(self) -> dict:
+        event = json.loads(tetragon_event("2025-01-01T00:00:00Z", ""))
+        kprobe = event["process_kprobe"]
+        kprobe["process"]["exec_id"] = "bWluaWt1YmU6MTQ4Mzcz"
+        kprobe["parent"] = {
+            "pid": 148370,
+            "binary": "/bin/sh",
+            "arguments": "-c 'cat /run/secrets/koney/service_token'",
+            "exec_id": "bWluaWt1YmU6MTQ4Mzcw",
+        }
+        kprobe["ancestors"] = [{"pid": 1, "binary": "/usr/sbin/sshd", "arguments": ""}]
+        return event
+
+    def feature_flags(self, enabled: bool):
+        flags = [{"name": "ProcessAncestryEnrichment", "enabled": enabled}]
+        return mock.patch.object(
+            tetragon, "read_cached_feature_flags", return_value=flags
+        )
+
+    def test_adds_the_parent_process_chain_if_enabled(self, _):
+        with self.feature_flags(enabled=True):
+            process = tetragon.map_tetragon_event(self.event())["process"]
+
+        self.assertEqual(process["exec_id"], "bWluaWt1YmU6MTQ4Mzcz")
+        self.assertEqual(
+            process["ancestors"],
+            [
+                {
+                    "pid": 148370,
+                    "binary": "/bin/sh",
+                    "arguments": "-c 'cat /run/secrets/koney/service_token'",
+                    "exec_id": "bWluaWt1YmU6MTQ4Mzcw",
+                },
+                dict(pid=1, binary="/usr/sbin/sshd", arguments="", exec_id=None),
+            ],
+        )
+
+    def test_omits_the_parent_process_chain_if_disabled(self, _):
+        with self.feature_flags(enabled=False):
+            process = tetragon.map_tetragon_event(self.event())["process"]
+
+        self.assertEqual(process["exec_id"], "bWluaWt1YmU6MTQ4Mzcz")
+        self.assertNotIn("ancestors", process)
+
+    def test_adds_credentials_capabilities_and_namespaces(self, _):
+        event = self.event()
+        event["process_kprobe"]["process"].update(
+            process_credentials={
+                "uid": 1000,
+                "gid": 1000,
+                "euid": 0,
+                "egid": 0,
+                "caps": {"effective": ["CAP_SYS_ADMIN"], "permitted": []},
+            },
+            ns={
+                "pid": {"inum": 4026531836, "is_host": True},
+                "mnt": {"inum": 4026532210},
+            },
+        )
+        with self.feature_flags(enabled=False):
+            process = tetragon.map_tetragon_event(event)["process"]
+
+        self.assertEqual(
+            process["credentials"], dict(uid=1000, gid=1000, euid=0, egid=0)
+        )
+        self.assertEqual(process["capabilities"], ["CAP_SYS_ADMIN"])
+        self.assertEqual(
+            process["namespaces"],
+            {
+                "pid": {"inum": 4026531836, "is_host": True},
+                "mnt": {"inum": 4026532210, "is_host": False},
+            },
+        )
+
+    def test_falls_back_to_the_capabilities_of_older_tetragon_versions(self, _):
+        event = self.event()
+        event["process_kprobe"]["process"]["cap"] = {"effective": ["CAP_CHOWN"]}
+        with self.feature_flags(enabled=False):
+            process = tetragon.map_tetragon_event(event)["process"]
+
+        self.assertIsNone(process["credentials"])
+        self.assertEqual(process["capabilities"], ["CAP_CHOWN"])
+        self.assertIsNone(process["namespaces"])
+
+    def test_attributes_the_alert_to_the_trap_of_the_tracing_policy(self, _):
+        event = self.event()
+        event["process_kprobe"]["args"] = []
+        alerting = alerting_metadata(
+            deception_policy_name="other-dp",
+            trap_type="filesystem_honeytoken",
+            file_path="/run/secrets/koney/service_token",
+        )
+        with self.feature_flags(enabled=False):
+            koney_alert = tetragon.map_tetragon_event(event, alerting)
+
+        self.assertEqual(koney_alert["schema_version"], SCHEMA_VERSION)
+        self.assertEqual(koney_alert["deception_policy_name"], "other-dp")
+        self.assertEqual(koney_alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(
+            koney_alert["metadata"], {"file_path": "/run/secrets/koney/service_token"}
+        )
+
+
+class ResolveAlertingTest(unittest.TestCase):
+    def test_resolves_the_trap_from_the_tracing_policy(self):
+        tracing_policy = {
+            "metadata": {
+                "labels": {"koney/deception-policy": "dp", "koney/severity": "high"},
+                "annotations": {
+                    "koney/trap-type": "filesystem_honeytoken",
+                    "koney/file-path": "/run/secrets/koney/service_token",
+                    "koney/trap-hash": "0b4a1bd5",
+                },
+            }
+        }
+        api = mock.Mock()
+        api.get_cluster_custom_object.return_value = tracing_policy
+        with mock.patch.object(
+            tetragon.client, "CustomObjectsApi", return_value=api, create=True
+        ):
+            alerting = tetragon.resolve_alerting(POLICY_NAME)
+
+        self.assertEqual(alerting["deception_policy_name"], "dp")
+        self.assertEqual(alerting["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(alerting["file_path"], "/run/secrets/koney/service_token")
+        self.assertEqual(alerting["severity"], resolve_severity(None, "high"))
+        self.assertEqual(alerting["trap"]["hash"], "0b4a1bd5")  # type: ignore
+
+    def test_returns_empty_metadata_for_deleted_tracing_policies(self):
+        not_found = tetragon.ApiException()
+        not_found.status = 404
+        api = mock.Mock()
+        api.get_cluster_custom_object.side_effect = not_found
+        with mock.patch.object(
+            tetragon.client, "CustomObjectsApi", return_value=api, create=True
+        ):
+            alerting = tetragon.resolve_alerting(POLICY_NAME)
+
+        self.assertIsNone(alerting["deception_policy_name"])
+        self.assertIsNone(alerting["file_path"])
+
+
+class BuildTrapMetadataTest(unittest.TestCase):
+    def test_decodes_techniques_and_activities(self):
+        trap = build_trap_metadata(
+            "0b4a1bd5", "2", None, '["T1552.001","T1083"]', '["EAC0005"]'
+        )
+        self.assertEqual(trap["attck_techniques"], ["T1552.001", "T1083"])
+        self.assertEqual(trap["engage_activities"], ["EAC0005"])
+
+    def test_defaults_to_empty_lists(self):
+        trap = build_trap_metadata("0b4a1bd5", "2", None)
+        self.assertEqual(trap["attck_techniques"], [])
+        self.assertEqual(trap["engage_activities"], [])
+
+
+class ResolveSeverityTest(unittest.TestCase):
+    def test_prefers_the_severity_override_of_the_alerting_configuration(self):
+        self.assertEqual(resolve_severity("LOW", "critical"), "LOW")
+
+    def test_falls_back_to_the_severity_of_the_trap(self):
+        self.assertEqual(resolve_severity(None, "info"), "INFO")
+        self.assertIsNone(resolve_severity(None, None))
+
+    def test_resolves_the_confidence_of_the_trap(self):
+        self.assertEqual(resolve_confidence("medium"), "medium")
+        self.assertIsNone(resolve_confidence(None))
@@ -0,0 +1,163 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from kubernetes.client.exceptions import ApiException
+
+from forwarder import quota
+from forwarder.types import SCHEMA_VERSION, KoneyAlert
+
+
+class FakeCustomObjectsApi:
+    def __init__(self, quotas: dict[str, int | None]):
+        self.quotas = quotas
+        self.lookups = 0
+
+    def get_cluster_custom_object(self, *args):
+        self.lookups += 1
+        if args[-1] not in self.quotas:
+            not_found = ApiException()
+            not_found.status = 404
+            raise not_found
+        return {
+            "metadata": {"name": args[-1]},
+            "spec": {"maxAlertsPerHour": self.quotas[args[-1]]},
+        }
+
+
+class QuotaTestCase(unittest.TestCase):
+    def setUp(self):
+        quota._forwarded_alerts.clear()
+        quota._suppressed_alerts.clear()
+        quota._quota_cache.clear()
+
+        self.api = FakeCustomObjectsApi({"limited": 2, "other": 1, "unlimited": None})
+        patcher = mock.patch.object(
+            quota.client, "CustomObjectsApi", return_value=self.api, create=True
+        )
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+        self.now = 1000.0
+        time_patcher = mock.patch.object(quota, "time")
+        time_mock = time_patcher.start()
+        time_mock.time.side_effect = lambda: self.now
+        self.addCleanup(time_patcher.stop)
+
+
+class CheckQuotaTest(QuotaTestCase):
+    def test_allows_alerts_that_cannot_be_attributed(self):
+        for _ in range(5):
+            self.assertEqual(quota.check_quota(None), "allow")
+        self.assertEqual(self.api.lookups, 0)
+
+    def test_allows_alerts_of_policies_without_a_quota(self):
+        for _ in range(5):
+            self.assertEqual(quota.check_quota("unlimited"), "allow")
+            self.assertEqual(quota.check_quota("deleted"), "allow")
+
+    def test_suppresses_alerts_over_the_quota(self):
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(quota.check_quota("limited"), "exceeded")
+        self.assertEqual(quota.check_quota("limited"), "suppress")
+        self.assertEqual(quota.check_quota("limited"), "suppress")
+
+    def test_limits_each_policy_separately(self):
+        self.assertEqual(quota.check_quota("other"), "allow")
+        self.assertEqual(quota.check_quota("other"), "exceeded")
+
+        # the quota of one policy does not count alerts of another
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(quota.check_quota("limited"), "exceeded")
+        self.assertEqual(quota.check_quota("other"), "suppress")
+
+    def test_frees_the_quota_when_alerts_leave_the_window(self):
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.now += 1800
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(quota.check_quota("limited"), "exceeded")
+
+        # the first alert leaves the window exactly one window after it was counted
+        self.now = 1000.0 + quota.QUOTA_WINDOW_SECONDS - 1
+        self.assertEqual(quota.check_quota("limited"), "suppress")
+        self.now = 1000.0 + quota.QUOTA_WINDOW_SECONDS
+        self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(quota.check_quota("limited"), "exceeded")
+
+    def test_reports_exceeding_again_after_the_quota_was_available(self):
+        quota.check_quota("other")
+        self.assertEqual(quota.check_quota("other"), "exceeded")
+        self.assertEqual(quota.check_quota("other"), "suppress")
+
+        self.now += quota.QUOTA_WINDOW_SECONDS
+        with self.assertLogs(quota.logger, level="WARNING") as logs:
+            self.assertEqual(quota.check_quota("other"), "allow")
+        self.assertIn("suppressed 2 alerts", logs.output[0])
+        self.assertEqual(quota.check_quota("other"), "exceeded")
+
+    def test_caches_the_quota_of_policies(self):
+        quota.check_quota("limited")
+        quota.check_quota("limited")
+        self.assertEqual(self.api.lookups, 1)
+
+        self.api.quotas["limited"] = 5
+        self.now += quota.QUOTA_CACHE_SECONDS
+        for _ in range(3):
+            self.assertEqual(quota.check_quota("limited"), "allow")
+        self.assertEqual(self.api.lookups, 2)
+
+
+class BuildQuotaExceededAlertTest(QuotaTestCase):
+    def test_derives_the_meta_alert_from_the_suppressed_alert(self):
+        koney_alert = KoneyAlert(
+            schema_version=SCHEMA_VERSION,
+            timestamp="2025-01-01T00:00:00Z",
+            deception_policy_name="limited",
+            trap_type="filesystem_honeytoken",
+            severity=None,
+            confidence=None,
+            tags={},
+            trap=None,
+            metadata={"file_path": "/run/secrets/koney/token"},
+            pod=None,
+            node=None,
+            process=None,
+            response=None,
+        )
+
+        meta_alert = quota.build_quota_exceeded_alert(koney_alert)
+
+        self.assertEqual(meta_alert["deception_policy_name"], "limited")
+        self.assertEqual(meta_alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(
+            meta_alert["metadata"],
+            {
+                "quota_exceeded": True,
+                "max_alerts_per_hour": 2,
+                "message": "quota exceeded, suppressing further alerts",
+            },
+        )
+        # the suppressed alert is left unchanged
+        self.assertEqual(
+            koney_alert["metadata"], {"file_path": "/run/secrets/koney/token"}
+        )
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,116 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import threading
+import unittest
+from unittest import mock
+
+from forwarder import main
+from forwarder.ratelimit import (
+    TriggerCoalescer,
+    TriggerLimiter,
+    format_trigger_metrics,
+)
+
+
+class TriggerLimiterTest(unittest.TestCase):
+    def test_limits_bursts_per_source(self):
+        limiter = TriggerLimiter(rate=1.0, burst=2)
+
+        self.assertTrue(limiter.allow("10.0.0.1", now=0.0))
+        self.assertTrue(limiter.allow("10.0.0.1", now=0.0))
+        self.assertFalse(limiter.allow("10.0.0.1", now=0.0))
+        # other sources have their own limits
+        self.assertTrue(limiter.allow("10.0.0.2", now=0.0))
+        self.assertEqual((limiter.accepted, limiter.rate_limited), (3, 1))
+
+    def test_refills_tokens_over_time(self):
+        limiter = TriggerLimiter(rate=1.0, burst=2)
+        limiter.allow("10.0.0.1", now=0.0)
+        limiter.allow("10.0.0.1", now=0.0)
+
+        self.assertFalse(limiter.allow("10.0.0.1", now=0.5))
+        self.assertTrue(limiter.allow("10.0.0.1", now=1.5))
+
+    def test_forgets_the_least_recently_seen_sources(self):
+        limiter = TriggerLimiter(rate=1.0, burst=1, max_sources=1)
+        limiter.allow("10.0.0.1", now=0.0)
+        limiter.allow("10.0.0.2", now=0.0)
+
+        # the first source starts with a full bucket again
+        self.assertTrue(limiter.allow("10.0.0.1", now=0.0))
+
+
+class TriggerCoalescerTest(unittest.TestCase):
+    def test_coalesces_triggers_until_the_run_starts(self):
+        coalescer = TriggerCoalescer(delay=0)
+        stopped = threading.Event()
+
+        self.assertTrue(coalescer.trigger())
+        self.assertFalse(coalescer.trigger())
+        self.assertFalse(coalescer.trigger())
+
+        coalescer.wait(stopped)
+        # triggers during the run schedule the next run
+        self.assertTrue(coalescer.trigger())
+        self.assertEqual((coalescer.runs, coalescer.coalesced), (1, 2))
+
+    def test_detects_runs_that_did_not_start(self):
+        coalescer = TriggerCoalescer(delay=5)
+        self.assertFalse(coalescer.is_stalled(now=1000.0))
+
+        coalescer.trigger(now=1000.0)
+        self.assertFalse(coalescer.is_stalled(now=1030.0))
+        self.assertTrue(coalescer.is_stalled(now=1100.0))
+
+        coalescer.wait(threading.Event())
+        self.assertFalse(coalescer.is_stalled(now=1100.0))
+
+    def test_formats_metrics(self):
+        limiter, coalescer = TriggerLimiter(), TriggerCoalescer(delay=0)
+        coalescer.trigger()
+        coalescer.trigger()
+
+        metrics = format_trigger_metrics(limiter, [coalescer])
+
+        self.assertIn("# TYPE koney_triggers_coalesced_total counter", metrics)
+        self.assertIn("koney_triggers_coalesced_total 1\n", metrics)
+
+
+@mock.patch.object(main, "TETRAGON_EVENT_SOURCE", "logs")
+@mock.patch.object(main, "log_triggers", new_callable=lambda: TriggerCoalescer(0))
+class TriggerTetragonEventsTest(unittest.TestCase):
+    def test_schedules_one_run_for_many_triggers(self, log_triggers):
+        background_tasks = mock.Mock()
+        for _ in range(10):
+            main.trigger_tetragon_events(background_tasks)
+
+        background_tasks.add_task.assert_called_once_with(main.load_new_alerts)
+        self.assertEqual(log_triggers.coalesced, 9)
+
+    @mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+    @mock.patch.object(main, "trigger_limiter", TriggerLimiter(rate=0.0, burst=0))
+    def test_reads_events_over_the_rate_limit_instead(self, *_):
+        request = mock.Mock(body=mock.AsyncMock(return_value=b'{"a": 1}'))
+        background_tasks = mock.Mock()
+
+        asyncio.run(main.handle_tetragon_events(mock.Mock(), request, background_tasks))
+
+        background_tasks.add_task.assert_called_once_with(main.load_new_alerts)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,208 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from forwarder import main, redelivery
+from forwarder.types import AlertSink, RedeliveryPolicy
+
+ALERT = {
+    "timestamp": "2025-01-03T18:47:56Z",
+    "deception_policy_name": "dp",
+    "trap_type": "filesystem_honeytoken",
+    "severity": "HIGH",
+    "pod": {"name": "nginx-1", "namespace": "koney-demo"},
+}
+
+
+def alert_sink(max_attempts: int = 3) -> AlertSink:
+    return AlertSink(
+        name="webhook",
+        match=None,
+        redelivery=RedeliveryPolicy(
+            max_attempts=max_attempts, initial_backoff=30.0, max_backoff=60.0
+        ),
+        dynatrace_sink=None,
+        webhook_sink=None,
+        chat_sink=None,
+        syslog_sink=None,
+    )
+
+
+@mock.patch.object(redelivery, "save_queue", return_value=True)
+@mock.patch.object(redelivery, "time")
+class RedeliveryTest(unittest.TestCase):
+    def setUp(self):
+        redelivery._queue = {"pending": [], "dead_letters": []}
+        redelivery._stats.clear()
+        redelivery._reported_pending.clear()
+        redelivery._failing_sinks.clear()
+
+    def test_queues_failed_deliveries_once(self, time, save_queue):
+        time.time.return_value = 1000.0
+
+        self.assertTrue(redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503"))
+        self.assertTrue(redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503"))
+
+        pending = redelivery._queue["pending"]
+        self.assertEqual(len(pending), 1)
+        self.assertEqual(pending[0]["attempts"], 1)
+        self.assertEqual(pending[0]["next_attempt"], 1030.0)
+        self.assertEqual(redelivery._stats["webhook"]["failed"], 2)
+        self.assertTrue(redelivery.has_pending_work())
+
+    def test_tracks_sinks_whose_last_delivery_failed(self, time, save_queue):
+        time.time.return_value = 1000.0
+
+        redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503")
+        self.assertEqual(redelivery.failing_sinks(), {"webhook": "503"})
+
+        redelivery.record_delivery("webhook", ALERT)
+        self.assertEqual(redelivery.failing_sinks(), {})
+
+    def test_reports_when_the_queue_cannot_be_persisted(self, time, save_queue):
+        time.time.return_value = 1000.0
+        save_queue.return_value = False
+
+        self.assertFalse(redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503"))
+
+    def test_redelivers_due_alerts(self, time, _):
+        time.time.return_value = 1000.0
+        redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503")
+
+        with mock.patch.object(redelivery, "send_alert_once") as send_alert_once:
+            # the backoff did not expire yet
+            redelivery.redeliver_due_alerts([alert_sink()], now=1029.0)
+            send_alert_once.assert_not_called()
+
+            redelivery.redeliver_due_alerts([alert_sink()], now=1030.0)
+            send_alert_once.assert_called_once_with(ALERT, alert_sink())
+
+        self.assertEqual(redelivery._queue["pending"], [])
+        self.assertEqual(redelivery._stats["webhook"]["delivered"], 1)
+        self.assertEqual(
+            redelivery._stats["webhook"]["recent_alert_timestamps"],
+            [ALERT["timestamp"]],
+        )
+
+    def test_backs_off_and_dead_letters_after_the_last_attempt(self, time, _):
+        time.time.return_value = 1000.0
+        redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503")
+
+        with mock.patch.object(
+            redelivery, "send_alert_once", side_effect=RuntimeError("502")
+        ):
+            redelivery.redeliver_due_alerts([alert_sink()], now=1030.0)
+            pending = redelivery._queue["pending"]
+            self.assertEqual(pending[0]["attempts"], 2)
+            self.assertEqual(pending[0]["next_attempt"], 1090.0)
+            self.assertEqual(pending[0]["last_error"], "502")
+
+            redelivery.redeliver_due_alerts([alert_sink()], now=1090.0)
+
+        self.assertEqual(redelivery._queue["pending"], [])
+        self.assertEqual(len(redelivery._queue["dead_letters"]), 1)
+        self.assertEqual(redelivery._stats["webhook"]["failed"], 3)
+        self.assertEqual(redelivery._stats["webhook"]["dead_lettered"], 1)
+
+    def test_drops_alerts_of_deleted_sinks(self, time, _):
+        time.time.return_value = 1000.0
+        redelivery.enqueue_failed_delivery(ALERT, alert_sink(), "503")
+
+        with mock.patch.object(redelivery, "send_alert_once") as send_alert_once:
+            redelivery.redeliver_due_alerts([], now=1030.0)
+            send_alert_once.assert_not_called()
+
+        self.assertEqual(redelivery._queue["pending"], [])
+        self.assertEqual(redelivery._queue["dead_letters"], [])
+
+    def test_caps_the_backoff(self, *_):
+        policy = alert_sink()["redelivery"]
+        self.assertEqual(redelivery.compute_backoff(policy, 1), 30.0)
+        self.assertEqual(redelivery.compute_backoff(policy, 2), 60.0)
+        self.assertEqual(redelivery.compute_backoff(policy, 5), 60.0)
+
+
+class BuildStatusTest(unittest.TestCase):
+    def test_adds_the_statistics_to_the_status(self):
+        stats = redelivery.DeliveryStats(
+            delivered=2,
+            failed=1,
+            dead_lettered=0,
+            last_delivery_time="2025-01-03T18:48:00Z",
+            last_failure_time="2025-01-03T18:47:56Z",
+            last_error="503",
+            recent_alert_timestamps=[f"2025-01-03T18:47:5{i}Z" for i in range(6)],
+        )
+        status = {
+            "deliveredAlerts": 40,
+            "failedDeliveries": 3,
+            "pendingAlerts": 2,
+            "recentAlertTimestamps": [f"2025-01-03T18:46:5{i}Z" for i in range(6)],
+        }
+
+        self.assertEqual(
+            redelivery._build_status(status, stats, 1),
+            {
+                "deliveredAlerts": 42,
+                "failedDeliveries": 4,
+                "deadLetteredAlerts": 0,
+                "pendingAlerts": 1,
+                "lastDeliveryTime": "2025-01-03T18:48:00Z",
+                "lastFailureTime": "2025-01-03T18:47:56Z",
+                "lastError": "503",
+                # only the most recent timestamps are kept
+                "recentAlertTimestamps": [
+                    *status["recentAlertTimestamps"][2:],
+                    *stats["recent_alert_timestamps"],
+                ],
+            },
+        )
+        self.assertEqual(
+            redelivery._build_status(status, None, 0), {**status, "pendingAlerts": 0}
+        )
+
+
+@mock.patch.object(main, "aggregate_alert", return_value="forward")
+@mock.patch.object(main, "check_quota", return_value="allow")
+@mock.patch.object(main, "try_export_alert")
+@mock.patch.object(main, "print_alert")
+class ForwardAlertTest(unittest.TestCase):
+    def test_queues_alerts_that_failed_to_deliver(self, *_):
+        with (
+            mock.patch.object(main, "send_alert_once", side_effect=RuntimeError("503")),
+            mock.patch.object(
+                main, "enqueue_failed_delivery", return_value=True
+            ) as enqueue_failed_delivery,
+        ):
+            self.assertTrue(main.forward_alert(ALERT, [alert_sink()]))
+            enqueue_failed_delivery.assert_called_once_with(ALERT, alert_sink(), "503")
+
+            # read the events again if the alert cannot be queued
+            enqueue_failed_delivery.return_value = False
+            self.assertFalse(main.forward_alert(ALERT, [alert_sink()]))
+
+    def test_records_deliveries(self, *_):
+        with (
+            mock.patch.object(main, "send_alert_once", return_value=True),
+            mock.patch.object(main, "record_delivery") as record_delivery,
+        ):
+            self.assertTrue(main.forward_alert(ALERT, [alert_sink()]))
+            record_delivery.assert_called_once_with("webhook", ALERT)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,420 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import copy
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+from forwarder import selftest
+from forwarder.alerts import create_alert_description
+
+SELF_TEST_ID = "0123456789abcdef"
+
+
+def deception_policy(traps: list[dict]) -> dict:
+    return {
+        "metadata": {"name": "dp", "generation": 2},
+        "status": {"conditions": [], "traps": traps},
+    }
+
+
+def honeytoken_trap(kind: str = "Pod", name: str = "app", **fields) -> dict:
+    trap = {
+        "index": 0,
+        "trapType": "FilesystemHoneytoken",
+        "placements": [
+            {
+                "kind": kind,
+                "namespace": "koney-demo",
+                "name": name,
+                "containers": ["nginx"],
+                "filePaths": ["/run/secrets/koney/token"],
+            }
+        ],
+    }
+    trap.update(fields)
+    return trap
+
+
+class FakeCustomObjectsApi:
+    """Stores a single deception policy, like the Kubernetes API with its status subresource."""
+
+    def __init__(self, policy: dict):
+        self.policy = policy
+
+    def get_cluster_custom_object(self, *_):
+        return copy.deepcopy(self.policy)
+
+    def replace_cluster_custom_object_status(self, *args):
+        self.policy = copy.deepcopy(args[-1])
+
+    def condition(self) -> dict | None:
+        return selftest._find_condition(self.policy, selftest.LAST_SELF_TEST_CONDITION)
+
+
+class FindSelfTestTargetTest(unittest.TestCase):
+    def test_finds_the_first_placed_honeytoken(self):
+        policy = deception_policy(
+            [
+                {"index": 0, "trapType": "HttpEndpoint", "placements": []},
+                honeytoken_trap(name="expired", expired=True),
+                honeytoken_trap(kind="Deployment", name="web"),
+                honeytoken_trap(name="app"),
+            ]
+        )
+
+        self.assertEqual(
+            selftest.find_self_test_target(policy),
+            {
+                "kind": "Deployment",
+                "namespace": "koney-demo",
+                "name": "web",
+                "container": "nginx",
+                "file_path": "/run/secrets/koney/token",
+            },
+        )
+
+    def test_finds_nothing_without_placements(self):
+        policy = deception_policy([honeytoken_trap(placements=[])])
+        self.assertIsNone(selftest.find_self_test_target(policy))
+
+    def test_finds_the_id_of_the_self_test_in_the_arguments(self):
+        arguments = f'-c ": < \\"$0\\" # KONEY_SELFTEST_{SELF_TEST_ID}" /token'
+        alert = {"process": {"arguments": arguments}}
+        self.assertEqual(selftest.find_self_test_id(alert), SELF_TEST_ID)  # type: ignore
+        self.assertIsNone(selftest.find_self_test_id({"process": None}))  # type: ignore
+
+
+@mock.patch.object(selftest.time, "sleep")
+@mock.patch.object(selftest.secrets, "token_hex", return_value=SELF_TEST_ID)
+@mock.patch.object(selftest.client, "CoreV1Api", create=True)
+class RunSelfTestTest(unittest.TestCase):
+    def setUp(self):
+        self.api = FakeCustomObjectsApi(deception_policy([honeytoken_trap()]))
+        patcher = mock.patch.object(
+            selftest.client, "CustomObjectsApi", create=True, return_value=self.api
+        )
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+    def test_passes_once_the_alert_was_forwarded(self, *_):
+        def touch_honeytoken(*args, **kwargs):
+            # the command is captured and the alert is forwarded by the pipeline
+            self.assertEqual(self.api.condition()["status"], "Unknown")
+            self.assertEqual(kwargs["command"][-1], "/run/secrets/koney/token")
+            self.assertIn(f"KONEY_SELFTEST_{SELF_TEST_ID}", kwargs["command"][2])
+            alert = {
+                "deception_policy_name": "dp",
+                "timestamp": "2025-01-01T00:00:00Z",
+            }
+            selftest.try_report_self_test_passed(alert, SELF_TEST_ID)  # type: ignore
+
+        with mock.patch.object(selftest, "stream", side_effect=touch_honeytoken):
+            result = selftest.run_self_test("dp")
+
+        self.assertTrue(result["passed"], result["message"])
+        condition = self.api.condition() or {}
+        self.assertEqual(condition["status"], "True")
+        self.assertEqual(condition["reason"], selftest.SELF_TEST_REASON_PASSED)
+        self.assertEqual(condition["observedGeneration"], 2)
+
+        status = self.api.policy["status"]["selfTest"]
+        self.assertTrue(status["passed"])
+        self.assertRegex(status["latency"], r"^\d+\.\d{3}s$")
+        self.assertEqual(status["lastSuccessTime"], status["lastRunTime"])
+        self.assertEqual(status["consecutiveFailures"], 0)
+
+    def test_times_out_if_the_alert_is_not_forwarded(self, *_):
+        with (
+            mock.patch.object(selftest, "stream"),
+            mock.patch.object(selftest, "SELF_TEST_TIMEOUT_SECONDS", 0),
+        ):
+            result = selftest.run_self_test("dp")
+
+        self.assertFalse(result["passed"])
+        condition = self.api.condition() or {}
+        self.assertEqual(condition["status"], "False")
+        self.assertEqual(condition["reason"], selftest.SELF_TEST_REASON_TIMED_OUT)
+        self.assertEqual(
+            self.api.policy["status"]["selfTest"],
+            {
+                "lastRunTime": condition["lastTransitionTime"],
+                "passed": False,
+                "consecutiveFailures": 1,
+            },
+        )
+
+    def test_fails_if_the_honeytoken_cannot_be_touched(self, *_):
+        with mock.patch.object(selftest, "stream", side_effect=RuntimeError("no sh")):
+            result = selftest.run_self_test("dp")
+
+        self.assertFalse(result["passed"])
+        self.assertIn("no sh", result["message"])
+        self.assertEqual(
+            (self.api.condition() or {})["reason"], selftest.SELF_TEST_REASON_FAILED
+        )
+
+    def test_touches_a_running_pod_of_deployments(self, core_v1_api, *_):
+        self.api.policy = deception_policy([honeytoken_trap(kind="Deployment")])
+        deployment = SimpleNamespace(
+            spec=SimpleNamespace(selector=SimpleNamespace(match_labels={"app": "web"}))
+        )
+        pod = SimpleNamespace(
+            metadata=SimpleNamespace(name="web-1", deletion_timestamp=None),
+            status=SimpleNamespace(phase="Running"),
+        )
+        core_v1_api.return_value.list_namespaced_pod.return_value.items = [pod]
+
+        apps_v1_api = mock.Mock()
+        apps_v1_api.read_namespaced_deployment.return_value = deployment
+
+        with (
+            mock.patch.object(
+                selftest.client, "AppsV1Api", create=True, return_value=apps_v1_api
+            ),
+            mock.patch.object(selftest, "stream") as stream,
+            mock.patch.object(selftest, "SELF_TEST_TIMEOUT_SECONDS", 0),
+        ):
+            selftest.run_self_test("dp")
+
+        self.assertEqual(stream.call_args.args[1:], ("web-1", "koney-demo"))
+        core_v1_api.return_value.list_namespaced_pod.assert_called_once_with(
+            "koney-demo", label_selector="app=web"
+        )
+
+    def test_does_not_report_results_of_other_self_tests(self, *_):
+        alert = {"deception_policy_name": "dp"}
+        selftest.try_report_self_test_passed(alert, SELF_TEST_ID)  # type: ignore
+        self.assertIsNone(self.api.condition())
+
+
+class RunSelfTestsTest(unittest.TestCase):
+    def test_skips_deception_policies_with_their_own_self_test(self):
+        tested = deception_policy([honeytoken_trap()])
+        scheduled = deception_policy([honeytoken_trap()])
+        scheduled["metadata"] = {"name": "dp-scheduled"}
+        scheduled["spec"] = {"selfTest": {"schedule": "*/5 * * * *"}}
+        unplaced = deception_policy([])
+        unplaced["metadata"] = {"name": "dp-unplaced"}
+
+        api = mock.Mock()
+        api.list_cluster_custom_object.return_value = {
+            "items": [tested, scheduled, unplaced]
+        }
+        with (
+            mock.patch.object(
+                selftest.client, "CustomObjectsApi", create=True, return_value=api
+            ),
+            mock.patch.object(selftest, "run_self_test") as run_self_test,
+        ):
+            selftest.run_self_tests()
+
+        run_self_test.assert_called_once_with("dp")
+
+    def test_tests_the_given_deception_policies(self):
+        with mock.patch.object(selftest, "run_self_test") as run_self_test:
+            selftest.run_self_tests(["dp-scheduled"])
+
+        run_self_test.assert_called_once_with("dp-scheduled")
+
+
+@mock.patch.object(selftest.config, "load_incluster_config", create=True)
+@mock.patch.object(selftest.logging, "basicConfig")
+@mock.patch.object(selftest, "configure_logging")
+class MainTest(unittest.TestCase):
+    failed = selftest.SelfTestResult(
+        deceptionPolicyName="dp", passed=False, message="timed out"
+    )
+
+    def test_tests_the_deception_policy_of_the_cron_job(self, *_):
+        passed = selftest.SelfTestResult(
+            deceptionPolicyName="dp", passed=True, message="passed"
+        )
+        with (
+            mock.patch.dict(selftest.os.environ, {"KONEY_DECEPTION_POLICY_NAME": "dp"}),
+            mock.patch.object(
+                selftest, "run_self_tests", return_value=[passed]
+            ) as run_self_tests,
+            mock.patch.object(selftest, "report_self_test_failures") as report,
+        ):
+            self.assertEqual(selftest.main(), 0)
+
+        run_self_tests.assert_called_once_with(["dp"])
+        report.assert_not_called()
+
+    def test_reports_failures_to_the_alert_forwarder_if_enabled(self, *_):
+        env = {
+            "KONEY_DECEPTION_POLICY_NAME": "",
+            "KONEY_SELF_TEST_ALERT_ON_FAILURE": "true",
+        }
+        with (
+            mock.patch.dict(selftest.os.environ, env),
+            mock.patch.object(
+                selftest, "run_self_tests", return_value=[self.failed]
+            ) as run_self_tests,
+            mock.patch.object(selftest, "report_self_test_failures") as report,
+        ):
+            self.assertEqual(selftest.main(), 1)
+
+        run_self_tests.assert_called_once_with(None)
+        report.assert_called_once_with([self.failed])
+
+    def test_does_not_report_failures_if_disabled(self, *_):
+        env = {"KONEY_SELF_TEST_ALERT_ON_FAILURE": "false"}
+        with (
+            mock.patch.dict(selftest.os.environ, env),
+            mock.patch.object(selftest, "run_self_tests", return_value=[self.failed]),
+            mock.patch.object(selftest, "report_self_test_failures") as report,
+        ):
+            self.assertEqual(selftest.main(), 1)
+
+        report.assert_not_called()
+
+
+class ReportSelfTestFailuresTest(unittest.TestCase):
+    def test_authenticates_and_trusts_the_alert_forwarder(self):
+        env = {
+            "KONEY_ALERT_FORWARDER_URL": "https://forwarder:8000/",
+            "KONEY_ALERT_FORWARDER_TOKEN": "secret",
+            "KONEY_ALERT_FORWARDER_CA_FILE": "/etc/koney/tls/ca.crt",
+        }
+        failed = selftest.SelfTestResult(
+            deceptionPolicyName="dp", passed=False, message="timed out"
+        )
+        with (
+            mock.patch.dict(selftest.os.environ, env),
+            mock.patch.object(selftest.requests, "post") as post,
+        ):
+            selftest.report_self_test_failures([failed])
+
+        self.assertEqual(
+            post.call_args.args, ("https://forwarder:8000/selftest/failures",)
+        )
+        self.assertEqual(post.call_args.kwargs["json"], {"results": [failed]})
+        self.assertEqual(
+            post.call_args.kwargs["headers"], {"Authorization": "Bearer secret"}
+        )
+        self.assertEqual(post.call_args.kwargs["verify"], "/etc/koney/tls/ca.crt")
+        post.return_value.raise_for_status.assert_called_once()
+
+
+class BuildSelfTestStatusTest(unittest.TestCase):
+    def test_counts_consecutive_failures_and_keeps_the_last_success(self):
+        passed = selftest.build_self_test_status(None, True, 1.5, "2025-01-01T00:00:00Z")
+        self.assertEqual(passed["latency"], "1.500s")
+
+        failed = selftest.build_self_test_status(
+            passed, False, None, "2025-01-01T01:00:00Z"
+        )
+        failed = selftest.build_self_test_status(
+            failed, False, None, "2025-01-01T02:00:00Z"
+        )
+        self.assertEqual(
+            failed,
+            {
+                "lastRunTime": "2025-01-01T02:00:00Z",
+                "passed": False,
+                "consecutiveFailures": 2,
+                "lastSuccessTime": "2025-01-01T00:00:00Z",
+            },
+        )
+
+
+class BuildSelfTestFailedAlertTest(unittest.TestCase):
+    def test_marks_the_alert_as_failed_self_test(self):
+        result = selftest.SelfTestResult(
+            deceptionPolicyName="dp", passed=False, message="no alert was forwarded"
+        )
+        alert = selftest.build_self_test_failed_alert(result)
+
+        self.assertEqual(alert["deception_policy_name"], "dp")
+        self.assertTrue(selftest.is_self_test_alert(alert))
+        self.assertTrue(alert["metadata"][selftest.SELF_TEST_FAILED_METADATA])
+        self.assertEqual(alert["metadata"]["message"], "no alert was forwarded")
+        self.assertIn("(dp) failed", create_alert_description(alert))
+
+
+def cron_job(name: str, env: list | None = None) -> SimpleNamespace:
+    container = SimpleNamespace(name="self-test", env=env)
+    pod_spec = SimpleNamespace(containers=[container])
+    job_spec = SimpleNamespace(template=SimpleNamespace(spec=pod_spec))
+    return SimpleNamespace(
+        metadata=SimpleNamespace(name=name, uid="uid-" + name),
+        spec=SimpleNamespace(
+            job_template=SimpleNamespace(
+                metadata=SimpleNamespace(labels={"app": "koney"}), spec=job_spec
+            )
+        ),
+    )
+
+
+@mock.patch.object(selftest.client, "V1EnvVar", SimpleNamespace, create=True)
+@mock.patch.object(selftest.client, "V1OwnerReference", SimpleNamespace, create=True)
+@mock.patch.object(selftest.client, "V1ObjectMeta", SimpleNamespace, create=True)
+@mock.patch.object(selftest.client, "V1Job", SimpleNamespace, create=True)
+class StartSelfTestJobTest(unittest.TestCase):
+    def start(self, name: str | None, own: list, shared: SimpleNamespace | None):
+        api = mock.Mock()
+        api.list_namespaced_cron_job.return_value = SimpleNamespace(items=own)
+        if shared is None:
+            not_found = selftest.ApiException()
+            not_found.status = 404
+            api.read_namespaced_cron_job.side_effect = not_found
+        else:
+            api.read_namespaced_cron_job.return_value = shared
+        with mock.patch.object(
+            selftest.client, "BatchV1Api", return_value=api, create=True
+        ):
+            return selftest.start_self_test_job(name), api
+
+    def created_job(self, api: mock.Mock) -> SimpleNamespace:
+        api.create_namespaced_job.assert_called_once()
+        return api.create_namespaced_job.call_args.args[1]
+
+    def test_starts_a_job_from_the_shared_cron_job(self):
+        job_name, api = self.start(None, [], cron_job("koney-self-test"))
+
+        api.list_namespaced_cron_job.assert_not_called()
+        job = self.created_job(api)
+        self.assertEqual(job_name, job.metadata.name)
+        self.assertTrue(job_name.startswith("koney-self-test-manual-"))
+        self.assertEqual(job.metadata.labels, {"app": "koney"})
+        self.assertEqual(job.metadata.owner_references[0].uid, "uid-koney-self-test")
+        self.assertIsNone(job.spec.template.spec.containers[0].env)
+
+    def test_prefers_the_cron_job_of_the_deception_policy(self):
+        env = [SimpleNamespace(name="KONEY_DECEPTION_POLICY_NAME", value="dp")]
+        own = cron_job("koney-self-test-0123456789", env)
+        job_name, api = self.start("dp", [own], cron_job("koney-self-test"))
+
+        api.read_namespaced_cron_job.assert_not_called()
+        job = self.created_job(api)
+        self.assertTrue(job_name.startswith("koney-self-test-0123456789-manual-"))
+        self.assertEqual(job.spec.template.spec.containers[0].env, env)
+
+    def test_tests_a_single_deception_policy_with_the_shared_cron_job(self):
+        _, api = self.start("dp", [], cron_job("koney-self-test"))
+
+        env = self.created_job(api).spec.template.spec.containers[0].env
+        self.assertEqual(
+            env, [SimpleNamespace(name="KONEY_DECEPTION_POLICY_NAME", value="dp")]
+        )
+
+    def test_returns_none_without_a_cron_job(self):
+        job_name, api = self.start("dp", [], None)
+
+        self.assertIsNone(job_name)
+        api.create_namespaced_job.assert_not_called()
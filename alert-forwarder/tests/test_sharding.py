@@ -0,0 +1,209 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+from forwarder import offsets, sharding
+
+
+def replica_pod(name: str, ready: bool = True, deleting: bool = False):
+    return SimpleNamespace(
+        metadata=SimpleNamespace(
+            name=name, deletion_timestamp="2025-01-03T18:47:56Z" if deleting else None
+        ),
+        status=SimpleNamespace(
+            conditions=[SimpleNamespace(type="Ready", status=str(ready))]
+        ),
+    )
+
+
+@mock.patch.object(sharding, "KONEY_POD_NAME", "koney-a")
+class ShardingTest(unittest.TestCase):
+    def setUp(self):
+        sharding._replicas_cache = None
+
+    def test_assigns_every_key_to_exactly_one_replica(self):
+        replicas = ["koney-a", "koney-b", "koney-c"]
+        keys = [f"tetragon-{i}" for i in range(30)]
+
+        owners = {key: sharding.shard_owner(key, replicas) for key in keys}
+        self.assertEqual(set(owners.values()), set(replicas))
+
+        # only the keys of a removed replica move to other replicas
+        for key in keys:
+            owner = sharding.shard_owner(key, ["koney-a", "koney-c"])
+            if owners[key] != "koney-b":
+                self.assertEqual(owner, owners[key])
+
+    def test_lists_ready_replicas_only(self):
+        api = mock.Mock()
+        api.list_namespaced_pod.return_value = SimpleNamespace(
+            items=[
+                replica_pod("koney-b"),
+                replica_pod("koney-c", ready=False),
+                replica_pod("koney-d", deleting=True),
+            ]
+        )
+        with (
+            mock.patch.object(
+                sharding.client, "CoreV1Api", return_value=api, create=True
+            ),
+            mock.patch.object(sharding.client, "V1PodList", create=True),
+        ):
+            self.assertEqual(sharding.list_replicas(), ["koney-a", "koney-b"])
+
+    def test_keeps_the_last_known_replicas_if_they_cannot_be_listed(self):
+        with (
+            mock.patch.object(sharding, "time") as time,
+            mock.patch.object(sharding, "list_replicas") as list_replicas,
+        ):
+            time.time.return_value = 1000.0
+            list_replicas.return_value = ["koney-a", "koney-b"]
+            self.assertEqual(sharding.read_cached_replicas(), ["koney-a", "koney-b"])
+
+            time.time.return_value = 1000.0 + sharding.REPLICAS_CACHE_SECONDS
+            list_replicas.return_value = None
+            self.assertEqual(sharding.read_cached_replicas(), ["koney-a", "koney-b"])
+
+    def test_does_all_the_work_without_known_replicas(self):
+        with mock.patch.object(sharding, "list_replicas", return_value=None):
+            self.assertTrue(sharding.owns_shard("tetragon-a"))
+            self.assertTrue(sharding.owns_shard("tetragon-b"))
+
+    def test_does_all_the_work_without_a_pod_name(self):
+        with (
+            mock.patch.object(sharding, "KONEY_POD_NAME", ""),
+            mock.patch.object(sharding, "list_replicas") as list_replicas,
+        ):
+            self.assertTrue(sharding.owns_shard("tetragon-a"))
+        list_replicas.assert_not_called()
+
+
+class SaveWatermarksTest(unittest.TestCase):
+    def config_map(self, watermarks: dict):
+        return SimpleNamespace(
+            metadata=SimpleNamespace(resource_version="7"),
+            data={offsets.OFFSETS_CONFIGMAP_KEY: json.dumps(watermarks)},
+        )
+
+    def saved_watermarks(self, api) -> dict:
+        config_map = api.replace_namespaced_config_map.call_args.args[2]
+        return json.loads(config_map.data[offsets.OFFSETS_CONFIGMAP_KEY])
+
+    def save(self, api, *args) -> bool:
+        with (
+            mock.patch.object(
+                offsets.client, "CoreV1Api", return_value=api, create=True
+            ),
+            mock.patch.multiple(
+                offsets.client,
+                V1ConfigMap=SimpleNamespace,
+                V1ObjectMeta=SimpleNamespace,
+                create=True,
+            ),
+        ):
+            return offsets.save_watermarks(*args)
+
+    def test_keeps_the_watermarks_of_other_replicas(self):
+        watermark_a = {"time": "2025-01-03T18:47:56Z", "hashes": ["a"]}
+        watermark_b = {"time": "2025-01-03T18:47:57Z", "hashes": ["b"]}
+        watermark_c = {"time": "2025-01-03T18:47:58Z", "hashes": ["c"]}
+        api = mock.Mock()
+        api.read_namespaced_config_map.return_value = self.config_map(
+            {"tetragon-a": watermark_a, "tetragon-c": watermark_c}
+        )
+
+        streams = ["tetragon-a", "tetragon-b"]
+        saved = self.save(api, {"tetragon-b": watermark_b}, streams)
+
+        self.assertTrue(saved)
+        self.assertEqual(
+            self.saved_watermarks(api),
+            {"tetragon-a": watermark_a, "tetragon-b": watermark_b},
+        )
+        config_map = api.replace_namespaced_config_map.call_args.args[2]
+        self.assertEqual(config_map.metadata.resource_version, "7")
+
+    def test_keeps_the_delivered_alert_ids(self):
+        api = mock.Mock()
+        api.read_namespaced_config_map.return_value = SimpleNamespace(
+            metadata=SimpleNamespace(resource_version="7"),
+            data={offsets.DELIVERED_CONFIGMAP_KEY: '{"webhook": ["A"]}'},
+        )
+
+        self.assertTrue(self.save(api, {}))
+        config_map = api.replace_namespaced_config_map.call_args.args[2]
+        self.assertEqual(
+            config_map.data[offsets.DELIVERED_CONFIGMAP_KEY], '{"webhook": ["A"]}'
+        )
+
+    def test_tries_again_if_another_replica_saved_meanwhile(self):
+        watermark = {"time": "2025-01-03T18:47:56Z", "hashes": ["a"]}
+        conflict = offsets.ApiException()
+        conflict.status = 409
+        api = mock.Mock()
+        api.read_namespaced_config_map.return_value = self.config_map({})
+        api.replace_namespaced_config_map.side_effect = [conflict, None]
+
+        self.assertTrue(self.save(api, {"tetragon-a": watermark}))
+        self.assertEqual(api.replace_namespaced_config_map.call_count, 2)
+
+
+class SaveDeliveredAlertIdsTest(unittest.TestCase):
+    def save(self, saved: dict, sink_name: str, alert_ids: list[str]) -> dict:
+        api = mock.Mock()
+        api.read_namespaced_config_map.return_value = SimpleNamespace(
+            metadata=SimpleNamespace(resource_version="7"),
+            data={
+                offsets.OFFSETS_CONFIGMAP_KEY: "{}",
+                offsets.DELIVERED_CONFIGMAP_KEY: json.dumps(saved),
+            },
+        )
+        with (
+            mock.patch.object(
+                offsets.client, "CoreV1Api", return_value=api, create=True
+            ),
+            mock.patch.multiple(
+                offsets.client,
+                V1ConfigMap=SimpleNamespace,
+                V1ObjectMeta=SimpleNamespace,
+                create=True,
+            ),
+        ):
+            self.assertTrue(offsets.save_delivered_alert_ids(sink_name, alert_ids))
+
+        config_map = api.replace_namespaced_config_map.call_args.args[2]
+        self.assertEqual(config_map.data[offsets.OFFSETS_CONFIGMAP_KEY], "{}")
+        return json.loads(config_map.data[offsets.DELIVERED_CONFIGMAP_KEY])
+
+    def test_appends_to_the_ids_of_other_replicas(self):
+        saved = {"webhook": ["A", "B"], "slack": ["A"]}
+
+        self.assertEqual(
+            self.save(saved, "webhook", ["A", "C"]),
+            {"webhook": ["B", "A", "C"], "slack": ["A"]},
+        )
+
+    def test_keeps_the_most_recent_ids(self):
+        limit = offsets.DELIVERED_ALERT_IDS_PER_SINK
+        saved = {"webhook": [str(i) for i in range(limit)]}
+
+        alert_ids = self.save(saved, "webhook", ["new"])["webhook"]
+        self.assertEqual(len(alert_ids), limit)
+        self.assertEqual(alert_ids[0], "1")
+        self.assertEqual(alert_ids[-1], "new")
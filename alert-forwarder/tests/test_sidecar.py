@@ -0,0 +1,98 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import json
+import unittest
+from unittest import mock
+
+from forwarder import main
+from forwarder.sidecar import process_sidecar_alert
+
+
+def sidecar_alert(**overrides) -> dict:
+    payload = {
+        "time": "2025-01-03T18:47:56Z",
+        "node": "node-1",
+        "pod": {"name": "web-7d4b9c8f6-abcde", "namespace": "default"},
+        "metadata": {
+            "koney-deception-policy-name": "deceptionpolicy-sample",
+            "koney-trap-type": "filesystem_honeytoken",
+            "koney-file-path": "/run/secrets/koney/token",
+            "koney-severity": "high",
+            "koney-alert-tags": '{"team": "payments"}',
+            "koney-trap-hash": "abc123",
+            "koney-response-actions": '["killPod"]',
+        },
+    }
+    payload.update(overrides)
+    return payload
+
+
+class ProcessSidecarAlertTest(unittest.TestCase):
+    def test_maps_sidecar_reports(self):
+        alert = process_sidecar_alert(sidecar_alert())
+
+        self.assertEqual(alert["timestamp"], "2025-01-03T18:47:56Z")
+        self.assertEqual(alert["deception_policy_name"], "deceptionpolicy-sample")
+        self.assertEqual(alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(alert["severity"], "HIGH")
+        self.assertEqual(alert["tags"], {"team": "payments"})
+        self.assertEqual(alert["metadata"], {"file_path": "/run/secrets/koney/token"})
+        self.assertEqual(alert["pod"]["name"], "web-7d4b9c8f6-abcde")
+        self.assertEqual(alert["pod"]["namespace"], "default")
+        self.assertEqual(alert["node"]["name"], "node-1")
+        self.assertEqual(alert["trap"]["hash"], "abc123")
+        self.assertEqual(alert["response"]["actions"], ["killPod"])
+
+    def test_leaves_the_process_and_container_unknown(self):
+        alert = process_sidecar_alert(sidecar_alert())
+
+        self.assertIsNone(alert["process"])
+        self.assertIsNone(alert["pod"]["container"]["name"])
+        self.assertIsNone(alert["trap"]["deception_policy_generation"])
+
+
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+class HandleSidecarTest(unittest.TestCase):
+    def handle(self, payload: dict, authorized: bool = True) -> mock.Mock:
+        request = mock.Mock()
+        request.body = mock.AsyncMock(return_value=json.dumps(payload).encode())
+        with (
+            mock.patch.object(main, "is_authorized_request", return_value=authorized),
+            mock.patch.object(main, "is_duplicate_access", return_value=False),
+            mock.patch.object(main, "enrich_alert"),
+            mock.patch.object(main, "try_read_alert_sinks", return_value=[]),
+            mock.patch.object(main, "try_request_response"),
+            mock.patch.object(main, "forward_alert") as forward_alert,
+        ):
+            asyncio.run(main.handle_sidecar(mock.Mock(), request))
+        return forward_alert
+
+    def test_forwards_reports_of_sidecars(self, *_):
+        forward_alert = self.handle(sidecar_alert())
+
+        forward_alert.assert_called_once()
+        alert = forward_alert.call_args.args[0]
+        self.assertEqual(alert["pod"]["name"], "web-7d4b9c8f6-abcde")
+
+    def test_rejects_unauthorized_reports(self, *_):
+        forward_alert = self.handle(sidecar_alert(), authorized=False)
+
+        forward_alert.assert_not_called()
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,107 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+
+from forwarder.siem import format_cef, format_leef, format_syslog_message
+
+ALERT = {
+    "timestamp": "2025-01-03T18:47:56.123456789Z",
+    "deception_policy_name": "deceptionpolicy-servicetoken",
+    "trap_type": "filesystem_honeytoken",
+    "severity": "HIGH",
+    "confidence": "high",
+    "tags": {},
+    "trap": {"hash": "0b4a1bd5", "attck_techniques": ["T1552.001", "T1083"]},
+    "metadata": {"file_path": "/run/secrets/koney/service_token"},
+    "pod": {
+        "name": "nginx-1",
+        "namespace": "koney-demo",
+        "container": {"id": "e19c", "name": "nginx"},
+    },
+    "node": {"name": "minikube"},
+    "process": {
+        "uid": 0,
+        "pid": 148373,
+        "cwd": "/",
+        "binary": "/usr/bin/cat",
+        "arguments": "/run/secrets/koney/service_token a=b|c",
+    },
+    "response": None,
+}
+
+
+class FormatCefTest(unittest.TestCase):
+    def test_formats_the_header(self):
+        header = format_cef(ALERT).split("|")[:7]
+        self.assertEqual(
+            header,
+            [
+                "CEF:0",
+                "Dynatrace Research",
+                "Koney",
+                "1.0",
+                "filesystem_honeytoken",
+                "Access to honeytoken (/run/secrets/koney/service_token) "
+                "in pod (koney-demo/nginx-1) detected",
+                "8",
+            ],
+        )
+
+    def test_maps_fields_to_extensions(self):
+        extension = format_cef(ALERT).split("|", 7)[7]
+        self.assertIn("rt=1735930076123", extension)
+        self.assertIn("shost=nginx-1", extension)
+        self.assertIn("sproc=cat spid=148373 suid=0", extension)
+        self.assertIn("cs1=deceptionpolicy-servicetoken", extension)
+        self.assertIn("cs5=T1552.001,T1083", extension)
+
+    def test_escapes_extensions(self):
+        extension = format_cef(ALERT).split("|", 7)[7]
+        self.assertIn(
+            "cs4=/usr/bin/cat /run/secrets/koney/service_token a\\=b|c", extension
+        )
+
+
+class FormatLeefTest(unittest.TestCase):
+    def test_formats_the_header_and_attributes(self):
+        *header, attributes = format_leef(ALERT).split("|", 5)
+        self.assertEqual(
+            header,
+            ["LEEF:1.0", "Dynatrace Research", "Koney", "1.0", "filesystem_honeytoken"],
+        )
+
+        attributes = dict(attr.split("=", 1) for attr in attributes.split("\t"))
+        self.assertEqual(attributes["devTime"], "Jan 03 2025 18:47:56.123 UTC")
+        self.assertEqual(attributes["sev"], "8")
+        self.assertEqual(attributes["pod"], "nginx-1")
+        self.assertEqual(attributes["commandLine"].split()[-1], "a=b|c")
+
+
+class FormatSyslogMessageTest(unittest.TestCase):
+    def test_computes_the_priority_from_facility_and_severity(self):
+        message = format_syslog_message(ALERT, "CEF:0|...", "local0", "koney-0")
+        # local0 (16) * 8 + error (3)
+        self.assertEqual(message, "<131>Jan  3 18:47:56 koney-0 koney: CEF:0|...")
+
+        message = format_syslog_message(
+            {**ALERT, "severity": None}, "CEF:0|...", "auth", "koney-0"
+        )
+        # auth (4) * 8 + warning (4)
+        self.assertTrue(message.startswith("<36>"))
+
+
+if __name__ == "__main__":
+    unittest.main()
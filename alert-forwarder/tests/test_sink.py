@@ -0,0 +1,415 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import base64
+import socket
+import unittest
+from unittest import mock
+
+from forwarder import sink
+from forwarder.alerts import create_alert_id
+from forwarder.types import (
+    AlertSink,
+    ChatSink,
+    DynatraceSink,
+    RedeliveryPolicy,
+    SyslogSink,
+    WebhookRetryPolicy,
+    WebhookSink,
+)
+
+REDELIVERY = RedeliveryPolicy(max_attempts=3, initial_backoff=30.0, max_backoff=60.0)
+
+ALERT = {"timestamp": "2025-01-03T18:47:56Z", "deception_policy_name": "dp"}
+
+DYNATRACE_ALERT = {
+    **ALERT,
+    "trap_type": "filesystem_honeytoken",
+    "severity": None,
+    "confidence": "high",
+    "tags": {},
+    "trap": {"hash": "0b4a1bd5", "attck_techniques": ["T1552.001"]},
+    "metadata": {"file_path": "/run/secrets/koney/service_token"},
+    "pod": {
+        "name": "nginx-1",
+        "namespace": "koney-demo",
+        "container": {"id": "e19c", "name": "nginx"},
+    },
+    "node": {"name": "minikube"},
+    "process": {"uid": 0, "pid": 1, "cwd": "/", "binary": "/usr/bin/cat"},
+    "response": None,
+}
+
+
+def dynatrace_sink(api: str, security_context: str | None = None) -> AlertSink:
+    return AlertSink(
+        name="dynatrace",
+        match=None,
+        redelivery=REDELIVERY,
+        dynatrace_sink=DynatraceSink(
+            api_url="https://abc12345.live.dynatrace.com",
+            api_token="dt0c01.TOKEN",
+            severity="HIGH",
+            api=api,
+            security_context=security_context,
+        ),
+        webhook_sink=None,
+        chat_sink=None,
+        syslog_sink=None,
+    )
+
+
+def webhook_sink(max_retries: int = 2) -> AlertSink:
+    return AlertSink(
+        name="webhook",
+        match=None,
+        redelivery=REDELIVERY,
+        dynatrace_sink=None,
+        webhook_sink=WebhookSink(
+            url="https://alerts.example.com/koney",
+            headers={"X-Team": "blue"},
+            retry=WebhookRetryPolicy(
+                max_retries=max_retries, initial_backoff=1.0, max_backoff=1.5
+            ),
+        ),
+        chat_sink=None,
+        syslog_sink=None,
+    )
+
+
+def chat_sink(provider: str, max_messages_per_minute: int = 2) -> AlertSink:
+    return AlertSink(
+        name=f"chat-{provider}",
+        match=None,
+        redelivery=REDELIVERY,
+        dynatrace_sink=None,
+        webhook_sink=None,
+        chat_sink=ChatSink(
+            provider=provider,
+            webhook_url="https://hooks.example.com/services/T000/B000/XXXX",
+            max_messages_per_minute=max_messages_per_minute,
+        ),
+        syslog_sink=None,
+    )
+
+
+def response(status_code: int) -> mock.Mock:
+    return mock.Mock(status_code=status_code, text="")
+
+
+@mock.patch.object(sink, "save_delivered_alert_ids")
+@mock.patch.object(sink, "send_alert")
+class SendAlertOnceTest(unittest.TestCase):
+    def setUp(self):
+        sink._delivered_alerts = None
+
+    def test_skips_alerts_that_were_delivered_before_a_restart(self, send_alert, _):
+        persisted = {"webhook": [create_alert_id(ALERT)]}
+        with mock.patch.object(
+            sink, "load_delivered_alert_ids", return_value=persisted
+        ):
+            self.assertFalse(sink.send_alert_once(ALERT, webhook_sink()))
+
+        send_alert.assert_not_called()
+
+    def test_persists_the_ids_of_delivered_alerts(self, send_alert, save):
+        with mock.patch.object(sink, "load_delivered_alert_ids", return_value={}):
+            self.assertTrue(sink.send_alert_once(ALERT, webhook_sink()))
+            self.assertFalse(sink.send_alert_once(ALERT, webhook_sink()))
+
+        send_alert.assert_called_once_with(ALERT, webhook_sink())
+        save.assert_called_once_with("webhook", [create_alert_id(ALERT)])
+
+    def test_does_not_persist_alerts_that_failed_to_deliver(self, send_alert, save):
+        send_alert.side_effect = RuntimeError("503")
+        with mock.patch.object(sink, "load_delivered_alert_ids", return_value={}):
+            with self.assertRaises(RuntimeError):
+                sink.send_alert_once(ALERT, webhook_sink())
+
+        save.assert_not_called()
+
+
+@mock.patch.object(sink.time, "sleep")
+@mock.patch.object(sink, "_get_cluster_uid", return_value=None)
+class SendAlertToWebhookTest(unittest.TestCase):
+    def test_posts_the_alert(self, _, sleep):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(200)
+        ) as post:
+            sink.send_alert(ALERT, webhook_sink())
+
+        post.assert_called_once()
+        self.assertEqual(post.call_args.args[0], "https://alerts.example.com/koney")
+        self.assertEqual(post.call_args.kwargs["json"], ALERT)
+        self.assertEqual(post.call_args.kwargs["headers"]["X-Team"], "blue")
+        self.assertEqual(
+            post.call_args.kwargs["headers"]["Idempotency-Key"], create_alert_id(ALERT)
+        )
+        sleep.assert_not_called()
+
+    def test_retries_server_errors_with_backoff(self, _, sleep):
+        responses = [response(503), response(429), response(202)]
+        with mock.patch.object(sink.requests, "post", side_effect=responses) as post:
+            sink.send_alert(ALERT, webhook_sink())
+
+        self.assertEqual(post.call_count, 3)
+        self.assertEqual([c.args[0] for c in sleep.call_args_list], [1.0, 1.5])
+
+    def test_fails_after_the_last_retry(self, _, sleep):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(500)
+        ) as post:
+            with self.assertRaises(RuntimeError):
+                sink.send_alert(ALERT, webhook_sink(max_retries=1))
+
+        self.assertEqual(post.call_count, 2)
+
+    def test_does_not_retry_client_errors(self, _, sleep):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(400)
+        ) as post:
+            with self.assertRaises(RuntimeError):
+                sink.send_alert(ALERT, webhook_sink())
+
+        post.assert_called_once()
+
+
+@mock.patch.object(sink, "_get_cluster_uid", return_value="c1")
+class SendAlertToDynatraceTest(unittest.TestCase):
+    def send(self, api: str, status_code: int, security_context: str | None = None):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(status_code)
+        ) as post:
+            sink.send_alert(DYNATRACE_ALERT, dynatrace_sink(api, security_context))
+        return post.call_args
+
+    def test_ingests_security_events(self, _):
+        call = self.send("securityEvents", 202, security_context="team-blue")
+        self.assertTrue(call.args[0].endswith("/platform/ingest/v1/security.events"))
+        self.assertEqual(call.kwargs["json"]["finding.severity"], "HIGH")
+        self.assertEqual(call.kwargs["json"]["dt.security_context"], "team-blue")
+
+    def test_ingests_events_linked_to_the_pod(self, _):
+        call = self.send("events", 201)
+        payload = call.kwargs["json"]
+        self.assertTrue(call.args[0].endswith("/api/v2/events/ingest"))
+        self.assertEqual(payload["eventType"], "CUSTOM_ALERT")
+        self.assertIn('entityName.equals("nginx-1")', payload["entitySelector"])
+        self.assertIn('entityName.equals("koney-demo")', payload["entitySelector"])
+        self.assertEqual(payload["properties"]["k8s.pod.name"], "nginx-1")
+        self.assertEqual(
+            payload["properties"]["koney.trap.attck_techniques"], '["T1552.001"]'
+        )
+        self.assertNotIn("dt.security_context", payload["properties"])
+
+    def test_ingests_logs(self, _):
+        call = self.send("logs", 204, security_context="team-blue")
+        payload = call.kwargs["json"]
+        self.assertTrue(call.args[0].endswith("/api/v2/logs/ingest"))
+        self.assertEqual(payload["loglevel"], "ERROR")
+        self.assertEqual(payload["k8s.namespace.name"], "koney-demo")
+        self.assertEqual(payload["dt.security_context"], "team-blue")
+        self.assertNotIn("koney.response.actions", payload)
+
+    def test_fails_on_errors(self, _):
+        with self.assertRaises(RuntimeError):
+            self.send("logs", 400)
+
+
+@mock.patch.object(sink.time, "monotonic", return_value=1000.0)
+@mock.patch.object(sink, "_get_cluster_uid", return_value=None)
+class SendAlertToChatTest(unittest.TestCase):
+    def setUp(self):
+        sink._chat_messages.clear()
+        sink._chat_suppressed.clear()
+
+    def test_formats_a_slack_message(self, *_):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(200)
+        ) as post:
+            sink.send_alert(DYNATRACE_ALERT, chat_sink("slack"))
+
+        message = post.call_args.kwargs["json"]
+        self.assertIn("/run/secrets/koney/service_token", message["text"])
+        fields = [field["text"] for field in message["blocks"][1]["fields"]]
+        self.assertIn("*Pod*\n`koney-demo/nginx-1 (nginx)`", fields)
+        self.assertIn("*Command line*\n`/usr/bin/cat`", fields)
+
+    def test_formats_a_teams_message(self, *_):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(202)
+        ) as post:
+            sink.send_alert(DYNATRACE_ALERT, chat_sink("teams"))
+
+        card = post.call_args.kwargs["json"]["attachments"][0]["content"]
+        facts = {fact["title"]: fact["value"] for fact in card["body"][1]["facts"]}
+        self.assertEqual(facts["Trap type"], "filesystem_honeytoken")
+        self.assertEqual(facts["Process"], "/usr/bin/cat (pid 1)")
+
+    def test_rate_limits_messages(self, _, monotonic):
+        with mock.patch.object(
+            sink.requests, "post", return_value=response(200)
+        ) as post:
+            for _ in range(5):
+                sink.send_alert(DYNATRACE_ALERT, chat_sink("slack"))
+            self.assertEqual(post.call_count, 2)
+
+            # once the minute passed, the next message reports the suppressed alerts
+            monotonic.return_value = 1060.0
+            sink.send_alert(DYNATRACE_ALERT, chat_sink("slack"))
+
+        self.assertEqual(post.call_count, 3)
+        note = post.call_args.kwargs["json"]["blocks"][-1]["elements"][0]["text"]
+        self.assertTrue(note.startswith("3 more alert(s)"))
+
+    def test_does_not_count_failed_messages(self, *_):
+        with mock.patch.object(sink.requests, "post", return_value=response(500)):
+            with self.assertRaises(RuntimeError):
+                sink.send_alert(DYNATRACE_ALERT, chat_sink("slack"))
+        self.assertEqual(len(sink._chat_messages["chat-slack"]), 0)
+
+
+@mock.patch.object(sink, "_get_cluster_uid", return_value=None)
+class SendAlertToSyslogTest(unittest.TestCase):
+    def test_sends_cef_over_udp(self, _):
+        with socket.socket(socket.AF_INET, socket.SOCK_DGRAM) as server:
+            server.bind(("127.0.0.1", 0))
+            server.settimeout(5)
+
+            syslog_sink = AlertSink(
+                name="syslog",
+                match=None,
+                redelivery=REDELIVERY,
+                dynatrace_sink=None,
+                webhook_sink=None,
+                chat_sink=None,
+                syslog_sink=SyslogSink(
+                    host="127.0.0.1",
+                    port=server.getsockname()[1],
+                    protocol="udp",
+                    format="cef",
+                    facility="local4",
+                    ca_cert=None,
+                ),
+            )
+            sink.send_alert(DYNATRACE_ALERT, syslog_sink)
+
+            message = server.recv(65535).decode("utf-8")
+        self.assertTrue(message.startswith("<164>"))  # local4 (20) * 8 + warning (4)
+        self.assertIn(" koney: CEF:0|Dynatrace Research|Koney|", message)
+
+    def test_applies_the_default_port_of_the_protocol(self, _):
+        obj = {"spec": {"syslog": {"host": "siem.example.com", "protocol": "tls"}}}
+        syslog_sink = sink._extract_syslog_sink(obj)
+        self.assertEqual(syslog_sink["port"], 6514)
+        self.assertEqual(syslog_sink["format"], "cef")
+        self.assertEqual(syslog_sink["facility"], "local0")
+
+
+class ExtractWebhookSinkTest(unittest.TestCase):
+    def test_applies_the_default_retry_policy(self):
+        obj = {"spec": {"webhook": {"url": "https://alerts.example.com"}}}
+        webhook = sink._extract_webhook_sink(obj)
+        self.assertEqual(
+            webhook["retry"],
+            {"max_retries": 3, "initial_backoff": 1.0, "max_backoff": 30.0},
+        )
+
+    def test_authenticates_with_the_secret(self):
+        obj = {
+            "spec": {
+                "webhook": {"url": "https://alerts.example.com", "secretName": "s"}
+            }
+        }
+
+        secret = {"token": "t0k3n"}
+        with mock.patch.object(sink, "_get_decoded_secret_data", return_value=secret):
+            webhook = sink._extract_webhook_sink(obj)
+        self.assertEqual(webhook["headers"]["Authorization"], "Bearer t0k3n")
+
+        secret = {"username": "koney", "password": "hunter2"}
+        with mock.patch.object(sink, "_get_decoded_secret_data", return_value=secret):
+            webhook = sink._extract_webhook_sink(obj)
+        credentials = base64.b64encode(b"koney:hunter2").decode()
+        self.assertEqual(webhook["headers"]["Authorization"], f"Basic {credentials}")
+
+    def test_skips_webhooks_with_missing_secrets(self):
+        obj = {
+            "spec": {
+                "webhook": {"url": "https://alerts.example.com", "secretName": "s"}
+            }
+        }
+        with mock.patch.object(sink, "_get_decoded_secret_data", return_value=None):
+            self.assertIsNone(sink._extract_webhook_sink(obj))
+
+
+class AlertMatchesSinkTest(unittest.TestCase):
+    def matches(self, match: dict | None, **alert) -> bool:
+        if match is not None:
+            match = {
+                "deception_policy_names": [],
+                "trap_types": [],
+                "namespaces": [],
+                "severities": [],
+                **match,
+            }
+        alert_sink = {**webhook_sink(), "match": match}
+        return sink.alert_matches_sink({**DYNATRACE_ALERT, **alert}, alert_sink)
+
+    def test_matches_all_alerts_without_match(self):
+        self.assertTrue(self.matches(None))
+        self.assertTrue(self.matches({}))
+
+    def test_matches_namespaces_with_globs(self):
+        match = {"namespaces": ["prod-*", "payments"]}
+        self.assertTrue(self.matches(match, pod={"namespace": "prod-eu"}))
+        self.assertTrue(self.matches(match, pod={"namespace": "payments"}))
+        self.assertFalse(self.matches(match, pod={"namespace": "staging"}))
+        self.assertFalse(self.matches(match, pod=None))
+
+    def test_matches_all_fields(self):
+        match = {
+            "deception_policy_names": ["dp"],
+            "trap_types": ["filesystem_honeytoken"],
+            "severities": ["HIGH", "CRITICAL"],
+        }
+        self.assertTrue(self.matches(match, severity="HIGH"))
+        self.assertFalse(self.matches(match, severity="LOW"))
+        self.assertFalse(self.matches(match, severity=None))
+        self.assertFalse(
+            self.matches(match, severity="HIGH", trap_type="http_endpoint")
+        )
+        self.assertFalse(
+            self.matches(match, severity="HIGH", deception_policy_name="other")
+        )
+
+
+class ExtractAlertSinkMatchTest(unittest.TestCase):
+    def test_defaults_to_empty_lists(self):
+        obj = {"spec": {"match": {"namespaces": ["prod-*"]}}}
+        self.assertEqual(
+            sink._extract_alert_sink_match(obj),
+            {
+                "deception_policy_names": [],
+                "trap_types": [],
+                "namespaces": ["prod-*"],
+                "severities": [],
+            },
+        )
+        self.assertIsNone(sink._extract_alert_sink_match({"spec": {}}))
+
+
+if __name__ == "__main__":
+    unittest.main()
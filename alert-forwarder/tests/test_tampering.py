@@ -0,0 +1,121 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import asyncio
+import json
+import unittest
+from unittest import mock
+
+from forwarder import main
+from forwarder.alerts import create_alert_description
+from forwarder.sink import alert_matches_sink
+from forwarder.tampering import TAMPERING_METADATA, process_tampering_alert
+
+
+def tampering(kind: str = "Deployment", name: str = "web", **overrides) -> dict:
+    payload = {
+        "time": "2025-01-03T18:47:56Z",
+        "reason": "ContentTampered",
+        "kind": kind,
+        "namespace": "payments",
+        "name": name,
+        "container": "nginx",
+        "metadata": {
+            "koney-deception-policy-name": "deceptionpolicy-sample",
+            "koney-trap-type": "filesystem_honeytoken",
+            "koney-file-path": "/run/secrets/koney/token",
+            "koney-severity": "high",
+            "koney-trap-hash": "abc123",
+            "koney-response-actions": '["killPod"]',
+        },
+    }
+    payload.update(overrides)
+    return payload
+
+
+class ProcessTamperingAlertTest(unittest.TestCase):
+    def test_maps_tampered_decoys_of_workloads(self):
+        alert = process_tampering_alert(tampering())
+
+        self.assertEqual(alert["deception_policy_name"], "deceptionpolicy-sample")
+        self.assertEqual(alert["trap_type"], "filesystem_honeytoken")
+        self.assertEqual(alert["severity"], "HIGH")
+        self.assertEqual(alert["trap"]["hash"], "abc123")
+        self.assertEqual(alert["metadata"][TAMPERING_METADATA], "ContentTampered")
+        self.assertIsNone(alert["pod"]["name"])
+        self.assertEqual(alert["pod"]["namespace"], "payments")
+        self.assertEqual(alert["pod"]["container"]["name"], "nginx")
+        self.assertEqual(alert["pod"]["owner"], {"kind": "Deployment", "name": "web"})
+        self.assertEqual(
+            create_alert_description(alert),
+            "Honeytoken (/run/secrets/koney/token) in deployment (payments/web) "
+            "was tampered with (ContentTampered)",
+        )
+
+    def test_maps_tampered_decoys_of_pods(self):
+        alert = process_tampering_alert(tampering(kind="Pod", name="app"))
+
+        self.assertEqual(alert["pod"]["name"], "app")
+        self.assertNotIn("owner", alert["pod"])
+
+    def test_does_not_trigger_response_actions(self):
+        self.assertIsNone(process_tampering_alert(tampering())["response"])
+
+    def test_is_routed_like_accesses_of_the_trap(self):
+        alert = process_tampering_alert(tampering())
+        sink = {
+            "match": {
+                "deception_policy_names": ["deceptionpolicy-*"],
+                "trap_types": ["filesystem_honeytoken"],
+                "namespaces": ["payments"],
+                "severities": [],
+            }
+        }
+
+        self.assertTrue(alert_matches_sink(alert, sink))
+
+
+@mock.patch.object(main, "authenticate_kubernetes", return_value=True)
+class HandleTamperingTest(unittest.TestCase):
+    def handle(self, forwarded: bool = True) -> tuple[mock.Mock, mock.Mock]:
+        request = mock.Mock()
+        request.body = mock.AsyncMock(return_value=json.dumps(tampering()).encode())
+        response = mock.Mock()
+        with (
+            mock.patch.object(main, "is_authorized_request", return_value=True),
+            mock.patch.object(main, "enrich_alert"),
+            mock.patch.object(main, "try_read_alert_sinks", return_value=[]),
+            mock.patch.object(
+                main, "forward_alert", return_value=forwarded
+            ) as forward_alert,
+        ):
+            asyncio.run(main.handle_tampering(response, request))
+        return forward_alert, response
+
+    def test_forwards_tampered_decoys(self, *_):
+        forward_alert, _ = self.handle()
+
+        forward_alert.assert_called_once()
+        alert = forward_alert.call_args.args[0]
+        self.assertEqual(alert["metadata"][TAMPERING_METADATA], "ContentTampered")
+
+    def test_fails_if_the_alert_cannot_be_queued(self, *_):
+        _, response = self.handle(forwarded=False)
+
+        self.assertEqual(response.status_code, 503)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,143 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import unittest
+from unittest import mock
+
+from kubernetes.client.exceptions import ApiException
+
+from forwarder import tetragon
+from forwarder.informer import CustomObjectInformer
+from forwarder.tetragon import TracingPolicyMatcher
+
+LABELS = {tetragon.TETRAGON_DECEPTION_POLICY_REF: "dp"}
+
+
+class FakeCustomObjectsApi:
+    def __init__(self, items: dict[str, list[dict]], error: Exception | None = None):
+        self.items = items
+        self.error = error
+        self.label_selectors = []
+
+    def list_cluster_custom_object(self, group, version, plural, label_selector=None):
+        self.label_selectors.append(label_selector)
+        if self.error:
+            raise self.error
+        return {"items": self.items.get(plural, [])}
+
+
+def unsynced_informers():
+    return (
+        mock.patch.object(
+            tetragon,
+            "tracing_policy_informer",
+            CustomObjectInformer(*tetragon.TETRAGON_TRACING_POLICIES_GVP),
+        ),
+        mock.patch.object(
+            tetragon,
+            "tracing_policy_namespaced_informer",
+            CustomObjectInformer(
+                *tetragon.TETRAGON_TRACING_POLICIES_NAMESPACED_GVP, namespaced=True
+            ),
+        ),
+    )
+
+
+class TracingPolicyMatcherTest(unittest.TestCase):
+    def setUp(self):
+        self.matcher = TracingPolicyMatcher({"custom-trap"}, ["koney-tracing-policy-"])
+
+    def test_matches_policies_by_name_or_prefix(self):
+        self.assertTrue(self.matcher.matches("custom-trap"))
+        self.assertTrue(self.matcher.matches("koney-tracing-policy-0b4a1bd5"))
+        self.assertFalse(self.matcher.matches("custom-trap-2"))
+        self.assertFalse(self.matcher.matches("other-koney-tracing-policy-0b4a1bd5"))
+        self.assertFalse(self.matcher.matches("koney-follow-0b4a1bd5"))
+
+    def test_prefilters_lines_that_could_match(self):
+        self.assertTrue(self.matcher.could_match('{"policy_name":"custom-trap"}'))
+        self.assertTrue(
+            self.matcher.could_match('{"policy_name":"koney-tracing-policy-0b4a"}')
+        )
+        self.assertFalse(self.matcher.could_match('{"policy_name":"other"}'))
+
+    def test_matches_nothing_without_names_and_prefixes(self):
+        matcher = TracingPolicyMatcher(set(), [])
+        self.assertFalse(matcher.could_match('{"policy_name":"koney-tracing-policy"}'))
+        self.assertFalse(matcher.matches("koney-tracing-policy-0"))
+
+
+class ListKoneyTracingPolicyNamesTest(unittest.TestCase):
+    def test_lists_the_labeled_tracing_policies_until_the_informers_synced(self):
+        api = FakeCustomObjectsApi(
+            {
+                "tracingpolicies": [
+                    {"metadata": {"name": "koney-a", "labels": LABELS}}
+                ],
+                # namespaced tracing policies have the same name in all namespaces
+                "tracingpoliciesnamespaced": [
+                    {"metadata": {"namespace": ns, "name": "koney-b", "labels": LABELS}}
+                    for ns in ("a", "b")
+                ],
+            }
+        )
+        informer, namespaced_informer = unsynced_informers()
+        with (
+            informer,
+            namespaced_informer,
+            mock.patch.object(
+                tetragon.client, "CustomObjectsApi", return_value=api, create=True
+            ),
+        ):
+            self.assertEqual(
+                tetragon.list_koney_tracing_policy_names(), {"koney-a", "koney-b"}
+            )
+        # only the tracing policies of Koney are listed
+        self.assertEqual(
+            api.label_selectors, [tetragon.TETRAGON_DECEPTION_POLICY_REF] * 2
+        )
+
+    def test_matches_by_prefix_only_if_the_tracing_policies_cannot_be_listed(self):
+        forbidden = ApiException()
+        forbidden.status = 403
+        api = FakeCustomObjectsApi({}, error=forbidden)
+        informer, namespaced_informer = unsynced_informers()
+        with (
+            informer,
+            namespaced_informer,
+            mock.patch.object(
+                tetragon.client, "CustomObjectsApi", return_value=api, create=True
+            ),
+            self.assertLogs(tetragon.logger, level="WARNING"),
+        ):
+            self.assertEqual(tetragon.list_koney_tracing_policy_names(), set())
+
+
+class BuildTracingPolicyMatcherTest(unittest.TestCase):
+    @mock.patch.object(tetragon, "TETRAGON_EXTRA_POLICY_PREFIXES", ["custom-"])
+    @mock.patch.object(
+        tetragon, "list_koney_tracing_policy_names", return_value={"renamed-trap"}
+    )
+    def test_matches_listed_names_and_all_prefixes(self, _):
+        matcher = tetragon.build_tracing_policy_matcher()
+
+        self.assertTrue(matcher.matches("renamed-trap"))
+        self.assertTrue(matcher.matches(tetragon.TETRAGON_POLICY_PREFIX + "0b4a"))
+        self.assertTrue(matcher.matches("custom-0b4a"))
+        self.assertFalse(matcher.matches("other"))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,194 @@
+# Copyright (c) 2025 Dynatrace LLC
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as published by
+# the Free Software Foundation, either version 3 of the License, or
+# (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+import json
+import threading
+import unittest
+from unittest import mock
+
+from forwarder import main, tetragon, tetragon_grpc
+from forwarder.tetragon import TracingPolicyMatcher
+
+POLICY_NAME = "koney-tracing-policy-0b4a1bd5ebfa3b1d1b4c2da4c8ba4ea4"
+
+
+def tetragon_event(time: str, arguments: str, policy_name: str = POLICY_NAME) -> str:
+    return json.dumps(
+        {
+            "process_kprobe": {
+                "process": {"binary": "/usr/bin/cat", "arguments": arguments},
+                "policy_name": policy_name,
+            },
+            "node_name": "minikube",
+            "time": time,
+        },
+        separators=(",", ":"),
+    )
+
+
+class FakeEventStream:
+    def __init__(self, lines: list[str]):
+        self.lines = lines
+        self.cancelled = threading.Event()
+
+    def __iter__(self):
+        yield from self.lines
+        # like a gRPC stream, block until the stream is cancelled
+        self.cancelled.wait()
+        raise RuntimeError("cancelled")
+
+    def cancel(self):
+        self.cancelled.set()
+
+
+class TetragonEventStreamTest(unittest.TestCase):
+    def setUp(self):
+        tetragon.event_cache.clear()
+        self.matcher = TracingPolicyMatcher({POLICY_NAME}, [])
+
+    def test_handles_streamed_events_once(self):
+        lines = [
+            tetragon_event("2025-01-03T18:47:56.000000001Z", "token"),
+            tetragon_event("2025-01-03T18:47:56.000000002Z", "token"),
+            tetragon_event("2025-01-03T18:47:57.000000001Z", "x", "other"),
+        ]
+        fake_stream = FakeEventStream(lines)
+        handled = threading.Event()
+        handle_event = mock.Mock(side_effect=lambda *_: handled.set())
+
+        with mock.patch.object(
+            tetragon_grpc, "open_event_stream", return_value=fake_stream
+        ) as open_event_stream:
+            stream = tetragon_grpc.TetragonEventStream(
+                "tetragon-a", "10.0.0.1:54321", handle_event
+            )
+            stream.start(self.matcher)
+            self.assertTrue(handled.wait(timeout=5))
+            stream.stop()
+
+        open_event_stream.assert_called_once_with("10.0.0.1:54321", [POLICY_NAME])
+        handle_event.assert_called_once()
+        pod_name, policy_name, event, _ = handle_event.call_args.args
+        self.assertEqual(pod_name, "tetragon-a")
+        self.assertEqual(policy_name, POLICY_NAME)
+        self.assertEqual(event["time"], "2025-01-03T18:47:56Z")
+
+
+@mock.patch.object(tetragon_grpc, "TetragonEventStream")
+@mock.patch.object(tetragon_grpc, "build_tracing_policy_matcher")
+@mock.patch.object(tetragon_grpc, "list_tetragon_pod_addresses")
+class TetragonEventStreamsTest(unittest.TestCase):
+    def test_follows_pods_and_tracing_policies(
+        self, list_tetragon_pod_addresses, build_matcher, event_stream
+    ):
+        streams = tetragon_grpc.TetragonEventStreams(mock.Mock())
+        list_tetragon_pod_addresses.return_value = {"tetragon-a": "10.0.0.1:54321"}
+        build_matcher.return_value = TracingPolicyMatcher({"a"}, [])
+        event_stream.return_value.address = "10.0.0.1:54321"
+
+        self.assertTrue(streams.resync())
+        event_stream.return_value.start.assert_called_once()
+
+        # nothing changed
+        self.assertFalse(streams.resync())
+
+        # a tracing policy was added
+        build_matcher.return_value = TracingPolicyMatcher({"a", "b"}, [])
+        self.assertTrue(streams.resync())
+        event_stream.return_value.resubscribe.assert_called_once()
+
+        # the pod was deleted
+        list_tetragon_pod_addresses.return_value = {}
+        self.assertFalse(streams.resync())
+        event_stream.return_value.stop.assert_called_once()
+        self.assertEqual(streams.streams, {})
+
+    def test_reports_whether_any_stream_is_connected(
+        self, list_tetragon_pod_addresses, build_matcher, event_stream
+    ):
+        streams = tetragon_grpc.TetragonEventStreams(mock.Mock())
+        self.assertIsNotNone(streams.check_health())  # not listed yet
+
+        list_tetragon_pod_addresses.return_value = {"tetragon-a": "10.0.0.1:54321"}
+        build_matcher.return_value = TracingPolicyMatcher({"a"}, [])
+        event_stream.return_value.connected = False
+        streams.resync()
+        self.assertIsNotNone(streams.check_health())
+
+        event_stream.return_value.connected = True
+        self.assertIsNone(streams.check_health())
+
+    def test_stops_all_streams(
+        self, list_tetragon_pod_addresses, build_matcher, event_stream
+    ):
+        streams = tetragon_grpc.TetragonEventStreams(mock.Mock())
+        list_tetragon_pod_addresses.return_value = {"tetragon-a": "10.0.0.1:54321"}
+        build_matcher.return_value = TracingPolicyMatcher({"a"}, [])
+        streams.resync()
+
+        streams.stop()
+        event_stream.return_value.stop.assert_called_once()
+        event_stream.return_value.join.assert_called_once()
+        self.assertEqual(streams.streams, {})
+
+        # stopped streams are not started again, and run_forever returns right away
+        self.assertFalse(streams.resync())
+        streams.run_forever()
+        event_stream.return_value.start.assert_called_once()
+
+
+class BuildPolicyFilterTest(unittest.TestCase):
+    def test_filters_by_names_unless_prefixes_are_configured(self):
+        matcher = TracingPolicyMatcher({"b", "a"}, [tetragon.TETRAGON_POLICY_PREFIX])
+        self.assertEqual(tetragon_grpc.build_policy_filter(matcher), ["a", "b"])
+
+        with mock.patch.object(
+            tetragon_grpc, "TETRAGON_EXTRA_POLICY_PREFIXES", ["custom-"]
+        ):
+            self.assertEqual(tetragon_grpc.build_policy_filter(matcher), [])
+
+
+@mock.patch.object(main, "try_read_alert_sinks", return_value=[])
+@mock.patch.object(main, "resolve_alerting", return_value=None)
+@mock.patch.object(main, "resolve_container_selectors", return_value=None)
+@mock.patch.object(main, "save_watermarks")
+@mock.patch.object(main, "load_watermarks", return_value={})
+class HandleStreamedTetragonEventTest(unittest.TestCase):
+    def test_forwards_the_alert_and_advances_the_watermark(
+        self, load_watermarks, save_watermarks, *_
+    ):
+        event = {"time": "2025-01-03T18:47:56Z"}
+        with mock.patch.object(
+            main, "process_tetragon_event", return_value=True
+        ) as process_tetragon_event:
+            main.handle_streamed_tetragon_event("tetragon-a", POLICY_NAME, event, "h")
+
+        process_tetragon_event.assert_called_once_with(event, None, None, [])
+        save_watermarks.assert_called_once_with(
+            {"tetragon-a": {"time": "2025-01-03T18:47:56Z", "hashes": ["h"]}}
+        )
+
+    def test_does_not_advance_the_watermark_if_not_forwarded(
+        self, load_watermarks, save_watermarks, *_
+    ):
+        event = {"time": "2025-01-03T18:47:56Z"}
+        with mock.patch.object(main, "process_tetragon_event", return_value=False):
+            main.handle_streamed_tetragon_event("tetragon-a", POLICY_NAME, event, "h")
+
+        save_watermarks.assert_not_called()
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -53,6 +53,10 @@ type TrapAnnotation struct {
 	// HttpPayload is the configuration for an HTTP payload trap.
 	// +optional
 	HttpPayload HttpPayloadAnnotation `json:"httpPayload"`
+
+	// NodeScopeLabel is the key of the label that was placed on the resource because it matched the node constraints of the trap.
+	// +optional
+	NodeScopeLabel string `json:"nodeScopeLabel,omitempty"`
 }
 
 // FilesystemHoneytokenAnnotation represents a concrete deployment of a filesystem honeytoken trap.
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterDeceptionPolicyPrefix is the prefix of the name of the DeceptionPolicy that a ClusterDeceptionPolicy is rolled out with.
+const ClusterDeceptionPolicyPrefix = "koney-cluster-"
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterDeceptionPolicy is the Schema for the clusterdeceptionpolicies API.
+// Its traps are applied to all namespaces that match its namespace selector, unless a DeceptionPolicy overrides it there.
+type ClusterDeceptionPolicy struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Spec is the specification of the ClusterDeceptionPolicy.
+	Spec ClusterDeceptionPolicySpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+
+	// Status is the status of the ClusterDeceptionPolicy.
+	Status ClusterDeceptionPolicyStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterDeceptionPolicyList contains a list of ClusterDeceptionPolicy
+type ClusterDeceptionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterDeceptionPolicy `json:"items"`
+}
+
+// ClusterDeceptionPolicySpec defines the desired state of ClusterDeceptionPolicy
+type ClusterDeceptionPolicySpec struct {
+	// NamespaceSelector selects the namespaces that the traps are applied to, by their labels.
+	// If not set, the traps are applied to all namespaces (except the namespace of Koney itself).
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+
+	// Traps is a list of traps to be deployed in the selected namespaces.
+	// The namespaces of their resource filters are narrowed down to the selected namespaces.
+	Traps []Trap `json:"traps,omitempty" yaml:"traps,omitempty"`
+
+	// TrapDefaults are defaults that cascade to all traps, like the TrapDefaults of a DeceptionPolicy.
+	// +optional
+	TrapDefaults *TrapDefaults `json:"trapDefaults,omitempty" yaml:"trapDefaults,omitempty"`
+
+	// StrictValidation is a flag that indicates whether the policy should be strictly validated.
+	// By default, it is set to true.
+	// +optional
+	// +kubebuilder:default:=true
+	StrictValidation *bool `json:"strictValidation,omitempty" yaml:"strictValidation,omitempty"`
+
+	// MutateExisting is a flag to also allow adding traps to existing resources.
+	// +optional
+	// +kubebuilder:default=true
+	MutateExisting *bool `json:"mutateExisting,omitempty" yaml:"mutateExisting,omitempty"`
+
+	// MaxAlertsPerHour is the maximum number of alerts that are forwarded for this policy within one hour.
+	// If not set, alerts are not limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAlertsPerHour *int32 `json:"maxAlertsPerHour,omitempty" yaml:"maxAlertsPerHour,omitempty"`
+
+	// AlertAggregationWindow is the time window in which near-identical alerts are aggregated into one.
+	// If not set, every alert is forwarded on its own.
+	// +optional
+	AlertAggregationWindow *metav1.Duration `json:"alertAggregationWindow,omitempty" yaml:"alertAggregationWindow,omitempty"`
+
+	// DryRun is a flag to only compute which resources, containers, and files the traps would be placed in.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+
+	// SelfTest runs periodic self-tests of the detection chain of the DeceptionPolicy that this ClusterDeceptionPolicy is rolled out with.
+	// If not set, it is not self-tested periodically.
+	// +optional
+	SelfTest *SelfTest `json:"selfTest,omitempty" yaml:"selfTest,omitempty"`
+}
+
+// ClusterDeceptionPolicyStatus defines the observed state of ClusterDeceptionPolicy
+type ClusterDeceptionPolicyStatus struct {
+	// ObservedGeneration is the generation of the ClusterDeceptionPolicy that was last rolled out.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" yaml:"observedGeneration,omitempty"`
+
+	// DeceptionPolicyName is the name of the DeceptionPolicy that deploys the traps.
+	// Its status reports the conditions and the status of each trap.
+	// +optional
+	DeceptionPolicyName string `json:"deceptionPolicyName,omitempty" yaml:"deceptionPolicyName,omitempty"`
+
+	// Namespaces are the namespaces that the traps are applied to.
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// OverriddenNamespaces are the selected namespaces in which a DeceptionPolicy overrides the ClusterDeceptionPolicy.
+	// +optional
+	// +listType=set
+	OverriddenNamespaces []string `json:"overriddenNamespaces,omitempty" yaml:"overriddenNamespaces,omitempty"`
+}
+
+// DeceptionPolicyName returns the name of the DeceptionPolicy that the ClusterDeceptionPolicy is rolled out with.
+func (cdp *ClusterDeceptionPolicy) DeceptionPolicyName() string {
+	return ClusterDeceptionPolicyPrefix + cdp.Name
+}
+
+// OverriddenNamespaces returns the namespaces in which the DeceptionPolicy overrides the ClusterDeceptionPolicy with the given name.
+func (dp *DeceptionPolicy) OverriddenNamespaces(clusterDeceptionPolicyName string) []string {
+	namespaces := []string{}
+	for _, override := range dp.Spec.ClusterPolicyOverrides {
+		if override.Name == "" || override.Name == clusterDeceptionPolicyName {
+			namespaces = append(namespaces, override.Namespaces...)
+		}
+	}
+	return namespaces
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterDeceptionPolicy{}, &ClusterDeceptionPolicyList{})
+}
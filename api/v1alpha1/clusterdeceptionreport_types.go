@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultReportRefreshInterval is how often the coverage of a ClusterDeceptionReport is computed, if not set.
+const DefaultReportRefreshInterval = 5 * time.Minute
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Workloads",type=integer,JSONPath=`.status.summary.workloads`
+// +kubebuilder:printcolumn:name="Protected",type=integer,JSONPath=`.status.summary.protectedWorkloads`
+// +kubebuilder:printcolumn:name="Verified",type=integer,JSONPath=`.status.summary.verifiedWorkloads`
+// +kubebuilder:printcolumn:name="Generated",type=date,JSONPath=`.status.generatedAt`
+
+// ClusterDeceptionReport is the Schema for the clusterdeceptionreports API.
+// Koney reports in its status which workloads of the selected namespaces are protected by traps, and whether these traps were verified.
+type ClusterDeceptionReport struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Spec is the specification of the ClusterDeceptionReport.
+	Spec ClusterDeceptionReportSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+
+	// Status is the deception coverage that Koney computed for the ClusterDeceptionReport.
+	Status ClusterDeceptionReportStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterDeceptionReportList contains a list of ClusterDeceptionReport
+type ClusterDeceptionReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterDeceptionReport `json:"items"`
+}
+
+// ClusterDeceptionReportSpec defines which namespaces are reported and how often
+type ClusterDeceptionReportSpec struct {
+	// NamespaceSelector selects the namespaces that are reported, by their labels.
+	// If not set, all namespaces are reported (except the namespace of Koney itself).
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+
+	// RefreshInterval is how often the coverage is computed. By default, it is computed every 5 minutes.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+}
+
+// ClusterDeceptionReportStatus defines the deception coverage of the selected namespaces
+type ClusterDeceptionReportStatus struct {
+	// GeneratedAt is the time when the coverage was last computed.
+	// +optional
+	GeneratedAt *metav1.Time `json:"generatedAt,omitempty" yaml:"generatedAt,omitempty"`
+
+	// Summary sums up the coverage of all reported namespaces.
+	// +optional
+	Summary CoverageSummary `json:"summary,omitempty" yaml:"summary,omitempty"`
+
+	// Namespaces reports the coverage of each namespace, sorted by name.
+	// +optional
+	Namespaces []NamespaceCoverage `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+}
+
+// CoverageSummary counts the workloads and how many of them are protected by traps
+type CoverageSummary struct {
+	// Namespaces is the number of reported namespaces.
+	Namespaces int `json:"namespaces" yaml:"namespaces"`
+
+	// Workloads is the number of workloads (e.g., deployments, or pods without a controller).
+	Workloads int `json:"workloads" yaml:"workloads"`
+
+	// ProtectedWorkloads is the number of workloads with at least one trap placed in them.
+	ProtectedWorkloads int `json:"protectedWorkloads" yaml:"protectedWorkloads"`
+
+	// VerifiedWorkloads is the number of protected workloads whose traps were all verified to be in place.
+	VerifiedWorkloads int `json:"verifiedWorkloads" yaml:"verifiedWorkloads"`
+}
+
+// NamespaceCoverage reports the coverage of the workloads in a namespace
+type NamespaceCoverage struct {
+	// Name is the name of the namespace.
+	Name string `json:"name" yaml:"name"`
+
+	// Summary counts the workloads of the namespace (the Namespaces field is always 1).
+	Summary CoverageSummary `json:"summary" yaml:"summary"`
+
+	// Workloads are the workloads in the namespace, sorted by kind and name.
+	// +optional
+	Workloads []WorkloadCoverage `json:"workloads,omitempty" yaml:"workloads,omitempty"`
+}
+
+// WorkloadCoverage reports which traps are placed in a workload
+type WorkloadCoverage struct {
+	// Kind is the kind of the workload (e.g., Deployment, StatefulSet, or Pod for pods without a controller).
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Name is the name of the workload.
+	Name string `json:"name" yaml:"name"`
+
+	// Verified is true if the workload is protected and all its traps were verified to be in place.
+	// +optional
+	Verified bool `json:"verified,omitempty" yaml:"verified,omitempty"`
+
+	// Traps are the traps that are placed in the workload or its pods. The workload is unprotected if there are none.
+	// +optional
+	Traps []WorkloadTrap `json:"traps,omitempty" yaml:"traps,omitempty"`
+}
+
+// WorkloadTrap is a trap that is placed in a workload
+type WorkloadTrap struct {
+	// DeceptionPolicyName is the name of the DeceptionPolicy of the trap.
+	DeceptionPolicyName string `json:"deceptionPolicyName" yaml:"deceptionPolicyName"`
+
+	// Index is the position of the trap in the traps of the DeceptionPolicy.
+	Index int `json:"index" yaml:"index"`
+
+	// TrapType is the type of the trap.
+	TrapType TrapType `json:"trapType" yaml:"trapType"`
+
+	// TrapHash is the hash of the spec of the trap.
+	TrapHash string `json:"trapHash" yaml:"trapHash"`
+
+	// Containers are the containers in which decoys of the trap are placed.
+	// +optional
+	Containers []string `json:"containers,omitempty" yaml:"containers,omitempty"`
+
+	// Verified is true if the latest verification of the DeceptionPolicy found the decoys of the trap in place in the workload.
+	// +optional
+	Verified bool `json:"verified,omitempty" yaml:"verified,omitempty"`
+}
+
+// GetRefreshInterval returns how often the coverage is computed, or DefaultReportRefreshInterval if it is not set.
+func (spec *ClusterDeceptionReportSpec) GetRefreshInterval() time.Duration {
+	if spec.RefreshInterval == nil || spec.RefreshInterval.Duration <= 0 {
+		return DefaultReportRefreshInterval
+	}
+	return spec.RefreshInterval.Duration
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterDeceptionReport{}, &ClusterDeceptionReportList{})
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Policy",type=string,JSONPath=`.spec.deceptionPolicyName`
+// +kubebuilder:printcolumn:name="Trap Type",type=string,JSONPath=`.spec.trapType`
+// +kubebuilder:printcolumn:name="Severity",type=string,JSONPath=`.spec.severity`
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.pod.namespace`,priority=1
+// +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.pod.name`
+// +kubebuilder:printcolumn:name="Binary",type=string,JSONPath=`.spec.process.binary`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DeceptionAlert is the Schema for the deceptionalerts API.
+// It records an alert that was raised when a trap was accessed, so that alerts can be queried in the cluster
+// and other controllers can react to them. The alert forwarder creates DeceptionAlerts in the namespace of Koney,
+// and the controller deletes them once their retention period passed.
+type DeceptionAlert struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the recorded alert.
+	Spec DeceptionAlertSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DeceptionAlertList contains a list of DeceptionAlert
+type DeceptionAlertList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeceptionAlert `json:"items"`
+}
+
+// DeceptionAlertSpec holds the most important fields of an alert, and the full alert in the format of the alerts package.
+type DeceptionAlertSpec struct {
+	// Timestamp is the time when the trap was accessed.
+	Timestamp metav1.Time `json:"timestamp" yaml:"timestamp"`
+
+	// DeceptionPolicyName is the name of the deception policy that created the trap, if it could be resolved.
+	// +optional
+	DeceptionPolicyName string `json:"deceptionPolicyName,omitempty" yaml:"deceptionPolicyName,omitempty"`
+
+	// TrapType is the type of the trap that was accessed.
+	// +kubebuilder:validation:Enum=filesystem_honeytoken;http_endpoint;http_payload;unknown
+	TrapType string `json:"trapType" yaml:"trapType"`
+
+	// TrapHash is the hash of the accessed trap, as stored in its captor.
+	// +optional
+	TrapHash string `json:"trapHash,omitempty" yaml:"trapHash,omitempty"`
+
+	// Severity is the severity of the alert, if any.
+	// +kubebuilder:validation:Enum=CRITICAL;HIGH;MEDIUM;LOW;INFO
+	// +optional
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
+	// Pod is the pod in which the trap was accessed, if known.
+	// +optional
+	Pod *DeceptionAlertPod `json:"pod,omitempty" yaml:"pod,omitempty"`
+
+	// NodeName is the name of the node on which the trap was accessed, if known.
+	// +optional
+	NodeName string `json:"nodeName,omitempty" yaml:"nodeName,omitempty"`
+
+	// Process is the process that accessed the trap, if known.
+	// +optional
+	Process *DeceptionAlertProcess `json:"process,omitempty" yaml:"process,omitempty"`
+
+	// Alert is the full alert, exactly as the alert forwarder emits it (see the alerts package for its format).
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	Alert runtime.RawExtension `json:"alert,omitempty" yaml:"alert,omitempty"`
+}
+
+// DeceptionAlertPod describes the pod in which a trap was accessed.
+type DeceptionAlertPod struct {
+	// Name is the name of the pod.
+	Name string `json:"name" yaml:"name"`
+
+	// Namespace is the namespace of the pod.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// ContainerName is the name of the container in which the trap was accessed, if known.
+	// +optional
+	ContainerName string `json:"containerName,omitempty" yaml:"containerName,omitempty"`
+}
+
+// DeceptionAlertProcess describes the process that accessed a trap.
+type DeceptionAlertProcess struct {
+	// Binary is the path of the binary of the process.
+	// +optional
+	Binary string `json:"binary,omitempty" yaml:"binary,omitempty"`
+
+	// Arguments are the arguments of the process.
+	// +optional
+	Arguments string `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+
+	// PID is the process id.
+	// +optional
+	PID int64 `json:"pid,omitempty" yaml:"pid,omitempty"`
+
+	// UID is the user id of the process.
+	// +optional
+	UID int64 `json:"uid,omitempty" yaml:"uid,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeceptionAlert{}, &DeceptionAlertList{})
+}
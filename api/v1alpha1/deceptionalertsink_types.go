@@ -20,6 +20,11 @@ import (
 )
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Delivered",type=integer,JSONPath=`.status.deliveredAlerts`
+// +kubebuilder:printcolumn:name="Pending",type=integer,JSONPath=`.status.pendingAlerts`
+// +kubebuilder:printcolumn:name="Dead-Lettered",type=integer,JSONPath=`.status.deadLetteredAlerts`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // DeceptionAlertSink is the Schema for the deceptionalertsinks API
 type DeceptionAlertSink struct {
@@ -31,6 +36,9 @@ type DeceptionAlertSink struct {
 
 	// Spec is the specification of the DeceptionAlertSinkSpec.
 	Spec DeceptionAlertSinkSpec `json:"spec,omitempty"`
+
+	// Status reports how alerts were delivered to the sink. It is maintained by the alert forwarder.
+	Status DeceptionAlertSinkStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -44,8 +52,114 @@ type DeceptionAlertSinkList struct {
 
 // DeceptionAlertSinkSpec defines the desired state of DeceptionAlertSink
 type DeceptionAlertSinkSpec struct {
+	// Match restricts the alerts that are sent to this sink. If not set, all alerts are sent.
+	// +optional
+	Match *AlertSinkMatch `json:"match,omitempty" yaml:"match,omitempty"`
+
+	// Redelivery describes how alerts that failed to deliver are queued and delivered again later.
+	// +optional
+	Redelivery *AlertSinkRedeliveryPolicy `json:"redelivery,omitempty" yaml:"redelivery,omitempty"`
+
 	// Dynatrace describes how to send alerts to Dynatrace
 	Dynatrace DynatraceSinkSpec `json:"dynatrace,omitempty" yaml:"dynatrace,omitempty"`
+
+	// Webhook describes how to send alerts to a generic HTTP endpoint
+	// +optional
+	Webhook *WebhookSinkSpec `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+
+	// Chat describes how to post alerts as messages to a Slack or Microsoft Teams channel
+	// +optional
+	Chat *ChatSinkSpec `json:"chat,omitempty" yaml:"chat,omitempty"`
+
+	// Syslog describes how to send alerts in CEF or LEEF format to a syslog server, e.g., of a SIEM
+	// +optional
+	Syslog *SyslogSinkSpec `json:"syslog,omitempty" yaml:"syslog,omitempty"`
+}
+
+// AlertSinkMatch selects alerts by where they come from. An alert matches if it matches all configured fields,
+// and it matches a field if it matches any of its values. Empty fields match all alerts.
+type AlertSinkMatch struct {
+	// DeceptionPolicyNames are the names of the deception policies whose alerts are sent.
+	// Glob patterns like "prod-*" are supported.
+	// +listType=set
+	// +optional
+	DeceptionPolicyNames []string `json:"deceptionPolicyNames,omitempty" yaml:"deceptionPolicyNames,omitempty"`
+
+	// TrapTypes are the types of the traps whose alerts are sent.
+	// +kubebuilder:validation:items:Enum=filesystem_honeytoken;http_endpoint;http_payload;unknown
+	// +listType=set
+	// +optional
+	TrapTypes []string `json:"trapTypes,omitempty" yaml:"trapTypes,omitempty"`
+
+	// Namespaces are the namespaces of the pods whose alerts are sent.
+	// Glob patterns like "prod-*" are supported.
+	// +listType=set
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// Severities are the severities of the alerts that are sent. Alerts without a severity do not match.
+	// +kubebuilder:validation:items:Enum=CRITICAL;HIGH;MEDIUM;LOW;INFO
+	// +listType=set
+	// +optional
+	Severities []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+// AlertSinkRedeliveryPolicy describes how alerts that failed to deliver are redelivered with exponential backoff.
+// The queue of pending alerts survives restarts of the alert forwarder.
+type AlertSinkRedeliveryPolicy struct {
+	// MaxAttempts is how often an alert is delivered in total before it is dead-lettered,
+	// i.e., logged as undeliverable and dropped from the queue.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=10
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+
+	// InitialBackoff is how long to wait before the first redelivery. The backoff doubles with every attempt.
+	// +kubebuilder:default="30s"
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty" yaml:"initialBackoff,omitempty"`
+
+	// MaxBackoff is the longest time to wait between two redeliveries.
+	// +kubebuilder:default="1h"
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+}
+
+// DeceptionAlertSinkStatus reports delivery statistics of a DeceptionAlertSink.
+type DeceptionAlertSinkStatus struct {
+	// DeliveredAlerts is the number of alerts that were delivered to the sink.
+	// +optional
+	DeliveredAlerts int64 `json:"deliveredAlerts,omitempty" yaml:"deliveredAlerts,omitempty"`
+
+	// FailedDeliveries is the number of failed attempts to deliver alerts to the sink, including redeliveries.
+	// +optional
+	FailedDeliveries int64 `json:"failedDeliveries,omitempty" yaml:"failedDeliveries,omitempty"`
+
+	// PendingAlerts is the number of alerts that are queued for redelivery.
+	// +optional
+	PendingAlerts int32 `json:"pendingAlerts,omitempty" yaml:"pendingAlerts,omitempty"`
+
+	// DeadLetteredAlerts is the number of alerts that were dropped after the last redelivery failed.
+	// +optional
+	DeadLetteredAlerts int64 `json:"deadLetteredAlerts,omitempty" yaml:"deadLetteredAlerts,omitempty"`
+
+	// LastDeliveryTime is when an alert was last delivered to the sink.
+	// +optional
+	LastDeliveryTime *metav1.Time `json:"lastDeliveryTime,omitempty" yaml:"lastDeliveryTime,omitempty"`
+
+	// RecentAlertTimestamps are the timestamps of the alerts that were last delivered to the sink (at most 10),
+	// so that the delivery of a specific alert can be confirmed.
+	// +optional
+	RecentAlertTimestamps []string `json:"recentAlertTimestamps,omitempty" yaml:"recentAlertTimestamps,omitempty"`
+
+	// LastFailureTime is when the delivery of an alert to the sink last failed.
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty" yaml:"lastFailureTime,omitempty"`
+
+	// LastError is the error of the last failed delivery.
+	// +optional
+	LastError string `json:"lastError,omitempty" yaml:"lastError,omitempty"`
 }
 
 type DynatraceSinkSpec struct {
@@ -57,6 +171,115 @@ type DynatraceSinkSpec struct {
 	// +optional
 	// +kubebuilder:default="HIGH"
 	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
+	// API is the Dynatrace API that alerts are ingested with.
+	// "securityEvents" ingests security events with OpenPipeline, "events" ingests custom alerts with the Events API v2
+	// (linked to the accessing pod), and "logs" ingests log records with the Log Monitoring API v2.
+	// +kubebuilder:validation:Enum=securityEvents;events;logs
+	// +kubebuilder:default="securityEvents"
+	// +optional
+	API string `json:"api,omitempty" yaml:"api,omitempty"`
+
+	// SecurityContext is stored as `dt.security_context` in all ingested records,
+	// so that access to alerts can be restricted with Dynatrace security policies.
+	// +optional
+	SecurityContext string `json:"securityContext,omitempty" yaml:"securityContext,omitempty"`
+}
+
+// WebhookSinkSpec describes an HTTP endpoint that receives every alert as a JSON-encoded KoneyAlert in a POST request.
+type WebhookSinkSpec struct {
+	// URL is the HTTP or HTTPS endpoint that alerts are posted to.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url" yaml:"url"`
+
+	// Headers are additional HTTP headers that are sent with every request.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// SecretName references the name of a secret to authenticate requests with.
+	// It holds either a `token` that is sent as a bearer token, or a `username` and a `password` for basic authentication.
+	// +optional
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+
+	// Retry describes how often and how fast failed requests are retried.
+	// +optional
+	Retry *WebhookRetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// WebhookRetryPolicy describes how failed requests to a webhook are retried with exponential backoff.
+// Requests are retried on connection errors, on 429 responses, and on 5xx responses.
+type WebhookRetryPolicy struct {
+	// MaxRetries is how often a failed request is retried before the alert is considered undelivered.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default=3
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	// InitialBackoff is how long to wait before the first retry. The backoff doubles with every retry.
+	// +kubebuilder:default="1s"
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty" yaml:"initialBackoff,omitempty"`
+
+	// MaxBackoff is the longest time to wait between two retries.
+	// +kubebuilder:default="30s"
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+}
+
+// ChatSinkSpec describes a Slack or Microsoft Teams channel that receives alerts as readable messages.
+type ChatSinkSpec struct {
+	// Provider is the chat service that messages are formatted for, i.e., "slack" or "teams".
+	// +kubebuilder:validation:Enum=slack;teams
+	Provider string `json:"provider" yaml:"provider"`
+
+	// SecretName references the name of a secret holding the `webhookUrl` of the incoming webhook of the channel.
+	SecretName string `json:"secretName" yaml:"secretName"`
+
+	// MaxMessagesPerMinute limits how many messages are posted per minute, so that noisy attacks do not flood the channel.
+	// Alerts beyond the limit are not posted, but counted in the next message.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	MaxMessagesPerMinute int32 `json:"maxMessagesPerMinute,omitempty" yaml:"maxMessagesPerMinute,omitempty"`
+}
+
+// SyslogSinkSpec describes a syslog server that receives alerts in the Common Event Format (CEF)
+// or the Log Event Extended Format (LEEF), e.g., the syslog receiver of ArcSight or QRadar.
+type SyslogSinkSpec struct {
+	// Host is the hostname or IP address of the syslog server.
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host" yaml:"host"`
+
+	// Port is the port of the syslog server.
+	// If not set, the default port of the protocol is used, i.e., 514 for UDP and TCP, and 6514 for TLS.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+
+	// Protocol is the transport of syslog messages, i.e., "udp", "tcp", or "tls".
+	// +kubebuilder:validation:Enum=udp;tcp;tls
+	// +kubebuilder:default="udp"
+	// +optional
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// Format is the format of the alerts in the syslog messages, i.e., "cef" or "leef".
+	// +kubebuilder:validation:Enum=cef;leef
+	// +kubebuilder:default="cef"
+	// +optional
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Facility is the syslog facility of the messages.
+	// +kubebuilder:validation:Enum=kern;user;mail;daemon;auth;syslog;lpr;news;uucp;cron;authpriv;ftp;local0;local1;local2;local3;local4;local5;local6;local7
+	// +kubebuilder:default="local0"
+	// +optional
+	Facility string `json:"facility,omitempty" yaml:"facility,omitempty"`
+
+	// SecretName references the name of a secret holding the `ca.crt` to verify the syslog server with, if the protocol is "tls".
+	// If not set, the certificate of the server is verified with the system certificates.
+	// +optional
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
 }
 
 func init() {
@@ -16,18 +16,340 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // DeceptionPolicyStatus defines the observed state of DeceptionPolicy
 type DeceptionPolicyStatus struct {
+	// ObservedGeneration is the generation of the DeceptionPolicy that was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" yaml:"observedGeneration,omitempty"`
+
 	// Conditions is an array of conditions that the DeceptionPolicy can be in.
 	// +listType=map
 	// +listMapKey=type
 	Conditions []DeceptionPolicyCondition `json:"conditions" yaml:"conditions"`
+
+	// HoneytokenRotations records the current and past generations of rotating honeytokens,
+	// so that alerts that are triggered late can still be attributed to the token that was accessed.
+	// +optional
+	HoneytokenRotations []HoneytokenRotation `json:"honeytokenRotations,omitempty" yaml:"honeytokenRotations,omitempty"`
+
+	// CanaryTokens refers to the tokens that were provisioned from a canarytokens-compatible service for the honeytokens,
+	// so that they are provisioned only once, and so that their alerts can be attributed to the trap they were placed with.
+	// +optional
+	CanaryTokens []CanaryTokenStatus `json:"canaryTokens,omitempty" yaml:"canaryTokens,omitempty"`
+
+	// Traps reports the status of each trap in the DeceptionPolicy, in the order of the spec.
+	// +optional
+	// +listType=map
+	// +listMapKey=index
+	Traps []TrapStatus `json:"traps,omitempty" yaml:"traps,omitempty"`
+
+	// TrapTemplates records the generations of the TrapTemplates that the traps were expanded from when they were last reconciled.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	TrapTemplates []TrapTemplateStatus `json:"trapTemplates,omitempty" yaml:"trapTemplates,omitempty"`
+
+	// Plan reports which resources, containers, and files the traps would be placed in, if the DeceptionPolicy is a dry run.
+	// +optional
+	Plan *DeceptionPolicyPlan `json:"plan,omitempty" yaml:"plan,omitempty"`
+
+	// SelfTest reports the result of the latest self-test, which the alert forwarder records.
+	// +optional
+	SelfTest *SelfTestStatus `json:"selfTest,omitempty" yaml:"selfTest,omitempty"`
+}
+
+// SelfTestStatus reports the result of the latest self-test of a DeceptionPolicy.
+type SelfTestStatus struct {
+	// LastRunTime is when the latest self-test finished.
+	LastRunTime metav1.Time `json:"lastRunTime" yaml:"lastRunTime"`
+
+	// Passed is true if the alert of the latest self-test was captured, mapped, and forwarded to the sinks.
+	Passed bool `json:"passed" yaml:"passed"`
+
+	// Latency is how long it took from touching the honeytoken until the alert was forwarded, if the latest self-test passed.
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty" yaml:"latency,omitempty"`
+
+	// LastSuccessTime is when a self-test passed the last time.
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty" yaml:"lastSuccessTime,omitempty"`
+
+	// ConsecutiveFailures is the number of self-tests that failed in a row since the last one passed.
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty" yaml:"consecutiveFailures,omitempty"`
+}
+
+// DeceptionPolicyPlan describes where the traps of a DeceptionPolicy would be placed, without deploying them.
+type DeceptionPolicyPlan struct {
+	// ObservedGeneration is the generation of the DeceptionPolicy that the plan is based on.
+	ObservedGeneration int64 `json:"observedGeneration" yaml:"observedGeneration"`
+
+	// Traps reports for each trap in the DeceptionPolicy where it would be placed, in the order of the spec.
+	// +optional
+	// +listType=map
+	// +listMapKey=index
+	Traps []TrapPlan `json:"traps,omitempty" yaml:"traps,omitempty"`
+}
+
+// TrapPlan describes where a single trap of a DeceptionPolicy would be placed.
+type TrapPlan struct {
+	// Index is the position of the trap in the traps list of the DeceptionPolicy spec.
+	Index int `json:"index" yaml:"index"`
+
+	// TrapType is the type of the trap.
+	TrapType TrapType `json:"trapType" yaml:"trapType"`
+
+	// Placements lists the resources and containers in which the decoys of the trap would be placed.
+	// +optional
+	Placements []TrapPlacement `json:"placements,omitempty" yaml:"placements,omitempty"`
+
+	// CaptorPolicyName is the name of the TracingPolicy or KivePolicy that would monitor the trap.
+	// +optional
+	CaptorPolicyName string `json:"captorPolicyName,omitempty" yaml:"captorPolicyName,omitempty"`
+
+	// Expired is true if the trap expired and would not be placed anymore.
+	// +optional
+	Expired bool `json:"expired,omitempty" yaml:"expired,omitempty"`
+
+	// Error is the error that occurred while validating the trap or matching resources for it.
+	// +optional
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// TrapStatus describes where a single trap of a DeceptionPolicy was deployed.
+type TrapStatus struct {
+	// Index is the position of the trap in the traps list of the DeceptionPolicy spec.
+	Index int `json:"index" yaml:"index"`
+
+	// TrapType is the type of the trap.
+	TrapType TrapType `json:"trapType" yaml:"trapType"`
+
+	// TrapHash is the hash of the trap spec, as also annotated on its captors.
+	TrapHash string `json:"trapHash" yaml:"trapHash"`
+
+	// Placements lists the resources and containers in which the decoys of the trap are placed.
+	// +optional
+	Placements []TrapPlacement `json:"placements,omitempty" yaml:"placements,omitempty"`
+
+	// CaptorPolicyName is the name of the TracingPolicy or KivePolicy that monitors the trap.
+	// +optional
+	CaptorPolicyName string `json:"captorPolicyName,omitempty" yaml:"captorPolicyName,omitempty"`
+
+	// DeployedAt is the time when the decoys and the captor of the trap were first deployed successfully,
+	// since the trap spec was last changed.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	DeployedAt *metav1.Time `json:"deployedAt,omitempty" yaml:"deployedAt,omitempty"`
+
+	// Expired is true if the trap expired and was removed.
+	// +optional
+	Expired bool `json:"expired,omitempty" yaml:"expired,omitempty"`
+
+	// LastError is the last error that occurred while validating or deploying the trap.
+	// +optional
+	LastError string `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+
+	// LastErrorReason classifies the last error, if its cause is known (e.g., NoShell, ExecForbidden, CaptorBackendMissing, or SelectorMatchesNothing).
+	// +optional
+	LastErrorReason string `json:"lastErrorReason,omitempty" yaml:"lastErrorReason,omitempty"`
+
+	// Drifts lists the decoys of the trap that the latest verification found not to be in place anymore.
+	// Drifted decoys are deployed again automatically.
+	// +optional
+	Drifts []TrapDrift `json:"drifts,omitempty" yaml:"drifts,omitempty"`
+
+	// FailedPlacements lists the containers in which placing the decoys of the trap failed too often, so that Koney gave up.
+	// Koney tries again when the DeceptionPolicy is changed.
+	// +optional
+	FailedPlacements []FailedPlacement `json:"failedPlacements,omitempty" yaml:"failedPlacements,omitempty"`
+
+	// Checksums lists the SHA-256 checksums of the decoy files of the trap, as they were placed.
+	// The verification compares the content of the placed decoys with them to detect tampering.
+	// For content that references the placement context, the checksums are the ones of the content before the placeholders are replaced.
+	// +optional
+	Checksums []DecoyChecksum `json:"checksums,omitempty" yaml:"checksums,omitempty"`
+
+	// TokenPlacements lists the pods in which honeytokens whose content references the placement context are placed,
+	// together with the values they got there. At most TokenPlacementsLimit pods are listed per trap.
+	// +optional
+	TokenPlacements []TokenPlacement `json:"tokenPlacements,omitempty" yaml:"tokenPlacements,omitempty"`
+
+	// OmittedTokenPlacements is the number of token placements that are not listed because of TokenPlacementsLimit.
+	// +optional
+	OmittedTokenPlacements int `json:"omittedTokenPlacements,omitempty" yaml:"omittedTokenPlacements,omitempty"`
+}
+
+// DecoyChecksum is the checksum of a decoy file of a trap, as it was placed.
+type DecoyChecksum struct {
+	// FilePath is the path of the decoy.
+	FilePath string `json:"filePath" yaml:"filePath"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the content of the decoy.
+	SHA256 string `json:"sha256" yaml:"sha256"`
+}
+
+// TokenPlacement records the values that the placement context placeholders in the content of a honeytoken
+// were replaced with in a pod, so that a later use of the token can be attributed to the pod in which it was placed.
+// TokenPlacementsLimit is the number of token placements that are listed per trap,
+// so that the status stays small enough for etcd even if a trap is placed in many pods.
+const TokenPlacementsLimit = 100
+
+// TokenPlacement describes a honeytoken whose content references the placement context, as it was placed in a pod.
+type TokenPlacement struct {
+	// FilePath is the path of the honeytoken.
+	FilePath string `json:"filePath" yaml:"filePath"`
+
+	// Namespace is the namespace of the pod.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Pod is the name of the pod.
+	Pod string `json:"pod" yaml:"pod"`
+
+	// ServiceAccount is the service account of the pod.
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+
+	// Node is the node of the pod.
+	// +optional
+	Node string `json:"node,omitempty" yaml:"node,omitempty"`
+
+	// PlacementIDHash is the MD5 hash of the placement ID that the honeytoken got in the pod.
+	PlacementIDHash string `json:"placementIDHash" yaml:"placementIDHash"`
+
+	// FileContentHash is the MD5 hash of the content of the honeytoken, as it was placed in the pod.
+	FileContentHash string `json:"fileContentHash" yaml:"fileContentHash"`
+}
+
+// ChecksumOf returns the recorded checksum of the decoy at the file path, or an empty string if there is none.
+func (s *TrapStatus) ChecksumOf(filePath string) string {
+	for _, checksum := range s.Checksums {
+		if checksum.FilePath == filePath {
+			return checksum.SHA256
+		}
+	}
+	return ""
+}
+
+// FailedPlacement describes a container in which Koney gave up placing a decoy of a trap after several failed attempts.
+type FailedPlacement struct {
+	// Kind is the kind of the resource (e.g., Pod or Deployment).
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Namespace is the namespace of the resource.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Name is the name of the resource.
+	Name string `json:"name" yaml:"name"`
+
+	// Container is the container of the resource in which the decoy could not be placed.
+	Container string `json:"container" yaml:"container"`
+
+	// FilePath is the path of the decoy, for filesystem honeytokens.
+	// +optional
+	FilePath string `json:"filePath,omitempty" yaml:"filePath,omitempty"`
+
+	// Attempts is the number of failed attempts to place the decoy.
+	Attempts int `json:"attempts" yaml:"attempts"`
+
+	// LastError is the error of the last attempt.
+	LastError string `json:"lastError" yaml:"lastError"`
+}
+
+// TrapDrift describes a decoy of a trap that is not in place anymore, as it was deployed.
+type TrapDrift struct {
+	// Kind is the kind of the resource (e.g., Pod or Deployment).
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Namespace is the namespace of the resource.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Name is the name of the resource.
+	Name string `json:"name" yaml:"name"`
+
+	// Container is the container of the resource in which the decoy drifted.
+	Container string `json:"container" yaml:"container"`
+
+	// FilePath is the path of the decoy, for filesystem honeytokens.
+	// +optional
+	FilePath string `json:"filePath,omitempty" yaml:"filePath,omitempty"`
+
+	// Reason explains why the decoy is not in place anymore (e.g., ContainerRestarted or ContentMismatch).
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// TrapPlacement describes a resource in which decoys of a trap are placed.
+type TrapPlacement struct {
+	// Kind is the kind of the resource (e.g., Pod or Deployment).
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Namespace is the namespace of the resource.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Name is the name of the resource.
+	Name string `json:"name" yaml:"name"`
+
+	// Containers are the containers of the resource in which decoys are placed.
+	// +optional
+	Containers []string `json:"containers,omitempty" yaml:"containers,omitempty"`
+
+	// FilePaths are the paths of the decoys that are placed in the resource, for filesystem honeytokens.
+	// +optional
+	FilePaths []string `json:"filePaths,omitempty" yaml:"filePaths,omitempty"`
+}
+
+// HoneytokenRotationHistoryLimit is the number of generations that are kept per rotating honeytoken.
+const HoneytokenRotationHistoryLimit = 10
+
+// HoneytokenRotation describes one generation of a rotating honeytoken.
+type HoneytokenRotation struct {
+	// FilePath is the path of the rotating honeytoken.
+	FilePath string `json:"filePath" yaml:"filePath"`
+
+	// Generation is the number of rotations since the DeceptionPolicy was created.
+	Generation int64 `json:"generation" yaml:"generation"`
+
+	// FileContentHash is the MD5 hash of the file content of this generation.
+	FileContentHash string `json:"fileContentHash" yaml:"fileContentHash"`
+
+	// TokenHash is the MD5 hash of the token that was generated for this generation.
+	TokenHash string `json:"tokenHash" yaml:"tokenHash"`
+
+	// ActiveFrom is the time when this generation became active.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	ActiveFrom metav1.Time `json:"activeFrom" yaml:"activeFrom"`
+
+	// ActiveUntil is the time when this generation is (or was) replaced by the next one.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	ActiveUntil metav1.Time `json:"activeUntil" yaml:"activeUntil"`
+}
+
+// CanaryTokenStatus refers to a token that was provisioned from a canarytokens-compatible service for a honeytoken.
+// The values of the token are stored in a secret in the Koney namespace, not in the status.
+type CanaryTokenStatus struct {
+	// FilePath is the path of the honeytoken that embeds the canary token.
+	FilePath string `json:"filePath" yaml:"filePath"`
+
+	// Kind is the kind of the canary token (url, awsKeys, or dns).
+	Kind string `json:"kind" yaml:"kind"`
+
+	// SecretName is the name of the secret in the Koney namespace that stores the values of the canary token.
+	SecretName string `json:"secretName" yaml:"secretName"`
+
+	// ProvisionedAt is the time when the canary token was provisioned.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	ProvisionedAt metav1.Time `json:"provisionedAt" yaml:"provisionedAt"`
 }
 
 // DeceptionPolicyCondition describes the state of one aspect of a DeceptionPolicy at a certain point.
+// It has the same fields as metav1.Condition, so that standard tooling (e.g., kubectl wait) can evaluate it.
 type DeceptionPolicyCondition struct {
 	// Type of deception policy condition.
 	// The regex it matches is (dns1123SubdomainFmt/)?(qualifiedNameFmt)
@@ -44,6 +366,11 @@ type DeceptionPolicyCondition struct {
 	// +kubebuilder:validation:Enum=True;False;Unknown
 	Status metav1.ConditionStatus `json:"status" yaml:"status"`
 
+	// ObservedGeneration is the generation of the DeceptionPolicy that the condition was set based upon.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" yaml:"observedGeneration,omitempty"`
+
 	// LastTransitionTime is the last time the condition transitioned from one status to another,
 	// i.e., when the underlying condition changed.
 	// +required
@@ -95,6 +422,7 @@ func (status *DeceptionPolicyStatus) PutCondition(conditionType string, conditio
 }
 
 // PutConditionStruct adds a new condition to the DeceptionPolicy status, or updates the first existing condition of the same type, if it exists.
+// Like for metav1.Condition, the LastTransitionTime of an existing condition is only updated if its status changes.
 // The function returns true if the conditions were modified as a result of the operation.
 func (status *DeceptionPolicyStatus) PutConditionStruct(condition DeceptionPolicyCondition) bool {
 	conditionsModified := false
@@ -103,8 +431,11 @@ func (status *DeceptionPolicyStatus) PutConditionStruct(condition DeceptionPolic
 		status.Conditions = append(status.Conditions, condition)
 		conditionsModified = true
 	} else if !condition.Equals(existingCondition) {
+		if existingCondition.Status != condition.Status {
+			existingCondition.LastTransitionTime = condition.LastTransitionTime
+		}
 		existingCondition.Status = condition.Status
-		existingCondition.LastTransitionTime = condition.LastTransitionTime
+		existingCondition.ObservedGeneration = condition.ObservedGeneration
 		existingCondition.Reason = condition.Reason
 		existingCondition.Message = condition.Message
 
@@ -114,6 +445,16 @@ func (status *DeceptionPolicyStatus) PutConditionStruct(condition DeceptionPolic
 	return conditionsModified
 }
 
+// MetaConditions returns the conditions as metav1.Condition, e.g., to evaluate them with the k8s.io/apimachinery/pkg/api/meta helpers.
+func (status *DeceptionPolicyStatus) MetaConditions() []metav1.Condition {
+	conditions := make([]metav1.Condition, 0, len(status.Conditions))
+	for _, condition := range status.Conditions {
+		conditions = append(conditions, metav1.Condition(condition))
+	}
+
+	return conditions
+}
+
 // Equals returns true if the conditions are equal (excluding LastTransitionTime).
 func (condition *DeceptionPolicyCondition) Equals(other *DeceptionPolicyCondition) bool {
 	if condition == other {
@@ -125,6 +466,9 @@ func (condition *DeceptionPolicyCondition) Equals(other *DeceptionPolicyConditio
 	if condition.Status != other.Status {
 		return false
 	}
+	if condition.ObservedGeneration != other.ObservedGeneration {
+		return false
+	}
 	if condition.Reason != other.Reason {
 		return false
 	}
@@ -134,3 +478,146 @@ func (condition *DeceptionPolicyCondition) Equals(other *DeceptionPolicyConditio
 
 	return true
 }
+
+// PutHoneytokenRotation records a honeytoken generation in the DeceptionPolicy status, if it is not recorded yet.
+// Only the latest HoneytokenRotationHistoryLimit generations are kept for each file path.
+// The function returns true if the rotations were modified as a result of the operation.
+func (status *DeceptionPolicyStatus) PutHoneytokenRotation(rotation HoneytokenRotation) bool {
+	for _, existingRotation := range status.HoneytokenRotations {
+		if existingRotation.FilePath == rotation.FilePath && existingRotation.Generation == rotation.Generation {
+			return false
+		}
+	}
+
+	status.HoneytokenRotations = append(status.HoneytokenRotations, rotation)
+
+	// Drop the oldest generations of this file path if we exceed the history limit
+	numGenerations := 0
+	for i := len(status.HoneytokenRotations) - 1; i >= 0; i-- {
+		if status.HoneytokenRotations[i].FilePath != rotation.FilePath {
+			continue
+		}
+		numGenerations++
+		if numGenerations > HoneytokenRotationHistoryLimit {
+			status.HoneytokenRotations = append(status.HoneytokenRotations[:i], status.HoneytokenRotations[i+1:]...)
+		}
+	}
+
+	return true
+}
+
+// TrapDeployedAt returns the time when the trap with the given hash was first deployed, or nil if it was not deployed yet.
+// The deployment time is kept after the trap expired, so that it stays expired.
+func (status *DeceptionPolicyStatus) TrapDeployedAt(trapHash string) *metav1.Time {
+	for _, trapStatus := range status.Traps {
+		if trapStatus.TrapHash == trapHash && trapStatus.DeployedAt != nil {
+			return trapStatus.DeployedAt
+		}
+	}
+	return nil
+}
+
+// SetTrapStatuses replaces the trap statuses of the DeceptionPolicy status.
+// As long as the spec of a trap does not change, its previous deployment time is kept,
+// even if the trap moved to another position in the traps list.
+// The function returns true if the trap statuses were modified as a result of the operation.
+func (status *DeceptionPolicyStatus) SetTrapStatuses(trapStatuses []TrapStatus) bool {
+	for i := range trapStatuses {
+		var deployedAt *metav1.Time
+		for _, existingStatus := range status.Traps {
+			if existingStatus.TrapHash != trapStatuses[i].TrapHash || existingStatus.DeployedAt == nil {
+				continue
+			}
+			// Prefer the status of the trap at the same position
+			if deployedAt == nil || existingStatus.Index == trapStatuses[i].Index {
+				deployedAt = existingStatus.DeployedAt
+			}
+		}
+		if deployedAt != nil {
+			trapStatuses[i].DeployedAt = deployedAt
+		}
+	}
+
+	if len(status.Traps) == 0 && len(trapStatuses) == 0 {
+		return false
+	}
+	if equality.Semantic.DeepEqual(status.Traps, trapStatuses) {
+		return false
+	}
+
+	status.Traps = trapStatuses
+	return true
+}
+
+// SetPlan replaces the dry run plan of the DeceptionPolicy status, or removes it if the plan is nil.
+// The function returns true if the plan was modified as a result of the operation.
+func (status *DeceptionPolicyStatus) SetPlan(plan *DeceptionPolicyPlan) bool {
+	if equality.Semantic.DeepEqual(status.Plan, plan) {
+		return false
+	}
+
+	status.Plan = plan
+	return true
+}
+
+// SetTrapTemplates records the generations of the TrapTemplates that the traps were expanded from.
+// It returns true if the recorded generations changed.
+func (status *DeceptionPolicyStatus) SetTrapTemplates(trapTemplates []TrapTemplateStatus) bool {
+	if len(status.TrapTemplates) == 0 && len(trapTemplates) == 0 {
+		return false
+	}
+	if equality.Semantic.DeepEqual(status.TrapTemplates, trapTemplates) {
+		return false
+	}
+
+	status.TrapTemplates = trapTemplates
+	return true
+}
+
+// FindCanaryToken returns the canary token of the given kind that was provisioned for the honeytoken at the file path,
+// or nil if there is none.
+func (status *DeceptionPolicyStatus) FindCanaryToken(filePath, kind string) *CanaryTokenStatus {
+	for i := range status.CanaryTokens {
+		if status.CanaryTokens[i].FilePath == filePath && status.CanaryTokens[i].Kind == kind {
+			return &status.CanaryTokens[i]
+		}
+	}
+
+	return nil
+}
+
+// PutCanaryToken records a provisioned canary token in the DeceptionPolicy status, replacing the canary token
+// that was provisioned for the same file path before (e.g., of another kind, or before its secret was deleted).
+// The function returns true if the canary tokens were modified as a result of the operation.
+func (status *DeceptionPolicyStatus) PutCanaryToken(canaryToken CanaryTokenStatus) bool {
+	for i, existingCanaryToken := range status.CanaryTokens {
+		if existingCanaryToken.FilePath != canaryToken.FilePath {
+			continue
+		}
+		if existingCanaryToken.Kind == canaryToken.Kind && existingCanaryToken.SecretName == canaryToken.SecretName &&
+			existingCanaryToken.ProvisionedAt.Equal(&canaryToken.ProvisionedAt) {
+			return false
+		}
+		status.CanaryTokens[i] = canaryToken
+		return true
+	}
+
+	status.CanaryTokens = append(status.CanaryTokens, canaryToken)
+	return true
+}
+
+// FindHoneytokenRotation returns the generation of the rotating honeytoken at the given
+// file path that was active at the given time, if it is still recorded.
+func (status *DeceptionPolicyStatus) FindHoneytokenRotation(filePath string, at metav1.Time) *HoneytokenRotation {
+	for i := range status.HoneytokenRotations {
+		rotation := &status.HoneytokenRotations[i]
+		if rotation.FilePath != filePath {
+			continue
+		}
+		if !at.Before(&rotation.ActiveFrom) && at.Before(&rotation.ActiveUntil) {
+			return rotation
+		}
+	}
+
+	return nil
+}
@@ -16,8 +16,11 @@
 package v1alpha1
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -170,6 +173,34 @@ var _ = Describe("PutCondition", func() {
 	})
 })
 
+var _ = Describe("PutConditionStruct", func() {
+	BeforeEach(func() {
+		resetDeceptionPolicy()
+	})
+
+	It("should only update the transition time if the status changes", func() {
+		transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+		fooCondition.LastTransitionTime = transitionTime
+		deceptionPolicy.Status.Conditions = append(deceptionPolicy.Status.Conditions, fooCondition)
+
+		updatedCondition := fooCondition
+		updatedCondition.ObservedGeneration = 2
+		updatedCondition.LastTransitionTime = metav1.Now()
+		Expect(deceptionPolicy.Status.PutConditionStruct(updatedCondition)).To(BeTrue())
+		Expect(deceptionPolicy.Status.Conditions[0].ObservedGeneration).To(Equal(int64(2)))
+		Expect(deceptionPolicy.Status.Conditions[0].LastTransitionTime).To(Equal(transitionTime))
+
+		updatedCondition.Status = metav1.ConditionFalse
+		Expect(deceptionPolicy.Status.PutConditionStruct(updatedCondition)).To(BeTrue())
+		Expect(deceptionPolicy.Status.Conditions[0].LastTransitionTime).To(Equal(updatedCondition.LastTransitionTime))
+	})
+
+	It("should be convertible to metav1.Condition", func() {
+		deceptionPolicy.Status.Conditions = append(deceptionPolicy.Status.Conditions, fooCondition)
+		Expect(meta.IsStatusConditionTrue(deceptionPolicy.Status.MetaConditions(), fooType)).To(BeTrue())
+	})
+})
+
 var _ = Describe("Equals", func() {
 	BeforeEach(func() {
 		resetDeceptionPolicy()
@@ -191,3 +222,211 @@ var _ = Describe("Equals", func() {
 		})
 	})
 })
+
+var _ = Describe("PutHoneytokenRotation", func() {
+	BeforeEach(func() {
+		resetDeceptionPolicy()
+	})
+
+	rotation := func(filePath string, generation int64) HoneytokenRotation {
+		return HoneytokenRotation{FilePath: filePath, Generation: generation}
+	}
+
+	Context("when the generation is not recorded", func() {
+		It("should record the generation", func() {
+			dirty := deceptionPolicy.Status.PutHoneytokenRotation(rotation("/foo", 0))
+
+			Expect(dirty).To(BeTrue())
+			Expect(deceptionPolicy.Status.HoneytokenRotations).To(HaveLen(1))
+		})
+	})
+
+	Context("when the generation is already recorded", func() {
+		It("should not record the generation again", func() {
+			deceptionPolicy.Status.PutHoneytokenRotation(rotation("/foo", 0))
+			dirty := deceptionPolicy.Status.PutHoneytokenRotation(rotation("/foo", 0))
+
+			Expect(dirty).To(BeFalse())
+			Expect(deceptionPolicy.Status.HoneytokenRotations).To(HaveLen(1))
+		})
+	})
+
+	Context("when the history limit is exceeded", func() {
+		It("should drop the oldest generations of the same file path only", func() {
+			deceptionPolicy.Status.PutHoneytokenRotation(rotation("/bar", 0))
+			for generation := int64(0); generation < HoneytokenRotationHistoryLimit+2; generation++ {
+				deceptionPolicy.Status.PutHoneytokenRotation(rotation("/foo", generation))
+			}
+
+			Expect(deceptionPolicy.Status.HoneytokenRotations).To(HaveLen(HoneytokenRotationHistoryLimit + 1))
+			Expect(deceptionPolicy.Status.HoneytokenRotations[0]).To(Equal(rotation("/bar", 0)))
+			Expect(deceptionPolicy.Status.HoneytokenRotations[1]).To(Equal(rotation("/foo", 2)))
+		})
+	})
+})
+
+var _ = Describe("FindHoneytokenRotation", func() {
+	BeforeEach(func() {
+		resetDeceptionPolicy()
+	})
+
+	It("should find the generation that was active at the given time", func() {
+		start := metav1.Now()
+		middle := metav1.NewTime(start.Add(time.Hour))
+		end := metav1.NewTime(start.Add(2 * time.Hour))
+
+		deceptionPolicy.Status.PutHoneytokenRotation(HoneytokenRotation{FilePath: "/foo", Generation: 0, ActiveFrom: start, ActiveUntil: middle})
+		deceptionPolicy.Status.PutHoneytokenRotation(HoneytokenRotation{FilePath: "/foo", Generation: 1, ActiveFrom: middle, ActiveUntil: end})
+
+		Expect(deceptionPolicy.Status.FindHoneytokenRotation("/foo", start).Generation).To(Equal(int64(0)))
+		Expect(deceptionPolicy.Status.FindHoneytokenRotation("/foo", middle).Generation).To(Equal(int64(1)))
+		Expect(deceptionPolicy.Status.FindHoneytokenRotation("/foo", end)).To(BeNil())
+		Expect(deceptionPolicy.Status.FindHoneytokenRotation("/bar", start)).To(BeNil())
+	})
+})
+
+var _ = Describe("SetTrapStatuses", func() {
+	var deployedAt metav1.Time
+
+	BeforeEach(func() {
+		resetDeceptionPolicy()
+		deployedAt = metav1.NewTime(time.Now().Add(-time.Hour))
+		deceptionPolicy.Status.Traps = []TrapStatus{
+			{Index: 0, TrapType: FilesystemHoneytokenTrap, TrapHash: "abc", DeployedAt: &deployedAt},
+		}
+	})
+
+	Context("when the trap spec did not change", func() {
+		It("should keep the previous deployment time", func() {
+			now := metav1.Now()
+			dirty := deceptionPolicy.Status.SetTrapStatuses([]TrapStatus{
+				{Index: 0, TrapType: FilesystemHoneytokenTrap, TrapHash: "abc", DeployedAt: &now},
+			})
+			Expect(dirty).To(BeFalse())
+			Expect(deceptionPolicy.Status.Traps[0].DeployedAt).To(Equal(&deployedAt))
+		})
+
+		It("should report a new error", func() {
+			dirty := deceptionPolicy.Status.SetTrapStatuses([]TrapStatus{
+				{Index: 0, TrapType: FilesystemHoneytokenTrap, TrapHash: "abc", LastError: "boom"},
+			})
+			Expect(dirty).To(BeTrue())
+			Expect(deceptionPolicy.Status.Traps[0].LastError).To(Equal("boom"))
+			Expect(deceptionPolicy.Status.Traps[0].DeployedAt).To(Equal(&deployedAt))
+		})
+	})
+
+	Context("when the trap moved to another position", func() {
+		It("should keep the previous deployment time", func() {
+			dirty := deceptionPolicy.Status.SetTrapStatuses([]TrapStatus{
+				{Index: 0, TrapType: FilesystemHoneytokenTrap, TrapHash: "def"},
+				{Index: 1, TrapType: FilesystemHoneytokenTrap, TrapHash: "abc"},
+			})
+			Expect(dirty).To(BeTrue())
+			Expect(deceptionPolicy.Status.Traps[0].DeployedAt).To(BeNil())
+			Expect(deceptionPolicy.Status.Traps[1].DeployedAt).To(Equal(&deployedAt))
+		})
+	})
+
+	Context("when the trap spec changed", func() {
+		It("should reset the deployment time", func() {
+			dirty := deceptionPolicy.Status.SetTrapStatuses([]TrapStatus{
+				{Index: 0, TrapType: FilesystemHoneytokenTrap, TrapHash: "def"},
+			})
+			Expect(dirty).To(BeTrue())
+			Expect(deceptionPolicy.Status.Traps[0].DeployedAt).To(BeNil())
+		})
+	})
+})
+
+var _ = Describe("TrapDeployedAt", func() {
+	var deployedAt metav1.Time
+
+	BeforeEach(func() {
+		resetDeceptionPolicy()
+		deployedAt = metav1.NewTime(time.Now().Add(-time.Hour))
+		deceptionPolicy.Status.Traps = []TrapStatus{
+			{Index: 0, TrapType: FilesystemHoneytokenTrap, TrapHash: "abc", DeployedAt: &deployedAt},
+			{Index: 1, TrapType: FilesystemHoneytokenTrap, TrapHash: "def"},
+		}
+	})
+
+	It("should return the deployment time of a deployed trap", func() {
+		Expect(deceptionPolicy.Status.TrapDeployedAt("abc")).To(Equal(&deployedAt))
+	})
+
+	It("should return nil for traps that were not deployed yet", func() {
+		Expect(deceptionPolicy.Status.TrapDeployedAt("def")).To(BeNil())
+		Expect(deceptionPolicy.Status.TrapDeployedAt("xyz")).To(BeNil())
+	})
+})
+
+var _ = Describe("SetPlan", func() {
+	var deceptionPolicy DeceptionPolicy
+	plan := &DeceptionPolicyPlan{ObservedGeneration: 1, Traps: []TrapPlan{
+		{Index: 0, TrapType: FilesystemHoneytokenTrap, Placements: []TrapPlacement{{Kind: "Pod", Namespace: "default", Name: "api"}}},
+	}}
+
+	BeforeEach(func() {
+		deceptionPolicy = DeceptionPolicy{}
+	})
+
+	It("should only report a modification if the plan changes", func() {
+		Expect(deceptionPolicy.Status.SetPlan(nil)).To(BeFalse())
+		Expect(deceptionPolicy.Status.SetPlan(plan.DeepCopy())).To(BeTrue())
+		Expect(deceptionPolicy.Status.SetPlan(plan.DeepCopy())).To(BeFalse())
+		Expect(deceptionPolicy.Status.SetPlan(nil)).To(BeTrue())
+		Expect(deceptionPolicy.Status.Plan).To(BeNil())
+	})
+})
+
+var _ = Describe("IsDryRun", func() {
+	It("should be enabled by the spec or the annotation", func() {
+		deceptionPolicy := DeceptionPolicy{}
+		Expect(deceptionPolicy.IsDryRun()).To(BeFalse())
+
+		deceptionPolicy.Spec.DryRun = &[]bool{true}[0]
+		Expect(deceptionPolicy.IsDryRun()).To(BeTrue())
+
+		deceptionPolicy.Spec.DryRun = &[]bool{false}[0]
+		deceptionPolicy.Annotations = map[string]string{DryRunAnnotation: "true"}
+		Expect(deceptionPolicy.IsDryRun()).To(BeTrue())
+
+		deceptionPolicy.Annotations[DryRunAnnotation] = "false"
+		Expect(deceptionPolicy.IsDryRun()).To(BeFalse())
+	})
+})
+
+var _ = Describe("IsPaused", func() {
+	It("should only be paused by the annotation", func() {
+		deceptionPolicy := DeceptionPolicy{}
+		Expect(deceptionPolicy.IsPaused()).To(BeFalse())
+
+		deceptionPolicy.Annotations = map[string]string{PausedAnnotation: "true"}
+		Expect(deceptionPolicy.IsPaused()).To(BeTrue())
+
+		deceptionPolicy.Annotations[PausedAnnotation] = "false"
+		Expect(deceptionPolicy.IsPaused()).To(BeFalse())
+	})
+})
+
+var _ = Describe("PutCanaryToken", func() {
+	BeforeEach(func() {
+		resetDeceptionPolicy()
+	})
+
+	It("should record and replace canary tokens per file path", func() {
+		canaryToken := CanaryTokenStatus{FilePath: "/foo", Kind: CanaryTokenKindDNS, SecretName: "abc"}
+		Expect(deceptionPolicy.Status.PutCanaryToken(canaryToken)).To(BeTrue())
+		Expect(deceptionPolicy.Status.PutCanaryToken(canaryToken)).To(BeFalse())
+		Expect(deceptionPolicy.Status.FindCanaryToken("/foo", CanaryTokenKindDNS)).To(Equal(&canaryToken))
+		Expect(deceptionPolicy.Status.FindCanaryToken("/foo", CanaryTokenKindURL)).To(BeNil())
+
+		replacement := CanaryTokenStatus{FilePath: "/foo", Kind: CanaryTokenKindURL, SecretName: "def"}
+		Expect(deceptionPolicy.Status.PutCanaryToken(replacement)).To(BeTrue())
+		Expect(deceptionPolicy.Status.CanaryTokens).To(Equal([]CanaryTokenStatus{replacement}))
+
+		Expect(deceptionPolicy.Status.PutCanaryToken(CanaryTokenStatus{FilePath: "/bar", Kind: CanaryTokenKindDNS, SecretName: "ghi"})).To(BeTrue())
+		Expect(deceptionPolicy.Status.CanaryTokens).To(HaveLen(2))
+	})
+})
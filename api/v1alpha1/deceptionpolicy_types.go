@@ -19,9 +19,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// DryRunAnnotation is the annotation that enables the dry run of a DeceptionPolicy, like the DryRun field of the spec.
+	DryRunAnnotation = "koney/dry-run"
+
+	// PausedAnnotation is the annotation that pauses the reconciliation of a DeceptionPolicy, leaving deployed traps in place.
+	PausedAnnotation = "koney/paused"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
 
 // DeceptionPolicy is the Schema for the deceptionpolicies API
 type DeceptionPolicy struct {
@@ -53,6 +62,11 @@ type DeceptionPolicySpec struct {
 	// Each trap represents a cyber deception technique.
 	Traps []Trap `json:"traps,omitempty" yaml:"traps,omitempty"`
 
+	// TrapDefaults are defaults that cascade to all traps, to avoid repeating them in every trap.
+	// They are applied when the DeceptionPolicy is created or updated. Fields that are set in a trap take precedence.
+	// +optional
+	TrapDefaults *TrapDefaults `json:"trapDefaults,omitempty" yaml:"trapDefaults,omitempty"`
+
 	// StrictValidation is a flag that indicates whether the policy should be strictly validated.
 	// If set to true, the traps will be deployed only if all the traps in the policy are valid.
 	// If set to false, the valid traps will be deployed even if some of the traps are invalid.
@@ -67,6 +81,64 @@ type DeceptionPolicySpec struct {
 	// +optional
 	// +kubebuilder:default=true
 	MutateExisting *bool `json:"mutateExisting,omitempty" yaml:"mutateExisting,omitempty"`
+
+	// MaxAlertsPerHour is the maximum number of alerts that are forwarded for this policy within one hour.
+	// Once the quota is exceeded, a single meta-alert is emitted and further alerts are suppressed (and counted)
+	// until the quota is available again. This protects downstream systems from runaway traps.
+	// If not set, alerts are not limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAlertsPerHour *int32 `json:"maxAlertsPerHour,omitempty" yaml:"maxAlertsPerHour,omitempty"`
+
+	// AlertAggregationWindow is the time window in which near-identical alerts are aggregated into one.
+	// Alerts of the same trap, pod, and process binary are grouped: the first one is forwarded right away,
+	// and the others within the window are summarized in a single alert with a hit count and first/last timestamps.
+	// If not set, every alert is forwarded on its own.
+	// +optional
+	AlertAggregationWindow *metav1.Duration `json:"alertAggregationWindow,omitempty" yaml:"alertAggregationWindow,omitempty"`
+
+	// DryRun is a flag to only compute which resources, containers, and files the traps would be placed in,
+	// without deploying, changing, or removing any decoys and captors. The result is published in the plan of the status.
+	// Alternatively, the annotation "koney/dry-run: true" enables the dry run as well.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+
+	// ClusterPolicyOverrides exempt namespaces from ClusterDeceptionPolicies, so that only the traps of this DeceptionPolicy
+	// are applied there. A DeceptionPolicy without traps opts the namespaces out of the ClusterDeceptionPolicies.
+	// +optional
+	ClusterPolicyOverrides []ClusterPolicyOverride `json:"clusterPolicyOverrides,omitempty" yaml:"clusterPolicyOverrides,omitempty"`
+
+	// SelfTest runs periodic self-tests of the detection chain of this DeceptionPolicy from a dedicated test pod.
+	// The results are reported in the status, and an alert is sent to the sinks if a self-test fails.
+	// If not set, this DeceptionPolicy is not self-tested periodically.
+	// +optional
+	SelfTest *SelfTest `json:"selfTest,omitempty" yaml:"selfTest,omitempty"`
+}
+
+// ClusterPolicyOverride exempts namespaces from a ClusterDeceptionPolicy.
+type ClusterPolicyOverride struct {
+	// Name is the name of the ClusterDeceptionPolicy that is overridden.
+	// If empty, all ClusterDeceptionPolicies are overridden.
+	// +optional
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Namespaces are the namespaces in which the ClusterDeceptionPolicy is not applied.
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces" yaml:"namespaces"`
+}
+
+// IsDryRun returns true if the DeceptionPolicy should only be planned, but not deployed,
+// either because of the DryRun field of the spec, or because of the dry run annotation.
+func (deceptionPolicy *DeceptionPolicy) IsDryRun() bool {
+	if deceptionPolicy.Spec.DryRun != nil && *deceptionPolicy.Spec.DryRun {
+		return true
+	}
+	return deceptionPolicy.Annotations[DryRunAnnotation] == "true"
+}
+
+// IsPaused returns true if the reconciliation of the DeceptionPolicy is paused with the paused annotation.
+func (deceptionPolicy *DeceptionPolicy) IsPaused() bool {
+	return deceptionPolicy.Annotations[PausedAnnotation] == "true"
 }
 
 func init() {
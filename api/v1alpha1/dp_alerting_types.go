@@ -0,0 +1,29 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+// Alerting configures the alerts that are emitted when a trap is accessed.
+type Alerting struct {
+	// Severity overrides the severity of alerts emitted for this trap.
+	// If not set, the severity configured in the alert sink is used.
+	// +kubebuilder:validation:Enum=CRITICAL;HIGH;MEDIUM;LOW
+	// +optional
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
+	// Tags are custom key-value pairs that are attached to alerts emitted for this trap.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
@@ -15,14 +15,82 @@
 
 package v1alpha1
 
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // CaptorDeployment is the entity that monitors access to the traps.
 type CaptorDeployment struct {
 	// Strategy is the technical method to deploy the captor.
 	// "tetragon" (default) requires the Tetragon controller to be installed.
 	// "kive" requires the Kive controller to be installed.
+	// "falco" requires Falco to be installed and to load the rules from the ConfigMap that Koney writes in its namespace.
+	// "sidecar" injects a watcher sidecar that uses inotify into the pods, for clusters where no eBPF-based captor can be installed.
+	// It requires a decoy deployment strategy that mounts a volume into deployments (volumeMount, projectedVolume, imageVolume, or initContainer).
 	// "none" disables captor deployment entirely for this trap.
-	// +kubebuilder:validation:Enum=tetragon;kive;none
+	// If not set, the strategy of the TrapDefaults of the DeceptionPolicy is used, or "tetragon" otherwise.
+	// +kubebuilder:validation:Enum=tetragon;kive;falco;sidecar;none
 	// +optional
-	// +kubebuilder:default="tetragon"
 	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+
+	// Strategies deploys several captors for the trap at once (e.g., ["tetragon", "kive"]), for redundancy when one of them is degraded.
+	// The alert forwarder deduplicates the alerts of the same access that several captors report. It cannot be set together with Strategy.
+	// +kubebuilder:validation:items:Enum=tetragon;kive;falco;sidecar
+	// +listType=set
+	// +optional
+	Strategies []string `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+
+	// RateLimit limits how often a captor reports repeated accesses to the trap, so that a process that reads
+	// the honeytoken in a tight loop does not flood the alert forwarder. Only the "tetragon" strategy supports it.
+	// +optional
+	RateLimit *CaptorRateLimit `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+}
+
+// CaptorRateLimit limits how often a captor reports accesses to a trap.
+type CaptorRateLimit struct {
+	// Period is the time within which repeated accesses are reported only once (e.g., "30s" or "5m").
+	// Tetragon rate-limits with a precision of seconds.
+	Period metav1.Duration `json:"period" yaml:"period"`
+
+	// Scope tells which accesses count as repeated.
+	// "thread" (default) limits each thread, "process" limits all threads of a process,
+	// and "global" limits all accesses, regardless of which process accessed the trap.
+	// +kubebuilder:validation:Enum=thread;process;global
+	// +optional
+	Scope string `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
+
+// IsValid checks if the rate limit is valid.
+func (r *CaptorRateLimit) IsValid() error {
+	if r.Period.Duration < time.Second {
+		return fmt.Errorf("CaptorDeployment.RateLimit.Period must be at least 1s, but is '%s'", r.Period.Duration)
+	}
+
+	return nil
+}
+
+// AllStrategies returns the strategies of all captors of the trap, i.e., Strategies if it is set, or Strategy otherwise.
+// The strategies are empty if neither is set (i.e., before the defaults are applied).
+func (c *CaptorDeployment) AllStrategies() []string {
+	if len(c.Strategies) > 0 {
+		return c.Strategies
+	}
+	if c.Strategy != "" {
+		return []string{c.Strategy}
+	}
+	return nil
+}
+
+// HasStrategy returns true if any captor of the trap is deployed with the given strategy.
+func (c *CaptorDeployment) HasStrategy(strategy string) bool {
+	return slices.Contains(c.AllStrategies(), strategy)
+}
+
+// IsZero returns true if neither Strategy nor Strategies is set (the RateLimit does not select a strategy).
+func (c *CaptorDeployment) IsZero() bool {
+	return c.Strategy == "" && len(c.Strategies) == 0
 }
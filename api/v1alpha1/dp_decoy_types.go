@@ -15,11 +15,97 @@
 
 package v1alpha1
 
+import (
+	"errors"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
 // DecoyDeployment is the entities that is attacked (e.g., the honeytoken).
 type DecoyDeployment struct {
 	// Strategy is the technical method to deploy the trap.
-	// +kubebuilder:validation:Enum=volumeMount;containerExec;kyvernoPolicy
+	// If not set, the strategy of the TrapDefaults of the DeceptionPolicy is used, or "volumeMount" otherwise.
+	// The "admission" strategy injects the decoy into new pods with the mutating webhook of Koney, which must be enabled.
+	// The "auto" strategy uses "containerExec" if Koney is allowed to exec into pods, and "projectedVolume" otherwise.
+	// +kubebuilder:validation:Enum=volumeMount;projectedVolume;containerExec;ephemeralContainer;imageVolume;initContainer;admission;kyvernoPolicy;auto
 	// +optional
-	// +kubebuilder:default="volumeMount"
 	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+
+	// ImageVolume configures the OCI image that contains the decoy files, if the strategy is imageVolume.
+	// +optional
+	ImageVolume *ImageVolumeDecoy `json:"imageVolume,omitempty" yaml:"imageVolume,omitempty"`
+
+	// InitContainer configures the init container that writes the decoy files, if the strategy is initContainer.
+	// +optional
+	InitContainer *InitContainerDecoy `json:"initContainer,omitempty" yaml:"initContainer,omitempty"`
+
+	// EphemeralContainer configures the ephemeral containers that write the decoy files, if the strategy is ephemeralContainer.
+	// +optional
+	EphemeralContainer *EphemeralContainerDecoy `json:"ephemeralContainer,omitempty" yaml:"ephemeralContainer,omitempty"`
+}
+
+// ImageVolumeDecoy configures an OCI image that is mounted as an image volume to deploy decoys.
+// Image volumes require Kubernetes 1.31 or newer with the ImageVolume feature gate enabled,
+// and mounting a single decoy file from them (with a subPath) requires Kubernetes 1.33 or newer.
+type ImageVolumeDecoy struct {
+	// Image is the reference of the OCI image that contains the decoy files.
+	Image string `json:"image" yaml:"image"`
+
+	// PullPolicy is the policy for pulling the image.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty" yaml:"pullPolicy,omitempty"`
+
+	// Path is the path of the decoy file inside the image.
+	// By default, it is the file name of the honeytoken in the root of the image.
+	// +optional
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// InitContainerDecoy configures the init container that writes decoy files into a shared emptyDir volume.
+// The image must provide a POSIX shell with printf (and chmod for read-only decoys).
+type InitContainerDecoy struct {
+	// Image is the image of the init container.
+	// By default, a small busybox image is used.
+	// +optional
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// PullPolicy is the policy for pulling the image.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty" yaml:"pullPolicy,omitempty"`
+}
+
+// EphemeralContainerDecoy configures the ephemeral containers that write decoy files into running containers.
+// The image must provide a POSIX shell with printf, mkdir, and rm (and chmod for read-only decoys).
+type EphemeralContainerDecoy struct {
+	// Image is the image of the ephemeral containers.
+	// By default, a small busybox image is used.
+	// +optional
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// PullPolicy is the policy for pulling the image.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty" yaml:"pullPolicy,omitempty"`
+}
+
+// AutoStrategies are the strategies that the "auto" strategy chooses from.
+var AutoStrategies = []string{"containerExec", "projectedVolume"}
+
+// CanUseStrategy returns true if decoys of this deployment may be placed with the strategy,
+// i.e., if it is the strategy of the deployment, or one that its "auto" strategy chooses from.
+func (d *DecoyDeployment) CanUseStrategy(strategy string) bool {
+	return strategy == d.Strategy || (d.Strategy == "auto" && slices.Contains(AutoStrategies, strategy))
+}
+
+// IsValid checks if the decoy deployment is valid.
+// The imageVolume strategy requires an image.
+func (d *DecoyDeployment) IsValid() error {
+	if d.Strategy == "imageVolume" && (d.ImageVolume == nil || d.ImageVolume.Image == "") {
+		return errors.New("DecoyDeployment.ImageVolume.Image is required for the imageVolume strategy")
+	}
+
+	return nil
 }
@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultFollowAttackerDuration is how long pods that access a trap are followed if the trap sets no duration.
+const DefaultFollowAttackerDuration = time.Hour
+
+// FollowAttacker configures how pods that access a trap are monitored more closely afterwards.
+type FollowAttacker struct {
+	// Duration is how long a pod is followed after it accessed the trap. Every access extends it.
+	// If not set, pods are followed for one hour.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// IsValid checks if the follow-the-attacker configuration is valid.
+func (f *FollowAttacker) IsValid() error {
+	if f.Duration != nil && f.Duration.Duration <= 0 {
+		return fmt.Errorf("FollowAttacker.Duration must be positive, but is '%s'", f.Duration.Duration)
+	}
+
+	return nil
+}
+
+// GetDuration returns how long pods are followed, or DefaultFollowAttackerDuration if no duration is set.
+func (f *FollowAttacker) GetDuration() time.Duration {
+	if f == nil || f.Duration == nil {
+		return DefaultFollowAttackerDuration
+	}
+
+	return f.Duration.Duration
+}
@@ -15,7 +15,18 @@
 
 package v1alpha1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
 
 // MatchResources is used to specify resource matching criteria for a trap.
 type MatchResources struct {
@@ -23,12 +34,79 @@ type MatchResources struct {
 	Any []ResourceFilter `json:"any,omitempty" yaml:"any,omitempty"`
 }
 
+// HasNodeConstraints returns true if any of the resource filters constrains the nodes of the matched pods.
+func (matchResources *MatchResources) HasNodeConstraints() bool {
+	for _, resourceFilter := range matchResources.Any {
+		if resourceFilter.HasNodeConstraints() {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetNamespaces returns the namespaces that all resource filters are restricted to, sorted and without duplicates.
+// The boolean is false if any resource filter (or the absence of filters) selects resources in all namespaces.
+func (matchResources *MatchResources) TargetNamespaces() ([]string, bool) {
+	if len(matchResources.Any) == 0 {
+		return nil, false
+	}
+
+	var namespaces []string
+	for _, resourceFilter := range matchResources.Any {
+		if len(resourceFilter.Namespaces) == 0 {
+			return nil, false
+		}
+		namespaces = append(namespaces, resourceFilter.Namespaces...)
+	}
+
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces), true
+}
+
 // ResourceFilter allow users to "AND" or "OR" between resources
 type ResourceFilter struct {
 	// ResourceDescription contains information about the resource being created or modified.
 	ResourceDescription `json:"resources,omitempty" yaml:"resources,omitempty"`
 }
 
+const (
+	// DeploymentWorkload references a Deployment.
+	DeploymentWorkload = "Deployment"
+
+	// StatefulSetWorkload references a StatefulSet.
+	StatefulSetWorkload = "StatefulSet"
+
+	// DaemonSetWorkload references a DaemonSet.
+	DaemonSetWorkload = "DaemonSet"
+)
+
+// WorkloadReference references a workload by its kind and name.
+type WorkloadReference struct {
+	// Kind is the kind of the workload.
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Name is the name of the workload.
+	// It does not support wildcards.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name" yaml:"name"`
+}
+
+// IsValid checks if the workload reference has a supported kind and a name.
+func (workload *WorkloadReference) IsValid() error {
+	switch workload.Kind {
+	case DeploymentWorkload, StatefulSetWorkload, DaemonSetWorkload:
+	default:
+		return fmt.Errorf("workload kind '%s' is not supported", workload.Kind)
+	}
+
+	if workload.Name == "" {
+		return errors.New("workload name is empty")
+	}
+
+	return nil
+}
+
 type ResourceDescription struct {
 	// Namespaces is a list of namespaces names.
 	// It does not support wildcards.
@@ -40,6 +118,23 @@ type ResourceDescription struct {
 	// +optional
 	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
 
+	// Workloads is a list of workloads whose pods are selected, by their kind and name.
+	// The pods are resolved with the pod selector of the workload, so new pods of the workload are selected after rollouts.
+	// Workloads are looked up in the namespaces of this filter, or in all namespaces if no namespaces are given.
+	// +optional
+	Workloads []WorkloadReference `json:"workloads,omitempty" yaml:"workloads,omitempty"`
+
+	// NodeSelector only selects pods that are scheduled to nodes with all of these labels.
+	// It is only supported with the containerExec decoy deployment strategy.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+
+	// NodeAffinity only selects pods that are scheduled to nodes that match at least one of the node selector terms,
+	// with the same semantics as requiredDuringSchedulingIgnoredDuringExecution in the node affinity of pods.
+	// It is only supported with the containerExec decoy deployment strategy.
+	// +optional
+	NodeAffinity *corev1.NodeSelector `json:"nodeAffinity,omitempty" yaml:"nodeAffinity,omitempty"`
+
 	// ContainerSelector is a selector to filter the containers to inject the trap into.
 	// Valid values are:
 	//   - "" (empty string): selects all containers
@@ -47,7 +142,122 @@ type ResourceDescription struct {
 	//   - "regex:<pattern>": selects containers whose name matches the regex pattern (e.g. "regex:.*" for all)
 	//   - "<name>": selects the container with the exact given name
 	// Note: a bare "*" is NOT a wildcard — it is treated as a literal container name. Use "glob:*" instead.
+	// Well-known injected sidecars (see Containers.IncludeSidecars) are not selected, unless they are selected by their exact name.
 	// +optional
 	// +kubebuilder:default=""
 	ContainerSelector string `json:"containerSelector,omitempty" yaml:"containerSelector,omitempty"`
+
+	// ImageSelector only selects containers whose image matches, with the same pattern syntax as ContainerSelector.
+	// Exact values match the full image reference (e.g. "postgres:16") or its repository (e.g. "postgres"),
+	// regex patterns are searched in the image reference, and glob patterns must match the full image reference
+	// (e.g. "glob:*/*/postgres:*", since "*" does not match the "/" between the path components of the image).
+	// +optional
+	ImageSelector string `json:"imageSelector,omitempty" yaml:"imageSelector,omitempty"`
+
+	// Containers selects containers with multiple patterns, with allow and deny semantics.
+	// Its included patterns cannot be combined with a non-empty ContainerSelector, but its excluded patterns also apply to it.
+	// +optional
+	Containers *ContainerSelection `json:"containers,omitempty" yaml:"containers,omitempty"`
+}
+
+// MatchContainerImage returns true if a container with the given image is selected by the image selector.
+// All images are selected if there is no image selector.
+func (description *ResourceDescription) MatchContainerImage(image string) (bool, error) {
+	return utils.MatchContainerImage(description.ImageSelector, image)
+}
+
+// SidecarContainerNames are the names of well-known sidecars that service meshes inject into pods.
+// They are not selected by container selectors, unless they are selected by their exact name or sidecars are included explicitly.
+var SidecarContainerNames = []string{"envoy", "istio-proxy", "linkerd-proxy"}
+
+// ContainerSelection selects containers by their names, with the same pattern syntax as ContainerSelector.
+type ContainerSelection struct {
+	// Include is a list of container name patterns. A container is selected if it matches any of them.
+	// If it is empty, all containers are selected.
+	// +optional
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude is a list of container name patterns. A container that matches any of them is never selected,
+	// even if it matches one of the included patterns.
+	// +optional
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
+	// IncludeSidecars also selects well-known injected sidecars (envoy, istio-proxy, linkerd-proxy),
+	// which are excluded by default, unless they are included by their exact name.
+	// +optional
+	IncludeSidecars bool `json:"includeSidecars,omitempty" yaml:"includeSidecars,omitempty"`
+}
+
+// HasNodeConstraints returns true if the resource description constrains the nodes of the matched pods.
+func (description *ResourceDescription) HasNodeConstraints() bool {
+	return len(description.NodeSelector) > 0 || description.NodeAffinity != nil
+}
+
+// ContainerIncludes returns the patterns of the containers that are selected. If it is empty, all containers are selected.
+func (description *ResourceDescription) ContainerIncludes() []string {
+	if description.ContainerSelector != "" {
+		return []string{description.ContainerSelector}
+	}
+	if description.Containers == nil {
+		return nil
+	}
+
+	includes := make([]string, 0, len(description.Containers.Include))
+	for _, pattern := range description.Containers.Include {
+		if pattern != "" {
+			includes = append(includes, pattern)
+		}
+	}
+	return includes
+}
+
+// ContainerExcludes returns the patterns of the containers that are never selected,
+// i.e., the excluded patterns and the sidecars that are not included by their exact name.
+func (description *ResourceDescription) ContainerExcludes() []string {
+	excludes := []string{}
+	includeSidecars := false
+	if description.Containers != nil {
+		excludes = append(excludes, description.Containers.Exclude...)
+		includeSidecars = description.Containers.IncludeSidecars
+	}
+
+	if !includeSidecars {
+		includes := description.ContainerIncludes()
+		for _, sidecar := range SidecarContainerNames {
+			if !slices.Contains(includes, sidecar) && !slices.Contains(excludes, sidecar) {
+				excludes = append(excludes, sidecar)
+			}
+		}
+	}
+
+	return excludes
+}
+
+// MatchContainer returns true if a container with the given name is selected,
+// i.e., if it matches none of the excluded patterns and any of the included patterns.
+func (description *ResourceDescription) MatchContainer(containerName string) (bool, error) {
+	// The watcher sidecars of Koney's captors are never selected, they only mount the decoys of other containers
+	if strings.HasPrefix(containerName, constants.CaptorSidecarNamePrefix) {
+		return false, nil
+	}
+
+	for _, pattern := range description.ContainerExcludes() {
+		matched, err := utils.MatchContainerName(pattern, containerName)
+		if err != nil || matched {
+			return false, err
+		}
+	}
+
+	includes := description.ContainerIncludes()
+	if len(includes) == 0 {
+		return true, nil
+	}
+	for _, pattern := range includes {
+		matched, err := utils.MatchContainerName(pattern, containerName)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
 }
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Quarantine configures how pods that access a trap are isolated from the network.
+type Quarantine struct {
+	// TTL is how long a pod stays quarantined. Once it passed, the quarantine is lifted automatically.
+	// If not set, pods stay quarantined until the koney/quarantined label is removed from them.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+
+	// CiliumNetworkPolicy additionally creates a CiliumNetworkPolicy that denies all traffic of the pod.
+	// Unlike a Kubernetes NetworkPolicy, its deny rules take precedence over other policies that allow traffic.
+	// This requires Cilium as the network plugin.
+	// +optional
+	CiliumNetworkPolicy bool `json:"ciliumNetworkPolicy,omitempty" yaml:"ciliumNetworkPolicy,omitempty"`
+}
+
+// IsValid checks if the quarantine configuration is valid.
+func (q *Quarantine) IsValid() error {
+	if q.TTL != nil && q.TTL.Duration <= 0 {
+		return fmt.Errorf("Quarantine.TTL must be positive, but is '%s'", q.TTL.Duration)
+	}
+
+	return nil
+}
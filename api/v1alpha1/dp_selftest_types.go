@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+// DefaultSelfTestSchedule is the schedule of the self-tests of a DeceptionPolicy if no other schedule is set.
+const DefaultSelfTestSchedule = "0 * * * *"
+
+// SelfTest configures periodic self-tests of a DeceptionPolicy, which touch one of its honeytokens from a dedicated
+// test pod and check that the alert is captured, mapped, and forwarded to the sinks.
+type SelfTest struct {
+	// Schedule is when the self-tests run, in cron syntax.
+	// +optional
+	// +kubebuilder:default:="0 * * * *"
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+
+	// AlertOnFailure sends an alert to the sinks if a self-test fails, i.e., if the detection chain is broken.
+	// By default, it is set to true.
+	// +optional
+	// +kubebuilder:default:=true
+	AlertOnFailure *bool `json:"alertOnFailure,omitempty" yaml:"alertOnFailure,omitempty"`
+}
+
+// GetSchedule returns the schedule of the self-tests, or the default schedule if none is set.
+func (s *SelfTest) GetSchedule() string {
+	if s.Schedule == "" {
+		return DefaultSelfTestSchedule
+	}
+	return s.Schedule
+}
@@ -16,32 +16,297 @@
 package v1alpha1
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RotationTokenPlaceholder is replaced in the FileContent of a rotating honeytoken
+	// with a freshly generated token on every rotation.
+	RotationTokenPlaceholder = "{{ .Token }}"
+
+	// PlacementNamespacePlaceholder is replaced in the FileContent with the namespace of the pod in which the honeytoken is placed.
+	PlacementNamespacePlaceholder = "{{ .Namespace }}"
+
+	// PlacementPodNamePlaceholder is replaced in the FileContent with the name of the pod in which the honeytoken is placed.
+	PlacementPodNamePlaceholder = "{{ .PodName }}"
+
+	// PlacementServiceAccountPlaceholder is replaced in the FileContent with the service account of the pod in which the honeytoken is placed.
+	PlacementServiceAccountPlaceholder = "{{ .ServiceAccount }}"
+
+	// PlacementNodeNamePlaceholder is replaced in the FileContent with the node of the pod in which the honeytoken is placed.
+	PlacementNodeNamePlaceholder = "{{ .NodeName }}"
+
+	// PlacementIDPlaceholder is replaced in the FileContent with an ID that is unique for every pod and file path,
+	// so that each placed honeytoken has a unique value.
+	PlacementIDPlaceholder = "{{ .PlacementID }}"
+
+	// CanaryTokenURLPlaceholder is replaced in the FileContent with the URL of a canary token of kind "url".
+	CanaryTokenURLPlaceholder = "{{ .CanaryToken.URL }}"
+
+	// CanaryTokenHostnamePlaceholder is replaced in the FileContent with the hostname of a canary token of kind "url" or "dns".
+	CanaryTokenHostnamePlaceholder = "{{ .CanaryToken.Hostname }}"
+
+	// CanaryTokenAccessKeyIDPlaceholder is replaced in the FileContent with the access key ID of a canary token of kind "awsKeys".
+	CanaryTokenAccessKeyIDPlaceholder = "{{ .CanaryToken.AccessKeyID }}"
+
+	// CanaryTokenSecretAccessKeyPlaceholder is replaced in the FileContent with the secret access key of a canary token of kind "awsKeys".
+	CanaryTokenSecretAccessKeyPlaceholder = "{{ .CanaryToken.SecretAccessKey }}"
+
+	// CanaryTokenKindURL is a canary token that triggers when its URL is requested.
+	CanaryTokenKindURL = "url"
+
+	// CanaryTokenKindAWSKeys is a canary token that triggers when its AWS access key is used.
+	CanaryTokenKindAWSKeys = "awsKeys"
+
+	// CanaryTokenKindDNS is a canary token that triggers when its hostname is resolved.
+	CanaryTokenKindDNS = "dns"
+
+	// MinRotationInterval is the shortest interval at which honeytokens can be rotated.
+	MinRotationInterval = 1 * time.Minute
+
+	// MonitorPathWildcard is the wildcard that can be used at the start or at the end of a monitor path.
+	MonitorPathWildcard = "*"
+
+	// EnforcementActionNone only monitors writes to the honeytoken.
+	EnforcementActionNone = "None"
+
+	// EnforcementActionOverride blocks writes to the honeytoken, which fail with a permission error.
+	EnforcementActionOverride = "Override"
+
+	// EnforcementActionSigkill kills processes that try to write to the honeytoken.
+	EnforcementActionSigkill = "Sigkill"
 )
 
 // FilesystemHoneytoken defines the configuration for a filesystem honeytoken trap.
 type FilesystemHoneytoken struct {
 	// FilePath is the path of the file to be created.
-	FilePath string `json:"filePath" yaml:"filePath"`
+	// Either FilePath, FilePaths, or both must be set.
+	// +optional
+	FilePath string `json:"filePath,omitempty" yaml:"filePath,omitempty"`
+
+	// FilePaths are the paths of additional files to be created, all with the same content.
+	// This avoids defining near-duplicate traps that differ only in their file path.
+	// +optional
+	// +listType=set
+	FilePaths []string `json:"filePaths,omitempty" yaml:"filePaths,omitempty"`
+
+	// MonitorPaths are additional path patterns that are monitored, but for which no file is created.
+	// This allows alerting on any access under a decoy directory. A pattern is either an absolute path
+	// (e.g., "/var/backups/secrets/db.key"), an absolute path prefix ending with "*" (e.g., "/var/backups/secrets/*"),
+	// or a path suffix starting with "*" (e.g., "*.kdbx"). Prefixes and suffixes are only supported by Tetragon.
+	// +optional
+	// +listType=set
+	MonitorPaths []string `json:"monitorPaths,omitempty" yaml:"monitorPaths,omitempty"`
 
 	// FileContent is the content of the file to be created.
+	// With the "containerExec" and "ephemeralContainer" decoy deployment strategies, the placeholders "{{ .Namespace }}", "{{ .PodName }}",
+	// "{{ .ServiceAccount }}", "{{ .NodeName }}", and "{{ .PlacementID }}" are replaced with the context of the pod in which the file is created.
 	// +optional
 	// +kubebuilder:default=""
 	FileContent string `json:"fileContent" yaml:"fileContent"`
 
 	// ReadOnly is a flag to make the file read-only.
+	// If not set, the readOnly of the TrapDefaults of the DeceptionPolicy is used, or true otherwise.
 	// +optional
-	// +kubebuilder:default=true
-	ReadOnly bool `json:"readOnly" yaml:"readOnly"`
+	ReadOnly *bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+
+	// EnforcementAction is the action that the captor takes when a process tries to write to the honeytoken.
+	// "Override" blocks the write, and "Sigkill" kills the process. By default ("None"), writes are only monitored.
+	// Enforcement requires the "tetragon" captor deployment strategy and a read-only honeytoken,
+	// and it is not supported with decoy deployment strategies that write the honeytoken from inside the containers.
+	// +kubebuilder:validation:Enum=None;Override;Sigkill
+	// +optional
+	EnforcementAction string `json:"enforcementAction,omitempty" yaml:"enforcementAction,omitempty"`
+
+	// RotateEvery is the interval at which the honeytoken is rotated (e.g., "24h").
+	// On every rotation, the placeholder "{{ .Token }}" in the FileContent is replaced with a new token.
+	// If not set, the honeytoken is never rotated.
+	// +optional
+	RotateEvery *metav1.Duration `json:"rotateEvery,omitempty" yaml:"rotateEvery,omitempty"`
+
+	// CanaryToken provisions a token from a canarytokens-compatible service for every file path of the honeytoken,
+	// whose values replace the placeholders "{{ .CanaryToken.URL }}", "{{ .CanaryToken.Hostname }}",
+	// "{{ .CanaryToken.AccessKeyID }}", and "{{ .CanaryToken.SecretAccessKey }}" in the FileContent.
+	// The service alerts when the token is used, even outside of the cluster.
+	// +optional
+	CanaryToken *CanaryToken `json:"canaryToken,omitempty" yaml:"canaryToken,omitempty"`
+}
+
+// CanaryToken defines a token that is provisioned from a canarytokens-compatible service.
+type CanaryToken struct {
+	// Kind is the kind of the canary token: "url" (a URL and its hostname), "awsKeys" (an AWS access key), or "dns" (a hostname).
+	// +kubebuilder:validation:Enum=url;awsKeys;dns
+	Kind string `json:"kind" yaml:"kind"`
+}
+
+// canaryTokenPlaceholders are the placeholders that each kind of canary token provides values for.
+var canaryTokenPlaceholders = map[string][]string{
+	CanaryTokenKindURL:     {CanaryTokenURLPlaceholder, CanaryTokenHostnamePlaceholder},
+	CanaryTokenKindAWSKeys: {CanaryTokenAccessKeyIDPlaceholder, CanaryTokenSecretAccessKeyPlaceholder},
+	CanaryTokenKindDNS:     {CanaryTokenHostnamePlaceholder},
+}
+
+// IsZero returns true if the filesystem honeytoken is not configured at all.
+func (f *FilesystemHoneytoken) IsZero() bool {
+	return f.FilePath == "" && len(f.FilePaths) == 0 && len(f.MonitorPaths) == 0 &&
+		f.FileContent == "" && (f.ReadOnly == nil || !*f.ReadOnly) && f.RotateEvery == nil && f.EnforcementAction == "" && f.CanaryToken == nil
+}
+
+// IsReadOnly returns true if the file is read-only, which it is unless ReadOnly is set to false.
+func (f *FilesystemHoneytoken) IsReadOnly() bool {
+	return f.ReadOnly == nil || *f.ReadOnly
+}
+
+// placementContextPlaceholders are the placeholders that are replaced with the context of the pod in which the honeytoken is placed.
+var placementContextPlaceholders = []string{
+	PlacementNamespacePlaceholder,
+	PlacementPodNamePlaceholder,
+	PlacementServiceAccountPlaceholder,
+	PlacementNodeNamePlaceholder,
+	PlacementIDPlaceholder,
+}
+
+// UsesPlacementContext returns true if the FileContent references the context of the pod in which the honeytoken is placed.
+func (f *FilesystemHoneytoken) UsesPlacementContext() bool {
+	return slices.ContainsFunc(placementContextPlaceholders, func(placeholder string) bool {
+		return strings.Contains(f.FileContent, placeholder)
+	})
+}
+
+// UsesCanaryToken returns true if the honeytoken embeds a token that is provisioned from a canarytokens-compatible service.
+func (f *FilesystemHoneytoken) UsesCanaryToken() bool {
+	return f.CanaryToken != nil
+}
+
+// IsEnforcing returns true if the captor blocks writes to the honeytoken or kills the writing processes.
+func (f *FilesystemHoneytoken) IsEnforcing() bool {
+	return f.EnforcementAction != "" && f.EnforcementAction != EnforcementActionNone
+}
+
+// AllFilePaths returns the FilePath (if set), followed by all FilePaths, without duplicates.
+func (f *FilesystemHoneytoken) AllFilePaths() []string {
+	filePaths := make([]string, 0, 1+len(f.FilePaths))
+	if f.FilePath != "" {
+		filePaths = append(filePaths, f.FilePath)
+	}
+	for _, filePath := range f.FilePaths {
+		if !slices.Contains(filePaths, filePath) {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+
+	return filePaths
+}
+
+// MonitoredPaths returns all paths that must be monitored, grouped by how they are matched:
+// exact paths (all file paths and the monitor paths without wildcard), path prefixes, and path suffixes.
+// The wildcards are stripped from the returned prefixes and suffixes.
+func (f *FilesystemHoneytoken) MonitoredPaths() (exact, prefixes, suffixes []string) {
+	exact = f.AllFilePaths()
+	for _, monitorPath := range f.MonitorPaths {
+		switch {
+		case strings.HasSuffix(monitorPath, MonitorPathWildcard):
+			prefixes = append(prefixes, strings.TrimSuffix(monitorPath, MonitorPathWildcard))
+		case strings.HasPrefix(monitorPath, MonitorPathWildcard):
+			suffixes = append(suffixes, strings.TrimPrefix(monitorPath, MonitorPathWildcard))
+		case !slices.Contains(exact, monitorPath):
+			exact = append(exact, monitorPath)
+		}
+	}
+
+	return exact, prefixes, suffixes
+}
+
+// HasMonitorPathPatterns returns true if any monitor path is a prefix or suffix pattern.
+func (f *FilesystemHoneytoken) HasMonitorPathPatterns() bool {
+	_, prefixes, suffixes := f.MonitoredPaths()
+	return len(prefixes) > 0 || len(suffixes) > 0
 }
 
 // IsValid checks if the filesystem honeytoken trap is valid.
-// The file path must be absolute.
+// At least one file path must be set, all file paths must be absolute,
+// all monitor paths must be valid patterns, the rotation interval, if set, must not be too short,
+// and the content may only reference the canary token placeholders of the configured kind of canary token.
 func (f *FilesystemHoneytoken) IsValid() error {
-	// Check if the file path is absolute
-	if !filepath.IsAbs(f.FilePath) {
-		return fmt.Errorf("FilePath is not absolute: '%s'", f.FilePath)
+	filePaths := f.AllFilePaths()
+	if len(filePaths) == 0 {
+		return errors.New("either FilePath or FilePaths must be set")
+	}
+
+	// Check if the file paths are absolute
+	for _, filePath := range filePaths {
+		if !filepath.IsAbs(filePath) {
+			return fmt.Errorf("FilePath is not absolute: '%s'", filePath)
+		}
+	}
+
+	// Check if the monitor paths are valid patterns
+	for _, monitorPath := range f.MonitorPaths {
+		if err := validateMonitorPath(monitorPath); err != nil {
+			return err
+		}
+	}
+
+	// Check if the rotation interval is long enough
+	if f.RotateEvery != nil && f.RotateEvery.Duration < MinRotationInterval {
+		return fmt.Errorf("RotateEvery must be at least %s, but is '%s'", MinRotationInterval, f.RotateEvery.Duration)
+	}
+
+	// Writes to files that are not read-only are expected, so they should not be blocked
+	if f.IsEnforcing() && !f.IsReadOnly() {
+		return fmt.Errorf("EnforcementAction '%s' requires ReadOnly to be true", f.EnforcementAction)
+	}
+
+	// Canary tokens are provisioned once, so they cannot be rotated, and their placeholders need the matching kind of token
+	if f.CanaryToken != nil {
+		if _, ok := canaryTokenPlaceholders[f.CanaryToken.Kind]; !ok {
+			return fmt.Errorf("CanaryToken.Kind is unknown: '%s'", f.CanaryToken.Kind)
+		}
+		if f.RotateEvery != nil {
+			return errors.New("CanaryToken and RotateEvery cannot be set together")
+		}
+	}
+	for _, placeholder := range []string{CanaryTokenURLPlaceholder, CanaryTokenHostnamePlaceholder, CanaryTokenAccessKeyIDPlaceholder, CanaryTokenSecretAccessKeyPlaceholder} {
+		if !strings.Contains(f.FileContent, placeholder) {
+			continue
+		}
+		if f.CanaryToken == nil {
+			return fmt.Errorf("FileContent references '%s', which requires a CanaryToken", placeholder)
+		} else if !slices.Contains(canaryTokenPlaceholders[f.CanaryToken.Kind], placeholder) {
+			return fmt.Errorf("FileContent references '%s', which a CanaryToken of kind '%s' does not provide", placeholder, f.CanaryToken.Kind)
+		}
+	}
+
+	return nil
+}
+
+// validateMonitorPath checks that a monitor path is an absolute path, an absolute path prefix
+// ending with a wildcard, or a non-empty path suffix starting with a wildcard.
+func validateMonitorPath(monitorPath string) error {
+	pattern := monitorPath
+	switch {
+	case strings.HasSuffix(pattern, MonitorPathWildcard):
+		pattern = strings.TrimSuffix(pattern, MonitorPathWildcard)
+	case strings.HasPrefix(pattern, MonitorPathWildcard):
+		pattern = strings.TrimPrefix(pattern, MonitorPathWildcard)
+		if pattern == "" || strings.Contains(pattern, MonitorPathWildcard) {
+			return fmt.Errorf("MonitorPaths contains an invalid suffix pattern: '%s'", monitorPath)
+		}
+		return nil
+	}
+
+	if strings.Contains(pattern, MonitorPathWildcard) {
+		return fmt.Errorf("MonitorPaths may only contain a wildcard at the start or at the end: '%s'", monitorPath)
+	}
+	if !filepath.IsAbs(pattern) {
+		return fmt.Errorf("MonitorPaths contains a path that is not absolute: '%s'", monitorPath)
 	}
 
 	return nil
@@ -18,10 +18,33 @@ package v1alpha1
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/dynatrace-oss/koney/internal/controller/utils"
 )
 
+var (
+	// attckTechniquePattern matches the IDs of MITRE ATT&CK techniques and sub-techniques, like the CRD does.
+	attckTechniquePattern = regexp.MustCompile(`^T[0-9]{4}(\.[0-9]{3})?$`)
+
+	// engageActivityPattern matches the IDs of MITRE Engage activities, like the CRD does.
+	engageActivityPattern = regexp.MustCompile(`^EAC[0-9]{4}$`)
+
+	// trapSeverities are the valid severities of a trap, like the CRD enforces.
+	trapSeverities = []string{"info", "low", "medium", "high", "critical"}
+
+	// trapConfidences are the valid confidences of a trap, like the CRD enforces.
+	trapConfidences = []string{"low", "medium", "high"}
+
+	// sidecarCaptorDecoyStrategies are the decoy deployment strategies that mount a volume into deployments,
+	// which the watcher sidecar of the "sidecar" captor deployment strategy mounts as well.
+	sidecarCaptorDecoyStrategies = []string{"volumeMount", "projectedVolume", "imageVolume", "initContainer"}
+)
+
 // TrapType is a string representation of a trap type and can be used like an enum.
 type TrapType string
 
@@ -41,6 +64,38 @@ const (
 
 // Trap describes a cyber deception technique, also simply known as a trap.
 type Trap struct {
+	// Description is a human-readable description of the trap.
+	// It is propagated to the captors and to the emitted alerts, to explain why the trap exists.
+	// +optional
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// AttckTechniques are the IDs of the MITRE ATT&CK techniques that the trap detects (e.g., "T1552.001").
+	// They are propagated as labels to the captors and included in the emitted alerts.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Pattern=`^T[0-9]{4}(\.[0-9]{3})?$`
+	AttckTechniques []string `json:"attckTechniques,omitempty" yaml:"attckTechniques,omitempty"`
+
+	// EngageActivities are the IDs of the MITRE Engage activities that the trap implements (e.g., "EAC0005").
+	// They are propagated as labels to the captors and included in the emitted alerts.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Pattern=`^EAC[0-9]{4}$`
+	EngageActivities []string `json:"engageActivities,omitempty" yaml:"engageActivities,omitempty"`
+
+	// Severity is how severe an access to the trap is, i.e., "info", "low", "medium", "high", or "critical".
+	// It is propagated as a label to the captors and sets the severity of the emitted alerts,
+	// unless the alerting configuration overrides it.
+	// +kubebuilder:validation:Enum=info;low;medium;high;critical
+	// +optional
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
+	// Confidence is how likely an access to the trap is malicious, i.e., "low", "medium", or "high".
+	// It is propagated as a label to the captors and included in the emitted alerts.
+	// +kubebuilder:validation:Enum=low;medium;high
+	// +optional
+	Confidence string `json:"confidence,omitempty" yaml:"confidence,omitempty"`
+
 	// FilesystemHoneytoken is the configuration for a filesystem honeytoken trap.
 	// +optional
 	FilesystemHoneytoken FilesystemHoneytoken `json:"filesystemHoneytoken,omitempty" yaml:"spec,omitempty"`
@@ -65,12 +120,69 @@ type Trap struct {
 	// Matching criteria are resources labels and/or namespaces.
 	// +optional
 	MatchResources MatchResources `json:"match,omitempty" yaml:"match,omitempty"`
+
+	// Alerting configures the alerts that are emitted when this trap is accessed.
+	// +optional
+	Alerting *Alerting `json:"alerting,omitempty" yaml:"alerting,omitempty"`
+
+	// ResponseActions are taken automatically when this trap is accessed, to contain the attacker.
+	// "killProcess" kills the processes of the accessing binary in the container, "killPod" deletes the pod,
+	// "labelPod" labels the pod as quarantined, "networkIsolate" labels the pod and denies all its network traffic,
+	// and "followAttacker" deploys a Tetragon TracingPolicy that traces all executions and connections of the pod for a while.
+	// By default, no actions are taken and accesses are only alerted.
+	// +kubebuilder:validation:items:Enum=killProcess;killPod;labelPod;networkIsolate;followAttacker
+	// +listType=set
+	// +optional
+	ResponseActions []string `json:"responseActions,omitempty" yaml:"responseActions,omitempty"`
+
+	// Quarantine isolates pods that access this trap from the network, like the "networkIsolate" response action,
+	// but can lift the quarantine automatically and use a CiliumNetworkPolicy in addition.
+	// +optional
+	Quarantine *Quarantine `json:"quarantine,omitempty" yaml:"quarantine,omitempty"`
+
+	// FollowAttacker monitors pods that access this trap more closely, like the "followAttacker" response action,
+	// but can configure for how long.
+	// +optional
+	FollowAttacker *FollowAttacker `json:"followAttacker,omitempty" yaml:"followAttacker,omitempty"`
+
+	// TTL is the time to live of the trap, counted from when the trap was first deployed (see deployedAt in its status).
+	// Once it passed, the trap is removed automatically (e.g., for time-boxed red-team exercises).
+	// Changing the trap restarts its TTL, since the changed trap is deployed anew.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+
+	// ExpiresAt is the point in time when the trap is removed automatically.
+	// If both TTL and ExpiresAt are set, the trap expires at whichever comes first.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+
+	// TemplateRef references a TrapTemplate that this trap is expanded from, with the values of its parameters.
+	// All other fields that are set in this trap take precedence over the fields of the template.
+	// Trap defaults are applied to the expanded trap, not to this trap.
+	// +optional
+	TemplateRef *TrapTemplateReference `json:"templateRef,omitempty" yaml:"templateRef,omitempty"`
+}
+
+// AllResponseActions returns the response actions of the trap, including "networkIsolate" if the trap quarantines pods
+// and "followAttacker" if the trap follows the attacker.
+func (trap *Trap) AllResponseActions() []string {
+	actions := trap.ResponseActions
+	if trap.Quarantine != nil && !slices.Contains(actions, "networkIsolate") {
+		actions = append(slices.Clone(actions), "networkIsolate")
+	}
+	if trap.FollowAttacker != nil && !slices.Contains(actions, "followAttacker") {
+		actions = append(slices.Clone(actions), "followAttacker")
+	}
+
+	return actions
 }
 
 // TrapType returns the type of trap.
 func (trap *Trap) TrapType() TrapType {
 	switch {
-	case trap.FilesystemHoneytoken != FilesystemHoneytoken{}:
+	case !trap.FilesystemHoneytoken.IsZero():
 		return FilesystemHoneytokenTrap
 	case trap.HttpEndpoint != HttpEndpoint{}:
 		return HttpEndpointTrap
@@ -81,31 +193,151 @@ func (trap *Trap) TrapType() TrapType {
 	}
 }
 
+// ExpirationTime returns the time when the trap expires, given the time when it was first deployed
+// (or nil if it was not deployed yet, so that its TTL did not start yet).
+// The second return value is false if the trap does not expire (yet).
+func (trap *Trap) ExpirationTime(deployedAt *metav1.Time) (time.Time, bool) {
+	var expiresAt time.Time
+	expires := false
+
+	if trap.TTL != nil && deployedAt != nil {
+		expiresAt = deployedAt.Add(trap.TTL.Duration)
+		expires = true
+	}
+	if trap.ExpiresAt != nil && (!expires || trap.ExpiresAt.Time.Before(expiresAt)) {
+		expiresAt = trap.ExpiresAt.Time
+		expires = true
+	}
+
+	return expiresAt, expires
+}
+
+// IsExpired returns true if the trap is expired at the given time, given the time when it was first deployed (or nil).
+func (trap *Trap) IsExpired(deployedAt *metav1.Time, now time.Time) bool {
+	expiresAt, expires := trap.ExpirationTime(deployedAt)
+	return expires && !now.Before(expiresAt)
+}
+
 // IsValid checks if the trap specification is valid.
-// The MatchResources field must include at least one of the MatchResources.Any.Namespaces or MatchResources.Any.Selector.
+// The MatchResources field must include at least one of the MatchResources.Any.Namespaces, MatchResources.Any.Selector,
+// or MatchResources.Any.Workloads.
 // Also, each individual trap will be validated as well. Note that only one trap can be specified at a time.
 func (trap *Trap) IsValid() error {
+	// Traps that still reference a template could not be expanded (e.g., because the template does not exist)
+	if trap.TemplateRef != nil {
+		return fmt.Errorf("TrapTemplate '%s' cannot be expanded", trap.TemplateRef.Name)
+	}
+
 	if trap.MatchResources.Any == nil {
 		return errors.New("MatchResources.Any is nil")
 	}
 
 	for _, value := range trap.MatchResources.Any {
-		if value.Namespaces == nil && value.Selector == nil {
-			return errors.New("MatchResources.Any.Namespaces and MatchResources.Any.Selector are nil")
+		if value.Namespaces == nil && value.Selector == nil && value.Workloads == nil {
+			return errors.New("MatchResources.Any.Namespaces, MatchResources.Any.Selector, and MatchResources.Any.Workloads are nil")
 		}
 
-		if len(value.Namespaces) == 0 && len(value.Selector.MatchLabels) == 0 {
-			return errors.New("MatchResources.Any.Namespaces and MatchResources.Any.Selector are empty")
+		if len(value.Namespaces) == 0 && (value.Selector == nil || len(value.Selector.MatchLabels) == 0) && len(value.Workloads) == 0 {
+			return errors.New("MatchResources.Any.Namespaces, MatchResources.Any.Selector, and MatchResources.Any.Workloads are empty")
+		}
+
+		for _, workload := range value.Workloads {
+			if err := workload.IsValid(); err != nil {
+				return fmt.Errorf("MatchResources.Any.Workloads is invalid: %w", err)
+			}
+		}
+
+		if value.NodeAffinity != nil && len(value.NodeAffinity.NodeSelectorTerms) == 0 {
+			return errors.New("MatchResources.Any.NodeAffinity has no node selector terms")
+		}
+
+		// Deployments are not bound to nodes, only their pods are, and pods are not scheduled yet when they are admitted
+		// (the "auto" strategy might choose the "projectedVolume" strategy)
+		if value.HasNodeConstraints() && (trap.DecoyDeployment.Strategy == "volumeMount" || trap.DecoyDeployment.Strategy == "projectedVolume" ||
+			trap.DecoyDeployment.Strategy == "imageVolume" || trap.DecoyDeployment.Strategy == "initContainer" || trap.DecoyDeployment.Strategy == "admission" ||
+			trap.DecoyDeployment.Strategy == "auto") {
+			return fmt.Errorf("MatchResources.Any.NodeSelector and MatchResources.Any.NodeAffinity are not supported with the '%s' decoy deployment strategy", trap.DecoyDeployment.Strategy)
 		}
 
 		_, err := utils.MatchContainerName(value.ContainerSelector, "test")
 		if err != nil {
 			return fmt.Errorf("MatchResources.Any.ContainerSelector is not a valid expression: %w", err)
 		}
+
+		if _, err := utils.MatchContainerImage(value.ImageSelector, "test"); err != nil {
+			return fmt.Errorf("MatchResources.Any.ImageSelector is not a valid expression: %w", err)
+		}
+
+		if value.Containers != nil {
+			if value.ContainerSelector != "" && len(value.Containers.Include) > 0 {
+				return errors.New("MatchResources.Any.ContainerSelector and MatchResources.Any.Containers.Include cannot be combined")
+			}
+			for _, pattern := range slices.Concat(value.Containers.Include, value.Containers.Exclude) {
+				if _, err := utils.MatchContainerName(pattern, "test"); err != nil {
+					return fmt.Errorf("MatchResources.Any.Containers has an invalid expression '%s': %w", pattern, err)
+				}
+			}
+		}
+	}
+
+	if err := trap.DecoyDeployment.IsValid(); err != nil {
+		return err
+	}
+
+	// Traps that are expanded from a TrapTemplate were not validated by the CRD of the DeceptionPolicy
+	for _, technique := range trap.AttckTechniques {
+		if !attckTechniquePattern.MatchString(technique) {
+			return fmt.Errorf("AttckTechniques has an invalid technique ID '%s'", technique)
+		}
+	}
+	for _, activity := range trap.EngageActivities {
+		if !engageActivityPattern.MatchString(activity) {
+			return fmt.Errorf("EngageActivities has an invalid activity ID '%s'", activity)
+		}
+	}
+	if trap.Severity != "" && !slices.Contains(trapSeverities, trap.Severity) {
+		return fmt.Errorf("Severity must be one of %v, but is '%s'", trapSeverities, trap.Severity)
+	}
+	if trap.Confidence != "" && !slices.Contains(trapConfidences, trap.Confidence) {
+		return fmt.Errorf("Confidence must be one of %v, but is '%s'", trapConfidences, trap.Confidence)
+	}
+
+	if trap.TTL != nil && trap.TTL.Duration <= 0 {
+		return fmt.Errorf("TTL must be positive, but is '%s'", trap.TTL.Duration)
+	}
+
+	if trap.Quarantine != nil {
+		if err := trap.Quarantine.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if trap.FollowAttacker != nil {
+		if err := trap.FollowAttacker.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if trap.CaptorDeployment.Strategy != "" && len(trap.CaptorDeployment.Strategies) > 0 {
+		return errors.New("CaptorDeployment.Strategy and CaptorDeployment.Strategies cannot be set together")
+	}
+	if slices.Contains(trap.CaptorDeployment.Strategies, "none") {
+		return errors.New("CaptorDeployment.Strategies cannot contain 'none'")
+	}
+	for i, strategy := range trap.CaptorDeployment.Strategies {
+		if slices.Contains(trap.CaptorDeployment.Strategies[:i], strategy) {
+			return fmt.Errorf("CaptorDeployment.Strategies contains '%s' more than once", strategy)
+		}
+	}
+
+	if trap.CaptorDeployment.RateLimit != nil {
+		if err := trap.CaptorDeployment.RateLimit.IsValid(); err != nil {
+			return err
+		}
 	}
 
 	numTraps := 0
-	if (trap.FilesystemHoneytoken != FilesystemHoneytoken{}) {
+	if !trap.FilesystemHoneytoken.IsZero() {
 		numTraps += 1
 	}
 	if (trap.HttpEndpoint != HttpEndpoint{}) {
@@ -124,6 +356,34 @@ func (trap *Trap) IsValid() error {
 		if err := trap.FilesystemHoneytoken.IsValid(); err != nil {
 			return err
 		}
+
+		// Only Tetragon can block writes, and Koney's own writes from inside the containers would be blocked as well
+		if trap.FilesystemHoneytoken.IsEnforcing() {
+			for _, strategy := range trap.CaptorDeployment.AllStrategies() {
+				if strategy != "tetragon" {
+					return fmt.Errorf("FilesystemHoneytoken.EnforcementAction is not supported with the '%s' captor deployment strategy", strategy)
+				}
+			}
+		}
+		if trap.FilesystemHoneytoken.IsEnforcing() && (trap.DecoyDeployment.Strategy == "containerExec" || trap.DecoyDeployment.Strategy == "ephemeralContainer" ||
+			trap.DecoyDeployment.Strategy == "auto") {
+			return fmt.Errorf("FilesystemHoneytoken.EnforcementAction is not supported with the '%s' decoy deployment strategy", trap.DecoyDeployment.Strategy)
+		}
+		// The imageVolume strategy mounts the decoy file from the image, so there is no way to place a given content
+		if trap.DecoyDeployment.Strategy == "imageVolume" && trap.FilesystemHoneytoken.FileContent != "" {
+			return errors.New("FilesystemHoneytoken.FileContent is not supported with the 'imageVolume' decoy deployment strategy, the content is taken from the image")
+		}
+		if trap.DecoyDeployment.Strategy == "imageVolume" && trap.FilesystemHoneytoken.CanaryToken != nil {
+			return errors.New("FilesystemHoneytoken.CanaryToken is not supported with the 'imageVolume' decoy deployment strategy, the content is taken from the image")
+		}
+		// Only strategies that write the honeytoken into a pod know the pod that the placeholders refer to
+		if trap.FilesystemHoneytoken.UsesPlacementContext() && trap.DecoyDeployment.Strategy != "containerExec" && trap.DecoyDeployment.Strategy != "ephemeralContainer" {
+			return fmt.Errorf("FilesystemHoneytoken.FileContent can only reference the placement context with the 'containerExec' or 'ephemeralContainer' decoy deployment strategy, not with '%s'",
+				trap.DecoyDeployment.Strategy)
+		}
+		if trap.CaptorDeployment.HasStrategy("sidecar") && trap.DecoyDeployment.Strategy != "" && !slices.Contains(sidecarCaptorDecoyStrategies, trap.DecoyDeployment.Strategy) {
+			return fmt.Errorf("the 'sidecar' captor deployment strategy is not supported with the '%s' decoy deployment strategy", trap.DecoyDeployment.Strategy)
+		}
 	case HttpEndpointTrap:
 		if err := trap.HttpEndpoint.IsValid(); err != nil {
 			return err
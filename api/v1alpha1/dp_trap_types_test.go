@@ -16,8 +16,11 @@
 package v1alpha1
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -66,7 +69,7 @@ func initializeTestTraps() {
 						FilesystemHoneytoken: FilesystemHoneytoken{
 							FilePath:    "/run/secrets/koney/service_token",
 							FileContent: "{\"service_token\":\"🐢\"}",
-							ReadOnly:    true,
+							ReadOnly:    &[]bool{true}[0],
 						},
 						DecoyDeployment: DecoyDeployment{
 							Strategy: deploymentStrategy,
@@ -159,6 +162,82 @@ var _ = Describe("IsValid", func() {
 		})
 	})
 
+	Context("when checking a trap that only references workloads", func() {
+		It("should be valid", func() {
+			for _, trap := range testTraps {
+				trap.MatchResources = MatchResources{
+					Any: []ResourceFilter{
+						{ResourceDescription: ResourceDescription{Workloads: []WorkloadReference{{Kind: "StatefulSet", Name: "db"}}}},
+					},
+				}
+				Expect(trap.IsValid()).To(Succeed())
+			}
+		})
+
+		It("should return error for unsupported workload kinds", func() {
+			for _, trap := range testTraps {
+				trap.MatchResources = MatchResources{
+					Any: []ResourceFilter{
+						{ResourceDescription: ResourceDescription{Workloads: []WorkloadReference{{Kind: "CronJob", Name: "backup"}}}},
+					},
+				}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("is not supported"))
+			}
+		})
+	})
+
+	Context("when checking a trap with node constraints", func() {
+		nodeConstrained := func(trap Trap, strategy string) Trap {
+			trap.DecoyDeployment = DecoyDeployment{Strategy: strategy}
+			trap.MatchResources = MatchResources{
+				Any: []ResourceFilter{
+					{ResourceDescription: ResourceDescription{
+						Namespaces:   []string{"default"},
+						NodeSelector: map[string]string{"node-pool": "internet-facing"},
+					}},
+				},
+			}
+			return trap
+		}
+
+		It("should be valid with the containerExec strategy", func() {
+			for _, trap := range testTraps {
+				trap = nodeConstrained(trap, "containerExec")
+				Expect(trap.IsValid()).To(Succeed())
+			}
+		})
+
+		It("should return error with strategies that deploy to deployments", func() {
+			for _, trap := range testTraps {
+				trap = nodeConstrained(trap, "volumeMount")
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("are not supported with the 'volumeMount' decoy deployment strategy"))
+			}
+		})
+
+		It("should return error with the admission strategy", func() {
+			for _, trap := range testTraps {
+				trap = nodeConstrained(trap, "admission")
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("are not supported with the 'admission' decoy deployment strategy"))
+			}
+		})
+
+		It("should return error for a node affinity without terms", func() {
+			for _, trap := range testTraps {
+				trap = nodeConstrained(trap, "containerExec")
+				trap.MatchResources.Any[0].NodeAffinity = &corev1.NodeSelector{}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("has no node selector terms"))
+			}
+		})
+	})
+
 	Context("when checking a filesystem honeytoken trap with a non-absolute file path", func() {
 		It("should return error", func() {
 			for _, trap := range testTraps {
@@ -171,6 +250,346 @@ var _ = Describe("IsValid", func() {
 		})
 	})
 
+	Context("when checking a filesystem honeytoken trap with multiple file paths", func() {
+		It("should validate all file paths", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.FilePaths = []string{"/run/secrets/koney/other_token"}
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+				Expect(trap.FilesystemHoneytoken.AllFilePaths()).To(HaveLen(2))
+
+				trap.FilesystemHoneytoken.FilePath = ""
+				Expect(trap.TrapType()).To(Equal(FilesystemHoneytokenTrap))
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+
+				trap.FilesystemHoneytoken.FilePaths = []string{"relative/path"}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("is not absolute"))
+
+				trap.FilesystemHoneytoken.FilePaths = nil
+				err = trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("either FilePath or FilePaths must be set"))
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap with monitor paths", func() {
+		It("should accept absolute paths, prefixes, and suffixes", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.MonitorPaths = []string{"/var/backups/secrets/*", "*.kdbx", "/etc/shadow"}
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+
+				exact, prefixes, suffixes := trap.FilesystemHoneytoken.MonitoredPaths()
+				Expect(exact).To(Equal([]string{trap.FilesystemHoneytoken.FilePath, "/etc/shadow"}))
+				Expect(prefixes).To(Equal([]string{"/var/backups/secrets/"}))
+				Expect(suffixes).To(Equal([]string{".kdbx"}))
+				Expect(trap.FilesystemHoneytoken.HasMonitorPathPatterns()).To(BeTrue())
+			}
+		})
+
+		It("should reject invalid patterns", func() {
+			for _, trap := range testTraps {
+				for _, monitorPath := range []string{"*", "relative/*", "/var/*/secrets", "*.tar.*"} {
+					trap.FilesystemHoneytoken.MonitorPaths = []string{monitorPath}
+					err := trap.IsValid()
+					Expect(err).Should(HaveOccurred(), monitorPath)
+					Expect(err.Error()).Should(ContainSubstring("MonitorPaths"))
+				}
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap with a too short rotation interval", func() {
+		It("should return error", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.RotateEvery = &metav1.Duration{Duration: MinRotationInterval / 2}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("RotateEvery must be at least"))
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap whose content references the placement context", func() {
+		It("should be valid with strategies that write into pods", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.FileContent = "key_id = " + PlacementNamespacePlaceholder + "-" + PlacementIDPlaceholder
+				Expect(trap.FilesystemHoneytoken.UsesPlacementContext()).To(BeTrue())
+				for _, strategy := range []string{"containerExec", "ephemeralContainer"} {
+					trap.DecoyDeployment = DecoyDeployment{Strategy: strategy}
+					Expect(trap.IsValid()).ShouldNot(HaveOccurred(), strategy)
+				}
+			}
+		})
+
+		It("should return error with other strategies", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.FileContent = "pod = " + PlacementPodNamePlaceholder
+				for _, strategy := range []string{"volumeMount", "projectedVolume", "initContainer", "admission", "auto"} {
+					trap.DecoyDeployment = DecoyDeployment{Strategy: strategy}
+					err := trap.IsValid()
+					Expect(err).Should(HaveOccurred(), strategy)
+					Expect(err.Error()).Should(ContainSubstring("placement context"))
+				}
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap that embeds a canary token", func() {
+		It("should be valid with the placeholders of its kind", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.FileContent = "aws_access_key_id = " + CanaryTokenAccessKeyIDPlaceholder + "\naws_secret_access_key = " + CanaryTokenSecretAccessKeyPlaceholder
+				trap.FilesystemHoneytoken.CanaryToken = &CanaryToken{Kind: CanaryTokenKindAWSKeys}
+				Expect(trap.FilesystemHoneytoken.UsesCanaryToken()).To(BeTrue())
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+			}
+		})
+
+		It("should return error for unknown kinds", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.CanaryToken = &CanaryToken{Kind: "qr"}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("CanaryToken.Kind is unknown"))
+			}
+		})
+
+		It("should return error for placeholders that its kind does not provide", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.FileContent = "url = " + CanaryTokenURLPlaceholder
+				trap.FilesystemHoneytoken.CanaryToken = &CanaryToken{Kind: CanaryTokenKindDNS}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("does not provide"))
+			}
+		})
+
+		It("should return error for placeholders without a canary token", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.FileContent = "host = " + CanaryTokenHostnamePlaceholder
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("requires a CanaryToken"))
+			}
+		})
+
+		It("should return error if the honeytoken is also rotated", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.CanaryToken = &CanaryToken{Kind: CanaryTokenKindURL}
+				trap.FilesystemHoneytoken.RotateEvery = &metav1.Duration{Duration: MinRotationInterval}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("cannot be set together"))
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap with an enforcement action", func() {
+		It("should be valid with a read-only honeytoken and the tetragon captor", func() {
+			for _, trap := range testTraps {
+				if trap.DecoyDeployment.Strategy == "containerExec" {
+					continue
+				}
+				for _, action := range []string{EnforcementActionNone, EnforcementActionOverride, EnforcementActionSigkill} {
+					trap.FilesystemHoneytoken.EnforcementAction = action
+					trap.CaptorDeployment = CaptorDeployment{Strategy: "tetragon"}
+					Expect(trap.IsValid()).ShouldNot(HaveOccurred(), action)
+				}
+			}
+		})
+
+		It("should return error for a writable honeytoken", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.EnforcementAction = EnforcementActionOverride
+				trap.FilesystemHoneytoken.ReadOnly = &[]bool{false}[0]
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("requires ReadOnly"))
+			}
+		})
+
+		It("should return error with other captor strategies", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.EnforcementAction = EnforcementActionSigkill
+				trap.CaptorDeployment = CaptorDeployment{Strategy: "kive"}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("captor deployment strategy"))
+			}
+		})
+
+		It("should return error with strategies that write from inside the containers", func() {
+			for _, trap := range testTraps {
+				trap.FilesystemHoneytoken.EnforcementAction = EnforcementActionOverride
+				for _, strategy := range []string{"containerExec", "ephemeralContainer", "auto"} {
+					trap.DecoyDeployment = DecoyDeployment{Strategy: strategy}
+					err := trap.IsValid()
+					Expect(err).Should(HaveOccurred(), strategy)
+					Expect(err.Error()).Should(ContainSubstring("decoy deployment strategy"))
+				}
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap with several captors", func() {
+		It("should be valid with distinct strategies", func() {
+			for _, trap := range testTraps {
+				trap.CaptorDeployment = CaptorDeployment{Strategies: []string{"tetragon", "kive"}}
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+				Expect(trap.CaptorDeployment.HasStrategy("kive")).To(BeTrue())
+			}
+		})
+
+		It("should return error for invalid lists of strategies", func() {
+			for _, trap := range testTraps {
+				for _, captorDeployment := range []CaptorDeployment{
+					{Strategy: "tetragon", Strategies: []string{"kive"}},
+					{Strategies: []string{"tetragon", "none"}},
+					{Strategies: []string{"kive", "kive"}},
+				} {
+					trap.CaptorDeployment = captorDeployment
+					Expect(trap.IsValid()).Should(HaveOccurred(), "%v", captorDeployment)
+				}
+			}
+		})
+
+		It("should only allow enforcement if all strategies are tetragon", func() {
+			for _, trap := range testTraps {
+				if trap.DecoyDeployment.Strategy == "containerExec" {
+					continue
+				}
+				trap.FilesystemHoneytoken.EnforcementAction = EnforcementActionOverride
+				trap.CaptorDeployment = CaptorDeployment{Strategies: []string{"tetragon", "kive"}}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("'kive' captor deployment strategy"))
+			}
+		})
+	})
+
+	Context("when checking a filesystem honeytoken trap with the sidecar captor", func() {
+		It("should be valid with strategies that mount a volume into deployments", func() {
+			for _, trap := range testTraps {
+				trap.CaptorDeployment = CaptorDeployment{Strategy: "sidecar"}
+				for _, strategy := range []string{"", "volumeMount", "projectedVolume", "initContainer"} {
+					trap.DecoyDeployment = DecoyDeployment{Strategy: strategy}
+					Expect(trap.IsValid()).ShouldNot(HaveOccurred(), strategy)
+				}
+			}
+		})
+
+		It("should return error with strategies that do not mount a volume into deployments", func() {
+			for _, trap := range testTraps {
+				trap.CaptorDeployment = CaptorDeployment{Strategy: "sidecar"}
+				for _, strategy := range []string{"containerExec", "ephemeralContainer", "admission", "auto"} {
+					trap.DecoyDeployment = DecoyDeployment{Strategy: strategy}
+					err := trap.IsValid()
+					Expect(err).Should(HaveOccurred(), strategy)
+					Expect(err.Error()).Should(ContainSubstring("'sidecar' captor deployment strategy"))
+				}
+			}
+		})
+	})
+
+	Context("when checking a trap with the imageVolume strategy but without an image", func() {
+		It("should return error", func() {
+			for _, trap := range testTraps {
+				trap.DecoyDeployment = DecoyDeployment{Strategy: "imageVolume"}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("ImageVolume.Image is required"))
+
+				trap.DecoyDeployment.ImageVolume = &ImageVolumeDecoy{Image: "registry.example.com/koney/decoys:latest"}
+				trap.FilesystemHoneytoken.FileContent = ""
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when checking a trap with the imageVolume strategy and a file content", func() {
+		It("should return error, since the content is taken from the image", func() {
+			for _, trap := range testTraps {
+				trap.DecoyDeployment = DecoyDeployment{Strategy: "imageVolume", ImageVolume: &ImageVolumeDecoy{Image: "registry.example.com/koney/decoys:latest"}}
+				trap.FilesystemHoneytoken.FileContent = "someverysecrettoken"
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("FileContent is not supported with the 'imageVolume' decoy deployment strategy"))
+			}
+		})
+	})
+
+	Context("when checking a trap with a non-positive TTL", func() {
+		It("should return error", func() {
+			for _, trap := range testTraps {
+				trap.TTL = &metav1.Duration{Duration: 0}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("TTL must be positive"))
+			}
+		})
+	})
+
+	Context("when checking a trap with MITRE ATT&CK techniques and Engage activities", func() {
+		It("should accept valid IDs and reject invalid ones", func() {
+			for _, trap := range testTraps {
+				trap.AttckTechniques = []string{"T1552", "T1552.001"}
+				trap.EngageActivities = []string{"EAC0005"}
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+
+				trap.AttckTechniques = []string{"Unsecured Credentials"}
+				Expect(trap.IsValid()).Should(MatchError(ContainSubstring("invalid technique ID")))
+
+				trap.AttckTechniques = nil
+				trap.EngageActivities = []string{"Lures"}
+				Expect(trap.IsValid()).Should(MatchError(ContainSubstring("invalid activity ID")))
+			}
+		})
+	})
+
+	Context("when checking a trap with a severity and confidence", func() {
+		It("should accept known values and reject unknown ones", func() {
+			for _, trap := range testTraps {
+				trap.Severity = "critical"
+				trap.Confidence = "high"
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+
+				trap.Severity = "CRITICAL"
+				Expect(trap.IsValid()).Should(MatchError(ContainSubstring("Severity must be one of")))
+
+				trap.Severity = ""
+				trap.Confidence = "certain"
+				Expect(trap.IsValid()).Should(MatchError(ContainSubstring("Confidence must be one of")))
+			}
+		})
+	})
+
+	Context("when checking a trap with a non-positive quarantine TTL", func() {
+		It("should return error", func() {
+			for _, trap := range testTraps {
+				trap.Quarantine = &Quarantine{TTL: &metav1.Duration{Duration: -time.Minute}}
+				err := trap.IsValid()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("Quarantine.TTL must be positive"))
+
+				trap.Quarantine.TTL = nil
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when checking a trap with a captor rate limit below one second", func() {
+		It("should return error", func() {
+			for _, trap := range testTraps {
+				trap.CaptorDeployment.RateLimit = &CaptorRateLimit{Period: metav1.Duration{Duration: 500 * time.Millisecond}}
+				Expect(trap.IsValid()).Should(MatchError(ContainSubstring("CaptorDeployment.RateLimit.Period must be at least 1s")))
+
+				trap.CaptorDeployment.RateLimit.Period.Duration = time.Minute
+				Expect(trap.IsValid()).ShouldNot(HaveOccurred())
+			}
+		})
+	})
+
 	Context("when checking a trap with captor strategy 'none'", func() {
 		It("should be valid", func() {
 			for _, trap := range testTraps {
@@ -181,3 +600,79 @@ var _ = Describe("IsValid", func() {
 		})
 	})
 })
+
+var _ = Describe("AllResponseActions", func() {
+	It("should return the response actions of the trap", func() {
+		trap := Trap{ResponseActions: []string{"labelPod"}}
+		Expect(trap.AllResponseActions()).To(Equal([]string{"labelPod"}))
+	})
+
+	It("should isolate pods if the trap quarantines them", func() {
+		trap := Trap{ResponseActions: []string{"labelPod"}, Quarantine: &Quarantine{}}
+		Expect(trap.AllResponseActions()).To(Equal([]string{"labelPod", "networkIsolate"}))
+		Expect(trap.ResponseActions).To(Equal([]string{"labelPod"}))
+
+		trap.ResponseActions = []string{"networkIsolate"}
+		Expect(trap.AllResponseActions()).To(Equal([]string{"networkIsolate"}))
+	})
+
+	It("should follow the attacker if the trap configures it", func() {
+		trap := Trap{ResponseActions: []string{"killProcess"}, FollowAttacker: &FollowAttacker{}}
+		Expect(trap.AllResponseActions()).To(Equal([]string{"killProcess", "followAttacker"}))
+		Expect(trap.ResponseActions).To(Equal([]string{"killProcess"}))
+		Expect(trap.FollowAttacker.GetDuration()).To(Equal(DefaultFollowAttackerDuration))
+	})
+})
+
+var _ = Describe("ExpirationTime", func() {
+	deployedAt := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	Context("when neither TTL nor ExpiresAt are set", func() {
+		It("should never expire", func() {
+			trap := Trap{}
+			_, expires := trap.ExpirationTime(&deployedAt)
+			Expect(expires).To(BeFalse())
+			Expect(trap.IsExpired(&deployedAt, deployedAt.Add(1000*time.Hour))).To(BeFalse())
+		})
+	})
+
+	Context("when only TTL is set", func() {
+		It("should expire relative to the deployment time", func() {
+			trap := Trap{TTL: &metav1.Duration{Duration: 2 * time.Hour}}
+			expiresAt, expires := trap.ExpirationTime(&deployedAt)
+			Expect(expires).To(BeTrue())
+			Expect(expiresAt).To(Equal(deployedAt.Add(2 * time.Hour)))
+			Expect(trap.IsExpired(&deployedAt, deployedAt.Add(1*time.Hour))).To(BeFalse())
+			Expect(trap.IsExpired(&deployedAt, deployedAt.Add(2*time.Hour))).To(BeTrue())
+		})
+
+		It("should not expire before the trap was deployed", func() {
+			trap := Trap{TTL: &metav1.Duration{Duration: 2 * time.Hour}}
+			_, expires := trap.ExpirationTime(nil)
+			Expect(expires).To(BeFalse())
+			Expect(trap.IsExpired(nil, deployedAt.Add(1000*time.Hour))).To(BeFalse())
+		})
+	})
+
+	Context("when only ExpiresAt is set", func() {
+		It("should expire even before the trap was deployed", func() {
+			expiresAt := metav1.NewTime(deployedAt.Add(1 * time.Hour))
+			trap := Trap{ExpiresAt: &expiresAt}
+			Expect(trap.IsExpired(nil, deployedAt.Add(2*time.Hour))).To(BeTrue())
+		})
+	})
+
+	Context("when both TTL and ExpiresAt are set", func() {
+		It("should expire at whichever comes first", func() {
+			expiresAt := metav1.NewTime(deployedAt.Add(1 * time.Hour))
+			trap := Trap{TTL: &metav1.Duration{Duration: 2 * time.Hour}, ExpiresAt: &expiresAt}
+			actual, expires := trap.ExpirationTime(&deployedAt)
+			Expect(expires).To(BeTrue())
+			Expect(actual).To(Equal(expiresAt.Time))
+
+			trap.TTL = &metav1.Duration{Duration: 30 * time.Minute}
+			actual, _ = trap.ExpirationTime(&deployedAt)
+			Expect(actual).To(Equal(deployedAt.Add(30 * time.Minute)))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1alpha1
+
+const (
+	// DefaultDecoyDeploymentStrategy is the decoy deployment strategy of traps that set none, neither directly nor through TrapDefaults.
+	DefaultDecoyDeploymentStrategy = "volumeMount"
+
+	// DefaultCaptorDeploymentStrategy is the captor deployment strategy of traps that set none, neither directly nor through TrapDefaults.
+	DefaultCaptorDeploymentStrategy = "tetragon"
+)
+
+// TrapDefaults are defaults that cascade to all traps of a DeceptionPolicy.
+// Fields that are set in a trap always take precedence over the defaults.
+type TrapDefaults struct {
+	// DecoyDeployment is the default decoy deployment of all traps.
+	// +optional
+	DecoyDeployment *DecoyDeployment `json:"decoyDeployment,omitempty" yaml:"decoyDeployment,omitempty"`
+
+	// FilesystemHoneytoken holds the defaults of all filesystem honeytoken traps.
+	// +optional
+	FilesystemHoneytoken *FilesystemHoneytokenDefaults `json:"filesystemHoneytoken,omitempty" yaml:"filesystemHoneytoken,omitempty"`
+
+	// CaptorDeployment is the default captor deployment of all traps.
+	// +optional
+	CaptorDeployment *CaptorDeployment `json:"captorDeployment,omitempty" yaml:"captorDeployment,omitempty"`
+
+	// MatchResources is the default resource matching criteria of all traps that do not define their own.
+	// +optional
+	MatchResources *MatchResources `json:"match,omitempty" yaml:"match,omitempty"`
+
+	// Alerting is the default alerting configuration of all traps.
+	// Tags of the trap are merged with the default tags, and the trap wins on conflicts.
+	// +optional
+	Alerting *Alerting `json:"alerting,omitempty" yaml:"alerting,omitempty"`
+}
+
+// FilesystemHoneytokenDefaults are the defaults of all filesystem honeytoken traps.
+type FilesystemHoneytokenDefaults struct {
+	// ReadOnly is the default readOnly of all filesystem honeytokens that do not set it.
+	// If not set, honeytokens are read-only.
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+}
+
+// ApplyDefaults applies the TrapDefaults of the DeceptionPolicy to all of its traps,
+// and the built-in defaults where neither the trap nor the TrapDefaults set a value.
+// Traps that reference a TrapTemplate are skipped, since the defaults are applied when they are expanded.
+// It returns true if any trap was changed.
+func (dp *DeceptionPolicy) ApplyDefaults() bool {
+	changed := false
+	for i := range dp.Spec.Traps {
+		if dp.Spec.Traps[i].TemplateRef != nil {
+			continue
+		}
+		if dp.Spec.Traps[i].ApplyDefaults(dp.Spec.TrapDefaults) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// ApplyDefaults applies the given defaults (which may be nil) and the built-in defaults to the trap.
+// It returns true if the trap was changed.
+func (trap *Trap) ApplyDefaults(defaults *TrapDefaults) bool {
+	if defaults == nil {
+		defaults = &TrapDefaults{}
+	}
+	changed := false
+
+	if trap.DecoyDeployment.Strategy == "" {
+		trap.DecoyDeployment.Strategy = DefaultDecoyDeploymentStrategy
+		if defaults.DecoyDeployment != nil && defaults.DecoyDeployment.Strategy != "" {
+			trap.DecoyDeployment.Strategy = defaults.DecoyDeployment.Strategy
+		}
+		changed = true
+	}
+	if trap.DecoyDeployment.ImageVolume == nil && trap.DecoyDeployment.Strategy == "imageVolume" &&
+		defaults.DecoyDeployment != nil && defaults.DecoyDeployment.ImageVolume != nil {
+		trap.DecoyDeployment.ImageVolume = defaults.DecoyDeployment.ImageVolume.DeepCopy()
+		changed = true
+	}
+	if trap.DecoyDeployment.InitContainer == nil && trap.DecoyDeployment.Strategy == "initContainer" &&
+		defaults.DecoyDeployment != nil && defaults.DecoyDeployment.InitContainer != nil {
+		trap.DecoyDeployment.InitContainer = defaults.DecoyDeployment.InitContainer.DeepCopy()
+		changed = true
+	}
+	if trap.DecoyDeployment.EphemeralContainer == nil && trap.DecoyDeployment.Strategy == "ephemeralContainer" &&
+		defaults.DecoyDeployment != nil && defaults.DecoyDeployment.EphemeralContainer != nil {
+		trap.DecoyDeployment.EphemeralContainer = defaults.DecoyDeployment.EphemeralContainer.DeepCopy()
+		changed = true
+	}
+
+	if trap.TrapType() == FilesystemHoneytokenTrap && trap.FilesystemHoneytoken.ReadOnly == nil {
+		trap.FilesystemHoneytoken.ReadOnly = &[]bool{true}[0]
+		if defaults.FilesystemHoneytoken != nil && defaults.FilesystemHoneytoken.ReadOnly != nil {
+			trap.FilesystemHoneytoken.ReadOnly = &[]bool{*defaults.FilesystemHoneytoken.ReadOnly}[0]
+		}
+		changed = true
+	}
+
+	if trap.CaptorDeployment.IsZero() {
+		trap.CaptorDeployment.Strategy = DefaultCaptorDeploymentStrategy
+		if defaults.CaptorDeployment != nil && !defaults.CaptorDeployment.IsZero() {
+			rateLimit := trap.CaptorDeployment.RateLimit
+			trap.CaptorDeployment = *defaults.CaptorDeployment.DeepCopy()
+			if rateLimit != nil {
+				trap.CaptorDeployment.RateLimit = rateLimit
+			}
+		}
+		changed = true
+	}
+	if trap.CaptorDeployment.RateLimit == nil && defaults.CaptorDeployment != nil && defaults.CaptorDeployment.RateLimit != nil {
+		trap.CaptorDeployment.RateLimit = defaults.CaptorDeployment.RateLimit.DeepCopy()
+		changed = true
+	}
+
+	if trap.MatchResources.Any == nil && defaults.MatchResources != nil && defaults.MatchResources.Any != nil {
+		trap.MatchResources = *defaults.MatchResources.DeepCopy()
+		changed = true
+	}
+
+	if defaults.Alerting != nil {
+		alerting := &Alerting{}
+		if trap.Alerting != nil {
+			alerting = trap.Alerting.DeepCopy()
+		}
+
+		alertingChanged := false
+		if alerting.Severity == "" && defaults.Alerting.Severity != "" {
+			alerting.Severity = defaults.Alerting.Severity
+			alertingChanged = true
+		}
+		for key, value := range defaults.Alerting.Tags {
+			if _, ok := alerting.Tags[key]; !ok {
+				if alerting.Tags == nil {
+					alerting.Tags = make(map[string]string, len(defaults.Alerting.Tags))
+				}
+				alerting.Tags[key] = value
+				alertingChanged = true
+			}
+		}
+
+		if alertingChanged {
+			trap.Alerting = alerting
+			changed = true
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("TrapDefaults", func() {
+	var trap Trap
+
+	BeforeEach(func() {
+		trap = Trap{
+			FilesystemHoneytoken: FilesystemHoneytoken{
+				FilePath: "/run/secrets/koney/service_token",
+				ReadOnly: &[]bool{true}[0],
+			},
+		}
+	})
+
+	It("should apply the built-in defaults without trap defaults", func() {
+		Expect(trap.ApplyDefaults(nil)).To(BeTrue())
+		Expect(trap.DecoyDeployment.Strategy).To(Equal(DefaultDecoyDeploymentStrategy))
+		Expect(trap.CaptorDeployment.Strategy).To(Equal(DefaultCaptorDeploymentStrategy))
+		Expect(trap.MatchResources.Any).To(BeNil())
+		Expect(trap.Alerting).To(BeNil())
+
+		By("not changing the trap again")
+		Expect(trap.ApplyDefaults(nil)).To(BeFalse())
+	})
+
+	It("should cascade the trap defaults", func() {
+		defaults := &TrapDefaults{
+			DecoyDeployment:  &DecoyDeployment{Strategy: "imageVolume", ImageVolume: &ImageVolumeDecoy{Image: "decoys:latest"}},
+			CaptorDeployment: &CaptorDeployment{Strategy: "kive"},
+			MatchResources: &MatchResources{Any: []ResourceFilter{
+				{ResourceDescription: ResourceDescription{Namespaces: []string{"koney"}}},
+			}},
+			Alerting: &Alerting{Severity: "HIGH", Tags: map[string]string{"team": "blue"}},
+		}
+
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue())
+		Expect(trap.DecoyDeployment.Strategy).To(Equal("imageVolume"))
+		Expect(trap.DecoyDeployment.ImageVolume.Image).To(Equal("decoys:latest"))
+		Expect(trap.CaptorDeployment.Strategy).To(Equal("kive"))
+		Expect(trap.MatchResources).To(Equal(*defaults.MatchResources))
+		Expect(trap.Alerting).To(Equal(&Alerting{Severity: "HIGH", Tags: map[string]string{"team": "blue"}}))
+		Expect(trap.IsValid()).To(Succeed())
+
+		By("not sharing memory with the trap defaults")
+		trap.Alerting.Tags["team"] = "red"
+		trap.MatchResources.Any[0].Namespaces[0] = "other"
+		Expect(defaults.Alerting.Tags["team"]).To(Equal("blue"))
+		Expect(defaults.MatchResources.Any[0].Namespaces[0]).To(Equal("koney"))
+	})
+
+	It("should cascade the init container of the trap defaults", func() {
+		trap.DecoyDeployment.Strategy = "initContainer"
+		defaults := &TrapDefaults{
+			DecoyDeployment: &DecoyDeployment{InitContainer: &InitContainerDecoy{Image: "registry.example.com/busybox:1.37"}},
+		}
+
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue())
+		Expect(trap.DecoyDeployment.InitContainer).To(Equal(defaults.DecoyDeployment.InitContainer))
+		Expect(trap.DecoyDeployment.InitContainer).ToNot(BeIdenticalTo(defaults.DecoyDeployment.InitContainer))
+		Expect(trap.ApplyDefaults(defaults)).To(BeFalse())
+	})
+
+	It("should prefer the values of the trap", func() {
+		trap.DecoyDeployment.Strategy = "containerExec"
+		trap.CaptorDeployment.Strategy = "none"
+		trap.MatchResources = MatchResources{Any: []ResourceFilter{
+			{ResourceDescription: ResourceDescription{Namespaces: []string{"trap"}}},
+		}}
+		trap.Alerting = &Alerting{Severity: "LOW", Tags: map[string]string{"team": "red"}}
+
+		defaults := &TrapDefaults{
+			DecoyDeployment:  &DecoyDeployment{Strategy: "volumeMount"},
+			CaptorDeployment: &CaptorDeployment{Strategy: "tetragon"},
+			MatchResources: &MatchResources{Any: []ResourceFilter{
+				{ResourceDescription: ResourceDescription{Namespaces: []string{"koney"}}},
+			}},
+			Alerting: &Alerting{Severity: "HIGH", Tags: map[string]string{"team": "blue", "env": "prod"}},
+		}
+
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue()) // the env tag is merged
+		Expect(trap.DecoyDeployment.Strategy).To(Equal("containerExec"))
+		Expect(trap.CaptorDeployment.Strategy).To(Equal("none"))
+		Expect(trap.MatchResources.Any[0].Namespaces).To(Equal([]string{"trap"}))
+		Expect(trap.Alerting).To(Equal(&Alerting{Severity: "LOW", Tags: map[string]string{"team": "red", "env": "prod"}}))
+		Expect(trap.ApplyDefaults(defaults)).To(BeFalse())
+	})
+
+	It("should apply the trap defaults to all traps of a DeceptionPolicy", func() {
+		deceptionPolicy := DeceptionPolicy{Spec: DeceptionPolicySpec{
+			Traps:        []Trap{trap, trap},
+			TrapDefaults: &TrapDefaults{CaptorDeployment: &CaptorDeployment{Strategy: "kive"}},
+		}}
+
+		Expect(deceptionPolicy.ApplyDefaults()).To(BeTrue())
+		for _, trap := range deceptionPolicy.Spec.Traps {
+			Expect(trap.CaptorDeployment.Strategy).To(Equal("kive"))
+		}
+		Expect(deceptionPolicy.ApplyDefaults()).To(BeFalse())
+	})
+
+	It("should cascade several captor deployment strategies", func() {
+		defaults := &TrapDefaults{CaptorDeployment: &CaptorDeployment{Strategies: []string{"tetragon", "kive"}}}
+
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue())
+		Expect(trap.CaptorDeployment.Strategy).To(BeEmpty())
+		Expect(trap.CaptorDeployment.AllStrategies()).To(Equal([]string{"tetragon", "kive"}))
+
+		By("not sharing memory with the trap defaults")
+		trap.CaptorDeployment.Strategies[0] = "falco"
+		Expect(defaults.CaptorDeployment.Strategies[0]).To(Equal("tetragon"))
+	})
+
+	It("should cascade the captor rate limit independently of the strategy", func() {
+		rateLimit := &CaptorRateLimit{Period: metav1.Duration{Duration: time.Minute}}
+		defaults := &TrapDefaults{CaptorDeployment: &CaptorDeployment{Strategy: "kive", RateLimit: rateLimit}}
+
+		trap.CaptorDeployment.RateLimit = &CaptorRateLimit{Period: metav1.Duration{Duration: time.Hour}}
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue())
+		Expect(trap.CaptorDeployment.Strategy).To(Equal("kive"))
+		Expect(trap.CaptorDeployment.RateLimit.Period.Duration).To(Equal(time.Hour))
+
+		trap = Trap{CaptorDeployment: CaptorDeployment{Strategy: "tetragon"}}
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue())
+		Expect(trap.CaptorDeployment.Strategy).To(Equal("tetragon"))
+		Expect(trap.CaptorDeployment.RateLimit).To(Equal(rateLimit))
+	})
+
+	It("should cascade readOnly to filesystem honeytokens that do not set it", func() {
+		defaults := &TrapDefaults{FilesystemHoneytoken: &FilesystemHoneytokenDefaults{ReadOnly: &[]bool{false}[0]}}
+
+		trap.FilesystemHoneytoken.ReadOnly = nil
+		Expect(trap.ApplyDefaults(defaults)).To(BeTrue())
+		Expect(trap.FilesystemHoneytoken.IsReadOnly()).To(BeFalse())
+
+		By("not sharing memory with the trap defaults")
+		*trap.FilesystemHoneytoken.ReadOnly = true
+		Expect(*defaults.FilesystemHoneytoken.ReadOnly).To(BeFalse())
+
+		By("keeping the readOnly of the trap")
+		trap.FilesystemHoneytoken.ReadOnly = &[]bool{true}[0]
+		Expect(trap.ApplyDefaults(defaults)).To(BeFalse())
+		Expect(trap.FilesystemHoneytoken.IsReadOnly()).To(BeTrue())
+	})
+
+	It("should make filesystem honeytokens read-only without trap defaults", func() {
+		trap.FilesystemHoneytoken.ReadOnly = nil
+		Expect(trap.ApplyDefaults(&TrapDefaults{})).To(BeTrue())
+		Expect(trap.FilesystemHoneytoken.ReadOnly).To(Equal(&[]bool{true}[0]))
+	})
+})
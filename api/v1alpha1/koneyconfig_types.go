@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KoneyConfigName is the name of the KoneyConfig that Koney reads. Other KoneyConfig objects are rejected.
+const KoneyConfigName = "koney"
+
+// FeatureFlagName is the name of a feature flag that gates a new detection behavior.
+type FeatureFlagName string
+
+const (
+	// ProcessAncestryEnrichmentFeature adds the parent process chain of the accessing process to alerts.
+	// The alert forwarder evaluates it per namespace of the accessing pod.
+	ProcessAncestryEnrichmentFeature FeatureFlagName = "ProcessAncestryEnrichment"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'koney'",message="the KoneyConfig must be named 'koney'"
+
+// KoneyConfig is the Schema for the koneyconfigs API
+type KoneyConfig struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Spec is the specification of the KoneyConfig.
+	Spec KoneyConfigSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KoneyConfigList contains a list of KoneyConfig
+type KoneyConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KoneyConfig `json:"items"`
+}
+
+// KoneyConfigSpec defines the cluster-wide configuration of Koney
+type KoneyConfigSpec struct {
+	// FeatureFlags enable new detection behaviors, either for the whole cluster or only for some namespaces.
+	// Features that are not listed here are disabled.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	FeatureFlags []FeatureFlag `json:"featureFlags,omitempty" yaml:"featureFlags,omitempty"`
+}
+
+// FeatureFlag enables or disables a feature
+type FeatureFlag struct {
+	// Name is the name of the feature.
+	// +kubebuilder:validation:Enum=ProcessAncestryEnrichment
+	Name FeatureFlagName `json:"name" yaml:"name"`
+
+	// Enabled indicates whether the feature is enabled.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Namespaces restricts the feature to workloads in these namespaces.
+	// If empty, the feature applies to the whole cluster.
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KoneyConfig{}, &KoneyConfigList{})
+}
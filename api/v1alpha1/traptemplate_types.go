@@ -0,0 +1,243 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TemplateParameterPlaceholderPrefix starts the placeholders of parameters in the string fields of a TrapTemplate,
+// which are written as "{{ .Parameters.<name> }}".
+const TemplateParameterPlaceholderPrefix = "{{ .Parameters."
+
+// templateParameterPlaceholderPattern matches the placeholders of parameters, to find the ones that are not declared.
+var templateParameterPlaceholderPattern = regexp.MustCompile(`\{\{ \.Parameters\.([A-Za-z0-9_]*) \}\}`)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// TrapTemplate is the Schema for the traptemplates API.
+// It holds a reusable, parameterizable trap definition that traps of DeceptionPolicies can reference.
+type TrapTemplate struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Spec is the specification of the TrapTemplate.
+	Spec TrapTemplateSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TrapTemplateList contains a list of TrapTemplate
+type TrapTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TrapTemplate `json:"items"`
+}
+
+// TrapTemplateSpec defines a reusable trap
+type TrapTemplateSpec struct {
+	// Parameters are the parameters of the template. Their values are substituted in all string fields of the trap,
+	// wherever the placeholder "{{ .Parameters.<name> }}" appears.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Parameters []TrapTemplateParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+
+	// Trap is the trap that the template expands to.
+	// Fields that are set in the trap that references the template take precedence.
+	Trap Trap `json:"trap" yaml:"trap"`
+}
+
+// TrapTemplateParameter declares a parameter of a TrapTemplate.
+type TrapTemplateParameter struct {
+	// Name is the name of the parameter, as used in its placeholder.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Name string `json:"name" yaml:"name"`
+
+	// Description is a human-readable description of the parameter.
+	// +optional
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Default is the value of the parameter if the referencing trap does not set one.
+	// Parameters without a default are required.
+	// +optional
+	Default *string `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// TrapTemplateReference references a TrapTemplate from a trap, with the values of its parameters.
+type TrapTemplateReference struct {
+	// Name is the name of the TrapTemplate.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name" yaml:"name"`
+
+	// Parameters are the values of the parameters of the TrapTemplate.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// TrapTemplateStatus records the generation of a TrapTemplate that the traps of a DeceptionPolicy were expanded from.
+type TrapTemplateStatus struct {
+	// Name is the name of the TrapTemplate.
+	Name string `json:"name" yaml:"name"`
+
+	// Generation is the generation of the TrapTemplate that was expanded.
+	Generation int64 `json:"generation" yaml:"generation"`
+}
+
+// Expand returns the trap that a trap referencing this template describes: the trap of the template with the parameters
+// substituted, where all top-level fields that are set in the referencing trap take precedence.
+// The expanded trap does not reference the template anymore, and no defaults are applied to it yet.
+func (template *TrapTemplate) Expand(trap Trap) (Trap, error) {
+	if trap.TemplateRef == nil || trap.TemplateRef.Name != template.Name {
+		return Trap{}, fmt.Errorf("trap does not reference TrapTemplate '%s'", template.Name)
+	}
+	if template.Spec.Trap.TemplateRef != nil {
+		return Trap{}, fmt.Errorf("TrapTemplate '%s' references another TrapTemplate", template.Name)
+	}
+
+	values, err := template.parameterValues(trap.TemplateRef.Parameters)
+	if err != nil {
+		return Trap{}, err
+	}
+
+	// Parameters are substituted in the string values only, so that values cannot change the structure of the trap
+	var templateFields map[string]any
+	if err := remarshal(template.Spec.Trap, &templateFields); err != nil {
+		return Trap{}, err
+	}
+	for key, value := range templateFields {
+		templateFields[key] = substituteParameters(value, values)
+	}
+
+	referencingTrap := trap
+	referencingTrap.TemplateRef = nil
+	var trapFields map[string]any
+	if err := remarshal(referencingTrap, &trapFields); err != nil {
+		return Trap{}, err
+	}
+	for key, value := range trapFields {
+		if !isZeroJSON(value) {
+			templateFields[key] = value
+		}
+	}
+
+	var expandedTrap Trap
+	if err := remarshal(templateFields, &expandedTrap); err != nil {
+		return Trap{}, err
+	}
+
+	if encoded, err := json.Marshal(expandedTrap); err == nil {
+		if match := templateParameterPlaceholderPattern.FindStringSubmatch(string(encoded)); match != nil {
+			return Trap{}, fmt.Errorf("TrapTemplate '%s' uses the undeclared parameter '%s'", template.Name, match[1])
+		}
+	}
+
+	return expandedTrap, nil
+}
+
+// parameterValues returns the values of all parameters of the template, given the values of the referencing trap.
+func (template *TrapTemplate) parameterValues(given map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(template.Spec.Parameters))
+	for _, parameter := range template.Spec.Parameters {
+		if value, ok := given[parameter.Name]; ok {
+			values[parameter.Name] = value
+		} else if parameter.Default != nil {
+			values[parameter.Name] = *parameter.Default
+		} else {
+			return nil, fmt.Errorf("TrapTemplate '%s' requires the parameter '%s'", template.Name, parameter.Name)
+		}
+	}
+
+	for name := range given {
+		if !slices.ContainsFunc(template.Spec.Parameters, func(parameter TrapTemplateParameter) bool { return parameter.Name == name }) {
+			return nil, fmt.Errorf("TrapTemplate '%s' has no parameter '%s'", template.Name, name)
+		}
+	}
+
+	return values, nil
+}
+
+// substituteParameters replaces the placeholders of the parameters in all strings of a decoded JSON value.
+func substituteParameters(value any, values map[string]string) any {
+	switch value := value.(type) {
+	case string:
+		for name, parameterValue := range values {
+			value = strings.ReplaceAll(value, TemplateParameterPlaceholderPrefix+name+" }}", parameterValue)
+		}
+		return value
+	case map[string]any:
+		for key, element := range value {
+			value[key] = substituteParameters(element, values)
+		}
+		return value
+	case []any:
+		for i, element := range value {
+			value[i] = substituteParameters(element, values)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// isZeroJSON returns true if a decoded JSON value is the zero value of its field, e.g., a struct without any fields set.
+// Structs are encoded even if they are tagged with omitempty, so they cannot be told apart from unset fields otherwise.
+func isZeroJSON(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case bool:
+		return !value
+	case float64:
+		return value == 0
+	case []any:
+		return len(value) == 0
+	case map[string]any:
+		for _, element := range value {
+			if !isZeroJSON(element) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// remarshal converts a value into another type by encoding it to JSON and decoding it again.
+func remarshal(in any, out any) error {
+	encoded, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, out)
+}
+
+func init() {
+	SchemeBuilder.Register(&TrapTemplate{}, &TrapTemplateList{})
+}
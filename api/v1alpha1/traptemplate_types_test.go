@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("TrapTemplate", func() {
+	var template *TrapTemplate
+
+	BeforeEach(func() {
+		template = &TrapTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-credentials-honeytoken"},
+			Spec: TrapTemplateSpec{
+				Parameters: []TrapTemplateParameter{
+					{Name: "profile"},
+					{Name: "path", Default: &[]string{"/root/.aws/credentials"}[0]},
+				},
+				Trap: Trap{
+					FilesystemHoneytoken: FilesystemHoneytoken{
+						FilePath:    "{{ .Parameters.path }}",
+						FileContent: "[{{ .Parameters.profile }}]\naws_access_key_id = {{ .Token }}",
+						ReadOnly:    &[]bool{true}[0],
+					},
+					CaptorDeployment: CaptorDeployment{Strategy: "tetragon"},
+					Alerting:         &Alerting{Severity: "high", Tags: map[string]string{"profile": "{{ .Parameters.profile }}"}},
+				},
+			},
+		}
+	})
+
+	reference := func(parameters map[string]string) Trap {
+		return Trap{TemplateRef: &TrapTemplateReference{Name: "aws-credentials-honeytoken", Parameters: parameters}}
+	}
+
+	It("should substitute the parameters and their defaults", func() {
+		trap, err := template.Expand(reference(map[string]string{"profile": "prod"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trap.TemplateRef).To(BeNil())
+		Expect(trap.FilesystemHoneytoken.FilePath).To(Equal("/root/.aws/credentials"))
+		Expect(trap.FilesystemHoneytoken.FileContent).To(Equal("[prod]\naws_access_key_id = {{ .Token }}"))
+		Expect(trap.FilesystemHoneytoken.IsReadOnly()).To(BeTrue())
+		Expect(trap.Alerting.Tags).To(HaveKeyWithValue("profile", "prod"))
+
+		By("not changing the template itself")
+		Expect(template.Spec.Trap.FilesystemHoneytoken.FilePath).To(Equal("{{ .Parameters.path }}"))
+	})
+
+	It("should let the fields of the referencing trap take precedence", func() {
+		trap := reference(map[string]string{"profile": "prod"})
+		trap.CaptorDeployment = CaptorDeployment{Strategy: "kive"}
+		trap.MatchResources = MatchResources{Any: []ResourceFilter{
+			{ResourceDescription: ResourceDescription{Namespaces: []string{"koney-demo"}}},
+		}}
+
+		expanded, err := template.Expand(trap)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expanded.CaptorDeployment.Strategy).To(Equal("kive"))
+		Expect(expanded.MatchResources.Any[0].Namespaces).To(ConsistOf("koney-demo"))
+		Expect(expanded.FilesystemHoneytoken.FilePath).To(Equal("/root/.aws/credentials"))
+	})
+
+	It("should fail if a required parameter is missing", func() {
+		_, err := template.Expand(reference(nil))
+		Expect(err).To(MatchError(ContainSubstring("requires the parameter 'profile'")))
+	})
+
+	It("should fail if an unknown parameter is given", func() {
+		_, err := template.Expand(reference(map[string]string{"profile": "prod", "region": "eu-central-1"}))
+		Expect(err).To(MatchError(ContainSubstring("has no parameter 'region'")))
+	})
+
+	It("should fail if the template uses an undeclared parameter", func() {
+		template.Spec.Trap.Description = "credentials of {{ .Parameters.team }}"
+		_, err := template.Expand(reference(map[string]string{"profile": "prod"}))
+		Expect(err).To(MatchError(ContainSubstring("undeclared parameter 'team'")))
+	})
+
+	It("should fail if the trap references another template", func() {
+		_, err := template.Expand(Trap{TemplateRef: &TrapTemplateReference{Name: "other"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should skip traps that reference a template when applying defaults", func() {
+		dp := &DeceptionPolicy{Spec: DeceptionPolicySpec{Traps: []Trap{reference(nil)}}}
+		Expect(dp.ApplyDefaults()).To(BeFalse())
+		Expect(dp.Spec.Traps[0].DecoyDeployment.Strategy).To(BeEmpty())
+		Expect(dp.Spec.Traps[0].IsValid()).To(MatchError(ContainSubstring("cannot be expanded")))
+	})
+})
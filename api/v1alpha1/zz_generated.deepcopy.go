@@ -5,190 +5,255 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CaptorDeployment) DeepCopyInto(out *CaptorDeployment) {
+func (in *AlertSinkMatch) DeepCopyInto(out *AlertSinkMatch) {
 	*out = *in
+	if in.DeceptionPolicyNames != nil {
+		in, out := &in.DeceptionPolicyNames, &out.DeceptionPolicyNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrapTypes != nil {
+		in, out := &in.TrapTypes, &out.TrapTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Severities != nil {
+		in, out := &in.Severities, &out.Severities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptorDeployment.
-func (in *CaptorDeployment) DeepCopy() *CaptorDeployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSinkMatch.
+func (in *AlertSinkMatch) DeepCopy() *AlertSinkMatch {
 	if in == nil {
 		return nil
 	}
-	out := new(CaptorDeployment)
+	out := new(AlertSinkMatch)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChangeAnnotation) DeepCopyInto(out *ChangeAnnotation) {
+func (in *AlertSinkRedeliveryPolicy) DeepCopyInto(out *AlertSinkRedeliveryPolicy) {
 	*out = *in
-	if in.Traps != nil {
-		in, out := &in.Traps, &out.Traps
-		*out = make([]TrapAnnotation, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeAnnotation.
-func (in *ChangeAnnotation) DeepCopy() *ChangeAnnotation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSinkRedeliveryPolicy.
+func (in *AlertSinkRedeliveryPolicy) DeepCopy() *AlertSinkRedeliveryPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ChangeAnnotation)
+	out := new(AlertSinkRedeliveryPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionAlertSink) DeepCopyInto(out *DeceptionAlertSink) {
+func (in *Alerting) DeepCopyInto(out *Alerting) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSink.
-func (in *DeceptionAlertSink) DeepCopy() *DeceptionAlertSink {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Alerting.
+func (in *Alerting) DeepCopy() *Alerting {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionAlertSink)
+	out := new(Alerting)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DeceptionAlertSink) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryToken) DeepCopyInto(out *CanaryToken) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryToken.
+func (in *CanaryToken) DeepCopy() *CanaryToken {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(CanaryToken)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionAlertSinkList) DeepCopyInto(out *DeceptionAlertSinkList) {
+func (in *CanaryTokenStatus) DeepCopyInto(out *CanaryTokenStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]DeceptionAlertSink, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ProvisionedAt.DeepCopyInto(&out.ProvisionedAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSinkList.
-func (in *DeceptionAlertSinkList) DeepCopy() *DeceptionAlertSinkList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTokenStatus.
+func (in *CanaryTokenStatus) DeepCopy() *CanaryTokenStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionAlertSinkList)
+	out := new(CanaryTokenStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DeceptionAlertSinkList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaptorDeployment) DeepCopyInto(out *CaptorDeployment) {
+	*out = *in
+	if in.Strategies != nil {
+		in, out := &in.Strategies, &out.Strategies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	return nil
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(CaptorRateLimit)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptorDeployment.
+func (in *CaptorDeployment) DeepCopy() *CaptorDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(CaptorDeployment)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionAlertSinkSpec) DeepCopyInto(out *DeceptionAlertSinkSpec) {
+func (in *CaptorRateLimit) DeepCopyInto(out *CaptorRateLimit) {
 	*out = *in
-	out.Dynatrace = in.Dynatrace
+	out.Period = in.Period
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSinkSpec.
-func (in *DeceptionAlertSinkSpec) DeepCopy() *DeceptionAlertSinkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptorRateLimit.
+func (in *CaptorRateLimit) DeepCopy() *CaptorRateLimit {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionAlertSinkSpec)
+	out := new(CaptorRateLimit)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionPolicy) DeepCopyInto(out *DeceptionPolicy) {
+func (in *ChangeAnnotation) DeepCopyInto(out *ChangeAnnotation) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Traps != nil {
+		in, out := &in.Traps, &out.Traps
+		*out = make([]TrapAnnotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicy.
-func (in *DeceptionPolicy) DeepCopy() *DeceptionPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeAnnotation.
+func (in *ChangeAnnotation) DeepCopy() *ChangeAnnotation {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionPolicy)
+	out := new(ChangeAnnotation)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DeceptionPolicy) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChatSinkSpec) DeepCopyInto(out *ChatSinkSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChatSinkSpec.
+func (in *ChatSinkSpec) DeepCopy() *ChatSinkSpec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(ChatSinkSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionPolicyCondition) DeepCopyInto(out *DeceptionPolicyCondition) {
+func (in *ClusterDeceptionPolicy) DeepCopyInto(out *ClusterDeceptionPolicy) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyCondition.
-func (in *DeceptionPolicyCondition) DeepCopy() *DeceptionPolicyCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionPolicy.
+func (in *ClusterDeceptionPolicy) DeepCopy() *ClusterDeceptionPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionPolicyCondition)
+	out := new(ClusterDeceptionPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDeceptionPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionPolicyList) DeepCopyInto(out *DeceptionPolicyList) {
+func (in *ClusterDeceptionPolicyList) DeepCopyInto(out *ClusterDeceptionPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DeceptionPolicy, len(*in))
+		*out = make([]ClusterDeceptionPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyList.
-func (in *DeceptionPolicyList) DeepCopy() *DeceptionPolicyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionPolicyList.
+func (in *ClusterDeceptionPolicyList) DeepCopy() *ClusterDeceptionPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionPolicyList)
+	out := new(ClusterDeceptionPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DeceptionPolicyList) DeepCopyObject() runtime.Object {
+func (in *ClusterDeceptionPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -196,8 +261,13 @@ func (in *DeceptionPolicyList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionPolicySpec) DeepCopyInto(out *DeceptionPolicySpec) {
+func (in *ClusterDeceptionPolicySpec) DeepCopyInto(out *ClusterDeceptionPolicySpec) {
 	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Traps != nil {
 		in, out := &in.Traps, &out.Traps
 		*out = make([]Trap, len(*in))
@@ -205,6 +275,11 @@ func (in *DeceptionPolicySpec) DeepCopyInto(out *DeceptionPolicySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TrapDefaults != nil {
+		in, out := &in.TrapDefaults, &out.TrapDefaults
+		*out = new(TrapDefaults)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StrictValidation != nil {
 		in, out := &in.StrictValidation, &out.StrictValidation
 		*out = new(bool)
@@ -215,263 +290,1770 @@ func (in *DeceptionPolicySpec) DeepCopyInto(out *DeceptionPolicySpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MaxAlertsPerHour != nil {
+		in, out := &in.MaxAlertsPerHour, &out.MaxAlertsPerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AlertAggregationWindow != nil {
+		in, out := &in.AlertAggregationWindow, &out.AlertAggregationWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SelfTest != nil {
+		in, out := &in.SelfTest, &out.SelfTest
+		*out = new(SelfTest)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicySpec.
-func (in *DeceptionPolicySpec) DeepCopy() *DeceptionPolicySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionPolicySpec.
+func (in *ClusterDeceptionPolicySpec) DeepCopy() *ClusterDeceptionPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionPolicySpec)
+	out := new(ClusterDeceptionPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeceptionPolicyStatus) DeepCopyInto(out *DeceptionPolicyStatus) {
+func (in *ClusterDeceptionPolicyStatus) DeepCopyInto(out *ClusterDeceptionPolicyStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]DeceptionPolicyCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OverriddenNamespaces != nil {
+		in, out := &in.OverriddenNamespaces, &out.OverriddenNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyStatus.
-func (in *DeceptionPolicyStatus) DeepCopy() *DeceptionPolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionPolicyStatus.
+func (in *ClusterDeceptionPolicyStatus) DeepCopy() *ClusterDeceptionPolicyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DeceptionPolicyStatus)
+	out := new(ClusterDeceptionPolicyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DecoyDeployment) DeepCopyInto(out *DecoyDeployment) {
+func (in *ClusterDeceptionReport) DeepCopyInto(out *ClusterDeceptionReport) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecoyDeployment.
-func (in *DecoyDeployment) DeepCopy() *DecoyDeployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionReport.
+func (in *ClusterDeceptionReport) DeepCopy() *ClusterDeceptionReport {
 	if in == nil {
 		return nil
 	}
-	out := new(DecoyDeployment)
+	out := new(ClusterDeceptionReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDeceptionReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynatraceSinkSpec) DeepCopyInto(out *DynatraceSinkSpec) {
+func (in *ClusterDeceptionReportList) DeepCopyInto(out *ClusterDeceptionReportList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterDeceptionReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynatraceSinkSpec.
-func (in *DynatraceSinkSpec) DeepCopy() *DynatraceSinkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionReportList.
+func (in *ClusterDeceptionReportList) DeepCopy() *ClusterDeceptionReportList {
 	if in == nil {
 		return nil
 	}
-	out := new(DynatraceSinkSpec)
+	out := new(ClusterDeceptionReportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterDeceptionReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilesystemHoneytoken) DeepCopyInto(out *FilesystemHoneytoken) {
+func (in *ClusterDeceptionReportSpec) DeepCopyInto(out *ClusterDeceptionReportSpec) {
 	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemHoneytoken.
-func (in *FilesystemHoneytoken) DeepCopy() *FilesystemHoneytoken {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionReportSpec.
+func (in *ClusterDeceptionReportSpec) DeepCopy() *ClusterDeceptionReportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FilesystemHoneytoken)
+	out := new(ClusterDeceptionReportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilesystemHoneytokenAnnotation) DeepCopyInto(out *FilesystemHoneytokenAnnotation) {
+func (in *ClusterDeceptionReportStatus) DeepCopyInto(out *ClusterDeceptionReportStatus) {
 	*out = *in
+	if in.GeneratedAt != nil {
+		in, out := &in.GeneratedAt, &out.GeneratedAt
+		*out = (*in).DeepCopy()
+	}
+	out.Summary = in.Summary
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespaceCoverage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemHoneytokenAnnotation.
-func (in *FilesystemHoneytokenAnnotation) DeepCopy() *FilesystemHoneytokenAnnotation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeceptionReportStatus.
+func (in *ClusterDeceptionReportStatus) DeepCopy() *ClusterDeceptionReportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(FilesystemHoneytokenAnnotation)
+	out := new(ClusterDeceptionReportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpEndpoint) DeepCopyInto(out *HttpEndpoint) {
+func (in *ClusterPolicyOverride) DeepCopyInto(out *ClusterPolicyOverride) {
 	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpEndpoint.
-func (in *HttpEndpoint) DeepCopy() *HttpEndpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPolicyOverride.
+func (in *ClusterPolicyOverride) DeepCopy() *ClusterPolicyOverride {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpEndpoint)
+	out := new(ClusterPolicyOverride)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpEndpointAnnotation) DeepCopyInto(out *HttpEndpointAnnotation) {
+func (in *ContainerSelection) DeepCopyInto(out *ContainerSelection) {
 	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpEndpointAnnotation.
-func (in *HttpEndpointAnnotation) DeepCopy() *HttpEndpointAnnotation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSelection.
+func (in *ContainerSelection) DeepCopy() *ContainerSelection {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpEndpointAnnotation)
+	out := new(ContainerSelection)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpPayload) DeepCopyInto(out *HttpPayload) {
+func (in *CoverageSummary) DeepCopyInto(out *CoverageSummary) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpPayload.
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoverageSummary.
+func (in *CoverageSummary) DeepCopy() *CoverageSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(CoverageSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlert) DeepCopyInto(out *DeceptionAlert) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlert.
+func (in *DeceptionAlert) DeepCopy() *DeceptionAlert {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionAlert) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertList) DeepCopyInto(out *DeceptionAlertList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeceptionAlert, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertList.
+func (in *DeceptionAlertList) DeepCopy() *DeceptionAlertList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionAlertList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertPod) DeepCopyInto(out *DeceptionAlertPod) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertPod.
+func (in *DeceptionAlertPod) DeepCopy() *DeceptionAlertPod {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertProcess) DeepCopyInto(out *DeceptionAlertProcess) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertProcess.
+func (in *DeceptionAlertProcess) DeepCopy() *DeceptionAlertProcess {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertProcess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertSink) DeepCopyInto(out *DeceptionAlertSink) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSink.
+func (in *DeceptionAlertSink) DeepCopy() *DeceptionAlertSink {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionAlertSink) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertSinkList) DeepCopyInto(out *DeceptionAlertSinkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeceptionAlertSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSinkList.
+func (in *DeceptionAlertSinkList) DeepCopy() *DeceptionAlertSinkList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertSinkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionAlertSinkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertSinkSpec) DeepCopyInto(out *DeceptionAlertSinkSpec) {
+	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = new(AlertSinkMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Redelivery != nil {
+		in, out := &in.Redelivery, &out.Redelivery
+		*out = new(AlertSinkRedeliveryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Dynatrace = in.Dynatrace
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookSinkSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Chat != nil {
+		in, out := &in.Chat, &out.Chat
+		*out = new(ChatSinkSpec)
+		**out = **in
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		*out = new(SyslogSinkSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSinkSpec.
+func (in *DeceptionAlertSinkSpec) DeepCopy() *DeceptionAlertSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertSinkStatus) DeepCopyInto(out *DeceptionAlertSinkStatus) {
+	*out = *in
+	if in.LastDeliveryTime != nil {
+		in, out := &in.LastDeliveryTime, &out.LastDeliveryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RecentAlertTimestamps != nil {
+		in, out := &in.RecentAlertTimestamps, &out.RecentAlertTimestamps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSinkStatus.
+func (in *DeceptionAlertSinkStatus) DeepCopy() *DeceptionAlertSinkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertSinkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionAlertSpec) DeepCopyInto(out *DeceptionAlertSpec) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(DeceptionAlertPod)
+		**out = **in
+	}
+	if in.Process != nil {
+		in, out := &in.Process, &out.Process
+		*out = new(DeceptionAlertProcess)
+		**out = **in
+	}
+	in.Alert.DeepCopyInto(&out.Alert)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionAlertSpec.
+func (in *DeceptionAlertSpec) DeepCopy() *DeceptionAlertSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionAlertSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicy) DeepCopyInto(out *DeceptionPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicy.
+func (in *DeceptionPolicy) DeepCopy() *DeceptionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicyCondition) DeepCopyInto(out *DeceptionPolicyCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyCondition.
+func (in *DeceptionPolicyCondition) DeepCopy() *DeceptionPolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicyList) DeepCopyInto(out *DeceptionPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeceptionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyList.
+func (in *DeceptionPolicyList) DeepCopy() *DeceptionPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicyPlan) DeepCopyInto(out *DeceptionPolicyPlan) {
+	*out = *in
+	if in.Traps != nil {
+		in, out := &in.Traps, &out.Traps
+		*out = make([]TrapPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyPlan.
+func (in *DeceptionPolicyPlan) DeepCopy() *DeceptionPolicyPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicyPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicySpec) DeepCopyInto(out *DeceptionPolicySpec) {
+	*out = *in
+	if in.Traps != nil {
+		in, out := &in.Traps, &out.Traps
+		*out = make([]Trap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TrapDefaults != nil {
+		in, out := &in.TrapDefaults, &out.TrapDefaults
+		*out = new(TrapDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StrictValidation != nil {
+		in, out := &in.StrictValidation, &out.StrictValidation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MutateExisting != nil {
+		in, out := &in.MutateExisting, &out.MutateExisting
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxAlertsPerHour != nil {
+		in, out := &in.MaxAlertsPerHour, &out.MaxAlertsPerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AlertAggregationWindow != nil {
+		in, out := &in.AlertAggregationWindow, &out.AlertAggregationWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClusterPolicyOverrides != nil {
+		in, out := &in.ClusterPolicyOverrides, &out.ClusterPolicyOverrides
+		*out = make([]ClusterPolicyOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SelfTest != nil {
+		in, out := &in.SelfTest, &out.SelfTest
+		*out = new(SelfTest)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicySpec.
+func (in *DeceptionPolicySpec) DeepCopy() *DeceptionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicyStatus) DeepCopyInto(out *DeceptionPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]DeceptionPolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HoneytokenRotations != nil {
+		in, out := &in.HoneytokenRotations, &out.HoneytokenRotations
+		*out = make([]HoneytokenRotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CanaryTokens != nil {
+		in, out := &in.CanaryTokens, &out.CanaryTokens
+		*out = make([]CanaryTokenStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Traps != nil {
+		in, out := &in.Traps, &out.Traps
+		*out = make([]TrapStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TrapTemplates != nil {
+		in, out := &in.TrapTemplates, &out.TrapTemplates
+		*out = make([]TrapTemplateStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(DeceptionPolicyPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SelfTest != nil {
+		in, out := &in.SelfTest, &out.SelfTest
+		*out = new(SelfTestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyStatus.
+func (in *DeceptionPolicyStatus) DeepCopy() *DeceptionPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecoyChecksum) DeepCopyInto(out *DecoyChecksum) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecoyChecksum.
+func (in *DecoyChecksum) DeepCopy() *DecoyChecksum {
+	if in == nil {
+		return nil
+	}
+	out := new(DecoyChecksum)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecoyDeployment) DeepCopyInto(out *DecoyDeployment) {
+	*out = *in
+	if in.ImageVolume != nil {
+		in, out := &in.ImageVolume, &out.ImageVolume
+		*out = new(ImageVolumeDecoy)
+		**out = **in
+	}
+	if in.InitContainer != nil {
+		in, out := &in.InitContainer, &out.InitContainer
+		*out = new(InitContainerDecoy)
+		**out = **in
+	}
+	if in.EphemeralContainer != nil {
+		in, out := &in.EphemeralContainer, &out.EphemeralContainer
+		*out = new(EphemeralContainerDecoy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecoyDeployment.
+func (in *DecoyDeployment) DeepCopy() *DecoyDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(DecoyDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynatraceSinkSpec) DeepCopyInto(out *DynatraceSinkSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynatraceSinkSpec.
+func (in *DynatraceSinkSpec) DeepCopy() *DynatraceSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynatraceSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralContainerDecoy) DeepCopyInto(out *EphemeralContainerDecoy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralContainerDecoy.
+func (in *EphemeralContainerDecoy) DeepCopy() *EphemeralContainerDecoy {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralContainerDecoy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedPlacement) DeepCopyInto(out *FailedPlacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedPlacement.
+func (in *FailedPlacement) DeepCopy() *FailedPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureFlag) DeepCopyInto(out *FeatureFlag) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureFlag.
+func (in *FeatureFlag) DeepCopy() *FeatureFlag {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureFlag)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemHoneytoken) DeepCopyInto(out *FilesystemHoneytoken) {
+	*out = *in
+	if in.FilePaths != nil {
+		in, out := &in.FilePaths, &out.FilePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MonitorPaths != nil {
+		in, out := &in.MonitorPaths, &out.MonitorPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RotateEvery != nil {
+		in, out := &in.RotateEvery, &out.RotateEvery
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CanaryToken != nil {
+		in, out := &in.CanaryToken, &out.CanaryToken
+		*out = new(CanaryToken)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemHoneytoken.
+func (in *FilesystemHoneytoken) DeepCopy() *FilesystemHoneytoken {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemHoneytoken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemHoneytokenAnnotation) DeepCopyInto(out *FilesystemHoneytokenAnnotation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemHoneytokenAnnotation.
+func (in *FilesystemHoneytokenAnnotation) DeepCopy() *FilesystemHoneytokenAnnotation {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemHoneytokenAnnotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemHoneytokenDefaults) DeepCopyInto(out *FilesystemHoneytokenDefaults) {
+	*out = *in
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemHoneytokenDefaults.
+func (in *FilesystemHoneytokenDefaults) DeepCopy() *FilesystemHoneytokenDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemHoneytokenDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FollowAttacker) DeepCopyInto(out *FollowAttacker) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FollowAttacker.
+func (in *FollowAttacker) DeepCopy() *FollowAttacker {
+	if in == nil {
+		return nil
+	}
+	out := new(FollowAttacker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HoneytokenRotation) DeepCopyInto(out *HoneytokenRotation) {
+	*out = *in
+	in.ActiveFrom.DeepCopyInto(&out.ActiveFrom)
+	in.ActiveUntil.DeepCopyInto(&out.ActiveUntil)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HoneytokenRotation.
+func (in *HoneytokenRotation) DeepCopy() *HoneytokenRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(HoneytokenRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpEndpoint) DeepCopyInto(out *HttpEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpEndpoint.
+func (in *HttpEndpoint) DeepCopy() *HttpEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpEndpointAnnotation) DeepCopyInto(out *HttpEndpointAnnotation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpEndpointAnnotation.
+func (in *HttpEndpointAnnotation) DeepCopy() *HttpEndpointAnnotation {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpEndpointAnnotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpPayload) DeepCopyInto(out *HttpPayload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpPayload.
 func (in *HttpPayload) DeepCopy() *HttpPayload {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpPayload)
+	out := new(HttpPayload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpPayloadAnnotation) DeepCopyInto(out *HttpPayloadAnnotation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpPayloadAnnotation.
+func (in *HttpPayloadAnnotation) DeepCopy() *HttpPayloadAnnotation {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpPayloadAnnotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVolumeDecoy) DeepCopyInto(out *ImageVolumeDecoy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVolumeDecoy.
+func (in *ImageVolumeDecoy) DeepCopy() *ImageVolumeDecoy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVolumeDecoy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitContainerDecoy) DeepCopyInto(out *InitContainerDecoy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitContainerDecoy.
+func (in *InitContainerDecoy) DeepCopy() *InitContainerDecoy {
+	if in == nil {
+		return nil
+	}
+	out := new(InitContainerDecoy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KoneyConfig) DeepCopyInto(out *KoneyConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KoneyConfig.
+func (in *KoneyConfig) DeepCopy() *KoneyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KoneyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KoneyConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KoneyConfigList) DeepCopyInto(out *KoneyConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KoneyConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KoneyConfigList.
+func (in *KoneyConfigList) DeepCopy() *KoneyConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(KoneyConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KoneyConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KoneyConfigSpec) DeepCopyInto(out *KoneyConfigSpec) {
+	*out = *in
+	if in.FeatureFlags != nil {
+		in, out := &in.FeatureFlags, &out.FeatureFlags
+		*out = make([]FeatureFlag, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KoneyConfigSpec.
+func (in *KoneyConfigSpec) DeepCopy() *KoneyConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KoneyConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchResources) DeepCopyInto(out *MatchResources) {
+	*out = *in
+	if in.Any != nil {
+		in, out := &in.Any, &out.Any
+		*out = make([]ResourceFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchResources.
+func (in *MatchResources) DeepCopy() *MatchResources {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceCoverage) DeepCopyInto(out *NamespaceCoverage) {
+	*out = *in
+	out.Summary = in.Summary
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadCoverage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceCoverage.
+func (in *NamespaceCoverage) DeepCopy() *NamespaceCoverage {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceCoverage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Quarantine) DeepCopyInto(out *Quarantine) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Quarantine.
+func (in *Quarantine) DeepCopy() *Quarantine {
+	if in == nil {
+		return nil
+	}
+	out := new(Quarantine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceDescription) DeepCopyInto(out *ResourceDescription) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(corev1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = new(ContainerSelection)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDescription.
+func (in *ResourceDescription) DeepCopy() *ResourceDescription {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceDescription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceFilter) DeepCopyInto(out *ResourceFilter) {
+	*out = *in
+	in.ResourceDescription.DeepCopyInto(&out.ResourceDescription)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFilter.
+func (in *ResourceFilter) DeepCopy() *ResourceFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfTest) DeepCopyInto(out *SelfTest) {
+	*out = *in
+	if in.AlertOnFailure != nil {
+		in, out := &in.AlertOnFailure, &out.AlertOnFailure
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfTest.
+func (in *SelfTest) DeepCopy() *SelfTest {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfTestStatus) DeepCopyInto(out *SelfTestStatus) {
+	*out = *in
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfTestStatus.
+func (in *SelfTestStatus) DeepCopy() *SelfTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyslogSinkSpec) DeepCopyInto(out *SyslogSinkSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogSinkSpec.
+func (in *SyslogSinkSpec) DeepCopy() *SyslogSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyslogSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenPlacement) DeepCopyInto(out *TokenPlacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenPlacement.
+func (in *TokenPlacement) DeepCopy() *TokenPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Trap) DeepCopyInto(out *Trap) {
+	*out = *in
+	if in.AttckTechniques != nil {
+		in, out := &in.AttckTechniques, &out.AttckTechniques
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EngageActivities != nil {
+		in, out := &in.EngageActivities, &out.EngageActivities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.FilesystemHoneytoken.DeepCopyInto(&out.FilesystemHoneytoken)
+	out.HttpEndpoint = in.HttpEndpoint
+	out.HttpPayload = in.HttpPayload
+	in.DecoyDeployment.DeepCopyInto(&out.DecoyDeployment)
+	in.CaptorDeployment.DeepCopyInto(&out.CaptorDeployment)
+	in.MatchResources.DeepCopyInto(&out.MatchResources)
+	if in.Alerting != nil {
+		in, out := &in.Alerting, &out.Alerting
+		*out = new(Alerting)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseActions != nil {
+		in, out := &in.ResponseActions, &out.ResponseActions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quarantine != nil {
+		in, out := &in.Quarantine, &out.Quarantine
+		*out = new(Quarantine)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FollowAttacker != nil {
+		in, out := &in.FollowAttacker, &out.FollowAttacker
+		*out = new(FollowAttacker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(TrapTemplateReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Trap.
+func (in *Trap) DeepCopy() *Trap {
+	if in == nil {
+		return nil
+	}
+	out := new(Trap)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpPayloadAnnotation) DeepCopyInto(out *HttpPayloadAnnotation) {
+func (in *TrapAnnotation) DeepCopyInto(out *TrapAnnotation) {
 	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.FilesystemHoneytoken = in.FilesystemHoneytoken
+	out.HttpEndpoint = in.HttpEndpoint
+	out.HttpPayload = in.HttpPayload
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpPayloadAnnotation.
-func (in *HttpPayloadAnnotation) DeepCopy() *HttpPayloadAnnotation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapAnnotation.
+func (in *TrapAnnotation) DeepCopy() *TrapAnnotation {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpPayloadAnnotation)
+	out := new(TrapAnnotation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MatchResources) DeepCopyInto(out *MatchResources) {
+func (in *TrapDefaults) DeepCopyInto(out *TrapDefaults) {
 	*out = *in
-	if in.Any != nil {
-		in, out := &in.Any, &out.Any
-		*out = make([]ResourceFilter, len(*in))
+	if in.DecoyDeployment != nil {
+		in, out := &in.DecoyDeployment, &out.DecoyDeployment
+		*out = new(DecoyDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FilesystemHoneytoken != nil {
+		in, out := &in.FilesystemHoneytoken, &out.FilesystemHoneytoken
+		*out = new(FilesystemHoneytokenDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CaptorDeployment != nil {
+		in, out := &in.CaptorDeployment, &out.CaptorDeployment
+		*out = new(CaptorDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MatchResources != nil {
+		in, out := &in.MatchResources, &out.MatchResources
+		*out = new(MatchResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Alerting != nil {
+		in, out := &in.Alerting, &out.Alerting
+		*out = new(Alerting)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapDefaults.
+func (in *TrapDefaults) DeepCopy() *TrapDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapDrift) DeepCopyInto(out *TrapDrift) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapDrift.
+func (in *TrapDrift) DeepCopy() *TrapDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapPlacement) DeepCopyInto(out *TrapPlacement) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FilePaths != nil {
+		in, out := &in.FilePaths, &out.FilePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapPlacement.
+func (in *TrapPlacement) DeepCopy() *TrapPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapPlan) DeepCopyInto(out *TrapPlan) {
+	*out = *in
+	if in.Placements != nil {
+		in, out := &in.Placements, &out.Placements
+		*out = make([]TrapPlacement, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchResources.
-func (in *MatchResources) DeepCopy() *MatchResources {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapPlan.
+func (in *TrapPlan) DeepCopy() *TrapPlan {
 	if in == nil {
 		return nil
 	}
-	out := new(MatchResources)
+	out := new(TrapPlan)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceDescription) DeepCopyInto(out *ResourceDescription) {
+func (in *TrapStatus) DeepCopyInto(out *TrapStatus) {
 	*out = *in
-	if in.Namespaces != nil {
-		in, out := &in.Namespaces, &out.Namespaces
-		*out = make([]string, len(*in))
+	if in.Placements != nil {
+		in, out := &in.Placements, &out.Placements
+		*out = make([]TrapPlacement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeployedAt != nil {
+		in, out := &in.DeployedAt, &out.DeployedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Drifts != nil {
+		in, out := &in.Drifts, &out.Drifts
+		*out = make([]TrapDrift, len(*in))
 		copy(*out, *in)
 	}
-	if in.Selector != nil {
-		in, out := &in.Selector, &out.Selector
-		*out = new(v1.LabelSelector)
+	if in.FailedPlacements != nil {
+		in, out := &in.FailedPlacements, &out.FailedPlacements
+		*out = make([]FailedPlacement, len(*in))
+		copy(*out, *in)
+	}
+	if in.Checksums != nil {
+		in, out := &in.Checksums, &out.Checksums
+		*out = make([]DecoyChecksum, len(*in))
+		copy(*out, *in)
+	}
+	if in.TokenPlacements != nil {
+		in, out := &in.TokenPlacements, &out.TokenPlacements
+		*out = make([]TokenPlacement, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapStatus.
+func (in *TrapStatus) DeepCopy() *TrapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapTemplate) DeepCopyInto(out *TrapTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapTemplate.
+func (in *TrapTemplate) DeepCopy() *TrapTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrapTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapTemplateList) DeepCopyInto(out *TrapTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TrapTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapTemplateList.
+func (in *TrapTemplateList) DeepCopy() *TrapTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrapTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapTemplateParameter) DeepCopyInto(out *TrapTemplateParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapTemplateParameter.
+func (in *TrapTemplateParameter) DeepCopy() *TrapTemplateParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapTemplateParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapTemplateReference) DeepCopyInto(out *TrapTemplateReference) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapTemplateReference.
+func (in *TrapTemplateReference) DeepCopy() *TrapTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapTemplateSpec) DeepCopyInto(out *TrapTemplateSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]TrapTemplateParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Trap.DeepCopyInto(&out.Trap)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapTemplateSpec.
+func (in *TrapTemplateSpec) DeepCopy() *TrapTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrapTemplateStatus) DeepCopyInto(out *TrapTemplateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapTemplateStatus.
+func (in *TrapTemplateStatus) DeepCopy() *TrapTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TrapTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookRetryPolicy) DeepCopyInto(out *WebhookRetryPolicy) {
+	*out = *in
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookRetryPolicy.
+func (in *WebhookRetryPolicy) DeepCopy() *WebhookRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSinkSpec) DeepCopyInto(out *WebhookSinkSpec) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(WebhookRetryPolicy)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDescription.
-func (in *ResourceDescription) DeepCopy() *ResourceDescription {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSinkSpec.
+func (in *WebhookSinkSpec) DeepCopy() *WebhookSinkSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceDescription)
+	out := new(WebhookSinkSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceFilter) DeepCopyInto(out *ResourceFilter) {
+func (in *WorkloadCoverage) DeepCopyInto(out *WorkloadCoverage) {
 	*out = *in
-	in.ResourceDescription.DeepCopyInto(&out.ResourceDescription)
+	if in.Traps != nil {
+		in, out := &in.Traps, &out.Traps
+		*out = make([]WorkloadTrap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFilter.
-func (in *ResourceFilter) DeepCopy() *ResourceFilter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadCoverage.
+func (in *WorkloadCoverage) DeepCopy() *WorkloadCoverage {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceFilter)
+	out := new(WorkloadCoverage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Trap) DeepCopyInto(out *Trap) {
+func (in *WorkloadReference) DeepCopyInto(out *WorkloadReference) {
 	*out = *in
-	out.FilesystemHoneytoken = in.FilesystemHoneytoken
-	out.HttpEndpoint = in.HttpEndpoint
-	out.HttpPayload = in.HttpPayload
-	out.DecoyDeployment = in.DecoyDeployment
-	out.CaptorDeployment = in.CaptorDeployment
-	in.MatchResources.DeepCopyInto(&out.MatchResources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Trap.
-func (in *Trap) DeepCopy() *Trap {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadReference.
+func (in *WorkloadReference) DeepCopy() *WorkloadReference {
 	if in == nil {
 		return nil
 	}
-	out := new(Trap)
+	out := new(WorkloadReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TrapAnnotation) DeepCopyInto(out *TrapAnnotation) {
+func (in *WorkloadTrap) DeepCopyInto(out *WorkloadTrap) {
 	*out = *in
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	out.FilesystemHoneytoken = in.FilesystemHoneytoken
-	out.HttpEndpoint = in.HttpEndpoint
-	out.HttpPayload = in.HttpPayload
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrapAnnotation.
-func (in *TrapAnnotation) DeepCopy() *TrapAnnotation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadTrap.
+func (in *WorkloadTrap) DeepCopy() *WorkloadTrap {
 	if in == nil {
 		return nil
 	}
-	out := new(TrapAnnotation)
+	out := new(WorkloadTrap)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1beta1
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &DeceptionPolicy{}
+
+// ConvertTo converts this DeceptionPolicy to the Hub version (v1alpha1).
+func (src *DeceptionPolicy) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha1.DeceptionPolicy)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 DeceptionPolicy but got %T", dstRaw)
+	}
+
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1alpha1.DeceptionPolicySpec{
+		TrapDefaults:           src.Spec.TrapDefaults,
+		StrictValidation:       src.Spec.StrictValidation,
+		MutateExisting:         src.Spec.MutateExisting,
+		MaxAlertsPerHour:       src.Spec.MaxAlertsPerHour,
+		AlertAggregationWindow: src.Spec.AlertAggregationWindow,
+		DryRun:                 src.Spec.DryRun,
+		ClusterPolicyOverrides: src.Spec.ClusterPolicyOverrides,
+		SelfTest:               src.Spec.SelfTest,
+	}
+	if src.Spec.Traps != nil {
+		dst.Spec.Traps = make([]v1alpha1.Trap, 0, len(src.Spec.Traps))
+		for _, trap := range src.Spec.Traps {
+			dst.Spec.Traps = append(dst.Spec.Traps, convertTrapToHub(trap))
+		}
+	}
+	dst.Status = src.Status
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this DeceptionPolicy.
+func (dst *DeceptionPolicy) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha1.DeceptionPolicy)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 DeceptionPolicy but got %T", srcRaw)
+	}
+
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = DeceptionPolicySpec{
+		TrapDefaults:           src.Spec.TrapDefaults,
+		StrictValidation:       src.Spec.StrictValidation,
+		MutateExisting:         src.Spec.MutateExisting,
+		MaxAlertsPerHour:       src.Spec.MaxAlertsPerHour,
+		AlertAggregationWindow: src.Spec.AlertAggregationWindow,
+		DryRun:                 src.Spec.DryRun,
+		ClusterPolicyOverrides: src.Spec.ClusterPolicyOverrides,
+		SelfTest:               src.Spec.SelfTest,
+	}
+	if src.Spec.Traps != nil {
+		dst.Spec.Traps = make([]Trap, 0, len(src.Spec.Traps))
+		for _, trap := range src.Spec.Traps {
+			dst.Spec.Traps = append(dst.Spec.Traps, convertTrapFromHub(trap))
+		}
+	}
+	dst.Status = src.Status
+
+	return nil
+}
+
+// convertTrapToHub converts a v1beta1 trap to a v1alpha1 trap, where the type is implied by the configured trap.
+func convertTrapToHub(trap Trap) v1alpha1.Trap {
+	hubTrap := v1alpha1.Trap{
+		Description:      trap.Description,
+		AttckTechniques:  trap.AttckTechniques,
+		EngageActivities: trap.EngageActivities,
+		Severity:         trap.Severity,
+		Confidence:       trap.Confidence,
+		DecoyDeployment:  trap.DecoyDeployment,
+		CaptorDeployment: trap.CaptorDeployment,
+		MatchResources:   trap.MatchResources,
+		Alerting:         trap.Alerting,
+		ResponseActions:  trap.ResponseActions,
+		Quarantine:       trap.Quarantine,
+		FollowAttacker:   trap.FollowAttacker,
+		TTL:              trap.TTL,
+		ExpiresAt:        trap.ExpiresAt,
+		TemplateRef:      trap.TemplateRef,
+	}
+	if trap.FilesystemHoneytoken != nil {
+		hubTrap.FilesystemHoneytoken = *trap.FilesystemHoneytoken
+	}
+	if trap.HttpEndpoint != nil {
+		hubTrap.HttpEndpoint = *trap.HttpEndpoint
+	}
+	if trap.HttpPayload != nil {
+		hubTrap.HttpPayload = *trap.HttpPayload
+	}
+
+	return hubTrap
+}
+
+// convertTrapFromHub converts a v1alpha1 trap to a v1beta1 trap with an explicit type.
+// All configured traps are kept, even if v1alpha1 considers more than one of them invalid.
+func convertTrapFromHub(hubTrap v1alpha1.Trap) Trap {
+	trap := Trap{
+		Description:      hubTrap.Description,
+		AttckTechniques:  hubTrap.AttckTechniques,
+		EngageActivities: hubTrap.EngageActivities,
+		Severity:         hubTrap.Severity,
+		Confidence:       hubTrap.Confidence,
+		DecoyDeployment:  hubTrap.DecoyDeployment,
+		CaptorDeployment: hubTrap.CaptorDeployment,
+		MatchResources:   hubTrap.MatchResources,
+		Alerting:         hubTrap.Alerting,
+		ResponseActions:  hubTrap.ResponseActions,
+		Quarantine:       hubTrap.Quarantine,
+		FollowAttacker:   hubTrap.FollowAttacker,
+		TTL:              hubTrap.TTL,
+		ExpiresAt:        hubTrap.ExpiresAt,
+		TemplateRef:      hubTrap.TemplateRef,
+	}
+	if trapType := hubTrap.TrapType(); trapType != v1alpha1.UnknownTrap {
+		trap.Type = trapType
+	}
+	if !hubTrap.FilesystemHoneytoken.IsZero() {
+		trap.FilesystemHoneytoken = &hubTrap.FilesystemHoneytoken
+	}
+	if hubTrap.HttpEndpoint != (v1alpha1.HttpEndpoint{}) {
+		trap.HttpEndpoint = &hubTrap.HttpEndpoint
+	}
+	if hubTrap.HttpPayload != (v1alpha1.HttpPayload{}) {
+		trap.HttpPayload = &hubTrap.HttpPayload
+	}
+
+	return trap
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1beta1
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// jsonFieldNames returns the JSON field names of a struct type, ignoring the given ones.
+func jsonFieldNames(t reflect.Type, ignored ...string) []string {
+	names := []string{}
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || slices.Contains(ignored, name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+var _ = Describe("DeceptionPolicy conversion", func() {
+	var hub *v1alpha1.DeceptionPolicy
+
+	BeforeEach(func() {
+		hub = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample", Generation: 3},
+			Spec: v1alpha1.DeceptionPolicySpec{
+				Traps: []v1alpha1.Trap{
+					{
+						Description:      "fake service account token",
+						AttckTechniques:  []string{"T1552.001", "T1528"},
+						EngageActivities: []string{"EAC0005"},
+						Severity:         "high",
+						Confidence:       "medium",
+						FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
+							FilePath:    "/run/secrets/koney/service_token",
+							FileContent: "someverysecrettoken",
+							ReadOnly:    &[]bool{true}[0],
+							RotateEvery: &metav1.Duration{Duration: time.Hour},
+						},
+						DecoyDeployment: v1alpha1.DecoyDeployment{
+							Strategy: "imageVolume",
+							ImageVolume: &v1alpha1.ImageVolumeDecoy{
+								Image:      "registry.example.com/decoys:latest",
+								PullPolicy: corev1.PullIfNotPresent,
+							},
+						},
+						CaptorDeployment: v1alpha1.CaptorDeployment{Strategy: "tetragon"},
+						MatchResources: v1alpha1.MatchResources{
+							Any: []v1alpha1.ResourceFilter{{
+								ResourceDescription: v1alpha1.ResourceDescription{
+									Namespaces: []string{"koney-demo"},
+									Selector: &metav1.LabelSelector{
+										MatchLabels: map[string]string{"demo.koney/honeytoken": "true"},
+									},
+									ContainerSelector: "*",
+								},
+							}},
+						},
+						Alerting:        &v1alpha1.Alerting{Severity: "high", Tags: map[string]string{"team": "blue"}},
+						ResponseActions: []string{"labelPod", "killProcess"},
+						Quarantine:      &v1alpha1.Quarantine{TTL: &metav1.Duration{Duration: time.Hour}, CiliumNetworkPolicy: true},
+						FollowAttacker:  &v1alpha1.FollowAttacker{Duration: &metav1.Duration{Duration: 30 * time.Minute}},
+						TTL:             &metav1.Duration{Duration: 24 * time.Hour},
+					},
+				},
+				TrapDefaults: &v1alpha1.TrapDefaults{
+					CaptorDeployment: &v1alpha1.CaptorDeployment{Strategy: "tetragon"},
+				},
+				StrictValidation:       &[]bool{true}[0],
+				MutateExisting:         &[]bool{false}[0],
+				MaxAlertsPerHour:       &[]int32{60}[0],
+				AlertAggregationWindow: &metav1.Duration{Duration: 5 * time.Minute},
+				ClusterPolicyOverrides: []v1alpha1.ClusterPolicyOverride{
+					{Name: "baseline", Namespaces: []string{"koney-demo"}},
+				},
+				SelfTest: &v1alpha1.SelfTest{Schedule: "*/30 * * * *", AlertOnFailure: &[]bool{true}[0]},
+			},
+			Status: v1alpha1.DeceptionPolicyStatus{ObservedGeneration: 3},
+		}
+	})
+
+	It("should set the trap type when converting from the hub", func() {
+		spoke := &DeceptionPolicy{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+
+		Expect(spoke.Spec.Traps).To(HaveLen(1))
+		Expect(spoke.Spec.Traps[0].Type).To(Equal(v1alpha1.FilesystemHoneytokenTrap))
+		Expect(spoke.Spec.Traps[0].FilesystemHoneytoken).NotTo(BeNil())
+		Expect(spoke.Spec.Traps[0].HttpEndpoint).To(BeNil())
+		Expect(spoke.Spec.Traps[0].HttpPayload).To(BeNil())
+	})
+
+	It("should leave the trap type empty if the hub trap has no type", func() {
+		hub.Spec.Traps[0].FilesystemHoneytoken = v1alpha1.FilesystemHoneytoken{}
+
+		spoke := &DeceptionPolicy{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Spec.Traps[0].Type).To(BeEmpty())
+		Expect(spoke.Spec.Traps[0].FilesystemHoneytoken).To(BeNil())
+	})
+
+	It("should round-trip a fully populated DeceptionPolicy without loss", func() {
+		spoke := &DeceptionPolicy{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+
+		converted := &v1alpha1.DeceptionPolicy{}
+		Expect(spoke.ConvertTo(converted)).To(Succeed())
+		Expect(converted).To(Equal(hub))
+	})
+
+	It("should round-trip a trap that references a TrapTemplate", func() {
+		hub.Spec.Traps = append(hub.Spec.Traps, v1alpha1.Trap{
+			TemplateRef: &v1alpha1.TrapTemplateReference{Name: "aws-credentials", Parameters: map[string]string{"profile": "prod"}},
+		})
+
+		spoke := &DeceptionPolicy{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Spec.Traps[1].TemplateRef).NotTo(BeNil())
+		Expect(spoke.Spec.Traps[1].TemplateRef.Name).To(Equal("aws-credentials"))
+
+		converted := &v1alpha1.DeceptionPolicy{}
+		Expect(spoke.ConvertTo(converted)).To(Succeed())
+		Expect(converted).To(Equal(hub))
+	})
+
+	It("should not share memory between the converted objects", func() {
+		spoke := &DeceptionPolicy{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+
+		spoke.Spec.Traps[0].FilesystemHoneytoken.FileContent = "changed"
+		spoke.Spec.Traps[0].Alerting.Tags["team"] = "red"
+		Expect(hub.Spec.Traps[0].FilesystemHoneytoken.FileContent).To(Equal("someverysecrettoken"))
+		Expect(hub.Spec.Traps[0].Alerting.Tags["team"]).To(Equal("blue"))
+	})
+
+	It("should keep the fields of both versions in sync", func() {
+		// New fields must be added to both versions and to the conversion functions
+		Expect(jsonFieldNames(reflect.TypeOf(DeceptionPolicySpec{}))).To(
+			Equal(jsonFieldNames(reflect.TypeOf(v1alpha1.DeceptionPolicySpec{}))))
+		Expect(jsonFieldNames(reflect.TypeOf(Trap{}), "type")).To(
+			Equal(jsonFieldNames(reflect.TypeOf(v1alpha1.Trap{}))))
+	})
+})
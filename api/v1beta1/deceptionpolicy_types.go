@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// DeceptionPolicy is the Schema for the deceptionpolicies API
+type DeceptionPolicy struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Spec is the specification of the DeceptionPolicy.
+	Spec DeceptionPolicySpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+
+	// Status is the status of the DeceptionPolicy.
+	Status v1alpha1.DeceptionPolicyStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DeceptionPolicyList contains a list of DeceptionPolicy
+type DeceptionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeceptionPolicy `json:"items"`
+}
+
+// DeceptionPolicySpec defines the desired state of DeceptionPolicy
+type DeceptionPolicySpec struct {
+	// Traps is a list of traps to be deployed by the deception policy.
+	// Each trap represents a cyber deception technique.
+	Traps []Trap `json:"traps,omitempty" yaml:"traps,omitempty"`
+
+	// TrapDefaults are defaults that cascade to all traps, to avoid repeating them in every trap.
+	// They are applied when the DeceptionPolicy is created or updated. Fields that are set in a trap take precedence.
+	// +optional
+	TrapDefaults *v1alpha1.TrapDefaults `json:"trapDefaults,omitempty" yaml:"trapDefaults,omitempty"`
+
+	// StrictValidation is a flag that indicates whether the policy should be strictly validated.
+	// If set to true, the traps will be deployed only if all the traps in the policy are valid.
+	// If set to false, the valid traps will be deployed even if some of the traps are invalid.
+	// By default, it is set to true.
+	// +optional
+	// +kubebuilder:default:=true
+	StrictValidation *bool `json:"strictValidation,omitempty" yaml:"strictValidation,omitempty"`
+
+	// MutateExisting is a flag to also allow adding traps to existing resources.
+	// Typically, that means that existing resource definitions will be updated to include the traps.
+	// Depending on the decoy and captor deployment strategies, this may require restarting the pods.
+	// +optional
+	// +kubebuilder:default=true
+	MutateExisting *bool `json:"mutateExisting,omitempty" yaml:"mutateExisting,omitempty"`
+
+	// MaxAlertsPerHour is the maximum number of alerts that are forwarded for this policy within one hour.
+	// Once the quota is exceeded, a single meta-alert is emitted and further alerts are suppressed (and counted)
+	// until the quota is available again. This protects downstream systems from runaway traps.
+	// If not set, alerts are not limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAlertsPerHour *int32 `json:"maxAlertsPerHour,omitempty" yaml:"maxAlertsPerHour,omitempty"`
+
+	// AlertAggregationWindow is the time window in which near-identical alerts are aggregated into one.
+	// Alerts of the same trap, pod, and process binary are grouped: the first one is forwarded right away,
+	// and the others within the window are summarized in a single alert with a hit count and first/last timestamps.
+	// If not set, every alert is forwarded on its own.
+	// +optional
+	AlertAggregationWindow *metav1.Duration `json:"alertAggregationWindow,omitempty" yaml:"alertAggregationWindow,omitempty"`
+
+	// DryRun is a flag to only compute which resources, containers, and files the traps would be placed in,
+	// without deploying, changing, or removing any decoys and captors. The result is published in the plan of the status.
+	// Alternatively, the annotation "koney/dry-run: true" enables the dry run as well.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+
+	// ClusterPolicyOverrides exempt namespaces from ClusterDeceptionPolicies, so that only the traps of this DeceptionPolicy
+	// are applied there. A DeceptionPolicy without traps opts the namespaces out of the ClusterDeceptionPolicies.
+	// +optional
+	ClusterPolicyOverrides []v1alpha1.ClusterPolicyOverride `json:"clusterPolicyOverrides,omitempty" yaml:"clusterPolicyOverrides,omitempty"`
+
+	// SelfTest runs periodic self-tests of the detection chain of this DeceptionPolicy from a dedicated test pod.
+	// The results are reported in the status, and an alert is sent to the sinks if a self-test fails.
+	// If not set, this DeceptionPolicy is not self-tested periodically.
+	// +optional
+	SelfTest *v1alpha1.SelfTest `json:"selfTest,omitempty" yaml:"selfTest,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeceptionPolicy{}, &DeceptionPolicyList{})
+}
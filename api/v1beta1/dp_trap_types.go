@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// Trap describes a cyber deception technique, also simply known as a trap.
+// It is a discriminated union: the Type field selects which of the trap-specific fields configures the trap.
+// +kubebuilder:validation:XValidation:rule="self.type == 'FilesystemHoneytoken' ? has(self.filesystemHoneytoken) : !has(self.filesystemHoneytoken)",message="filesystemHoneytoken must be set if and only if the type is FilesystemHoneytoken"
+// +kubebuilder:validation:XValidation:rule="self.type == 'HttpEndpoint' ? has(self.httpEndpoint) : !has(self.httpEndpoint)",message="httpEndpoint must be set if and only if the type is HttpEndpoint"
+// +kubebuilder:validation:XValidation:rule="self.type == 'HttpPayload' ? has(self.httpPayload) : !has(self.httpPayload)",message="httpPayload must be set if and only if the type is HttpPayload"
+type Trap struct {
+	// Type is the type of the trap.
+	// +unionDiscriminator
+	// +kubebuilder:validation:Enum=FilesystemHoneytoken;HttpEndpoint;HttpPayload
+	Type v1alpha1.TrapType `json:"type" yaml:"type"`
+
+	// Description is a human-readable description of the trap.
+	// It is propagated to the captors and to the emitted alerts, to explain why the trap exists.
+	// +optional
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// AttckTechniques are the IDs of the MITRE ATT&CK techniques that the trap detects (e.g., "T1552.001").
+	// They are propagated as labels to the captors and included in the emitted alerts.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Pattern=`^T[0-9]{4}(\.[0-9]{3})?$`
+	AttckTechniques []string `json:"attckTechniques,omitempty" yaml:"attckTechniques,omitempty"`
+
+	// EngageActivities are the IDs of the MITRE Engage activities that the trap implements (e.g., "EAC0005").
+	// They are propagated as labels to the captors and included in the emitted alerts.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Pattern=`^EAC[0-9]{4}$`
+	EngageActivities []string `json:"engageActivities,omitempty" yaml:"engageActivities,omitempty"`
+
+	// Severity is how severe an access to the trap is, i.e., "info", "low", "medium", "high", or "critical".
+	// It is propagated as a label to the captors and sets the severity of the emitted alerts,
+	// unless the alerting configuration overrides it.
+	// +kubebuilder:validation:Enum=info;low;medium;high;critical
+	// +optional
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
+	// Confidence is how likely an access to the trap is malicious, i.e., "low", "medium", or "high".
+	// It is propagated as a label to the captors and included in the emitted alerts.
+	// +kubebuilder:validation:Enum=low;medium;high
+	// +optional
+	Confidence string `json:"confidence,omitempty" yaml:"confidence,omitempty"`
+
+	// FilesystemHoneytoken is the configuration for a filesystem honeytoken trap.
+	// +optional
+	FilesystemHoneytoken *v1alpha1.FilesystemHoneytoken `json:"filesystemHoneytoken,omitempty" yaml:"filesystemHoneytoken,omitempty"`
+
+	// HttpEndpoint is the configuration for an HTTP endpoint trap.
+	// +optional
+	HttpEndpoint *v1alpha1.HttpEndpoint `json:"httpEndpoint,omitempty" yaml:"httpEndpoint,omitempty"`
+
+	// HttpPayload is the configuration for an HTTP payload trap.
+	// +optional
+	HttpPayload *v1alpha1.HttpPayload `json:"httpPayload,omitempty" yaml:"httpPayload,omitempty"`
+
+	// DecoyDeployment configures how traps (the entities that are attacked) are going to be deployed.
+	// +optional
+	DecoyDeployment v1alpha1.DecoyDeployment `json:"decoyDeployment,omitempty" yaml:"decoyDeployment,omitempty"`
+
+	// CaptorDeployment configures how captors (the entities that monitor access to the traps) are going to be deployed.
+	// +optional
+	CaptorDeployment v1alpha1.CaptorDeployment `json:"captorDeployment,omitempty" yaml:"captorDeployment,omitempty"`
+
+	// Match define what Kubernetes resources to apply this trap to.
+	// Matching criteria are resources labels and/or namespaces.
+	// +optional
+	MatchResources v1alpha1.MatchResources `json:"match,omitempty" yaml:"match,omitempty"`
+
+	// Alerting configures the alerts that are emitted when this trap is accessed.
+	// +optional
+	Alerting *v1alpha1.Alerting `json:"alerting,omitempty" yaml:"alerting,omitempty"`
+
+	// ResponseActions are taken automatically when this trap is accessed, to contain the attacker.
+	// "killProcess" kills the processes of the accessing binary in the container, "killPod" deletes the pod,
+	// "labelPod" labels the pod as quarantined, "networkIsolate" labels the pod and denies all its network traffic,
+	// and "followAttacker" deploys a Tetragon TracingPolicy that traces all executions and connections of the pod for a while.
+	// By default, no actions are taken and accesses are only alerted.
+	// +kubebuilder:validation:items:Enum=killProcess;killPod;labelPod;networkIsolate;followAttacker
+	// +listType=set
+	// +optional
+	ResponseActions []string `json:"responseActions,omitempty" yaml:"responseActions,omitempty"`
+
+	// Quarantine isolates pods that access this trap from the network, like the "networkIsolate" response action,
+	// but can lift the quarantine automatically and use a CiliumNetworkPolicy in addition.
+	// +optional
+	Quarantine *v1alpha1.Quarantine `json:"quarantine,omitempty" yaml:"quarantine,omitempty"`
+
+	// FollowAttacker monitors pods that access this trap more closely, like the "followAttacker" response action,
+	// but can configure for how long.
+	// +optional
+	FollowAttacker *v1alpha1.FollowAttacker `json:"followAttacker,omitempty" yaml:"followAttacker,omitempty"`
+
+	// TTL is the time to live of the trap, counted from the creation of the DeceptionPolicy.
+	// Once it passed, the trap is removed automatically (e.g., for time-boxed red-team exercises).
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+
+	// ExpiresAt is the point in time when the trap is removed automatically.
+	// If both TTL and ExpiresAt are set, the trap expires at whichever comes first.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+
+	// TemplateRef references a TrapTemplate that this trap is expanded from, with the values of its parameters.
+	// +optional
+	TemplateRef *v1alpha1.TrapTemplateReference `json:"templateRef,omitempty" yaml:"templateRef,omitempty"`
+}
@@ -0,0 +1,212 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicy) DeepCopyInto(out *DeceptionPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicy.
+func (in *DeceptionPolicy) DeepCopy() *DeceptionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicyList) DeepCopyInto(out *DeceptionPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeceptionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicyList.
+func (in *DeceptionPolicyList) DeepCopy() *DeceptionPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeceptionPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeceptionPolicySpec) DeepCopyInto(out *DeceptionPolicySpec) {
+	*out = *in
+	if in.Traps != nil {
+		in, out := &in.Traps, &out.Traps
+		*out = make([]Trap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TrapDefaults != nil {
+		in, out := &in.TrapDefaults, &out.TrapDefaults
+		*out = new(v1alpha1.TrapDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StrictValidation != nil {
+		in, out := &in.StrictValidation, &out.StrictValidation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MutateExisting != nil {
+		in, out := &in.MutateExisting, &out.MutateExisting
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxAlertsPerHour != nil {
+		in, out := &in.MaxAlertsPerHour, &out.MaxAlertsPerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AlertAggregationWindow != nil {
+		in, out := &in.AlertAggregationWindow, &out.AlertAggregationWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClusterPolicyOverrides != nil {
+		in, out := &in.ClusterPolicyOverrides, &out.ClusterPolicyOverrides
+		*out = make([]v1alpha1.ClusterPolicyOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SelfTest != nil {
+		in, out := &in.SelfTest, &out.SelfTest
+		*out = new(v1alpha1.SelfTest)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeceptionPolicySpec.
+func (in *DeceptionPolicySpec) DeepCopy() *DeceptionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeceptionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Trap) DeepCopyInto(out *Trap) {
+	*out = *in
+	if in.AttckTechniques != nil {
+		in, out := &in.AttckTechniques, &out.AttckTechniques
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EngageActivities != nil {
+		in, out := &in.EngageActivities, &out.EngageActivities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FilesystemHoneytoken != nil {
+		in, out := &in.FilesystemHoneytoken, &out.FilesystemHoneytoken
+		*out = new(v1alpha1.FilesystemHoneytoken)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HttpEndpoint != nil {
+		in, out := &in.HttpEndpoint, &out.HttpEndpoint
+		*out = new(v1alpha1.HttpEndpoint)
+		**out = **in
+	}
+	if in.HttpPayload != nil {
+		in, out := &in.HttpPayload, &out.HttpPayload
+		*out = new(v1alpha1.HttpPayload)
+		**out = **in
+	}
+	in.DecoyDeployment.DeepCopyInto(&out.DecoyDeployment)
+	in.CaptorDeployment.DeepCopyInto(&out.CaptorDeployment)
+	in.MatchResources.DeepCopyInto(&out.MatchResources)
+	if in.Alerting != nil {
+		in, out := &in.Alerting, &out.Alerting
+		*out = new(v1alpha1.Alerting)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseActions != nil {
+		in, out := &in.ResponseActions, &out.ResponseActions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quarantine != nil {
+		in, out := &in.Quarantine, &out.Quarantine
+		*out = new(v1alpha1.Quarantine)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FollowAttacker != nil {
+		in, out := &in.FollowAttacker, &out.FollowAttacker
+		*out = new(v1alpha1.FollowAttacker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(v1alpha1.TrapTemplateReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Trap.
+func (in *Trap) DeepCopy() *Trap {
+	if in == nil {
+		return nil
+	}
+	out := new(Trap)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+	"github.com/dynatrace-oss/koney/pkg/client"
+)
+
+// sinkPollInterval is how often the status of the alert sinks is checked.
+// The alert forwarder reports its deliveries to the status of the sinks every 15 seconds.
+const sinkPollInterval = 5 * time.Second
+
+// simulateAttackOptions are the flags of the simulate-attack command.
+type simulateAttackOptions struct {
+	kubeconfig     string
+	kubeContext    string
+	koneyNamespace string
+	trapIndex      int
+	filePath       string
+	pod            string
+	container      string
+	sinks          string
+	timeout        time.Duration
+}
+
+// attackTarget is the decoy that a simulated attack accesses, and the resource and container that it is placed in.
+type attackTarget struct {
+	TrapIndex int
+	FilePath  string
+	Placement v1alpha1.TrapPlacement
+	Container string
+}
+
+func runSimulateAttack(args []string) int {
+	opts := simulateAttackOptions{}
+	flags := flag.NewFlagSet("simulate-attack", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), `Usage: kubectl koney simulate-attack <deception policy> [flags]
+
+Execs into a pod that a trap of the deception policy is placed in, and reads a decoy file without
+the fingerprint of Koney, so that the access raises an alert like the access of an attacker would.
+Then waits until the alert forwarder emitted the alert and delivered it to the alert sinks.
+
+Flags:
+`)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to KUBECONFIG or ~/.kube/config)")
+	flags.StringVar(&opts.kubeContext, "context", "", "The context of the kubeconfig to use")
+	flags.StringVar(&opts.koneyNamespace, "koney-namespace", client.DefaultKoneyNamespace, "The namespace where Koney is installed")
+	flags.IntVar(&opts.trapIndex, "trap", -1, "The index of the trap in the deception policy (defaults to the first filesystem honeytoken)")
+	flags.StringVar(&opts.filePath, "file-path", "", "The decoy file to read (defaults to the first decoy of the trap)")
+	flags.StringVar(&opts.pod, "pod", "", "The pod to read the decoy in, as namespace/name (defaults to the first resource that the trap is placed in)")
+	flags.StringVar(&opts.container, "container", "", "The container to read the decoy in (defaults to the first container with decoys)")
+	flags.StringVar(&opts.sinks, "sinks", "", "Comma-separated names of the alert sinks that must receive the alert (defaults to all sinks)")
+	flags.DurationVar(&opts.timeout, "timeout", 2*time.Minute, "How long to wait for the alert to reach the sinks")
+
+	// the name of the deception policy may come before or after the flags
+	if err := flags.Parse(args); err != nil {
+		return exitCodeOf(err)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	deceptionPolicyName := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return exitCodeOf(err)
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := simulateAttack(ctx, deceptionPolicyName, opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "simulated attack failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// exitCodeOf returns the exit code for an error of parsing the flags, which is zero if only the help was requested.
+func exitCodeOf(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
+
+// simulateAttack reads a decoy of the deception policy and waits until its alert reached the alert sinks.
+func simulateAttack(ctx context.Context, deceptionPolicyName string, opts simulateAttackOptions, out io.Writer) error {
+	config, err := loadConfig(opts.kubeconfig, opts.kubeContext)
+	if err != nil {
+		return fmt.Errorf("unable to load the kubeconfig: %w", err)
+	}
+	koney, err := client.New(config)
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	deceptionPolicy, err := koney.DeceptionPolicies().Get(ctx, deceptionPolicyName)
+	if err != nil {
+		return err
+	}
+	target, err := selectAttackTarget(deceptionPolicy, opts)
+	if err != nil {
+		return err
+	}
+	pod, err := resolveTargetPod(ctx, clientset, target.Placement)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	// subscribe before the access, so that the alert cannot be missed
+	alertsChan, errorsChan, err := client.SubscribeAlerts(ctx, clientset, client.AlertStreamOptions{Namespace: opts.koneyNamespace})
+	if err != nil {
+		return fmt.Errorf("unable to stream the alerts of Koney: %w", err)
+	}
+	go func() {
+		for err := range errorsChan {
+			fmt.Fprintf(os.Stderr, "warning: unable to stream alerts: %v\n", err)
+		}
+	}()
+
+	fmt.Fprintf(out, "Reading %s in container %s of pod %s/%s (trap %d)\n", target.FilePath, target.Container, pod.Namespace, pod.Name, target.TrapIndex)
+	accessedAt := time.Now()
+
+	// Koney reads its decoys with a fingerprint that the alert forwarder filters, but this access has none
+	if _, err := utils.ExecuteCommandInContainer(ctx, clientset, config, *pod, target.Container, []string{"cat", target.FilePath}); err != nil {
+		return fmt.Errorf("unable to read %s: %w", target.FilePath, err)
+	}
+
+	alert, err := waitForAlert(ctx, alertsChan, deceptionPolicyName, pod, target.FilePath)
+	if err != nil {
+		return err
+	}
+	severity := "-"
+	if alert.Severity != nil {
+		severity = string(*alert.Severity)
+	}
+	fmt.Fprintf(out, "Alert emitted after %s (severity %s)\n", time.Since(accessedAt).Round(time.Second), severity)
+
+	return waitForSinks(ctx, koney.DeceptionAlertSinks(opts.koneyNamespace), splitNames(opts.sinks), alert.Timestamp, out)
+}
+
+// loadConfig loads the kubeconfig like kubectl does, from the given file or the default locations.
+func loadConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
+// selectAttackTarget selects the trap, decoy, resource, and container that the simulated attack accesses,
+// from the placements in the status of the deception policy. Unset options select the first candidate.
+func selectAttackTarget(deceptionPolicy *v1alpha1.DeceptionPolicy, opts simulateAttackOptions) (*attackTarget, error) {
+	var trapStatus *v1alpha1.TrapStatus
+	for i := range deceptionPolicy.Status.Traps {
+		status := &deceptionPolicy.Status.Traps[i]
+		if opts.trapIndex == status.Index || (opts.trapIndex < 0 && status.TrapType == v1alpha1.FilesystemHoneytokenTrap) {
+			trapStatus = status
+			break
+		}
+	}
+	if trapStatus == nil {
+		if opts.trapIndex >= 0 {
+			return nil, fmt.Errorf("the deception policy has no deployed trap with index %d", opts.trapIndex)
+		}
+		return nil, errors.New("the deception policy has no deployed filesystem honeytoken")
+	}
+	if trapStatus.TrapType != v1alpha1.FilesystemHoneytokenTrap {
+		return nil, fmt.Errorf("trap %d is a %s trap, but only filesystem honeytokens can be accessed", trapStatus.Index, trapStatus.TrapType)
+	}
+
+	namespace, name, _ := strings.Cut(opts.pod, "/")
+	for _, placement := range trapStatus.Placements {
+		if opts.pod != "" && (placement.Kind != "Pod" || placement.Namespace != namespace || placement.Name != name) {
+			continue
+		}
+		if opts.filePath != "" && !slices.Contains(placement.FilePaths, opts.filePath) {
+			continue
+		}
+		if opts.container != "" && !slices.Contains(placement.Containers, opts.container) {
+			continue
+		}
+		if len(placement.Containers) == 0 || len(placement.FilePaths) == 0 {
+			continue
+		}
+
+		target := &attackTarget{TrapIndex: trapStatus.Index, FilePath: opts.filePath, Placement: placement, Container: opts.container}
+		if target.FilePath == "" {
+			target.FilePath = placement.FilePaths[0]
+		}
+		if target.Container == "" {
+			target.Container = placement.Containers[0]
+		}
+		return target, nil
+	}
+
+	return nil, fmt.Errorf("trap %d is not placed in a matching pod or container", trapStatus.Index)
+}
+
+// resolveTargetPod returns the pod that the decoy is read in. Decoys in deployments are read in one of their running pods.
+func resolveTargetPod(ctx context.Context, clientset kubernetes.Interface, placement v1alpha1.TrapPlacement) (*corev1.Pod, error) {
+	switch placement.Kind {
+	case "Pod":
+		return clientset.CoreV1().Pods(placement.Namespace).Get(ctx, placement.Name, metav1.GetOptions{})
+	case "Deployment":
+		deployment, err := clientset.AppsV1().Deployments(placement.Namespace).Get(ctx, placement.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return findRunningPod(ctx, clientset, deployment)
+	default:
+		return nil, fmt.Errorf("decoys in a %s cannot be accessed", placement.Kind)
+	}
+}
+
+// findRunningPod returns a running pod of the deployment.
+func findRunningPod(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment) (*corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("the deployment %s/%s has no running pods", deployment.Namespace, deployment.Name)
+}
+
+// waitForAlert waits until the alert forwarder emits the alert of the simulated attack.
+func waitForAlert(ctx context.Context, alertsChan <-chan alerts.KoneyAlert, deceptionPolicyName string, pod *corev1.Pod, filePath string) (*alerts.KoneyAlert, error) {
+	for {
+		select {
+		case alert, ok := <-alertsChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil, errors.New("no alert was emitted before the timeout, check that the captor of the trap is deployed")
+				}
+				return nil, errors.New("the alert stream ended before the alert was emitted, the alert forwarder might have restarted")
+			}
+			if isAlertOfAttack(alert, deceptionPolicyName, pod, filePath) {
+				return &alert, nil
+			}
+		case <-ctx.Done():
+			return nil, errors.New("no alert was emitted before the timeout, check that the captor of the trap is deployed")
+		}
+	}
+}
+
+// isAlertOfAttack returns true if the alert was raised for reading the decoy in the pod.
+func isAlertOfAttack(alert alerts.KoneyAlert, deceptionPolicyName string, pod *corev1.Pod, filePath string) bool {
+	if alert.DeceptionPolicyName == nil || *alert.DeceptionPolicyName != deceptionPolicyName {
+		return false
+	}
+	if alert.Pod == nil || alert.Pod.Namespace != pod.Namespace || alert.Pod.Name != pod.Name {
+		return false
+	}
+	alertFilePath, ok := alert.FilePath()
+	return !ok || alertFilePath == filePath
+}
+
+// waitForSinks waits until the alert sinks report the delivery of the alert with the given timestamp.
+// Without names, all sinks must report it.
+func waitForSinks(ctx context.Context, alertSinks *client.DeceptionAlertSinkClient, names []string, alertTimestamp string, out io.Writer) error {
+	reported := map[string]bool{}
+
+	for {
+		sinks, err := alertSinks.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		pending := pendingSinks(sinks.Items, names, alertTimestamp)
+		for _, sink := range sinks.Items {
+			if (len(names) == 0 || slices.Contains(names, sink.Name)) && !slices.Contains(pending, sink.Name) && !reported[sink.Name] {
+				fmt.Fprintf(out, "Alert delivered to sink %s\n", sink.Name)
+				reported[sink.Name] = true
+			}
+		}
+		if len(sinks.Items) == 0 && len(names) == 0 {
+			fmt.Fprintln(out, "No alert sinks are configured, so the alert was only written to the output of the alert forwarder")
+			return nil
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the alert was not delivered to the sinks %s before the timeout", strings.Join(pending, ", "))
+		case <-time.After(sinkPollInterval):
+		}
+	}
+}
+
+// pendingSinks returns the names of the sinks that did not report the delivery of the alert with the given timestamp.
+// Other alerts that were delivered in the meantime do not count. Sinks that are selected by name but do not exist are pending, too.
+func pendingSinks(sinks []v1alpha1.DeceptionAlertSink, names []string, alertTimestamp string) []string {
+	pending := []string{}
+	for _, name := range names {
+		if !slices.ContainsFunc(sinks, func(sink v1alpha1.DeceptionAlertSink) bool { return sink.Name == name }) {
+			pending = append(pending, name)
+		}
+	}
+	for _, sink := range sinks {
+		if len(names) > 0 && !slices.Contains(names, sink.Name) {
+			continue
+		}
+		if !slices.Contains(sink.Status.RecentAlertTimestamps, alertTimestamp) {
+			pending = append(pending, sink.Name)
+		}
+	}
+	slices.Sort(pending)
+	return pending
+}
+
+// splitNames splits a comma-separated list of names.
+func splitNames(value string) []string {
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+var _ = Describe("selectAttackTarget", func() {
+	deceptionPolicy := &v1alpha1.DeceptionPolicy{Status: v1alpha1.DeceptionPolicyStatus{Traps: []v1alpha1.TrapStatus{
+		{Index: 0, TrapType: v1alpha1.HttpEndpointTrap},
+		{Index: 1, TrapType: v1alpha1.FilesystemHoneytokenTrap, Placements: []v1alpha1.TrapPlacement{
+			{Kind: "Deployment", Namespace: "koney-demo", Name: "nginx", Containers: []string{"nginx", "sidecar"}, FilePaths: []string{"/run/secrets/koney/token"}},
+			{Kind: "Pod", Namespace: "koney-demo", Name: "busybox", Containers: []string{"busybox"}, FilePaths: []string{"/run/secrets/koney/token", "/root/.aws/credentials"}},
+		}},
+	}}}
+
+	It("should select the first placement of the first filesystem honeytoken", func() {
+		target, err := selectAttackTarget(deceptionPolicy, simulateAttackOptions{trapIndex: -1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.TrapIndex).To(Equal(1))
+		Expect(target.Placement.Name).To(Equal("nginx"))
+		Expect(target.Container).To(Equal("nginx"))
+		Expect(target.FilePath).To(Equal("/run/secrets/koney/token"))
+	})
+
+	It("should select the placement with the given pod and file path", func() {
+		target, err := selectAttackTarget(deceptionPolicy, simulateAttackOptions{
+			trapIndex: -1, pod: "koney-demo/busybox", filePath: "/root/.aws/credentials",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.Placement.Name).To(Equal("busybox"))
+		Expect(target.FilePath).To(Equal("/root/.aws/credentials"))
+
+		_, err = selectAttackTarget(deceptionPolicy, simulateAttackOptions{trapIndex: -1, container: "other"})
+		Expect(err).To(MatchError(ContainSubstring("not placed in a matching pod or container")))
+	})
+
+	It("should reject traps that cannot be accessed", func() {
+		_, err := selectAttackTarget(deceptionPolicy, simulateAttackOptions{trapIndex: 0})
+		Expect(err).To(MatchError(ContainSubstring("only filesystem honeytokens")))
+
+		_, err = selectAttackTarget(deceptionPolicy, simulateAttackOptions{trapIndex: 2})
+		Expect(err).To(MatchError(ContainSubstring("no deployed trap with index 2")))
+	})
+})
+
+var _ = Describe("isAlertOfAttack", func() {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "koney-demo", Name: "busybox"}}
+	deceptionPolicyName := "deceptionpolicy-servicetoken"
+
+	It("should match the alert of the decoy in the pod", func() {
+		alert := alerts.KoneyAlert{
+			DeceptionPolicyName: &deceptionPolicyName,
+			Pod:                 &alerts.PodMetadata{Namespace: "koney-demo", Name: "busybox"},
+			Metadata:            map[string]any{"file_path": "/run/secrets/koney/token"},
+		}
+		Expect(isAlertOfAttack(alert, deceptionPolicyName, pod, "/run/secrets/koney/token")).To(BeTrue())
+		Expect(isAlertOfAttack(alert, deceptionPolicyName, pod, "/root/.aws/credentials")).To(BeFalse())
+		Expect(isAlertOfAttack(alert, "other", pod, "/run/secrets/koney/token")).To(BeFalse())
+
+		alert.Pod.Name = "nginx"
+		Expect(isAlertOfAttack(alert, deceptionPolicyName, pod, "/run/secrets/koney/token")).To(BeFalse())
+	})
+})
+
+var _ = Describe("pendingSinks", func() {
+	const alertTimestamp = "2025-01-03T18:47:56.123456789Z"
+	sink := func(name string, recentAlertTimestamps ...string) v1alpha1.DeceptionAlertSink {
+		alertSink := v1alpha1.DeceptionAlertSink{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		alertSink.Status.RecentAlertTimestamps = recentAlertTimestamps
+		if len(recentAlertTimestamps) > 0 {
+			alertSink.Status.LastDeliveryTime = &metav1.Time{Time: time.Date(2025, 1, 3, 18, 48, 0, 0, time.UTC)}
+		}
+		return alertSink
+	}
+	sinks := []v1alpha1.DeceptionAlertSink{
+		sink("slack", "2025-01-03T18:47:50.000000000Z", alertTimestamp),
+		// another alert was delivered after the access, but not the one of the simulated attack
+		sink("dynatrace", "2025-01-03T18:47:58.000000000Z"),
+		sink("webhook"),
+	}
+
+	It("should wait for all sinks without names", func() {
+		Expect(pendingSinks(sinks, nil, alertTimestamp)).To(Equal([]string{"dynatrace", "webhook"}))
+	})
+
+	It("should only wait for the sinks with the given names", func() {
+		Expect(pendingSinks(sinks, []string{"slack"}, alertTimestamp)).To(BeEmpty())
+		Expect(pendingSinks(sinks, []string{"slack", "missing"}, alertTimestamp)).To(Equal([]string{"missing"}))
+	})
+})
+
+var _ = Describe("splitNames", func() {
+	It("should split comma-separated names", func() {
+		Expect(splitNames(" slack, ,dynatrace")).To(Equal([]string{"slack", "dynatrace"}))
+		Expect(splitNames("")).To(BeEmpty())
+	})
+})
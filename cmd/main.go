@@ -16,10 +16,12 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -27,10 +29,12 @@ import (
 
 	kivev1 "github.com/San7o/kivebpf/api/v1"
 	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -39,7 +43,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	researchdynatracecomv1alpha1 "github.com/dynatrace-oss/koney/api/v1alpha1"
+	researchdynatracecomv1beta1 "github.com/dynatrace-oss/koney/api/v1beta1"
 	"github.com/dynatrace-oss/koney/internal/controller"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	webhookcorev1 "github.com/dynatrace-oss/koney/internal/webhook/v1"
+	webhookresearchdynatracecomv1alpha1 "github.com/dynatrace-oss/koney/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -52,7 +61,9 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(ciliumiov1alpha1.AddToScheme(scheme))
 	utilruntime.Must(researchdynatracecomv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(researchdynatracecomv1beta1.AddToScheme(scheme))
 	utilruntime.Must(kivev1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -61,6 +72,8 @@ func main() {
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
 	var enableLeaderElection bool
+	var leaderElectionNamespace string
+	var leaseDuration, renewDeadline, retryPeriod time.Duration
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
@@ -71,6 +84,14 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"The namespace of the leader election lease. Defaults to the namespace of the controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"The duration that replicas wait before they take over the lease from a leader that stopped renewing it.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"The duration that the leader retries to renew the lease before it gives up leadership (must be less than the lease duration).")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
+		"The duration that replicas wait between attempts to acquire or renew the lease.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
@@ -179,24 +200,40 @@ func main() {
 		})
 	}
 
+	// In the namespace-scoped mode, the controller only has permissions in the watched namespaces and its own namespace,
+	// so the cache must not watch namespaced resources in any other namespace (cluster-scoped resources are still watched)
+	cacheOptions := cache.Options{}
+	if watchNamespaces := utils.GetWatchNamespaces(); len(watchNamespaces) > 0 {
+		setupLog.Info("Watching only the configured namespaces", "namespaces", watchNamespaces)
+		cacheOptions.DefaultNamespaces = map[string]cache.Config{utils.GetKoneyNamespace(): {}}
+		for _, namespace := range watchNamespaces {
+			cacheOptions.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b3b1bc0d.koney",
+		// Only the leader runs the controllers, the verification of decoys, and the captor garbage collector,
+		// so that several replicas (e.g., during upgrades) never place the same decoys twice
+		LeaderElectionNamespace: leaderElectionNamespace,
+		LeaseDuration:           &leaseDuration,
+		RenewDeadline:           &renewDeadline,
+		RetryPeriod:             &retryPeriod,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
 		// speeds up voluntary leader transitions as the new leader don't have to wait
 		// LeaseDuration time first.
 		//
-		// In the default scaffold provided, the program ends immediately after
-		// the manager stops, so would be fine to enable this option. However,
-		// if you are doing or is intended to do any operation such as perform cleanups
-		// after the manager stops then its usage might be unsafe.
-		// LeaderElectionReleaseOnCancel: true,
+		// The program ends immediately after the manager stops, and no cleanups run afterward,
+		// so the new replica of a rolling upgrade takes over right away.
+		LeaderElectionReleaseOnCancel: true,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -213,6 +250,70 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "DeceptionPolicy")
 		os.Exit(1)
 	}
+	// The ClusterDeceptionPolicy controller rolls out each ClusterDeceptionPolicy with a DeceptionPolicy
+	if err = (&controller.ClusterDeceptionPolicyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterDeceptionPolicy")
+		os.Exit(1)
+	}
+	// The ClusterDeceptionReport controller periodically reports which workloads are protected by traps
+	if err = (&controller.ClusterDeceptionReportReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterDeceptionReport")
+		os.Exit(1)
+	}
+	// The response controller takes the response actions of traps when the alert forwarder requests it
+	if err = (&controller.ResponseReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Response")
+		os.Exit(1)
+	}
+	// The DeceptionAlert controller attributes the alert records of the alert forwarder to the generation of rotating honeytokens,
+	// and deletes them once their retention passed
+	if err = (&controller.DeceptionAlertReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Retention: utils.GetDeceptionAlertRetention(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DeceptionAlert")
+		os.Exit(1)
+	}
+	// The captor garbage collector deletes captors that the DeceptionPolicy controller missed to clean up
+	if err = mgr.Add(&controller.CaptorGarbageCollector{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Interval:  constants.CaptorGarbageCollectionInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to add captor garbage collector to manager")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&controller.DecoyGarbageCollector{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Interval:  constants.DecoyGarbageCollectionInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to add decoy garbage collector to manager")
+		os.Exit(1)
+	}
+	// The defaulting webhook requires certificates (e.g., from cert-manager), so it must be enabled explicitly.
+	// Without it, the controller applies the same defaults when reconciling.
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = webhookresearchdynatracecomv1alpha1.SetupDeceptionPolicyWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DeceptionPolicy")
+			os.Exit(1)
+		}
+		// The pod webhook injects the decoys of traps with the admission strategy
+		if err = webhookcorev1.SetupPodWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -240,6 +341,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// the cache is not started yet, so the fingerprint is read directly from the API server
+	if _, err := utils.LoadKoneyFingerprint(context.Background(), mgr.GetAPIReader(), mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to load fingerprint")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -36,6 +36,13 @@ Always uses the Helm release namespace.
 {{- .Release.Namespace }}
 {{- end }}
 
+{{/*
+Namespace of the leader election lease of the controller manager, which defaults to the namespace of the release.
+*/}}
+{{- define "chart.leaderElectionNamespace" -}}
+{{- .Values.manager.leaderElection.namespace | default (include "chart.namespaceName" .) }}
+{{- end }}
+
 {{/*
 Service name with proper truncation for Kubernetes 63-character limit.
 Takes a context with .suffix for the service type (e.g., "webhook-service").
@@ -50,6 +57,20 @@ If fullname + suffix exceeds 63 chars, truncates fullname to 45 chars.
 {{- end -}}
 {{- end }}
 
+{{/*
+Name of the secret with the token that captors authenticate with at the alert forwarder.
+*/}}
+{{- define "chart.alertForwarderTokenSecretName" -}}
+{{- .Values.alertForwarder.auth.existingSecret | default "koney-alert-forwarder-token" }}
+{{- end }}
+
+{{/*
+Name of the secret with the TLS certificate of the alert forwarder.
+*/}}
+{{- define "chart.alertForwarderCertSecretName" -}}
+{{- .Values.alertForwarder.tls.existingSecret | default "koney-alert-forwarder-cert" }}
+{{- end }}
+
 {{/*
 Common labels for Helm charts.
 Includes app version, chart version, app name, instance, and managed-by labels.
@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Command alertschema writes the JSON Schema of the alert format to the given file.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	alertsv1 "github.com/dynatrace-oss/koney/pkg/alerts/v1"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: alertschema <output file>")
+		os.Exit(2)
+	}
+
+	schema, err := alertsv1.JSONSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to generate JSON Schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(os.Args[1], schema, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write JSON Schema: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -23,5 +23,12 @@ if [ -d "$CRD_DIR" ]; then
 
     # Insert 'crd.keep' check and annotation
     sed -i '/controller-gen.kubebuilder.io\/version:/a \    {{- if and .Values.crd.keep .Values.template.helmLabels }}\n    helm.sh/resource-policy: keep\n    {{- end }}' "$file"
+
+    # DeceptionPolicy is stored as v1alpha1, so other versions are only served with the conversion webhook
+    if [ "$(basename "$file")" = "research.dynatrace.com_deceptionpolicies.yaml" ]; then
+      sed -i '/^  - name: v1beta1$/,/^    served: true$/ s/^    served: true$/    served: {{ .Values.webhook.enable }}/' "$file"
+      sed -i '/^  scope: Cluster$/a \  {{- if .Values.webhook.enable }}\n  conversion:\n    strategy: Webhook\n    webhook:\n      clientConfig:\n        service:\n          name: koney-webhook-service\n          namespace: {{ include "chart.namespaceName" . }}\n          path: /convert\n      conversionReviewVersions:\n      - v1\n  {{- end }}' "$file"
+      sed -i '/controller-gen.kubebuilder.io\/version:/a \    {{- if .Values.webhook.enable }}\n    cert-manager.io/inject-ca-from: {{ include "chart.namespaceName" . }}/koney-serving-cert\n    {{- end }}' "$file"
+    fi
   done
 fi
@@ -50,6 +50,19 @@ func AddTrapToAnnotations(resource client.Object, crdName string, trap v1alpha1.
 		return err
 	}
 
+	// Label the resource, so that captors only monitor resources on the nodes that the trap is constrained to
+	if trap.MatchResources.HasNodeConstraints() {
+		annotationTrap.NodeScopeLabel, err = NodeScopeLabelKey(trap.MatchResources)
+		if err != nil {
+			return err
+		}
+
+		if resource.GetLabels() == nil {
+			resource.SetLabels(make(map[string]string))
+		}
+		resource.GetLabels()[annotationTrap.NodeScopeLabel] = "true"
+	}
+
 	changeExists := false
 	// Check if the crdName already exists in the changes list
 	for _, change := range oldAnnotationChanges {
@@ -66,6 +79,7 @@ func AddTrapToAnnotations(resource client.Object, crdName string, trap v1alpha1.
 					// and the containers list
 					change.Traps[index].UpdatedAt = time.Now().Format(time.RFC3339)
 					change.Traps[index].Containers = containers
+					change.Traps[index].NodeScopeLabel = annotationTrap.NodeScopeLabel
 
 					break
 				}
@@ -202,6 +216,11 @@ func RemoveTrapAnnotations(resource client.Object, crdName string, trap v1alpha1
 		}
 	}
 
+	// Remove the node scope label, unless other traps with the same node constraints are still deployed
+	if trap.NodeScopeLabel != "" && !isNodeScopeLabelInUse(newAnnotationChanges, trap.NodeScopeLabel) {
+		delete(resource.GetLabels(), trap.NodeScopeLabel)
+	}
+
 	// If there are no changes left, remove the annotation
 	if len(newAnnotationChanges) == 0 {
 		delete(resource.GetAnnotations(), constants.AnnotationKeyChanges)
@@ -243,8 +262,8 @@ func GetAnnotationChange(resource client.Object, crdName string) (v1alpha1.Chang
 // AreTheSameTrap returns true if the provided v1alpha1.AnnotationTrap and v1alpha1.Trap are the same.
 // This ignores the containers list.
 func AreTheSameTrap(annotationTrap v1alpha1.TrapAnnotation, trap v1alpha1.Trap) bool {
-	// First, check if the deployment strategy is the same
-	if annotationTrap.DeploymentStrategy != trap.DecoyDeployment.Strategy {
+	// First, check if the deployment strategy is the same (or the one that the "auto" strategy chose)
+	if !trap.DecoyDeployment.CanUseStrategy(annotationTrap.DeploymentStrategy) {
 		return false
 	}
 
@@ -262,7 +281,7 @@ func AreTheSameTrap(annotationTrap v1alpha1.TrapAnnotation, trap v1alpha1.Trap)
 		if annotationTrap.FilesystemHoneytoken.FileContentHash != utils.Hash(trap.FilesystemHoneytoken.FileContent) {
 			return false
 		}
-		if annotationTrap.FilesystemHoneytoken.ReadOnly != trap.FilesystemHoneytoken.ReadOnly {
+		if annotationTrap.FilesystemHoneytoken.ReadOnly != trap.FilesystemHoneytoken.IsReadOnly() {
 			return false
 		}
 	case v1alpha1.HttpEndpointTrap:
@@ -319,6 +338,28 @@ func GetAnnotatedResources(r client.Reader, ctx context.Context, crdName string)
 	return annotatedResources, nil
 }
 
+// NodeScopeLabelKey returns the key of the label that is placed on resources that match the node constraints of the given MatchResources.
+// Traps with the same MatchResources share the same label.
+func NodeScopeLabelKey(matchResources v1alpha1.MatchResources) (string, error) {
+	matchResourcesJSON, err := json.Marshal(matchResources)
+	if err != nil {
+		return "", err
+	}
+
+	return constants.LabelKeyPrefixNodeScope + utils.Hash(string(matchResourcesJSON)), nil
+}
+
+func isNodeScopeLabelInUse(changes []v1alpha1.ChangeAnnotation, labelKey string) bool {
+	for _, change := range changes {
+		for _, trap := range change.Traps {
+			if trap.NodeScopeLabel == labelKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func convertTrapToTrapAnnotation(trap v1alpha1.Trap, containers []string) (v1alpha1.TrapAnnotation, error) {
 	annotationTrap := v1alpha1.TrapAnnotation{
 		DeploymentStrategy: trap.DecoyDeployment.Strategy,
@@ -331,7 +372,7 @@ func convertTrapToTrapAnnotation(trap v1alpha1.Trap, containers []string) (v1alp
 		annotationTrap.FilesystemHoneytoken = v1alpha1.FilesystemHoneytokenAnnotation{
 			FilePath:        trap.FilesystemHoneytoken.FilePath,
 			FileContentHash: utils.Hash(trap.FilesystemHoneytoken.FileContent),
-			ReadOnly:        trap.FilesystemHoneytoken.ReadOnly,
+			ReadOnly:        trap.FilesystemHoneytoken.IsReadOnly(),
 		}
 	case v1alpha1.HttpEndpointTrap:
 		annotationTrap.HttpEndpoint = v1alpha1.HttpEndpointAnnotation{}
@@ -68,7 +68,7 @@ func initializeTestTraps() {
 					FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
 						FilePath:    testFilePath,
 						FileContent: "someverysecrettoken",
-						ReadOnly:    true,
+						ReadOnly:    &[]bool{true}[0],
 					},
 					DecoyDeployment: v1alpha1.DecoyDeployment{
 						Strategy: deploymentStrategy,
@@ -129,7 +129,7 @@ var _ = Describe("AreTheSameTrap", func() {
 						FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{
 							FilePath:        trap.FilesystemHoneytoken.FilePath,
 							FileContentHash: utils.Hash(trap.FilesystemHoneytoken.FileContent),
-							ReadOnly:        trap.FilesystemHoneytoken.ReadOnly,
+							ReadOnly:        trap.FilesystemHoneytoken.IsReadOnly(),
 						},
 					}
 				case v1alpha1.HttpEndpointTrap:
@@ -161,7 +161,7 @@ var _ = Describe("AreTheSameTrap", func() {
 							FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{
 								FilePath:        trap.FilesystemHoneytoken.FilePath,
 								FileContentHash: utils.Hash(trap.FilesystemHoneytoken.FileContent),
-								ReadOnly:        trap.FilesystemHoneytoken.ReadOnly,
+								ReadOnly:        trap.FilesystemHoneytoken.IsReadOnly(),
 							},
 						}
 						// Modify the trap field
@@ -180,7 +180,7 @@ var _ = Describe("AreTheSameTrap", func() {
 						case "fileContentHash":
 							annotationTrap.FilesystemHoneytoken.FileContentHash = testFileHash
 						case "readOnly":
-							annotationTrap.FilesystemHoneytoken.ReadOnly = !trap.FilesystemHoneytoken.ReadOnly
+							annotationTrap.FilesystemHoneytoken.ReadOnly = !trap.FilesystemHoneytoken.IsReadOnly()
 						}
 
 						Expect(AreTheSameTrap(annotationTrap, trap)).To(BeFalse())
@@ -197,6 +197,25 @@ var _ = Describe("AreTheSameTrap", func() {
 			}
 		})
 	})
+
+	Context("when comparing a trap with the auto strategy", func() {
+		It("should match the strategies that the auto strategy chooses from", func() {
+			trap := v1alpha1.Trap{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/token"},
+				DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "auto"},
+			}
+			annotationTrap := v1alpha1.TrapAnnotation{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{FilePath: "/run/secrets/koney/token", FileContentHash: utils.Hash(""), ReadOnly: true},
+			}
+
+			for _, strategy := range v1alpha1.AutoStrategies {
+				annotationTrap.DeploymentStrategy = strategy
+				Expect(AreTheSameTrap(annotationTrap, trap)).To(BeTrue())
+			}
+			annotationTrap.DeploymentStrategy = "volumeMount"
+			Expect(AreTheSameTrap(annotationTrap, trap)).To(BeFalse())
+		})
+	})
 })
 
 var _ = Describe("trapToAnnotationTrap", func() {
@@ -251,7 +270,7 @@ var _ = Describe("AddTrapToResourceAnnotations", func() {
 						Expect(annotationTrap.Containers).To(Equal(containers))
 						Expect(annotationTrap.FilesystemHoneytoken.FilePath).To(Equal(trap.FilesystemHoneytoken.FilePath))
 						Expect(annotationTrap.FilesystemHoneytoken.FileContentHash).To(Equal(utils.Hash(trap.FilesystemHoneytoken.FileContent)))
-						Expect(annotationTrap.FilesystemHoneytoken.ReadOnly).To(Equal(trap.FilesystemHoneytoken.ReadOnly))
+						Expect(annotationTrap.FilesystemHoneytoken.ReadOnly).To(Equal(trap.FilesystemHoneytoken.IsReadOnly()))
 					case v1alpha1.HttpEndpointTrap:
 						// TODO: Implement.
 					case v1alpha1.HttpPayloadTrap:
@@ -523,3 +542,40 @@ var _ = Describe("GetAnnotationChange", func() {
 		})
 	})
 })
+
+var _ = Describe("NodeScopeLabel", func() {
+	Context("when adding and removing traps with node constraints", func() {
+		It("should label the resource until the last trap with the same node constraints is removed", func() {
+			nodeConstrainedTrap := func(filePath string) v1alpha1.Trap {
+				return v1alpha1.Trap{
+					FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filePath, FileContent: "someverysecrettoken"},
+					DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "containerExec"},
+					MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{
+						{ResourceDescription: v1alpha1.ResourceDescription{NodeSelector: map[string]string{"node-pool": "internet-facing"}}},
+					}},
+				}
+			}
+			trap1, trap2 := nodeConstrainedTrap(testFilePath), nodeConstrainedTrap(testFilePath+"_backup")
+
+			labelKey, err := NodeScopeLabelKey(trap1.MatchResources)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labelKey).To(HavePrefix(constants.LabelKeyPrefixNodeScope))
+
+			pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: testPodName, Namespace: testNamespace}}
+			Expect(AddTrapToAnnotations(&pod, testCrdName, trap1, containersValues[1])).To(Succeed())
+			Expect(AddTrapToAnnotations(&pod, testCrdName, trap2, containersValues[1])).To(Succeed())
+			Expect(pod.Labels).To(HaveKeyWithValue(labelKey, "true"))
+
+			annotationChange, err := GetAnnotationChange(&pod, testCrdName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(annotationChange.Traps).To(HaveLen(2))
+			Expect(annotationChange.Traps[0].NodeScopeLabel).To(Equal(labelKey))
+
+			Expect(RemoveTrapAnnotations(&pod, testCrdName, annotationChange.Traps[0])).To(Succeed())
+			Expect(pod.Labels).To(HaveKey(labelKey))
+
+			Expect(RemoveTrapAnnotations(&pod, testCrdName, annotationChange.Traps[1])).To(Succeed())
+			Expect(pod.Labels).ToNot(HaveKey(labelKey))
+		})
+	})
+})
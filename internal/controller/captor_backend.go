@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// checkCaptorBackends checks that the backends of the captor strategies of the traps are installed and healthy,
+// so that captors are not deployed silently where no one enforces them. It returns a description of every problem found.
+// Strategies that do not depend on a backend in the cluster (i.e., "sidecar" and "none") are always available.
+func (r *DeceptionPolicyReconciler) checkCaptorBackends(ctx context.Context, traps []v1alpha1.Trap) ([]string, error) {
+	var problems []string
+	var errs []error
+
+	checked := map[string]bool{}
+	for _, trap := range traps {
+		for _, strategy := range trap.CaptorDeployment.AllStrategies() {
+			if checked[strategy] {
+				continue
+			}
+			checked[strategy] = true
+
+			problem, err := r.checkCaptorBackend(ctx, strategy)
+			if err != nil {
+				errs = append(errs, err)
+			} else if problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	return problems, errors.Join(errs...)
+}
+
+// checkCaptorBackend checks the backend of a single captor strategy, and returns a description of the problem if it is unavailable.
+func (r *DeceptionPolicyReconciler) checkCaptorBackend(ctx context.Context, strategy string) (string, error) {
+	switch strategy {
+	case "tetragon":
+		if installed, err := r.isCRDInstalled(ctx, constants.TetragonTracingPolicyCRDName); err != nil || !installed {
+			return "Tetragon is not installed (the TracingPolicy CRD is missing)", err
+		}
+
+		daemonSet := &appsv1.DaemonSet{}
+		key := client.ObjectKey{Namespace: utils.GetTetragonNamespace(), Name: utils.GetTetragonDaemonSetName()}
+		if err := r.apiReader().Get(ctx, key, daemonSet); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("the Tetragon DaemonSet %s is missing", key), nil
+			}
+			return "", err
+		}
+
+		// Nodes without a ready Tetragon pod are not monitored, so the DaemonSet must be ready on all nodes
+		ready, desired := daemonSet.Status.NumberReady, daemonSet.Status.DesiredNumberScheduled
+		if desired == 0 || ready < desired {
+			return fmt.Sprintf("the Tetragon DaemonSet %s is not ready (%d/%d pods ready)", key, ready, desired), nil
+		}
+	case "kive":
+		if installed, err := r.isCRDInstalled(ctx, constants.KivePolicyCRDName); err != nil || !installed {
+			return "the Kive operator is not installed (the KivePolicy CRD is missing)", err
+		}
+	case "falco":
+		if !utils.IsFalcoEnabled() {
+			return "the namespace of Falco is not configured (KONEY_FALCO_NAMESPACE)", nil
+		}
+	}
+
+	return "", nil
+}
+
+// isCRDInstalled returns true if the CRD with the given name exists in the cluster.
+// Only the metadata of the CRD is fetched, since the schemas of CRDs can be large.
+func (r *DeceptionPolicyReconciler) isCRDInstalled(ctx context.Context, name string) (bool, error) {
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	if err := r.apiReader().Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// buildCaptorBackendAvailableCondition returns the status condition that reports if the backends of all captor strategies are available.
+func buildCaptorBackendAvailableCondition(problems []string, err error) v1alpha1.DeceptionPolicyCondition {
+	condition := v1alpha1.DeceptionPolicyCondition{
+		Type:               CaptorBackendAvailableType,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             CaptorBackendAvailableReason_Available,
+		Message:            CaptorBackendAvailableMessage_Available,
+	}
+
+	if err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = CaptorBackendAvailableReason_Error
+		condition.Message = err.Error()
+	} else if len(problems) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = CaptorBackendAvailableReason_Unavailable
+		condition.Message = strings.Join(problems, "; ")
+	}
+
+	return condition
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("Captor backends", func() {
+	var (
+		ctx   context.Context
+		traps []v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		traps = []v1alpha1.Trap{
+			{CaptorDeployment: v1alpha1.CaptorDeployment{Strategies: []string{"tetragon", "sidecar"}}},
+			{CaptorDeployment: v1alpha1.CaptorDeployment{Strategy: "kive"}},
+		}
+	})
+
+	newReconciler := func(objects ...client.Object) *DeceptionPolicyReconciler {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		return &DeceptionPolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	}
+
+	crd := func(name string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	tetragonDaemonSet := func(ready, desired int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "tetragon"},
+			Status:     appsv1.DaemonSetStatus{NumberReady: ready, DesiredNumberScheduled: desired},
+		}
+	}
+
+	It("should report no problems if all backends are healthy", func() {
+		r := newReconciler(crd(constants.TetragonTracingPolicyCRDName), crd(constants.KivePolicyCRDName), tetragonDaemonSet(3, 3))
+
+		problems, err := r.checkCaptorBackends(ctx, traps)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("should report missing backends", func() {
+		problems, err := newReconciler().checkCaptorBackends(ctx, traps)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(ConsistOf(
+			ContainSubstring("Tetragon is not installed"),
+			ContainSubstring("Kive operator is not installed"),
+		))
+	})
+
+	It("should report a Tetragon DaemonSet that is not ready on all nodes", func() {
+		r := newReconciler(crd(constants.TetragonTracingPolicyCRDName), crd(constants.KivePolicyCRDName), tetragonDaemonSet(2, 3))
+
+		problems, err := r.checkCaptorBackends(ctx, traps)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(ConsistOf("the Tetragon DaemonSet kube-system/tetragon is not ready (2/3 pods ready)"))
+	})
+
+	It("should look for the Tetragon DaemonSet where it is configured", func() {
+		GinkgoT().Setenv("KONEY_TETRAGON_NAMESPACE", "tetragon")
+		r := newReconciler(crd(constants.TetragonTracingPolicyCRDName), tetragonDaemonSet(3, 3))
+
+		problems, err := r.checkCaptorBackends(ctx, traps[:1])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(ConsistOf("the Tetragon DaemonSet tetragon/tetragon is missing"))
+	})
+
+	It("should not check backends of unused strategies", func() {
+		traps = []v1alpha1.Trap{{CaptorDeployment: v1alpha1.CaptorDeployment{Strategy: "sidecar"}}}
+
+		problems, err := newReconciler().checkCaptorBackends(ctx, traps)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("should build the condition from the problems", func() {
+		condition := buildCaptorBackendAvailableCondition(nil, nil)
+		Expect(condition.Type).To(Equal(CaptorBackendAvailableType))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+		condition = buildCaptorBackendAvailableCondition([]string{"a", "b"}, nil)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(CaptorBackendAvailableReason_Unavailable))
+		Expect(condition.Message).To(Equal("a; b"))
+
+		condition = buildCaptorBackendAvailableCondition(nil, errors.New("forbidden"))
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(CaptorBackendAvailableReason_Error))
+	})
+})
@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	kivev1 "github.com/San7o/kivebpf/api/v1"
+	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// CaptorGarbageCollector periodically deletes the captors (Tetragon tracing policies, Kive policies, and Falco rules)
+// whose DeceptionPolicy or trap does not exist anymore. The DeceptionPolicy controller only cleans up
+// the captors of traps that it observes being removed, so renamed policies, edited traps, or policies
+// that were deleted while Koney was not running would otherwise leave stale captors behind.
+type CaptorGarbageCollector struct {
+	client.Client
+
+	// APIReader reads the Falco rules ConfigMap directly from the API server, since ConfigMaps are not cached.
+	// If it is not set, the client is used.
+	APIReader client.Reader
+
+	// Interval is the time between two collections.
+	Interval time.Duration
+}
+
+// Start collects orphaned captors every interval until the context is done.
+func (c *CaptorGarbageCollector) Start(ctx context.Context) error {
+	log := k8slog.FromContext(ctx).WithName("captor-garbage-collector")
+	ctx = k8slog.IntoContext(ctx, log)
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.Collect(ctx, time.Now()); err != nil {
+				log.Error(err, "Unable to collect orphaned captors")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns true, so that only the leader deletes captors.
+func (c *CaptorGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect deletes the captors that are older than constants.OrphanedCaptorGracePeriod
+// and whose DeceptionPolicy or trap does not exist anymore.
+func (c *CaptorGarbageCollector) Collect(ctx context.Context, now time.Time) error {
+	log := k8slog.FromContext(ctx)
+
+	// List the captors before the DeceptionPolicies, so that each captor was created for a policy that is listed
+	captors, err := c.listCaptors(ctx)
+	if err != nil {
+		return err
+	}
+	if len(captors) == 0 && !utils.IsFalcoEnabled() {
+		return nil
+	}
+
+	deceptionPolicies, err := listAllDeceptionPolicies(c, ctx)
+	if err != nil {
+		return err
+	}
+	expectedCaptors, err := buildExpectedCaptorNames(deceptionPolicies)
+	if err != nil {
+		return err
+	}
+
+	var joinedErrors error
+	for _, captor := range captors {
+		if now.Sub(captor.GetCreationTimestamp().Time) < constants.OrphanedCaptorGracePeriod {
+			continue
+		}
+		if !isOrphanedCaptor(captor, expectedCaptors) {
+			continue
+		}
+
+		if err := c.Delete(ctx, captor); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Unable to delete orphaned captor", "captor", captor.GetName())
+			joinedErrors = errors.Join(joinedErrors, err)
+			continue
+		}
+		log.Info("Deleted orphaned captor", "captor", captor.GetName(), "deceptionPolicy", captor.GetLabels()[constants.LabelKeyDeceptionPolicyRef])
+	}
+
+	var reader client.Reader = c.Client
+	if c.APIReader != nil {
+		reader = c.APIReader
+	}
+	if err := filesystoken.CollectOrphanedFalcoRules(ctx, reader, c, expectedCaptors, now); err != nil {
+		log.Error(err, "Unable to collect orphaned Falco rules")
+		joinedErrors = errors.Join(joinedErrors, err)
+	}
+
+	return joinedErrors
+}
+
+// listCaptors returns the Tetragon tracing policies (cluster-scoped and namespaced) and Kive policies that Koney created.
+// Captors of tools that are not installed are skipped.
+func (c *CaptorGarbageCollector) listCaptors(ctx context.Context) ([]client.Object, error) {
+	captors := []client.Object{}
+
+	tracingPolicies := &ciliumiov1alpha1.TracingPolicyList{}
+	if err := c.List(ctx, tracingPolicies); err != nil {
+		// If the error is *meta.NoKindMatchError, Tetragon is not installed
+		if _, ok := err.(*meta.NoKindMatchError); !ok {
+			return nil, err
+		}
+	}
+	for i := range tracingPolicies.Items {
+		if strings.HasPrefix(tracingPolicies.Items[i].Name, filesystoken.CaptorNamePrefix) {
+			captors = append(captors, &tracingPolicies.Items[i])
+		}
+	}
+
+	namespacedTracingPolicies := &ciliumiov1alpha1.TracingPolicyNamespacedList{}
+	if err := c.List(ctx, namespacedTracingPolicies); err != nil {
+		// Older versions of Tetragon do not support namespaced tracing policies
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	for i := range namespacedTracingPolicies.Items {
+		if strings.HasPrefix(namespacedTracingPolicies.Items[i].Name, filesystoken.CaptorNamePrefix) {
+			captors = append(captors, &namespacedTracingPolicies.Items[i])
+		}
+	}
+
+	kivePolicies := &kivev1.KivePolicyList{}
+	if err := c.List(ctx, kivePolicies, client.InNamespace(utils.GetKoneyNamespace())); err != nil {
+		// If the error is *meta.NoKindMatchError, Kive is not installed
+		if _, ok := err.(*meta.NoKindMatchError); !ok {
+			return nil, err
+		}
+	}
+	for i := range kivePolicies.Items {
+		if strings.HasPrefix(kivePolicies.Items[i].Name, filesystoken.CaptorNamePrefix) {
+			captors = append(captors, &kivePolicies.Items[i])
+		}
+	}
+
+	return captors, nil
+}
+
+// buildExpectedCaptorNames returns the names of the captors that the traps of each DeceptionPolicy might have deployed,
+// keyed by the name of the DeceptionPolicy. All traps count, including paused, expired, or invalid ones,
+// since the DeceptionPolicy controller takes care of their captors.
+func buildExpectedCaptorNames(deceptionPolicies []v1alpha1.DeceptionPolicy) (map[string]map[string]bool, error) {
+	expectedCaptors := make(map[string]map[string]bool, len(deceptionPolicies))
+	for _, deceptionPolicy := range deceptionPolicies {
+		names := map[string]bool{}
+		for _, trap := range deceptionPolicy.Spec.Traps {
+			captorName, err := filesystoken.GenerateCaptorName(&deceptionPolicy, trap)
+			if err != nil {
+				return nil, err
+			}
+			names[captorName] = true
+		}
+		expectedCaptors[deceptionPolicy.Name] = names
+	}
+
+	return expectedCaptors, nil
+}
+
+// isOrphanedCaptor returns true if the DeceptionPolicy of a captor does not exist anymore,
+// or if none of its traps deploys a captor with that name anymore.
+// Captors without a DeceptionPolicy label are orphaned if no DeceptionPolicy deploys a captor with that name.
+func isOrphanedCaptor(captor client.Object, expectedCaptors map[string]map[string]bool) bool {
+	deceptionPolicyName, ok := captor.GetLabels()[constants.LabelKeyDeceptionPolicyRef]
+	if !ok {
+		for _, names := range expectedCaptors {
+			if names[captor.GetName()] {
+				return false
+			}
+		}
+		return true
+	}
+
+	names, found := expectedCaptors[deceptionPolicyName]
+	return !found || !names[captor.GetName()]
+}
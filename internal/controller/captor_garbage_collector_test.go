@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"time"
+
+	kivev1 "github.com/San7o/kivebpf/api/v1"
+	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("Captor garbage collection", func() {
+	var (
+		ctx             context.Context
+		now             time.Time
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		trapCaptorName  string
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample"},
+			Spec: v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/service_token", FileContent: "someverysecrettoken"},
+			}}},
+		}
+
+		var err error
+		trapCaptorName, err = filesystoken.GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only run on the leader, so that several replicas do not collect the same captors", func() {
+		var collector manager.LeaderElectionRunnable = &CaptorGarbageCollector{}
+		Expect(collector.NeedLeaderElection()).To(BeTrue())
+	})
+
+	captorMeta := func(name, deceptionPolicyName string, age time.Duration) metav1.ObjectMeta {
+		objectMeta := metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))}
+		if deceptionPolicyName != "" {
+			objectMeta.Labels = map[string]string{constants.LabelKeyDeceptionPolicyRef: deceptionPolicyName}
+		}
+		return objectMeta
+	}
+
+	tracingPolicy := func(name, deceptionPolicyName string, age time.Duration) *ciliumiov1alpha1.TracingPolicy {
+		return &ciliumiov1alpha1.TracingPolicy{ObjectMeta: captorMeta(name, deceptionPolicyName, age)}
+	}
+
+	Context("When deciding whether a captor is orphaned", func() {
+		It("should keep the captors of existing traps", func() {
+			expectedCaptors, err := buildExpectedCaptorNames([]v1alpha1.DeceptionPolicy{*deceptionPolicy})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(isOrphanedCaptor(tracingPolicy(trapCaptorName, deceptionPolicy.Name, 0), expectedCaptors)).To(BeFalse())
+			Expect(isOrphanedCaptor(tracingPolicy(trapCaptorName, "", 0), expectedCaptors)).To(BeFalse())
+		})
+
+		It("should detect captors of removed traps and deleted policies", func() {
+			expectedCaptors, err := buildExpectedCaptorNames([]v1alpha1.DeceptionPolicy{*deceptionPolicy})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(isOrphanedCaptor(tracingPolicy(filesystoken.CaptorNamePrefix+"removed", deceptionPolicy.Name, 0), expectedCaptors)).To(BeTrue())
+			Expect(isOrphanedCaptor(tracingPolicy(trapCaptorName, "deleted-policy", 0), expectedCaptors)).To(BeTrue())
+			Expect(isOrphanedCaptor(tracingPolicy(filesystoken.CaptorNamePrefix+"removed", "", 0), expectedCaptors)).To(BeTrue())
+		})
+	})
+
+	Context("When collecting orphaned captors", func() {
+		It("should only delete orphaned captors that are older than the grace period", func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(ciliumiov1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(kivev1.AddToScheme(scheme)).To(Succeed())
+
+			old := 2 * constants.OrphanedCaptorGracePeriod
+			kivePolicy := &kivev1.KivePolicy{ObjectMeta: captorMeta(filesystoken.CaptorNamePrefix+"stale-kive", "deleted-policy", old)}
+			kivePolicy.Namespace = utils.GetKoneyNamespace()
+
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				deceptionPolicy,
+				tracingPolicy(trapCaptorName, deceptionPolicy.Name, old),
+				tracingPolicy(filesystoken.CaptorNamePrefix+"stale", deceptionPolicy.Name, old),
+				tracingPolicy(filesystoken.CaptorNamePrefix+"fresh", deceptionPolicy.Name, constants.OrphanedCaptorGracePeriod/2),
+				tracingPolicy("foreign-tracing-policy", "", old),
+				kivePolicy,
+			).Build()
+
+			collector := &CaptorGarbageCollector{Client: fakeClient, Interval: time.Minute}
+			Expect(collector.Collect(ctx, now)).To(Succeed())
+
+			exists := func(obj client.Object) bool {
+				return fakeClient.Get(ctx, client.ObjectKeyFromObject(obj), obj) == nil
+			}
+			Expect(exists(&ciliumiov1alpha1.TracingPolicy{ObjectMeta: metav1.ObjectMeta{Name: trapCaptorName}})).To(BeTrue())
+			Expect(exists(&ciliumiov1alpha1.TracingPolicy{ObjectMeta: metav1.ObjectMeta{Name: filesystoken.CaptorNamePrefix + "stale"}})).To(BeFalse())
+			Expect(exists(&ciliumiov1alpha1.TracingPolicy{ObjectMeta: metav1.ObjectMeta{Name: filesystoken.CaptorNamePrefix + "fresh"}})).To(BeTrue())
+			Expect(exists(&ciliumiov1alpha1.TracingPolicy{ObjectMeta: metav1.ObjectMeta{Name: "foreign-tracing-policy"}})).To(BeTrue())
+			Expect(exists(&kivev1.KivePolicy{ObjectMeta: metav1.ObjectMeta{Name: kivePolicy.Name, Namespace: kivePolicy.Namespace}})).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+// captorResyncState is the reason why the captors of a DeceptionPolicy must be resynced.
+type captorResyncState string
+
+const (
+	// captorResyncCRDDeleted means that the tracing policy CRD was deleted, so all tracing policies are gone.
+	captorResyncCRDDeleted captorResyncState = "CRDDeleted"
+	// captorResyncCRDRecreated means that the tracing policy CRD was created again, so the tracing policies must be recreated.
+	captorResyncCRDRecreated captorResyncState = "CRDRecreated"
+)
+
+// captorResyncs remembers the DeceptionPolicies whose captors must be resynced because Tetragon was reinstalled.
+// The zero value is ready to use.
+type captorResyncs struct {
+	mu      sync.Mutex
+	pending map[string]captorResyncState
+}
+
+// markAll marks all DeceptionPolicies with the given names as pending a resync.
+func (c *captorResyncs) markAll(names []string, state captorResyncState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending == nil {
+		c.pending = map[string]captorResyncState{}
+	}
+	for _, name := range names {
+		c.pending[name] = state
+	}
+}
+
+// get returns the resync state of a DeceptionPolicy, and whether a resync is pending at all.
+func (c *captorResyncs) get(name string) (captorResyncState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.pending[name]
+	return state, ok
+}
+
+// done clears the pending resync of a DeceptionPolicy, unless the state changed in the meantime.
+func (c *captorResyncs) done(name string, state captorResyncState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending[name] == state {
+		delete(c.pending, name)
+	}
+}
+
+// tracingPolicyCRDHandler enqueues all DeceptionPolicies when the CRD of Tetragon tracing policies is deleted or recreated,
+// e.g., during a Tetragon upgrade, which also deletes all tracing policies. The CRDs that exist when the controller starts are ignored.
+func (r *DeceptionPolicyReconciler) tracingPolicyCRDHandler(startedAt time.Time) handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			if e.Object.GetCreationTimestamp().Time.Before(startedAt) {
+				return
+			}
+			r.requestCaptorResync(ctx, q, captorResyncCRDRecreated, constants.CaptorResyncDelay)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.requestCaptorResync(ctx, q, captorResyncCRDDeleted, 0)
+		},
+	}
+}
+
+func (r *DeceptionPolicyReconciler) requestCaptorResync(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], state captorResyncState, delay time.Duration) {
+	log := k8slog.FromContext(ctx)
+
+	deceptionPolicies, err := listAllDeceptionPolicies(r, ctx)
+	if err != nil {
+		log.Error(err, "Unable to list DeceptionPolicies to resync captors")
+		return
+	}
+
+	names := make([]string, 0, len(deceptionPolicies))
+	for _, deceptionPolicy := range deceptionPolicies {
+		names = append(names, deceptionPolicy.Name)
+	}
+	r.captorResyncs.markAll(names, state)
+
+	log.Info("Tetragon tracing policy CRD changed - will resync captors", "state", state, "deceptionPolicies", len(names))
+	for _, name := range names {
+		q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}, delay)
+	}
+}
+
+// isTracingPolicyCRD returns true if the object is the CRD of Tetragon tracing policies.
+func isTracingPolicyCRD(obj client.Object) bool {
+	return obj.GetName() == constants.TetragonTracingPolicyCRDName
+}
+
+// buildCaptorsResyncedCondition returns the status condition that reports the progress of a captor resync,
+// and whether the resync is complete.
+func buildCaptorsResyncedCondition(state captorResyncState, captorResult TrapReconcileResult) (v1alpha1.DeceptionPolicyCondition, bool) {
+	condition := v1alpha1.DeceptionPolicyCondition{
+		Type:               CaptorsResyncedType,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	switch {
+	case state == captorResyncCRDDeleted:
+		condition.Reason = CaptorsResyncedReason_CRDMissing
+		condition.Message = CaptorsResyncedMessage_CRDMissing
+	case captorResult.NumFailures > 0 || captorResult.ShouldRequeue:
+		condition.Reason = CaptorsResyncedReason_Failed
+		condition.Message = fmt.Sprintf("%d/%d captors recreated", captorResult.NumSuccesses, captorResult.NumTraps)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = CaptorsResyncedReason_Success
+		condition.Message = CaptorsResyncedMessage_Success
+	}
+
+	return condition, condition.Status == metav1.ConditionTrue
+}
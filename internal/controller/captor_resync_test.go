@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("captorResyncs", func() {
+	It("should track pending resyncs per DeceptionPolicy", func() {
+		var resyncs captorResyncs
+		_, pending := resyncs.get("policy-a")
+		Expect(pending).To(BeFalse())
+
+		resyncs.markAll([]string{"policy-a", "policy-b"}, captorResyncCRDDeleted)
+		resyncs.markAll([]string{"policy-a"}, captorResyncCRDRecreated)
+
+		state, pending := resyncs.get("policy-a")
+		Expect(pending).To(BeTrue())
+		Expect(state).To(Equal(captorResyncCRDRecreated))
+
+		// Completing a resync for an outdated state must not clear the newer one
+		resyncs.done("policy-b", captorResyncCRDRecreated)
+		_, pending = resyncs.get("policy-b")
+		Expect(pending).To(BeTrue())
+
+		resyncs.done("policy-a", captorResyncCRDRecreated)
+		_, pending = resyncs.get("policy-a")
+		Expect(pending).To(BeFalse())
+	})
+})
+
+var _ = Describe("buildCaptorsResyncedCondition", func() {
+	It("should wait while the CRD is missing", func() {
+		condition, resynced := buildCaptorsResyncedCondition(captorResyncCRDDeleted, TrapReconcileResult{NumTraps: 1, NumFailures: 1})
+		Expect(resynced).To(BeFalse())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(CaptorsResyncedReason_CRDMissing))
+	})
+
+	It("should report failures after the CRD was recreated", func() {
+		condition, resynced := buildCaptorsResyncedCondition(captorResyncCRDRecreated, TrapReconcileResult{NumTraps: 2, NumSuccesses: 1, NumFailures: 1})
+		Expect(resynced).To(BeFalse())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(CaptorsResyncedReason_Failed))
+		Expect(condition.Message).To(Equal("1/2 captors recreated"))
+	})
+
+	It("should complete once all captors were recreated", func() {
+		condition, resynced := buildCaptorsResyncedCondition(captorResyncCRDRecreated, TrapReconcileResult{NumTraps: 2, NumSuccesses: 2})
+		Expect(resynced).To(BeTrue())
+		Expect(condition.Type).To(Equal(CaptorsResyncedType))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(CaptorsResyncedReason_Success))
+	})
+})
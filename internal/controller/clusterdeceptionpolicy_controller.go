@@ -0,0 +1,332 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/templates"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// ClusterDeceptionPolicyReconciler rolls out each ClusterDeceptionPolicy with a DeceptionPolicy,
+// whose traps are narrowed down to the namespaces that the ClusterDeceptionPolicy applies to.
+// The DeceptionPolicy controller then deploys the traps, and the DeceptionPolicy is deleted together with the ClusterDeceptionPolicy.
+type ClusterDeceptionPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile creates or updates the DeceptionPolicy of a ClusterDeceptionPolicy.
+func (r *ClusterDeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := k8slog.FromContext(ctx)
+
+	clusterDeceptionPolicy := &v1alpha1.ClusterDeceptionPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, clusterDeceptionPolicy); err != nil {
+		// The DeceptionPolicy of a deleted ClusterDeceptionPolicy is garbage collected by its owner reference
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if clusterDeceptionPolicy.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	namespaces, overriddenNamespaces, err := r.selectNamespaces(ctx, clusterDeceptionPolicy)
+	if err != nil {
+		log.Error(err, "Namespaces of ClusterDeceptionPolicy cannot be selected", "ClusterDeceptionPolicy", req.Name)
+		return ctrl.Result{}, err
+	}
+
+	// Traps that reference a TrapTemplate are expanded first, so that their resource filters can be narrowed down
+	deceptionPolicy := buildClusterDeceptionPolicySpec(clusterDeceptionPolicy)
+	if _, err := templates.ExpandTrapTemplates(r, ctx, deceptionPolicy); errors.Is(err, templates.ErrTrapTemplateUnavailable) {
+		log.Error(err, "TrapTemplates cannot be fetched - will retry", "ClusterDeceptionPolicy", req.Name)
+		return ctrl.Result{}, err
+	} else if err != nil {
+		log.Error(err, "Some TrapTemplates cannot be expanded", "ClusterDeceptionPolicy", req.Name)
+	}
+	deceptionPolicy.ApplyDefaults()
+	deceptionPolicy.Spec.Traps = restrictTrapsToNamespaces(deceptionPolicy.Spec.Traps, namespaces)
+
+	if err := r.putDeceptionPolicy(ctx, clusterDeceptionPolicy, deceptionPolicy); err != nil {
+		log.Error(err, "DeceptionPolicy of ClusterDeceptionPolicy cannot be created or updated", "ClusterDeceptionPolicy", req.Name)
+		return ctrl.Result{}, err
+	}
+
+	status := v1alpha1.ClusterDeceptionPolicyStatus{
+		ObservedGeneration:   clusterDeceptionPolicy.Generation,
+		DeceptionPolicyName:  deceptionPolicy.Name,
+		Namespaces:           namespaces,
+		OverriddenNamespaces: overriddenNamespaces,
+	}
+	if err := r.updateClusterDeceptionPolicyStatus(ctx, req, clusterDeceptionPolicy, status); err != nil {
+		log.Error(err, "Status of ClusterDeceptionPolicy cannot be set", "ClusterDeceptionPolicy", req.Name)
+		return ctrl.Result{}, err
+	}
+
+	log.Info("ClusterDeceptionPolicy rolled out", "ClusterDeceptionPolicy", req.Name, "DeceptionPolicy", deceptionPolicy.Name,
+		"namespaces", len(namespaces), "overridden", len(overriddenNamespaces))
+	return ctrl.Result{}, nil
+}
+
+// selectNamespaces returns the namespaces that the traps of a ClusterDeceptionPolicy are applied to,
+// and the selected namespaces in which a DeceptionPolicy overrides the ClusterDeceptionPolicy. Both are sorted.
+// The namespace of Koney itself and namespaces that are being deleted are never selected.
+func (r *ClusterDeceptionPolicyReconciler) selectNamespaces(ctx context.Context, clusterDeceptionPolicy *v1alpha1.ClusterDeceptionPolicy) ([]string, []string, error) {
+	selector := labels.Everything()
+	if clusterDeceptionPolicy.Spec.NamespaceSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(clusterDeceptionPolicy.Spec.NamespaceSelector); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, nil, err
+	}
+
+	deceptionPolicies, err := listAllDeceptionPolicies(r, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespaces, overriddenNamespaces := partitionNamespaces(namespaceList.Items, collectOverriddenNamespaces(clusterDeceptionPolicy.Name, deceptionPolicies))
+	return namespaces, overriddenNamespaces, nil
+}
+
+// collectOverriddenNamespaces returns the namespaces in which any DeceptionPolicy overrides the ClusterDeceptionPolicy with the given name.
+// The DeceptionPolicies that ClusterDeceptionPolicies are rolled out with cannot override other ClusterDeceptionPolicies.
+func collectOverriddenNamespaces(clusterDeceptionPolicyName string, deceptionPolicies []v1alpha1.DeceptionPolicy) map[string]bool {
+	overridden := map[string]bool{}
+	for _, deceptionPolicy := range deceptionPolicies {
+		if _, managed := deceptionPolicy.Labels[constants.LabelKeyClusterDeceptionPolicyRef]; managed {
+			continue
+		}
+		for _, namespace := range deceptionPolicy.OverriddenNamespaces(clusterDeceptionPolicyName) {
+			overridden[namespace] = true
+		}
+	}
+
+	return overridden
+}
+
+// partitionNamespaces splits the selected namespaces into the ones that the traps are applied to and the overridden ones.
+func partitionNamespaces(selected []corev1.Namespace, overridden map[string]bool) ([]string, []string) {
+	namespaces, overriddenNamespaces := []string{}, []string{}
+	for _, namespace := range selected {
+		if namespace.DeletionTimestamp != nil || namespace.Name == utils.GetKoneyNamespace() || !utils.IsWatchedNamespace(namespace.Name) {
+			continue
+		}
+		if overridden[namespace.Name] {
+			overriddenNamespaces = append(overriddenNamespaces, namespace.Name)
+		} else {
+			namespaces = append(namespaces, namespace.Name)
+		}
+	}
+	slices.Sort(namespaces)
+	slices.Sort(overriddenNamespaces)
+
+	return namespaces, overriddenNamespaces
+}
+
+// buildClusterDeceptionPolicySpec returns the DeceptionPolicy that a ClusterDeceptionPolicy is rolled out with,
+// with the traps as they are written in the ClusterDeceptionPolicy.
+func buildClusterDeceptionPolicySpec(clusterDeceptionPolicy *v1alpha1.ClusterDeceptionPolicy) *v1alpha1.DeceptionPolicy {
+	spec := clusterDeceptionPolicy.Spec.DeepCopy()
+	return &v1alpha1.DeceptionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterDeceptionPolicy.DeceptionPolicyName()},
+		Spec: v1alpha1.DeceptionPolicySpec{
+			Traps:                  spec.Traps,
+			TrapDefaults:           spec.TrapDefaults,
+			StrictValidation:       spec.StrictValidation,
+			MutateExisting:         spec.MutateExisting,
+			MaxAlertsPerHour:       spec.MaxAlertsPerHour,
+			AlertAggregationWindow: spec.AlertAggregationWindow,
+			DryRun:                 spec.DryRun,
+			SelfTest:               spec.SelfTest,
+		},
+	}
+}
+
+// restrictTrapsToNamespaces narrows down the resource filters of the traps to the given namespaces.
+// Resource filters without namespaces get all of them, and the others keep the given namespaces they list.
+// Resource filters that are left without namespaces are dropped, and so are the traps that are left without resource filters
+// (e.g., if no namespace is selected at all).
+func restrictTrapsToNamespaces(traps []v1alpha1.Trap, namespaces []string) []v1alpha1.Trap {
+	restrictedTraps := make([]v1alpha1.Trap, 0, len(traps))
+	for _, trap := range traps {
+		// Traps without any resource filters are kept, so that the DeceptionPolicy reports them as invalid
+		if len(trap.MatchResources.Any) == 0 {
+			restrictedTraps = append(restrictedTraps, trap)
+			continue
+		}
+
+		resourceFilters := make([]v1alpha1.ResourceFilter, 0, len(trap.MatchResources.Any))
+		for _, resourceFilter := range trap.MatchResources.Any {
+			if len(resourceFilter.Namespaces) == 0 {
+				resourceFilter.Namespaces = slices.Clone(namespaces)
+			} else {
+				resourceFilter.Namespaces = slices.DeleteFunc(slices.Clone(resourceFilter.Namespaces), func(namespace string) bool {
+					return !slices.Contains(namespaces, namespace)
+				})
+			}
+			if len(resourceFilter.Namespaces) > 0 {
+				resourceFilters = append(resourceFilters, resourceFilter)
+			}
+		}
+
+		if len(resourceFilters) == 0 {
+			continue
+		}
+		trap.MatchResources.Any = resourceFilters
+		restrictedTraps = append(restrictedTraps, trap)
+	}
+
+	return restrictedTraps
+}
+
+// putDeceptionPolicy creates the DeceptionPolicy of a ClusterDeceptionPolicy, or updates it if it changed.
+// DeceptionPolicies with the same name that do not belong to the ClusterDeceptionPolicy are never touched.
+func (r *ClusterDeceptionPolicyReconciler) putDeceptionPolicy(ctx context.Context, clusterDeceptionPolicy *v1alpha1.ClusterDeceptionPolicy, desired *v1alpha1.DeceptionPolicy) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{}
+		if err := r.Get(ctx, client.ObjectKey{Name: desired.Name}, deceptionPolicy); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			deceptionPolicy.Name = desired.Name
+		} else if deceptionPolicy.Labels[constants.LabelKeyClusterDeceptionPolicyRef] != clusterDeceptionPolicy.Name {
+			return fmt.Errorf("DeceptionPolicy %s already exists and does not belong to ClusterDeceptionPolicy %s", desired.Name, clusterDeceptionPolicy.Name)
+		}
+
+		original := deceptionPolicy.DeepCopy()
+		if deceptionPolicy.Labels == nil {
+			deceptionPolicy.Labels = map[string]string{}
+		}
+		deceptionPolicy.Labels[constants.LabelKeyClusterDeceptionPolicyRef] = clusterDeceptionPolicy.Name
+
+		// The dry run and paused annotations of the ClusterDeceptionPolicy are passed on to its DeceptionPolicy
+		for _, annotation := range []string{v1alpha1.DryRunAnnotation, v1alpha1.PausedAnnotation} {
+			if value, ok := clusterDeceptionPolicy.Annotations[annotation]; ok {
+				if deceptionPolicy.Annotations == nil {
+					deceptionPolicy.Annotations = map[string]string{}
+				}
+				deceptionPolicy.Annotations[annotation] = value
+			} else {
+				delete(deceptionPolicy.Annotations, annotation)
+			}
+		}
+
+		deceptionPolicy.Spec = desired.Spec
+		if err := controllerutil.SetControllerReference(clusterDeceptionPolicy, deceptionPolicy, r.Scheme); err != nil {
+			return err
+		}
+
+		if deceptionPolicy.CreationTimestamp.IsZero() {
+			return r.Create(ctx, deceptionPolicy)
+		}
+		if equality.Semantic.DeepEqual(original.ObjectMeta, deceptionPolicy.ObjectMeta) && equality.Semantic.DeepEqual(original.Spec, deceptionPolicy.Spec) {
+			return nil // Already up-to-date
+		}
+		return r.Update(ctx, deceptionPolicy)
+	})
+}
+
+func (r *ClusterDeceptionPolicyReconciler) updateClusterDeceptionPolicyStatus(ctx context.Context, req ctrl.Request, clusterDeceptionPolicy *v1alpha1.ClusterDeceptionPolicy, status v1alpha1.ClusterDeceptionPolicyStatus) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, clusterDeceptionPolicy); err != nil {
+			return err
+		}
+
+		if equality.Semantic.DeepEqual(clusterDeceptionPolicy.Status, status) {
+			return nil // Status already has its desired value
+		}
+		clusterDeceptionPolicy.Status = status
+
+		return r.Status().Update(ctx, clusterDeceptionPolicy)
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterDeceptionPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Namespaces, DeceptionPolicies (which might override the ClusterDeceptionPolicies), and TrapTemplates
+	// can change what any ClusterDeceptionPolicy rolls out, so they are mapped to all of them
+	allClusterDeceptionPolicies := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return HandleClusterDeceptionPolicyEvent(r, ctx, obj)
+		})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ClusterDeceptionPolicy{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Named("clusterdeceptionpolicy").
+		Owns(&v1alpha1.DeceptionPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Namespace{}, allClusterDeceptionPolicies,
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.GenerationChangedPredicate{}))).
+		Watches(&v1alpha1.DeceptionPolicy{}, allClusterDeceptionPolicies,
+			builder.WithPredicates(predicate.NewPredicateFuncs(isUserDeceptionPolicy), predicate.GenerationChangedPredicate{})).
+		Watches(&v1alpha1.TrapTemplate{}, allClusterDeceptionPolicies,
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// HandleClusterDeceptionPolicyEvent maps any object to all ClusterDeceptionPolicies.
+func HandleClusterDeceptionPolicyEvent(r client.Reader, ctx context.Context, obj client.Object) []reconcile.Request {
+	log := k8slog.FromContext(ctx)
+
+	clusterDeceptionPolicies := &v1alpha1.ClusterDeceptionPolicyList{}
+	if err := r.List(ctx, clusterDeceptionPolicies); err != nil {
+		log.Error(err, "Unable to list ClusterDeceptionPolicies while watching resource changes")
+		return []reconcile.Request{}
+	}
+
+	reconcileRequests := make([]reconcile.Request, 0, len(clusterDeceptionPolicies.Items))
+	for _, clusterDeceptionPolicy := range clusterDeceptionPolicies.Items {
+		reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterDeceptionPolicy)})
+	}
+
+	return reconcileRequests
+}
+
+// isUserDeceptionPolicy returns true if a DeceptionPolicy was created by users rather than for a ClusterDeceptionPolicy,
+// since only those can override ClusterDeceptionPolicies (whether they did before a change cannot be told here).
+func isUserDeceptionPolicy(obj client.Object) bool {
+	deceptionPolicy, ok := obj.(*v1alpha1.DeceptionPolicy)
+	if !ok {
+		return false
+	}
+	_, managed := deceptionPolicy.Labels[constants.LabelKeyClusterDeceptionPolicyRef]
+	return !managed
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("ClusterDeceptionPolicy", func() {
+	newTrap := func(filePath string, namespaces ...string) v1alpha1.Trap {
+		return v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filePath, ReadOnly: &[]bool{true}[0]},
+			MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{
+				{ResourceDescription: v1alpha1.ResourceDescription{Namespaces: namespaces}},
+			}},
+		}
+	}
+
+	It("should narrow down the traps to the selected namespaces", func() {
+		traps := []v1alpha1.Trap{newTrap("/everywhere"), newTrap("/some", "team-a", "team-c"), newTrap("/elsewhere", "team-c")}
+
+		restricted := restrictTrapsToNamespaces(traps, []string{"team-a", "team-b"})
+		Expect(restricted).To(HaveLen(2))
+		Expect(restricted[0].MatchResources.Any[0].Namespaces).To(Equal([]string{"team-a", "team-b"}))
+		Expect(restricted[1].MatchResources.Any[0].Namespaces).To(Equal([]string{"team-a"}))
+
+		By("not changing the original traps")
+		Expect(traps[0].MatchResources.Any[0].Namespaces).To(BeEmpty())
+		Expect(traps[1].MatchResources.Any[0].Namespaces).To(Equal([]string{"team-a", "team-c"}))
+	})
+
+	It("should drop all traps if no namespace is selected, except the ones without resource filters", func() {
+		invalid := v1alpha1.Trap{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/invalid"}}
+		restricted := restrictTrapsToNamespaces([]v1alpha1.Trap{newTrap("/everywhere"), invalid}, []string{})
+		Expect(restricted).To(Equal([]v1alpha1.Trap{invalid}))
+	})
+
+	It("should skip overridden namespaces and the namespace of Koney", func() {
+		namespace := func(name string) corev1.Namespace {
+			return corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		}
+		deceptionPolicies := []v1alpha1.DeceptionPolicy{
+			{Spec: v1alpha1.DeceptionPolicySpec{ClusterPolicyOverrides: []v1alpha1.ClusterPolicyOverride{
+				{Name: "baseline", Namespaces: []string{"team-b"}},
+				{Name: "other", Namespaces: []string{"team-c"}},
+			}}},
+			{Spec: v1alpha1.DeceptionPolicySpec{ClusterPolicyOverrides: []v1alpha1.ClusterPolicyOverride{
+				{Namespaces: []string{"team-d"}},
+			}}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{constants.LabelKeyClusterDeceptionPolicyRef: "other"}},
+				Spec: v1alpha1.DeceptionPolicySpec{ClusterPolicyOverrides: []v1alpha1.ClusterPolicyOverride{
+					{Namespaces: []string{"team-a"}},
+				}},
+			},
+		}
+
+		overridden := collectOverriddenNamespaces("baseline", deceptionPolicies)
+		Expect(overridden).To(Equal(map[string]bool{"team-b": true, "team-d": true}))
+
+		namespaces, overriddenNamespaces := partitionNamespaces([]corev1.Namespace{
+			namespace("team-d"), namespace("team-c"), namespace("team-b"), namespace("team-a"), namespace("koney-system"),
+		}, overridden)
+		Expect(namespaces).To(Equal([]string{"team-a", "team-c"}))
+		Expect(overriddenNamespaces).To(Equal([]string{"team-b", "team-d"}))
+	})
+
+	It("should only roll out to the namespaces that Koney watches", func() {
+		GinkgoT().Setenv("KONEY_WATCH_NAMESPACES", "team-a,team-b")
+
+		namespaces, overriddenNamespaces := partitionNamespaces([]corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		}, map[string]bool{"team-b": true})
+		Expect(namespaces).To(Equal([]string{"team-a"}))
+		Expect(overriddenNamespaces).To(Equal([]string{"team-b"}))
+	})
+
+	It("should copy the spec into the DeceptionPolicy it is rolled out with", func() {
+		clusterDeceptionPolicy := &v1alpha1.ClusterDeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+			Spec: v1alpha1.ClusterDeceptionPolicySpec{
+				Traps:            []v1alpha1.Trap{newTrap("/everywhere")},
+				StrictValidation: &[]bool{false}[0],
+			},
+		}
+
+		deceptionPolicy := buildClusterDeceptionPolicySpec(clusterDeceptionPolicy)
+		Expect(deceptionPolicy.Name).To(Equal("koney-cluster-baseline"))
+		Expect(deceptionPolicy.Spec.Traps).To(Equal(clusterDeceptionPolicy.Spec.Traps))
+		Expect(*deceptionPolicy.Spec.StrictValidation).To(BeFalse())
+
+		By("not sharing memory with the ClusterDeceptionPolicy")
+		deceptionPolicy.Spec.Traps[0].FilesystemHoneytoken.FilePath = "/changed"
+		Expect(clusterDeceptionPolicy.Spec.Traps[0].FilesystemHoneytoken.FilePath).To(Equal("/everywhere"))
+	})
+
+	It("should copy every field that the ClusterDeceptionPolicy shares with the DeceptionPolicy", func() {
+		// Fields of a DeceptionPolicy that make no sense for the DeceptionPolicy of a ClusterDeceptionPolicy
+		notShared := []string{"ClusterPolicyOverrides"}
+
+		clusterSpecType := reflect.TypeOf(v1alpha1.ClusterDeceptionPolicySpec{})
+		specType := reflect.TypeOf(v1alpha1.DeceptionPolicySpec{})
+		for i := range specType.NumField() {
+			field := specType.Field(i)
+			if slices.Contains(notShared, field.Name) {
+				continue
+			}
+
+			clusterField, found := clusterSpecType.FieldByName(field.Name)
+			Expect(found).To(BeTrue(), "ClusterDeceptionPolicySpec lacks the field %s", field.Name)
+			Expect(clusterField.Type).To(Equal(field.Type), "field %s", field.Name)
+
+			// Set only this field (to a non-zero value) and check that it ends up in the DeceptionPolicy
+			clusterDeceptionPolicy := &v1alpha1.ClusterDeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "baseline"}}
+			value := reflect.ValueOf(&clusterDeceptionPolicy.Spec).Elem().FieldByName(field.Name)
+			switch field.Type.Kind() {
+			case reflect.Pointer:
+				value.Set(reflect.New(field.Type.Elem()))
+			case reflect.Slice:
+				value.Set(reflect.MakeSlice(field.Type, 1, 1))
+			default:
+				Fail(fmt.Sprintf("field %s has an unexpected kind %s", field.Name, field.Type.Kind()))
+			}
+
+			deceptionPolicy := buildClusterDeceptionPolicySpec(clusterDeceptionPolicy)
+			copied := reflect.ValueOf(deceptionPolicy.Spec).FieldByName(field.Name)
+			Expect(copied.Interface()).To(Equal(value.Interface()), "field %s is not copied", field.Name)
+		}
+	})
+})
@@ -0,0 +1,294 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/metrics"
+)
+
+// ClusterDeceptionReportReconciler computes the deception coverage of each ClusterDeceptionReport, i.e., which workloads
+// of the selected namespaces are protected by traps, from the trap statuses of all DeceptionPolicies.
+// The coverage is computed periodically, since it depends on the placements of all traps in the cluster.
+type ClusterDeceptionReportReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile computes the coverage of a ClusterDeceptionReport and records it in its status and in the metrics.
+func (r *ClusterDeceptionReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := k8slog.FromContext(ctx)
+
+	report := &v1alpha1.ClusterDeceptionReport{}
+	if err := r.Get(ctx, req.NamespacedName, report); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			metrics.ForgetCoverage(req.Name)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if report.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	namespaces, err := r.selectReportedNamespaces(ctx, report)
+	if err != nil {
+		log.Error(err, "Namespaces of ClusterDeceptionReport cannot be selected", "ClusterDeceptionReport", req.Name)
+		return ctrl.Result{}, err
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments); err != nil {
+		return ctrl.Result{}, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods); err != nil {
+		return ctrl.Result{}, err
+	}
+	deceptionPolicies, err := listAllDeceptionPolicies(r, ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := buildCoverageReport(namespaces, deployments.Items, pods.Items, deceptionPolicies)
+	now := metav1.Now()
+	status.GeneratedAt = &now
+	if err := r.updateClusterDeceptionReportStatus(ctx, req, report, status); err != nil {
+		log.Error(err, "Status of ClusterDeceptionReport cannot be set", "ClusterDeceptionReport", req.Name)
+		return ctrl.Result{}, err
+	}
+	metrics.RecordCoverage(report.Name, countCoverage(status))
+
+	log.Info("Deception coverage computed", "ClusterDeceptionReport", req.Name, "workloads", status.Summary.Workloads,
+		"protected", status.Summary.ProtectedWorkloads, "verified", status.Summary.VerifiedWorkloads)
+	return ctrl.Result{RequeueAfter: report.Spec.GetRefreshInterval()}, nil
+}
+
+// selectReportedNamespaces returns the sorted names of the namespaces that a ClusterDeceptionReport reports.
+// The namespace of Koney itself and namespaces that are being deleted are never reported.
+func (r *ClusterDeceptionReportReconciler) selectReportedNamespaces(ctx context.Context, report *v1alpha1.ClusterDeceptionReport) ([]string, error) {
+	selector := labels.Everything()
+	if report.Spec.NamespaceSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(report.Spec.NamespaceSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	namespaces, _ := partitionNamespaces(namespaceList.Items, nil)
+	return namespaces, nil
+}
+
+// workloadKey identifies a workload in the coverage report.
+type workloadKey struct {
+	namespace, kind, name string
+}
+
+// podWorkload returns the workload that a pod belongs to: the deployment of its replica set, its controller, or the pod itself.
+func podWorkload(pod *corev1.Pod) workloadKey {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return workloadKey{namespace: pod.Namespace, kind: "Pod", name: pod.Name}
+	}
+
+	// Replica sets of deployments are named after the deployment and the hash of the pod template
+	if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; owner.Kind == "ReplicaSet" && hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+		return workloadKey{namespace: pod.Namespace, kind: "Deployment", name: strings.TrimSuffix(owner.Name, "-"+hash)}
+	}
+	return workloadKey{namespace: pod.Namespace, kind: owner.Kind, name: owner.Name}
+}
+
+// buildCoverageReport computes which workloads of the given namespaces are protected by the traps of the DeceptionPolicies.
+// Workloads are deployments and the controllers of running pods (or the pods themselves, if they have none).
+// A trap is verified in a workload if the latest verification of its DeceptionPolicy found none of its decoys in the workload drifted.
+func buildCoverageReport(namespaces []string, deployments []appsv1.Deployment, pods []corev1.Pod,
+	deceptionPolicies []v1alpha1.DeceptionPolicy) v1alpha1.ClusterDeceptionReportStatus {
+	workloads := map[workloadKey]*v1alpha1.WorkloadCoverage{}
+	addWorkload := func(key workloadKey) *v1alpha1.WorkloadCoverage {
+		if workloads[key] == nil {
+			workloads[key] = &v1alpha1.WorkloadCoverage{Kind: key.kind, Name: key.name}
+		}
+		return workloads[key]
+	}
+
+	for _, deployment := range deployments {
+		if slices.Contains(namespaces, deployment.Namespace) {
+			addWorkload(workloadKey{namespace: deployment.Namespace, kind: "Deployment", name: deployment.Name})
+		}
+	}
+
+	// Traps that are placed in pods are attributed to the workloads of the pods
+	podWorkloads := map[client.ObjectKey]workloadKey{}
+	for i := range pods {
+		pod := &pods[i]
+		if !slices.Contains(namespaces, pod.Namespace) || pod.DeletionTimestamp != nil ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		key := podWorkload(pod)
+		podWorkloads[client.ObjectKeyFromObject(pod)] = key
+		addWorkload(key)
+	}
+
+	for _, deceptionPolicy := range deceptionPolicies {
+		condition := deceptionPolicy.Status.GetCondition(DecoysVerifiedType)
+		isVerified := condition != nil && (condition.Status == metav1.ConditionTrue || condition.Status == metav1.ConditionFalse)
+
+		for _, trapStatus := range deceptionPolicy.Status.Traps {
+			if trapStatus.Expired {
+				continue
+			}
+
+			for _, placement := range trapStatus.Placements {
+				if !slices.Contains(namespaces, placement.Namespace) {
+					continue
+				}
+
+				key := workloadKey{namespace: placement.Namespace, kind: placement.Kind, name: placement.Name}
+				if podKey, ok := podWorkloads[client.ObjectKey{Namespace: placement.Namespace, Name: placement.Name}]; ok && placement.Kind == "Pod" {
+					key = podKey
+				}
+				isDrifted := slices.ContainsFunc(trapStatus.Drifts, func(drift v1alpha1.TrapDrift) bool {
+					return drift.Kind == placement.Kind && drift.Namespace == placement.Namespace && drift.Name == placement.Name
+				})
+
+				workload := addWorkload(key)
+				index := slices.IndexFunc(workload.Traps, func(trap v1alpha1.WorkloadTrap) bool {
+					return trap.DeceptionPolicyName == deceptionPolicy.Name && trap.Index == trapStatus.Index
+				})
+				if index < 0 {
+					workload.Traps = append(workload.Traps, v1alpha1.WorkloadTrap{
+						DeceptionPolicyName: deceptionPolicy.Name,
+						Index:               trapStatus.Index,
+						TrapType:            trapStatus.TrapType,
+						TrapHash:            trapStatus.TrapHash,
+						Verified:            true,
+					})
+					index = len(workload.Traps) - 1
+				}
+
+				trap := &workload.Traps[index]
+				trap.Verified = trap.Verified && isVerified && !isDrifted
+				for _, container := range placement.Containers {
+					if !slices.Contains(trap.Containers, container) {
+						trap.Containers = append(trap.Containers, container)
+					}
+				}
+				slices.Sort(trap.Containers)
+			}
+		}
+	}
+
+	// Group the workloads by namespace, and count them
+	namespaceCoverages := make([]v1alpha1.NamespaceCoverage, 0, len(namespaces))
+	summary := v1alpha1.CoverageSummary{Namespaces: len(namespaces)}
+	for _, namespace := range namespaces {
+		namespaceCoverage := v1alpha1.NamespaceCoverage{Name: namespace, Summary: v1alpha1.CoverageSummary{Namespaces: 1}}
+		for key, workload := range workloads {
+			if key.namespace != namespace {
+				continue
+			}
+
+			workload.Verified = len(workload.Traps) > 0 && !slices.ContainsFunc(workload.Traps, func(trap v1alpha1.WorkloadTrap) bool { return !trap.Verified })
+			slices.SortFunc(workload.Traps, func(a, b v1alpha1.WorkloadTrap) int {
+				if c := strings.Compare(a.DeceptionPolicyName, b.DeceptionPolicyName); c != 0 {
+					return c
+				}
+				return a.Index - b.Index
+			})
+			namespaceCoverage.Workloads = append(namespaceCoverage.Workloads, *workload)
+
+			namespaceCoverage.Summary.Workloads++
+			if len(workload.Traps) > 0 {
+				namespaceCoverage.Summary.ProtectedWorkloads++
+			}
+			if workload.Verified {
+				namespaceCoverage.Summary.VerifiedWorkloads++
+			}
+		}
+		slices.SortFunc(namespaceCoverage.Workloads, func(a, b v1alpha1.WorkloadCoverage) int {
+			if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		summary.Workloads += namespaceCoverage.Summary.Workloads
+		summary.ProtectedWorkloads += namespaceCoverage.Summary.ProtectedWorkloads
+		summary.VerifiedWorkloads += namespaceCoverage.Summary.VerifiedWorkloads
+		namespaceCoverages = append(namespaceCoverages, namespaceCoverage)
+	}
+
+	return v1alpha1.ClusterDeceptionReportStatus{Summary: summary, Namespaces: namespaceCoverages}
+}
+
+// countCoverage counts the workloads of each namespace in a coverage report by their coverage, for the metrics.
+func countCoverage(status v1alpha1.ClusterDeceptionReportStatus) map[string]metrics.NamespaceCoverage {
+	coverage := make(map[string]metrics.NamespaceCoverage, len(status.Namespaces))
+	for _, namespace := range status.Namespaces {
+		coverage[namespace.Name] = metrics.NamespaceCoverage{
+			Unprotected: namespace.Summary.Workloads - namespace.Summary.ProtectedWorkloads,
+			Unverified:  namespace.Summary.ProtectedWorkloads - namespace.Summary.VerifiedWorkloads,
+			Verified:    namespace.Summary.VerifiedWorkloads,
+		}
+	}
+	return coverage
+}
+
+func (r *ClusterDeceptionReportReconciler) updateClusterDeceptionReportStatus(ctx context.Context, req ctrl.Request, report *v1alpha1.ClusterDeceptionReport, status v1alpha1.ClusterDeceptionReportStatus) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, report); err != nil {
+			return err
+		}
+
+		if equality.Semantic.DeepEqual(report.Status, status) {
+			return nil // Status already has its desired value
+		}
+		report.Status = status
+
+		return r.Status().Update(ctx, report)
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterDeceptionReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The coverage is computed again periodically (see Reconcile), so only changes of the spec trigger a reconciliation
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ClusterDeceptionReport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("clusterdeceptionreport").
+		Complete(r)
+}
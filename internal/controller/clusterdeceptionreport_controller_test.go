@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/metrics"
+)
+
+var _ = Describe("buildCoverageReport", func() {
+	var (
+		deployments       []appsv1.Deployment
+		pods              []corev1.Pod
+		deceptionPolicies []v1alpha1.DeceptionPolicy
+	)
+
+	BeforeEach(func() {
+		isController := true
+		deployments = []appsv1.Deployment{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "api"}},
+		}
+		pods = []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-5d8f7-abcde",
+				Labels:          map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5d8f7"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f7", Controller: &isController}}}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "db-0",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &isController}}}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "debug"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "job-xyz"}, Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		}
+		deceptionPolicies = []v1alpha1.DeceptionPolicy{{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-coverage"},
+			Status: v1alpha1.DeceptionPolicyStatus{
+				Conditions: []v1alpha1.DeceptionPolicyCondition{{Type: DecoysVerifiedType, Status: metav1.ConditionFalse}},
+				Traps: []v1alpha1.TrapStatus{
+					{Index: 0, TrapType: v1alpha1.FilesystemHoneytokenTrap, TrapHash: "a", Placements: []v1alpha1.TrapPlacement{
+						{Kind: "Deployment", Namespace: "shop", Name: "api", Containers: []string{"app"}},
+						{Kind: "Deployment", Namespace: "other", Name: "api", Containers: []string{"app"}},
+					}},
+					{Index: 1, TrapType: v1alpha1.FilesystemHoneytokenTrap, TrapHash: "b", Placements: []v1alpha1.TrapPlacement{
+						{Kind: "Pod", Namespace: "shop", Name: "web-5d8f7-abcde", Containers: []string{"nginx"}},
+						{Kind: "Pod", Namespace: "shop", Name: "db-0", Containers: []string{"postgres"}},
+					}, Drifts: []v1alpha1.TrapDrift{{Kind: "Pod", Namespace: "shop", Name: "db-0", Container: "postgres", Reason: "ContainerRestarted"}}},
+					{Index: 2, Expired: true, Placements: []v1alpha1.TrapPlacement{{Kind: "Pod", Namespace: "shop", Name: "debug"}}},
+				},
+			},
+		}}
+	})
+
+	It("should report which workloads of the namespaces are protected and verified", func() {
+		status := buildCoverageReport([]string{"empty", "shop"}, deployments, pods, deceptionPolicies)
+		Expect(status.Summary).To(Equal(v1alpha1.CoverageSummary{Namespaces: 2, Workloads: 4, ProtectedWorkloads: 3, VerifiedWorkloads: 2}))
+		Expect(status.Namespaces).To(HaveLen(2))
+		Expect(status.Namespaces[0].Name).To(Equal("empty"))
+		Expect(status.Namespaces[0].Workloads).To(BeEmpty())
+
+		workloads := status.Namespaces[1].Workloads
+		Expect(workloads).To(HaveLen(4))
+		Expect(workloads[0].Kind + "/" + workloads[0].Name).To(Equal("Deployment/api"))
+		Expect(workloads[0].Verified).To(BeTrue())
+		Expect(workloads[0].Traps).To(Equal([]v1alpha1.WorkloadTrap{{DeceptionPolicyName: "deceptionpolicy-coverage", Index: 0,
+			TrapType: v1alpha1.FilesystemHoneytokenTrap, TrapHash: "a", Containers: []string{"app"}, Verified: true}}))
+
+		By("attributing pods to the deployments of their replica sets and to their controllers")
+		Expect(workloads[1].Kind + "/" + workloads[1].Name).To(Equal("Deployment/web"))
+		Expect(workloads[1].Traps[0].Containers).To(Equal([]string{"nginx"}))
+		Expect(workloads[1].Verified).To(BeTrue())
+		Expect(workloads[2].Kind + "/" + workloads[2].Name).To(Equal("Pod/debug"))
+		Expect(workloads[2].Traps).To(BeEmpty())
+		Expect(workloads[3].Kind + "/" + workloads[3].Name).To(Equal("StatefulSet/db"))
+		Expect(workloads[3].Verified).To(BeFalse())
+	})
+
+	It("should not verify traps of DeceptionPolicies that were not verified yet", func() {
+		deceptionPolicies[0].Status.Conditions = nil
+
+		status := buildCoverageReport([]string{"shop"}, deployments, pods, deceptionPolicies)
+		Expect(status.Summary.ProtectedWorkloads).To(Equal(3))
+		Expect(status.Summary.VerifiedWorkloads).To(BeZero())
+
+		coverage := countCoverage(status)
+		Expect(coverage).To(Equal(map[string]metrics.NamespaceCoverage{"shop": {Unprotected: 1, Unverified: 3}}))
+	})
+})
@@ -32,20 +32,281 @@ const (
 	// Koney might create resources such as a TracingPolicy for captors.
 	LabelKeyDeceptionPolicyRef = "koney/deception-policy"
 
+	// LabelKeyDecoySecret is the label key that is placed on the secrets that hold decoys (with the value "true"),
+	// so that only the secrets that Koney manages are garbage-collected.
+	LabelKeyDecoySecret = "koney/decoy-secret"
+
+	// LabelKeyCanaryToken is the label key that is placed on the secrets that store provisioned canary tokens (with the value "true"),
+	// so that the alert forwarder finds the DeceptionPolicy of a triggered canary token.
+	LabelKeyCanaryToken = "koney/canary-token"
+
+	// LabelKeyClusterDeceptionPolicyRef is the label key that is placed on the DeceptionPolicy that a ClusterDeceptionPolicy is rolled out with.
+	LabelKeyClusterDeceptionPolicyRef = "koney/cluster-deception-policy"
+
+	// LabelKeyPrefixNodeScope is the prefix of the label key that is placed on pods that match the node constraints of a trap.
+	// Captors select pods by this label, since Tetragon and Kive cannot select pods by the labels of their nodes.
+	LabelKeyPrefixNodeScope = "koney/node-scope-"
+
+	// LabelKeyQuarantined is the label key that is placed on pods by the "labelPod" and "networkIsolate" response actions.
+	// The network policies of quarantined pods select pods by this label. Remove the label to release a pod.
+	LabelKeyQuarantined = "koney/quarantined"
+
+	// LabelKeyQuarantineID is the label key that identifies a quarantined pod, so that its network policies only select this pod.
+	LabelKeyQuarantineID = "koney/quarantine-id"
+
+	// LabelKeyFollowID is the label key that identifies a pod that is followed by the "followAttacker" response action,
+	// so that its TracingPolicy only selects this pod.
+	LabelKeyFollowID = "koney/follow-id"
+
+	// LabelKeyPrefixAttckTechnique is the prefix of the label key that is placed on captors for each MITRE ATT&CK technique of the trap.
+	// The technique ID is appended to the prefix (e.g., "koney/attck-T1552.001") and the label value is "true".
+	LabelKeyPrefixAttckTechnique = "koney/attck-"
+
+	// LabelKeyPrefixEngageActivity is the prefix of the label key that is placed on captors for each MITRE Engage activity of the trap.
+	// The activity ID is appended to the prefix (e.g., "koney/engage-EAC0005") and the label value is "true".
+	LabelKeyPrefixEngageActivity = "koney/engage-"
+
+	// LabelKeyHoneytokenGeneration is the label key that is placed on DeceptionAlerts of rotating honeytokens
+	// to store the generation of the honeytoken that was active when it was accessed (e.g., "3").
+	LabelKeyHoneytokenGeneration = "koney/honeytoken-generation"
+
+	// LabelKeySeverity is the label key that is placed on captors to store the severity of the trap (e.g., "high").
+	LabelKeySeverity = "koney/severity"
+
+	// LabelKeyConfidence is the label key that is placed on captors to store the confidence of the trap (e.g., "medium").
+	LabelKeyConfidence = "koney/confidence"
+
+	// QuarantineNetworkPolicyPrefix is the prefix of the names of the network policies that deny all traffic of a quarantined pod.
+	QuarantineNetworkPolicyPrefix = "koney-quarantine-"
+
+	// FollowTracingPolicyPrefix is the prefix of the names of the TracingPolicies that trace all executions and connections of a followed pod.
+	// It differs from the prefix of captors, so that the alert forwarder does not alert on their events.
+	FollowTracingPolicyPrefix = "koney-follow-"
+
 	// The name used by our controller to claim ownership of fields when doing server-side apply in Kubernetes.
 	FieldOwnerKoneyController = "koney-controller"
 
 	// MetadataKeyDeceptionPolicyName is the key that custom metadata in foreign resources holds to store the deception policy name
 	MetadataKeyDeceptionPolicyName = "koney-deception-policy-name"
 
+	// MetadataKeyAlertSeverity is the key that custom metadata in foreign resources holds to store the severity override of a trap
+	MetadataKeyAlertSeverity = "koney-alert-severity"
+
+	// MetadataKeyAlertTags is the key that custom metadata in foreign resources holds to store the custom alert tags of a trap (JSON-encoded)
+	MetadataKeyAlertTags = "koney-alert-tags"
+
+	// MetadataKeyTrapHash is the key that custom metadata in foreign resources holds to store the hash of the originating trap spec
+	MetadataKeyTrapHash = "koney-trap-hash"
+
+	// MetadataKeyTrapDescription is the key that custom metadata in foreign resources holds to store the description of a trap
+	MetadataKeyTrapDescription = "koney-trap-description"
+
+	// MetadataKeyDeceptionPolicyGeneration is the key that custom metadata in foreign resources holds to store the deception policy generation
+	MetadataKeyDeceptionPolicyGeneration = "koney-deception-policy-generation"
+
+	// MetadataKeyResponseActions is the key that custom metadata in foreign resources holds to store the response actions of a trap (JSON-encoded)
+	MetadataKeyResponseActions = "koney-response-actions"
+
+	// MetadataKeyQuarantineTTL is the key that custom metadata in foreign resources holds to store how long a trap quarantines pods
+	MetadataKeyQuarantineTTL = "koney-quarantine-ttl"
+
+	// MetadataKeyAttckTechniques is the key that custom metadata in foreign resources holds to store the MITRE ATT&CK techniques of a trap (JSON-encoded)
+	MetadataKeyAttckTechniques = "koney-attck-techniques"
+
+	// MetadataKeyEngageActivities is the key that custom metadata in foreign resources holds to store the MITRE Engage activities of a trap (JSON-encoded)
+	MetadataKeyEngageActivities = "koney-engage-activities"
+
+	// MetadataKeySeverity is the key that custom metadata in foreign resources holds to store the severity of a trap
+	MetadataKeySeverity = "koney-severity"
+
+	// MetadataKeyConfidence is the key that custom metadata in foreign resources holds to store the confidence of a trap
+	MetadataKeyConfidence = "koney-confidence"
+
+	// MetadataKeyTrapType is the key that custom metadata in foreign resources holds to store the trap type of a trap
+	MetadataKeyTrapType = "koney-trap-type"
+
+	// MetadataKeyFilePath is the key that custom metadata in foreign resources holds to store the file path of a filesystem honeytoken
+	MetadataKeyFilePath = "koney-file-path"
+
 	// If reconciliation fails, retry after this interval.
 	NormalFailureRetryInterval = 1 * time.Minute
 
 	// If resources are not ready yet for traps (e.g., containers are still starting), retry reconciliation after this shorter interval.
 	ShortStatusCheckInterval = 10 * time.Second
 
-	// AnnotationKeyContainerSelectors is the annotation key on a TracingPolicy that stores the original container selectors.
+	// If only the traps that changed were reconciled after a spec change, reconcile all traps again after this interval.
+	PartialReconciliationResyncInterval = 30 * time.Second
+
+	// Check again if the controller is allowed to exec into pods (for the "auto" decoy deployment strategy) after this interval.
+	ExecPermissionProbeInterval = 5 * time.Minute
+
+	// Retry placing a decoy in a container after this delay when it failed, doubling it after every failed attempt up to PlacementRetryMaxDelay.
+	PlacementRetryBaseDelay = 30 * time.Second
+	PlacementRetryMaxDelay  = 10 * time.Minute
+
+	// Give up placing a decoy in a container after this many failed attempts (until the DeceptionPolicy changes).
+	PlacementMaxAttempts = 5
+
+	// Verify that deployed decoys are still in place (and deploy them again otherwise) after this interval.
+	DecoyVerificationInterval = 5 * time.Minute
+
+	// Orphaned secrets of decoys are only deleted if they are older than this,
+	// because the pods that mount a freshly created secret might not exist yet.
+	OrphanedSecretGracePeriod = 1 * time.Minute
+
+	// Look for secrets and volumes of decoys that are not used anymore (and delete them) after this interval.
+	DecoyGarbageCollectionInterval = 5 * time.Minute
+
+	// Look for captors whose DeceptionPolicy or trap does not exist anymore (and delete them) after this interval.
+	CaptorGarbageCollectionInterval = 10 * time.Minute
+
+	// Orphaned captors are only deleted if they are older than this, so that captors of traps that were
+	// just added are not mistaken for orphans before the cache observed the new DeceptionPolicy.
+	OrphanedCaptorGracePeriod = 1 * time.Minute
+
+	// After the CRD of Tetragon tracing policies was recreated, wait this long before recreating the captors,
+	// so that Tetragon is ready and the informers of the controller observed the new CRD.
+	CaptorResyncDelay = 10 * time.Second
+
+	// DefaultInitContainerImage is the image of the init container that writes decoys with the initContainer strategy, unless a trap sets another one.
+	DefaultInitContainerImage = "busybox:1.37"
+
+	// DefaultEphemeralContainerImage is the image of the ephemeral containers that write decoys with the ephemeralContainer strategy, unless a trap sets another one.
+	DefaultEphemeralContainerImage = "busybox:1.37"
+
+	// InitContainerDecoyDir is the directory where the init container of the initContainer strategy mounts the shared emptyDir volume.
+	InitContainerDecoyDir = "/koney"
+
+	// InitContainerUser is the (unprivileged) user that the init container of the initContainer strategy runs as.
+	InitContainerUser = 65534
+
+	// DefaultCaptorSidecarImage is the image of the watcher sidecar of the sidecar captor strategy. It needs inotifyd and wget.
+	DefaultCaptorSidecarImage = "busybox:1.37"
+
+	// CaptorSidecarNamePrefix is the prefix of the names of the watcher sidecars of the sidecar captor strategy.
+	// Containers with this prefix are never selected by traps.
+	CaptorSidecarNamePrefix = "koney-captor-"
+
+	// CaptorSidecarDecoyDir is the directory where the watcher sidecar of the sidecar captor strategy mounts the volume of the decoy.
+	CaptorSidecarDecoyDir = "/koney"
+
+	// CaptorSidecarUser is the (unprivileged) user that the watcher sidecar of the sidecar captor strategy runs as.
+	CaptorSidecarUser = 65534
+
+	// DefaultSelfTestImage is the image of the test pods that run the self-tests of DeceptionPolicies, which is the image of the alert forwarder.
+	DefaultSelfTestImage = "ghcr.io/dynatrace-oss/koney-alert-forwarder:0.2.0"
+
+	// SelfTestServiceAccountName is the service account of the test pods that run the self-tests of DeceptionPolicies,
+	// which may exec into the pods with honeytokens and report the results in the status of DeceptionPolicies.
+	SelfTestServiceAccountName = "koney-self-test"
+
+	// SelfTestCronJobPrefix is the prefix of the names of the CronJobs that run the self-tests of DeceptionPolicies.
+	SelfTestCronJobPrefix = "koney-self-test-"
+
+	// TetragonTracingPolicyCRDName is the name of the CRD of Tetragon tracing policies.
+	TetragonTracingPolicyCRDName = "tracingpolicies.cilium.io"
+
+	// KivePolicyCRDName is the name of the CRD of Kive policies, which the Kive operator installs.
+	KivePolicyCRDName = "kivepolicies.kivebpf.san7o.github.io"
+
+	// AnnotationKeyContainerSelectors is the annotation key on a TracingPolicy that stores the included and excluded container patterns of each resource filter.
 	// It is set so that the alert forward can possibly perform client-side filtering of alerts (typically for regex- and glob-based selectors).
 	// This is needed for captor strategies that do not support setting complex container selectors directly, e.g., in the tracing policy.
 	AnnotationKeyContainerSelectors = "koney/container-selectors"
+
+	// AnnotationKeyInjectedContainers is the annotation key on a pod template that lists the containers that Koney injected (e.g., decoy or proxy sidecars).
+	// Autoscalers and other tooling can use this list to exclude the deception layer from their calculations.
+	AnnotationKeyInjectedContainers = "koney/injected-containers"
+
+	// AnnotationKeyExcludedContainers is the annotation key on a VerticalPodAutoscaler that lists the injected containers
+	// for which Koney added a container policy with mode "Off", so that Koney only removes its own container policies again.
+	AnnotationKeyExcludedContainers = "koney/excluded-containers"
+
+	// AnnotationKeyAlertSeverity is the annotation key on a TracingPolicy that stores the severity override of the trap.
+	// The alert forwarder uses it to set the severity of emitted alerts.
+	AnnotationKeyAlertSeverity = "koney/alert-severity"
+
+	// AnnotationKeyAlertTags is the annotation key on a TracingPolicy that stores the custom alert tags of the trap (JSON-encoded).
+	// The alert forwarder attaches these tags to emitted alerts.
+	AnnotationKeyAlertTags = "koney/alert-tags"
+
+	// AnnotationKeyTrapHash is the annotation key on a TracingPolicy, KivePolicy, or decoy secret that stores the hash of the originating trap spec.
+	// Operators and the alert forwarder can use it to correlate the resource with the trap in the DeceptionPolicy,
+	// and the controller uses it to detect captors that must be updated because their trap changed.
+	AnnotationKeyTrapHash = "koney/trap-hash"
+
+	// AnnotationKeyTrapType is the annotation key on a TracingPolicy or KivePolicy that stores the trap type of its alerts (e.g., "filesystem_honeytoken").
+	// The alert forwarder uses it to pick the mapper that interprets the events of the TracingPolicy, instead of guessing from the events.
+	AnnotationKeyTrapType = "koney/trap-type"
+
+	// AnnotationKeyFilePath is the annotation key on a TracingPolicy or KivePolicy that stores the file path of the filesystem honeytoken.
+	// The alert forwarder attributes alerts to this path when the event itself does not carry one.
+	AnnotationKeyFilePath = "koney/file-path"
+
+	// AnnotationKeyCallbackHash is the annotation key on a TracingPolicy or KivePolicy that stores the hash of its callback URL,
+	// if the alert forwarder requires TLS or a token. The controller uses it to update captors when the token changes.
+	AnnotationKeyCallbackHash = "koney/callback-hash"
+
+	// AnnotationKeySelfTestHash is the annotation key on the self-test CronJob of a DeceptionPolicy that stores the hash of its spec.
+	// The controller uses it to update the CronJob when the self-test configuration or the alert forwarder changes.
+	AnnotationKeySelfTestHash = "koney/self-test-hash"
+
+	// AnnotationKeyFalcoRuleTimestamps is the annotation key on the Falco rules ConfigMap that stores when each rules file was added (JSON-encoded),
+	// so that the captor garbage collector can apply the grace period to single rules files.
+	AnnotationKeyFalcoRuleTimestamps = "koney/falco-rule-timestamps"
+
+	// AnnotationKeyTrapDescription is the annotation key on a TracingPolicy that stores the human-readable description of the trap.
+	AnnotationKeyTrapDescription = "koney/trap-description"
+
+	// AnnotationKeyDeceptionPolicyGeneration is the annotation key on a TracingPolicy that stores
+	// the generation of the DeceptionPolicy that the TracingPolicy was last deployed from.
+	AnnotationKeyDeceptionPolicyGeneration = "koney/deception-policy-generation"
+
+	// AnnotationKeyResponseActions is the annotation key on a TracingPolicy that stores the response actions of the trap (JSON-encoded).
+	// The alert forwarder only requests a response for alerts of traps with response actions.
+	AnnotationKeyResponseActions = "koney/response-actions"
+
+	// AnnotationKeyResponseRequest is the annotation key on a pod that the alert forwarder sets to request a response to an alert (JSON-encoded).
+	// The controller takes the response actions of the trap and removes the annotation afterwards.
+	AnnotationKeyResponseRequest = "koney/response-request"
+
+	// AnnotationKeyQuarantineTTL is the annotation key on a TracingPolicy that stores how long the trap quarantines pods.
+	// The alert forwarder records it in the alerts of the trap.
+	AnnotationKeyQuarantineTTL = "koney/quarantine-ttl"
+
+	// AnnotationKeyQuarantinedUntil is the annotation key on a quarantined pod that stores when the quarantine is lifted (RFC 3339).
+	AnnotationKeyQuarantinedUntil = "koney/quarantined-until"
+
+	// AnnotationKeyFollowedUntil is the annotation key on a followed pod that stores when its TracingPolicy is removed (RFC 3339).
+	AnnotationKeyFollowedUntil = "koney/followed-until"
+
+	// AnnotationKeyAttckTechniques is the annotation key on a TracingPolicy that stores the MITRE ATT&CK techniques of the trap (JSON-encoded).
+	AnnotationKeyAttckTechniques = "koney/attck-techniques"
+
+	// AnnotationKeyEngageActivities is the annotation key on a TracingPolicy that stores the MITRE Engage activities of the trap (JSON-encoded).
+	AnnotationKeyEngageActivities = "koney/engage-activities"
+)
+
+// Reasons of the events that Koney emits on a DeceptionPolicy, so that `kubectl describe` shows the history of its traps.
+const (
+	// EventReasonTrapDeployed is the reason of the event that a decoy was placed in containers of a resource.
+	EventReasonTrapDeployed = "TrapDeployed"
+
+	// EventReasonTrapRemoved is the reason of the event that a decoy was removed from a resource.
+	EventReasonTrapRemoved = "TrapRemoved"
+
+	// EventReasonPlacementFailed is the reason of the event that a decoy cannot be placed in a container of a resource.
+	EventReasonPlacementFailed = "PlacementFailed"
+
+	// EventReasonCaptorCreated is the reason of the event that a captor (e.g., a Tetragon tracing policy) was created.
+	EventReasonCaptorCreated = "CaptorCreated"
+
+	// EventReasonDecoyTampered is the reason of the event that the content of a placed decoy does not match its recorded checksum.
+	EventReasonDecoyTampered = "DecoyTampered"
+
+	// EventReasonSelfTestScheduled is the reason of the event that the self-test CronJob of a DeceptionPolicy was created or updated.
+	EventReasonSelfTestScheduled = "SelfTestScheduled"
+
+	// EventReasonCanaryTokenProvisioned is the reason of the event that a canary token was provisioned for a honeytoken.
+	EventReasonCanaryTokenProvisioned = "CanaryTokenProvisioned"
 )
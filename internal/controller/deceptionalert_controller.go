@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+// DeceptionAlertReconciler attributes DeceptionAlerts of rotating honeytokens to their generation,
+// and deletes DeceptionAlerts once their retention period passed.
+type DeceptionAlertReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Retention is how long DeceptionAlerts are kept after they were created. Zero keeps them forever.
+	Retention time.Duration
+}
+
+// Reconcile labels a DeceptionAlert with the generation of its rotating honeytoken,
+// and deletes it if it expired, or checks back when it expires.
+func (r *DeceptionAlertReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	deceptionAlert := &v1alpha1.DeceptionAlert{}
+	if err := r.Get(ctx, req.NamespacedName, deceptionAlert); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if err := r.labelHoneytokenGeneration(ctx, deceptionAlert); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.Retention <= 0 {
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	expiry := deceptionAlert.CreationTimestamp.Add(r.Retention)
+	if now.Before(expiry) {
+		return ctrl.Result{RequeueAfter: expiry.Sub(now)}, nil
+	}
+
+	k8slog.FromContext(ctx).Info("Deleting expired DeceptionAlert", "deceptionAlert", req.NamespacedName, "retention", r.Retention)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, deceptionAlert))
+}
+
+// labelHoneytokenGeneration labels a DeceptionAlert of a rotating honeytoken with the generation that was active
+// when the honeytoken was accessed, as recorded in the status of its DeceptionPolicy. That way, alerts that are
+// triggered late can still be attributed to the generation they came from, even after the honeytoken was rotated.
+func (r *DeceptionAlertReconciler) labelHoneytokenGeneration(ctx context.Context, deceptionAlert *v1alpha1.DeceptionAlert) error {
+	if deceptionAlert.Spec.TrapType != string(alerts.FilesystemHoneytokenTrap) || deceptionAlert.Spec.DeceptionPolicyName == "" {
+		return nil
+	}
+	if _, labeled := deceptionAlert.Labels[constants.LabelKeyHoneytokenGeneration]; labeled {
+		return nil
+	}
+
+	var koneyAlert alerts.KoneyAlert
+	if err := json.Unmarshal(deceptionAlert.Spec.Alert.Raw, &koneyAlert); err != nil {
+		return nil // alerts in an unknown format cannot be attributed
+	}
+	filePath, _ := koneyAlert.Metadata["file_path"].(string)
+	if filePath == "" {
+		return nil
+	}
+
+	deceptionPolicy := &v1alpha1.DeceptionPolicy{}
+	if err := r.Get(ctx, client.ObjectKey{Name: deceptionAlert.Spec.DeceptionPolicyName}, deceptionPolicy); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	rotation := deceptionPolicy.Status.FindHoneytokenRotation(filePath, deceptionAlert.Spec.Timestamp)
+	if rotation == nil {
+		return nil
+	}
+
+	patch := client.MergeFrom(deceptionAlert.DeepCopy())
+	if deceptionAlert.Labels == nil {
+		deceptionAlert.Labels = map[string]string{}
+	}
+	deceptionAlert.Labels[constants.LabelKeyHoneytokenGeneration] = strconv.FormatInt(rotation.Generation, 10)
+	return client.IgnoreNotFound(r.Patch(ctx, deceptionAlert, patch))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DeceptionAlertReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.DeceptionAlert{}).
+		Named("deceptionalert").
+		Complete(r)
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("DeceptionAlert retention", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+	)
+
+	newDeceptionAlert := func(name string, age time.Duration) *v1alpha1.DeceptionAlert {
+		return &v1alpha1.DeceptionAlert{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "koney-system",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		}}
+	}
+
+	reconcile := func(retention time.Duration, name string) ctrl.Result {
+		reconciler := &DeceptionAlertReconciler{Client: fakeClient, Retention: retention}
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "koney-system", Name: name}})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	exists := func(name string) bool {
+		err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "koney-system", Name: name}, &v1alpha1.DeceptionAlert{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(newDeceptionAlert("old", 2*time.Hour), newDeceptionAlert("new", time.Minute)).Build()
+	})
+
+	It("should delete alerts once their retention passed", func() {
+		Expect(reconcile(time.Hour, "old")).To(Equal(ctrl.Result{}))
+		Expect(exists("old")).To(BeFalse())
+
+		result := reconcile(time.Hour, "new")
+		Expect(result.RequeueAfter).To(BeNumerically("~", 59*time.Minute, time.Minute))
+		Expect(exists("new")).To(BeTrue())
+	})
+
+	It("should keep alerts forever without a retention", func() {
+		Expect(reconcile(0, "old")).To(Equal(ctrl.Result{}))
+		Expect(exists("old")).To(BeTrue())
+	})
+
+	It("should ignore alerts that were already deleted", func() {
+		Expect(reconcile(time.Hour, "missing")).To(Equal(ctrl.Result{}))
+	})
+})
+
+var _ = Describe("DeceptionAlert honeytoken generations", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		accessedAt time.Time
+	)
+
+	newDeceptionAlert := func(name, filePath string, timestamp time.Time) *v1alpha1.DeceptionAlert {
+		return &v1alpha1.DeceptionAlert{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "koney-system", CreationTimestamp: metav1.Now()},
+			Spec: v1alpha1.DeceptionAlertSpec{
+				Timestamp:           metav1.NewTime(timestamp),
+				DeceptionPolicyName: "rotating",
+				TrapType:            "filesystem_honeytoken",
+				Alert:               runtime.RawExtension{Raw: []byte(`{"metadata":{"file_path":"` + filePath + `"}}`)},
+			},
+		}
+	}
+
+	generationLabel := func(name string) (string, bool) {
+		deceptionAlert := &v1alpha1.DeceptionAlert{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "koney-system", Name: name}, deceptionAlert)).To(Succeed())
+		generation, labeled := deceptionAlert.Labels[constants.LabelKeyHoneytokenGeneration]
+		return generation, labeled
+	}
+
+	reconcile := func(name string) {
+		reconciler := &DeceptionAlertReconciler{Client: fakeClient}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "koney-system", Name: name}})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		now := time.Now().Truncate(time.Second)
+		accessedAt = now.Add(-36 * time.Hour)
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "rotating"},
+			Status: v1alpha1.DeceptionPolicyStatus{HoneytokenRotations: []v1alpha1.HoneytokenRotation{
+				{FilePath: "/run/secrets/api_key", Generation: 1, ActiveFrom: metav1.NewTime(now.Add(-48 * time.Hour)), ActiveUntil: metav1.NewTime(now.Add(-24 * time.Hour))},
+				{FilePath: "/run/secrets/api_key", Generation: 2, ActiveFrom: metav1.NewTime(now.Add(-24 * time.Hour)), ActiveUntil: metav1.NewTime(now)},
+			}},
+		}
+
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(deceptionPolicy,
+			newDeceptionAlert("late", "/run/secrets/api_key", accessedAt),
+			newDeceptionAlert("static", "/run/secrets/password", accessedAt),
+			newDeceptionAlert("forgotten", "/run/secrets/api_key", now.Add(-72*time.Hour)),
+		).Build()
+	})
+
+	It("should label alerts with the generation that was active when the honeytoken was accessed", func() {
+		reconcile("late")
+		generation, labeled := generationLabel("late")
+		Expect(labeled).To(BeTrue())
+		Expect(generation).To(Equal("1"))
+	})
+
+	It("should not label alerts of honeytokens that do not rotate", func() {
+		reconcile("static")
+		_, labeled := generationLabel("static")
+		Expect(labeled).To(BeFalse())
+	})
+
+	It("should not label alerts whose generation is no longer recorded", func() {
+		reconcile("forgotten")
+		_, labeled := generationLabel("forgotten")
+		Expect(labeled).To(BeFalse())
+	})
+})
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// provisionCanaryTokens provisions the canary tokens of the honeytokens of the given traps that were not provisioned yet
+// (or whose secret was deleted), one per file path, stores each of them in a secret, and records the reference to it
+// in the status of the DeceptionPolicy right away, so that every canary token is provisioned only once.
+// It returns the errors of the canary tokens that cannot be provisioned, per file path, which are retried with the next reconciliation.
+func (r *DeceptionPolicyReconciler) provisionCanaryTokens(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap) map[string]error {
+	expandedTraps := filesystoken.ExpandFilePaths(traps)
+	missingTraps := findMissingCanaryTokens(deceptionPolicy, expandedTraps)
+	lostCanaryTokens := r.findLostCanaryTokens(ctx, deceptionPolicy)
+	if len(missingTraps) == 0 && len(lostCanaryTokens) == 0 {
+		return nil
+	}
+
+	// The cached DeceptionPolicy might not know the canary tokens that were recorded by the last reconciliation yet
+	var latest v1alpha1.DeceptionPolicy
+	if err := r.apiReader().Get(ctx, req.NamespacedName, &latest); err != nil {
+		errs := map[string]error{}
+		for _, trap := range missingTraps {
+			errs[trap.FilesystemHoneytoken.FilePath] = fmt.Errorf("%w for %s: %w", trapsapi.ErrCanaryTokenNotProvisioned, trap.FilesystemHoneytoken.FilePath, err)
+		}
+		return errs
+	}
+	for _, canaryToken := range latest.Status.CanaryTokens {
+		deceptionPolicy.Status.PutCanaryToken(canaryToken)
+	}
+	deceptionPolicy.Status.CanaryTokens = slices.DeleteFunc(deceptionPolicy.Status.CanaryTokens, func(canaryToken v1alpha1.CanaryTokenStatus) bool {
+		return slices.Contains(lostCanaryTokens, canaryToken.SecretName)
+	})
+
+	errs := map[string]error{}
+	provisioner := r.canaryTokenProvisioner()
+	for _, trap := range findMissingCanaryTokens(deceptionPolicy, expandedTraps) {
+		filePath, kind := trap.FilesystemHoneytoken.FilePath, trap.FilesystemHoneytoken.CanaryToken.Kind
+		canaryToken, err := provisioner.Provision(ctx, kind, filesystoken.BuildCanaryTokenMemo(deceptionPolicy, filePath))
+		if err != nil {
+			errs[filePath] = fmt.Errorf("%w for %s: %w", trapsapi.ErrCanaryTokenNotProvisioned, filePath, err)
+			continue
+		}
+
+		canaryTokenStatus, err := filesystoken.StoreCanaryToken(r.Client, ctx, deceptionPolicy, filePath, kind, canaryToken)
+		if err == nil {
+			err = r.recordCanaryToken(ctx, req, canaryTokenStatus)
+		}
+		if err != nil {
+			errs[filePath] = fmt.Errorf("%w for %s: %w", trapsapi.ErrCanaryTokenNotProvisioned, filePath, err)
+			continue
+		}
+		deceptionPolicy.Status.PutCanaryToken(canaryTokenStatus)
+
+		r.Recorder.Eventf(deceptionPolicy, corev1.EventTypeNormal, constants.EventReasonCanaryTokenProvisioned,
+			"Canary token (%s) provisioned for honeytoken %s", kind, filePath)
+	}
+
+	return errs
+}
+
+// findMissingCanaryTokens returns the traps whose honeytoken embeds a canary token that was not provisioned yet.
+func findMissingCanaryTokens(deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap) []v1alpha1.Trap {
+	var missingTraps []v1alpha1.Trap
+	for _, trap := range traps {
+		if trap.TrapType() == v1alpha1.FilesystemHoneytokenTrap && !filesystoken.IsCanaryTokenProvisioned(deceptionPolicy, trap) {
+			missingTraps = append(missingTraps, trap)
+		}
+	}
+	return missingTraps
+}
+
+// findLostCanaryTokens returns the names of the secrets of the provisioned canary tokens that were deleted.
+// Secrets that are missing from the cache are looked up in the API server, since they might have just been created.
+func (r *DeceptionPolicyReconciler) findLostCanaryTokens(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) []string {
+	var lostSecretNames []string
+	for _, canaryToken := range deceptionPolicy.Status.CanaryTokens {
+		key := client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: canaryToken.SecretName}
+		if err := r.Get(ctx, key, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+			continue
+		}
+		if err := r.apiReader().Get(ctx, key, &corev1.Secret{}); apierrors.IsNotFound(err) {
+			lostSecretNames = append(lostSecretNames, canaryToken.SecretName)
+		}
+	}
+	return lostSecretNames
+}
+
+// resolveCanaryTokens resolves the canary tokens that the given traps embed (see filesystoken.ResolveCanaryToken).
+// The traps whose canary token cannot be resolved, e.g., because it was not provisioned yet, are returned as failed results instead.
+func (r *DeceptionPolicyReconciler) resolveCanaryTokens(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap) ([]v1alpha1.Trap, []trapsapi.DecoyDeploymentResult) {
+	resolvedTraps := make([]v1alpha1.Trap, 0, len(traps))
+	var unresolvedResults []trapsapi.DecoyDeploymentResult
+	for _, trap := range traps {
+		resolvedTrap, err := filesystoken.ResolveCanaryToken(r.Client, ctx, deceptionPolicy, trap)
+		if err != nil {
+			unresolvedResults = append(unresolvedResults, trapsapi.DecoyDeploymentResult{Trap: &trap, Errors: err})
+			continue
+		}
+		resolvedTraps = append(resolvedTraps, resolvedTrap)
+	}
+	return resolvedTraps, unresolvedResults
+}
+
+// recordCanaryToken records a provisioned canary token in the status of a DeceptionPolicy resource.
+// The DeceptionPolicy is fetched again, so that the expanded traps of the reconciled copy are not replaced.
+// This function retries on conflicts (to resolve parallel update attempts) and returns an error if the update fails.
+func (r *DeceptionPolicyReconciler) recordCanaryToken(ctx context.Context, req ctrl.Request, canaryToken v1alpha1.CanaryTokenStatus) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var deceptionPolicy v1alpha1.DeceptionPolicy
+		if err := r.Get(ctx, req.NamespacedName, &deceptionPolicy); err != nil {
+			return err
+		}
+		if !deceptionPolicy.Status.PutCanaryToken(canaryToken) {
+			return nil // The canary token is already recorded
+		}
+
+		return r.Status().Update(ctx, &deceptionPolicy)
+	})
+}
+
+// canaryTokenProvisioner returns the CanaryTokenProvisioner of the reconciler, or the configured canarytokens service.
+func (r *DeceptionPolicyReconciler) canaryTokenProvisioner() filesystoken.CanaryTokenProvisioner {
+	if r.CanaryTokenProvisioner != nil {
+		return r.CanaryTokenProvisioner
+	}
+	return &filesystoken.HTTPCanaryTokenProvisioner{URL: utils.GetCanaryTokensURL(), WebhookURL: utils.GetCanaryTokensWebhookURL()}
+}
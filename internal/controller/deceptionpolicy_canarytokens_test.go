@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// fakeCanaryTokenProvisioner provisions canary tokens with sequential tokens, except for the kinds that fail.
+type fakeCanaryTokenProvisioner struct {
+	failingKinds []string
+	numTokens    int
+}
+
+func (p *fakeCanaryTokenProvisioner) Provision(_ context.Context, kind, _ string) (filesystoken.CanaryToken, error) {
+	for _, failingKind := range p.failingKinds {
+		if kind == failingKind {
+			return filesystoken.CanaryToken{}, errors.New("service unavailable")
+		}
+	}
+	p.numTokens++
+	return filesystoken.CanaryToken{Token: fmt.Sprintf("token-%d", p.numTokens), Hostname: "canary.example.com"}, nil
+}
+
+var _ = Describe("Canary tokens", func() {
+	It("should find the canary tokens that were not provisioned yet", func() {
+		withCanaryToken := func(filePath, kind string) v1alpha1.Trap {
+			return v1alpha1.Trap{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filePath, CanaryToken: &v1alpha1.CanaryToken{Kind: kind}}}
+		}
+		traps := []v1alpha1.Trap{
+			withCanaryToken("/root/.aws/credentials", v1alpha1.CanaryTokenKindAWSKeys),
+			withCanaryToken("/etc/hosts.bak", v1alpha1.CanaryTokenKindDNS),
+			{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney"}},
+		}
+
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{}
+		deceptionPolicy.Status.PutCanaryToken(v1alpha1.CanaryTokenStatus{FilePath: "/root/.aws/credentials", Kind: v1alpha1.CanaryTokenKindAWSKeys, SecretName: "abc"})
+		Expect(findMissingCanaryTokens(deceptionPolicy, traps)).To(Equal([]v1alpha1.Trap{traps[1]}))
+
+		deceptionPolicy.Status.PutCanaryToken(v1alpha1.CanaryTokenStatus{FilePath: "/etc/hosts.bak", Kind: v1alpha1.CanaryTokenKindDNS, SecretName: "def"})
+		Expect(findMissingCanaryTokens(deceptionPolicy, traps)).To(BeEmpty())
+	})
+
+	Describe("provisionCanaryTokens", func() {
+		var (
+			ctx             context.Context
+			fakeClient      client.Client
+			deceptionPolicy *v1alpha1.DeceptionPolicy
+			traps           []v1alpha1.Trap
+			req             ctrl.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", UID: "uid"}}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(deceptionPolicy).WithStatusSubresource(deceptionPolicy).Build()
+			traps = []v1alpha1.Trap{
+				{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/etc/hosts.bak", CanaryToken: &v1alpha1.CanaryToken{Kind: v1alpha1.CanaryTokenKindDNS}}},
+				{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/root/.aws/credentials", CanaryToken: &v1alpha1.CanaryToken{Kind: v1alpha1.CanaryTokenKindAWSKeys}}},
+			}
+			req = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(deceptionPolicy)}
+		})
+
+		It("should record failures per file path and provision the other canary tokens", func() {
+			recorder := record.NewFakeRecorder(10)
+			provisioner := &fakeCanaryTokenProvisioner{failingKinds: []string{v1alpha1.CanaryTokenKindAWSKeys}}
+			reconciler := &DeceptionPolicyReconciler{Client: fakeClient, Recorder: recorder, CanaryTokenProvisioner: provisioner}
+
+			errs := reconciler.provisionCanaryTokens(ctx, req, deceptionPolicy, traps)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs["/root/.aws/credentials"]).To(MatchError(trapsapi.ErrCanaryTokenNotProvisioned))
+
+			// Only a reference to the secret is recorded in the status, and the event does not reveal the token
+			Expect(deceptionPolicy.Status.CanaryTokens).To(HaveLen(1))
+			canaryToken := deceptionPolicy.Status.CanaryTokens[0]
+			Expect(canaryToken.SecretName).To(Equal(filesystoken.GenerateCanaryTokenSecretName(deceptionPolicy, "/etc/hosts.bak")))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: canaryToken.SecretName}, &corev1.Secret{})).To(Succeed())
+			Expect(recorder.Events).To(Receive(Equal("Normal CanaryTokenProvisioned Canary token (dns) provisioned for honeytoken /etc/hosts.bak")))
+
+			var latest v1alpha1.DeceptionPolicy
+			Expect(fakeClient.Get(ctx, req.NamespacedName, &latest)).To(Succeed())
+			Expect(latest.Status.CanaryTokens).To(HaveLen(1))
+			Expect(latest.Status.CanaryTokens[0].SecretName).To(Equal(canaryToken.SecretName))
+
+			// The trap whose canary token failed cannot be resolved, while the other one is
+			resolvedTraps, unresolvedResults := reconciler.resolveCanaryTokens(ctx, deceptionPolicy, traps)
+			Expect(resolvedTraps).To(HaveLen(1))
+			Expect(resolvedTraps[0].FilesystemHoneytoken.FilePath).To(Equal("/etc/hosts.bak"))
+			Expect(unresolvedResults).To(HaveLen(1))
+			Expect(unresolvedResults[0].Trap.FilesystemHoneytoken.FilePath).To(Equal("/root/.aws/credentials"))
+		})
+
+		It("should provision canary tokens again whose secret was deleted", func() {
+			provisioner := &fakeCanaryTokenProvisioner{}
+			reconciler := &DeceptionPolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10), CanaryTokenProvisioner: provisioner}
+			Expect(reconciler.provisionCanaryTokens(ctx, req, deceptionPolicy, traps)).To(BeEmpty())
+			Expect(provisioner.numTokens).To(Equal(2))
+
+			Expect(reconciler.provisionCanaryTokens(ctx, req, deceptionPolicy, traps)).To(BeEmpty())
+			Expect(provisioner.numTokens).To(Equal(2))
+
+			secretName := filesystoken.GenerateCanaryTokenSecretName(deceptionPolicy, "/etc/hosts.bak")
+			Expect(fakeClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: utils.GetKoneyNamespace(), Name: secretName}})).To(Succeed())
+			Expect(reconciler.provisionCanaryTokens(ctx, req, deceptionPolicy, traps)).To(BeEmpty())
+			Expect(provisioner.numTokens).To(Equal(3))
+			Expect(deceptionPolicy.Status.CanaryTokens).To(HaveLen(2))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: secretName}, &corev1.Secret{})).To(Succeed())
+		})
+	})
+
+	It("should fall back to the configured canarytokens service", func() {
+		reconciler := &DeceptionPolicyReconciler{}
+		Expect(reconciler.canaryTokenProvisioner()).To(BeAssignableToTypeOf(&filesystoken.HTTPCanaryTokenProvisioner{}))
+	})
+})
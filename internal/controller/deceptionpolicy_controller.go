@@ -19,9 +19,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -29,6 +32,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -39,6 +43,9 @@ import (
 
 	"github.com/dynatrace-oss/koney/api/v1alpha1"
 	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/metrics"
+	"github.com/dynatrace-oss/koney/internal/controller/templates"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
 )
 
 // DeceptionPolicyReconciler reconciles a DeceptionPolicy object
@@ -48,6 +55,19 @@ type DeceptionPolicyReconciler struct {
 	Recorder  record.EventRecorder
 	Clientset kubernetes.Clientset
 	Config    rest.Config
+
+	// APIReader reads directly from the API server, bypassing the (possibly stale) cache.
+	APIReader client.Reader
+
+	// CanaryTokenProvisioner provisions the canary tokens of honeytokens, or the configured canarytokens service if nil.
+	CanaryTokenProvisioner filesystoken.CanaryTokenProvisioner
+
+	// DecoyTamperingReporter reports tampered decoys, or reports them to the alert forwarder if nil.
+	DecoyTamperingReporter filesystoken.DecoyTamperingReporter
+
+	captorResyncs      captorResyncs
+	placementRetries   filesystoken.PlacementRetries
+	decoyContentChecks decoyContentChecks
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -56,11 +76,17 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	log := k8slog.FromContext(ctx)
 	log.Info("Reconciling DeceptionPolicy ...", "DeceptionPolicy", req.NamespacedName)
 
+	start := time.Now()
+	defer func() { metrics.ObserveReconcile(time.Since(start), reconcileErr) }()
+
 	// Fetch the DeceptionPolicy instance
 	var deceptionPolicy v1alpha1.DeceptionPolicy
 	if err := r.Get(ctx, req.NamespacedName, &deceptionPolicy); err != nil {
 		if client.IgnoreNotFound(err) == nil {
 			log.Info("DeceptionPolicy already deleted - stopping reconciliation", "DeceptionPolicy", req.NamespacedName)
+			metrics.ForgetDeceptionPolicy(req.Name)
+			r.placementRetries.Forget(req.Name)
+			r.decoyContentChecks.forget(req.Name)
 			return ctrl.Result{}, nil
 		}
 
@@ -70,9 +96,16 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	// Do not reconcile if the DeceptionPolicy is marked for deletion
 	// Run the finalizers to clean-up the deployed traps instead
-	markedForDeletion, err := r.runFinalizerIfMarkedForDeletion(ctx, req, &deceptionPolicy)
+	markedForDeletion, isCleanupPending, err := r.runFinalizerIfMarkedForDeletion(ctx, req, &deceptionPolicy)
 	if markedForDeletion || err != nil {
 		if markedForDeletion {
+			metrics.ForgetDeceptionPolicy(req.Name)
+			r.placementRetries.Forget(req.Name)
+			r.decoyContentChecks.forget(req.Name)
+			if isCleanupPending && err == nil {
+				log.Info("DeceptionPolicy marked for deletion - waiting for traps to be removed", "DeceptionPolicy", req.NamespacedName)
+				return ctrl.Result{RequeueAfter: constants.ShortStatusCheckInterval}, nil
+			}
 			if client.IgnoreNotFound(err) == nil {
 				log.Info("Finalizer already removed - stopping reconciliation", "DeceptionPolicy", req.NamespacedName)
 				return ctrl.Result{}, nil
@@ -84,6 +117,16 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
+	// Do not touch anything while the DeceptionPolicy is paused, until the paused annotation is removed again
+	if deceptionPolicy.IsPaused() {
+		log.Info("DeceptionPolicy is paused - stopping reconciliation", "DeceptionPolicy", req.NamespacedName)
+		if err := r.updatePausedCondition(ctx, req, &deceptionPolicy); err != nil {
+			log.Error(err, "Paused condition cannot be set", "DeceptionPolicy", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	missingFinalizer, err := r.putFinalizer(ctx, req, &deceptionPolicy)
 	if missingFinalizer || err != nil {
 		// We can safely return even if err == nil, another reconciliation request will come,
@@ -97,6 +140,24 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
+	// Apply the trap defaults in memory only, unless the defaulting webhook already did (e.g., because it is disabled),
+	// writing them back would bump the generation on every reconcile and fight with GitOps tools that own the spec
+	deceptionPolicy.ApplyDefaults()
+
+	// Expand the traps that reference a TrapTemplate (in memory only), traps that cannot be expanded are reported as invalid below,
+	// but if a TrapTemplate is unavailable, we must not treat its traps as removed and clean up their decoys and captors
+	trapTemplates, templateErr := templates.ExpandTrapTemplates(r, ctx, &deceptionPolicy)
+	if errors.Is(templateErr, templates.ErrTrapTemplateUnavailable) {
+		log.Error(templateErr, "TrapTemplates cannot be fetched - will retry", "DeceptionPolicy", req.NamespacedName)
+		return ctrl.Result{}, templateErr
+	} else if templateErr != nil {
+		log.Error(templateErr, "Some TrapTemplates cannot be expanded", "DeceptionPolicy", req.NamespacedName)
+	}
+
+	// In a dry run, we only plan where traps would be placed, but we do not touch any resources
+	isDryRun := deceptionPolicy.IsDryRun()
+	var plan *v1alpha1.DeceptionPolicyPlan
+
 	// Status conditions that are going to be set during the reconciliation
 	resourceFoundCondition := v1alpha1.DeceptionPolicyCondition{
 		Type:               ResourceFoundType,
@@ -130,34 +191,114 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		Message:            "",
 	}
 
+	// The generation that this reconciliation is based on (the status updates below re-fetch the DeceptionPolicy)
+	observedGeneration := deceptionPolicy.Generation
+
+	// Generations of rotating honeytokens that are going to be recorded in the status
+	var honeytokenRotations []v1alpha1.HoneytokenRotation
+
+	// Results of deploying decoys and captors, which are reported per trap in the status
+	var decoyResult, captorResult TrapReconcileResult
+	now := time.Now()
+
+	// If Tetragon was reinstalled, all captors must be recreated, and the progress is reported in an extra condition
+	resyncState, isCaptorResync := r.captorResyncs.get(deceptionPolicy.Name)
+	var captorsResyncedCondition *v1alpha1.DeceptionPolicyCondition
+
+	// Decoys that drifted since they were deployed, which are reported per trap in the status
+	var drifts map[string][]v1alpha1.TrapDrift
+	var decoysVerifiedCondition *v1alpha1.DeceptionPolicyCondition
+
+	// Whether the backends of the captor strategies (e.g., Tetragon) are installed and healthy
+	var captorBackendAvailableCondition *v1alpha1.DeceptionPolicyCondition
+
 	defer func() {
-		// Eventually, update status conditions
-		err := r.updateStatusConditions(ctx, req, &deceptionPolicy, []v1alpha1.DeceptionPolicyCondition{
+		// The status updates below re-fetch the DeceptionPolicy, so the trap statuses are built from the expanded traps of this copy
+		expandedPolicy := deceptionPolicy.DeepCopy()
+
+		// Eventually, update status conditions (the summary conditions are derived from the others)
+		trapsDeployedCondition, degradedCondition := summarizeStatusConditions(policyValidCondition, decoysDeployedCondition, captorsDeployedCondition)
+		conditions := []v1alpha1.DeceptionPolicyCondition{
 			resourceFoundCondition,
 			policyValidCondition,
 			decoysDeployedCondition,
 			captorsDeployedCondition,
-		})
+			trapsDeployedCondition,
+			degradedCondition,
+		}
+		if captorsResyncedCondition != nil {
+			conditions = append(conditions, *captorsResyncedCondition)
+		}
+		if decoysVerifiedCondition != nil {
+			conditions = append(conditions, *decoysVerifiedCondition)
+		}
+		if captorBackendAvailableCondition != nil {
+			conditions = append(conditions, *captorBackendAvailableCondition)
+		}
+		if isDryRun || deceptionPolicy.Status.ContainsCondition(DryRunType) {
+			conditions = append(conditions, buildDryRunCondition(isDryRun, plan))
+		}
+		if deceptionPolicy.Status.ContainsCondition(PausedType) {
+			conditions = append(conditions, buildPausedCondition(false))
+		}
+		err := r.updateStatusConditions(ctx, req, &deceptionPolicy, observedGeneration, conditions)
 		if err != nil {
 			log.Error(err, "Status conditions cannot be set", "DeceptionPolicy", req.NamespacedName)
 			reconcileErr = errors.Join(reconcileErr, err)
 		}
+
+		if !isDryRun || plan != nil {
+			if err := r.updatePlan(ctx, req, &deceptionPolicy, plan); err != nil {
+				log.Error(err, "Dry run plan cannot be set", "DeceptionPolicy", req.NamespacedName)
+				reconcileErr = errors.Join(reconcileErr, err)
+			}
+		}
+
+		if err := r.updateHoneytokenRotations(ctx, req, &deceptionPolicy, honeytokenRotations); err != nil {
+			log.Error(err, "Honeytoken rotations cannot be recorded", "DeceptionPolicy", req.NamespacedName)
+			reconcileErr = errors.Join(reconcileErr, err)
+		}
+
+		if err := r.updateTrapTemplates(ctx, req, &deceptionPolicy, trapTemplates); err != nil {
+			log.Error(err, "TrapTemplate generations cannot be recorded", "DeceptionPolicy", req.NamespacedName)
+			reconcileErr = errors.Join(reconcileErr, err)
+		}
+
+		// The trap statuses are updated on a copy, so that the expanded traps remain available for the metrics
+		trapStatuses, err := r.updateTrapStatuses(ctx, req, expandedPolicy.DeepCopy(), decoyResult, captorResult, drifts, now)
+		if err != nil {
+			log.Error(err, "Trap statuses cannot be set", "DeceptionPolicy", req.NamespacedName)
+			reconcileErr = errors.Join(reconcileErr, err)
+		} else {
+			recordDeceptionPolicyMetrics(expandedPolicy, trapStatuses, decoyResult, captorResult)
+		}
 	}()
 
+	// Rotating honeytokens are rendered for the generation that is active right now,
+	// and traps that expired by now are treated as if they were removed from the DeceptionPolicy
+	unexpiredTraps := r.filterUnexpiredTraps(ctx, &deceptionPolicy, now)
+	numTrapsExpired := len(deceptionPolicy.Spec.Traps) - len(unexpiredTraps)
+
 	// If some traps were removed from the DeceptionPolicy, remove the related deployed decoys and captors
-	if err := r.cleanupRemovedTraps(ctx, &deceptionPolicy); err != nil {
+	// (this also removes expired traps and decoys of rotating honeytokens that belong to a past generation)
+	if isDryRun {
+		log.Info("DeceptionPolicy is a dry run - skipping clean-up of removed traps", "DeceptionPolicy", req.NamespacedName)
+	} else if err := r.cleanupRemovedTraps(ctx, &deceptionPolicy, unexpiredTraps, now); err != nil {
 		log.Error(err, "Clean-up of traps that were removed failed", "DeceptionPolicy", req.NamespacedName)
 		reconcileErr = errors.Join(reconcileErr, err)
 		return ctrl.Result{}, reconcileErr
 	}
 
-	validTraps := r.filterValidTraps(ctx, &deceptionPolicy)
-	numTraps := len(deceptionPolicy.Spec.Traps)
+	validTraps := r.filterValidTraps(ctx, unexpiredTraps)
+	numTraps := len(unexpiredTraps)
 	numTrapsValid := len(validTraps)
-	numTrapsInvalid := len(deceptionPolicy.Spec.Traps) - len(validTraps)
+	numTrapsInvalid := len(unexpiredTraps) - len(validTraps)
 
 	if numTraps > 0 {
 		policyValidCondition.Message = fmt.Sprintf("%d/%d traps are valid", len(validTraps), numTraps)
+		if numTrapsExpired > 0 {
+			policyValidCondition.Message += fmt.Sprintf(" (%d expired)", numTrapsExpired)
+		}
 		if numTrapsInvalid > 0 {
 			policyValidCondition.Status = metav1.ConditionFalse
 			policyValidCondition.Reason = PolicyValidReason_Invalid
@@ -165,6 +306,33 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			policyValidCondition.Status = metav1.ConditionTrue
 			policyValidCondition.Reason = PolicyValidReason_Valid
 		}
+	} else if numTrapsExpired > 0 {
+		policyValidCondition.Status = metav1.ConditionTrue
+		policyValidCondition.Reason = PolicyValidReason_Expired
+		policyValidCondition.Message = fmt.Sprintf("All %d traps expired", numTrapsExpired)
+	}
+
+	// Check that the captors of the valid traps will be enforced by their backends, which also helps to plan a dry run
+	backendProblems, backendErr := r.checkCaptorBackends(ctx, validTraps)
+	backendCondition := buildCaptorBackendAvailableCondition(backendProblems, backendErr)
+	captorBackendAvailableCondition = &backendCondition
+	if backendErr != nil {
+		log.Error(backendErr, "Backends of the captors cannot be checked", "DeceptionPolicy", req.NamespacedName)
+	} else if len(backendProblems) > 0 {
+		log.Info("Some captors will not be enforced - their backends are unavailable", "DeceptionPolicy", req.NamespacedName, "problems", backendProblems)
+	}
+
+	// Self-test the detection chain periodically from a dedicated test pod, unless nothing is deployed in a dry run
+	if err := r.reconcileSelfTest(ctx, &deceptionPolicy, deceptionPolicy.Spec.SelfTest != nil && !isDryRun); err != nil {
+		log.Error(err, "Self-test CronJob cannot be reconciled", "DeceptionPolicy", req.NamespacedName)
+		reconcileErr = errors.Join(reconcileErr, err)
+	}
+
+	// In a dry run, only publish where the valid traps would be placed (strict validation would not place any trap)
+	if isDryRun {
+		plan = r.buildPlan(ctx, &deceptionPolicy, numTrapsInvalid == 0 || !*deceptionPolicy.Spec.StrictValidation, now)
+		log.Info("DeceptionPolicy is a dry run - published the plan instead of deploying traps", "DeceptionPolicy", req.NamespacedName)
+		return ctrl.Result{}, reconcileErr
 	}
 
 	// Check if strict validation is enabled and we possibly need to stop the reconciliation
@@ -177,44 +345,136 @@ func (r *DeceptionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		}
 	}
 
-	decoyResult := r.reconcileDecoys(ctx, &deceptionPolicy, validTraps)
+	// Canary tokens are provisioned before any decoy is deployed, since the content of the decoys embeds them.
+	// The decoys of the traps whose canary token cannot be provisioned fail below, while the other traps are deployed.
+	canaryTokenErrs := r.provisionCanaryTokens(ctx, req, &deceptionPolicy, validTraps)
+	for filePath, err := range canaryTokenErrs {
+		log.Error(err, "Canary token cannot be provisioned", "DeceptionPolicy", req.NamespacedName, "filePath", filePath)
+	}
+
+	// Verify that the decoys that were deployed before are still in place and were not tampered with,
+	// and forget the decoys that drifted, so that they are deployed again below
+	drifts, verifyErr := r.verifyDecoys(ctx, &deceptionPolicy, validTraps, now)
+	verifiedCondition := buildDecoysVerifiedCondition(drifts, verifyErr)
+	decoysVerifiedCondition = &verifiedCondition
+	if verifyErr != nil {
+		log.Error(verifyErr, "Verification of deployed decoys failed", "DeceptionPolicy", req.NamespacedName)
+		reconcileErr = errors.Join(reconcileErr, verifyErr)
+	} else if len(drifts) > 0 {
+		log.Info("Some decoys drifted since they were deployed - will deploy them again", "DeceptionPolicy", req.NamespacedName, "traps", len(drifts))
+	}
+
+	// If the spec changed since the last reconciliation, only deploy the traps that were added or changed (the delta),
+	// and skip the traps that were already deployed successfully for a previous generation (removed traps were cleaned up above),
+	// unless decoys drifted, which might belong to any trap (a changed TrapTemplate changes the traps that were expanded from it)
+	reconcileTraps := validTraps
+	isPartialReconciliation := (isSpecChanged(&deceptionPolicy) || isTrapTemplateChanged(&deceptionPolicy, trapTemplates)) && !isCaptorResync && len(drifts) == 0
+	if isPartialReconciliation {
+		trapDiff := diffTraps(deceptionPolicy.Status.Traps, deceptionPolicy.Spec.Traps)
+		reconcileTraps = trapDiff.Delta(validTraps)
+		log.Info("DeceptionPolicy changed - deploying only traps that were added or changed", "DeceptionPolicy", req.NamespacedName,
+			"added", len(trapDiff.Added), "changed", len(trapDiff.Changed), "removed", len(trapDiff.RemovedHashes),
+			"pending", len(trapDiff.Pending), "unchanged", len(trapDiff.Unchanged))
+	}
+
+	// Decoys are deployed per file path and with the content of the current generation,
+	// while captors cover all file paths of a trap at once and do not depend on the content
+	expandedTraps := filesystoken.ExpandFilePaths(validTraps)
+	for _, trap := range expandedTraps {
+		if filesystoken.IsRotating(trap) {
+			honeytokenRotations = append(honeytokenRotations, filesystoken.BuildHoneytokenRotation(&deceptionPolicy, trap, now))
+		}
+	}
+
+	// Unchanged traps are counted as successes, since they were deployed successfully before
+	expandedReconcileTraps := filesystoken.ExpandFilePaths(reconcileTraps)
+	resolvedTraps, unresolvedResults := r.resolveCanaryTokens(ctx, &deceptionPolicy, expandedReconcileTraps)
+	decoyResult = r.reconcileDecoys(ctx, &deceptionPolicy, filesystoken.ResolveRotatedTraps(&deceptionPolicy, resolvedTraps, now))
+	for _, result := range unresolvedResults {
+		// The failed trap must match the decoys of the current generation when the status is built
+		rotatedTrap := filesystoken.ResolveRotatedTrap(&deceptionPolicy, *result.Trap, now)
+		if err, ok := canaryTokenErrs[rotatedTrap.FilesystemHoneytoken.FilePath]; ok {
+			result.Errors = err
+		}
+		result.Trap = &rotatedTrap
+		decoyResult.addFailure(result)
+	}
+	decoyResult.addUnchanged(len(expandedTraps) - len(expandedReconcileTraps))
 	translateReconcileResultToStatusCondition(&decoyResult, &decoysDeployedCondition, DecoyDeployedStatusConditions)
 
-	captorResult := r.reconcileCaptors(ctx, &deceptionPolicy, validTraps)
+	// Captors are named after the position of their trap, so they are reconciled for all traps (even in a partial reconciliation),
+	// which updates captors in place whose trap changed or moved, and is cheap for captors that are up-to-date
+	captorResult = r.reconcileCaptors(ctx, &deceptionPolicy, validTraps, isCaptorResync)
 	translateReconcileResultToStatusCondition(&captorResult, &captorsDeployedCondition, CaptorDeployedStatusConditions)
 
-	// We might encounter resources that are not ready yet, so we should retry later
-	shouldRequeue := decoyResult.ShouldRequeue || captorResult.ShouldRequeue
+	if isCaptorResync {
+		condition, resynced := buildCaptorsResyncedCondition(resyncState, captorResult)
+		captorsResyncedCondition = &condition
+		if resynced {
+			r.captorResyncs.done(deceptionPolicy.Name, resyncState)
+			log.Info("Captors recreated after Tetragon was reinstalled", "DeceptionPolicy", req.NamespacedName)
+		}
+	}
+
+	// We might encounter resources that are not ready yet, so we should retry later,
+	// and decoys that were deployed again after they drifted should be verified again soon
+	shouldRequeue := decoyResult.ShouldRequeue || captorResult.ShouldRequeue || len(drifts) > 0
+
+	// Rotating honeytokens need another reconciliation when their current generation expires,
+	// and traps with an expiration time need another reconciliation to remove them
+	nextRotation, isRotating := filesystoken.NextRotation(&deceptionPolicy, validTraps, now)
+	if nextExpiration, isExpiring := nextTrapExpiration(&deceptionPolicy, validTraps, now); isExpiring {
+		if !isRotating || nextExpiration.Before(nextRotation) {
+			nextRotation, isRotating = nextExpiration, true
+		}
+	}
+
+	// Deployed decoys are verified periodically, so that decoys that drifted are deployed again
+	nextVerification := now.Add(constants.DecoyVerificationInterval)
 
 	reconcileErr = errors.Join(reconcileErr, decoyResult.Errors, captorResult.Errors)
 	if reconcileErr != nil {
 		// If we couldn't deploy all the traps, requeue after a minute to avoid infinite loops
 		log.Error(reconcileErr, "Reconciliation failed - check previous logs", "DeceptionPolicy", req.NamespacedName)
-		return ctrl.Result{RequeueAfter: constants.NormalFailureRetryInterval}, err
+		return ctrl.Result{RequeueAfter: constants.NormalFailureRetryInterval}, reconcileErr
 	} else if shouldRequeue {
 		// If we encountered resources that are not yet ready for traps, check status again shortly
 		log.Info("Reconciliation successful, but some resources are not ready yet - will retry soon", "DeceptionPolicy", req.NamespacedName)
 		return ctrl.Result{RequeueAfter: constants.ShortStatusCheckInterval}, nil
+	} else if isPartialReconciliation && (!isRotating || time.Until(nextRotation) > constants.PartialReconciliationResyncInterval) {
+		// Resources that appeared while only the delta was deployed might still miss unchanged traps, so reconcile all traps soon
+		log.Info("Partial reconciliation successful - will reconcile all traps soon", "DeceptionPolicy", req.NamespacedName)
+		return ctrl.Result{RequeueAfter: constants.PartialReconciliationResyncInterval}, nil
+	} else if isRotating && nextRotation.Before(nextVerification) {
+		log.Info("Reconciliation successful - will rotate or expire traps next", "DeceptionPolicy", req.NamespacedName, "nextUpdate", nextRotation)
+		return ctrl.Result{RequeueAfter: time.Until(nextRotation)}, nil
 	}
 
-	log.Info("Reconciliation successful", "DeceptionPolicy", req.NamespacedName)
-	return ctrl.Result{}, reconcileErr
+	log.Info("Reconciliation successful - will verify decoys next", "DeceptionPolicy", req.NamespacedName, "nextUpdate", nextVerification)
+	return ctrl.Result{RequeueAfter: time.Until(nextVerification)}, reconcileErr
 }
 
-func (r *DeceptionPolicyReconciler) runFinalizerIfMarkedForDeletion(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy) (bool, error) {
+// runFinalizerIfMarkedForDeletion cleans up the deployed traps if the DeceptionPolicy is marked for deletion,
+// and removes the finalizer once all traps were removed. The second boolean return value indicates
+// if the removal of some traps is still in progress, so that the finalizer was not removed yet.
+func (r *DeceptionPolicyReconciler) runFinalizerIfMarkedForDeletion(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy) (bool, bool, error) {
 	log := k8slog.FromContext(ctx)
 
 	markedForDeletion := deceptionPolicy.GetDeletionTimestamp() != nil
 	if markedForDeletion {
 		if controllerutil.ContainsFinalizer(deceptionPolicy, constants.FinalizerName) {
 			// Run the finalizer to clean-up the deployed traps
-			if err := r.cleanupDeceptionPolicy(ctx, deceptionPolicy); err != nil {
+			isPending, err := r.cleanupDeceptionPolicy(ctx, deceptionPolicy)
+			if err != nil {
 				log.Error(err, "Finalizer failed to clean-up traps", "DeceptionPolicy", req.NamespacedName)
-				return markedForDeletion, err
+				return markedForDeletion, isPending, err
+			}
+			if isPending {
+				return markedForDeletion, isPending, nil
 			}
 
 			// Remove the finalizer after the clean-up was successful
-			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 				if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
 					return err
 				}
@@ -225,12 +485,12 @@ func (r *DeceptionPolicyReconciler) runFinalizerIfMarkedForDeletion(ctx context.
 				return r.Update(ctx, deceptionPolicy)
 			})
 			if err != nil {
-				return markedForDeletion, err
+				return markedForDeletion, false, err
 			}
 		}
 	}
 
-	return markedForDeletion, nil
+	return markedForDeletion, false, nil
 }
 
 func (r *DeceptionPolicyReconciler) putFinalizer(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy) (bool, error) {
@@ -255,11 +515,48 @@ func (r *DeceptionPolicyReconciler) putFinalizer(ctx context.Context, req ctrl.R
 	return missingFinalizer, nil
 }
 
-func (r *DeceptionPolicyReconciler) filterValidTraps(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) []v1alpha1.Trap {
+func (r *DeceptionPolicyReconciler) filterUnexpiredTraps(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, now time.Time) []v1alpha1.Trap {
 	log := k8slog.FromContext(ctx)
 
-	validTraps := make([]v1alpha1.Trap, 0)
+	unexpiredTraps := make([]v1alpha1.Trap, 0, len(deceptionPolicy.Spec.Traps))
 	for _, trap := range deceptionPolicy.Spec.Traps {
+		if trap.IsExpired(deceptionPolicy.Status.TrapDeployedAt(hashTrap(trap)), now) {
+			log.Info("Trap expired - will be removed", "trap", trap)
+			continue
+		}
+		unexpiredTraps = append(unexpiredTraps, trap)
+	}
+
+	return unexpiredTraps
+}
+
+// nextTrapExpiration returns the earliest time at which one of the traps expires.
+// Traps that were not deployed yet are assumed to be deployed now, since their TTL starts with this reconciliation.
+// The second return value is false if none of the traps expires.
+func nextTrapExpiration(deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap, now time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, trap := range traps {
+		deployedAt := deceptionPolicy.Status.TrapDeployedAt(hashTrap(trap))
+		if deployedAt == nil {
+			deployedAt = &metav1.Time{Time: now}
+		}
+		if expiresAt, expires := trap.ExpirationTime(deployedAt); expires {
+			if !found || expiresAt.Before(next) {
+				next = expiresAt
+				found = true
+			}
+		}
+	}
+
+	return next, found
+}
+
+func (r *DeceptionPolicyReconciler) filterValidTraps(ctx context.Context, traps []v1alpha1.Trap) []v1alpha1.Trap {
+	log := k8slog.FromContext(ctx)
+
+	validTraps := make([]v1alpha1.Trap, 0)
+	for _, trap := range traps {
 		if err := trap.IsValid(); err == nil {
 			for _, resourceFilter := range trap.MatchResources.Any {
 				if resourceFilter.ContainerSelector == "*" {
@@ -275,6 +572,18 @@ func (r *DeceptionPolicyReconciler) filterValidTraps(ctx context.Context, decept
 	return validTraps
 }
 
+// isSpecChanged returns true if the spec of the DeceptionPolicy changed since it was last reconciled.
+// The first reconciliation of a DeceptionPolicy does not count as a change.
+func isSpecChanged(deceptionPolicy *v1alpha1.DeceptionPolicy) bool {
+	return deceptionPolicy.Status.ObservedGeneration != 0 && deceptionPolicy.Generation != deceptionPolicy.Status.ObservedGeneration
+}
+
+// isTrapTemplateChanged returns true if the TrapTemplates that the traps of the DeceptionPolicy were expanded from changed
+// since it was last reconciled, e.g., because a template was updated.
+func isTrapTemplateChanged(deceptionPolicy *v1alpha1.DeceptionPolicy, trapTemplates []v1alpha1.TrapTemplateStatus) bool {
+	return deceptionPolicy.Status.ObservedGeneration != 0 && !slices.Equal(deceptionPolicy.Status.TrapTemplates, trapTemplates)
+}
+
 func translateReconcileResultToStatusCondition(result *TrapReconcileResult, condition *v1alpha1.DeceptionPolicyCondition, fields TrapDeploymentStatusEnum) {
 	if result.NumTraps > 0 {
 		condition.Message = fmt.Sprintf("%d/%d %s deployed (%d skipped)", result.NumSuccesses, result.NumTries(), fields.ObjectName, result.NumSkipped())
@@ -282,6 +591,10 @@ func translateReconcileResultToStatusCondition(result *TrapReconcileResult, cond
 		if result.NumFailures > 0 || result.Errors != nil {
 			condition.Status = metav1.ConditionFalse
 			condition.Reason = fields.Reasons.Error
+			// Known errors tell the cause right away, e.g., that a container has no shell
+			if reason := result.ErrorReason(); reason != "" {
+				condition.Reason = reason
+			}
 		} else if result.NumTries() == 0 {
 			condition.Status = metav1.ConditionFalse
 			condition.Reason = fields.Reasons.NoObjects
@@ -304,47 +617,84 @@ func translateReconcileResultToStatusCondition(result *TrapReconcileResult, cond
 	}
 }
 
+// apiReader returns the reader that bypasses the cache, or the client if it is not set (e.g., in tests).
+func (r *DeceptionPolicyReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DeceptionPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Clientset = *kubernetes.NewForConfigOrDie(mgr.GetConfig())
 	r.Config = *mgr.GetConfig()
+	r.APIReader = mgr.GetAPIReader()
 
 	watchHandler := handler.EnqueueRequestsFromMapFunc(
 		func(ctx context.Context, obj client.Object) []reconcile.Request {
 			return HandleWatchEvent(r, ctx, obj)
 		})
 
+	// Pods are mapped to the deception policies that deploy decoys into them, so that new or restarted pods get their decoys right away
+	podHandler := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return HandlePodEvent(r, ctx, obj)
+		})
+
+	// TrapTemplates are mapped to the deception policies that reference them, so that template changes are rolled out
+	trapTemplateHandler := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return HandleTrapTemplateEvent(r, ctx, obj)
+		})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.DeceptionPolicy{}).
 		Named("deceptionpolicy").
-		Watches(&corev1.Pod{}, watchHandler).
+		Watches(&corev1.Pod{}, podHandler).
 		Watches(&appsv1.Deployment{}, watchHandler).
+		Watches(&v1alpha1.TrapTemplate{}, trapTemplateHandler).
+		Watches(&apiextensionsv1.CustomResourceDefinition{}, r.tracingPolicyCRDHandler(time.Now()),
+			builder.OnlyMetadata, builder.WithPredicates(predicate.NewPredicateFuncs(isTracingPolicyCRD))).
 		WithEventFilter(predicate.Funcs{
 			GenericFunc: func(e event.GenericEvent) bool { return false },
 			CreateFunc:  func(e event.CreateEvent) bool { return true },
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				switch e.ObjectNew.(type) {
 				case *corev1.Pod:
+					// For pods, consider label changes and containers that became ready or restarted
+					// - Label changes could affect what is matched by the deception policies
+					// - Containers that became ready can receive decoys, and restarted containers lost the decoys written into them
+					// (generation changes are ignored, since Koney itself adds ephemeral containers to pods)
+					return predicate.LabelChangedPredicate{}.Update(e) || containersBecameReady(e.ObjectOld.(*corev1.Pod), e.ObjectNew.(*corev1.Pod))
 				case *appsv1.Deployment:
-					// For pods and deployments, consider generation changes and label changes
+					// For deployments, consider generation changes and label changes
 					// - Generation changes means spec changes, e.g., new container images that need new decoys
 					// - Label changes could affect what is matched by the deception policies
 					return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}).Update(e)
 				case *v1alpha1.DeceptionPolicy:
-					// For deception policies, only consider generation changes
-					// (skips update on status, metadata, labels, etc.)
+					// For deception policies, only consider generation changes and toggling the dry run or paused annotations
+					// (skips update on status, other metadata, labels, etc.)
+					oldPolicy, newPolicy := e.ObjectOld.(*v1alpha1.DeceptionPolicy), e.ObjectNew.(*v1alpha1.DeceptionPolicy)
+					return predicate.GenerationChangedPredicate{}.Update(e) ||
+						oldPolicy.IsDryRun() != newPolicy.IsDryRun() || oldPolicy.IsPaused() != newPolicy.IsPaused()
+				case *v1alpha1.TrapTemplate:
+					// For trap templates, only consider generation changes, i.e., changes of the template itself
 					return predicate.GenerationChangedPredicate{}.Update(e)
 				}
 				return false
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				switch e.Object.(type) {
-				case *corev1.Pod:
-				case *appsv1.Deployment:
+				case *corev1.Pod, *appsv1.Deployment:
 					// The controller must not change anything when pods or deployments are deleted,
 					// only the status conditions will be incorrect until the next periodic reconciliation
 					return false
-				case *v1alpha1.DeceptionPolicy:
+				case *v1alpha1.DeceptionPolicy, *v1alpha1.TrapTemplate:
+					// Traps that reference a deleted TrapTemplate cannot be expanded anymore, so their decoys are removed
+					return true
+				case *metav1.PartialObjectMetadata:
+					// The CRD of Tetragon tracing policies was deleted (the only metadata-only watch)
 					return true
 				}
 				return false
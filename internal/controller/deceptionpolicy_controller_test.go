@@ -86,6 +86,39 @@ var _ = Describe("DeceptionPolicy Controller", func() {
 			Expect(condition.Reason).To(Equal(ResourceFoundReason_Found))
 			Expect(condition.Message).To(Equal(ResourceFoundMessage_Found))
 			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			By("Checking that the status was observed for the current generation")
+			Expect(deceptionPolicy.Status.ObservedGeneration).To(Equal(deceptionPolicy.Generation))
+			condition = deceptionPolicy.Status.GetCondition(DegradedType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.ObservedGeneration).To(Equal(deceptionPolicy.Generation))
+		})
+	})
+
+	Context("When summarizing status conditions", func() {
+		condition := func(conditionType string, status metav1.ConditionStatus, reason, message string) v1alpha1.DeceptionPolicyCondition {
+			return v1alpha1.DeceptionPolicyCondition{Type: conditionType, Status: status, Reason: reason, Message: message}
+		}
+
+		It("should report deployed traps when decoys and captors are deployed", func() {
+			trapsDeployed, degraded := summarizeStatusConditions(
+				condition(PolicyValidType, metav1.ConditionTrue, PolicyValidReason_Valid, "1/1 traps are valid"),
+				condition(DecoysDeployedType, metav1.ConditionTrue, DecoysDeployedReason_Success, "1/1 decoys deployed (0 skipped)"),
+				condition(CaptorsDeployedType, metav1.ConditionTrue, CaptorsDeployedReason_Success, "1/1 captors deployed (0 skipped)"),
+			)
+			Expect(trapsDeployed.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should report degradation when captors cannot be deployed", func() {
+			trapsDeployed, degraded := summarizeStatusConditions(
+				condition(PolicyValidType, metav1.ConditionTrue, PolicyValidReason_Valid, "1/1 traps are valid"),
+				condition(DecoysDeployedType, metav1.ConditionTrue, DecoysDeployedReason_Success, "1/1 decoys deployed (0 skipped)"),
+				condition(CaptorsDeployedType, metav1.ConditionFalse, CaptorsDeployedReason_MissingTetragon, CaptorsDeployedMessage_MissingTetragon),
+			)
+			Expect(trapsDeployed.Status).To(Equal(metav1.ConditionFalse))
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Message).To(Equal(CaptorsDeployedMessage_MissingTetragon))
 		})
 	})
 
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/matching"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// buildPlan computes where the traps of a DeceptionPolicy would be placed, without deploying, changing, or removing anything.
+// If deployable is false (e.g., because strict validation failed), no trap would be placed, and only errors are reported.
+func (r *DeceptionPolicyReconciler) buildPlan(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, deployable bool, now time.Time) *v1alpha1.DeceptionPolicyPlan {
+	// Like when deploying decoys, avoid resources created before the policy if we aren't allowed to mutate them
+	var filterCreatedAfter metav1.Time
+	if !*deceptionPolicy.Spec.MutateExisting {
+		filterCreatedAfter = deceptionPolicy.CreationTimestamp
+	}
+
+	plan := &v1alpha1.DeceptionPolicyPlan{ObservedGeneration: deceptionPolicy.Generation}
+	for index, trap := range deceptionPolicy.Spec.Traps {
+		trapPlan := v1alpha1.TrapPlan{Index: index, TrapType: trap.TrapType()}
+
+		if trap.IsExpired(deceptionPolicy.Status.TrapDeployedAt(hashTrap(trap)), now) {
+			trapPlan.Expired = true
+			plan.Traps = append(plan.Traps, trapPlan)
+			continue
+		}
+		if err := trap.IsValid(); err != nil {
+			trapPlan.Error = err.Error()
+			plan.Traps = append(plan.Traps, trapPlan)
+			continue
+		}
+		if !deployable {
+			plan.Traps = append(plan.Traps, trapPlan)
+			continue
+		}
+
+		if captorPolicyName, err := filesystoken.GenerateCaptorPolicyName(deceptionPolicy, trap); err == nil {
+			trapPlan.CaptorPolicyName = captorPolicyName
+		}
+
+		// Decoys are placed per file path, so we match resources for each of them
+		for _, decoyTrap := range filesystoken.ExpandFilePaths([]v1alpha1.Trap{trap}) {
+			decoyTrap = filesystoken.ResolveDecoyStrategy(ctx, r.Client, decoyTrap)
+			matchingResult, err := matching.GetDeployableObjectsWithContainers(r, ctx, decoyTrap, &filterCreatedAfter)
+			if err != nil {
+				trapPlan.Error = err.Error()
+				break
+			}
+			for resource, containers := range matchingResult.DeployableObjects {
+				trapPlan.Placements = addPlannedPlacement(trapPlan.Placements, resource, containers, decoyTrap.FilesystemHoneytoken.FilePath)
+			}
+		}
+
+		// The order of matched resources is random, but the plan should only change if the placements change
+		slices.SortFunc(trapPlan.Placements, func(a, b v1alpha1.TrapPlacement) int {
+			return strings.Compare(a.Kind+"/"+a.Namespace+"/"+a.Name, b.Kind+"/"+b.Namespace+"/"+b.Name)
+		})
+		plan.Traps = append(plan.Traps, trapPlan)
+	}
+
+	return plan
+}
+
+// addPlannedPlacement adds the containers and the file path of a resource to the placements,
+// merging them with an existing placement of the same resource.
+func addPlannedPlacement(placements []v1alpha1.TrapPlacement, resource client.Object, containers []string, filePath string) []v1alpha1.TrapPlacement {
+	index := slices.IndexFunc(placements, func(placement v1alpha1.TrapPlacement) bool {
+		return placement.Kind == utils.ResourceKind(resource) && placement.Namespace == resource.GetNamespace() && placement.Name == resource.GetName()
+	})
+	if index < 0 {
+		placements = append(placements, v1alpha1.TrapPlacement{Kind: utils.ResourceKind(resource), Namespace: resource.GetNamespace(), Name: resource.GetName()})
+		index = len(placements) - 1
+	}
+
+	placement := &placements[index]
+	for _, container := range containers {
+		if !slices.Contains(placement.Containers, container) {
+			placement.Containers = append(placement.Containers, container)
+		}
+	}
+	slices.Sort(placement.Containers)
+	if filePath != "" && !slices.Contains(placement.FilePaths, filePath) {
+		placement.FilePaths = append(placement.FilePaths, filePath)
+	}
+
+	return placements
+}
+
+// buildDryRunCondition returns the status condition that reports whether the DeceptionPolicy is a dry run,
+// and summarizes the plan. If the policy is not a dry run, the plan is ignored.
+func buildDryRunCondition(isDryRun bool, plan *v1alpha1.DeceptionPolicyPlan) v1alpha1.DeceptionPolicyCondition {
+	condition := v1alpha1.DeceptionPolicyCondition{
+		Type:               DryRunType,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             DryRunReason_Disabled,
+		Message:            DryRunMessage_Disabled,
+	}
+	if !isDryRun {
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = DryRunReason_Enabled
+	condition.Message = DryRunMessage_Pending
+	if plan == nil {
+		return condition
+	}
+
+	numResources, numContainers := 0, 0
+	for _, trapPlan := range plan.Traps {
+		numResources += len(trapPlan.Placements)
+		for _, placement := range trapPlan.Placements {
+			numContainers += len(placement.Containers)
+		}
+	}
+	condition.Message = fmt.Sprintf("Traps would be placed in %d containers of %d resources - see the plan in the status", numContainers, numResources)
+	return condition
+}
+
+// updatePlan sets the dry run plan in the status of a DeceptionPolicy resource, or removes it if the plan is nil.
+// If nothing changes, no update is performed. This function retries on conflicts and returns an error if the update fails.
+func (r *DeceptionPolicyReconciler) updatePlan(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy, plan *v1alpha1.DeceptionPolicyPlan) error {
+	if plan == nil && deceptionPolicy.Status.Plan == nil {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
+			return err
+		}
+
+		if dirty := deceptionPolicy.Status.SetPlan(plan.DeepCopy()); !dirty {
+			return nil // The plan already has its desired value
+		}
+
+		return r.Status().Update(ctx, deceptionPolicy)
+	})
+}
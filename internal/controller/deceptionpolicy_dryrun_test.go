@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("addPlannedPlacement", func() {
+	It("should merge the containers and file paths of the same resource", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"}}
+		otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "other"}}
+
+		placements := addPlannedPlacement(nil, pod, []string{"app"}, "/run/secrets/token")
+		placements = addPlannedPlacement(placements, pod, []string{"sidecar", "app"}, "/run/secrets/backup")
+		placements = addPlannedPlacement(placements, otherPod, []string{"app"}, "/run/secrets/token")
+
+		Expect(placements).To(Equal([]v1alpha1.TrapPlacement{
+			{Kind: "Pod", Namespace: "koney-demo", Name: "api", Containers: []string{"app", "sidecar"}, FilePaths: []string{"/run/secrets/token", "/run/secrets/backup"}},
+			{Kind: "Pod", Namespace: "other", Name: "api", Containers: []string{"app"}, FilePaths: []string{"/run/secrets/token"}},
+		}))
+	})
+})
+
+var _ = Describe("buildDryRunCondition", func() {
+	It("should summarize the plan in a dry run", func() {
+		plan := &v1alpha1.DeceptionPolicyPlan{Traps: []v1alpha1.TrapPlan{
+			{Index: 0, Placements: []v1alpha1.TrapPlacement{{Containers: []string{"app", "sidecar"}}, {Containers: []string{"app"}}}},
+			{Index: 1, Error: "invalid"},
+		}}
+
+		condition := buildDryRunCondition(true, plan)
+		Expect(condition.Type).To(Equal(DryRunType))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(DryRunReason_Enabled))
+		Expect(condition.Message).To(HavePrefix("Traps would be placed in 3 containers of 2 resources"))
+	})
+
+	It("should report a disabled dry run", func() {
+		condition := buildDryRunCondition(false, nil)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(DryRunReason_Disabled))
+	})
+})
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+)
+
+var _ = Describe("Error reasons", func() {
+	trap := &v1alpha1.Trap{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney"}}
+	noShell := fmt.Errorf("failed to place decoy: %w", trapsapi.ErrNoShell)
+
+	decoyResult := TrapReconcileResult{
+		NumTraps:    3,
+		NumFailures: 2,
+		Results: []trapsapi.TrapDeploymentResult{
+			trapsapi.DecoyDeploymentResult{Trap: trap, Errors: errors.New("unknown")},
+			trapsapi.DecoyDeploymentResult{Trap: trap, Errors: errors.Join(errors.New("unknown"), noShell)},
+			trapsapi.DecoyDeploymentResult{Trap: trap, AtLeastOneObjectsWasMatched: true, AllObjectsWereReady: true},
+		},
+	}
+
+	It("should classify the errors of the traps", func() {
+		Expect(trapsapi.ErrorReason(nil)).To(BeEmpty())
+		Expect(trapsapi.ErrorReason(noShell)).To(Equal(trapsapi.ErrorReasonNoShell))
+		Expect(trapsapi.IsErrorReason(trapsapi.ErrorReasonExecForbidden)).To(BeTrue())
+		Expect(trapsapi.IsErrorReason(DecoysDeployedReason_GenericError)).To(BeFalse())
+
+		Expect(decoyResult.ErrorReason()).To(Equal(trapsapi.ErrorReasonNoShell))
+		Expect(decoyResult.NumFailuresByReason()).To(Equal(map[string]int{"": 1, trapsapi.ErrorReasonNoShell: 1}))
+	})
+
+	It("should use the reason of known errors in the status conditions", func() {
+		decoysDeployed := v1alpha1.DeceptionPolicyCondition{Type: DecoysDeployedType}
+		translateReconcileResultToStatusCondition(&decoyResult, &decoysDeployed, DecoyDeployedStatusConditions)
+		Expect(decoysDeployed.Status).To(Equal(metav1.ConditionFalse))
+		Expect(decoysDeployed.Reason).To(Equal(trapsapi.ErrorReasonNoShell))
+
+		policyValid := v1alpha1.DeceptionPolicyCondition{Type: PolicyValidType, Status: metav1.ConditionTrue, Reason: PolicyValidReason_Valid}
+		captorsDeployed := v1alpha1.DeceptionPolicyCondition{Type: CaptorsDeployedType, Status: metav1.ConditionFalse, Reason: trapsapi.ErrorReasonCaptorBackendMissing}
+		_, degraded := summarizeStatusConditions(policyValid, decoysDeployed, captorsDeployed)
+		Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(DegradedReason_Degraded))
+	})
+})
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/metrics"
+)
+
+// buildDeceptionPolicyStats summarizes the traps of a DeceptionPolicy for its metrics,
+// based on the (expanded) traps in its spec and the statuses that were built for them.
+func buildDeceptionPolicyStats(deceptionPolicy *v1alpha1.DeceptionPolicy, trapStatuses []v1alpha1.TrapStatus) metrics.DeceptionPolicyStats {
+	stats := metrics.DeceptionPolicyStats{
+		Traps:            map[string]int{},
+		TrappedResources: map[string]int{},
+		CaptorPolicies:   map[string]int{},
+	}
+
+	// Several traps can be placed in the same resource, which is only counted once
+	trappedResources := map[string]bool{}
+	for _, trapStatus := range trapStatuses {
+		if trapStatus.Expired || trapStatus.Index >= len(deceptionPolicy.Spec.Traps) {
+			continue
+		}
+		trap := deceptionPolicy.Spec.Traps[trapStatus.Index]
+		if trap.IsValid() != nil {
+			continue
+		}
+
+		stats.Traps[string(trapStatus.TrapType)]++
+		for _, placement := range trapStatus.Placements {
+			key := placement.Kind + "/" + placement.Namespace + "/" + placement.Name
+			if !trappedResources[key] {
+				trappedResources[key] = true
+				stats.TrappedResources[placement.Kind]++
+			}
+		}
+		if trapStatus.CaptorPolicyName != "" {
+			for _, strategy := range trap.CaptorDeployment.AllStrategies() {
+				stats.CaptorPolicies[strategy]++
+			}
+		}
+	}
+
+	return stats
+}
+
+// recordDeceptionPolicyMetrics updates the metrics of a DeceptionPolicy after a reconciliation.
+func recordDeceptionPolicyMetrics(deceptionPolicy *v1alpha1.DeceptionPolicy, trapStatuses []v1alpha1.TrapStatus,
+	decoyResult, captorResult TrapReconcileResult) {
+	metrics.RecordDeceptionPolicy(deceptionPolicy.Name, buildDeceptionPolicyStats(deceptionPolicy, trapStatuses))
+	metrics.RecordPlacementFailures(deceptionPolicy.Name, metrics.ComponentDecoy, decoyResult.NumFailuresByReason())
+	metrics.RecordPlacementFailures(deceptionPolicy.Name, metrics.ComponentCaptor, captorResult.NumFailuresByReason())
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("buildDeceptionPolicyStats", func() {
+	It("should count active traps, trapped resources, and captor policies", func() {
+		trap := func(filePath string, strategies ...string) v1alpha1.Trap {
+			return v1alpha1.Trap{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filePath},
+				DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "volumeMount"},
+				CaptorDeployment:     v1alpha1.CaptorDeployment{Strategies: strategies},
+				MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{
+					{ResourceDescription: v1alpha1.ResourceDescription{Namespaces: []string{"koney"}}},
+				}},
+			}
+		}
+		invalid := trap("/run/secrets/invalid", "tetragon")
+		invalid.MatchResources.Any = nil
+
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-metrics"},
+			Spec: v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{
+				trap("/run/secrets/a", "tetragon", "falco"),
+				trap("/run/secrets/b", "tetragon"),
+				trap("/run/secrets/expired", "tetragon"),
+				invalid,
+			}},
+		}
+		api := v1alpha1.TrapPlacement{Kind: "Deployment", Namespace: "koney", Name: "api"}
+		web := v1alpha1.TrapPlacement{Kind: "Pod", Namespace: "koney", Name: "web"}
+		trapStatuses := []v1alpha1.TrapStatus{
+			{Index: 0, TrapType: v1alpha1.FilesystemHoneytokenTrap, Placements: []v1alpha1.TrapPlacement{api, web}, CaptorPolicyName: "koney-a"},
+			{Index: 1, TrapType: v1alpha1.FilesystemHoneytokenTrap, Placements: []v1alpha1.TrapPlacement{api}},
+			{Index: 2, TrapType: v1alpha1.FilesystemHoneytokenTrap, Expired: true},
+			{Index: 3, TrapType: v1alpha1.FilesystemHoneytokenTrap, CaptorPolicyName: "koney-invalid"},
+		}
+
+		stats := buildDeceptionPolicyStats(deceptionPolicy, trapStatuses)
+		Expect(stats.Traps).To(Equal(map[string]int{string(v1alpha1.FilesystemHoneytokenTrap): 2}))
+		Expect(stats.TrappedResources).To(Equal(map[string]int{"Deployment": 1, "Pod": 1}))
+		Expect(stats.CaptorPolicies).To(Equal(map[string]int{"tetragon": 1, "falco": 1}))
+	})
+})
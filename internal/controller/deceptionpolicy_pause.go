@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// buildPausedCondition returns the status condition that reports whether the reconciliation of a DeceptionPolicy is paused.
+func buildPausedCondition(isPaused bool) v1alpha1.DeceptionPolicyCondition {
+	condition := v1alpha1.DeceptionPolicyCondition{
+		Type:               PausedType,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             PausedReason_Resumed,
+		Message:            PausedMessage_Resumed,
+	}
+	if isPaused {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = PausedReason_Paused
+		condition.Message = PausedMessage_Paused
+	}
+
+	return condition
+}
+
+// updatePausedCondition marks a paused DeceptionPolicy as paused in its status.
+// Unlike updateStatusConditions, this does not change the observed generation, since the DeceptionPolicy is not reconciled.
+func (r *DeceptionPolicyReconciler) updatePausedCondition(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
+			return err
+		}
+
+		condition := buildPausedCondition(true)
+		condition.ObservedGeneration = deceptionPolicy.Generation
+		if dirty := deceptionPolicy.Status.PutConditionStruct(condition); !dirty {
+			return nil // The condition already has its desired value
+		}
+
+		return r.Status().Update(ctx, deceptionPolicy)
+	})
+}
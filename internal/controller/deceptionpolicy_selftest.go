@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// selfTestCertsVolume is the volume of the test pods with the CA certificate of the alert forwarder, if it serves TLS.
+const selfTestCertsVolume = "alert-forwarder-certs"
+
+// reconcileSelfTest creates or updates the CronJob that periodically self-tests the DeceptionPolicy from a dedicated test pod,
+// or deletes it if the DeceptionPolicy should not be self-tested (anymore).
+// The CronJob is read without the cache, so that the controller does not watch all CronJobs of the cluster.
+func (r *DeceptionPolicyReconciler) reconcileSelfTest(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, enabled bool) error {
+	name := generateSelfTestCronJobName(deceptionPolicy.Name)
+
+	var existing batchv1.CronJob
+	err := r.apiReader().Get(ctx, types.NamespacedName{Namespace: utils.GetKoneyNamespace(), Name: name}, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("self-test CronJob %s cannot be fetched: %w", name, err)
+	}
+	exists := err == nil
+
+	if !enabled {
+		if !exists {
+			return nil
+		}
+		if err := r.Delete(ctx, &existing); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("self-test CronJob %s cannot be deleted: %w", name, err)
+		}
+		return nil
+	}
+
+	desired, err := buildSelfTestCronJob(deceptionPolicy, name)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("self-test CronJob %s cannot be created: %w", name, err)
+		}
+	} else if existing.Annotations[constants.AnnotationKeySelfTestHash] != desired.Annotations[constants.AnnotationKeySelfTestHash] {
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		existing.OwnerReferences = desired.OwnerReferences
+		existing.Spec = desired.Spec
+		if err := r.Update(ctx, &existing); err != nil {
+			return fmt.Errorf("self-test CronJob %s cannot be updated: %w", name, err)
+		}
+	} else {
+		return nil // The CronJob is up-to-date
+	}
+
+	r.Recorder.Eventf(deceptionPolicy, corev1.EventTypeNormal, constants.EventReasonSelfTestScheduled,
+		"Self-tests scheduled at %q with CronJob %s/%s", desired.Spec.Schedule, desired.Namespace, desired.Name)
+	return nil
+}
+
+// generateSelfTestCronJobName returns the name of the self-test CronJob of a DeceptionPolicy,
+// which is based on a hash, since the names of CronJobs are much shorter than those of DeceptionPolicies.
+func generateSelfTestCronJobName(deceptionPolicyName string) string {
+	return constants.SelfTestCronJobPrefix + utils.Hash(deceptionPolicyName)
+}
+
+// buildSelfTestCronJob returns the CronJob that periodically self-tests the DeceptionPolicy from a dedicated test pod.
+// The test pod touches a honeytoken with a marker that identifies the self-test, and waits until the alert forwarder reports in the status
+// that the alert was forwarded. The test pod fails if the self-test failed, so that failed jobs show up in the cluster as well,
+// and hands the failure to the alert forwarder, which alerts the sinks (unless disabled).
+func buildSelfTestCronJob(deceptionPolicy *v1alpha1.DeceptionPolicy, name string) (*batchv1.CronJob, error) {
+	alertOnFailure := deceptionPolicy.Spec.SelfTest.AlertOnFailure == nil || *deceptionPolicy.Spec.SelfTest.AlertOnFailure
+	alertForwarderUrl := "http://koney-alert-forwarder-webhook." + utils.GetKoneyNamespace() + ".svc:8000"
+	if utils.IsAlertForwarderTLSEnabled() {
+		alertForwarderUrl = "https://koney-alert-forwarder-webhook." + utils.GetKoneyNamespace() + ".svc:8000"
+	}
+	container := corev1.Container{
+		Name:    "self-test",
+		Image:   utils.GetSelfTestImage(),
+		Command: []string{"python", "-m", "forwarder.selftest"},
+		Env: []corev1.EnvVar{
+			{Name: "KONEY_DECEPTION_POLICY_NAME", Value: deceptionPolicy.Name},
+			{Name: "KONEY_SELF_TEST_ALERT_ON_FAILURE", Value: fmt.Sprint(alertOnFailure)},
+			{Name: "KONEY_ALERT_FORWARDER_URL", Value: alertForwarderUrl},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: &[]bool{false}[0],
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+	}
+
+	if utils.GetAlertForwarderToken() != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: "KONEY_ALERT_FORWARDER_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: utils.GetAlertForwarderTokenSecretName()},
+				Key:                  "token",
+			}},
+		})
+	}
+
+	var volumes []corev1.Volume
+	if utils.IsAlertForwarderTLSEnabled() {
+		volumes = append(volumes, corev1.Volume{
+			Name: selfTestCertsVolume,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: utils.GetAlertForwarderCertSecretName(),
+			}},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      selfTestCertsVolume,
+			MountPath: "/etc/koney/tls",
+			ReadOnly:  true,
+		})
+		container.Env = append(container.Env, corev1.EnvVar{Name: "KONEY_ALERT_FORWARDER_CA_FILE", Value: "/etc/koney/tls/ca.crt"})
+	}
+
+	labels := map[string]string{constants.LabelKeyDeceptionPolicyRef: deceptionPolicy.Name}
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: utils.GetKoneyNamespace(),
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(deceptionPolicy, v1alpha1.GroupVersion.WithKind("DeceptionPolicy")),
+			},
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   deceptionPolicy.Spec.SelfTest.GetSchedule(),
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &[]int32{1}[0],
+			FailedJobsHistoryLimit:     &[]int32{3}[0],
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &[]int32{0}[0],
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							SecurityContext: &corev1.PodSecurityContext{
+								RunAsNonRoot:   &[]bool{true}[0],
+								SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+							},
+							ServiceAccountName: constants.SelfTestServiceAccountName,
+							Containers:         []corev1.Container{container},
+							Volumes:            volumes,
+							RestartPolicy:      corev1.RestartPolicyNever,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	spec, err := json.Marshal(cronJob.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("self-test CronJob %s cannot be hashed: %w", name, err)
+	}
+	cronJob.Annotations = map[string]string{constants.AnnotationKeySelfTestHash: utils.Hash(string(spec))}
+
+	return cronJob, nil
+}
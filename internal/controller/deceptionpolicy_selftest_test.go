@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("buildSelfTestCronJob", func() {
+	var deceptionPolicy *v1alpha1.DeceptionPolicy
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("KONEY_NAMESPACE", "koney-system")
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TOKEN", "")
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TLS", "false")
+
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample", UID: "uid-1"},
+			Spec:       v1alpha1.DeceptionPolicySpec{SelfTest: &v1alpha1.SelfTest{}},
+		}
+	})
+
+	It("should self-test the DeceptionPolicy from a test pod on the default schedule", func() {
+		cronJob, err := buildSelfTestCronJob(deceptionPolicy, generateSelfTestCronJobName(deceptionPolicy.Name))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cronJob.Name).To(HavePrefix(constants.SelfTestCronJobPrefix))
+		Expect(len(cronJob.Name)).To(BeNumerically("<=", 52))
+		Expect(cronJob.Namespace).To(Equal("koney-system"))
+		Expect(cronJob.Labels).To(HaveKeyWithValue(constants.LabelKeyDeceptionPolicyRef, "deceptionpolicy-sample"))
+		Expect(cronJob.OwnerReferences).To(HaveLen(1))
+		Expect(cronJob.OwnerReferences[0].Kind).To(Equal("DeceptionPolicy"))
+		Expect(cronJob.Spec.Schedule).To(Equal(v1alpha1.DefaultSelfTestSchedule))
+
+		podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+		Expect(podSpec.ServiceAccountName).To(Equal(constants.SelfTestServiceAccountName))
+		Expect(podSpec.Volumes).To(BeEmpty())
+
+		container := podSpec.Containers[0]
+		Expect(container.Image).To(Equal(constants.DefaultSelfTestImage))
+		Expect(container.Command).To(Equal([]string{"python", "-m", "forwarder.selftest"}))
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "KONEY_DECEPTION_POLICY_NAME", Value: "deceptionpolicy-sample"},
+			corev1.EnvVar{Name: "KONEY_SELF_TEST_ALERT_ON_FAILURE", Value: "true"},
+			corev1.EnvVar{Name: "KONEY_ALERT_FORWARDER_URL", Value: "http://koney-alert-forwarder-webhook.koney-system.svc:8000"},
+		))
+	})
+
+	It("should authenticate with the token and trust the certificate of the alert forwarder", func() {
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TOKEN", "secret")
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TLS", "true")
+
+		cronJob, err := buildSelfTestCronJob(deceptionPolicy, "koney-self-test-x")
+		Expect(err).NotTo(HaveOccurred())
+
+		podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+		Expect(podSpec.Containers[0].Env).To(ContainElements(
+			HaveField("ValueFrom.SecretKeyRef.Name", "koney-alert-forwarder-token"),
+			corev1.EnvVar{Name: "KONEY_ALERT_FORWARDER_URL", Value: "https://koney-alert-forwarder-webhook.koney-system.svc:8000"},
+			corev1.EnvVar{Name: "KONEY_ALERT_FORWARDER_CA_FILE", Value: "/etc/koney/tls/ca.crt"},
+		))
+		Expect(podSpec.Containers[0].Env).NotTo(ContainElement(HaveField("Value", "secret")))
+		Expect(podSpec.Volumes).To(HaveLen(1))
+		Expect(podSpec.Volumes[0].Secret.SecretName).To(Equal("koney-alert-forwarder-cert"))
+	})
+
+	It("should change the hash if the self-test configuration changes", func() {
+		before, err := buildSelfTestCronJob(deceptionPolicy, "koney-self-test-x")
+		Expect(err).NotTo(HaveOccurred())
+
+		deceptionPolicy.Spec.SelfTest = &v1alpha1.SelfTest{Schedule: "*/5 * * * *", AlertOnFailure: &[]bool{false}[0]}
+		after, err := buildSelfTestCronJob(deceptionPolicy, "koney-self-test-x")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(after.Spec.Schedule).To(Equal("*/5 * * * *"))
+		Expect(after.Annotations[constants.AnnotationKeySelfTestHash]).NotTo(Equal(before.Annotations[constants.AnnotationKeySelfTestHash]))
+	})
+})
@@ -43,6 +43,8 @@ type TrapReconcileResult struct {
 	OverrideStatusConditionMessage string
 	// Errors contains all the errors that happened during the reconciliation.
 	Errors error
+	// Results contains the deployment result of each trap that was passed for reconciliation.
+	Results []trapsapi.TrapDeploymentResult
 }
 
 // NumTries is the total number of traps for which we tried a reconciliation (NumSuccesses + NumFailures).
@@ -56,23 +58,60 @@ func (r TrapReconcileResult) NumSkipped() int {
 	return r.NumTraps - r.NumSuccesses - r.NumFailures
 }
 
+// ErrorReason returns the reason of the first known error (see trapsapi.ErrorReason) in the results of the traps,
+// or an empty string if no trap failed with a known error.
+func (r TrapReconcileResult) ErrorReason() string {
+	for _, result := range r.Results {
+		if reason := trapsapi.ErrorReason(result.GetErrors()); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// NumFailuresByReason counts the traps that had errors during reconciliation, per reason of their errors (see trapsapi.ErrorReason).
+// Traps that failed with an unknown error are counted with an empty reason.
+func (r TrapReconcileResult) NumFailuresByReason() map[string]int {
+	numFailuresByReason := map[string]int{}
+	for _, result := range r.Results {
+		if result.ImpliesFailure() {
+			numFailuresByReason[trapsapi.ErrorReason(result.GetErrors())]++
+		}
+	}
+	return numFailuresByReason
+}
+
+// addUnchanged counts traps that were not passed for reconciliation, because they did not change
+// since they were deployed successfully, as successes.
+func (r *TrapReconcileResult) addUnchanged(numUnchanged int) {
+	r.NumTraps += numUnchanged
+	r.NumSuccesses += numUnchanged
+}
+
+// addFailure counts a trap that could not be passed for reconciliation, e.g., because its canary token
+// cannot be provisioned, as a failure. Its errors are kept, so that the reconciliation is retried.
+func (r *TrapReconcileResult) addFailure(result trapsapi.DecoyDeploymentResult) {
+	r.NumTraps++
+	r.NumFailures++
+	r.Errors = errors.Join(r.Errors, result.Errors)
+	r.Results = append(r.Results, result)
+}
+
 func (r *DeceptionPolicyReconciler) buildFilesystemTokenReconciler(deceptionPolicy *v1alpha1.DeceptionPolicy) filesystoken.FilesystemHoneytokenReconciler {
-	return filesystoken.FilesystemHoneytokenReconciler{Client: r.Client, Clientset: r.Clientset, Config: r.Config, DeceptionPolicy: deceptionPolicy}
+	return filesystoken.FilesystemHoneytokenReconciler{Client: r.Client, Clientset: r.Clientset, Config: r.Config,
+		APIReader: r.APIReader, Recorder: r.Recorder, DeceptionPolicy: deceptionPolicy, PlacementRetries: &r.placementRetries}
 }
 
 func (r *DeceptionPolicyReconciler) reconcileDecoys(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, reconcileTraps []v1alpha1.Trap) TrapReconcileResult {
 	log := k8slog.FromContext(ctx)
 
 	results := make([]trapsapi.DecoyDeploymentResult, 0, len(reconcileTraps))
+	var filesystemHoneytokenTraps []v1alpha1.Trap
 	for _, trap := range reconcileTraps {
 		switch trap.TrapType() {
 		case v1alpha1.FilesystemHoneytokenTrap:
-			rd := r.buildFilesystemTokenReconciler(deceptionPolicy)
-			result := rd.DeployDecoy(ctx, deceptionPolicy, trap)
-			results = append(results, result)
-			if result.GetErrors() != nil {
-				log.Error(result.GetErrors(), "FilesystemHoneytoken decoy deployment had errors", "trap", trap.FilesystemHoneytoken)
-			}
+			// FilesystemHoneytoken decoys are deployed together below, so that decoys in the same container are placed at once
+			filesystemHoneytokenTraps = append(filesystemHoneytokenTraps, trap)
 		case v1alpha1.HttpEndpointTrap:
 			log.Error(nil, "HttpEndpointTrap not implemented yet", "trap", trap.HttpEndpoint)
 			results = append(results, trapsapi.DecoyDeploymentResult{Trap: &trap, Errors: errors.New("HttpEndpointTrap not implemented yet")})
@@ -85,10 +124,21 @@ func (r *DeceptionPolicyReconciler) reconcileDecoys(ctx context.Context, decepti
 		}
 	}
 
+	if len(filesystemHoneytokenTraps) > 0 {
+		rd := r.buildFilesystemTokenReconciler(deceptionPolicy)
+		for i, result := range rd.DeployDecoys(ctx, deceptionPolicy, filesystemHoneytokenTraps) {
+			results = append(results, result)
+			if result.GetErrors() != nil {
+				log.Error(result.GetErrors(), "FilesystemHoneytoken decoy deployment had errors", "trap", filesystemHoneytokenTraps[i].FilesystemHoneytoken)
+			}
+		}
+	}
+
 	// Summarize the decoy deployment results
 	reconcileResult := TrapReconcileResult{NumTraps: len(reconcileTraps)}
 	for _, result := range results {
 		result.Errors = errors.Join(result.Errors, result.GetErrors())
+		reconcileResult.Results = append(reconcileResult.Results, result)
 		if result.ImpliesFailure() {
 			reconcileResult.NumFailures++
 		} else if result.ImpliesSuccess() {
@@ -103,7 +153,9 @@ func (r *DeceptionPolicyReconciler) reconcileDecoys(ctx context.Context, decepti
 	return reconcileResult
 }
 
-func (r *DeceptionPolicyReconciler) reconcileCaptors(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, reconcileTraps []v1alpha1.Trap) TrapReconcileResult {
+// reconcileCaptors deploys the captors of the given traps. If uncached is true, existing captors are looked up
+// directly in the API server, e.g., because the cache might still contain captors that were deleted with their CRD.
+func (r *DeceptionPolicyReconciler) reconcileCaptors(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, reconcileTraps []v1alpha1.Trap, uncached bool) TrapReconcileResult {
 	log := k8slog.FromContext(ctx)
 
 	results := make([]trapsapi.CaptorDeploymentResult, 0, len(reconcileTraps))
@@ -111,6 +163,9 @@ func (r *DeceptionPolicyReconciler) reconcileCaptors(ctx context.Context, decept
 		switch trap.TrapType() {
 		case v1alpha1.FilesystemHoneytokenTrap:
 			rd := r.buildFilesystemTokenReconciler(deceptionPolicy)
+			if uncached {
+				rd.CaptorReader = r.APIReader
+			}
 			result := rd.DeployCaptor(ctx, deceptionPolicy, trap)
 			results = append(results, result)
 			if result.GetErrors() != nil {
@@ -132,6 +187,7 @@ func (r *DeceptionPolicyReconciler) reconcileCaptors(ctx context.Context, decept
 	reconcileResult := TrapReconcileResult{NumTraps: len(reconcileTraps)}
 	for _, result := range results {
 		result.Errors = errors.Join(result.Errors, result.GetErrors())
+		reconcileResult.Results = append(reconcileResult.Results, result)
 		if result.ImpliesFailure() {
 			reconcileResult.NumFailures++
 		} else if result.ImpliesSuccess() {
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// TrapDiff is the semantic difference between the traps that were reconciled for a previous generation
+// of a DeceptionPolicy (as recorded in its status) and the traps of its current generation.
+type TrapDiff struct {
+	// Added are the traps that were not part of the previous generation.
+	Added []v1alpha1.Trap
+	// Changed are the traps whose spec changed at the same position in the traps list.
+	Changed []v1alpha1.Trap
+	// Unchanged are the traps that were already deployed successfully for a previous generation.
+	Unchanged []v1alpha1.Trap
+	// Pending are the traps that did not change, but were not deployed successfully yet.
+	Pending []v1alpha1.Trap
+	// RemovedHashes are the hashes of the traps that are not part of the current generation anymore.
+	RemovedHashes []string
+}
+
+// Delta returns the given traps without the unchanged ones, i.e., the traps that must be deployed.
+func (d TrapDiff) Delta(traps []v1alpha1.Trap) []v1alpha1.Trap {
+	return slices.DeleteFunc(slices.Clone(traps), func(trap v1alpha1.Trap) bool {
+		hash := hashTrap(trap)
+		return slices.ContainsFunc(d.Unchanged, func(unchanged v1alpha1.Trap) bool { return hashTrap(unchanged) == hash })
+	})
+}
+
+// diffTraps computes the difference between the trap statuses of a previous generation and the given traps.
+// Traps are identified by the hash of their spec, so moving a trap within the list does not change it.
+func diffTraps(previous []v1alpha1.TrapStatus, traps []v1alpha1.Trap) TrapDiff {
+	var diff TrapDiff
+
+	previousByHash := make(map[string]v1alpha1.TrapStatus, len(previous))
+	previousHashByIndex := make(map[int]string, len(previous))
+	for _, trapStatus := range previous {
+		previousByHash[trapStatus.TrapHash] = trapStatus
+		previousHashByIndex[trapStatus.Index] = trapStatus.TrapHash
+	}
+
+	currentHashes := make(map[string]bool, len(traps))
+	for index, trap := range traps {
+		hash := hashTrap(trap)
+		currentHashes[hash] = true
+
+		trapStatus, existed := previousByHash[hash]
+		switch {
+		case !existed:
+			if _, replaced := previousHashByIndex[index]; replaced {
+				diff.Changed = append(diff.Changed, trap)
+			} else {
+				diff.Added = append(diff.Added, trap)
+			}
+		case trapStatus.DeployedAt != nil && trapStatus.LastError == "" && !trapStatus.Expired && !filesystoken.IsRotating(trap):
+			// Rotating honeytokens are never skipped, because their decoys change over time
+			diff.Unchanged = append(diff.Unchanged, trap)
+		default:
+			diff.Pending = append(diff.Pending, trap)
+		}
+	}
+
+	for _, trapStatus := range previous {
+		if !currentHashes[trapStatus.TrapHash] {
+			diff.RemovedHashes = append(diff.RemovedHashes, trapStatus.TrapHash)
+			currentHashes[trapStatus.TrapHash] = true // Report duplicates only once
+		}
+	}
+
+	return diff
+}
+
+// hashTrap returns the hash of a trap spec, as it is recorded in the trap status and annotated on captors.
+func hashTrap(trap v1alpha1.Trap) string {
+	trapJSON, err := json.Marshal(trap)
+	if err != nil {
+		return ""
+	}
+	return utils.Hash(string(trapJSON))
+}
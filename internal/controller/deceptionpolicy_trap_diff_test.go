@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("diffTraps", func() {
+	newTrap := func(filePath string) v1alpha1.Trap {
+		return v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filePath, ReadOnly: &[]bool{true}[0]},
+			MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{
+				{ResourceDescription: v1alpha1.ResourceDescription{Namespaces: []string{"koney"}}},
+			}},
+		}
+	}
+
+	deployedAt := metav1.Now()
+	deployed := func(index int, trap v1alpha1.Trap) v1alpha1.TrapStatus {
+		return v1alpha1.TrapStatus{Index: index, TrapHash: hashTrap(trap), DeployedAt: &deployedAt}
+	}
+
+	It("should detect added, changed, pending, and unchanged traps", func() {
+		unchanged, moved, failed := newTrap("/unchanged"), newTrap("/moved"), newTrap("/failed")
+		changedBefore, changedAfter := newTrap("/changed"), newTrap("/changed-now")
+		added := newTrap("/added")
+
+		failedStatus := deployed(3, failed)
+		failedStatus.LastError = "boom"
+		previous := []v1alpha1.TrapStatus{
+			deployed(0, unchanged),
+			deployed(1, changedBefore),
+			deployed(2, moved),
+			failedStatus,
+		}
+
+		diff := diffTraps(previous, []v1alpha1.Trap{unchanged, changedAfter, failed, moved, added, added})
+		Expect(diff.Unchanged).To(ConsistOf(unchanged, moved))
+		Expect(diff.Changed).To(ConsistOf(changedAfter))
+		Expect(diff.Pending).To(ConsistOf(failed))
+		Expect(diff.Added).To(ConsistOf(added, added))
+		Expect(diff.RemovedHashes).To(ConsistOf(hashTrap(changedBefore)))
+
+		By("only deploying traps that are not unchanged")
+		Expect(diff.Delta([]v1alpha1.Trap{unchanged, changedAfter, failed, moved, added})).To(Equal([]v1alpha1.Trap{changedAfter, failed, added}))
+	})
+
+	It("should detect removed traps", func() {
+		unchanged, removed := newTrap("/unchanged"), newTrap("/removed")
+
+		diff := diffTraps([]v1alpha1.TrapStatus{deployed(0, unchanged), deployed(1, removed), deployed(2, removed)}, []v1alpha1.Trap{unchanged})
+		Expect(diff.Unchanged).To(ConsistOf(unchanged))
+		Expect(diff.Added).To(BeEmpty())
+		Expect(diff.Changed).To(BeEmpty())
+		Expect(diff.RemovedHashes).To(Equal([]string{hashTrap(removed)}))
+	})
+
+	It("should never skip rotating honeytokens", func() {
+		rotating := newTrap("/rotating")
+		rotating.FilesystemHoneytoken.RotateEvery = &metav1.Duration{Duration: v1alpha1.MinRotationInterval}
+
+		diff := diffTraps([]v1alpha1.TrapStatus{deployed(0, rotating)}, []v1alpha1.Trap{rotating})
+		Expect(diff.Unchanged).To(BeEmpty())
+		Expect(diff.Pending).To(ConsistOf(rotating))
+	})
+})
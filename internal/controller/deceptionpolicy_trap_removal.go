@@ -17,9 +17,13 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	kivev1 "github.com/San7o/kivebpf/api/v1"
 	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,69 +37,85 @@ import (
 	"github.com/dynatrace-oss/koney/api/v1alpha1"
 )
 
-// cleanupDeceptionPolicy cleans up all the traps deployed by a DeceptionPolicy
-func (r *DeceptionPolicyReconciler) cleanupDeceptionPolicy(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) error {
+// cleanupDeceptionPolicy cleans up all the traps deployed by a DeceptionPolicy.
+// All resources are cleaned up, even if some of them fail. The boolean return type indicates if the removal of some
+// decoys is still in progress, so that the DeceptionPolicy must not be deleted yet and this function should be called again later.
+func (r *DeceptionPolicyReconciler) cleanupDeceptionPolicy(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) (bool, error) {
+	log := k8slog.FromContext(ctx)
+
 	// Cycle through the pods and get their annotations
 	resources, err := annotations.GetAnnotatedResources(r, ctx, deceptionPolicy.Name)
 	if err != nil {
-		return err
+		return false, err
 	}
+
+	var joinedErrors error
+	isPending := false
 	for _, resource := range resources {
 		annotationChange, err := annotations.GetAnnotationChange(resource, deceptionPolicy.Name)
 		if err != nil {
-			return err
+			joinedErrors = errors.Join(joinedErrors, err)
+			continue
 		}
 
 		// Cycle through the traps and remove them
 		for _, trapAnnotation := range annotationChange.Traps {
-			if err := r.cleanupTrap(ctx, deceptionPolicy, trapAnnotation, resource); err != nil {
-				return err
+			pending, err := r.cleanupTrap(ctx, deceptionPolicy, trapAnnotation, resource)
+			if err != nil {
+				log.Error(err, "Trap cannot be removed", "resource", client.ObjectKeyFromObject(resource), "trap", trapAnnotation.FilesystemHoneytoken.FilePath)
+				joinedErrors = errors.Join(joinedErrors, err)
 			}
+			isPending = isPending || pending
 		}
 	}
-
-	return nil
+	// The Falco rules are not owned by the DeceptionPolicy (they share a ConfigMap), so they are removed here
+	if err := filesystoken.RemoveFalcoRules(ctx, r.apiReader(), r, deceptionPolicy.Name, nil); err != nil {
+		log.Error(err, "Falco rules cannot be removed")
+		joinedErrors = errors.Join(joinedErrors, err)
+	}
+	// The secrets of the removed decoys are deleted by the Kubernetes garbage collector (since they are owned by the DeceptionPolicy),
+	// or by the DecoyGarbageCollector once they are not mounted anymore
+	return isPending, joinedErrors
 }
 
-// cleanupTrap cleans up a trap from a pod
-func (r *DeceptionPolicyReconciler) cleanupTrap(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trapAnnotation v1alpha1.TrapAnnotation, resource client.Object) error {
+// cleanupTrap cleans up a trap from a pod.
+// The boolean return type indicates if the removal is still in progress and this function should be called again later.
+func (r *DeceptionPolicyReconciler) cleanupTrap(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trapAnnotation v1alpha1.TrapAnnotation, resource client.Object) (bool, error) {
 	switch trapAnnotation.TrapType() {
 	case v1alpha1.FilesystemHoneytokenTrap:
 		rd := r.buildFilesystemTokenReconciler(deceptionPolicy)
-		if err := rd.RemoveDecoy(ctx, deceptionPolicy.Name, trapAnnotation, resource); err != nil {
-			return err
+		pending, err := rd.RemoveDecoy(ctx, deceptionPolicy.Name, trapAnnotation, resource)
+		if err == nil && !pending && r.Recorder != nil {
+			r.Recorder.Eventf(deceptionPolicy, corev1.EventTypeNormal, constants.EventReasonTrapRemoved, "Decoy %s removed from containers %v of %s %s/%s",
+				trapAnnotation.FilesystemHoneytoken.FilePath, trapAnnotation.Containers, utils.ResourceKind(resource), resource.GetNamespace(), resource.GetName())
 		}
+		return pending, err
 
 	case v1alpha1.HttpEndpointTrap:
 		// TODO: Implement.
-		return nil
+		return false, nil
 	case v1alpha1.HttpPayloadTrap:
 		// TODO: Implement.
-		return nil
+		return false, nil
 	default:
-		return nil
+		return false, nil
 	}
-
-	return nil
 }
 
-// cleanupRemovedTraps cleans up the traps that have been removed from a DeceptionPolicy
-func (r *DeceptionPolicyReconciler) cleanupRemovedTraps(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) error {
+// cleanupRemovedTraps cleans up the traps of a DeceptionPolicy that are not part of the given (still active) traps anymore.
+// Decoys of rotating honeytokens are considered removed if they do not belong to the generation that is active at the given time.
+func (r *DeceptionPolicyReconciler) cleanupRemovedTraps(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, activeTraps []v1alpha1.Trap, now time.Time) error {
 	// Remove the captors
-	if err := r.cleanupRemovedCaptors(ctx, deceptionPolicy); err != nil {
+	if err := r.cleanupRemovedCaptors(ctx, deceptionPolicy, activeTraps); err != nil {
 		return err
 	}
 
-	// Remove the decoys
-	if err := r.cleanupRemovedDecoys(ctx, deceptionPolicy); err != nil {
-		return err
-	}
-
-	return nil
+	// Remove the decoys (the secrets and volumes that they leave behind are removed by the DecoyGarbageCollector)
+	return r.cleanupRemovedDecoys(ctx, deceptionPolicy, activeTraps, now)
 }
 
 // cleanupRemovedCaptors cleans up the captors that have been removed from a DeceptionPolicy
-func (r *DeceptionPolicyReconciler) cleanupRemovedCaptors(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) error {
+func (r *DeceptionPolicyReconciler) cleanupRemovedCaptors(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, activeTraps []v1alpha1.Trap) error {
 	log := k8slog.FromContext(ctx)
 
 	// Tetragon
@@ -115,11 +135,11 @@ func (r *DeceptionPolicyReconciler) cleanupRemovedCaptors(ctx context.Context, d
 
 	if isTetragonInstalled {
 		tetragonPolicyNamesFromTraps := []string{}
-		for _, trap := range deceptionPolicy.Spec.Traps {
-			if trap.CaptorDeployment.Strategy == "none" {
+		for _, trap := range activeTraps {
+			if !trap.CaptorDeployment.HasStrategy("tetragon") {
 				continue
 			}
-			tracingPolicyName, err := filesystoken.GenerateTetragonTracingPolicyName(trap)
+			tracingPolicyName, err := filesystoken.GenerateCaptorName(deceptionPolicy, trap)
 			if err != nil {
 				return err
 			}
@@ -147,6 +167,39 @@ func (r *DeceptionPolicyReconciler) cleanupRemovedCaptors(ctx context.Context, d
 				}
 			}
 		}
+
+		// Traps that only target specific namespaces have a namespaced tracing policy in each of them (if Tetragon supports them)
+		namespacedTracingPolicies := &ciliumiov1alpha1.TracingPolicyNamespacedList{}
+		if err := r.List(ctx, namespacedTracingPolicies, client.MatchingLabels{constants.LabelKeyDeceptionPolicyRef: deceptionPolicy.Name}); err != nil && !meta.IsNoMatchError(err) {
+			return err
+		}
+		for i := range namespacedTracingPolicies.Items {
+			namespacedTracingPolicy := &namespacedTracingPolicies.Items[i]
+			if utils.Contains(tetragonPolicyNamesFromTraps, namespacedTracingPolicy.Name) {
+				continue
+			}
+
+			log.Info("Deleting namespaced tracing policy for removed trap", "policy", namespacedTracingPolicy.Name, "namespace", namespacedTracingPolicy.Namespace)
+			if err := r.Delete(ctx, namespacedTracingPolicy); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+	}
+
+	// Falco
+	falcoRuleNamesFromTraps := []string{}
+	for _, trap := range activeTraps {
+		if !trap.CaptorDeployment.HasStrategy("falco") {
+			continue
+		}
+		ruleName, err := filesystoken.GenerateCaptorName(deceptionPolicy, trap)
+		if err != nil {
+			return err
+		}
+		falcoRuleNamesFromTraps = append(falcoRuleNamesFromTraps, ruleName)
+	}
+	if err := filesystoken.RemoveFalcoRules(ctx, r.apiReader(), r, deceptionPolicy.Name, falcoRuleNamesFromTraps); err != nil {
+		return err
 	}
 
 	// Kive
@@ -163,11 +216,11 @@ func (r *DeceptionPolicyReconciler) cleanupRemovedCaptors(ctx context.Context, d
 	}
 
 	kivePolicyNamesFromTraps := []string{}
-	for _, trap := range deceptionPolicy.Spec.Traps {
-		if trap.CaptorDeployment.Strategy == "none" {
+	for _, trap := range activeTraps {
+		if !trap.CaptorDeployment.HasStrategy("kive") {
 			continue
 		}
-		tracingPolicyName, err := filesystoken.GenerateKivePolicyName(trap)
+		tracingPolicyName, err := filesystoken.GenerateCaptorName(deceptionPolicy, trap)
 		if err != nil {
 			return err
 		}
@@ -201,7 +254,17 @@ func (r *DeceptionPolicyReconciler) cleanupRemovedCaptors(ctx context.Context, d
 }
 
 // cleanupRemovedDecoys cleans up the decoys that have been removed from a DeceptionPolicy
-func (r *DeceptionPolicyReconciler) cleanupRemovedDecoys(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) error {
+func (r *DeceptionPolicyReconciler) cleanupRemovedDecoys(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, activeTraps []v1alpha1.Trap, now time.Time) error {
+	resolvedTraps, unresolvedResults := r.resolveCanaryTokens(ctx, deceptionPolicy, filesystoken.ExpandFilePaths(activeTraps))
+	decoyTraps := filesystoken.ResolveRotatedTraps(deceptionPolicy, resolvedTraps, now)
+
+	// The decoys of active traps whose canary token cannot be resolved (e.g., because its secret is not cached yet)
+	// cannot be told apart from removed decoys, so they are kept until the canary token is resolved again
+	unresolvedFilePaths := make([]string, 0, len(unresolvedResults))
+	for _, result := range unresolvedResults {
+		unresolvedFilePaths = append(unresolvedFilePaths, result.Trap.FilesystemHoneytoken.FilePath)
+	}
+
 	// Cycle through the pods and get their annotations
 	resources, err := annotations.GetAnnotatedResources(r, ctx, deceptionPolicy.Name)
 	if err != nil {
@@ -216,16 +279,18 @@ func (r *DeceptionPolicyReconciler) cleanupRemovedDecoys(ctx context.Context, de
 		// Cycle through the traps and remove them
 		for _, trapAnnotation := range annotationChange.Traps {
 			// If the trap has been removed from the DeceptionPolicy, remove it
-			found := false
-			for _, trap := range deceptionPolicy.Spec.Traps {
+			found := slices.Contains(unresolvedFilePaths, trapAnnotation.FilesystemHoneytoken.FilePath)
+			for _, trap := range decoyTraps {
 				if annotations.AreTheSameTrap(trapAnnotation, trap) {
 					found = true
 					break
 				}
 			}
 
+			// Removals that are still in progress are checked again in the next reconciliation,
+			// since the trap stays in the annotations until then
 			if !found {
-				if err := r.cleanupTrap(ctx, deceptionPolicy, trapAnnotation, resource); err != nil {
+				if _, err := r.cleanupTrap(ctx, deceptionPolicy, trapAnnotation, resource); err != nil {
 					return err
 				}
 			}
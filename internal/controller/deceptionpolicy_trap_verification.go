@@ -0,0 +1,275 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/metrics"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// decoyContentChecks remembers when the decoys of each DeceptionPolicy were last read back from containers,
+// since that takes an exec per decoy and container, while the other checks of the verification run with every reconciliation.
+// The zero value is ready to use.
+type decoyContentChecks struct {
+	mu        sync.Mutex
+	checkedAt map[string]time.Time
+}
+
+// due returns true (and remembers the check) if the decoys of a DeceptionPolicy were not read back within the verification interval.
+func (c *decoyContentChecks) due(name string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if checkedAt, ok := c.checkedAt[name]; ok && now.Sub(checkedAt) < constants.DecoyVerificationInterval {
+		return false
+	}
+	if c.checkedAt == nil {
+		c.checkedAt = map[string]time.Time{}
+	}
+	c.checkedAt[name] = now
+	return true
+}
+
+// forget forgets when the decoys of a DeceptionPolicy were last read back, e.g., because it was deleted.
+func (c *decoyContentChecks) forget(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.checkedAt, name)
+}
+
+// verifyDecoys checks if the decoys of the given traps are still in place in all resources where Koney annotated that
+// it deployed them, and if their content still matches the checksums recorded in the status. Decoys in the filesystem
+// of containers are only read back (with exec) once per verification interval. Containers in which a decoy drifted are removed
+// from the annotations, so that the decoy deployment that follows deploys the decoy there again. The drifts are returned per trap hash.
+func (r *DeceptionPolicyReconciler) verifyDecoys(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap, now time.Time) (map[string][]v1alpha1.TrapDrift, error) {
+	log := k8slog.FromContext(ctx)
+
+	resources, err := annotations.GetAnnotatedResources(r, ctx, deceptionPolicy.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	readContainerFiles := r.decoyContentChecks.due(deceptionPolicy.Name, now)
+	numTamperings := 0
+	defer func() { metrics.RecordDecoyTamperings(deceptionPolicy.Name, numTamperings) }()
+
+	var joinedErrors error
+	drifts := map[string][]v1alpha1.TrapDrift{}
+	for _, trap := range traps {
+		if trap.TrapType() != v1alpha1.FilesystemHoneytokenTrap {
+			continue
+		}
+
+		// Decoys are deployed per file path and generation, so we verify the ones of the current generation
+		// (decoys whose canary token cannot be resolved are not deployed, so there is nothing to verify)
+		resolvedTraps, _ := r.resolveCanaryTokens(ctx, deceptionPolicy, filesystoken.ExpandFilePaths([]v1alpha1.Trap{trap}))
+		decoyTraps := filesystoken.ResolveRotatedTraps(deceptionPolicy, resolvedTraps, now)
+		for _, resource := range resources {
+			annotationChange, err := annotations.GetAnnotationChange(resource, deceptionPolicy.Name)
+			if err != nil {
+				joinedErrors = errors.Join(joinedErrors, err)
+				continue
+			}
+
+			for _, annotationTrap := range annotationChange.Traps {
+				decoyIndex := slices.IndexFunc(decoyTraps, func(decoyTrap v1alpha1.Trap) bool { return annotations.AreTheSameTrap(annotationTrap, decoyTrap) })
+				if decoyIndex < 0 {
+					continue
+				}
+
+				rd := r.buildFilesystemTokenReconciler(deceptionPolicy)
+				verification := filesystoken.DecoyVerification{
+					Checksum:           findDecoyChecksum(deceptionPolicy, trap, decoyTraps[decoyIndex]),
+					ReadContainerFiles: readContainerFiles,
+				}
+				containerDrifts, err := rd.VerifyDecoy(ctx, decoyTraps[decoyIndex], annotationTrap, resource, verification)
+				if err != nil {
+					joinedErrors = errors.Join(joinedErrors, err)
+					continue
+				} else if len(containerDrifts) == 0 {
+					continue
+				}
+
+				for _, container := range annotationTrap.Containers {
+					if reason, drifted := containerDrifts[container]; drifted {
+						// Changing the content of a decoy (or removing it from the container) hints at an attacker covering their tracks
+						if reason == filesystoken.DriftReasonContentTampered || reason == filesystoken.DriftReasonFileMissing {
+							numTamperings++
+							if r.Recorder != nil {
+								r.Recorder.Eventf(deceptionPolicy, corev1.EventTypeWarning, constants.EventReasonDecoyTampered,
+									"Decoy %s in container %s of %s %s/%s was tampered with (%s)", annotationTrap.FilesystemHoneytoken.FilePath,
+									container, utils.ResourceKind(resource), resource.GetNamespace(), resource.GetName(), reason)
+							}
+
+							// Tampering is alerted on like an access of the trap, so that the alert reaches the sinks of the trap
+							tampering := filesystoken.NewDecoyTampering(deceptionPolicy, trap, annotationTrap.FilesystemHoneytoken.FilePath,
+								resource, container, reason, now)
+							if err := r.reportDecoyTampering(ctx, tampering); err != nil {
+								// The drift is not forgotten, so that the tampering is reported again with the next verification
+								log.Error(err, "unable to report tampered decoy", "resource", resource.GetName(), "container", container)
+								joinedErrors = errors.Join(joinedErrors, err)
+								delete(containerDrifts, container)
+							}
+						}
+						drifts[hashTrap(trap)] = append(drifts[hashTrap(trap)], v1alpha1.TrapDrift{
+							Kind:      utils.ResourceKind(resource),
+							Namespace: resource.GetNamespace(),
+							Name:      resource.GetName(),
+							Container: container,
+							FilePath:  annotationTrap.FilesystemHoneytoken.FilePath,
+							Reason:    reason,
+						})
+					}
+				}
+
+				if err := r.forgetDriftedDecoy(ctx, deceptionPolicy.Name, annotationTrap, resource, containerDrifts); err != nil {
+					log.Error(err, "unable to forget drifted decoy", "resource", resource.GetName())
+					joinedErrors = errors.Join(joinedErrors, err)
+				}
+			}
+		}
+	}
+
+	return drifts, joinedErrors
+}
+
+// findDecoyChecksum returns the checksum of a decoy of a trap that was recorded in the status of the DeceptionPolicy when it was placed.
+// Checksums of other content (e.g., of the previous generation of a rotating honeytoken) are ignored, since the decoy was replaced since.
+func findDecoyChecksum(deceptionPolicy *v1alpha1.DeceptionPolicy, trap, decoyTrap v1alpha1.Trap) string {
+	trapHash := hashTrap(trap)
+	for _, trapStatus := range deceptionPolicy.Status.Traps {
+		if trapStatus.TrapHash != trapHash {
+			continue
+		}
+		if checksum := trapStatus.ChecksumOf(decoyTrap.FilesystemHoneytoken.FilePath); checksum == utils.SHA256(decoyTrap.FilesystemHoneytoken.FileContent) {
+			return checksum
+		}
+	}
+	return ""
+}
+
+// forgetDriftedDecoy removes the containers in which a decoy drifted from the trap annotation of a resource,
+// so that the decoy is not considered as deployed to these containers anymore.
+func (r *DeceptionPolicyReconciler) forgetDriftedDecoy(ctx context.Context, deceptionPolicyName string, annotationTrap v1alpha1.TrapAnnotation,
+	resource client.Object, driftedContainers map[string]string) error {
+	containersWithTrap := []string{}
+	for _, container := range annotationTrap.Containers {
+		if _, drifted := driftedContainers[container]; !drifted {
+			containersWithTrap = append(containersWithTrap, container)
+		}
+	}
+
+	// The trap stays in the annotation even if it drifted in all containers, to keep the node scope label of the resource
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
+			return err
+		}
+
+		if err := annotations.UpdateContainersInAnnotations(resource, deceptionPolicyName, annotationTrap, containersWithTrap); err != nil {
+			return err
+		}
+
+		return r.Update(ctx, resource)
+	})
+}
+
+// buildDecoysVerifiedCondition returns the status condition that reports whether the latest verification found
+// decoys that drifted. If the verification failed, the condition reports the error instead.
+func buildDecoysVerifiedCondition(drifts map[string][]v1alpha1.TrapDrift, err error) v1alpha1.DeceptionPolicyCondition {
+	condition := v1alpha1.DeceptionPolicyCondition{
+		Type:               DecoysVerifiedType,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             DecoysVerifiedReason_Intact,
+		Message:            DecoysVerifiedMessage_Intact,
+	}
+
+	if err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = DecoysVerifiedReason_Error
+		condition.Message = err.Error()
+		return condition
+	}
+
+	numDrifts := 0
+	for _, trapDrifts := range drifts {
+		numDrifts += len(trapDrifts)
+	}
+	if numDrifts > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = DecoysVerifiedReason_Drifted
+		condition.Message = fmt.Sprintf("%d decoys drifted and are deployed again - see the drifts of the traps in the status", numDrifts)
+	}
+
+	return condition
+}
+
+// reportDecoyTampering reports a tampered decoy with the DecoyTamperingReporter of the reconciler,
+// or to the tampering handler of the alert forwarder, which alerts the sinks of the trap.
+func (r *DeceptionPolicyReconciler) reportDecoyTampering(ctx context.Context, tampering filesystoken.DecoyTampering) error {
+	if r.DecoyTamperingReporter != nil {
+		return r.DecoyTamperingReporter.ReportTampering(ctx, tampering)
+	}
+
+	httpClient, err := r.alertForwarderClient(ctx)
+	if err != nil {
+		return err
+	}
+	reporter := &filesystoken.HTTPDecoyTamperingReporter{URL: filesystoken.BuildTamperingHandlerUrl(), Client: httpClient}
+	return reporter.ReportTampering(ctx, tampering)
+}
+
+// alertForwarderClient returns an HTTP client for the alert forwarder. If the alert forwarder uses TLS,
+// the client trusts the CA in the certificate secret of the alert forwarder, or returns nil to use the default client otherwise.
+func (r *DeceptionPolicyReconciler) alertForwarderClient(ctx context.Context) (*http.Client, error) {
+	if !utils.IsAlertForwarderTLSEnabled() {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: utils.GetAlertForwarderCertSecretName()}
+	if err := r.apiReader().Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("unable to read the certificate secret of the alert forwarder: %w", err)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(secret.Data["ca.crt"]) {
+		return nil, fmt.Errorf("the certificate secret %s of the alert forwarder has no valid CA", key.Name)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport, Timeout: 10 * time.Second}, nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("buildDecoysVerifiedCondition", func() {
+	It("should report intact decoys", func() {
+		condition := buildDecoysVerifiedCondition(map[string][]v1alpha1.TrapDrift{}, nil)
+		Expect(condition.Type).To(Equal(DecoysVerifiedType))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(DecoysVerifiedReason_Intact))
+	})
+
+	It("should count drifted decoys of all traps", func() {
+		condition := buildDecoysVerifiedCondition(map[string][]v1alpha1.TrapDrift{
+			"a": {{Name: "api", Container: "app", Reason: "ContainerRestarted"}, {Name: "api", Container: "sidecar", Reason: "ContainerRestarted"}},
+			"b": {{Name: "web", Container: "app", Reason: "SecretMissing"}},
+		}, nil)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(DecoysVerifiedReason_Drifted))
+		Expect(condition.Message).To(HavePrefix("3 decoys drifted"))
+	})
+
+	It("should report errors of the verification", func() {
+		condition := buildDecoysVerifiedCondition(nil, errors.New("unable to list pods"))
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(DecoysVerifiedReason_Error))
+		Expect(condition.Message).To(Equal("unable to list pods"))
+	})
+})
+
+var _ = Describe("decoy checksums", func() {
+	var (
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		trap            v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		trap = v1alpha1.Trap{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/token", FileContent: "token"}}
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-checksums"}}
+	})
+
+	It("should record the checksums of placed decoys only", func() {
+		other := trap
+		other.FilesystemHoneytoken.FilePath = "/etc/other"
+		placements := []v1alpha1.TrapPlacement{{Kind: "Pod", Name: "web", FilePaths: []string{trap.FilesystemHoneytoken.FilePath}}}
+
+		Expect(buildDecoyChecksums([]v1alpha1.Trap{trap, other}, placements)).To(ConsistOf(v1alpha1.DecoyChecksum{
+			FilePath: trap.FilesystemHoneytoken.FilePath,
+			SHA256:   "3c469e9d6c5875d37a43f353d4f88e61fcf812c66eee3457465a40b0da4153e0",
+		}))
+	})
+
+	It("should record the values of decoys that reference the placement context", func() {
+		templated := trap
+		templated.FilesystemHoneytoken.FileContent = "token-" + v1alpha1.PlacementIDPlaceholder
+		web := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "koney", Name: "web"}}
+		api := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "koney", Name: "api"}}
+		placements := []v1alpha1.TrapPlacement{{Kind: "Pod", Namespace: "koney", Name: "web", FilePaths: []string{trap.FilesystemHoneytoken.FilePath}}}
+
+		tokenPlacements, omitted := buildTokenPlacements([]client.Object{web, api}, []v1alpha1.Trap{trap}, placements)
+		Expect(tokenPlacements).To(BeEmpty())
+		Expect(omitted).To(BeZero())
+
+		tokenPlacements, omitted = buildTokenPlacements([]client.Object{web, api}, []v1alpha1.Trap{templated}, placements)
+		Expect(tokenPlacements).To(Equal([]v1alpha1.TokenPlacement{
+			filesystoken.BuildTokenPlacement(templated, *web),
+		}))
+		Expect(omitted).To(BeZero())
+	})
+
+	It("should only record a limited number of token placements", func() {
+		templated := trap
+		templated.FilesystemHoneytoken.FileContent = "token-" + v1alpha1.PlacementIDPlaceholder
+		var resources []client.Object
+		var placements []v1alpha1.TrapPlacement
+		for i := range v1alpha1.TokenPlacementsLimit + 5 {
+			name := fmt.Sprintf("web-%d", i)
+			resources = append(resources, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "koney", Name: name}})
+			placements = append(placements, v1alpha1.TrapPlacement{Kind: "Pod", Namespace: "koney", Name: name, FilePaths: []string{trap.FilesystemHoneytoken.FilePath}})
+		}
+
+		tokenPlacements, omitted := buildTokenPlacements(resources, []v1alpha1.Trap{templated}, placements)
+		Expect(tokenPlacements).To(HaveLen(v1alpha1.TokenPlacementsLimit))
+		Expect(tokenPlacements[0].Pod).To(Equal("web-0"))
+		Expect(omitted).To(Equal(5))
+	})
+
+	It("should only use recorded checksums of the current content", func() {
+		deceptionPolicy.Status.Traps = []v1alpha1.TrapStatus{{
+			TrapHash:  hashTrap(trap),
+			Checksums: buildDecoyChecksums([]v1alpha1.Trap{trap}, []v1alpha1.TrapPlacement{{FilePaths: []string{trap.FilesystemHoneytoken.FilePath}}}),
+		}}
+		Expect(findDecoyChecksum(deceptionPolicy, trap, trap)).To(Equal(utils.SHA256("token")))
+
+		rotated := trap
+		rotated.FilesystemHoneytoken.FileContent = "rotated token"
+		Expect(findDecoyChecksum(deceptionPolicy, trap, rotated)).To(BeEmpty())
+	})
+
+	It("should read decoys back from containers once per verification interval", func() {
+		var checks decoyContentChecks
+		now := time.Now()
+
+		Expect(checks.due(deceptionPolicy.Name, now)).To(BeTrue())
+		Expect(checks.due(deceptionPolicy.Name, now.Add(time.Minute))).To(BeFalse())
+		Expect(checks.due(deceptionPolicy.Name, now.Add(constants.DecoyVerificationInterval))).To(BeTrue())
+
+		checks.forget(deceptionPolicy.Name)
+		Expect(checks.due(deceptionPolicy.Name, now)).To(BeTrue())
+	})
+})
+
+type recordingTamperingReporter struct {
+	tamperings []filesystoken.DecoyTampering
+}
+
+func (r *recordingTamperingReporter) ReportTampering(_ context.Context, tampering filesystoken.DecoyTampering) error {
+	r.tamperings = append(r.tamperings, tampering)
+	return nil
+}
+
+var _ = Describe("decoy tampering reports", func() {
+	It("should report tamperings with the reporter of the reconciler", func() {
+		reporter := &recordingTamperingReporter{}
+		reconciler := &DeceptionPolicyReconciler{DecoyTamperingReporter: reporter}
+		tampering := filesystoken.DecoyTampering{Reason: filesystoken.DriftReasonFileMissing, Kind: "Pod", Name: "web"}
+
+		Expect(reconciler.reportDecoyTampering(context.Background(), tampering)).To(Succeed())
+		Expect(reporter.tamperings).To(ConsistOf(tampering))
+	})
+
+	It("should use the default client for the alert forwarder without TLS", func() {
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TLS", "false")
+		httpClient, err := (&DeceptionPolicyReconciler{}).alertForwarderClient(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(httpClient).To(BeNil())
+	})
+
+	It("should fail if the certificate secret of the alert forwarder has no CA", func() {
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TLS", "true")
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: utils.GetAlertForwarderCertSecretName(), Namespace: utils.GetKoneyNamespace()}}
+		reconciler := &DeceptionPolicyReconciler{Client: fake.NewClientBuilder().WithObjects(secret).Build()}
+
+		_, err := reconciler.alertForwarderClient(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("has no valid CA")))
+	})
+})
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+)
+
+// DecoyGarbageCollector periodically deletes the secrets of decoys that are not mounted anymore, and removes the decoy volumes
+// from deployments that no trap accounts for anymore. The DeceptionPolicy controller removes the decoys of removed traps,
+// but the secrets and volumes that they leave behind might still be used by other traps or pods, so they are collected separately.
+type DecoyGarbageCollector struct {
+	client.Client
+
+	// APIReader reads the pods and workloads that mount secrets directly from the API server, so that workloads
+	// that are not cached (e.g., jobs) are taken into account, too. If it is not set, the client is used.
+	APIReader client.Reader
+
+	// Interval is the time between two collections.
+	Interval time.Duration
+}
+
+// Start collects orphaned secrets and volumes every interval until the context is done.
+func (c *DecoyGarbageCollector) Start(ctx context.Context) error {
+	log := k8slog.FromContext(ctx).WithName("decoy-garbage-collector")
+	ctx = k8slog.IntoContext(ctx, log)
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.Collect(ctx, time.Now()); err != nil {
+				log.Error(err, "Unable to collect orphaned secrets and volumes")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns true, so that only the leader deletes secrets and volumes.
+func (c *DecoyGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect removes the decoy volumes that no trap accounts for anymore first, so that their secrets are collected right away.
+func (c *DecoyGarbageCollector) Collect(ctx context.Context, now time.Time) error {
+	var reader client.Reader = c.Client
+	if c.APIReader != nil {
+		reader = c.APIReader
+	}
+
+	return errors.Join(
+		filesystoken.CollectOrphanedVolumes(ctx, c),
+		filesystoken.CollectOrphanedSecrets(ctx, reader, c, now),
+	)
+}
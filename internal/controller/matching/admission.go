@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package matching
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// GetMatchingContainersOfPod returns the containers of a single pod that match the given MatchResources.
+// Unlike the other matching functions, the pod does not need to exist in the cluster yet, so that pods can be matched
+// while they are admitted. Node constraints are not supported, because pods are not scheduled to nodes yet at that time.
+// Resources are matched with a logical OR between different ResourceFilters and a logical AND within a ResourceFilter.
+func GetMatchingContainersOfPod(r client.Reader, ctx context.Context, pod *corev1.Pod, matchResources v1alpha1.MatchResources) ([]string, error) {
+	matchingContainers := []string{}
+
+	for _, resourceFilter := range matchResources.Any {
+		matched, err := podMatchesResourceFilter(r, ctx, pod, resourceFilter)
+		if err != nil {
+			return nil, err
+		} else if !matched {
+			continue
+		}
+
+		selectedContainers, err := selectContainers(pod, resourceFilter.ResourceDescription)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, container := range selectedContainers {
+			if !utils.Contains(matchingContainers, container) {
+				matchingContainers = append(matchingContainers, container)
+			}
+		}
+	}
+
+	return matchingContainers, nil
+}
+
+// podMatchesResourceFilter checks if a pod matches the namespaces, labels, and workloads of a resource filter.
+// An empty resource filter matches no pods, consistent with getMatchingObjectsByNamespaceAndLabels.
+func podMatchesResourceFilter(r client.Reader, ctx context.Context, pod *corev1.Pod, resourceFilter v1alpha1.ResourceFilter) (bool, error) {
+	hasLabels := resourceFilter.Selector != nil && len(resourceFilter.Selector.MatchLabels) > 0
+	if len(resourceFilter.Namespaces) == 0 && !hasLabels && len(resourceFilter.Workloads) == 0 {
+		return false, nil
+	}
+
+	if len(resourceFilter.Namespaces) > 0 && !utils.Contains(resourceFilter.Namespaces, pod.Namespace) {
+		return false, nil
+	}
+
+	if hasLabels && !labels.SelectorFromSet(resourceFilter.Selector.MatchLabels).Matches(labels.Set(pod.Labels)) {
+		return false, nil
+	}
+
+	if len(resourceFilter.Workloads) > 0 {
+		return podBelongsToWorkloads(r, ctx, pod, resourceFilter)
+	}
+
+	return true, nil
+}
+
+// podBelongsToWorkloads checks if the pod is selected by the pod selector of one of the workloads of the resource filter.
+func podBelongsToWorkloads(r client.Reader, ctx context.Context, pod *corev1.Pod, resourceFilter v1alpha1.ResourceFilter) (bool, error) {
+	for _, workloadRef := range resourceFilter.Workloads {
+		workloads, err := getWorkloads(r, ctx, workloadRef, resourceFilter.Namespaces)
+		if err != nil {
+			return false, err
+		}
+
+		for _, workload := range workloads {
+			if workload.GetNamespace() != pod.Namespace {
+				continue
+			}
+
+			podSelector := podSelectorOfWorkload(workload)
+			if podSelector == nil {
+				continue
+			}
+
+			selector, err := metav1.LabelSelectorAsSelector(podSelector)
+			if err != nil {
+				return false, fmt.Errorf("invalid pod selector of %s '%s': %w", workloadRef.Kind, workload.GetName(), err)
+			}
+
+			if selector.Matches(labels.Set(pod.Labels)) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package matching
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("Admission matching", func() {
+	var fakeClient client.Client
+	var ctx context.Context
+
+	const AppNamespace = "koney-demo"
+
+	// Pods that are admitted do not exist in the cluster yet, so they are not added to the fake client
+	newPod := func(namespace string, labels map[string]string, containers ...string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Labels: labels}}
+		for _, container := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+		}
+		return pod
+	}
+
+	matchResources := func(resourceDescriptions ...v1alpha1.ResourceDescription) v1alpha1.MatchResources {
+		matchResources := v1alpha1.MatchResources{}
+		for _, resourceDescription := range resourceDescriptions {
+			matchResources.Any = append(matchResources.Any, v1alpha1.ResourceFilter{ResourceDescription: resourceDescription})
+		}
+		return matchResources
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		fakeClient = fake.NewClientBuilder().WithObjects(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: AppNamespace},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				},
+			},
+		).Build()
+	})
+
+	It("should match a pod by its namespace and labels", func() {
+		pod := newPod(AppNamespace, map[string]string{"app": "api", "tier": "backend"}, "app", "sidecar")
+
+		containers, err := GetMatchingContainersOfPod(fakeClient, ctx, pod, matchResources(v1alpha1.ResourceDescription{
+			Namespaces: []string{AppNamespace},
+			Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(ConsistOf("app", "sidecar"))
+	})
+
+	It("should not match a pod in another namespace or with other labels", func() {
+		pod := newPod("other", map[string]string{"app": "api"}, "app")
+
+		containers, err := GetMatchingContainersOfPod(fakeClient, ctx, pod, matchResources(
+			v1alpha1.ResourceDescription{Namespaces: []string{AppNamespace}},
+			v1alpha1.ResourceDescription{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+		))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(BeEmpty())
+	})
+
+	It("should match a pod by the pod selector of a workload", func() {
+		resourceDescription := v1alpha1.ResourceDescription{
+			Workloads: []v1alpha1.WorkloadReference{{Kind: v1alpha1.DeploymentWorkload, Name: "api"}},
+		}
+
+		containers, err := GetMatchingContainersOfPod(fakeClient, ctx,
+			newPod(AppNamespace, map[string]string{"app": "api"}, "app"), matchResources(resourceDescription))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(ConsistOf("app"))
+
+		containers, err = GetMatchingContainersOfPod(fakeClient, ctx,
+			newPod("other", map[string]string{"app": "api"}, "app"), matchResources(resourceDescription))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(BeEmpty())
+	})
+
+	It("should only return containers that match the container selector, without duplicates", func() {
+		pod := newPod(AppNamespace, map[string]string{"app": "api"}, "app", "sidecar")
+
+		containers, err := GetMatchingContainersOfPod(fakeClient, ctx, pod, matchResources(
+			v1alpha1.ResourceDescription{Namespaces: []string{AppNamespace}, ContainerSelector: "app"},
+			v1alpha1.ResourceDescription{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}, ContainerSelector: "a*"},
+		))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(Equal([]string{"app"}))
+	})
+
+	It("should not match anything with an empty resource filter", func() {
+		pod := newPod(AppNamespace, map[string]string{"app": "api"}, "app")
+
+		containers, err := GetMatchingContainersOfPod(fakeClient, ctx, pod, matchResources(v1alpha1.ResourceDescription{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(BeEmpty())
+	})
+})
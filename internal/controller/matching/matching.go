@@ -48,7 +48,7 @@ type MatchingResult struct {
 // - If a createdAfter timestamp is given, only resources created after the given timestamp are returned.
 // Additionally, the function filters out resources that are not ready, e.g., pods that are just starting, not ready, or terminating.
 //
-// The deployment strategy determines which resources are returned: pods (if the strategy is containerExec) or deployments (if the strategy is volumeMount).
+// The deployment strategy determines which resources are returned: pods (if the strategy is containerExec, ephemeralContainer, or admission) or deployments (if the strategy is volumeMount, projectedVolume, imageVolume, or initContainer).
 // The function returns a matching result and an error. The matching result reports if at least one object matched the three criteria above,
 // and if all of those objects were also ready. The final set of deployable objects both matches all criteria and is ready.
 func GetDeployableObjectsWithContainers(r client.Reader, ctx context.Context, trap v1alpha1.Trap, createdAfter *metav1.Time) (MatchingResult, error) {
@@ -60,7 +60,7 @@ func GetDeployableObjectsWithContainers(r client.Reader, ctx context.Context, tr
 	)
 
 	switch trap.DecoyDeployment.Strategy {
-	case "containerExec":
+	case "containerExec", "ephemeralContainer", "admission":
 		matchingObjects, err = getMatchingPodsWithContainers(r, ctx, trap.MatchResources)
 		matchingObjects = filterObjectsWithoutDeletionTimestamp(matchingObjects)
 		if createdAfter != nil {
@@ -68,7 +68,7 @@ func GetDeployableObjectsWithContainers(r client.Reader, ctx context.Context, tr
 		}
 
 		filteredObjects, allObjectsReady = filterPodsReadyForTraps(matchingObjects)
-	case "volumeMount":
+	case "volumeMount", "projectedVolume", "imageVolume", "initContainer":
 		matchingObjects, err = getMatchingDeploymentsWithContainers(r, ctx, trap.MatchResources)
 		matchingObjects = filterObjectsWithoutDeletionTimestamp(matchingObjects)
 		if createdAfter != nil {
@@ -118,7 +118,7 @@ func getMatchingObjectsWithContainers(r client.Reader, ctx context.Context, matc
 		}
 
 		for _, matchingObject := range matchingObjects {
-			selectedContainers, err := selectContainers(matchingObject, resourceFilter.ContainerSelector)
+			selectedContainers, err := selectContainers(matchingObject, resourceFilter.ResourceDescription)
 			if err != nil {
 				return nil, err
 			} else if len(selectedContainers) == 0 {
@@ -149,7 +149,7 @@ func getMatchingObjectsWithContainers(r client.Reader, ctx context.Context, matc
 }
 
 // getMatchingObjectsByNamespaceAndLabels returns a list of objects (pods or deployments)
-// that match the given resource filter with a logical AND between the namespaces and labels.
+// that match the given resource filter with a logical AND between the namespaces, labels, workloads, and nodes.
 func getMatchingObjectsByNamespaceAndLabels(r client.Reader, ctx context.Context, resourceFilter v1alpha1.ResourceFilter, makeList func() client.ObjectList) ([]client.Object, error) {
 	matchingObjects := []client.Object{} // The objects that match the MatchResources
 
@@ -159,6 +159,10 @@ func getMatchingObjectsByNamespaceAndLabels(r client.Reader, ctx context.Context
 	if len(resourceFilter.Namespaces) > 0 {
 		// Get the objects that match one of the namespaces
 		for _, namespace := range resourceFilter.Namespaces {
+			if !utils.IsWatchedNamespace(namespace) {
+				continue // Traps are never deployed in namespaces that Koney does not watch
+			}
+
 			items := []client.Object{}
 			if err := listItemsAsObjects(r, ctx, &items, makeList(), client.InNamespace(namespace)); err != nil {
 				return nil, err
@@ -215,6 +219,25 @@ func getMatchingObjectsByNamespaceAndLabels(r client.Reader, ctx context.Context
 		}
 	}
 
+	// If workloads are specified, only keep the objects that belong to one of the workloads (logical AND with namespaces and labels)
+	if len(resourceFilter.Workloads) > 0 {
+		matchingByWorkloads, err := getMatchingObjectsByWorkloads(r, ctx, resourceFilter, makeList)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resourceFilter.Namespaces) == 0 && (resourceFilter.Selector == nil || len(resourceFilter.Selector.MatchLabels) == 0) {
+			matchingObjects = matchingByWorkloads
+		} else {
+			matchingObjects = intersectObjects(matchingObjects, matchingByWorkloads)
+		}
+	}
+
+	// If node constraints are specified, only keep the pods that are scheduled to matching nodes (logical AND with everything else)
+	if resourceFilter.HasNodeConstraints() {
+		return filterObjectsByNodeConstraints(r, ctx, matchingObjects, resourceFilter)
+	}
+
 	return matchingObjects, nil
 }
 
@@ -296,10 +319,10 @@ func filterDeploymentsReadyForTraps(objects map[client.Object][]string) (map[cli
 }
 
 // selectContainers selects the container(s) in a Kubernetes resource based
-// on the containerSelector. containerSelector can be a wildcard
-// and can include wildcards inside the string.
+// on the container selector, the included and excluded containers, and the image selector of the resource description.
+// Patterns can be wildcards and can include wildcards inside the string.
 // The function returns a list of container names that match the selector.
-func selectContainers(resource client.Object, containerSelector string) ([]string, error) {
+func selectContainers(resource client.Object, description v1alpha1.ResourceDescription) ([]string, error) {
 	var containers []corev1.Container
 	switch resource := resource.(type) {
 	case *corev1.Pod:
@@ -312,20 +335,20 @@ func selectContainers(resource client.Object, containerSelector string) ([]strin
 
 	selectedContainers := []string{}
 
-	if ContainerSelectorSelectsAll(containerSelector) {
-		for _, container := range containers {
-			selectedContainers = append(selectedContainers, container.Name)
-		}
-		return selectedContainers, nil
-	}
-
 	for _, container := range containers {
 
-		matched, err := utils.MatchContainerName(containerSelector, container.Name)
+		matched, err := description.MatchContainer(container.Name)
 		if err != nil {
 			return []string{}, fmt.Errorf("invalid container selector: %w", err)
 		}
 
+		if matched {
+			matched, err = description.MatchContainerImage(container.Image)
+			if err != nil {
+				return []string{}, fmt.Errorf("invalid image selector: %w", err)
+			}
+		}
+
 		if matched {
 			selectedContainers = append(selectedContainers, container.Name)
 		}
@@ -1192,81 +1192,153 @@ var _ = Describe("selectContainers", func() {
 		})
 
 		It("should select a single container", func() {
-			selection, err := selectContainers(&pod, "foo")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "foo"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("foo"))
 		})
 
 		It("should select no containers", func() {
-			selection, err := selectContainers(&pod, "non-existing")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "non-existing"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(BeEmpty())
 		})
 
 		It("regex should select a single container", func() {
-			selection, err := selectContainers(&pod, "regex:foo")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:foo"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("foo"))
 		})
 
 		It("regex should select no containers", func() {
-			selection, err := selectContainers(&pod, "regex:non-existing")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:non-existing"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(BeEmpty())
 		})
 
 		It("regex should select all containers", func() {
-			selection, err := selectContainers(&pod, "regex:.*")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:.*"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("foo", "bar", "baz", "quz"))
 		})
 
 		It("regex should select containers starting with some string", func() {
-			selection, err := selectContainers(&pod, "regex:^b")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:^b"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("bar", "baz"))
 		})
 
 		It("regex should select containers ending with some string", func() {
-			selection, err := selectContainers(&pod, "regex:z$")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:z$"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("baz", "quz"))
 		})
 
 		It("regex should select containers containing some string", func() {
-			selection, err := selectContainers(&pod, "regex:a")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:a"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("bar", "baz"))
 		})
 
 		It("regex should perform a substring match, not a full-string match", func() {
-			selection, err := selectContainers(&pod, "regex:oo")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "regex:oo"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("foo"))
 		})
 
 		It("glob should select all containers", func() {
-			selection, err := selectContainers(&pod, "glob:*")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "glob:*"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("foo", "bar", "baz", "quz"))
 		})
 
 		It("glob should select containers starting with some string", func() {
-			selection, err := selectContainers(&pod, "glob:b*")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "glob:b*"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("bar", "baz"))
 		})
 
 		It("glob should select containers ending with some string", func() {
-			selection, err := selectContainers(&pod, "glob:*z")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "glob:*z"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("baz", "quz"))
 		})
 
 		It("glob should select containers containing some string", func() {
-			selection, err := selectContainers(&pod, "glob:*a*")
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "glob:*a*"})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(selection).To(ConsistOf("bar", "baz"))
 		})
 	})
+
+	Context("With a pod that has injected sidecars", func() {
+		BeforeEach(func() {
+			pod = corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app"},
+						{Name: "worker"},
+						{Name: "istio-proxy"},
+						{Name: "linkerd-proxy"},
+					},
+				},
+			}
+		})
+
+		It("should not select sidecars by default", func() {
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("app", "worker"))
+
+			selection, err = selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "glob:*"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("app", "worker"))
+		})
+
+		It("should select sidecars by their exact name", func() {
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ContainerSelector: "istio-proxy"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("istio-proxy"))
+		})
+
+		It("should select sidecars if they are included explicitly", func() {
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{
+				Containers: &v1alpha1.ContainerSelection{IncludeSidecars: true},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("app", "worker", "istio-proxy", "linkerd-proxy"))
+		})
+
+		It("should select containers that match any included and no excluded pattern", func() {
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{
+				Containers: &v1alpha1.ContainerSelection{
+					Include:         []string{"app", "regex:proxy$"},
+					Exclude:         []string{"glob:linkerd-*"},
+					IncludeSidecars: true,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("app", "istio-proxy"))
+		})
+
+		It("should select containers by their image", func() {
+			pod.Spec.Containers[0].Image = "docker.io/library/postgres:16"
+			pod.Spec.Containers[1].Image = "postgres:15"
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{ImageSelector: "postgres"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("worker"))
+
+			selection, err = selectContainers(&pod, v1alpha1.ResourceDescription{ImageSelector: "regex:(^|/)postgres:"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("app", "worker"))
+		})
+
+		It("should apply excluded patterns to the container selector", func() {
+			selection, err := selectContainers(&pod, v1alpha1.ResourceDescription{
+				ContainerSelector: "regex:.*",
+				Containers:        &v1alpha1.ContainerSelection{Exclude: []string{"worker"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selection).To(ConsistOf("app"))
+		})
+	})
 })
@@ -27,13 +27,18 @@ func ContainerSelectorSelectsAll(containerSelector string) bool {
 	return containerSelector == "regex:.*" || containerSelector == "" || containerSelector == "glob:*"
 }
 
+// ContainerSelectorIsPattern reports whether the given containerSelector is a regex or glob pattern, rather than an exact container name.
+func ContainerSelectorIsPattern(containerSelector string) bool {
+	return strings.HasPrefix(containerSelector, "regex:") || strings.HasPrefix(containerSelector, "glob:")
+}
+
 // ContainerSelectorNeedsClientFiltering reports whether the given containerSelector contains a pattern
 // that captors like Tetragon cannot evaluate natively, i.e., a regex or glob pattern that is not equivalent to "match all".
 func ContainerSelectorNeedsClientFiltering(containerSelector string) bool {
 	if ContainerSelectorSelectsAll(containerSelector) {
 		return false
 	}
-	return strings.HasPrefix(containerSelector, "regex:") || strings.HasPrefix(containerSelector, "glob:")
+	return ContainerSelectorIsPattern(containerSelector)
 }
 
 // extractObjectNames is a helper function that extracts the names of the objects from a list of objects.
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package matching
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// filterObjectsByNodeConstraints only keeps pods that are scheduled to nodes that match the node constraints of the given resource filter.
+// Pods that are not scheduled yet are kept, because they are not ready for traps anyway and are matched again once they are scheduled.
+// Other objects (e.g., deployments) are not bound to nodes and thus never match node constraints.
+func filterObjectsByNodeConstraints(r client.Reader, ctx context.Context, objects []client.Object, resourceFilter v1alpha1.ResourceFilter) ([]client.Object, error) {
+	filteredObjects := []client.Object{}
+	nodeMatches := map[string]bool{} // Cache the result per node, since many pods run on the same node
+
+	for _, object := range objects {
+		pod, ok := object.(*corev1.Pod)
+		if !ok {
+			continue
+		}
+
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			filteredObjects = append(filteredObjects, object)
+			continue
+		}
+
+		matches, cached := nodeMatches[nodeName]
+		if !cached {
+			node := &corev1.Node{}
+			if err := r.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					return nil, err
+				}
+				// The node is gone, so its pods are about to be deleted as well
+				nodeMatches[nodeName] = false
+				continue
+			}
+
+			var err error
+			if matches, err = nodeMatchesConstraints(node, resourceFilter.ResourceDescription); err != nil {
+				return nil, err
+			}
+			nodeMatches[nodeName] = matches
+		}
+
+		if matches {
+			filteredObjects = append(filteredObjects, object)
+		}
+	}
+
+	return filteredObjects, nil
+}
+
+// nodeMatchesConstraints returns true if the node has all labels of the node selector,
+// and matches at least one of the node selector terms of the node affinity (if any).
+func nodeMatchesConstraints(node *corev1.Node, description v1alpha1.ResourceDescription) (bool, error) {
+	if !labels.SelectorFromSet(description.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false, nil
+	}
+
+	if description.NodeAffinity == nil {
+		return true, nil
+	}
+
+	for _, term := range description.NodeAffinity.NodeSelectorTerms {
+		matches, err := nodeMatchesSelectorTerm(node, term)
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// nodeMatchesSelectorTerm returns true if the node matches all expressions and fields of the node selector term.
+// Like in the Kubernetes scheduler, a term without any expressions and fields matches no nodes.
+func nodeMatchesSelectorTerm(node *corev1.Node, term corev1.NodeSelectorTerm) (bool, error) {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false, nil
+	}
+
+	labelSelector, err := nodeSelectorRequirementsAsSelector(term.MatchExpressions)
+	if err != nil {
+		return false, err
+	}
+	if !labelSelector.Matches(labels.Set(node.Labels)) {
+		return false, nil
+	}
+
+	// The only field that is supported by the Kubernetes scheduler is the node name
+	for _, field := range term.MatchFields {
+		if field.Key != "metadata.name" {
+			return false, fmt.Errorf("node selector field '%s' is not supported", field.Key)
+		}
+	}
+	fieldSelector, err := nodeSelectorRequirementsAsSelector(term.MatchFields)
+	if err != nil {
+		return false, err
+	}
+
+	return fieldSelector.Matches(labels.Set{"metadata.name": node.Name}), nil
+}
+
+// nodeSelectorRequirementsAsSelector converts node selector requirements to a label selector.
+func nodeSelectorRequirementsAsSelector(requirements []corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	selector := labels.NewSelector()
+
+	for _, requirement := range requirements {
+		var operator selection.Operator
+		switch requirement.Operator {
+		case corev1.NodeSelectorOpIn:
+			operator = selection.In
+		case corev1.NodeSelectorOpNotIn:
+			operator = selection.NotIn
+		case corev1.NodeSelectorOpExists:
+			operator = selection.Exists
+		case corev1.NodeSelectorOpDoesNotExist:
+			operator = selection.DoesNotExist
+		case corev1.NodeSelectorOpGt:
+			operator = selection.GreaterThan
+		case corev1.NodeSelectorOpLt:
+			operator = selection.LessThan
+		default:
+			return nil, fmt.Errorf("node selector operator '%s' is not supported", requirement.Operator)
+		}
+
+		labelRequirement, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector requirement for key '%s': %w", requirement.Key, err)
+		}
+		selector = selector.Add(*labelRequirement)
+	}
+
+	return selector, nil
+}
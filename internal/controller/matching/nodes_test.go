@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package matching
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("Node matching", func() {
+	var fakeClient client.Client
+	var ctx context.Context
+
+	const AppNamespace = "koney-demo"
+
+	scheduledPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: AppNamespace, Labels: map[string]string{"app": "api"}},
+			Spec:       corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{Name: "app"}}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+		}
+	}
+
+	node := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+
+	nodeTrap := func(resourceDescription v1alpha1.ResourceDescription) v1alpha1.Trap {
+		resourceDescription.Namespaces = []string{AppNamespace}
+		return v1alpha1.Trap{
+			DecoyDeployment: v1alpha1.DecoyDeployment{Strategy: "containerExec"},
+			MatchResources: v1alpha1.MatchResources{
+				Any: []v1alpha1.ResourceFilter{{ResourceDescription: resourceDescription}},
+			},
+		}
+	}
+
+	deployableNames := func(result MatchingResult) []string {
+		names := []string{}
+		for object := range result.DeployableObjects {
+			names = append(names, object.GetName())
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		pendingPod := scheduledPod("api-pending", "")
+		pendingPod.Status = corev1.PodStatus{Phase: corev1.PodPending}
+
+		fakeClient = fake.NewClientBuilder().WithObjects(
+			node("edge-1", map[string]string{"node-pool": "internet-facing", "zone": "a"}),
+			node("edge-2", map[string]string{"node-pool": "internet-facing", "zone": "b"}),
+			node("internal-1", map[string]string{"node-pool": "internal", "zone": "a"}),
+			scheduledPod("api-edge-1", "edge-1"),
+			scheduledPod("api-edge-2", "edge-2"),
+			scheduledPod("api-internal-1", "internal-1"),
+			scheduledPod("api-lost", "deleted-node"),
+			pendingPod,
+		).Build()
+	})
+
+	It("should only select pods on nodes with all labels of the node selector", func() {
+		trap := nodeTrap(v1alpha1.ResourceDescription{
+			NodeSelector: map[string]string{"node-pool": "internet-facing", "zone": "a"},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf("api-edge-1"))
+	})
+
+	It("should select pods on nodes that match any node selector term", func() {
+		trap := nodeTrap(v1alpha1.ResourceDescription{
+			NodeAffinity: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "node-pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"internet-facing"}},
+					{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"a"}},
+				}},
+				{MatchFields: []corev1.NodeSelectorRequirement{
+					{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"internal-1"}},
+				}},
+			}},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf("api-edge-2", "api-internal-1"))
+	})
+
+	It("should keep pods that are not scheduled yet as matched but not ready", func() {
+		trap := nodeTrap(v1alpha1.ResourceDescription{
+			NodeSelector: map[string]string{"node-pool": "internal"},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf("api-internal-1"))
+		Expect(result.AllDeployableObjectsWereReady).To(BeFalse())
+	})
+
+	It("should reject unsupported node selector fields", func() {
+		trap := nodeTrap(v1alpha1.ResourceDescription{
+			NodeAffinity: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchFields: []corev1.NodeSelectorRequirement{
+					{Key: "spec.unschedulable", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}},
+				}},
+			}},
+		})
+
+		_, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).To(MatchError(ContainSubstring("is not supported")))
+	})
+})
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package matching
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// ResolveWorkloadPodLabels returns the labels of the pod selectors of all workloads that are referenced in the given MatchResources.
+// Captors use these labels to only monitor the pods of the workloads. Workloads that cannot be found result in an error,
+// because a captor without these labels would monitor more pods than intended.
+func ResolveWorkloadPodLabels(r client.Reader, ctx context.Context, matchResources v1alpha1.MatchResources) (map[string]string, error) {
+	podLabels := map[string]string{}
+
+	for _, resourceFilter := range matchResources.Any {
+		for _, workloadRef := range resourceFilter.Workloads {
+			workloads, err := getWorkloads(r, ctx, workloadRef, resourceFilter.Namespaces)
+			if err != nil {
+				return nil, err
+			}
+			if len(workloads) == 0 {
+				return nil, fmt.Errorf("workload %s '%s' not found", workloadRef.Kind, workloadRef.Name)
+			}
+
+			for _, workload := range workloads {
+				if selector := podSelectorOfWorkload(workload); selector != nil {
+					maps.Copy(podLabels, selector.MatchLabels)
+				}
+			}
+		}
+	}
+
+	return podLabels, nil
+}
+
+// getMatchingObjectsByWorkloads returns a list of objects (pods or deployments) that belong to the workloads of the given resource filter.
+// Pods are resolved with the pod selector of each workload, so that new pods are matched after rollouts.
+// Deployments are only returned if they are referenced directly, because other workloads cannot be mutated to deploy decoys.
+func getMatchingObjectsByWorkloads(r client.Reader, ctx context.Context, resourceFilter v1alpha1.ResourceFilter, makeList func() client.ObjectList) ([]client.Object, error) {
+	matchingObjects := []client.Object{}
+
+	for _, workloadRef := range resourceFilter.Workloads {
+		workloads, err := getWorkloads(r, ctx, workloadRef, resourceFilter.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, workload := range workloads {
+			switch makeList().(type) {
+			case *corev1.PodList:
+				selector, err := metav1.LabelSelectorAsSelector(podSelectorOfWorkload(workload))
+				if err != nil {
+					return nil, fmt.Errorf("invalid pod selector of %s '%s': %w", workloadRef.Kind, workload.GetName(), err)
+				}
+
+				items := []client.Object{}
+				if err := listItemsAsObjects(r, ctx, &items, makeList(),
+					client.InNamespace(workload.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+					return nil, err
+				}
+				matchingObjects = append(matchingObjects, items...)
+			case *appsv1.DeploymentList:
+				if deployment, ok := workload.(*appsv1.Deployment); ok {
+					matchingObjects = append(matchingObjects, deployment)
+				}
+			}
+		}
+	}
+
+	return matchingObjects, nil
+}
+
+// getWorkloads returns the workloads with the kind and name of the reference,
+// either in the given namespaces, or in all namespaces if no namespaces are given.
+func getWorkloads(r client.Reader, ctx context.Context, workloadRef v1alpha1.WorkloadReference, namespaces []string) ([]client.Object, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	workloads := []client.Object{}
+	for _, namespace := range namespaces {
+		if namespace != metav1.NamespaceAll && !utils.IsWatchedNamespace(namespace) {
+			continue
+		}
+
+		var items []client.Object
+
+		switch workloadRef.Kind {
+		case v1alpha1.DeploymentWorkload:
+			list := &appsv1.DeploymentList{}
+			if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				items = append(items, &list.Items[i])
+			}
+		case v1alpha1.StatefulSetWorkload:
+			list := &appsv1.StatefulSetList{}
+			if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				items = append(items, &list.Items[i])
+			}
+		case v1alpha1.DaemonSetWorkload:
+			list := &appsv1.DaemonSetList{}
+			if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				items = append(items, &list.Items[i])
+			}
+		default:
+			return nil, fmt.Errorf("invalid workload kind: %s", workloadRef.Kind)
+		}
+
+		for _, item := range items {
+			if item.GetName() == workloadRef.Name && item.GetDeletionTimestamp() == nil {
+				workloads = append(workloads, item)
+			}
+		}
+	}
+
+	return workloads, nil
+}
+
+// podSelectorOfWorkload returns the selector that a workload uses to find its pods.
+func podSelectorOfWorkload(workload client.Object) *metav1.LabelSelector {
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		return workload.Spec.Selector
+	case *appsv1.StatefulSet:
+		return workload.Spec.Selector
+	case *appsv1.DaemonSet:
+		return workload.Spec.Selector
+	default:
+		return nil
+	}
+}
+
+// intersectObjects returns the objects of the first list that are also in the second list, compared by namespace and name.
+func intersectObjects(objects, others []client.Object) []client.Object {
+	keys := map[client.ObjectKey]bool{}
+	for _, other := range others {
+		keys[client.ObjectKeyFromObject(other)] = true
+	}
+
+	intersection := []client.Object{}
+	for _, object := range objects {
+		if keys[client.ObjectKeyFromObject(object)] {
+			intersection = append(intersection, object)
+		}
+	}
+	return intersection
+}
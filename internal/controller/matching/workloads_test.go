@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package matching
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("Workload matching", func() {
+	var fakeClient client.Client
+	var ctx context.Context
+
+	const (
+		AppNamespace   = "koney-demo"
+		OtherNamespace = "other"
+	)
+
+	readyPod := func(name, namespace string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+		}
+	}
+
+	workloadTrap := func(strategy string, resourceDescription v1alpha1.ResourceDescription) v1alpha1.Trap {
+		return v1alpha1.Trap{
+			DecoyDeployment: v1alpha1.DecoyDeployment{Strategy: strategy},
+			MatchResources: v1alpha1.MatchResources{
+				Any: []v1alpha1.ResourceFilter{{ResourceDescription: resourceDescription}},
+			},
+		}
+	}
+
+	deployableNames := func(result MatchingResult) []string {
+		names := []string{}
+		for object := range result.DeployableObjects {
+			names = append(names, object.GetNamespace()+"/"+object.GetName())
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		apiDeployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: AppNamespace},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api", "tier": "backend"}},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+			},
+		}
+		otherApiDeployment := apiDeployment.DeepCopy()
+		otherApiDeployment.Namespace = OtherNamespace
+		dbStatefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: AppNamespace},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+		}
+		agentDaemonSet := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: AppNamespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+			},
+		}
+
+		fakeClient = fake.NewClientBuilder().WithObjects(
+			apiDeployment, otherApiDeployment, dbStatefulSet, agentDaemonSet,
+			readyPod("api-1", AppNamespace, map[string]string{"app": "api", "tier": "backend"}),
+			readyPod("api-2", AppNamespace, map[string]string{"app": "api", "tier": "frontend"}),
+			readyPod("api-1", OtherNamespace, map[string]string{"app": "api", "tier": "backend"}),
+			readyPod("db-0", AppNamespace, map[string]string{"app": "db"}),
+			readyPod("agent-x", AppNamespace, map[string]string{"app": "agent"}),
+			readyPod("unrelated", AppNamespace, map[string]string{"app": "unrelated"}),
+		).Build()
+	})
+
+	It("should select the pods of a Deployment by its pod selector", func() {
+		trap := workloadTrap("containerExec", v1alpha1.ResourceDescription{
+			Namespaces: []string{AppNamespace},
+			Workloads:  []v1alpha1.WorkloadReference{{Kind: v1alpha1.DeploymentWorkload, Name: "api"}},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf(AppNamespace+"/api-1", AppNamespace+"/api-2"))
+	})
+
+	It("should select the pods of StatefulSets and DaemonSets", func() {
+		trap := workloadTrap("containerExec", v1alpha1.ResourceDescription{
+			Workloads: []v1alpha1.WorkloadReference{
+				{Kind: v1alpha1.StatefulSetWorkload, Name: "db"},
+				{Kind: v1alpha1.DaemonSetWorkload, Name: "agent"},
+			},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf(AppNamespace+"/db-0", AppNamespace+"/agent-x"))
+	})
+
+	It("should combine workloads and labels with a logical AND", func() {
+		trap := workloadTrap("containerExec", v1alpha1.ResourceDescription{
+			Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+			Workloads: []v1alpha1.WorkloadReference{{Kind: v1alpha1.DeploymentWorkload, Name: "api"}},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf(AppNamespace + "/api-2"))
+	})
+
+	It("should select the Deployment itself for volume mounts", func() {
+		trap := workloadTrap("volumeMount", v1alpha1.ResourceDescription{
+			Namespaces: []string{AppNamespace},
+			Workloads: []v1alpha1.WorkloadReference{
+				{Kind: v1alpha1.DeploymentWorkload, Name: "api"},
+				{Kind: v1alpha1.StatefulSetWorkload, Name: "db"},
+			},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployableNames(result)).To(ConsistOf(AppNamespace + "/api"))
+	})
+
+	It("should not match anything if the workload does not exist", func() {
+		trap := workloadTrap("containerExec", v1alpha1.ResourceDescription{
+			Workloads: []v1alpha1.WorkloadReference{{Kind: v1alpha1.DeploymentWorkload, Name: "missing"}},
+		})
+
+		result, err := GetDeployableObjectsWithContainers(fakeClient, ctx, trap, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.AtLeastOneObjectWasMatched).To(BeFalse())
+	})
+
+	It("should resolve the pod labels of workloads for captors", func() {
+		podLabels, err := ResolveWorkloadPodLabels(fakeClient, ctx, v1alpha1.MatchResources{
+			Any: []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Workloads: []v1alpha1.WorkloadReference{{Kind: v1alpha1.StatefulSetWorkload, Name: "db"}},
+			}}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podLabels).To(Equal(map[string]string{"app": "db"}))
+
+		_, err = ResolveWorkloadPodLabels(fakeClient, ctx, v1alpha1.MatchResources{
+			Any: []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Workloads: []v1alpha1.WorkloadReference{{Kind: v1alpha1.DaemonSetWorkload, Name: "missing"}},
+			}}},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
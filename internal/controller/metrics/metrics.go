@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Labels of the metrics that Koney exposes.
+const (
+	LabelDeceptionPolicy = "deception_policy"
+	LabelTrapType        = "trap_type"
+	LabelKind            = "kind"
+	LabelComponent       = "component"
+	LabelStrategy        = "strategy"
+	LabelResult          = "result"
+	LabelReport          = "report"
+	LabelNamespace       = "namespace"
+	LabelCoverage        = "coverage"
+	LabelReason          = "reason"
+)
+
+// Coverage states of the workloads in a ClusterDeceptionReport, see RecordCoverage.
+const (
+	CoverageUnprotected = "unprotected"
+	CoverageUnverified  = "unverified"
+	CoverageVerified    = "verified"
+)
+
+// Components of a trap that can fail to be placed, see RecordPlacementFailures.
+const (
+	ComponentDecoy  = "decoy"
+	ComponentCaptor = "captor"
+)
+
+// ReasonOther is the reason of placement failures whose cause is not classified, see RecordPlacementFailures.
+const ReasonOther = "Other"
+
+var (
+	deceptionPolicies = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "koney",
+		Name:      "deception_policies",
+		Help:      "Number of DeceptionPolicies that are reconciled by Koney.",
+	})
+
+	traps = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "koney",
+		Name:      "traps",
+		Help:      "Number of traps that are neither expired nor invalid, per DeceptionPolicy and trap type.",
+	}, []string{LabelDeceptionPolicy, LabelTrapType})
+
+	trappedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "koney",
+		Name:      "trapped_resources",
+		Help:      "Number of resources (e.g., pods or deployments) with traps placed in them, per DeceptionPolicy and kind.",
+	}, []string{LabelDeceptionPolicy, LabelKind})
+
+	captorPolicies = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "koney",
+		Name:      "captor_policies",
+		Help:      "Number of captor policies that are deployed, per DeceptionPolicy and captor strategy.",
+	}, []string{LabelDeceptionPolicy, LabelStrategy})
+
+	placementFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "koney",
+		Name:      "trap_placement_failures_total",
+		Help:      "Number of traps whose decoys or captors could not be placed, per DeceptionPolicy, component, and reason.",
+	}, []string{LabelDeceptionPolicy, LabelComponent, LabelReason})
+
+	decoyTamperings = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "koney",
+		Name:      "decoy_tamperings_total",
+		Help:      "Number of decoys whose content did not match the checksum recorded when they were placed, per DeceptionPolicy.",
+	}, []string{LabelDeceptionPolicy})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "koney",
+		Name:      "deception_policy_reconcile_duration_seconds",
+		Help:      "Duration of the reconciliations of DeceptionPolicies, per result (success or error).",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{LabelResult})
+
+	coverageWorkloads = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "koney",
+		Name:      "coverage_workloads",
+		Help:      "Number of workloads per ClusterDeceptionReport, namespace, and coverage (unprotected, unverified, or verified).",
+	}, []string{LabelReport, LabelNamespace, LabelCoverage})
+)
+
+// recordedPolicies are the names of the DeceptionPolicies that metrics were recorded for.
+var (
+	recordedPolicies   = map[string]struct{}{}
+	recordedPoliciesMu sync.Mutex
+)
+
+func init() {
+	// Register the metrics with the registry of controller-runtime, which is served by the metrics endpoint of the manager
+	ctrlmetrics.Registry.MustRegister(deceptionPolicies, traps, trappedResources, captorPolicies, placementFailures, decoyTamperings, reconcileDuration, coverageWorkloads)
+}
+
+// DeceptionPolicyStats summarizes the traps of a DeceptionPolicy after a reconciliation.
+type DeceptionPolicyStats struct {
+	// Traps is the number of active traps per trap type.
+	Traps map[string]int
+	// TrappedResources is the number of resources with traps placed in them per kind (e.g., "Pod").
+	TrappedResources map[string]int
+	// CaptorPolicies is the number of deployed captor policies per captor strategy.
+	CaptorPolicies map[string]int
+}
+
+// RecordDeceptionPolicy replaces the recorded metrics of a DeceptionPolicy with the given stats.
+func RecordDeceptionPolicy(name string, stats DeceptionPolicyStats) {
+	recordedPoliciesMu.Lock()
+	defer recordedPoliciesMu.Unlock()
+
+	recordedPolicies[name] = struct{}{}
+	deceptionPolicies.Set(float64(len(recordedPolicies)))
+
+	// Trap types, kinds, or strategies that are gone since the last reconciliation must not be reported anymore
+	deleteGauges(name)
+	for trapType, count := range stats.Traps {
+		traps.WithLabelValues(name, trapType).Set(float64(count))
+	}
+	for kind, count := range stats.TrappedResources {
+		trappedResources.WithLabelValues(name, kind).Set(float64(count))
+	}
+	for strategy, count := range stats.CaptorPolicies {
+		captorPolicies.WithLabelValues(name, strategy).Set(float64(count))
+	}
+}
+
+// RecordPlacementFailures counts the traps whose decoys or captors (see ComponentDecoy and ComponentCaptor) could not be placed,
+// per reason of the failure (e.g., NoShell or ExecForbidden). An empty reason is recorded as ReasonOther.
+func RecordPlacementFailures(name, component string, numFailuresByReason map[string]int) {
+	for reason, numFailures := range numFailuresByReason {
+		if reason == "" {
+			reason = ReasonOther
+		}
+		if numFailures > 0 {
+			placementFailures.WithLabelValues(name, component, reason).Add(float64(numFailures))
+		}
+	}
+}
+
+// RecordDecoyTamperings counts the decoys whose content was found to be tampered with.
+func RecordDecoyTamperings(name string, numTamperings int) {
+	if numTamperings > 0 {
+		decoyTamperings.WithLabelValues(name).Add(float64(numTamperings))
+	}
+}
+
+// ObserveReconcile records the duration of a reconciliation of a DeceptionPolicy and whether it failed.
+func ObserveReconcile(duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// ForgetDeceptionPolicy removes all metrics of a DeceptionPolicy, e.g., because it was deleted.
+func ForgetDeceptionPolicy(name string) {
+	recordedPoliciesMu.Lock()
+	defer recordedPoliciesMu.Unlock()
+
+	delete(recordedPolicies, name)
+	deceptionPolicies.Set(float64(len(recordedPolicies)))
+
+	deleteGauges(name)
+	placementFailures.DeletePartialMatch(prometheus.Labels{LabelDeceptionPolicy: name})
+	decoyTamperings.DeletePartialMatch(prometheus.Labels{LabelDeceptionPolicy: name})
+}
+
+// NamespaceCoverage counts the workloads of a namespace in a ClusterDeceptionReport by their coverage.
+type NamespaceCoverage struct {
+	Unprotected int
+	Unverified  int
+	Verified    int
+}
+
+// RecordCoverage replaces the recorded coverage of a ClusterDeceptionReport with the given coverage per namespace.
+func RecordCoverage(report string, namespaces map[string]NamespaceCoverage) {
+	coverageWorkloads.DeletePartialMatch(prometheus.Labels{LabelReport: report})
+	for namespace, coverage := range namespaces {
+		coverageWorkloads.WithLabelValues(report, namespace, CoverageUnprotected).Set(float64(coverage.Unprotected))
+		coverageWorkloads.WithLabelValues(report, namespace, CoverageUnverified).Set(float64(coverage.Unverified))
+		coverageWorkloads.WithLabelValues(report, namespace, CoverageVerified).Set(float64(coverage.Verified))
+	}
+}
+
+// ForgetCoverage removes the recorded coverage of a ClusterDeceptionReport, e.g., because it was deleted.
+func ForgetCoverage(report string) {
+	coverageWorkloads.DeletePartialMatch(prometheus.Labels{LabelReport: report})
+}
+
+func deleteGauges(name string) {
+	labels := prometheus.Labels{LabelDeceptionPolicy: name}
+	traps.DeletePartialMatch(labels)
+	trappedResources.DeletePartialMatch(labels)
+	captorPolicies.DeletePartialMatch(labels)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("DeceptionPolicy metrics", func() {
+	AfterEach(func() {
+		ForgetDeceptionPolicy("policy-a")
+		ForgetDeceptionPolicy("policy-b")
+	})
+
+	It("should replace the metrics of a DeceptionPolicy on every reconciliation", func() {
+		RecordDeceptionPolicy("policy-a", DeceptionPolicyStats{
+			Traps:            map[string]int{"filesystem_honeytoken": 3},
+			TrappedResources: map[string]int{"Pod": 2, "Deployment": 1},
+			CaptorPolicies:   map[string]int{"tetragon": 3},
+		})
+		RecordDeceptionPolicy("policy-b", DeceptionPolicyStats{Traps: map[string]int{"filesystem_honeytoken": 1}})
+		Expect(testutil.ToFloat64(deceptionPolicies)).To(Equal(2.0))
+		Expect(testutil.ToFloat64(traps.WithLabelValues("policy-a", "filesystem_honeytoken"))).To(Equal(3.0))
+		Expect(testutil.ToFloat64(trappedResources.WithLabelValues("policy-a", "Pod"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(captorPolicies.WithLabelValues("policy-a", "tetragon"))).To(Equal(3.0))
+
+		By("dropping kinds and strategies that are gone")
+		RecordDeceptionPolicy("policy-a", DeceptionPolicyStats{
+			Traps:            map[string]int{"filesystem_honeytoken": 1},
+			TrappedResources: map[string]int{"Pod": 1},
+		})
+		Expect(testutil.CollectAndCount(trappedResources)).To(Equal(1))
+		Expect(testutil.CollectAndCount(captorPolicies)).To(Equal(0))
+		Expect(testutil.ToFloat64(deceptionPolicies)).To(Equal(2.0))
+	})
+
+	It("should forget all metrics of a deleted DeceptionPolicy", func() {
+		RecordDeceptionPolicy("policy-a", DeceptionPolicyStats{Traps: map[string]int{"filesystem_honeytoken": 1}})
+		RecordPlacementFailures("policy-a", ComponentDecoy, map[string]int{"NoShell": 2, "": 1})
+		RecordPlacementFailures("policy-a", ComponentCaptor, map[string]int{"CaptorBackendMissing": 0})
+		Expect(testutil.ToFloat64(placementFailures.WithLabelValues("policy-a", ComponentDecoy, "NoShell"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(placementFailures.WithLabelValues("policy-a", ComponentDecoy, ReasonOther))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(placementFailures)).To(Equal(2))
+		RecordDecoyTamperings("policy-a", 1)
+		Expect(testutil.ToFloat64(decoyTamperings.WithLabelValues("policy-a"))).To(Equal(1.0))
+
+		ForgetDeceptionPolicy("policy-a")
+		Expect(testutil.ToFloat64(deceptionPolicies)).To(Equal(0.0))
+		Expect(testutil.CollectAndCount(traps)).To(Equal(0))
+		Expect(testutil.CollectAndCount(placementFailures)).To(Equal(0))
+		Expect(testutil.CollectAndCount(decoyTamperings)).To(Equal(0))
+	})
+
+	It("should replace the coverage of a report", func() {
+		RecordCoverage("report", map[string]NamespaceCoverage{"a": {Unprotected: 1, Verified: 2}, "b": {Unverified: 1}})
+		Expect(testutil.ToFloat64(coverageWorkloads.WithLabelValues("report", "a", CoverageVerified))).To(Equal(2.0))
+		Expect(testutil.CollectAndCount(coverageWorkloads)).To(Equal(6))
+
+		RecordCoverage("report", map[string]NamespaceCoverage{"a": {Verified: 3}})
+		Expect(testutil.CollectAndCount(coverageWorkloads)).To(Equal(3))
+
+		ForgetCoverage("report")
+		Expect(testutil.CollectAndCount(coverageWorkloads)).To(Equal(0))
+	})
+
+	It("should observe reconcile durations per result", func() {
+		ObserveReconcile(time.Second, nil)
+		ObserveReconcile(time.Second, errors.New("failed"))
+		Expect(testutil.CollectAndCount(reconcileDuration)).To(Equal(2))
+	})
+})
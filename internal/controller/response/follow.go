@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
+	slimv1 "github.com/cilium/tetragon/pkg/k8s/slim/k8s/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// FollowID returns the id that the TracingPolicy of a followed pod selects it by.
+func FollowID(pod *corev1.Pod) string {
+	return utils.Hash(string(pod.UID))
+}
+
+// FollowExpiry returns when a followed pod stops being followed.
+// The second return value is false if the pod is not followed.
+func FollowExpiry(pod *corev1.Pod) (time.Time, bool) {
+	value, found := pod.Annotations[constants.AnnotationKeyFollowedUntil]
+	if !found {
+		return time.Time{}, false
+	}
+
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiry, true
+}
+
+// followAttacker labels a pod and creates a Tetragon TracingPolicy that traces all executions and connections in it.
+// The TracingPolicy is owned by the pod, so it is deleted together with it.
+// If the pod is followed again, following is extended, but never shortened.
+func (r *Responder) followAttacker(ctx context.Context, pod *corev1.Pod, followAttacker *v1alpha1.FollowAttacker, now time.Time) error {
+	followID := FollowID(pod)
+	expiry, followed := FollowExpiry(pod)
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[constants.LabelKeyFollowID] = followID
+	if newExpiry := now.Add(followAttacker.GetDuration()); !followed || newExpiry.After(expiry) {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[constants.AnnotationKeyFollowedUntil] = newExpiry.UTC().Format(time.RFC3339)
+	}
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return err
+	}
+
+	err := r.Create(ctx, buildFollowTracingPolicy(pod, followID))
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("Tetragon is not installed: %w", err)
+	}
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+// StopFollowing deletes the TracingPolicy of a followed pod and removes its label and annotation.
+func (r *Responder) StopFollowing(ctx context.Context, pod *corev1.Pod) error {
+	var errs []error
+	tracingPolicy := buildFollowTracingPolicy(pod, FollowID(pod))
+	if err := r.Delete(ctx, tracingPolicy); client.IgnoreNotFound(err) != nil && !meta.IsNoMatchError(err) {
+		errs = append(errs, err)
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	delete(pod.Labels, constants.LabelKeyFollowID)
+	delete(pod.Annotations, constants.AnnotationKeyFollowedUntil)
+	if err := r.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// buildFollowTracingPolicy returns a TracingPolicyNamespaced that traces all program executions (security_bprm_check)
+// and outgoing TCP connections (tcp_connect) of a followed pod. Its events are only exported by Tetragon, so they do not raise alerts.
+func buildFollowTracingPolicy(pod *corev1.Pod, followID string) *ciliumiov1alpha1.TracingPolicyNamespaced {
+	return &ciliumiov1alpha1.TracingPolicyNamespaced{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            constants.FollowTracingPolicyPrefix + followID,
+			Namespace:       pod.Namespace,
+			OwnerReferences: buildPodOwnerReferences(pod),
+		},
+		Spec: ciliumiov1alpha1.TracingPolicySpec{
+			PodSelector: &slimv1.LabelSelector{
+				MatchLabels: map[string]slimv1.MatchLabelsValue{constants.LabelKeyFollowID: followID},
+			},
+			KProbes: []ciliumiov1alpha1.KProbeSpec{
+				{
+					Call:    "security_bprm_check",
+					Syscall: false,
+					Args:    []ciliumiov1alpha1.KProbeArg{{Index: 0, Type: "linux_binprm"}},
+				},
+				{
+					Call:    "tcp_connect",
+					Syscall: false,
+					Args:    []ciliumiov1alpha1.KProbeArg{{Index: 0, Type: "sock"}},
+				},
+			},
+		},
+	}
+}
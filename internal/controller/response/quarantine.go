@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package response
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// ciliumNetworkPolicyGVK is the kind of Cilium network policies, which are created without depending on the Cilium API.
+var ciliumNetworkPolicyGVK = schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+
+// QuarantineID returns the id that the network policies of a quarantined pod select it by.
+func QuarantineID(pod *corev1.Pod) string {
+	return utils.Hash(string(pod.UID))
+}
+
+// QuarantineExpiry returns when the quarantine of a pod is lifted.
+// The second return value is false if the quarantine is never lifted automatically.
+func QuarantineExpiry(pod *corev1.Pod) (time.Time, bool) {
+	value, found := pod.Annotations[constants.AnnotationKeyQuarantinedUntil]
+	if !found {
+		return time.Time{}, false
+	}
+
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiry, true
+}
+
+// quarantinePod labels a pod as quarantined and creates network policies that deny all its traffic.
+// The network policies are owned by the pod, so they are deleted together with it.
+// If the pod is quarantined again, the quarantine is extended, but never shortened.
+func (r *Responder) quarantinePod(ctx context.Context, pod *corev1.Pod, quarantine v1alpha1.Quarantine, now time.Time) error {
+	quarantineID := QuarantineID(pod)
+	_, wasQuarantined := pod.Labels[constants.LabelKeyQuarantineID]
+	expiry, expires := QuarantineExpiry(pod)
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[constants.LabelKeyQuarantined] = "true"
+	pod.Labels[constants.LabelKeyQuarantineID] = quarantineID
+
+	switch {
+	case quarantine.TTL == nil:
+		delete(pod.Annotations, constants.AnnotationKeyQuarantinedUntil)
+	case wasQuarantined && !expires:
+		// The pod is already quarantined without a TTL, which must not be shortened
+	case !expires || now.Add(quarantine.TTL.Duration).After(expiry):
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[constants.AnnotationKeyQuarantinedUntil] = now.Add(quarantine.TTL.Duration).UTC().Format(time.RFC3339)
+	}
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return err
+	}
+
+	if err := r.Create(ctx, buildQuarantineNetworkPolicy(pod, quarantineID)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if quarantine.CiliumNetworkPolicy {
+		if err := r.Create(ctx, buildQuarantineCiliumNetworkPolicy(pod, quarantineID)); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LiftQuarantine deletes the network policies of a quarantined pod and removes its quarantine labels.
+func (r *Responder) LiftQuarantine(ctx context.Context, pod *corev1.Pod) error {
+	quarantineID := QuarantineID(pod)
+
+	var errs []error
+	networkPolicy := buildQuarantineNetworkPolicy(pod, quarantineID)
+	if err := r.Delete(ctx, networkPolicy); client.IgnoreNotFound(err) != nil {
+		errs = append(errs, err)
+	}
+	ciliumNetworkPolicy := buildQuarantineCiliumNetworkPolicy(pod, quarantineID)
+	if err := r.Delete(ctx, ciliumNetworkPolicy); client.IgnoreNotFound(err) != nil && !meta.IsNoMatchError(err) {
+		errs = append(errs, err)
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	delete(pod.Labels, constants.LabelKeyQuarantined)
+	delete(pod.Labels, constants.LabelKeyQuarantineID)
+	delete(pod.Annotations, constants.AnnotationKeyQuarantinedUntil)
+	if err := r.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// buildQuarantineNetworkPolicy returns a NetworkPolicy that denies all ingress and egress traffic of a quarantined pod.
+func buildQuarantineNetworkPolicy(pod *corev1.Pod, quarantineID string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            constants.QuarantineNetworkPolicyPrefix + quarantineID,
+			Namespace:       pod.Namespace,
+			OwnerReferences: buildPodOwnerReferences(pod),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					constants.LabelKeyQuarantined:  "true",
+					constants.LabelKeyQuarantineID: quarantineID,
+				},
+			},
+			// Without any rules, all ingress and egress traffic is denied
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+}
+
+// buildQuarantineCiliumNetworkPolicy returns a CiliumNetworkPolicy that denies all ingress and egress traffic of a quarantined pod.
+func buildQuarantineCiliumNetworkPolicy(pod *corev1.Pod, quarantineID string) *unstructured.Unstructured {
+	ciliumNetworkPolicy := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"endpointSelector": map[string]any{
+				"matchLabels": map[string]any{
+					constants.LabelKeyQuarantined:  "true",
+					constants.LabelKeyQuarantineID: quarantineID,
+				},
+			},
+			"ingressDeny": []any{map[string]any{"fromEntities": []any{"all"}}},
+			"egressDeny":  []any{map[string]any{"toEntities": []any{"all"}}},
+		},
+	}}
+	ciliumNetworkPolicy.SetGroupVersionKind(ciliumNetworkPolicyGVK)
+	ciliumNetworkPolicy.SetName(constants.QuarantineNetworkPolicyPrefix + quarantineID)
+	ciliumNetworkPolicy.SetNamespace(pod.Namespace)
+	ciliumNetworkPolicy.SetOwnerReferences(buildPodOwnerReferences(pod))
+
+	return ciliumNetworkPolicy
+}
+
+// buildPodOwnerReferences returns owner references to a pod, so that the garbage collector deletes dependents with the pod.
+func buildPodOwnerReferences(pod *corev1.Pod) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       pod.Name,
+			UID:        pod.UID,
+		},
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// Request is a request of the alert forwarder to respond to an alert, stored in an annotation on the accessing pod.
+type Request struct {
+	// DeceptionPolicyName is the name of the deception policy of the accessed trap.
+	DeceptionPolicyName string `json:"deceptionPolicyName"`
+
+	// TrapHash is the hash of the accessed trap, as stored in its captor.
+	TrapHash string `json:"trapHash"`
+
+	// ContainerName is the name of the container that accessed the trap.
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Binary is the path of the binary of the process that accessed the trap.
+	Binary string `json:"binary,omitempty"`
+}
+
+// actionOrder is the order in which response actions are taken:
+// the pod is contained and followed first, then the accessing processes and finally the pod are killed.
+var actionOrder = []string{"labelPod", "networkIsolate", "followAttacker", "killProcess", "killPod"}
+
+// ParseRequest returns the response request in the annotations of a pod.
+// The second return value is false if the pod has no response request.
+func ParseRequest(pod *corev1.Pod) (Request, bool, error) {
+	value, found := pod.Annotations[constants.AnnotationKeyResponseRequest]
+	if !found {
+		return Request{}, false, nil
+	}
+
+	request := Request{}
+	if err := json.Unmarshal([]byte(value), &request); err != nil {
+		return Request{}, true, err
+	}
+	if request.DeceptionPolicyName == "" || request.TrapHash == "" {
+		return Request{}, true, errors.New("response request must name a deception policy and a trap hash")
+	}
+
+	return request, true, nil
+}
+
+// FindTrap returns the trap with the given hash. The second return value is false
+// if no trap has this hash, e.g., because the trap was changed after the alert.
+func FindTrap(traps []v1alpha1.Trap, trapHash string) (v1alpha1.Trap, bool) {
+	for _, trap := range traps {
+		if hash, err := filesystoken.TrapHash(trap); err == nil && hash == trapHash {
+			return trap, true
+		}
+	}
+
+	return v1alpha1.Trap{}, false
+}
+
+// Responder takes the response actions of traps on pods.
+type Responder struct {
+	client.Client
+	Clientset kubernetes.Interface
+	Config    *rest.Config
+}
+
+// Respond takes the response actions of a trap on a pod. All actions are attempted, even if some of them fail.
+func (r *Responder) Respond(ctx context.Context, pod *corev1.Pod, request Request, trap v1alpha1.Trap, now time.Time) error {
+	quarantine := v1alpha1.Quarantine{}
+	if trap.Quarantine != nil {
+		quarantine = *trap.Quarantine
+	}
+
+	var errs []error
+	for _, action := range actionOrder {
+		if !utils.Contains(trap.AllResponseActions(), action) {
+			continue
+		}
+
+		var err error
+		switch action {
+		case "labelPod":
+			err = r.labelPod(ctx, pod)
+		case "networkIsolate":
+			err = r.quarantinePod(ctx, pod, quarantine, now)
+		case "followAttacker":
+			err = r.followAttacker(ctx, pod, trap.FollowAttacker, now)
+		case "killProcess":
+			err = r.killProcess(ctx, pod, request)
+		case "killPod":
+			err = r.killPod(ctx, pod)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("response action '%s' failed: %w", action, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// labelPod labels a pod as quarantined.
+func (r *Responder) labelPod(ctx context.Context, pod *corev1.Pod) error {
+	if pod.Labels[constants.LabelKeyQuarantined] == "true" {
+		return nil
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[constants.LabelKeyQuarantined] = "true"
+
+	return r.Patch(ctx, pod, patch)
+}
+
+// killProcess kills the processes of the accessing binary in the accessing container.
+func (r *Responder) killProcess(ctx context.Context, pod *corev1.Pod, request Request) error {
+	if request.ContainerName == "" || request.Binary == "" {
+		return errors.New("response request must name the container and the binary of the accessing process")
+	}
+
+	output, err := utils.ExecuteCommandInContainer(ctx, r.Clientset, r.Config, *pod, request.ContainerName, buildKillProcessCommand(request.Binary))
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+
+	return nil
+}
+
+// killPod deletes a pod immediately.
+func (r *Responder) killPod(ctx context.Context, pod *corev1.Pod) error {
+	err := r.Delete(ctx, pod, client.GracePeriodSeconds(0))
+	return client.IgnoreNotFound(err)
+}
+
+// buildKillProcessCommand returns a command that kills all processes of a binary, except for the init process
+// of the container and the command itself. The binary is passed as an argument, so it is never interpreted by the shell.
+func buildKillProcessCommand(binary string) []string {
+	script := `for p in /proc/[0-9]*; do pid=${p#/proc/}; ` +
+		`if [ "$pid" != 1 ] && [ "$pid" != $$ ] && [ "$(readlink "$p/exe")" = "$1" ]; then kill -9 "$pid"; fi; done`
+	return []string{"sh", "-c", script, "koney-response", binary}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package response
+
+import (
+	"context"
+	"time"
+
+	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+)
+
+var _ = Describe("ParseRequest", func() {
+	newPod := func(annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "koney-demo", Annotations: annotations}}
+	}
+
+	It("should report pods without a response request", func() {
+		_, found, err := ParseRequest(newPod(nil))
+		Expect(found).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should parse the response request", func() {
+		request, found, err := ParseRequest(newPod(map[string]string{
+			constants.AnnotationKeyResponseRequest: `{"deceptionPolicyName":"dp","trapHash":"abc","containerName":"nginx","binary":"/usr/bin/cat"}`,
+		}))
+		Expect(found).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request).To(Equal(Request{DeceptionPolicyName: "dp", TrapHash: "abc", ContainerName: "nginx", Binary: "/usr/bin/cat"}))
+	})
+
+	It("should reject malformed response requests", func() {
+		for _, value := range []string{"not json", `{"deceptionPolicyName":"dp"}`} {
+			_, found, err := ParseRequest(newPod(map[string]string{constants.AnnotationKeyResponseRequest: value}))
+			Expect(found).To(BeTrue())
+			Expect(err).To(HaveOccurred(), value)
+		}
+	})
+})
+
+var _ = Describe("FindTrap", func() {
+	traps := []v1alpha1.Trap{
+		{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/a"}},
+		{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/b"}, ResponseActions: []string{"killPod"}},
+	}
+
+	It("should find the trap by the hash that captors store", func() {
+		trapHash, err := filesystoken.TrapHash(traps[1])
+		Expect(err).NotTo(HaveOccurred())
+
+		trap, found := FindTrap(traps, trapHash)
+		Expect(found).To(BeTrue())
+		Expect(trap.ResponseActions).To(Equal([]string{"killPod"}))
+	})
+
+	It("should not find traps that changed", func() {
+		_, found := FindTrap(traps, "unknown")
+		Expect(found).To(BeFalse())
+	})
+})
+
+var _ = Describe("Responder", func() {
+	var fakeClient client.Client
+	var responder Responder
+	var pod *corev1.Pod
+	var ctx context.Context
+
+	now := time.Date(2025, 1, 3, 18, 47, 56, 0, time.UTC)
+
+	respondWith := func(actions ...string) v1alpha1.Trap {
+		return v1alpha1.Trap{ResponseActions: actions}
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "koney-demo", UID: "e19c1827"}}
+		fakeClient = fake.NewClientBuilder().WithObjects(pod).Build()
+		responder = Responder{Client: fakeClient}
+	})
+
+	It("should label the pod as quarantined", func() {
+		Expect(responder.Respond(ctx, pod, Request{}, respondWith("labelPod"), now)).To(Succeed())
+
+		updated := &corev1.Pod{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updated)).To(Succeed())
+		Expect(updated.Labels).To(HaveKeyWithValue(constants.LabelKeyQuarantined, "true"))
+	})
+
+	It("should isolate only this pod from the network", func() {
+		Expect(responder.Respond(ctx, pod, Request{}, respondWith("networkIsolate"), now)).To(Succeed())
+		// Isolating a second time reuses the existing NetworkPolicy
+		Expect(responder.Respond(ctx, pod, Request{}, respondWith("networkIsolate"), now)).To(Succeed())
+
+		updated := &corev1.Pod{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updated)).To(Succeed())
+		Expect(updated.Labels).To(HaveKeyWithValue(constants.LabelKeyQuarantined, "true"))
+		Expect(updated.Labels).To(HaveKeyWithValue(constants.LabelKeyQuarantineID, QuarantineID(pod)))
+		Expect(updated.Annotations).NotTo(HaveKey(constants.AnnotationKeyQuarantinedUntil))
+
+		networkPolicy := &networkingv1.NetworkPolicy{}
+		key := types.NamespacedName{Name: constants.QuarantineNetworkPolicyPrefix + QuarantineID(pod), Namespace: "koney-demo"}
+		Expect(fakeClient.Get(ctx, key, networkPolicy)).To(Succeed())
+		Expect(networkPolicy.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(constants.LabelKeyQuarantineID, QuarantineID(pod)))
+		Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+		Expect(networkPolicy.Spec.Ingress).To(BeEmpty())
+		Expect(networkPolicy.Spec.Egress).To(BeEmpty())
+		Expect(networkPolicy.OwnerReferences).To(HaveLen(1))
+		Expect(networkPolicy.OwnerReferences[0].UID).To(Equal(pod.UID))
+	})
+
+	It("should label the pod before deleting it", func() {
+		Expect(responder.Respond(ctx, pod, Request{}, respondWith("killPod", "labelPod"), now)).To(Succeed())
+
+		err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), &corev1.Pod{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(pod.Labels).To(HaveKeyWithValue(constants.LabelKeyQuarantined, "true"))
+	})
+
+	It("should require the container and the binary to kill processes", func() {
+		err := responder.Respond(ctx, pod, Request{ContainerName: "nginx"}, respondWith("killProcess"), now)
+		Expect(err).To(MatchError(ContainSubstring("killProcess")))
+	})
+
+	Context("with a quarantine", func() {
+		quarantineFor := func(ttl time.Duration) v1alpha1.Trap {
+			return v1alpha1.Trap{Quarantine: &v1alpha1.Quarantine{TTL: &metav1.Duration{Duration: ttl}}}
+		}
+
+		It("should record when the quarantine is lifted", func() {
+			Expect(responder.Respond(ctx, pod, Request{}, quarantineFor(time.Hour), now)).To(Succeed())
+
+			expiry, expires := QuarantineExpiry(pod)
+			Expect(expires).To(BeTrue())
+			Expect(expiry).To(Equal(now.Add(time.Hour)))
+		})
+
+		It("should extend, but never shorten the quarantine", func() {
+			Expect(responder.Respond(ctx, pod, Request{}, quarantineFor(time.Hour), now)).To(Succeed())
+			Expect(responder.Respond(ctx, pod, Request{}, quarantineFor(time.Minute), now.Add(time.Minute))).To(Succeed())
+			expiry, _ := QuarantineExpiry(pod)
+			Expect(expiry).To(Equal(now.Add(time.Hour)))
+
+			Expect(responder.Respond(ctx, pod, Request{}, quarantineFor(time.Hour), now.Add(time.Minute))).To(Succeed())
+			expiry, _ = QuarantineExpiry(pod)
+			Expect(expiry).To(Equal(now.Add(time.Hour + time.Minute)))
+
+			// A quarantine without a TTL is never lifted automatically
+			Expect(responder.Respond(ctx, pod, Request{}, respondWith("networkIsolate"), now)).To(Succeed())
+			_, expires := QuarantineExpiry(pod)
+			Expect(expires).To(BeFalse())
+			Expect(responder.Respond(ctx, pod, Request{}, quarantineFor(time.Hour), now)).To(Succeed())
+			_, expires = QuarantineExpiry(pod)
+			Expect(expires).To(BeFalse())
+		})
+
+		It("should lift the quarantine", func() {
+			Expect(responder.Respond(ctx, pod, Request{}, quarantineFor(time.Hour), now)).To(Succeed())
+			Expect(responder.LiftQuarantine(ctx, pod)).To(Succeed())
+
+			updated := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updated)).To(Succeed())
+			Expect(updated.Labels).NotTo(HaveKey(constants.LabelKeyQuarantined))
+			Expect(updated.Labels).NotTo(HaveKey(constants.LabelKeyQuarantineID))
+			Expect(updated.Annotations).NotTo(HaveKey(constants.AnnotationKeyQuarantinedUntil))
+
+			key := types.NamespacedName{Name: constants.QuarantineNetworkPolicyPrefix + QuarantineID(pod), Namespace: "koney-demo"}
+			err := fakeClient.Get(ctx, key, &networkingv1.NetworkPolicy{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("when following the attacker", func() {
+		followFor := func(duration time.Duration) v1alpha1.Trap {
+			return v1alpha1.Trap{FollowAttacker: &v1alpha1.FollowAttacker{Duration: &metav1.Duration{Duration: duration}}}
+		}
+		tracingPolicyKey := func() types.NamespacedName {
+			return types.NamespacedName{Name: constants.FollowTracingPolicyPrefix + FollowID(pod), Namespace: "koney-demo"}
+		}
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(ciliumiov1alpha1.AddToScheme(scheme)).To(Succeed())
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+			responder = Responder{Client: fakeClient}
+		})
+
+		It("should trace only this pod until following expires", func() {
+			Expect(responder.Respond(ctx, pod, Request{}, respondWith("followAttacker"), now)).To(Succeed())
+
+			expiry, followed := FollowExpiry(pod)
+			Expect(followed).To(BeTrue())
+			Expect(expiry).To(Equal(now.Add(v1alpha1.DefaultFollowAttackerDuration)))
+
+			tracingPolicy := &ciliumiov1alpha1.TracingPolicyNamespaced{}
+			Expect(fakeClient.Get(ctx, tracingPolicyKey(), tracingPolicy)).To(Succeed())
+			Expect(tracingPolicy.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(constants.LabelKeyFollowID, FollowID(pod)))
+			Expect(tracingPolicy.Spec.KProbes).To(HaveLen(2))
+			Expect(tracingPolicy.OwnerReferences[0].UID).To(Equal(pod.UID))
+			Expect(tracingPolicy.Labels).NotTo(HaveKey(constants.LabelKeyDeceptionPolicyRef))
+
+			updated := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updated)).To(Succeed())
+			Expect(updated.Labels).To(HaveKeyWithValue(constants.LabelKeyFollowID, FollowID(pod)))
+		})
+
+		It("should extend, but never shorten following", func() {
+			Expect(responder.Respond(ctx, pod, Request{}, followFor(time.Hour), now)).To(Succeed())
+			Expect(responder.Respond(ctx, pod, Request{}, followFor(time.Minute), now.Add(time.Minute))).To(Succeed())
+			expiry, _ := FollowExpiry(pod)
+			Expect(expiry).To(Equal(now.Add(time.Hour)))
+
+			Expect(responder.Respond(ctx, pod, Request{}, followFor(time.Hour), now.Add(time.Minute))).To(Succeed())
+			expiry, _ = FollowExpiry(pod)
+			Expect(expiry).To(Equal(now.Add(time.Hour + time.Minute)))
+		})
+
+		It("should stop following", func() {
+			Expect(responder.Respond(ctx, pod, Request{}, followFor(time.Hour), now)).To(Succeed())
+			Expect(responder.StopFollowing(ctx, pod)).To(Succeed())
+
+			updated := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updated)).To(Succeed())
+			Expect(updated.Labels).NotTo(HaveKey(constants.LabelKeyFollowID))
+			Expect(updated.Annotations).NotTo(HaveKey(constants.AnnotationKeyFollowedUntil))
+
+			err := fakeClient.Get(ctx, tracingPolicyKey(), &ciliumiov1alpha1.TracingPolicyNamespaced{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("buildQuarantineCiliumNetworkPolicy", func() {
+	It("should deny all traffic of the pod", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "koney-demo", UID: "e19c1827"}}
+		ciliumNetworkPolicy := buildQuarantineCiliumNetworkPolicy(pod, QuarantineID(pod))
+
+		Expect(ciliumNetworkPolicy.GetKind()).To(Equal("CiliumNetworkPolicy"))
+		Expect(ciliumNetworkPolicy.GetNamespace()).To(Equal("koney-demo"))
+		Expect(ciliumNetworkPolicy.GetOwnerReferences()[0].UID).To(Equal(pod.UID))
+		Expect(ciliumNetworkPolicy.Object["spec"]).To(HaveKeyWithValue("ingressDeny", []any{map[string]any{"fromEntities": []any{"all"}}}))
+		Expect(ciliumNetworkPolicy.Object["spec"]).To(HaveKeyWithValue("egressDeny", []any{map[string]any{"toEntities": []any{"all"}}}))
+	})
+})
+
+var _ = Describe("buildKillProcessCommand", func() {
+	It("should pass the binary as an argument of the script", func() {
+		cmd := buildKillProcessCommand("/bin/sh; rm -rf /")
+		Expect(cmd[:2]).To(Equal([]string{"sh", "-c"}))
+		Expect(cmd[2]).NotTo(ContainSubstring("rm -rf"))
+		Expect(cmd[4]).To(Equal("/bin/sh; rm -rf /"))
+	})
+})
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/matching"
+	"github.com/dynatrace-oss/koney/internal/controller/response"
+	"github.com/dynatrace-oss/koney/internal/controller/templates"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// ResponseReconciler takes the response actions of traps when the alert forwarder requests a response on a pod,
+// and lifts the quarantine of pods and stops following them once it expired.
+type ResponseReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Clientset kubernetes.Clientset
+	Config    rest.Config
+}
+
+// Reconcile handles the response request on a pod, if any, the quarantine of the pod, and whether it is followed.
+// Each response request is handled at most once.
+func (r *ResponseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, req.NamespacedName, pod); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	responder := &response.Responder{Client: r.Client, Clientset: &r.Clientset, Config: &r.Config}
+	now := time.Now()
+
+	if err := r.handleResponseRequest(ctx, responder, pod, now); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	quarantineResult, err := r.reconcileQuarantine(ctx, responder, pod, now)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	followResult, err := r.reconcileFollowing(ctx, responder, pod, now)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Check back when the quarantine or following expires, whichever comes first
+	if quarantineResult.RequeueAfter == 0 || (followResult.RequeueAfter > 0 && followResult.RequeueAfter < quarantineResult.RequeueAfter) {
+		return followResult, nil
+	}
+	return quarantineResult, nil
+}
+
+// handleResponseRequest takes the response actions of the trap that the response request on a pod refers to.
+// Invalid requests are logged and dropped, only errors that are worth a retry are returned.
+func (r *ResponseReconciler) handleResponseRequest(ctx context.Context, responder *response.Responder, pod *corev1.Pod, now time.Time) error {
+	log := k8slog.FromContext(ctx)
+	podName := client.ObjectKeyFromObject(pod)
+
+	request, found, parseErr := response.ParseRequest(pod)
+	if !found {
+		return nil
+	}
+
+	// Remove the request before taking any action, so that destructive actions are never repeated
+	patch := client.MergeFrom(pod.DeepCopy())
+	delete(pod.Annotations, constants.AnnotationKeyResponseRequest)
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return err
+	}
+
+	if parseErr != nil {
+		log.Error(parseErr, "Ignoring malformed response request", "pod", podName)
+		return nil
+	}
+
+	trap, err := r.resolveTrap(ctx, pod, request)
+	if err != nil {
+		log.Error(err, "Ignoring response request", "pod", podName, "deceptionPolicy", request.DeceptionPolicyName)
+		return nil
+	}
+	if trap == nil || len(trap.AllResponseActions()) == 0 {
+		return nil
+	}
+
+	log.Info("Responding to trap access", "pod", podName, "deceptionPolicy", request.DeceptionPolicyName, "actions", trap.AllResponseActions())
+	if err := responder.Respond(ctx, pod, request, *trap, now); err != nil {
+		log.Error(err, "Unable to take all response actions", "pod", podName)
+	}
+
+	return nil
+}
+
+// reconcileQuarantine lifts the quarantine of a pod once it expired, or checks back when it expires.
+func (r *ResponseReconciler) reconcileQuarantine(ctx context.Context, responder *response.Responder, pod *corev1.Pod, now time.Time) (ctrl.Result, error) {
+	log := k8slog.FromContext(ctx)
+
+	expiry, expires := response.QuarantineExpiry(pod)
+	if !expires {
+		return ctrl.Result{}, nil
+	}
+	if now.Before(expiry) {
+		return ctrl.Result{RequeueAfter: expiry.Sub(now)}, nil
+	}
+
+	log.Info("Lifting expired quarantine", "pod", client.ObjectKeyFromObject(pod), "quarantinedUntil", expiry)
+	if err := responder.LiftQuarantine(ctx, pod); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileFollowing stops following a pod once following expired, or checks back when it expires.
+func (r *ResponseReconciler) reconcileFollowing(ctx context.Context, responder *response.Responder, pod *corev1.Pod, now time.Time) (ctrl.Result, error) {
+	log := k8slog.FromContext(ctx)
+
+	expiry, followed := response.FollowExpiry(pod)
+	if !followed {
+		return ctrl.Result{}, nil
+	}
+	if now.Before(expiry) {
+		return ctrl.Result{RequeueAfter: expiry.Sub(now)}, nil
+	}
+
+	log.Info("Stopping to follow pod", "pod", client.ObjectKeyFromObject(pod), "followedUntil", expiry)
+	if err := responder.StopFollowing(ctx, pod); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// resolveTrap returns the trap that a response request refers to, or nil if the deception policy must not respond.
+// The deception policy is the source of truth, so requests for traps that do not match the pod are rejected.
+func (r *ResponseReconciler) resolveTrap(ctx context.Context, pod *corev1.Pod, request response.Request) (*v1alpha1.Trap, error) {
+	deceptionPolicy := &v1alpha1.DeceptionPolicy{}
+	if err := r.Get(ctx, types.NamespacedName{Name: request.DeceptionPolicyName}, deceptionPolicy); err != nil {
+		return nil, err
+	}
+
+	// Paused and dry-run policies must not change the cluster
+	if deceptionPolicy.IsPaused() || deceptionPolicy.IsDryRun() {
+		return nil, nil
+	}
+
+	// Captors are deployed for the defaulted and expanded traps, so the hash refers to the expanded trap
+	// (traps that cannot be expanded are not found below, so the error can be ignored)
+	deceptionPolicy.ApplyDefaults()
+	_, _ = templates.ExpandTrapTemplates(r, ctx, deceptionPolicy)
+
+	trap, found := response.FindTrap(deceptionPolicy.Spec.Traps, request.TrapHash)
+	if !found {
+		return nil, fmt.Errorf("deception policy has no trap with hash %s", request.TrapHash)
+	}
+
+	containers, err := matching.GetMatchingContainersOfPod(r, ctx, pod, trap.MatchResources)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 || (request.ContainerName != "" && !utils.Contains(containers, request.ContainerName)) {
+		return nil, errors.New("trap does not match the pod of the response request")
+	}
+
+	return &trap, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ResponseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Clientset = *kubernetes.NewForConfigOrDie(mgr.GetConfig())
+	r.Config = *mgr.GetConfig()
+
+	// Only pods with a response request, a quarantine that expires, or that are followed need to be reconciled
+	needsResponse := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, requested := obj.GetAnnotations()[constants.AnnotationKeyResponseRequest]
+		_, expires := obj.GetAnnotations()[constants.AnnotationKeyQuarantinedUntil]
+		_, followed := obj.GetAnnotations()[constants.AnnotationKeyFollowedUntil]
+		return requested || expires || followed
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}, builder.WithPredicates(needsResponse)).
+		Named("response").
+		Complete(r)
+}
@@ -17,11 +17,23 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
 )
 
 const (
@@ -29,6 +41,14 @@ const (
 	PolicyValidType     = "PolicyValid"
 	DecoysDeployedType  = "DecoysDeployed"
 	CaptorsDeployedType = "CaptorsDeployed"
+	TrapsDeployedType   = "TrapsDeployed"
+	DegradedType        = "Degraded"
+	CaptorsResyncedType = "CaptorsResynced"
+	DecoysVerifiedType  = "DecoysVerified"
+	DryRunType          = "DryRun"
+	PausedType          = "Paused"
+
+	CaptorBackendAvailableType = "CaptorBackendAvailable"
 
 	ResourceFoundReason_Found = "ResourceFound"
 
@@ -37,6 +57,7 @@ const (
 	PolicyValidReason_Pending = "ValidationPending"
 	PolicyValidReason_Valid   = "TrapsSpecValid"
 	PolicyValidReason_Invalid = "TrapsSpecInvalid"
+	PolicyValidReason_Expired = "TrapsExpired"
 
 	DecoysDeployedReason_Pending        = "DecoyDeploymentPending"
 	DecoysDeployedReason_Success        = "DecoyDeploymentSucceeded"
@@ -54,6 +75,49 @@ const (
 	CaptorsDeployedReason_MissingTetragon = "TetragonNotInstalled"
 
 	CaptorsDeployedMessage_MissingTetragon = "Cannot deploy captors without Tetragon"
+
+	TrapsDeployedReason_Pending    = "TrapDeploymentPending"
+	TrapsDeployedReason_Success    = "TrapDeploymentSucceeded"
+	TrapsDeployedReason_Incomplete = "TrapDeploymentIncomplete"
+
+	TrapsDeployedMessage_Success = "All decoys and captors deployed"
+
+	DegradedReason_AsExpected = "AsExpected"
+	DegradedReason_Degraded   = "TrapsDegraded"
+
+	DegradedMessage_AsExpected = "All traps are valid and were deployed without errors"
+
+	CaptorsResyncedReason_Success    = "CaptorsRecreated"
+	CaptorsResyncedReason_CRDMissing = "TracingPolicyCRDMissing"
+	CaptorsResyncedReason_Failed     = "CaptorResyncFailed"
+
+	CaptorsResyncedMessage_Success    = "Captors recreated after the Tetragon tracing policy CRD was recreated"
+	CaptorsResyncedMessage_CRDMissing = "Tetragon tracing policy CRD was deleted - waiting for Tetragon to be reinstalled"
+
+	DecoysVerifiedReason_Pending = "DecoyVerificationPending"
+	DecoysVerifiedReason_Intact  = "DecoysIntact"
+	DecoysVerifiedReason_Drifted = "DecoyDriftDetected"
+	DecoysVerifiedReason_Error   = "DecoyVerificationError"
+
+	DecoysVerifiedMessage_Intact = "All deployed decoys are in place"
+
+	DryRunReason_Enabled  = "DryRunEnabled"
+	DryRunReason_Disabled = "DryRunDisabled"
+
+	DryRunMessage_Pending  = "Dry run enabled - no traps are deployed, changed, or removed"
+	DryRunMessage_Disabled = "Dry run disabled - traps are deployed"
+
+	PausedReason_Paused  = "ReconciliationPaused"
+	PausedReason_Resumed = "ReconciliationResumed"
+
+	PausedMessage_Paused  = "Reconciliation paused - deployed traps are left in place until the paused annotation is removed"
+	PausedMessage_Resumed = "Reconciliation resumed"
+
+	CaptorBackendAvailableReason_Available   = "CaptorBackendsAvailable"
+	CaptorBackendAvailableReason_Unavailable = "CaptorBackendUnavailable"
+	CaptorBackendAvailableReason_Error       = "CaptorBackendCheckError"
+
+	CaptorBackendAvailableMessage_Available = "The backends of all captor strategies are installed and healthy"
 )
 
 // TrapDeploymentStatusEnum defines the possible conditions for a trap deployment.
@@ -109,19 +173,88 @@ var CaptorDeployedStatusConditions = TrapDeploymentStatusEnum{
 	},
 }
 
+// summarizeStatusConditions derives the TrapsDeployed and Degraded conditions from the other conditions.
+// TrapsDeployed is true once all decoys and captors are deployed, so that tools like `kubectl wait` can detect a complete rollout.
+// Degraded is true if traps are invalid or if errors occurred while deploying them.
+func summarizeStatusConditions(policyValid, decoysDeployed, captorsDeployed v1alpha1.DeceptionPolicyCondition) (v1alpha1.DeceptionPolicyCondition, v1alpha1.DeceptionPolicyCondition) {
+	trapsDeployed := v1alpha1.DeceptionPolicyCondition{
+		Type:               TrapsDeployedType,
+		Status:             metav1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             TrapsDeployedReason_Pending,
+		Message:            "",
+	}
+
+	switch {
+	case decoysDeployed.Status == metav1.ConditionTrue && captorsDeployed.Status == metav1.ConditionTrue:
+		trapsDeployed.Status = metav1.ConditionTrue
+		trapsDeployed.Reason = TrapsDeployedReason_Success
+		trapsDeployed.Message = TrapsDeployedMessage_Success
+	case policyValid.Status == metav1.ConditionFalse || decoysDeployed.Status == metav1.ConditionFalse || captorsDeployed.Status == metav1.ConditionFalse:
+		trapsDeployed.Status = metav1.ConditionFalse
+		trapsDeployed.Reason = TrapsDeployedReason_Incomplete
+		trapsDeployed.Message = joinConditionMessages(policyValid, decoysDeployed, captorsDeployed)
+	}
+
+	degraded := v1alpha1.DeceptionPolicyCondition{
+		Type:               DegradedType,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             DegradedReason_AsExpected,
+		Message:            DegradedMessage_AsExpected,
+	}
+
+	var degradedConditions []v1alpha1.DeceptionPolicyCondition
+	if policyValid.Reason == PolicyValidReason_Invalid {
+		degradedConditions = append(degradedConditions, policyValid)
+	}
+	if decoysDeployed.Reason == DecoysDeployedReason_GenericError || trapsapi.IsErrorReason(decoysDeployed.Reason) {
+		degradedConditions = append(degradedConditions, decoysDeployed)
+	}
+	if captorsDeployed.Reason == CaptorsDeployedReason_GenericError || captorsDeployed.Reason == CaptorsDeployedReason_MissingTetragon ||
+		trapsapi.IsErrorReason(captorsDeployed.Reason) {
+		degradedConditions = append(degradedConditions, captorsDeployed)
+	}
+	if len(degradedConditions) > 0 {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = DegradedReason_Degraded
+		degraded.Message = joinConditionMessages(degradedConditions...)
+	}
+
+	return trapsDeployed, degraded
+}
+
+// joinConditionMessages joins the non-empty messages of the conditions into a single message.
+func joinConditionMessages(conditions ...v1alpha1.DeceptionPolicyCondition) string {
+	messages := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		if condition.Message != "" {
+			messages = append(messages, condition.Message)
+		}
+	}
+
+	return strings.Join(messages, "; ")
+}
+
 // updateStatusConditions updates one or more conditions of a DeceptionPolicy resource.
 // If the conditions are already set as desired, no update is performed.
 // When comparing the current and desired conditions, the LastTransitionTime field is ignored.
+// The conditions and the status are marked as observed for the given generation of the DeceptionPolicy.
 // This function retries on conflicts (to resolve parallel update attempts) and returns an error if the update fails.
-func (r *DeceptionPolicyReconciler) updateStatusConditions(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy, conditions []v1alpha1.DeceptionPolicyCondition) error {
+func (r *DeceptionPolicyReconciler) updateStatusConditions(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy, observedGeneration int64, conditions []v1alpha1.DeceptionPolicyCondition) error {
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
 			return err
 		}
 
 		anyDirty := false
+		if deceptionPolicy.Status.ObservedGeneration != observedGeneration {
+			deceptionPolicy.Status.ObservedGeneration = observedGeneration
+			anyDirty = true
+		}
 		for _, condition := range conditions {
-			dirty := deceptionPolicy.Status.PutCondition(condition.Type, condition.Status, condition.Reason, condition.Message)
+			condition.ObservedGeneration = observedGeneration
+			dirty := deceptionPolicy.Status.PutConditionStruct(condition)
 			anyDirty = anyDirty || dirty
 		}
 		if !anyDirty {
@@ -133,3 +266,255 @@ func (r *DeceptionPolicyReconciler) updateStatusConditions(ctx context.Context,
 		return err
 	})
 }
+
+// updateHoneytokenRotations records the given generations of rotating honeytokens in the status of a DeceptionPolicy resource.
+// Generations that are already recorded are not recorded again, and if nothing changes, no update is performed.
+// This function retries on conflicts (to resolve parallel update attempts) and returns an error if the update fails.
+func (r *DeceptionPolicyReconciler) updateHoneytokenRotations(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy, rotations []v1alpha1.HoneytokenRotation) error {
+	if len(rotations) == 0 {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
+			return err
+		}
+
+		anyDirty := false
+		for _, rotation := range rotations {
+			dirty := deceptionPolicy.Status.PutHoneytokenRotation(rotation)
+			anyDirty = anyDirty || dirty
+		}
+		if !anyDirty {
+			return nil // All generations are already recorded
+		}
+
+		return r.Status().Update(ctx, deceptionPolicy)
+	})
+}
+
+// updateTrapTemplates records the generations of the TrapTemplates that the traps of a DeceptionPolicy were expanded from,
+// so that the next reconciliation can tell if a template changed in the meantime.
+func (r *DeceptionPolicyReconciler) updateTrapTemplates(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy, trapTemplates []v1alpha1.TrapTemplateStatus) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
+			return err
+		}
+
+		if dirty := deceptionPolicy.Status.SetTrapTemplates(slices.Clone(trapTemplates)); !dirty {
+			return nil // All generations are already recorded
+		}
+
+		return r.Status().Update(ctx, deceptionPolicy)
+	})
+}
+
+// updateTrapStatuses reports the status of each trap in the status of a DeceptionPolicy resource,
+// based on the results of the latest decoy verification and the decoy and captor deployments, and on the annotations of the resources.
+// If nothing changes, no update is performed.
+// This function retries on conflicts (to resolve parallel update attempts) and returns the trap statuses, or an error if the update fails.
+func (r *DeceptionPolicyReconciler) updateTrapStatuses(ctx context.Context, req ctrl.Request, deceptionPolicy *v1alpha1.DeceptionPolicy,
+	decoyResult, captorResult TrapReconcileResult, drifts map[string][]v1alpha1.TrapDrift, now time.Time) ([]v1alpha1.TrapStatus, error) {
+	trapStatuses, err := r.buildTrapStatuses(ctx, deceptionPolicy, decoyResult, captorResult, drifts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return trapStatuses, retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, req.NamespacedName, deceptionPolicy); err != nil {
+			return err
+		}
+
+		if dirty := deceptionPolicy.Status.SetTrapStatuses(slices.Clone(trapStatuses)); !dirty {
+			return nil // All trap statuses already have their desired values
+		}
+
+		return r.Status().Update(ctx, deceptionPolicy)
+	})
+}
+
+// buildTrapStatuses builds the status of each trap in the spec of a DeceptionPolicy.
+func (r *DeceptionPolicyReconciler) buildTrapStatuses(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy,
+	decoyResult, captorResult TrapReconcileResult, drifts map[string][]v1alpha1.TrapDrift, now time.Time) ([]v1alpha1.TrapStatus, error) {
+	resources, err := annotations.GetAnnotatedResources(r, ctx, deceptionPolicy.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	trapStatuses := make([]v1alpha1.TrapStatus, 0, len(deceptionPolicy.Spec.Traps))
+	for index, trap := range deceptionPolicy.Spec.Traps {
+		trapStatus := v1alpha1.TrapStatus{Index: index, TrapType: trap.TrapType(), TrapHash: hashTrap(trap)}
+
+		if trap.IsExpired(deceptionPolicy.Status.TrapDeployedAt(trapStatus.TrapHash), now) {
+			trapStatus.Expired = true
+			trapStatuses = append(trapStatuses, trapStatus)
+			continue
+		}
+		if err := trap.IsValid(); err != nil {
+			trapStatus.LastError = err.Error()
+			trapStatuses = append(trapStatuses, trapStatus)
+			continue
+		}
+
+		// Decoys are deployed per file path and generation, so we look for all of them
+		// (traps whose canary token cannot be resolved are kept unresolved, so that their failed results are found)
+		resolvedTraps, unresolvedResults := r.resolveCanaryTokens(ctx, deceptionPolicy, filesystoken.ExpandFilePaths([]v1alpha1.Trap{trap}))
+		for _, result := range unresolvedResults {
+			resolvedTraps = append(resolvedTraps, *result.Trap)
+		}
+		decoyTraps := filesystoken.ResolveRotatedTraps(deceptionPolicy, resolvedTraps, now)
+		placements, err := findTrapPlacements(deceptionPolicy.Name, resources, decoyTraps)
+		if err != nil {
+			return nil, err
+		}
+		trapStatus.Placements = placements
+		trapStatus.Checksums = buildDecoyChecksums(decoyTraps, placements)
+		trapStatus.TokenPlacements, trapStatus.OmittedTokenPlacements = buildTokenPlacements(resources, decoyTraps, placements)
+		trapStatus.FailedPlacements = r.placementRetries.FailedPlacements(deceptionPolicy.Name, getFilePaths(decoyTraps))
+		trapStatus.Drifts = drifts[trapStatus.TrapHash]
+
+		if captorPolicyName, err := filesystoken.GenerateCaptorPolicyName(deceptionPolicy, trap); err == nil {
+			trapStatus.CaptorPolicyName = captorPolicyName
+		}
+
+		// Summarize the deployment results of the decoys and the captor of this trap
+		var errs []error
+		numResults, numSuccesses := 0, 0
+		numDecoyResults, numUnmatched := 0, 0
+		for _, result := range decoyResult.Results {
+			if slices.ContainsFunc(decoyTraps, func(decoyTrap v1alpha1.Trap) bool {
+				return equality.Semantic.DeepEqual(decoyTrap, *result.GetTrap())
+			}) {
+				numResults++
+				numDecoyResults++
+				if result.ImpliesSuccess() {
+					numSuccesses++
+				}
+				if decoyResult, ok := result.(trapsapi.DecoyDeploymentResult); ok && !decoyResult.AtLeastOneObjectsWasMatched {
+					numUnmatched++
+				}
+				errs = append(errs, result.GetErrors())
+			}
+		}
+		for _, result := range captorResult.Results {
+			if equality.Semantic.DeepEqual(trap, *result.GetTrap()) {
+				numResults++
+				if result.ImpliesSuccess() {
+					numSuccesses++
+				}
+				errs = append(errs, result.GetErrors())
+			}
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			trapStatus.LastError = err.Error()
+			trapStatus.LastErrorReason = trapsapi.ErrorReason(err)
+		} else if numResults > 0 && numResults == numSuccesses {
+			trapStatus.DeployedAt = &metav1.Time{Time: now}
+		} else if numDecoyResults > 0 && numDecoyResults == numUnmatched {
+			trapStatus.LastError = trapsapi.ErrSelectorMatchesNothing.Error()
+			trapStatus.LastErrorReason = trapsapi.ErrorReasonSelectorMatchesNothing
+		}
+
+		trapStatuses = append(trapStatuses, trapStatus)
+	}
+
+	return trapStatuses, nil
+}
+
+// getFilePaths returns the file paths of the decoys of the given traps.
+func getFilePaths(traps []v1alpha1.Trap) []string {
+	filePaths := make([]string, 0, len(traps))
+	for _, trap := range traps {
+		filePaths = append(filePaths, trap.FilesystemHoneytoken.FilePath)
+	}
+	return filePaths
+}
+
+// buildDecoyChecksums returns the SHA-256 checksums of the decoys of the given traps that are placed somewhere.
+// Placements are only annotated after the decoys were verified (e.g., read back from the container), so the checksums
+// describe the content as it was placed.
+func buildDecoyChecksums(traps []v1alpha1.Trap, placements []v1alpha1.TrapPlacement) []v1alpha1.DecoyChecksum {
+	var checksums []v1alpha1.DecoyChecksum
+	for _, trap := range traps {
+		filePath := trap.FilesystemHoneytoken.FilePath
+		if !slices.ContainsFunc(placements, func(placement v1alpha1.TrapPlacement) bool { return slices.Contains(placement.FilePaths, filePath) }) ||
+			slices.ContainsFunc(checksums, func(checksum v1alpha1.DecoyChecksum) bool { return checksum.FilePath == filePath }) {
+			continue
+		}
+		checksums = append(checksums, v1alpha1.DecoyChecksum{FilePath: filePath, SHA256: utils.SHA256(trap.FilesystemHoneytoken.FileContent)})
+	}
+	return checksums
+}
+
+// buildTokenPlacements records the values that the decoys of the given traps got in the pods where they are placed,
+// for decoys whose content references the placement context. Only the first TokenPlacementsLimit placements are recorded,
+// the second return value is the number of placements that were omitted.
+func buildTokenPlacements(resources []client.Object, traps []v1alpha1.Trap, placements []v1alpha1.TrapPlacement) ([]v1alpha1.TokenPlacement, int) {
+	var tokenPlacements []v1alpha1.TokenPlacement
+	omitted := 0
+	for _, resource := range resources {
+		pod, ok := resource.(*corev1.Pod)
+		if !ok {
+			continue
+		}
+		index := slices.IndexFunc(placements, func(placement v1alpha1.TrapPlacement) bool {
+			return placement.Kind == utils.ResourceKind(pod) && placement.Namespace == pod.Namespace && placement.Name == pod.Name
+		})
+		if index < 0 {
+			continue
+		}
+
+		for _, trap := range traps {
+			if !trap.FilesystemHoneytoken.UsesPlacementContext() || !slices.Contains(placements[index].FilePaths, trap.FilesystemHoneytoken.FilePath) {
+				continue
+			}
+			if len(tokenPlacements) >= v1alpha1.TokenPlacementsLimit {
+				omitted++
+				continue
+			}
+			tokenPlacements = append(tokenPlacements, filesystoken.BuildTokenPlacement(trap, *pod))
+		}
+	}
+	return tokenPlacements, omitted
+}
+
+// findTrapPlacements returns the resources and containers where Koney annotated that any of the given traps were placed.
+func findTrapPlacements(deceptionPolicyName string, resources []client.Object, traps []v1alpha1.Trap) ([]v1alpha1.TrapPlacement, error) {
+	var placements []v1alpha1.TrapPlacement
+	for _, resource := range resources {
+		annotationChange, err := annotations.GetAnnotationChange(resource, deceptionPolicyName)
+		if err != nil {
+			return nil, err
+		}
+
+		var placement *v1alpha1.TrapPlacement
+		for _, annotationTrap := range annotationChange.Traps {
+			if !slices.ContainsFunc(traps, func(trap v1alpha1.Trap) bool { return annotations.AreTheSameTrap(annotationTrap, trap) }) {
+				continue
+			}
+
+			if placement == nil {
+				placement = &v1alpha1.TrapPlacement{
+					Kind:      utils.ResourceKind(resource),
+					Namespace: resource.GetNamespace(),
+					Name:      resource.GetName(),
+				}
+			}
+			for _, container := range annotationTrap.Containers {
+				if !slices.Contains(placement.Containers, container) {
+					placement.Containers = append(placement.Containers, container)
+				}
+			}
+			if filePath := annotationTrap.FilesystemHoneytoken.FilePath; filePath != "" && !slices.Contains(placement.FilePaths, filePath) {
+				placement.FilePaths = append(placement.FilePaths, filePath)
+			}
+		}
+
+		if placement != nil {
+			placements = append(placements, *placement)
+		}
+	}
+
+	return placements, nil
+}
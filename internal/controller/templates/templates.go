@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// ErrTrapTemplateUnavailable is returned if a TrapTemplate cannot be fetched for another reason than that it does not exist
+// (e.g., because the API server is unavailable), so that the traps that reference it are neither valid nor invalid right now.
+var ErrTrapTemplateUnavailable = errors.New("the TrapTemplate cannot be fetched")
+
+// ExpandTrapTemplates replaces the traps of a DeceptionPolicy that reference a TrapTemplate with their expansion,
+// and applies the trap defaults of the DeceptionPolicy to the expanded traps. The DeceptionPolicy is changed in memory only,
+// so that users keep seeing the references in the spec. Traps whose template cannot be expanded (e.g., because it does not exist)
+// are left as they are, so that they are reported as invalid. If a TrapTemplate is unavailable, the expansion stops
+// and an error that wraps ErrTrapTemplateUnavailable is returned.
+// It returns the name and generation of every TrapTemplate that was expanded, sorted by name.
+func ExpandTrapTemplates(r client.Reader, ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy) ([]v1alpha1.TrapTemplateStatus, error) {
+	trapTemplates := map[string]*v1alpha1.TrapTemplate{}
+	var errs []error
+
+	for i, trap := range deceptionPolicy.Spec.Traps {
+		if trap.TemplateRef == nil {
+			continue
+		}
+
+		trapTemplate, found := trapTemplates[trap.TemplateRef.Name]
+		if !found {
+			trapTemplate = &v1alpha1.TrapTemplate{}
+			if err := r.Get(ctx, client.ObjectKey{Name: trap.TemplateRef.Name}, trapTemplate); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%w: '%s': %w", ErrTrapTemplateUnavailable, trap.TemplateRef.Name, err)
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("TrapTemplate '%s' cannot be fetched: %w", trap.TemplateRef.Name, err))
+				continue
+			}
+			trapTemplates[trap.TemplateRef.Name] = trapTemplate
+		}
+
+		expandedTrap, err := trapTemplate.Expand(trap)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		expandedTrap.ApplyDefaults(deceptionPolicy.Spec.TrapDefaults)
+		deceptionPolicy.Spec.Traps[i] = expandedTrap
+	}
+
+	statuses := make([]v1alpha1.TrapTemplateStatus, 0, len(trapTemplates))
+	for _, trapTemplate := range trapTemplates {
+		statuses = append(statuses, v1alpha1.TrapTemplateStatus{Name: trapTemplate.Name, Generation: trapTemplate.Generation})
+	}
+	slices.SortFunc(statuses, func(a, b v1alpha1.TrapTemplateStatus) int { return strings.Compare(a.Name, b.Name) })
+
+	return statuses, errors.Join(errs...)
+}
+
+// ReferencesTrapTemplate returns true if any trap of a DeceptionPolicy references the TrapTemplate with the given name.
+func ReferencesTrapTemplate(deceptionPolicy *v1alpha1.DeceptionPolicy, name string) bool {
+	return slices.ContainsFunc(deceptionPolicy.Spec.Traps, func(trap v1alpha1.Trap) bool {
+		return trap.TemplateRef != nil && trap.TemplateRef.Name == name
+	})
+}
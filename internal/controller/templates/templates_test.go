@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package templates
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("ExpandTrapTemplates", func() {
+	ctx := context.Background()
+
+	var fakeClient client.Client
+	var deceptionPolicy *v1alpha1.DeceptionPolicy
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&v1alpha1.TrapTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-token", Generation: 2},
+			Spec: v1alpha1.TrapTemplateSpec{
+				Parameters: []v1alpha1.TrapTemplateParameter{{Name: "token"}},
+				Trap: v1alpha1.Trap{
+					FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
+						FilePath:    "/run/secrets/koney/service_token",
+						FileContent: "{{ .Parameters.token }}",
+					},
+				},
+			},
+		}).Build()
+
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-templates"},
+			Spec: v1alpha1.DeceptionPolicySpec{
+				TrapDefaults: &v1alpha1.TrapDefaults{
+					DecoyDeployment: &v1alpha1.DecoyDeployment{Strategy: "containerExec"},
+				},
+				Traps: []v1alpha1.Trap{
+					{TemplateRef: &v1alpha1.TrapTemplateReference{Name: "service-token", Parameters: map[string]string{"token": "someverysecrettoken"}}},
+					{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/root/.aws/credentials", FileContent: "[default]"}},
+				},
+			},
+		}
+	})
+
+	It("should expand the traps that reference a template and apply the trap defaults", func() {
+		statuses, err := ExpandTrapTemplates(fakeClient, ctx, deceptionPolicy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(Equal([]v1alpha1.TrapTemplateStatus{{Name: "service-token", Generation: 2}}))
+
+		trap := deceptionPolicy.Spec.Traps[0]
+		Expect(trap.TemplateRef).To(BeNil())
+		Expect(trap.FilesystemHoneytoken.FileContent).To(Equal("someverysecrettoken"))
+		Expect(trap.DecoyDeployment.Strategy).To(Equal("containerExec"))
+		Expect(trap.CaptorDeployment.Strategy).To(Equal(v1alpha1.DefaultCaptorDeploymentStrategy))
+
+		By("leaving the other traps untouched")
+		Expect(deceptionPolicy.Spec.Traps[1].FilesystemHoneytoken.FilePath).To(Equal("/root/.aws/credentials"))
+		Expect(deceptionPolicy.Spec.Traps[1].DecoyDeployment.Strategy).To(BeEmpty())
+	})
+
+	It("should leave traps that reference a missing template as they are", func() {
+		deceptionPolicy.Spec.Traps[0].TemplateRef.Name = "missing"
+
+		statuses, err := ExpandTrapTemplates(fakeClient, ctx, deceptionPolicy)
+		Expect(err).To(MatchError(ContainSubstring("TrapTemplate 'missing' cannot be fetched")))
+		Expect(statuses).To(BeEmpty())
+		Expect(deceptionPolicy.Spec.Traps[0].TemplateRef).NotTo(BeNil())
+		Expect(deceptionPolicy.Spec.Traps[0].IsValid()).To(HaveOccurred())
+	})
+
+	It("should stop if a template is unavailable", func() {
+		unavailableClient := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+			Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+				return apierrors.NewServiceUnavailable("etcd is down")
+			},
+		})
+
+		statuses, err := ExpandTrapTemplates(unavailableClient, ctx, deceptionPolicy)
+		Expect(err).To(MatchError(ErrTrapTemplateUnavailable))
+		Expect(statuses).To(BeNil())
+		Expect(deceptionPolicy.Spec.Traps[0].TemplateRef).NotTo(BeNil())
+	})
+
+	It("should tell whether a DeceptionPolicy references a template", func() {
+		Expect(ReferencesTrapTemplate(deceptionPolicy, "service-token")).To(BeTrue())
+		Expect(ReferencesTrapTemplate(deceptionPolicy, "missing")).To(BeFalse())
+	})
+})
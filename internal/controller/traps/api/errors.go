@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import "errors"
+
+// Errors that deploying the decoys and captors of traps can fail with. They are wrapped into the errors of the
+// deployment results, so that the controller can tell the causes apart (see ErrorReason) without parsing messages.
+var (
+	// ErrNoShell means that a decoy cannot be placed with exec, because the container has neither a shell nor tar.
+	ErrNoShell = errors.New("the container has neither a shell nor tar")
+	// ErrExecForbidden means that the controller is not allowed to exec into the pod.
+	ErrExecForbidden = errors.New("exec into the pod is forbidden")
+	// ErrCaptorBackendMissing means that a captor cannot be deployed, because its backend (e.g., Tetragon) is not installed or configured.
+	ErrCaptorBackendMissing = errors.New("the backend of the captor is missing")
+	// ErrSelectorMatchesNothing means that the match criteria of a trap do not select any resource, so its decoys are placed nowhere.
+	ErrSelectorMatchesNothing = errors.New("the trap does not match any resource")
+	// ErrCanaryTokenNotProvisioned means that the canary token of a honeytoken cannot be provisioned from the canarytokens service.
+	ErrCanaryTokenNotProvisioned = errors.New("the canary token cannot be provisioned")
+)
+
+// Reasons that classify the errors above, which are used as reasons of status conditions and as labels of metrics.
+const (
+	ErrorReasonNoShell                   = "NoShell"
+	ErrorReasonExecForbidden             = "ExecForbidden"
+	ErrorReasonCaptorBackendMissing      = "CaptorBackendMissing"
+	ErrorReasonSelectorMatchesNothing    = "SelectorMatchesNothing"
+	ErrorReasonCanaryTokenNotProvisioned = "CanaryTokenNotProvisioned"
+)
+
+// errorReasons maps the errors above to their reasons, in the order in which they are looked for in an error.
+var errorReasons = []struct {
+	err    error
+	reason string
+}{
+	{ErrCaptorBackendMissing, ErrorReasonCaptorBackendMissing},
+	{ErrExecForbidden, ErrorReasonExecForbidden},
+	{ErrNoShell, ErrorReasonNoShell},
+	{ErrSelectorMatchesNothing, ErrorReasonSelectorMatchesNothing},
+	{ErrCanaryTokenNotProvisioned, ErrorReasonCanaryTokenNotProvisioned},
+}
+
+// ErrorReason returns the reason of the first known error that the (possibly joined) error wraps,
+// or an empty string if the error is nil or none of the known errors.
+func ErrorReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, known := range errorReasons {
+		if errors.Is(err, known.err) {
+			return known.reason
+		}
+	}
+	return ""
+}
+
+// IsErrorReason returns true if the reason is one of the reasons of ErrorReason.
+func IsErrorReason(reason string) bool {
+	for _, known := range errorReasons {
+		if known.reason == reason {
+			return true
+		}
+	}
+	return false
+}
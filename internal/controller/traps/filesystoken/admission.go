@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// InjectDecoyIntoPod injects a FilesystemHoneytoken decoy into a pod while it is admitted, using the admission strategy.
+// The honeytoken is mounted from a secret, which is created in the namespace of the pod (unless dryRun is set).
+// Containers that already mount something at the file path of the honeytoken are skipped, and the pod is annotated
+// with the trap like the controller annotates resources. The function returns the containers the decoy was injected into.
+func InjectDecoyIntoPod(c client.Client, ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap,
+	pod *corev1.Pod, containers []string, dryRun bool) ([]string, error) {
+	volume, volumeMount, data, err := buildSecretVolume(trap)
+	if err != nil {
+		return nil, err
+	}
+
+	// The volume is mounted in a copy first, so that the pod is left unchanged if its secret cannot be created
+	podSpec := pod.Spec.DeepCopy()
+	injectedContainers := mountVolumeInPodSpec(podSpec, containers, volume, volumeMount)
+	if len(injectedContainers) == 0 {
+		return nil, nil
+	}
+
+	if !dryRun {
+		if err := createSecret(c, ctx, deceptionPolicy, trap, pod.Namespace, volume.Secret.SecretName, data); err != nil {
+			return nil, err
+		}
+	}
+	pod.Spec = *podSpec
+
+	if err := annotations.AddTrapToAnnotations(pod, deceptionPolicy.Name, trap, injectedContainers); err != nil {
+		return nil, err
+	}
+
+	return injectedContainers, nil
+}
+
+// mountVolumeInPodSpec adds a volume to a pod spec and mounts it in the given containers.
+// If the pod spec already has a different volume with the same name (e.g., the same file path with other content),
+// or a container already mounts something at the same path, nothing is mounted, because the pod would be rejected.
+// The function returns the containers the volume was mounted in.
+func mountVolumeInPodSpec(podSpec *corev1.PodSpec, containers []string, volume corev1.Volume, volumeMount corev1.VolumeMount) []string {
+	for _, existingVolume := range podSpec.Volumes {
+		if existingVolume.Name == volume.Name {
+			return nil
+		}
+	}
+
+	mountedContainers := []string{}
+	for i, container := range podSpec.Containers {
+		if !utils.Contains(containers, container.Name) || hasMountPath(container, volumeMount.MountPath) {
+			continue
+		}
+
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, volumeMount)
+		mountedContainers = append(mountedContainers, container.Name)
+	}
+
+	if len(mountedContainers) > 0 {
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+	}
+
+	return mountedContainers
+}
+
+// isVolumeMountedInPod checks if the volume of a trap is mounted in a container of a pod,
+// i.e., if the decoy was injected into the container when the pod was admitted.
+func isVolumeMountedInPod(pod *corev1.Pod, containerName string, volume corev1.Volume) bool {
+	volumeFound := false
+	for _, existingVolume := range pod.Spec.Volumes {
+		if existingVolume.Name == volume.Name && existingVolume.Secret != nil && existingVolume.Secret.SecretName == volume.Secret.SecretName {
+			volumeFound = true
+			break
+		}
+	}
+	if !volumeFound {
+		return false
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name != containerName {
+			continue
+		}
+		for _, existingVolumeMount := range container.VolumeMounts {
+			if existingVolumeMount.Name == volume.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasMountPath checks if a container already mounts a volume at the given path.
+func hasMountPath(container corev1.Container, mountPath string) bool {
+	for _, existingVolumeMount := range container.VolumeMounts {
+		if existingVolumeMount.MountPath == mountPath {
+			return true
+		}
+	}
+	return false
+}
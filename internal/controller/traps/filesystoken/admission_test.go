@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+)
+
+var _ = Describe("InjectDecoyIntoPod", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx             context.Context
+		fakeClient      client.Client
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		trap            v1alpha1.Trap
+		pod             *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		fakeClient = fake.NewClientBuilder().Build()
+
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-admission"}}
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "someverysecrettoken", ReadOnly: &[]bool{true}[0]},
+			DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "admission"},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "koney-demo"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}}},
+		}
+	})
+
+	It("should mount the honeytoken from a secret and annotate the pod", func() {
+		containers, err := InjectDecoyIntoPod(fakeClient, ctx, deceptionPolicy, trap, pod, []string{"app"}, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(Equal([]string{"app"}))
+
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(pod.Spec.Volumes[0].Secret.SecretName).To(Equal(generateSecretName(trap)))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+			Name: generateVolumeName(FilePath), MountPath: FilePath, ReadOnly: true, SubPath: "service_token",
+		}))
+		Expect(pod.Spec.Containers[1].VolumeMounts).To(BeEmpty())
+		Expect(isVolumeMountedInPod(pod, "app", pod.Spec.Volumes[0])).To(BeTrue())
+
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "koney-demo", Name: generateSecretName(trap)}, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue("service_token", []byte("someverysecrettoken")))
+
+		changes, err := annotations.GetAnnotationChange(pod, deceptionPolicy.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes.Traps).To(HaveLen(1))
+		Expect(changes.Traps[0].Containers).To(Equal([]string{"app"}))
+		Expect(annotations.AreTheSameTrap(changes.Traps[0], trap)).To(BeTrue())
+	})
+
+	It("should not create the secret for dry-run requests", func() {
+		containers, err := InjectDecoyIntoPod(fakeClient, ctx, deceptionPolicy, trap, pod, []string{"app"}, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(Equal([]string{"app"}))
+
+		secrets := &corev1.SecretList{}
+		Expect(fakeClient.List(ctx, secrets)).To(Succeed())
+		Expect(secrets.Items).To(BeEmpty())
+	})
+
+	It("should skip containers that already mount something at the file path", func() {
+		pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "tokens", MountPath: FilePath}}
+
+		containers, err := InjectDecoyIntoPod(fakeClient, ctx, deceptionPolicy, trap, pod, []string{"app", "sidecar"}, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(Equal([]string{"sidecar"}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(HaveLen(1))
+	})
+
+	It("should not inject anything if another decoy was already injected at the same file path", func() {
+		otherTrap := trap
+		otherTrap.FilesystemHoneytoken.FileContent = "anotherverysecrettoken"
+		_, err := InjectDecoyIntoPod(fakeClient, ctx, deceptionPolicy, otherTrap, pod, []string{"app"}, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		containers, err := InjectDecoyIntoPod(fakeClient, ctx, deceptionPolicy, trap, pod, []string{"app", "sidecar"}, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(containers).To(BeEmpty())
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(isVolumeMountedInPod(pod, "app", corev1.Volume{
+			Name:         generateVolumeName(FilePath),
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: generateSecretName(trap)}},
+		})).To(BeFalse())
+	})
+})
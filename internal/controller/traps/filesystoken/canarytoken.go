@@ -0,0 +1,265 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// canaryTokenTypes maps the kinds of canary tokens to the types of the canarytokens API.
+var canaryTokenTypes = map[string]string{
+	v1alpha1.CanaryTokenKindURL:     "web",
+	v1alpha1.CanaryTokenKindAWSKeys: "aws_keys",
+	v1alpha1.CanaryTokenKindDNS:     "dns",
+}
+
+const (
+	// canaryTokenSecretNamePrefix is the prefix of the secrets in the Koney namespace that store the provisioned canary tokens.
+	canaryTokenSecretNamePrefix = "koney-canarytoken-"
+
+	// canaryTokenRequestTimeout limits how long a request to the canarytokens service may take,
+	// so that an unresponsive service does not block the reconciliation.
+	canaryTokenRequestTimeout = 10 * time.Second
+)
+
+// Keys of the data of a canary token secret, which the alert forwarder reads to correlate triggered canary tokens.
+const (
+	CanaryTokenSecretKeyToken           = "token"
+	CanaryTokenSecretKeyKind            = "kind"
+	CanaryTokenSecretKeyFilePath        = "filePath"
+	CanaryTokenSecretKeyURL             = "url"
+	CanaryTokenSecretKeyHostname        = "hostname"
+	CanaryTokenSecretKeyAccessKeyID     = "accessKeyID"
+	CanaryTokenSecretKeySecretAccessKey = "secretAccessKey"
+)
+
+// defaultCanaryTokenClient is the HTTP client that requests the canarytokens service if no other client is configured.
+var defaultCanaryTokenClient = &http.Client{Timeout: canaryTokenRequestTimeout}
+
+// CanaryToken holds the values of a canary token that was provisioned from a canarytokens-compatible service.
+// The values are decoys that grant no access, but they are only stored in a secret, never in the status of a DeceptionPolicy.
+type CanaryToken struct {
+	// Token is the ID of the canary token at the service, which the alerts of the service refer to.
+	Token string
+	// URL is the URL of a canary token of kind "url".
+	URL string
+	// Hostname is the hostname of a canary token of kind "url" or "dns".
+	Hostname string
+	// AccessKeyID is the AWS access key ID of a canary token of kind "awsKeys".
+	AccessKeyID string
+	// SecretAccessKey is the AWS secret access key of a canary token of kind "awsKeys".
+	SecretAccessKey string
+}
+
+// CanaryTokenProvisioner provisions tokens from a canarytokens-compatible service.
+type CanaryTokenProvisioner interface {
+	// Provision creates a new canary token of the given kind. The memo is shown by the service when the token triggers.
+	Provision(ctx context.Context, kind, memo string) (CanaryToken, error)
+}
+
+// HTTPCanaryTokenProvisioner provisions canary tokens with the /generate endpoint of a canarytokens-compatible service.
+type HTTPCanaryTokenProvisioner struct {
+	// URL is the base URL of the service (e.g., "https://canarytokens.example.com").
+	URL string
+	// WebhookURL is the URL that the service reports triggered canary tokens to.
+	WebhookURL string
+	// Client is the HTTP client that requests the service, or a client with a timeout of 10 seconds if nil.
+	Client *http.Client
+}
+
+// generateResponse is the response of the /generate endpoint. The fields are matched case-insensitively,
+// so that both older ("Token", "Url") and newer ("token", "token_url") versions of the API are understood.
+type generateResponse struct {
+	Token              string `json:"token"`
+	URL                string `json:"url"`
+	TokenURL           string `json:"token_url"`
+	Hostname           string `json:"hostname"`
+	AWSAccessKeyID     string `json:"aws_access_key_id"`
+	AWSSecretAccessKey string `json:"aws_secret_access_key"`
+}
+
+// Provision creates a new canary token of the given kind, which reports to the webhook URL when it triggers.
+func (p *HTTPCanaryTokenProvisioner) Provision(ctx context.Context, kind, memo string) (CanaryToken, error) {
+	tokenType, ok := canaryTokenTypes[kind]
+	if !ok {
+		return CanaryToken{}, fmt.Errorf("unknown kind of canary token: '%s'", kind)
+	}
+	if p.URL == "" {
+		return CanaryToken{}, errors.New("no canarytokens service is configured")
+	}
+
+	form := url.Values{"type": {tokenType}, "memo": {memo}, "webhook_url": {p.WebhookURL}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.URL, "/")+"/generate", strings.NewReader(form.Encode()))
+	if err != nil {
+		return CanaryToken{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpClient := p.Client
+	if httpClient == nil {
+		httpClient = defaultCanaryTokenClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return CanaryToken{}, err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return CanaryToken{}, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return CanaryToken{}, fmt.Errorf("the canarytokens service responded with status %d: %s", response.StatusCode, body)
+	}
+
+	var generated generateResponse
+	if err := json.Unmarshal(body, &generated); err != nil {
+		return CanaryToken{}, fmt.Errorf("unable to parse the response of the canarytokens service: %w", err)
+	}
+	if generated.Token == "" {
+		return CanaryToken{}, errors.New("the canarytokens service did not return a token")
+	}
+
+	canaryToken := CanaryToken{Token: generated.Token}
+	switch kind {
+	case v1alpha1.CanaryTokenKindURL:
+		canaryToken.URL = generated.TokenURL
+		if canaryToken.URL == "" {
+			canaryToken.URL = generated.URL
+		}
+		canaryToken.Hostname = generated.Hostname
+	case v1alpha1.CanaryTokenKindAWSKeys:
+		canaryToken.AccessKeyID = generated.AWSAccessKeyID
+		canaryToken.SecretAccessKey = generated.AWSSecretAccessKey
+	case v1alpha1.CanaryTokenKindDNS:
+		canaryToken.Hostname = generated.Hostname
+	}
+
+	return canaryToken, nil
+}
+
+// BuildCanaryTokenMemo describes the honeytoken that a canary token is placed with, which the service shows when it triggers.
+func BuildCanaryTokenMemo(deceptionPolicy *v1alpha1.DeceptionPolicy, filePath string) string {
+	return fmt.Sprintf("Koney honeytoken %s of DeceptionPolicy %s", filePath, deceptionPolicy.Name)
+}
+
+// GenerateCanaryTokenSecretName generates the name of the secret that stores the canary token
+// of the honeytoken at the file path of a DeceptionPolicy.
+func GenerateCanaryTokenSecretName(deceptionPolicy *v1alpha1.DeceptionPolicy, filePath string) string {
+	return canaryTokenSecretNamePrefix + utils.Hash(deceptionPolicy.Name+":"+filePath)
+}
+
+// StoreCanaryToken stores a provisioned canary token in a secret in the Koney namespace, owned by the DeceptionPolicy,
+// and returns the reference to it that is recorded in the status. The secret of a canary token that was provisioned
+// for the same file path before (e.g., of another kind) is overwritten.
+func StoreCanaryToken(c client.Client, ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, filePath, kind string, canaryToken CanaryToken) (v1alpha1.CanaryTokenStatus, error) {
+	secretName := GenerateCanaryTokenSecretName(deceptionPolicy, filePath)
+	data := map[string][]byte{
+		CanaryTokenSecretKeyToken:           []byte(canaryToken.Token),
+		CanaryTokenSecretKeyKind:            []byte(kind),
+		CanaryTokenSecretKeyFilePath:        []byte(filePath),
+		CanaryTokenSecretKeyURL:             []byte(canaryToken.URL),
+		CanaryTokenSecretKeyHostname:        []byte(canaryToken.Hostname),
+		CanaryTokenSecretKeyAccessKeyID:     []byte(canaryToken.AccessKeyID),
+		CanaryTokenSecretKeySecretAccessKey: []byte(canaryToken.SecretAccessKey),
+	}
+
+	secret := corev1.Secret{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: secretName}, &secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return v1alpha1.CanaryTokenStatus{}, err
+	} else if err != nil {
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: utils.GetKoneyNamespace(),
+				Labels: map[string]string{
+					constants.LabelKeyDeceptionPolicyRef: deceptionPolicy.Name,
+					constants.LabelKeyCanaryToken:        "true",
+				},
+			},
+			Data: data,
+		}
+		addSecretOwner(&secret, deceptionPolicy)
+		err = c.Create(ctx, &secret)
+	} else {
+		secret.Data = data
+		addSecretOwner(&secret, deceptionPolicy)
+		err = c.Update(ctx, &secret)
+	}
+	if err != nil {
+		return v1alpha1.CanaryTokenStatus{}, err
+	}
+
+	return v1alpha1.CanaryTokenStatus{FilePath: filePath, Kind: kind, SecretName: secretName, ProvisionedAt: metav1.NewTime(time.Now())}, nil
+}
+
+// IsCanaryTokenProvisioned returns true if the trap embeds no canary token, or if its canary token was provisioned already.
+func IsCanaryTokenProvisioned(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) bool {
+	canaryToken := trap.FilesystemHoneytoken.CanaryToken
+	return canaryToken == nil || deceptionPolicy.Status.FindCanaryToken(trap.FilesystemHoneytoken.FilePath, canaryToken.Kind) != nil
+}
+
+// ResolveCanaryToken returns a copy of the trap whose file content embeds the canary token that was provisioned for it,
+// i.e., with the canary token placeholders replaced with the values that are stored in the secret of the canary token.
+// Traps without a canary token are returned unchanged. An error wrapping trapsapi.ErrCanaryTokenNotProvisioned is returned
+// if the canary token was not provisioned yet, or if its secret does not exist.
+func ResolveCanaryToken(c client.Reader, ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) (v1alpha1.Trap, error) {
+	if !trap.FilesystemHoneytoken.UsesCanaryToken() {
+		return trap, nil
+	}
+	filePath := trap.FilesystemHoneytoken.FilePath
+	canaryTokenStatus := deceptionPolicy.Status.FindCanaryToken(filePath, trap.FilesystemHoneytoken.CanaryToken.Kind)
+	if canaryTokenStatus == nil {
+		return trap, fmt.Errorf("%w for %s: not provisioned yet", trapsapi.ErrCanaryTokenNotProvisioned, filePath)
+	}
+
+	secret := corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: canaryTokenStatus.SecretName}, &secret); apierrors.IsNotFound(err) {
+		return trap, fmt.Errorf("%w for %s: secret '%s' not found", trapsapi.ErrCanaryTokenNotProvisioned, filePath, canaryTokenStatus.SecretName)
+	} else if err != nil {
+		return trap, err
+	}
+
+	replacer := strings.NewReplacer(
+		v1alpha1.CanaryTokenURLPlaceholder, string(secret.Data[CanaryTokenSecretKeyURL]),
+		v1alpha1.CanaryTokenHostnamePlaceholder, string(secret.Data[CanaryTokenSecretKeyHostname]),
+		v1alpha1.CanaryTokenAccessKeyIDPlaceholder, string(secret.Data[CanaryTokenSecretKeyAccessKeyID]),
+		v1alpha1.CanaryTokenSecretAccessKeyPlaceholder, string(secret.Data[CanaryTokenSecretKeySecretAccessKey]),
+	)
+	trap.FilesystemHoneytoken.FileContent = replacer.Replace(trap.FilesystemHoneytoken.FileContent)
+
+	return trap, nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("Canary tokens", func() {
+	var (
+		server         *httptest.Server
+		requestedForms []map[string]string
+		responseStatus int
+		responseBody   string
+	)
+
+	BeforeEach(func() {
+		requestedForms = nil
+		responseStatus = http.StatusOK
+		responseBody = `{"token": "abc123", "token_url": "http://canary.example.com/abc123/index.html", "hostname": "abc123.canary.example.com",
+			"aws_access_key_id": "AKIAEXAMPLE", "aws_secret_access_key": "secret"}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/generate"))
+			Expect(r.ParseForm()).To(Succeed())
+			requestedForms = append(requestedForms, map[string]string{
+				"type": r.PostForm.Get("type"), "memo": r.PostForm.Get("memo"), "webhook_url": r.PostForm.Get("webhook_url"),
+			})
+			w.WriteHeader(responseStatus)
+			_, _ = w.Write([]byte(responseBody))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("HTTPCanaryTokenProvisioner", func() {
+		It("should provision URL tokens", func() {
+			provisioner := &HTTPCanaryTokenProvisioner{URL: server.URL + "/", WebhookURL: "http://forwarder/handlers/canarytoken"}
+			canaryToken, err := provisioner.Provision(context.Background(), v1alpha1.CanaryTokenKindURL, "memo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestedForms).To(Equal([]map[string]string{{"type": "web", "memo": "memo", "webhook_url": "http://forwarder/handlers/canarytoken"}}))
+			Expect(canaryToken.Token).To(Equal("abc123"))
+			Expect(canaryToken.URL).To(Equal("http://canary.example.com/abc123/index.html"))
+			Expect(canaryToken.Hostname).To(Equal("abc123.canary.example.com"))
+			Expect(canaryToken.AccessKeyID).To(BeEmpty())
+		})
+
+		It("should provision AWS key tokens", func() {
+			provisioner := &HTTPCanaryTokenProvisioner{URL: server.URL}
+			canaryToken, err := provisioner.Provision(context.Background(), v1alpha1.CanaryTokenKindAWSKeys, "memo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestedForms[0]["type"]).To(Equal("aws_keys"))
+			Expect(canaryToken.AccessKeyID).To(Equal("AKIAEXAMPLE"))
+			Expect(canaryToken.SecretAccessKey).To(Equal("secret"))
+			Expect(canaryToken.URL).To(BeEmpty())
+		})
+
+		It("should provision DNS tokens", func() {
+			provisioner := &HTTPCanaryTokenProvisioner{URL: server.URL}
+			canaryToken, err := provisioner.Provision(context.Background(), v1alpha1.CanaryTokenKindDNS, "memo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requestedForms[0]["type"]).To(Equal("dns"))
+			Expect(canaryToken.Hostname).To(Equal("abc123.canary.example.com"))
+			Expect(canaryToken.URL).To(BeEmpty())
+		})
+
+		It("should fail if the service responds with an error", func() {
+			responseStatus = http.StatusBadRequest
+			responseBody = "invalid type"
+			provisioner := &HTTPCanaryTokenProvisioner{URL: server.URL}
+			_, err := provisioner.Provision(context.Background(), v1alpha1.CanaryTokenKindURL, "memo")
+			Expect(err).To(MatchError(ContainSubstring("status 400: invalid type")))
+		})
+
+		It("should fail if the service returns no token", func() {
+			responseBody = `{}`
+			provisioner := &HTTPCanaryTokenProvisioner{URL: server.URL}
+			_, err := provisioner.Provision(context.Background(), v1alpha1.CanaryTokenKindURL, "memo")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail without a configured service or for unknown kinds", func() {
+			_, err := (&HTTPCanaryTokenProvisioner{}).Provision(context.Background(), v1alpha1.CanaryTokenKindURL, "memo")
+			Expect(err).To(HaveOccurred())
+			_, err = (&HTTPCanaryTokenProvisioner{URL: server.URL}).Provision(context.Background(), "qr", "memo")
+			Expect(err).To(HaveOccurred())
+			Expect(requestedForms).To(BeEmpty())
+		})
+
+		It("should time out without a configured client", func() {
+			Expect(defaultCanaryTokenClient.Timeout).To(Equal(canaryTokenRequestTimeout))
+		})
+	})
+
+	Describe("ResolveCanaryToken", func() {
+		var (
+			fakeClient      client.Client
+			deceptionPolicy v1alpha1.DeceptionPolicy
+			trap            v1alpha1.Trap
+		)
+
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().Build()
+			deceptionPolicy = v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", UID: "uid"}}
+			trap = v1alpha1.Trap{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
+					FilePath: "/root/.aws/credentials",
+					FileContent: "aws_access_key_id = " + v1alpha1.CanaryTokenAccessKeyIDPlaceholder +
+						"\naws_secret_access_key = " + v1alpha1.CanaryTokenSecretAccessKeyPlaceholder,
+					CanaryToken: &v1alpha1.CanaryToken{Kind: v1alpha1.CanaryTokenKindAWSKeys},
+				},
+			}
+		})
+
+		It("should fail until the canary token is provisioned", func() {
+			Expect(IsCanaryTokenProvisioned(&deceptionPolicy, trap)).To(BeFalse())
+			_, err := ResolveCanaryToken(fakeClient, context.Background(), &deceptionPolicy, trap)
+			Expect(err).To(MatchError(trapsapi.ErrCanaryTokenNotProvisioned))
+		})
+
+		It("should store the canary token in a secret and embed it", func() {
+			canaryToken := CanaryToken{Token: "abc123", AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"}
+			canaryTokenStatus, err := StoreCanaryToken(fakeClient, context.Background(), &deceptionPolicy, "/root/.aws/credentials", v1alpha1.CanaryTokenKindAWSKeys, canaryToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(canaryTokenStatus.SecretName).To(Equal(GenerateCanaryTokenSecretName(&deceptionPolicy, "/root/.aws/credentials")))
+			Expect(canaryTokenStatus.ProvisionedAt.IsZero()).To(BeFalse())
+
+			secret := corev1.Secret{}
+			Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: canaryTokenStatus.SecretName}, &secret)).To(Succeed())
+			Expect(secret.Labels).To(HaveKeyWithValue(constants.LabelKeyCanaryToken, "true"))
+			Expect(secret.Labels).To(HaveKeyWithValue(constants.LabelKeyDeceptionPolicyRef, "policy"))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.Data).To(HaveKeyWithValue(CanaryTokenSecretKeyToken, []byte("abc123")))
+			Expect(secret.Data).To(HaveKeyWithValue(CanaryTokenSecretKeyFilePath, []byte("/root/.aws/credentials")))
+
+			deceptionPolicy.Status.PutCanaryToken(canaryTokenStatus)
+			Expect(IsCanaryTokenProvisioned(&deceptionPolicy, trap)).To(BeTrue())
+
+			resolvedTrap, err := ResolveCanaryToken(fakeClient, context.Background(), &deceptionPolicy, trap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolvedTrap.FilesystemHoneytoken.FileContent).To(Equal("aws_access_key_id = AKIAEXAMPLE\naws_secret_access_key = secret"))
+			Expect(trap.FilesystemHoneytoken.FileContent).To(ContainSubstring(v1alpha1.CanaryTokenAccessKeyIDPlaceholder))
+
+			// Provisioning the canary token again overwrites the secret
+			_, err = StoreCanaryToken(fakeClient, context.Background(), &deceptionPolicy, "/root/.aws/credentials", v1alpha1.CanaryTokenKindAWSKeys, CanaryToken{Token: "def456"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: canaryTokenStatus.SecretName}, &secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue(CanaryTokenSecretKeyToken, []byte("def456")))
+		})
+
+		It("should fail if the secret of the canary token was deleted", func() {
+			deceptionPolicy.Status.PutCanaryToken(v1alpha1.CanaryTokenStatus{
+				FilePath: "/root/.aws/credentials", Kind: v1alpha1.CanaryTokenKindAWSKeys, SecretName: "koney-canarytoken-missing",
+			})
+			_, err := ResolveCanaryToken(fakeClient, context.Background(), &deceptionPolicy, trap)
+			Expect(err).To(MatchError(trapsapi.ErrCanaryTokenNotProvisioned))
+			Expect(err).To(MatchError(ContainSubstring("koney-canarytoken-missing")))
+		})
+
+		It("should not embed a canary token of another kind", func() {
+			deceptionPolicy.Status.PutCanaryToken(v1alpha1.CanaryTokenStatus{FilePath: "/root/.aws/credentials", Kind: v1alpha1.CanaryTokenKindDNS, SecretName: "abc123"})
+			Expect(IsCanaryTokenProvisioned(&deceptionPolicy, trap)).To(BeFalse())
+			_, err := ResolveCanaryToken(fakeClient, context.Background(), &deceptionPolicy, trap)
+			Expect(err).To(MatchError(trapsapi.ErrCanaryTokenNotProvisioned))
+		})
+
+		It("should ignore traps without canary tokens", func() {
+			Expect(IsCanaryTokenProvisioned(&deceptionPolicy, v1alpha1.Trap{})).To(BeTrue())
+			Expect(ResolveCanaryToken(fakeClient, context.Background(), &deceptionPolicy, v1alpha1.Trap{})).To(Equal(v1alpha1.Trap{}))
+		})
+	})
+})
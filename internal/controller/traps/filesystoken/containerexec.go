@@ -0,0 +1,268 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/matching"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+// placementScriptMarker separates the output of the decoys in the output of a placement script.
+// Each decoy is followed by a line with the marker, the index of the decoy, the last step, and its exit status.
+const placementScriptMarker = "::koney-placement::"
+
+// execPlacementKey identifies a container of a pod in which decoys are placed with the containerExec strategy.
+type execPlacementKey struct {
+	namespace, pod, container string
+}
+
+// execPlacements holds the results of the decoys that were placed with the containerExec strategy, by container and file path.
+type execPlacements map[execPlacementKey]map[string]error
+
+// lookup returns true and the result of the decoy at the file path if it was placed in the container of the pod.
+func (p execPlacements) lookup(pod corev1.Pod, containerName, filePath string) (bool, error) {
+	results, ok := p[execPlacementKey{pod.Namespace, pod.Name, containerName}]
+	if !ok {
+		return false, nil
+	}
+	err, ok := results[filePath]
+	return ok, err
+}
+
+// containerExecPlan collects the decoys that are placed in a container of a pod with a single exec.
+type containerExecPlan struct {
+	pod           corev1.Pod
+	containerName string
+	traps         []v1alpha1.Trap
+	results       map[string]error
+}
+
+// placeDecoysWithContainerExec places the decoys of all containerExec traps in the containers of their matched pods
+// where they are not placed yet, with a single exec per container that covers the decoys of all traps.
+// Traps without a matching result (because matching failed) are skipped.
+func (r *FilesystemHoneytokenReconciler) placeDecoysWithContainerExec(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy,
+	traps []v1alpha1.Trap, matchingResults []*matching.MatchingResult) execPlacements {
+	plans := map[execPlacementKey]*containerExecPlan{}
+	for i, trap := range traps {
+		if trap.DecoyDeployment.Strategy != "containerExec" || matchingResults[i] == nil {
+			continue
+		}
+
+		for resource, selectedContainers := range matchingResults[i].DeployableObjects {
+			pod, ok := resource.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+			// Errors are reported when the trap is recorded in the annotation of the pod
+			alreadyDeployedToContainers, err := getDeployedContainersOfTrap(resource, deceptionPolicy.Name, trap)
+			if err != nil {
+				continue
+			}
+
+			for _, containerName := range selectedContainers {
+				// Containers that wait for another attempt are skipped (see deployDecoyToResource)
+				if utils.Contains(alreadyDeployedToContainers, containerName) ||
+					r.PlacementRetries.next(deceptionPolicy, newPlacementTarget(resource, containerName, trap), time.Now()) != placementDue {
+					continue
+				}
+
+				key := execPlacementKey{pod.Namespace, pod.Name, containerName}
+				plan, ok := plans[key]
+				if !ok {
+					plan = &containerExecPlan{pod: *pod, containerName: containerName}
+					plans[key] = plan
+				}
+				// Traps with the same file path are placed on their own, since their results cannot be told apart
+				if !hasTrapWithFilePath(plan.traps, trap.FilesystemHoneytoken.FilePath) {
+					plan.traps = append(plan.traps, trap)
+				}
+			}
+		}
+	}
+
+	// Errors are collected per decoy, so that they are reported with their trap
+	_ = utils.ForEachConcurrently(utils.GetMapKeys(plans), utils.GetPlacementConcurrency(), func(key execPlacementKey) error {
+		plan := plans[key]
+		plan.results = r.placeDecoysInContainer(ctx, plan.pod, plan.containerName, plan.traps)
+		return nil
+	})
+
+	placements := make(execPlacements, len(plans))
+	for key, plan := range plans {
+		placements[key] = plan.results
+	}
+	return placements
+}
+
+// placeDecoysInContainer places the decoys of the given traps in a container of a pod with a single exec, which creates
+// the directories, writes the files, reads them back to verify their content, and makes them read-only if needed.
+// Containers without a shell get the decoys with tar instead (see placeDecoysWithTar).
+// It returns the result of each decoy by file path.
+func (r *FilesystemHoneytokenReconciler) placeDecoysInContainer(ctx context.Context, pod corev1.Pod, containerName string, traps []v1alpha1.Trap) map[string]error {
+	log := k8slog.FromContext(ctx)
+
+	// The content of each decoy is rendered for this pod, before it is written and compared with what was read back
+	traps = ResolvePlacementContexts(traps, pod)
+
+	results := make(map[string]error, len(traps))
+	method, err := r.detectPlacementMethod(ctx, pod, containerName)
+	if err != nil {
+		log.Error(err, "unable to detect how to deploy FilesystemHoneytoken traps to container", "container", containerName)
+		for _, trap := range traps {
+			results[trap.FilesystemHoneytoken.FilePath] = err
+		}
+		return results
+	} else if method == placementMethodTar {
+		return r.placeDecoysWithTar(ctx, pod, containerName, traps)
+	}
+
+	output, err := r.executeCommandInContainer(ctx, pod, containerName, []string{"sh", "-c", buildPlacementScript(traps)})
+	if err != nil {
+		log.Error(err, "unable to deploy FilesystemHoneytoken traps to container", "container", containerName, "stderr", output)
+		for _, trap := range traps {
+			results[trap.FilesystemHoneytoken.FilePath] = err
+		}
+		return results
+	}
+
+	outcomes := parsePlacementOutput(output)
+	for i, trap := range traps {
+		filePath := trap.FilesystemHoneytoken.FilePath
+		err := checkPlacementOutcome(trap, outcomes[i])
+		if err != nil {
+			log.Error(err, "unable to deploy FilesystemHoneytoken trap to container", "container", containerName, "filePath", filePath)
+		} else {
+			log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName, "filePath", filePath)
+		}
+		results[filePath] = err
+	}
+	return results
+}
+
+// placementOutcome is the output of a decoy in the output of a placement script.
+type placementOutcome struct {
+	// content is what the script read from the file after writing it
+	content string
+	// step is the last step of the script for the decoy, which failed if the status is not zero
+	step   string
+	status int
+}
+
+// buildPlacementScript builds a shell script that places the decoys of the given traps in a container.
+// For each decoy, the script prints the content of the file after writing it, followed by a line with the
+// placementScriptMarker, the index of the decoy, the last step, and its exit status, which parsePlacementOutput reads.
+// The script continues with the next decoy if a step fails.
+func buildPlacementScript(traps []v1alpha1.Trap) string {
+	// mark the commands with a fingerprint so that we won't alert on them later
+	echoFingerprint := alerts.EncodeFingerprintInEcho(utils.GetKoneyFingerprint())
+	catFingerprint := alerts.EncodeFingerprintInCat(utils.GetKoneyFingerprint())
+
+	var script strings.Builder
+	for i, trap := range traps {
+		filePath := trap.FilesystemHoneytoken.FilePath
+
+		var writeCommand string
+		if trap.FilesystemHoneytoken.FileContent != "" {
+			// To avoid issues with special characters (e.g., command injection vulnerabilities),
+			// we first encode the content in octal (sh does not like hex) and then decode it in the container
+			// $(which echo) is used to avoid issues with the shell built-in echo command
+			octalContent := utils.StringToOct(trap.FilesystemHoneytoken.FileContent)
+			writeCommand = "{ oct_string=\"" + octalContent + "\"; i=1; while [ $i -lt ${#oct_string} ]; do $(which echo) -e \"\\0$(expr substr $oct_string $i 3)\\c " +
+				echoFingerprint + "\"; i=$(expr $i + 3); done > \"" + filePath + "\"; }"
+		} else {
+			// We don't use touch because if the file already includes content, touch would not make it empty
+			writeCommand = "$(which echo) -e \"\\c " + echoFingerprint + "\" > \"" + filePath + "\""
+		}
+
+		script.WriteString("step=mkdir; mkdir -p \"" + filepath.Dir(filePath) + "\" && step=write && " + writeCommand +
+			" && step=cat && cat " + catFingerprint + " \"" + filePath + "\"")
+		if trap.FilesystemHoneytoken.IsReadOnly() {
+			script.WriteString(" && step=chmod && chmod 444 \"" + filePath + "\"")
+		}
+		fmt.Fprintf(&script, "; status=$?; printf '\\n%%s %%d %%s %%d\\n' %q %d \"$step\" \"$status\"\n", placementScriptMarker, i)
+	}
+	return script.String()
+}
+
+// parsePlacementOutput reads the outcome of each decoy from the output of a placement script, by the index of the decoy.
+func parsePlacementOutput(output string) map[int]placementOutcome {
+	outcomes := map[int]placementOutcome{}
+	for {
+		content, rest, found := strings.Cut(output, "\n"+placementScriptMarker+" ")
+		if !found {
+			return outcomes
+		}
+
+		line, remainder, _ := strings.Cut(rest, "\n")
+		var (
+			index   int
+			outcome placementOutcome
+		)
+		if _, err := fmt.Sscanf(line, "%d %s %d", &index, &outcome.step, &outcome.status); err == nil {
+			outcome.content = content
+			outcomes[index] = outcome
+		}
+		output = remainder
+	}
+}
+
+// checkPlacementOutcome returns an error if the decoy of the trap was not placed as expected.
+func checkPlacementOutcome(trap v1alpha1.Trap, outcome placementOutcome) error {
+	if outcome.step == "" {
+		return errors.New("the placement script did not report on the decoy")
+	}
+
+	var err error
+	switch {
+	case outcome.status == 0 || outcome.step == "chmod":
+		// TrimSuffix removes the trailing newline
+		if strings.TrimSuffix(outcome.content, "\n") != strings.TrimSuffix(trap.FilesystemHoneytoken.FileContent, "\n") {
+			err = errors.New("the content of the file is not the expected content")
+		}
+		if outcome.status != 0 {
+			err = errors.Join(err, fmt.Errorf("unable to make the file read-only (exit status %d)", outcome.status))
+		}
+	case outcome.step == "mkdir":
+		err = fmt.Errorf("unable to create directory with mkdir (exit status %d)", outcome.status)
+	case outcome.step == "write":
+		err = fmt.Errorf("unable to write the file (exit status %d)", outcome.status)
+	default:
+		err = fmt.Errorf("unable to read the content of the file (exit status %d)", outcome.status)
+	}
+	return err
+}
+
+// hasTrapWithFilePath checks if one of the traps places a decoy at the file path.
+func hasTrapWithFilePath(traps []v1alpha1.Trap, filePath string) bool {
+	for _, trap := range traps {
+		if trap.FilesystemHoneytoken.FilePath == filePath {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("containerExec placement scripts", func() {
+	var (
+		dir   string
+		traps []v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		traps = []v1alpha1.Trap{
+			{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filepath.Join(dir, "secrets", "token"), FileContent: "some \"token\"\n$(id)", ReadOnly: &[]bool{true}[0]}},
+			{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filepath.Join(dir, "empty")}},
+		}
+	})
+
+	runPlacementScript := func() map[int]placementOutcome {
+		output, err := exec.Command("sh", "-c", buildPlacementScript(traps)).Output()
+		Expect(err).NotTo(HaveOccurred())
+		return parsePlacementOutput(string(output))
+	}
+
+	It("should place all decoys with one script", func() {
+		outcomes := runPlacementScript()
+		Expect(outcomes).To(HaveLen(2))
+		for i, trap := range traps {
+			Expect(checkPlacementOutcome(trap, outcomes[i])).To(Succeed())
+		}
+
+		content, err := os.ReadFile(traps[0].FilesystemHoneytoken.FilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(traps[0].FilesystemHoneytoken.FileContent))
+		info, err := os.Stat(traps[0].FilesystemHoneytoken.FilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0444)))
+		Expect(traps[1].FilesystemHoneytoken.FilePath).To(BeARegularFile())
+	})
+
+	It("should report failed decoys and still place the others", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "secrets"), nil, 0644)).To(Succeed())
+
+		outcomes := runPlacementScript()
+		Expect(checkPlacementOutcome(traps[0], outcomes[0])).To(MatchError(ContainSubstring("unable to create directory")))
+		Expect(checkPlacementOutcome(traps[1], outcomes[1])).To(Succeed())
+	})
+
+	It("should fail if the script did not report on a decoy", func() {
+		Expect(parsePlacementOutput("unexpected output")).To(BeEmpty())
+		Expect(checkPlacementOutcome(traps[0], placementOutcome{})).NotTo(Succeed())
+	})
+
+	It("should only look up the results of decoys that were placed together", func() {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "koney-demo", Name: "web"}}
+		placementErr := errors.New("exec failed")
+		reconciler := FilesystemHoneytokenReconciler{execPlacements: execPlacements{
+			{namespace: "koney-demo", pod: "web", container: "app"}: {traps[0].FilesystemHoneytoken.FilePath: placementErr},
+		}}
+
+		Expect(reconciler.deployDecoyWithContainerExec(context.TODO(), traps[0], pod, "app")).To(MatchError(placementErr))
+		placed, _ := reconciler.execPlacements.lookup(pod, "app", traps[1].FilesystemHoneytoken.FilePath)
+		Expect(placed).To(BeFalse())
+		placed, _ = reconciler.execPlacements.lookup(pod, "worker", traps[0].FilesystemHoneytoken.FilePath)
+		Expect(placed).To(BeFalse())
+	})
+})
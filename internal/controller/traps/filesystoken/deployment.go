@@ -16,23 +16,27 @@
 package filesystoken
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	kivev1 "github.com/San7o/kivebpf/api/v1"
 	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
@@ -51,15 +55,35 @@ type FilesystemHoneytokenReconciler struct {
 	Clientset kubernetes.Clientset
 	Config    rest.Config
 
+	// CaptorReader is used to look up existing captors, if set (otherwise, the client is used).
+	CaptorReader client.Reader
+
+	// APIReader reads directly from the API server, e.g., the Falco rules ConfigMap (otherwise, the client is used).
+	APIReader client.Reader
+
+	// Recorder emits events about the traps on the DeceptionPolicy, if set.
+	Recorder record.EventRecorder
+
 	DeceptionPolicy *v1alpha1.DeceptionPolicy
+
+	// PlacementRetries tracks the failed placements of decoys, so that they are retried with backoff, if set (otherwise, they are retried immediately).
+	PlacementRetries *PlacementRetries
+
+	// execPlacements holds the decoys that DeployDecoys placed with the containerExec strategy before recording their traps.
+	execPlacements execPlacements
 }
 
 // DeployDecoy deploys a FilesystemHoneytoken decoy.
 // The trap is only deployed to the resources where the trap is not already deployed.
 // The boolean return type indicates if any of the resources was not ready yet and this function should be called again later.
 func (r *FilesystemHoneytokenReconciler) DeployDecoy(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) trapsapi.DecoyDeploymentResult {
+	return r.DeployDecoys(ctx, deceptionPolicy, []v1alpha1.Trap{trap})[0]
+}
+
+// DeployDecoys deploys the FilesystemHoneytoken decoys of several traps, like DeployDecoy, and returns their results in the same order.
+// The decoys of containerExec traps are placed with a single exec per container for all traps, instead of several execs per decoy.
+func (r *FilesystemHoneytokenReconciler) DeployDecoys(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap) []trapsapi.DecoyDeploymentResult {
 	log := k8slog.FromContext(ctx)
-	var joinedErrors error
 
 	// If we aren't allowed to mutate existing resources, we avoid matching resources created before the policy was created
 	var filterCreatedAfter metav1.Time
@@ -67,282 +91,629 @@ func (r *FilesystemHoneytokenReconciler) DeployDecoy(ctx context.Context, decept
 		filterCreatedAfter = deceptionPolicy.CreationTimestamp
 	}
 
-	// Get matching resources and the matched containers: pods for containerExec, deployments for volumeMount
-	matchingResult, err := matching.GetDeployableObjectsWithContainers(r, ctx, trap, &filterCreatedAfter)
-	if err != nil {
-		log.Error(err, "unable to get matching resources")
-		// wrap error with message "unable to get matching resources"
-		return trapsapi.DecoyDeploymentResult{Errors: errors.Join(err, errors.New("unable to get matching resources"))}
-	} else if len(matchingResult.DeployableObjects) == 0 {
+	// Traps with the "auto" strategy are deployed with the strategy that it chooses, which is also recorded in the annotations,
+	// while the results refer to the original traps
+	deployTraps := make([]v1alpha1.Trap, len(traps))
+	for i, trap := range traps {
+		deployTraps[i] = ResolveDecoyStrategy(ctx, r.Client, trap)
+	}
+
+	// Get matching resources and the matched containers: pods for containerExec, ephemeralContainer, and admission, deployments for volumeMount, projectedVolume, imageVolume, and initContainer
+	results := make([]trapsapi.DecoyDeploymentResult, len(traps))
+	matchingResults := make([]*matching.MatchingResult, len(traps))
+	for i, trap := range deployTraps {
+		matchingResult, err := matching.GetDeployableObjectsWithContainers(r, ctx, trap, &filterCreatedAfter)
+		if err != nil {
+			log.Error(err, "unable to get matching resources")
+			// wrap error with message "unable to get matching resources"
+			results[i] = trapsapi.DecoyDeploymentResult{Errors: errors.Join(err, errors.New("unable to get matching resources"))}
+			continue
+		}
+		matchingResults[i] = &matchingResult
+	}
+
+	// Place the decoys of containerExec traps first, with one exec per container, so that only their results are looked up below
+	r.execPlacements = r.placeDecoysWithContainerExec(ctx, deceptionPolicy, deployTraps, matchingResults)
+	defer func() { r.execPlacements = nil }()
+
+	for i, trap := range deployTraps {
+		if matchingResults[i] != nil {
+			results[i] = r.deployDecoyToMatchingResources(ctx, deceptionPolicy, trap, *matchingResults[i])
+		}
+		results[i].Trap = &traps[i]
+	}
+	return results
+}
+
+// deployDecoyToMatchingResources deploys a FilesystemHoneytoken decoy to the resources that the trap matched.
+func (r *FilesystemHoneytokenReconciler) deployDecoyToMatchingResources(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy,
+	trap v1alpha1.Trap, matchingResult matching.MatchingResult) trapsapi.DecoyDeploymentResult {
+	if len(matchingResult.DeployableObjects) == 0 {
 		return trapsapi.DecoyDeploymentResult{
 			AtLeastOneObjectsWasMatched: matchingResult.AtLeastOneObjectWasMatched,
 			AllObjectsWereReady:         matchingResult.AllDeployableObjectsWereReady}
 	}
 
-	// Deploy the trap to the matching resources
-	for resource, selectedContainers := range matchingResult.DeployableObjects {
-		// Check if the trap was already deployed to the resource (and to which containers)
-		// Get the resource's changes annotation
-		changes, err := annotations.GetAnnotationChange(resource, deceptionPolicy.Name) // Empty if the annotation does not exist
+	// Deploy the trap to the matching resources, several at a time, since placing decoys in hundreds of pods takes a while
+	var isWaiting atomic.Bool
+	resources := utils.GetMapKeys(matchingResult.DeployableObjects)
+	joinedErrors := utils.ForEachConcurrently(resources, utils.GetPlacementConcurrency(), func(resource client.Object) error {
+		waiting, err := r.deployDecoyToResource(ctx, deceptionPolicy, trap, resource, matchingResult.DeployableObjects[resource])
+		if waiting {
+			isWaiting.Store(true)
+		}
+		return err
+	})
+
+	// Containers that wait for another attempt are treated like containers that are not ready yet, so that the deployment is retried soon
+	return trapsapi.DecoyDeploymentResult{
+		AtLeastOneObjectsWasMatched: matchingResult.AtLeastOneObjectWasMatched,
+		AllObjectsWereReady:         matchingResult.AllDeployableObjectsWereReady && !isWaiting.Load(),
+		Errors:                      joinedErrors}
+}
+
+// deployDecoyToResource deploys a FilesystemHoneytoken decoy to the selected containers of a resource and records the trap in its annotation.
+// It is called concurrently for different resources, so it must not share state with other calls except through the API server.
+// The boolean return value indicates if some containers are waiting for another attempt after placing the decoy failed before.
+func (r *FilesystemHoneytokenReconciler) deployDecoyToResource(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap,
+	resource client.Object, selectedContainers []string) (bool, error) {
+	log := k8slog.FromContext(ctx)
+	var joinedErrors error
+	var isWaiting bool
+
+	// Check if the trap was already deployed to the resource (and to which containers)
+	alreadyDeployedToContainers, err := getDeployedContainersOfTrap(resource, deceptionPolicy.Name, trap)
+	if err != nil {
+		log.Error(err, "unable to get annotation changes")
+		return false, err
+	}
+
+	var deployedToContainers []string // Containers where at the end of the function the trap is deployed to
+
+	// Deploy the trap to the selected container(s)
+	for _, containerName := range selectedContainers {
+		if utils.Contains(alreadyDeployedToContainers, containerName) {
+			log.Info("FilesystemHoneytoken trap already deployed to container", "resource", resource.GetName(), "container", containerName)
+
+			// We need to add it here regardless to update the annotation
+			// Note that, since we are cycling through the selected containers,
+			// this will not add containers where the trap was already deployed but that do not exist anymore
+			deployedToContainers = append(deployedToContainers, containerName)
+
+			// The projectedVolume strategy guarantees the integrity of the honeytoken, so we restore its secret if it was tampered with
+			if trap.DecoyDeployment.Strategy == "projectedVolume" {
+				if err := r.ensureSecretOfProjectedVolume(ctx, trap, resource.GetNamespace()); err != nil {
+					joinedErrors = errors.Join(joinedErrors, err)
+				}
+			}
+			continue
+		}
+
+		// Containers where placing the decoy failed before are only tried again after a backoff, and only a few times
+		target := newPlacementTarget(resource, containerName, trap)
+		switch r.PlacementRetries.next(deceptionPolicy, target, time.Now()) {
+		case placementBackoff:
+			isWaiting = true
+			continue
+		case placementGaveUp:
+			joinedErrors = errors.Join(joinedErrors, fmt.Errorf("gave up placing decoy %s in container %s of %s %s/%s after %d attempts",
+				trap.FilesystemHoneytoken.FilePath, containerName, target.Kind, target.Namespace, target.Name, constants.PlacementMaxAttempts))
+			continue
+		}
+
+		// Deploy the trap to the container
+		deployed, err := r.deployDecoyToContainer(ctx, trap, resource, containerName)
 		if err != nil {
-			log.Error(err, "unable to get annotation changes")
 			joinedErrors = errors.Join(joinedErrors, err)
-			continue
+			r.recordEvent(corev1.EventTypeWarning, constants.EventReasonPlacementFailed, "Decoy %s cannot be placed in container %s of %s %s/%s: %v",
+				trap.FilesystemHoneytoken.FilePath, containerName, target.Kind, target.Namespace, target.Name, err)
+			if r.PlacementRetries.failed(deceptionPolicy, target, err, time.Now()) {
+				r.recordEvent(corev1.EventTypeWarning, constants.EventReasonPlacementFailed, "Gave up placing decoy %s in container %s of %s %s/%s after %d attempts",
+					trap.FilesystemHoneytoken.FilePath, containerName, target.Kind, target.Namespace, target.Name, constants.PlacementMaxAttempts)
+			}
+		} else if deployed {
+			deployedToContainers = append(deployedToContainers, containerName)
+			r.PlacementRetries.succeeded(deceptionPolicy, target)
 		}
+	}
 
-		var alreadyDeployedToContainers []string // Containers where the trap was already deployed
-		var deployedToContainers []string        // Containers where at the end of the function the trap is deployed to
+	// Annotate the pod with the trap
+	if len(deployedToContainers) > 0 {
+		// Use RetryOnConflict to elegantly avoid conflicts when updating a resource
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
+				return err
+			}
 
-		// Cycle through the traps in the annotation
-		for _, annotationTrap := range changes.Traps {
-			// Are areTheSameTrap checks if two traps are the same, ignoring the containers field
-			// since Trap does not have a list of containers, but only a containerSelector
-			if annotations.AreTheSameTrap(annotationTrap, trap) {
-				// The trap was already deployed to the containers in the annotation
-				alreadyDeployedToContainers = append(alreadyDeployedToContainers, annotationTrap.Containers...)
+			// Add the trap to the pod annotations
+			err := annotations.AddTrapToAnnotations(resource, deceptionPolicy.Name, trap, deployedToContainers)
+			if err != nil {
+				log.Error(err, "unable to add trap to resource annotations", "resource", resource.GetName())
+				joinedErrors = errors.Join(joinedErrors, err)
 			}
+
+			// TODO: Can we use patch instead of update to avoid conflicts?
+			return r.Update(ctx, resource)
+		})
+		if err != nil {
+			log.Error(err, "unable to update resource", "resource", resource.GetName())
+			joinedErrors = errors.Join(joinedErrors, err)
+		} else if newContainers := slices.DeleteFunc(slices.Clone(deployedToContainers), func(containerName string) bool {
+			return slices.Contains(alreadyDeployedToContainers, containerName)
+		}); len(newContainers) > 0 {
+			r.recordEvent(corev1.EventTypeNormal, constants.EventReasonTrapDeployed, "Decoy %s placed in containers %v of %s %s/%s",
+				trap.FilesystemHoneytoken.FilePath, newContainers, utils.ResourceKind(resource), resource.GetNamespace(), resource.GetName())
+		}
+	}
+
+	return isWaiting, joinedErrors
+}
+
+// newPlacementTarget returns the target of placing the decoy of a trap in a container of a resource.
+func newPlacementTarget(resource client.Object, containerName string, trap v1alpha1.Trap) PlacementTarget {
+	return PlacementTarget{Kind: utils.ResourceKind(resource), Namespace: resource.GetNamespace(), Name: resource.GetName(),
+		Container: containerName, FilePath: trap.FilesystemHoneytoken.FilePath}
+}
+
+// getDeployedContainersOfTrap returns the containers of a resource where the trap was already deployed, according to its changes annotation.
+func getDeployedContainersOfTrap(resource client.Object, deceptionPolicyName string, trap v1alpha1.Trap) ([]string, error) {
+	changes, err := annotations.GetAnnotationChange(resource, deceptionPolicyName) // Empty if the annotation does not exist
+	if err != nil {
+		return nil, err
+	}
+
+	var deployedToContainers []string
+	for _, annotationTrap := range changes.Traps {
+		// AreTheSameTrap checks if two traps are the same, ignoring the containers field
+		// since Trap does not have a list of containers, but only a containerSelector
+		if annotations.AreTheSameTrap(annotationTrap, trap) {
+			deployedToContainers = append(deployedToContainers, annotationTrap.Containers...)
 		}
+	}
+	return deployedToContainers, nil
+}
 
-		// Deploy the trap to the selected container(s)
-		for _, containerName := range selectedContainers {
-			if utils.Contains(alreadyDeployedToContainers, containerName) {
-				log.Info("FilesystemHoneytoken trap already deployed to container", "resource", resource.GetName(), "container", containerName)
+// deployDecoyToContainer deploys a FilesystemHoneytoken decoy to a container of a resource with the decoy deployment strategy of the trap.
+// The boolean return value indicates if the decoy is deployed to the container (e.g., the admission strategy only deploys it when pods are created).
+func (r *FilesystemHoneytokenReconciler) deployDecoyToContainer(ctx context.Context, trap v1alpha1.Trap, resource client.Object, containerName string) (bool, error) {
+	log := k8slog.FromContext(ctx)
 
-				// We need to add it here regardless to update the annotation
-				// Note that, since we are cycling through the selected containers,
-				// this will not add containers where the trap was already deployed but that do not exist anymore
-				deployedToContainers = append(deployedToContainers, containerName)
-				continue
+	switch trap.DecoyDeployment.Strategy {
+	case "projectedVolume":
+		// The projectedVolume strategy deploys the honeytoken mounting a read-only projected volume in the deployment to the containers
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			if err := r.deployDecoyWithProjectedVolume(ctx, trap, *deployment, containerName); err != nil {
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with projectedVolume strategy", "container", containerName)
+				return false, err
 			}
+			return true, nil
+		}
 
-			// Deploy the trap to the container
-			switch trap.DecoyDeployment.Strategy {
-			case "containerExec":
-				// The containerExec strategy deploys the honeytoken directly to containers inside a pod
-				if pod, ok := resource.(*corev1.Pod); ok {
-					if err := r.deployDecoyWithContainerExec(ctx, trap, *pod, containerName); err != nil {
-						log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with containerExec strategy", "container", containerName)
-						joinedErrors = errors.Join(joinedErrors, err)
-					} else {
-						deployedToContainers = append(deployedToContainers, containerName)
-					}
-				}
+	case "containerExec":
+		// The containerExec strategy deploys the honeytoken directly to containers inside a pod
+		if pod, ok := resource.(*corev1.Pod); ok {
+			if err := r.deployDecoyWithContainerExec(ctx, trap, *pod, containerName); err != nil {
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with containerExec strategy", "container", containerName)
+				return false, err
+			}
+			return true, nil
+		}
 
-			case "volumeMount":
-				// The volumeMount strategy deploys the honeytoken mounting a volume in the deployment to the containers
-				if deployment, ok := resource.(*appsv1.Deployment); ok {
-					if err := r.deployDecoyWithVolumeMount(ctx, trap, *deployment, containerName); err != nil {
-						log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with volumeMount strategy", "container", containerName)
-						joinedErrors = errors.Join(joinedErrors, err)
-					} else {
-						deployedToContainers = append(deployedToContainers, containerName)
-					}
-				}
+	case "ephemeralContainer":
+		// The ephemeralContainer strategy deploys the honeytoken with an ephemeral container that shares the process namespace of the container
+		if pod, ok := resource.(*corev1.Pod); ok {
+			if err := r.deployDecoyWithEphemeralContainer(ctx, trap, *pod, containerName); err != nil {
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with ephemeralContainer strategy", "container", containerName)
+				return false, err
+			}
+			return true, nil
+		}
 
-			case "kyvernoPolicy":
-				log.Info("KyvernoPolicy strategy not implemented yet")
-				joinedErrors = errors.Join(joinedErrors, errors.New("KyvernoPolicy strategy not implemented yet"))
-			default:
-				log.Error(nil, "unknown strategy", "strategy", trap.DecoyDeployment.Strategy)
-				joinedErrors = errors.Join(joinedErrors, errors.New("unknown strategy"))
+	case "volumeMount":
+		// The volumeMount strategy deploys the honeytoken mounting a volume in the deployment to the containers
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			if err := r.deployDecoyWithVolumeMount(ctx, trap, *deployment, containerName); err != nil {
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with volumeMount strategy", "container", containerName)
+				return false, err
 			}
+			return true, nil
 		}
 
-		// Annotate the pod with the trap
-		if len(deployedToContainers) > 0 {
-			// Use RetryOnConflict to elegantly avoid conflicts when updating a resource
-			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-				if err := r.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
-					return err
-				}
+	case "imageVolume":
+		// The imageVolume strategy deploys the honeytoken by mounting a file from an OCI image in the deployment to the containers
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			if err := r.deployDecoyWithImageVolume(ctx, trap, *deployment, containerName); err != nil {
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with imageVolume strategy", "container", containerName)
+				return false, err
+			}
+			return true, nil
+		}
 
-				// Add the trap to the pod annotations
-				err := annotations.AddTrapToAnnotations(resource, deceptionPolicy.Name, trap, deployedToContainers)
-				if err != nil {
-					log.Error(err, "unable to add trap to resource annotations", "resource", resource.GetName())
-					joinedErrors = errors.Join(joinedErrors, err)
-				}
+	case "initContainer":
+		// The initContainer strategy deploys the honeytoken with an init container that writes it into a volume in the deployment
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			if err := r.deployDecoyWithInitContainer(ctx, trap, *deployment, containerName); err != nil {
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with initContainer strategy", "container", containerName)
+				return false, err
+			}
+			return true, nil
+		}
 
-				// TODO: Can we use patch instead of update to avoid conflicts?
-				return r.Update(ctx, resource)
-			})
+	case "admission":
+		// The admission strategy deploys the honeytoken with the mutating webhook when pods are created,
+		// so we only keep track of the pods that the webhook injected the decoy into
+		if pod, ok := resource.(*corev1.Pod); ok {
+			deployed, err := r.deployDecoyWithAdmission(ctx, trap, *pod, containerName)
 			if err != nil {
-				log.Error(err, "unable to update resource", "resource", resource.GetName())
-				joinedErrors = errors.Join(joinedErrors, err)
+				log.Error(err, "unable to deploy FilesystemHoneytoken trap to container with admission strategy", "container", containerName)
+				return false, err
 			}
+			return deployed, nil
 		}
+
+	case "kyvernoPolicy":
+		log.Info("KyvernoPolicy strategy not implemented yet")
+		return false, errors.New("KyvernoPolicy strategy not implemented yet")
+	default:
+		log.Error(nil, "unknown strategy", "strategy", trap.DecoyDeployment.Strategy)
+		return false, errors.New("unknown strategy")
 	}
 
-	return trapsapi.DecoyDeploymentResult{
-		AtLeastOneObjectsWasMatched: matchingResult.AtLeastOneObjectWasMatched,
-		AllObjectsWereReady:         matchingResult.AllDeployableObjectsWereReady,
-		Errors:                      joinedErrors}
+	return false, nil
 }
 
-// DeployCaptor deploys a captor for a filesystem honeytoken trap.
+// DeployCaptor deploys the captors of a filesystem honeytoken trap, one for each of its captor deployment strategies.
+// If a captor cannot be deployed, the others are deployed nonetheless, so that the trap is still monitored.
 func (r *FilesystemHoneytokenReconciler) DeployCaptor(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) trapsapi.CaptorDeploymentResult {
+	result := trapsapi.CaptorDeploymentResult{Trap: &trap}
+	for _, strategy := range trap.CaptorDeployment.AllStrategies() {
+		missingCaptor, err := r.deployCaptorWithStrategy(ctx, deceptionPolicy, trap, strategy)
+		result.Errors = errors.Join(result.Errors, err)
+		result.MissingTetragon = result.MissingTetragon || missingCaptor
+	}
+
+	return result
+}
+
+// deployCaptorWithStrategy deploys a captor of a filesystem honeytoken trap with the given captor deployment strategy.
+// The boolean return value indicates if the captor could not be deployed because Tetragon or Kive is not installed.
+func (r *FilesystemHoneytokenReconciler) deployCaptorWithStrategy(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy,
+	trap v1alpha1.Trap, strategy string) (bool, error) {
 	log := k8slog.FromContext(ctx)
 
-	switch trap.CaptorDeployment.Strategy {
+	switch strategy {
 	case "tetragon":
 		if err := r.deployCaptorWithTetragon(ctx, deceptionPolicy, trap); err != nil {
 			missingTetragon := errors.Is(err, &meta.NoKindMatchError{})
 			if missingTetragon {
 				log.Error(nil, "Tetragon is not installed - cannot deploy captors with Tetragon")
+				err = fmt.Errorf("%w: %w", trapsapi.ErrCaptorBackendMissing, err)
 			}
-			return trapsapi.CaptorDeploymentResult{Trap: &trap, Errors: err, MissingTetragon: missingTetragon}
+			return missingTetragon, err
 		}
 	case "kive":
 		if err := r.deployCaptorWithKive(ctx, deceptionPolicy, trap); err != nil {
 			missingKive := errors.Is(err, &meta.NoKindMatchError{})
 			if missingKive {
 				log.Error(nil, "Kive is not installed - cannot deploy captors with Kive")
+				err = fmt.Errorf("%w: %w", trapsapi.ErrCaptorBackendMissing, err)
 			}
-			return trapsapi.CaptorDeploymentResult{Trap: &trap, Errors: err, MissingTetragon: missingKive}
+			return missingKive, err
+		}
+	case "falco":
+		if err := r.deployCaptorWithFalco(ctx, deceptionPolicy, trap); err != nil {
+			return false, err
 		}
+	case "sidecar":
+		log.Info("Captor deployment strategy is 'sidecar' - the captor is deployed with the decoys")
 	case "none":
 		log.Info("Captor deployment strategy is 'none' - skipping captor deployment")
-		return trapsapi.CaptorDeploymentResult{Trap: &trap}
 	default:
-		log.Error(nil, fmt.Sprintf("captor deployment strategy '%s' unknown", trap.CaptorDeployment.Strategy))
-		return trapsapi.CaptorDeploymentResult{Trap: &trap, Errors: errors.New("captor deployment strategy unknown")}
+		log.Error(nil, fmt.Sprintf("captor deployment strategy '%s' unknown", strategy))
+		return false, errors.New("captor deployment strategy unknown")
 	}
 
-	return trapsapi.CaptorDeploymentResult{Trap: &trap}
+	return false, nil
 }
 
-// deployDecoyWithContainerExec deploys a FilesystemHoneytoken trap to a list of pods using the containerExec strategy.
-// The trap is only deployed to the pods where the trap is not already deployed.
+// deployDecoyWithContainerExec deploys a FilesystemHoneytoken trap to a container of a pod using the containerExec strategy.
+// If the decoy was already placed together with the decoys of other traps in the same container, only its result is returned.
 func (r *FilesystemHoneytokenReconciler) deployDecoyWithContainerExec(ctx context.Context, trap v1alpha1.Trap, pod corev1.Pod, containerName string) error {
+	if placed, err := r.execPlacements.lookup(pod, containerName, trap.FilesystemHoneytoken.FilePath); placed {
+		return err
+	}
+	return r.placeDecoysInContainer(ctx, pod, containerName, []v1alpha1.Trap{trap})[trap.FilesystemHoneytoken.FilePath]
+}
+
+// deployDecoyWithEphemeralContainer deploys a FilesystemHoneytoken trap to a container of a pod using the ephemeralContainer strategy.
+// An ephemeral container is added to the pod, which writes the honeytoken into the filesystem of the container,
+// so that Koney does not need to exec into the container (e.g., if exec is forbidden by policy).
+// Ephemeral containers run asynchronously, so the trap is considered deployed once the ephemeral container was added.
+func (r *FilesystemHoneytokenReconciler) deployDecoyWithEphemeralContainer(ctx context.Context, trap v1alpha1.Trap, pod corev1.Pod, containerName string) error {
+	log := k8slog.FromContext(ctx)
+
+	script := `mkdir -p "$(dirname "$KONEY_FILE_PATH")" && printf '%s' "$KONEY_FILE_CONTENT" > "$KONEY_FILE_PATH"`
+	if trap.FilesystemHoneytoken.IsReadOnly() {
+		script += ` && chmod 0444 "$KONEY_FILE_PATH"`
+	}
+
+	name := generateEphemeralContainerName("write", trap.FilesystemHoneytoken.FilePath, containerName, time.Now())
+	resolvedTrap := ResolvePlacementContext(trap, pod)
+	if err := r.addEphemeralContainer(ctx, &pod, buildEphemeralContainer(resolvedTrap, pod, containerName, name, script)); err != nil {
+		log.Error(err, "unable to add ephemeral container to pod", "pod", pod.Name, "ephemeralContainer", name)
+		return err
+	}
+
+	log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName, "ephemeralContainer", name)
+	return nil
+}
+
+// addEphemeralContainer adds an ephemeral container to a pod through the ephemeralcontainers subresource.
+func (r *FilesystemHoneytokenReconciler) addEphemeralContainer(ctx context.Context, pod *corev1.Pod, ephemeralContainer corev1.EphemeralContainer) error {
+	// Use RetryOnConflict to elegantly avoid conflicts when updating a resource
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+			return err
+		}
+
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, ephemeralContainer)
+		return r.SubResource("ephemeralcontainers").Update(ctx, pod)
+	})
+}
+
+// deployDecoyWithVolumeMount deploys a FilesystemHoneytoken trap to
+// a list of deployments using the volumeMount strategy.
+// The trap is only deployed to the pods where the trap is not already deployed.
+func (r *FilesystemHoneytokenReconciler) deployDecoyWithVolumeMount(ctx context.Context, trap v1alpha1.Trap, deployment appsv1.Deployment, containerName string) error {
 	log := k8slog.FromContext(ctx)
 
 	var joinedErrors error
-	var cmd []string
 
-	// Create the directory if it doesn't exist
-	directory := trap.FilesystemHoneytoken.FilePath[:strings.LastIndex(trap.FilesystemHoneytoken.FilePath, "/")]
-	cmd = []string{"mkdir", "-p", directory}
-	_, err := r.executeCommandInContainer(ctx, pod, containerName, cmd)
+	mountPath, _ := filepath.Split(trap.FilesystemHoneytoken.FilePath)
+	volume, volumeMount, err := createSecretVolume(r.Client, ctx, r.DeceptionPolicy, trap, deployment.Namespace)
 	if err != nil {
-		log.Error(err, "unable to create directory with mkdir in container", "directory", directory, "container", containerName)
+		log.Error(err, "unable to create secret volume", "secret", generateSecretName(trap))
 		joinedErrors = errors.Join(joinedErrors, err)
 
 		return joinedErrors
 	}
 
-	// mark the commands with a fingerprint so that we won't alert on them later
-	echoFingerprint := utils.EncodeFingerprintInEcho(utils.KoneyFingerprint)
-	catFingerprint := utils.EncodeFingerprintInCat(utils.KoneyFingerprint)
+	if err := r.mountVolumeInDeployment(ctx, trap, &deployment, containerName, volume, volumeMount); err != nil {
+		joinedErrors = errors.Join(joinedErrors, err)
+	} else {
+		log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName, "mountPath", mountPath)
+	}
 
-	if trap.FilesystemHoneytoken.FileContent != "" {
-		// To avoid issues with special characters (e.g., command injection vulnerabilities),
-		// we first encode the content in octal (sh does not like hex) and then decode it in the container
-		octalContent := utils.StringToOct(trap.FilesystemHoneytoken.FileContent)
+	return joinedErrors
+}
 
-		// To decode the octal content, we use the following command:
-		// oct_string="141142143"; i=1; while [ $i -lt ${#oct_string} ]; do $(which echo) -e "\0$(expr substr $oct_string $i 3)\c"; i=$(expr $i + 3); done > /path/to/file
-		// $(which echo) is used to avoid issues with the shell built-in echo command
-		cmd = []string{"sh", "-c", "oct_string=\"" + octalContent + "\"; i=1; while [ $i -lt ${#oct_string} ]; do $(which echo) -e \"\\0$(expr substr $oct_string $i 3)\\c " + echoFingerprint + "\"; i=$(expr $i + 3); done > \"" + trap.FilesystemHoneytoken.FilePath + "\""}
-	} else {
-		// We don't use touch because if the file already includes content, touch would not make it empty
-		cmd = []string{"sh", "-c", "echo -e \"\\c " + echoFingerprint + "\" > \"" + trap.FilesystemHoneytoken.FilePath + "\""}
+// deployDecoyWithAdmission checks if a FilesystemHoneytoken trap was injected into a container of a pod
+// by the mutating webhook, using the admission strategy, and makes sure that the secret of the honeytoken exists.
+// Volumes cannot be added to running pods, so pods that were created without the decoy only get it when they are recreated.
+// The boolean return value indicates if the trap is deployed to the container.
+func (r *FilesystemHoneytokenReconciler) deployDecoyWithAdmission(ctx context.Context, trap v1alpha1.Trap, pod corev1.Pod, containerName string) (bool, error) {
+	log := k8slog.FromContext(ctx)
+
+	volume, _, data, err := buildSecretVolume(trap)
+	if err != nil {
+		log.Error(err, "unable to build secret volume", "secret", generateSecretName(trap))
+		return false, err
+	}
+
+	if !isVolumeMountedInPod(&pod, containerName, volume) {
+		log.Info("FilesystemHoneytoken trap not injected into container - the pod must be recreated to receive it", "pod", pod.Name, "container", containerName)
+		return false, nil
 	}
 
-	// Use ExecCMDInContainer to execute the command in the container
-	output, err := r.executeCommandInContainer(ctx, pod, containerName, cmd)
+	// The webhook creates the secret when it injects the decoy, but the secret could have been deleted since then
+	if err := createSecret(r.Client, ctx, r.DeceptionPolicy, trap, pod.Namespace, volume.Secret.SecretName, data); err != nil {
+		log.Error(err, "unable to create secret", "secret", volume.Secret.SecretName)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// deployDecoyWithProjectedVolume deploys a FilesystemHoneytoken trap to
+// a list of deployments using the projectedVolume strategy. The honeytoken is mounted from a projected volume,
+// which the kubelet manages, with an immutable secret as its source. The mount is always read-only,
+// so the honeytoken cannot be modified in the containers, regardless of file permissions.
+func (r *FilesystemHoneytokenReconciler) deployDecoyWithProjectedVolume(ctx context.Context, trap v1alpha1.Trap, deployment appsv1.Deployment, containerName string) error {
+	log := k8slog.FromContext(ctx)
+
+	volume, volumeMount, _, err := buildProjectedVolume(trap)
 	if err != nil {
-		log.Error(err, "unable to deploy FilesystemHoneytoken trap to container", "container", containerName, "stderr", output)
-		// We don't return here to try to deploy the trap to the other containers
-		joinedErrors = errors.Join(joinedErrors, err)
+		log.Error(err, "unable to build projected volume", "file path", trap.FilesystemHoneytoken.FilePath)
+		return err
+	}
 
-		return joinedErrors
-	} else {
-		// Check if the file was created with the expected content
-		cmd = []string{"sh", "-c", "cat " + catFingerprint + " \"" + trap.FilesystemHoneytoken.FilePath + "\""}
-		output, err := r.executeCommandInContainer(ctx, pod, containerName, cmd)
-		if err != nil {
-			log.Error(err, "unable to read the content of the file", "container", containerName)
-			joinedErrors = errors.Join(joinedErrors, err)
-		} else if strings.TrimSuffix(output, "\n") != strings.TrimSuffix(trap.FilesystemHoneytoken.FileContent, "\n") { // TrimSuffix removes the trailing newline
-			log.Error(nil, "the content of the file is not the expected content", "container", containerName, "expected", trap.FilesystemHoneytoken.FileContent, "actual", output)
-			joinedErrors = errors.Join(joinedErrors, errors.New("the content of the file is not the expected content"))
-		} else {
-			log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName)
-		}
+	if err := r.ensureSecretOfProjectedVolume(ctx, trap, deployment.Namespace); err != nil {
+		return err
+	}
 
-		if trap.FilesystemHoneytoken.ReadOnly {
-			cmd = []string{"chmod", "444", trap.FilesystemHoneytoken.FilePath}
-			_, err = r.executeCommandInContainer(ctx, pod, containerName, cmd)
-			if err != nil {
-				log.Error(err, "unable to make the file read-only", "container", containerName)
-				joinedErrors = errors.Join(joinedErrors, err)
-			}
-		}
+	if err := r.mountVolumeInDeployment(ctx, trap, &deployment, containerName, volume, volumeMount); err != nil {
+		return err
 	}
 
-	return joinedErrors
+	log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName, "secret", generateSecretName(trap))
+	return nil
 }
 
-// deployDecoyWithVolumeMount deploys a FilesystemHoneytoken trap to
-// a list of deployments using the volumeMount strategy.
-// The trap is only deployed to the pods where the trap is not already deployed.
-func (r *FilesystemHoneytokenReconciler) deployDecoyWithVolumeMount(ctx context.Context, trap v1alpha1.Trap, deployment appsv1.Deployment, containerName string) error {
+// ensureSecretOfProjectedVolume makes sure that the immutable secret of a trap with the projectedVolume strategy
+// exists in the namespace and holds the honeytoken, and restores it otherwise.
+func (r *FilesystemHoneytokenReconciler) ensureSecretOfProjectedVolume(ctx context.Context, trap v1alpha1.Trap, namespace string) error {
 	log := k8slog.FromContext(ctx)
 
-	var joinedErrors error
+	_, _, data, err := buildProjectedVolume(trap)
+	if err != nil {
+		return err
+	}
 
-	// The name of the secret is generated based on the trap's file path and content
+	// Decoys are placed in several deployments of a namespace at the same time, which all share the secret,
+	// so another placement may create or update it concurrently, in which case it is checked again
 	secretName := generateSecretName(trap)
+	if err := retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		return ensureImmutableSecret(r.Client, ctx, r.DeceptionPolicy, trap, namespace, secretName, data)
+	}); err != nil {
+		log.Error(err, "unable to ensure immutable secret", "secret", secretName)
+		return err
+	}
+
+	return nil
+}
+
+// deployDecoyWithImageVolume deploys a FilesystemHoneytoken trap to
+// a list of deployments using the imageVolume strategy, i.e., by mounting a file from an OCI image.
+// The trap is only deployed to the pods where the trap is not already deployed.
+func (r *FilesystemHoneytokenReconciler) deployDecoyWithImageVolume(ctx context.Context, trap v1alpha1.Trap, deployment appsv1.Deployment, containerName string) error {
+	log := k8slog.FromContext(ctx)
+
+	imageVolume := trap.DecoyDeployment.ImageVolume
+	if imageVolume == nil || imageVolume.Image == "" {
+		log.Error(nil, "imageVolume strategy requires an image", "trap", trap.FilesystemHoneytoken)
+		return errors.New("imageVolume strategy requires an image")
+	}
 
-	mountPath, fileName := filepath.Split(trap.FilesystemHoneytoken.FilePath)
+	_, fileName := filepath.Split(trap.FilesystemHoneytoken.FilePath)
 	if fileName == "" {
 		log.Error(nil, "file path must point to a file", "file path", trap.FilesystemHoneytoken.FilePath)
 		return errors.New("file path must point to a file")
 	}
 
-	data := map[string][]byte{
-		fileName: []byte(trap.FilesystemHoneytoken.FileContent),
+	// Older clusters reject mounts of image volumes with a subPath, which the decoy file always needs
+	serverVersion, err := r.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		log.Error(err, "unable to get the version of the cluster")
+		return err
+	}
+	if err := checkImageVolumeSubPathSupport(serverVersion.GitVersion); err != nil {
+		log.Error(err, "imageVolume strategy is not supported by the cluster", "version", serverVersion.GitVersion)
+		return err
 	}
 
-	if err := createSecret(r.Client, ctx, deployment.Namespace, secretName, data); err != nil {
-		log.Error(err, "unable to create secret", "secret", secretName)
-		joinedErrors = errors.Join(joinedErrors, err)
-
-		return joinedErrors
+	// By default, the file in the image has the same name as the honeytoken (in the root of the image)
+	subPath := strings.TrimPrefix(imageVolume.Path, "/")
+	if subPath == "" {
+		subPath = fileName
 	}
 
-	// The name of the volume is generated based on the trap's file path
-	// For the volume name, we don't need to also consider the content of the file
-	// since there cannot be two volumes mounted to the same path with different content
 	volumeName := generateVolumeName(trap.FilesystemHoneytoken.FilePath)
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Image: &corev1.ImageVolumeSource{
+				Reference:  imageVolume.Image,
+				PullPolicy: imageVolume.PullPolicy,
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: trap.FilesystemHoneytoken.FilePath,
+		ReadOnly:  true, // Image volumes are always read-only
+		SubPath:   subPath,
+	}
+
+	if err := r.mountVolumeInDeployment(ctx, trap, &deployment, containerName, volume, volumeMount); err != nil {
+		return err
+	}
+
+	// If the ImageVolume feature gate is disabled, the API server silently drops image volumes
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == volumeName && volume.Image != nil {
+			log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName, "image", imageVolume.Image)
+			return nil
+		}
+	}
+
+	log.Error(nil, "image volume was dropped by the API server - is the ImageVolume feature gate enabled?", "deployment", deployment.Name)
+	return errors.New("image volumes are not supported by the cluster")
+}
+
+// deployDecoyWithInitContainer deploys a FilesystemHoneytoken trap to
+// a list of deployments using the initContainer strategy, i.e., by adding an init container
+// that writes the honeytoken into an emptyDir volume, which is mounted in the containers.
+// The trap is only deployed to the pods where the trap is not already deployed.
+func (r *FilesystemHoneytokenReconciler) deployDecoyWithInitContainer(ctx context.Context, trap v1alpha1.Trap, deployment appsv1.Deployment, containerName string) error {
+	log := k8slog.FromContext(ctx)
+
+	initContainer, volume, volumeMount, err := buildInitContainerVolume(trap)
+	if err != nil {
+		log.Error(err, "unable to build init container", "file path", trap.FilesystemHoneytoken.FilePath)
+		return err
+	}
+
+	if err := r.mountVolumeInDeployment(ctx, trap, &deployment, containerName, volume, volumeMount, initContainer); err != nil {
+		return err
+	}
+
+	log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName, "initContainer", initContainer.Name)
+	return nil
+}
+
+// mountVolumeInDeployment adds a volume to a deployment (unless a volume with the same name already exists)
+// and mounts it in the given container (unless it is already mounted there). Init containers that prepare the volume
+// are added as well (unless an init container with the same name already exists), and so is the watcher sidecar
+// if the trap uses the sidecar captor strategy. The deployment is updated in the Kubernetes API server
+// and the passed deployment reflects the updated state afterward.
+func (r *FilesystemHoneytokenReconciler) mountVolumeInDeployment(ctx context.Context, trap v1alpha1.Trap, deployment *appsv1.Deployment, containerName string,
+	volume corev1.Volume, volumeMount corev1.VolumeMount, initContainers ...corev1.Container) error {
+	log := k8slog.FromContext(ctx)
+
+	var joinedErrors error
 
-	// Get the pod
-	if err := r.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment); err != nil {
+	// Get the deployment
+	if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), deployment); err != nil {
 		log.Error(err, "unable to get deployment", "deployment", deployment.Name)
 		joinedErrors = errors.Join(joinedErrors, err)
 	}
 
 	// Check if the volume is already configured to the deployment
 	volumeAlreadyConfigured := false
-	for _, volume := range deployment.Spec.Template.Spec.Volumes {
-		if volume.Name == volumeName {
+	for _, existingVolume := range deployment.Spec.Template.Spec.Volumes {
+		if existingVolume.Name == volume.Name {
 			volumeAlreadyConfigured = true
 			break
 		}
 	}
 
 	if volumeAlreadyConfigured {
-		log.Info("Volume already configured", "volume", volumeName)
+		log.Info("Volume already configured", "volume", volume.Name)
 	} else {
 		// Add the volume to the deployment
-		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
-			Name: volumeName,
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName: secretName,
-				},
-			},
-		})
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+	}
+
+	// Add the init containers to the deployment
+	for _, initContainer := range initContainers {
+		initContainerAlreadyConfigured := false
+		for _, existingInitContainer := range deployment.Spec.Template.Spec.InitContainers {
+			if existingInitContainer.Name == initContainer.Name {
+				initContainerAlreadyConfigured = true
+				break
+			}
+		}
+
+		if !initContainerAlreadyConfigured {
+			log.Info("Adding init container to deployment", "initContainer", initContainer.Name, "deployment", deployment.Name)
+			deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers, initContainer)
+			utils.MarkInjectedContainers(&deployment.Spec.Template, []string{initContainer.Name})
+		}
+	}
+
+	// Add the watcher sidecar that monitors the volume (or replace it if the trap changed)
+	if trap.CaptorDeployment.HasStrategy("sidecar") {
+		sidecar, err := buildCaptorSidecar(r.DeceptionPolicy, trap, volume, volumeMount)
+		if err != nil {
+			log.Error(err, "unable to build captor sidecar", "file path", trap.FilesystemHoneytoken.FilePath)
+			return errors.Join(joinedErrors, err)
+		}
+
+		if upsertCaptorSidecar(&deployment.Spec.Template, sidecar) {
+			log.Info("Adding captor sidecar to deployment", "sidecar", sidecar.Name, "deployment", deployment.Name)
+		}
 	}
 
 	// Add the volume mount to the container
@@ -350,34 +721,30 @@ func (r *FilesystemHoneytokenReconciler) deployDecoyWithVolumeMount(ctx context.
 		if container.Name == containerName {
 			// Check if the volume is already mounted
 			volumeAlreadyMounted := false
-			for _, volumeMount := range deployment.Spec.Template.Spec.Containers[i].VolumeMounts {
-				if volumeMount.Name == volumeName {
+			for _, existingVolumeMount := range deployment.Spec.Template.Spec.Containers[i].VolumeMounts {
+				if existingVolumeMount.Name == volume.Name {
 					volumeAlreadyMounted = true
 					break
 				}
 			}
 
 			if !volumeAlreadyMounted {
-				log.Info("Adding volume mount to container", "container", containerName, "volume", volumeName, "mountPath", mountPath)
-				deployment.Spec.Template.Spec.Containers[i].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
-					Name:      volumeName,
-					MountPath: trap.FilesystemHoneytoken.FilePath,
-					ReadOnly:  trap.FilesystemHoneytoken.ReadOnly,
-					SubPath:   fileName,
-				})
+				log.Info("Adding volume mount to container", "container", containerName, "volume", volume.Name, "mountPath", volumeMount.MountPath)
+				deployment.Spec.Template.Spec.Containers[i].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[i].VolumeMounts, volumeMount)
 			}
 		}
 	}
 
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// TODO: Can we use patch instead of update to avoid conflicts?
-		return r.Update(ctx, &deployment)
+		return r.Update(ctx, deployment)
 	})
 	if err != nil {
 		log.Error(err, "unable to update deployment", "deployment", deployment.Name)
 		joinedErrors = errors.Join(joinedErrors, err)
-	} else {
-		log.Info("FilesystemHoneytoken trap deployed to container", "container", containerName)
+	} else if err := utils.ExcludeInjectedContainersFromAutoscalers(r.Client, ctx, deployment); err != nil {
+		log.Error(err, "unable to exclude injected containers from autoscalers", "deployment", deployment.Name)
+		joinedErrors = errors.Join(joinedErrors, err)
 	}
 
 	return joinedErrors
@@ -385,103 +752,227 @@ func (r *FilesystemHoneytokenReconciler) deployDecoyWithVolumeMount(ctx context.
 
 // deployCaptorWithTetragon generates a Tetragon tracing policy
 // to trace the filesystem access of a filesystem honeytoken trap and applies it to the cluster.
+// Traps that only target specific namespaces are traced with a TracingPolicyNamespaced in each of these namespaces instead,
+// which cannot trace pods in other namespaces, even if the selectors of the trap are broader than intended.
 func (r *FilesystemHoneytokenReconciler) deployCaptorWithTetragon(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) error {
 	log := k8slog.FromContext(ctx)
 
-	tracingPolicyName, err := GenerateTetragonTracingPolicyName(trap)
+	tracingPolicyName, err := GenerateCaptorName(deceptionPolicy, trap)
 	if err != nil {
 		log.Error(err, "unable to generate Tetragon tracing policy name")
 		return err
 	}
 
-	// Get the Tetragon tracing policy if it already exists
-	// If the tracing policy already exists, we don't need to do anything
-	// since the name is unique for each unique trap
-	existingTracingPolicy := &ciliumiov1alpha1.TracingPolicy{}
-	err = r.Get(ctx, client.ObjectKey{Name: tracingPolicyName}, existingTracingPolicy)
+	var reader client.Reader = r.Client
+	if r.CaptorReader != nil {
+		reader = r.CaptorReader
+	}
 
-	// If the policy does not exist, err is not nil and is a NotFound error
-	if err != nil {
-		// If the policy does not exist, we create it
+	// An empty namespace stands for the cluster-scoped tracing policy
+	namespaces, isNamespaced := trap.MatchResources.TargetNamespaces()
+	if !isNamespaced {
+		namespaces = []string{""}
+	}
+
+	// The tracing policy is only generated if a captor is missing or outdated
+	var tracingPolicy *ciliumiov1alpha1.TracingPolicy
+	for _, namespace := range namespaces {
+		// Get the Tetragon tracing policy if it already exists
+		// If the tracing policy already exists and was generated from the same trap spec, we don't need to do anything
+		existingTracingPolicy := newTetragonCaptor(namespace)
+		err = reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: tracingPolicyName}, existingTracingPolicy)
 		if client.IgnoreNotFound(err) != nil {
-			log.Error(err, "unable to get Tetragon tracing policy")
+			log.Error(err, "unable to get Tetragon tracing policy", "namespace", namespace)
 			return err
 		}
+		isExisting := err == nil
+		if isExisting && !isCaptorOutdated(existingTracingPolicy, trap) {
+			continue
+		}
 
-		tracingPolicy := generateTetragonTracingPolicy(deceptionPolicy, trap, tracingPolicyName)
+		if tracingPolicy == nil {
+			tracingPolicy = generateTetragonTracingPolicy(deceptionPolicy, trap, tracingPolicyName)
+
+			// Only monitor the pods of the referenced workloads (their pod selectors are immutable) on the selected nodes
+			captorPodLabels, err := resolveCaptorPodLabels(r, ctx, trap)
+			if err != nil {
+				log.Error(err, "unable to resolve pod labels of workloads")
+				return err
+			}
+			addPodLabelsToTracingPolicy(tracingPolicy, captorPodLabels)
+		}
+		captor := buildTetragonCaptor(tracingPolicy, namespace)
 
-		if err := r.Create(ctx, tracingPolicy); err != nil {
-			log.Error(err, "unable to create Tetragon tracing policy")
+		// If the policy does not exist, we create it
+		if !isExisting {
+			if err := r.Create(ctx, captor); err != nil {
+				log.Error(err, "unable to create Tetragon tracing policy", "namespace", namespace)
+				return err
+			}
+
+			log.Info("Tetragon tracing policy created", "policy", captor)
+			r.recordEvent(corev1.EventTypeNormal, constants.EventReasonCaptorCreated, "Tetragon tracing policy %s created", client.ObjectKeyFromObject(captor))
+			continue
+		}
+
+		// If the trap changed, we update the policy in place, so that there is no gap in monitoring
+		existingTracingPolicy.SetLabels(captor.GetLabels())
+		existingTracingPolicy.SetAnnotations(captor.GetAnnotations())
+		existingTracingPolicy.SetOwnerReferences(captor.GetOwnerReferences())
+		*existingTracingPolicy.TpSpec() = *captor.TpSpec()
+		if err := r.Update(ctx, existingTracingPolicy); err != nil {
+			log.Error(err, "unable to update Tetragon tracing policy", "namespace", namespace)
 			return err
 		}
 
-		log.Info("Tetragon tracing policy created", "policy", tracingPolicy)
+		log.Info("Tetragon tracing policy updated", "policy", existingTracingPolicy)
+	}
+
+	// Only remove the tracing policies that are not needed anymore (e.g., because the namespaces of the trap changed)
+	// after the new ones are in place, so that there is no gap in monitoring
+	return r.removeStaleTetragonCaptors(ctx, reader, deceptionPolicy, tracingPolicyName, namespaces)
+}
+
+// removeStaleTetragonCaptors removes the tracing policies with the given name that are not in one of the given namespaces,
+// where an empty namespace stands for the cluster-scoped tracing policy.
+func (r *FilesystemHoneytokenReconciler) removeStaleTetragonCaptors(ctx context.Context, reader client.Reader,
+	deceptionPolicy *v1alpha1.DeceptionPolicy, tracingPolicyName string, namespaces []string) error {
+	log := k8slog.FromContext(ctx)
+
+	if !slices.Contains(namespaces, "") {
+		tracingPolicy := &ciliumiov1alpha1.TracingPolicy{}
+		err := reader.Get(ctx, client.ObjectKey{Name: tracingPolicyName}, tracingPolicy)
+		if err == nil {
+			if err := r.Delete(ctx, tracingPolicy); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.Info("Tetragon tracing policy replaced by namespaced tracing policies", "policy", tracingPolicyName, "namespaces", namespaces)
+		} else if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	// Tetragon versions without namespaced tracing policies cannot have stale ones
+	tracingPolicies := &ciliumiov1alpha1.TracingPolicyNamespacedList{}
+	if err := reader.List(ctx, tracingPolicies, client.MatchingLabels{constants.LabelKeyDeceptionPolicyRef: deceptionPolicy.Name}); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	for i := range tracingPolicies.Items {
+		tracingPolicy := &tracingPolicies.Items[i]
+		if tracingPolicy.Name != tracingPolicyName || slices.Contains(namespaces, tracingPolicy.Namespace) {
+			continue
+		}
+		if err := r.Delete(ctx, tracingPolicy); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		log.Info("Tetragon namespaced tracing policy removed", "policy", tracingPolicyName, "namespace", tracingPolicy.Namespace)
 	}
 
 	return nil
 }
 
+// isCaptorOutdated returns true if a captor was not generated from the given trap spec, according to its trap hash annotation,
+// or if it calls the alert forwarder with an outdated URL (e.g., with another token), according to its callback hash annotation.
+func isCaptorOutdated(captor client.Object, trap v1alpha1.Trap) bool {
+	trapHash, err := TrapHash(trap)
+	if err != nil {
+		return true
+	}
+
+	annotations := captor.GetAnnotations()
+	return annotations[constants.AnnotationKeyTrapHash] != trapHash ||
+		annotations[constants.AnnotationKeyCallbackHash] != callbackHash()
+}
+
 // deployCaptorWithKive generates a Kive tracing policy
 // to trace the filesystem access of a filesystem honeytoken trap and applies it to the cluster.
 func (r *FilesystemHoneytokenReconciler) deployCaptorWithKive(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) error {
 	log := k8slog.FromContext(ctx)
 
-	tracingPolicyName, err := GenerateKivePolicyName(trap)
+	if trap.FilesystemHoneytoken.HasMonitorPathPatterns() {
+		err := errors.New("path prefixes and suffixes in MonitorPaths are not supported by Kive")
+		log.Error(err, "unable to generate Kive tracing policy")
+		return err
+	}
+
+	tracingPolicyName, err := GenerateCaptorName(deceptionPolicy, trap)
 	if err != nil {
 		log.Error(err, "unable to generate Kive tracing policy name")
 		return err
 	}
 
+	// If the Kive policy already exists and was generated from the same trap spec, we don't need to do anything,
+	// otherwise it is applied (and thus updated in place if the trap changed)
+	var reader client.Reader = r.Client
+	if r.CaptorReader != nil {
+		reader = r.CaptorReader
+	}
+
+	existingKivePolicy := &kivev1.KivePolicy{}
+	err = reader.Get(ctx, client.ObjectKey{Namespace: utils.GetKoneyNamespace(), Name: tracingPolicyName}, existingKivePolicy)
+	if client.IgnoreNotFound(err) != nil {
+		log.Error(err, "unable to get Kive tracing policy")
+		return err
+	}
+	isExisting := err == nil
+	if isExisting && !isCaptorOutdated(existingKivePolicy, trap) {
+		return nil
+	}
+
 	tracingPolicy := generateKivePolicy(deceptionPolicy, trap, tracingPolicyName)
 	if err != nil {
 		log.Error(err, "unable to generate Kive tracing policy")
 		return err
 	}
 
+	// Only monitor the pods of the referenced workloads on the selected nodes
+	captorPodLabels, err := resolveCaptorPodLabels(r, ctx, trap)
+	if err != nil {
+		log.Error(err, "unable to resolve pod labels of workloads")
+		return err
+	}
+	addPodLabelsToKivePolicy(tracingPolicy, captorPodLabels)
+
 	if err := r.Patch(ctx, tracingPolicy, client.Apply, client.ForceOwnership, client.FieldOwner(constants.FieldOwnerKoneyController)); err != nil {
 		log.Error(err, "unable to create Kive tracing policy")
 		return err
 	}
 
-	log.Info("Kive tracing policy created", "policy", tracingPolicy)
+	log.Info("Kive tracing policy applied", "policy", tracingPolicy)
+	if !isExisting {
+		r.recordEvent(corev1.EventTypeNormal, constants.EventReasonCaptorCreated, "Kive tracing policy %s created", client.ObjectKeyFromObject(tracingPolicy))
+	}
 
 	return nil
 }
 
-// executeCommandInContainer executes a command in a container. If the command
-// is successful, the function returns the stdout output. If the command
-// fails, the function returns the stderr output and an error.
-func (r *FilesystemHoneytokenReconciler) executeCommandInContainer(ctx context.Context, pod corev1.Pod, containerName string, cmd []string) (string, error) {
-	req := r.Clientset.CoreV1().RESTClient().Post().
-		Resource("pods").
-		Name(pod.Name).
-		Namespace(pod.Namespace).
-		SubResource("exec").
-		VersionedParams(&corev1.PodExecOptions{
-			Command:   cmd,
-			Container: containerName,
-			Stdin:     false,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       false,
-		}, scheme.ParameterCodec)
-
-	exec, err := remotecommand.NewSPDYExecutor(&r.Config, "POST", req.URL())
-	if err != nil {
-		return "", err
+// recordEvent emits an event on the DeceptionPolicy of the reconciler, if it has a recorder.
+func (r *FilesystemHoneytokenReconciler) recordEvent(eventType, reason, messageFmt string, args ...any) {
+	if r.Recorder != nil && r.DeceptionPolicy != nil {
+		r.Recorder.Eventf(r.DeceptionPolicy, eventType, reason, messageFmt, args...)
 	}
+}
 
-	// Create new buffers for the output
-	var stdout, stderr bytes.Buffer
+// executeCommandInContainer executes a command in a container (see utils.ExecuteCommandInContainer).
+func (r *FilesystemHoneytokenReconciler) executeCommandInContainer(ctx context.Context, pod corev1.Pod, containerName string, cmd []string) (string, error) {
+	output, err := utils.ExecuteCommandInContainer(ctx, &r.Clientset, &r.Config, pod, containerName, cmd)
+	return output, classifyExecError(err)
+}
 
-	// Execute the command
-	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdout: &stdout,
-		Stderr: &stderr,
-	})
-	if err != nil {
-		return stderr.String(), err
-	}
+// executeCommandInContainerWithStdin executes a command in a container and streams stdin to it (see utils.ExecuteCommandInContainerWithStdin).
+func (r *FilesystemHoneytokenReconciler) executeCommandInContainerWithStdin(ctx context.Context, pod corev1.Pod, containerName string,
+	cmd []string, stdin io.Reader) (string, error) {
+	output, err := utils.ExecuteCommandInContainerWithStdin(ctx, &r.Clientset, &r.Config, pod, containerName, cmd, stdin)
+	return output, classifyExecError(err)
+}
 
-	return stdout.String(), nil
+// classifyExecError wraps the error of an exec with trapsapi.ErrExecForbidden if the API server denied the exec.
+func classifyExecError(err error) error {
+	if apierrors.IsForbidden(err) {
+		return fmt.Errorf("%w: %w", trapsapi.ErrExecForbidden, err)
+	}
+	return err
 }
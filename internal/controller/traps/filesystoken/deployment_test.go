@@ -0,0 +1,265 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+var _ = Describe("initContainer strategy", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx        context.Context
+		reconciler FilesystemHoneytokenReconciler
+		trap       v1alpha1.Trap
+		deployment *appsv1.Deployment
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "some 'very' $secret token", ReadOnly: &[]bool{true}[0]},
+			DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "initContainer"},
+		}
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}}},
+				},
+			},
+		}
+		reconciler = FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithObjects(deployment).Build()}
+	})
+
+	It("should build an unprivileged init container that writes the honeytoken", func() {
+		initContainer, volume, volumeMount, err := buildInitContainerVolume(trap)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(initContainer.Image).To(Equal(constants.DefaultInitContainerImage))
+		Expect(initContainer.Env).To(ContainElements(
+			corev1.EnvVar{Name: "KONEY_FILE_CONTENT", Value: trap.FilesystemHoneytoken.FileContent},
+			corev1.EnvVar{Name: "KONEY_FILE_PATH", Value: "/koney/service_token"},
+		))
+		Expect(initContainer.Command[2]).To(ContainSubstring("chmod 0444"))
+		Expect(initContainer.Command[2]).ToNot(ContainSubstring(trap.FilesystemHoneytoken.FileContent))
+		Expect(*initContainer.SecurityContext.RunAsNonRoot).To(BeTrue())
+		Expect(initContainer.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: volume.Name, MountPath: constants.InitContainerDecoyDir}))
+
+		Expect(volume.EmptyDir).ToNot(BeNil())
+		Expect(volumeMount).To(Equal(corev1.VolumeMount{Name: volume.Name, MountPath: FilePath, ReadOnly: true, SubPath: "service_token"}))
+
+		By("using the image of the trap")
+		trap.DecoyDeployment.InitContainer = &v1alpha1.InitContainerDecoy{Image: "registry.example.com/busybox:1.37", PullPolicy: corev1.PullIfNotPresent}
+		initContainer, _, _, err = buildInitContainerVolume(trap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(initContainer.Image).To(Equal("registry.example.com/busybox:1.37"))
+		Expect(initContainer.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+	})
+
+	It("should add the init container once and remove it again", func() {
+		Expect(reconciler.deployDecoyWithInitContainer(ctx, trap, *deployment, "app")).To(Succeed())
+		Expect(reconciler.deployDecoyWithInitContainer(ctx, trap, *deployment, "sidecar")).To(Succeed())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.InitContainers).To(HaveLen(1))
+		Expect(podSpec.Volumes).To(HaveLen(1))
+		Expect(podSpec.Containers[0].VolumeMounts).To(HaveLen(1))
+		Expect(podSpec.Containers[1].VolumeMounts).To(HaveLen(1))
+		Expect(utils.GetInjectedContainers(&deployment.Spec.Template)).To(Equal([]string{podSpec.InitContainers[0].Name}))
+
+		trapAnnotation := v1alpha1.TrapAnnotation{
+			DeploymentStrategy:   "initContainer",
+			Containers:           []string{"app", "sidecar"},
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{FilePath: FilePath},
+		}
+		Expect(reconciler.removeDecoyWithVolumeMount(ctx, trapAnnotation, *deployment, "app")).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(reconciler.removeDecoyWithVolumeMount(ctx, trapAnnotation, *deployment, "sidecar")).To(Succeed())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.InitContainers).To(BeEmpty())
+		Expect(deployment.Spec.Template.Spec.Volumes).To(BeEmpty())
+		Expect(utils.GetInjectedContainers(&deployment.Spec.Template)).To(BeEmpty())
+	})
+
+	It("should only deploy to resources that the strategy supports", func() {
+		deployed, err := reconciler.deployDecoyToContainer(ctx, trap, deployment, "app")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployed).To(BeTrue())
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-abc", Namespace: "koney-demo"}}
+		deployed, err = reconciler.deployDecoyToContainer(ctx, trap, pod, "app")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployed).To(BeFalse())
+
+		trap.DecoyDeployment.Strategy = "unknown"
+		_, err = reconciler.deployDecoyToContainer(ctx, trap, deployment, "app")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ephemeralContainer strategy", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		trap v1alpha1.Trap
+		pod  corev1.Pod
+	)
+
+	BeforeEach(func() {
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "someverysecrettoken"},
+			DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "ephemeralContainer"},
+		}
+		pod = corev1.Pod{
+			Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: &[]int64{1000}[0], RunAsGroup: &[]int64{1000}[0]},
+				Containers: []corev1.Container{
+					{Name: "app"},
+					{Name: "sidecar", SecurityContext: &corev1.SecurityContext{RunAsUser: &[]int64{2000}[0]}},
+				},
+			},
+		}
+	})
+
+	It("should target the container and write into its filesystem", func() {
+		ephemeralContainer := buildEphemeralContainer(trap, pod, "app", "koney-write-test", "true")
+
+		Expect(ephemeralContainer.TargetContainerName).To(Equal("app"))
+		Expect(ephemeralContainer.Image).To(Equal(constants.DefaultEphemeralContainerImage))
+		Expect(ephemeralContainer.Env).To(ContainElements(
+			corev1.EnvVar{Name: "KONEY_FILE_CONTENT", Value: "someverysecrettoken"},
+			corev1.EnvVar{Name: "KONEY_FILE_PATH", Value: "/proc/1/root" + FilePath},
+		))
+		Expect(ephemeralContainer.Command[2]).To(ContainSubstring(alerts.EncodeFingerprintInEcho(utils.GetKoneyFingerprint())))
+	})
+
+	It("should run as the user of the target container", func() {
+		ephemeralContainer := buildEphemeralContainer(trap, pod, "app", "koney-write-test", "true")
+		Expect(*ephemeralContainer.SecurityContext.RunAsUser).To(Equal(int64(1000)))
+		Expect(*ephemeralContainer.SecurityContext.RunAsGroup).To(Equal(int64(1000)))
+
+		ephemeralContainer = buildEphemeralContainer(trap, pod, "sidecar", "koney-write-test", "true")
+		Expect(*ephemeralContainer.SecurityContext.RunAsUser).To(Equal(int64(2000)))
+		Expect(*ephemeralContainer.SecurityContext.RunAsGroup).To(Equal(int64(1000)))
+	})
+
+	It("should generate unique names with a common prefix", func() {
+		now := time.Now()
+		name := generateEphemeralContainerName("write", FilePath, "app", now)
+		Expect(name).To(HavePrefix(ephemeralContainerNamePrefix("write", FilePath, "app")))
+		Expect(name).ToNot(Equal(generateEphemeralContainerName("write", FilePath, "app", now.Add(time.Millisecond))))
+		Expect(ephemeralContainerNamePrefix("write", FilePath, "app")).ToNot(Equal(ephemeralContainerNamePrefix("write", FilePath, "sidecar")))
+		Expect(len(name)).To(BeNumerically("<=", 63))
+	})
+})
+
+var _ = Describe("projectedVolume strategy", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		trap       v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		fakeClient = fake.NewClientBuilder().Build()
+
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "someverysecrettoken", ReadOnly: &[]bool{true}[0]},
+			DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "projectedVolume"},
+		}
+	})
+
+	It("should always mount the projected volume read-only", func() {
+		volume, volumeMount, data, err := buildProjectedVolume(trap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(volume.Projected.Sources).To(ConsistOf(corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: generateSecretName(trap)}},
+		}))
+		Expect(*volume.Projected.DefaultMode).To(Equal(int32(0444)))
+		Expect(volumeMount.ReadOnly).To(BeTrue())
+		Expect(data).To(HaveKeyWithValue("service_token", []byte("someverysecrettoken")))
+
+		trap.FilesystemHoneytoken.ReadOnly = &[]bool{false}[0]
+		volume, volumeMount, _, err = buildProjectedVolume(trap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*volume.Projected.DefaultMode).To(Equal(int32(0644)))
+		Expect(volumeMount.ReadOnly).To(BeTrue())
+	})
+
+	It("should restore a secret that was tampered with", func() {
+		data := map[string][]byte{"service_token": []byte("someverysecrettoken")}
+		key := client.ObjectKey{Namespace: "koney-demo", Name: generateSecretName(trap)}
+		Expect(ensureImmutableSecret(fakeClient, ctx, nil, trap, key.Namespace, key.Name, data)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, key, secret)).To(Succeed())
+		Expect(*secret.Immutable).To(BeTrue())
+		Expect(secret.Data).To(Equal(data))
+		trapHash, err := TrapHash(trap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyTrapHash, trapHash))
+
+		By("replacing the secret with a mutable one with other data")
+		Expect(fakeClient.Delete(ctx, secret)).To(Succeed())
+		Expect(fakeClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Data:       map[string][]byte{"service_token": []byte("tampered")},
+		})).To(Succeed())
+
+		Expect(ensureImmutableSecret(fakeClient, ctx, nil, trap, key.Namespace, key.Name, data)).To(Succeed())
+		secret = &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, key, secret)).To(Succeed())
+		Expect(*secret.Immutable).To(BeTrue())
+		Expect(secret.Data).To(Equal(data))
+	})
+
+	It("should share the secret between decoys that are placed at the same time", func() {
+		reconciler := FilesystemHoneytokenReconciler{Client: fakeClient, DeceptionPolicy: &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-projected", UID: "uid"},
+		}}
+		placements := make([]int, 20)
+		Expect(utils.ForEachConcurrently(placements, len(placements), func(int) error {
+			return reconciler.ensureSecretOfProjectedVolume(ctx, trap, "koney-demo")
+		})).To(Succeed())
+
+		secrets := &corev1.SecretList{}
+		Expect(fakeClient.List(ctx, secrets)).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(1))
+		Expect(secrets.Items[0].OwnerReferences).To(HaveLen(1))
+	})
+})
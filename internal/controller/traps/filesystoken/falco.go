@@ -0,0 +1,528 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/matching"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// FalcoRulesConfigMapName is the name of the ConfigMap in the namespace of Falco that holds the Falco rules of the captors.
+// Falco must load it as a rules directory (e.g., mounted at /etc/falco/rules.d) to monitor the traps.
+const FalcoRulesConfigMapName = "koney-falco-rules"
+
+// FalcoTag is the tag of all Falco rules that Koney generates, which falcosidekick can use to only forward their alerts.
+const FalcoTag = "koney"
+
+// falcoRulesFileSeparator separates the DeceptionPolicy name from the captor name in the name of a rules file.
+// It cannot be part of a DeceptionPolicy name, so the name of a rules file always tells which policy it belongs to.
+const falcoRulesFileSeparator = "_"
+
+// falcoRule is a rule in a Falco rules file.
+type falcoRule struct {
+	Rule      string   `json:"rule"`
+	Desc      string   `json:"desc"`
+	Condition string   `json:"condition"`
+	Output    string   `json:"output"`
+	Priority  string   `json:"priority"`
+	Source    string   `json:"source"`
+	Tags      []string `json:"tags"`
+}
+
+// falcoPriorities maps the severities of traps to the priorities of Falco rules.
+var falcoPriorities = map[string]string{
+	"info":     "INFORMATIONAL",
+	"low":      "NOTICE",
+	"medium":   "WARNING",
+	"high":     "ERROR",
+	"critical": "CRITICAL",
+}
+
+// falcoOutputFields are the fields of the output of the Falco rules, which the alert forwarder reads from falcosidekick.
+var falcoOutputFields = []string{
+	"fd.name", "k8s.ns.name", "k8s.pod.name", "container.name", "container.id",
+	"proc.pid", "proc.exepath", "proc.args", "proc.cwd", "user.uid",
+}
+
+// FalcoRulesFileName returns the name of the rules file (i.e., the key in the Falco rules ConfigMap) of a captor.
+func FalcoRulesFileName(deceptionPolicyName, captorName string) string {
+	return deceptionPolicyName + falcoRulesFileSeparator + captorName + ".yaml"
+}
+
+// parseFalcoRulesFileName returns the DeceptionPolicy name and the captor name of a rules file.
+// The boolean return value is false if the file was not generated by Koney.
+func parseFalcoRulesFileName(fileName string) (string, string, bool) {
+	if !strings.HasSuffix(fileName, ".yaml") {
+		return "", "", false
+	}
+
+	deceptionPolicyName, captorName, found := strings.Cut(strings.TrimSuffix(fileName, ".yaml"), falcoRulesFileSeparator)
+	if !found || !strings.HasPrefix(captorName, CaptorNamePrefix) {
+		return "", "", false
+	}
+
+	return deceptionPolicyName, captorName, true
+}
+
+// deployCaptorWithFalco generates a Falco rule to trace the filesystem access of a filesystem honeytoken trap
+// and stores it in the Falco rules ConfigMap, from which Falco loads it.
+func (r *FilesystemHoneytokenReconciler) deployCaptorWithFalco(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) error {
+	log := k8slog.FromContext(ctx)
+
+	if !utils.IsFalcoEnabled() {
+		err := fmt.Errorf("%w: the namespace of Falco is not configured (KONEY_FALCO_NAMESPACE)", trapsapi.ErrCaptorBackendMissing)
+		log.Error(err, "unable to deploy captor with Falco")
+		return err
+	}
+
+	ruleName, err := GenerateCaptorName(deceptionPolicy, trap)
+	if err != nil {
+		log.Error(err, "unable to generate Falco rule name")
+		return err
+	}
+
+	// Only monitor the pods of the referenced workloads on the selected nodes
+	captorPodLabels, err := resolveCaptorPodLabels(r, ctx, trap)
+	if err != nil {
+		log.Error(err, "unable to resolve pod labels of workloads")
+		return err
+	}
+
+	rules, err := generateFalcoRules(deceptionPolicy, trap, ruleName, captorPodLabels)
+	if err != nil {
+		log.Error(err, "unable to generate Falco rules")
+		return err
+	}
+
+	// The rules file is only written if it changed, so that Falco does not reload its rules needlessly
+	fileName := FalcoRulesFileName(deceptionPolicy.Name, ruleName)
+	changed, created := false, false
+	err = updateFalcoRulesConfigMap(ctx, r.falcoReader(), r, func(configMap *corev1.ConfigMap) bool {
+		_, isExisting := configMap.Data[fileName]
+		changed = setFalcoRulesFile(configMap, fileName, string(rules), time.Now())
+		created = !isExisting
+		return changed
+	})
+	if err != nil {
+		log.Error(err, "unable to update Falco rules", "configMap", FalcoRulesConfigMapName, "file", fileName)
+		return err
+	}
+
+	if changed {
+		log.Info("Falco rules applied", "configMap", FalcoRulesConfigMapName, "file", fileName)
+	}
+	if created {
+		r.recordEvent(corev1.EventTypeNormal, constants.EventReasonCaptorCreated, "Falco rules file %s added to ConfigMap %s", fileName, FalcoRulesConfigMapName)
+	}
+
+	return nil
+}
+
+// falcoReader returns the reader for the Falco rules ConfigMap. ConfigMaps are not cached,
+// so that the controller does not need to watch all ConfigMaps of the cluster.
+func (r *FilesystemHoneytokenReconciler) falcoReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// RemoveFalcoRules removes the rules files of a DeceptionPolicy from the Falco rules ConfigMap,
+// except for the rules files of the given captors. Nothing is removed if Falco is not enabled.
+func RemoveFalcoRules(ctx context.Context, reader client.Reader, writer client.Writer, deceptionPolicyName string, captorNames []string) error {
+	if !utils.IsFalcoEnabled() {
+		return nil
+	}
+
+	log := k8slog.FromContext(ctx)
+
+	removedFiles := []string{}
+	err := updateFalcoRulesConfigMap(ctx, reader, writer, func(configMap *corev1.ConfigMap) bool {
+		removedFiles = removeFalcoRulesFiles(configMap, func(fileDeceptionPolicyName, captorName string) bool {
+			return fileDeceptionPolicyName == deceptionPolicyName && !slices.Contains(captorNames, captorName)
+		})
+		return len(removedFiles) > 0
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(removedFiles) > 0 {
+		log.Info("Deleted Falco rules for removed traps", "configMap", FalcoRulesConfigMapName, "files", removedFiles)
+	}
+
+	return nil
+}
+
+// CollectOrphanedFalcoRules removes the rules files from the Falco rules ConfigMap that were added longer than
+// constants.OrphanedCaptorGracePeriod ago and whose DeceptionPolicy or trap does not exist anymore.
+// The expected captors are keyed by the name of their DeceptionPolicy. Nothing is removed if Falco is not enabled.
+func CollectOrphanedFalcoRules(ctx context.Context, reader client.Reader, writer client.Writer,
+	expectedCaptors map[string]map[string]bool, now time.Time) error {
+	if !utils.IsFalcoEnabled() {
+		return nil
+	}
+
+	log := k8slog.FromContext(ctx)
+
+	removedFiles := []string{}
+	err := updateFalcoRulesConfigMap(ctx, reader, writer, func(configMap *corev1.ConfigMap) bool {
+		timestamps := getFalcoRuleTimestamps(configMap)
+		removedFiles = removeFalcoRulesFiles(configMap, func(deceptionPolicyName, captorName string) bool {
+			fileName := FalcoRulesFileName(deceptionPolicyName, captorName)
+			if addedAt, ok := timestamps[fileName]; ok && now.Sub(addedAt) < constants.OrphanedCaptorGracePeriod {
+				return false
+			}
+			return !expectedCaptors[deceptionPolicyName][captorName]
+		})
+		return len(removedFiles) > 0
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, fileName := range removedFiles {
+		log.Info("Deleted orphaned Falco rules", "configMap", FalcoRulesConfigMapName, "file", fileName)
+	}
+
+	return nil
+}
+
+// updateFalcoRulesConfigMap applies a change to the Falco rules ConfigMap and writes it if the change returns true,
+// retrying on conflicts. The ConfigMap is created if it does not exist yet and the change adds rules files.
+func updateFalcoRulesConfigMap(ctx context.Context, reader client.Reader, writer client.Writer, change func(configMap *corev1.ConfigMap) bool) error {
+	key := client.ObjectKey{Namespace: utils.GetFalcoNamespace(), Name: FalcoRulesConfigMapName}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := reader.Get(ctx, key, configMap)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+			if !change(configMap) || len(configMap.Data) == 0 {
+				return nil
+			}
+
+			// If another replica created the ConfigMap in the meantime, retry with the ConfigMap that it created
+			err := writer.Create(ctx, configMap)
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), key.Name, err)
+			}
+			return err
+		}
+
+		if !change(configMap) {
+			return nil
+		}
+		return writer.Update(ctx, configMap)
+	})
+}
+
+// setFalcoRulesFile stores a rules file in the Falco rules ConfigMap and returns true if it was added or changed.
+// The time of the first addition is kept in an annotation, so that the file is not garbage collected right away.
+func setFalcoRulesFile(configMap *corev1.ConfigMap, fileName, rules string, now time.Time) bool {
+	if existingRules, ok := configMap.Data[fileName]; ok && existingRules == rules {
+		return false
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[fileName] = rules
+
+	timestamps := getFalcoRuleTimestamps(configMap)
+	if _, ok := timestamps[fileName]; !ok {
+		timestamps[fileName] = now
+	}
+	setFalcoRuleTimestamps(configMap, timestamps)
+
+	return true
+}
+
+// removeFalcoRulesFiles removes the rules files generated by Koney from the Falco rules ConfigMap,
+// for which the given function returns true, and returns the names of the removed files.
+func removeFalcoRulesFiles(configMap *corev1.ConfigMap, remove func(deceptionPolicyName, captorName string) bool) []string {
+	removedFiles := []string{}
+	for _, fileName := range slices.Sorted(maps.Keys(configMap.Data)) {
+		deceptionPolicyName, captorName, ok := parseFalcoRulesFileName(fileName)
+		if ok && remove(deceptionPolicyName, captorName) {
+			delete(configMap.Data, fileName)
+			removedFiles = append(removedFiles, fileName)
+		}
+	}
+
+	if len(removedFiles) > 0 {
+		timestamps := getFalcoRuleTimestamps(configMap)
+		for _, fileName := range removedFiles {
+			delete(timestamps, fileName)
+		}
+		setFalcoRuleTimestamps(configMap, timestamps)
+	}
+
+	return removedFiles
+}
+
+// getFalcoRuleTimestamps returns when the rules files of the Falco rules ConfigMap were added, keyed by their names.
+// Rules files without a (valid) timestamp are missing from the result.
+func getFalcoRuleTimestamps(configMap *corev1.ConfigMap) map[string]time.Time {
+	timestamps := map[string]time.Time{}
+	if value, ok := configMap.Annotations[constants.AnnotationKeyFalcoRuleTimestamps]; ok {
+		_ = json.Unmarshal([]byte(value), &timestamps)
+	}
+
+	return timestamps
+}
+
+// setFalcoRuleTimestamps stores when the rules files of the Falco rules ConfigMap were added.
+func setFalcoRuleTimestamps(configMap *corev1.ConfigMap, timestamps map[string]time.Time) {
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	if timestampsJSON, err := json.Marshal(timestamps); err == nil {
+		configMap.Annotations[constants.AnnotationKeyFalcoRuleTimestamps] = string(timestampsJSON)
+	}
+}
+
+// generateFalcoRules generates the Falco rules file for a filesystem honeytoken trap, with a single rule that
+// matches when a monitored path is opened in a selected container. The pods must also have the given labels.
+// The metadata of the trap is stored in the tags of the rule, as "key=value", since falcosidekick forwards them to the alert forwarder.
+func generateFalcoRules(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, ruleName string, podLabels map[string]string) ([]byte, error) {
+	metadata := buildCaptorMetadata(deceptionPolicy, trap)
+
+	tags := []string{FalcoTag}
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		tags = append(tags, key+"="+metadata[key])
+	}
+
+	outputFields := make([]string, 0, len(falcoOutputFields))
+	for _, field := range falcoOutputFields {
+		outputFields = append(outputFields, strings.ReplaceAll(field, ".", "_")+"=%"+field)
+	}
+
+	rule := falcoRule{
+		Rule:      ruleName,
+		Desc:      fmt.Sprintf("Access to a filesystem honeytoken of the DeceptionPolicy %s", deceptionPolicy.Name),
+		Condition: buildFalcoCondition(trap, podLabels),
+		Output:    "Koney filesystem honeytoken accessed (" + strings.Join(outputFields, " ") + ")",
+		Priority:  falcoPriority(trap),
+		Source:    "syscall",
+		Tags:      tags,
+	}
+
+	return yaml.Marshal([]falcoRule{rule})
+}
+
+// falcoPriority returns the priority of the Falco rule of a trap, according to its severity.
+// The severity override of the alerting settings takes precedence.
+func falcoPriority(trap v1alpha1.Trap) string {
+	severity := trap.Severity
+	if trap.Alerting != nil && trap.Alerting.Severity != "" {
+		severity = trap.Alerting.Severity
+	}
+
+	if priority, ok := falcoPriorities[severity]; ok {
+		return priority
+	}
+	return falcoPriorities["medium"]
+}
+
+// buildFalcoCondition returns the condition of the Falco rule of a trap: a monitored path is opened
+// in a container that any of the resource filters selects, in a pod with the given labels.
+func buildFalcoCondition(trap v1alpha1.Trap, podLabels map[string]string) string {
+	conditions := []string{
+		"evt.type in (open, openat, openat2)",
+		"evt.dir = <",
+		"container.id != host",
+		buildFalcoPathCondition(trap),
+	}
+
+	if resourceCondition := buildFalcoResourceCondition(trap); resourceCondition != "" {
+		conditions = append(conditions, resourceCondition)
+	}
+	for _, key := range slices.Sorted(maps.Keys(podLabels)) {
+		conditions = append(conditions, falcoPodLabelField(key)+" = "+falcoQuote(podLabels[key]))
+	}
+
+	return strings.Join(conditions, " and ")
+}
+
+// buildFalcoPathCondition returns the condition that matches the exact paths and the path patterns that a trap monitors.
+func buildFalcoPathCondition(trap v1alpha1.Trap) string {
+	exactPaths, prefixes, suffixes := trap.FilesystemHoneytoken.MonitoredPaths()
+
+	conditions := []string{}
+	if len(exactPaths) > 0 {
+		conditions = append(conditions, "fd.name in "+falcoList(exactPaths))
+	}
+	for _, prefix := range prefixes {
+		conditions = append(conditions, "fd.name startswith "+falcoQuote(prefix))
+	}
+	for _, suffix := range suffixes {
+		conditions = append(conditions, "fd.name endswith "+falcoQuote(suffix))
+	}
+
+	return falcoAny(conditions)
+}
+
+// buildFalcoResourceCondition returns the condition that matches the containers that any of the resource filters of a trap selects.
+// The condition is empty if all containers are selected.
+func buildFalcoResourceCondition(trap v1alpha1.Trap) string {
+	conditions := []string{}
+	for _, resourceFilter := range trap.MatchResources.Any {
+		condition := buildFalcoResourceFilterCondition(resourceFilter)
+		if condition == "" {
+			return ""
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+	return falcoAny(conditions)
+}
+
+// buildFalcoResourceFilterCondition returns the condition that matches the containers that a resource filter selects.
+// The condition is empty if the resource filter selects all containers.
+func buildFalcoResourceFilterCondition(resourceFilter v1alpha1.ResourceFilter) string {
+	conditions := []string{}
+	if len(resourceFilter.Namespaces) > 0 {
+		conditions = append(conditions, "k8s.ns.name in "+falcoList(resourceFilter.Namespaces))
+	}
+	if resourceFilter.Selector != nil {
+		conditions = append(conditions, buildFalcoSelectorConditions(resourceFilter.Selector)...)
+	}
+
+	includes := resourceFilter.ContainerIncludes()
+	if len(includes) > 0 && !slices.ContainsFunc(includes, matching.ContainerSelectorSelectsAll) {
+		includeConditions := []string{}
+		for _, include := range includes {
+			includeConditions = append(includeConditions, buildFalcoPatternCondition("container.name", include))
+		}
+		conditions = append(conditions, falcoAny(includeConditions))
+	}
+	if excludes := resourceFilter.ContainerExcludes(); len(excludes) > 0 {
+		excludeConditions := []string{}
+		for _, exclude := range excludes {
+			excludeConditions = append(excludeConditions, buildFalcoPatternCondition("container.name", exclude))
+		}
+		conditions = append(conditions, "not "+falcoGroup(strings.Join(excludeConditions, " or ")))
+	}
+	if resourceFilter.ImageSelector != "" {
+		if matching.ContainerSelectorIsPattern(resourceFilter.ImageSelector) {
+			conditions = append(conditions, buildFalcoPatternCondition("container.image", resourceFilter.ImageSelector))
+		} else {
+			conditions = append(conditions, falcoGroup("container.image = "+falcoQuote(resourceFilter.ImageSelector)+
+				" or container.image.repository = "+falcoQuote(resourceFilter.ImageSelector)))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+	return falcoGroup(strings.Join(conditions, " and "))
+}
+
+// buildFalcoSelectorConditions returns the conditions that match the pods that a label selector selects.
+func buildFalcoSelectorConditions(selector *metav1.LabelSelector) []string {
+	conditions := []string{}
+	for _, key := range slices.Sorted(maps.Keys(selector.MatchLabels)) {
+		conditions = append(conditions, falcoPodLabelField(key)+" = "+falcoQuote(selector.MatchLabels[key]))
+	}
+
+	for _, expression := range selector.MatchExpressions {
+		field := falcoPodLabelField(expression.Key)
+		switch expression.Operator {
+		case metav1.LabelSelectorOpIn:
+			conditions = append(conditions, field+" in "+falcoList(expression.Values))
+		case metav1.LabelSelectorOpNotIn:
+			conditions = append(conditions, "not "+field+" in "+falcoList(expression.Values))
+		case metav1.LabelSelectorOpExists:
+			conditions = append(conditions, field+" exists")
+		case metav1.LabelSelectorOpDoesNotExist:
+			conditions = append(conditions, "not "+field+" exists")
+		}
+	}
+
+	return conditions
+}
+
+// buildFalcoPatternCondition returns the condition that matches a field against a container name pattern,
+// with the same syntax as the ContainerSelector. Regex patterns are searched in the value, like in MatchContainerName.
+func buildFalcoPatternCondition(field, pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, "regex:"):
+		return field + " regex " + falcoQuote(".*(?:"+strings.TrimPrefix(pattern, "regex:")+").*")
+	case strings.HasPrefix(pattern, "glob:"):
+		return field + " glob " + falcoQuote(strings.TrimPrefix(pattern, "glob:"))
+	default:
+		return field + " = " + falcoQuote(pattern)
+	}
+}
+
+// falcoPodLabelField returns the Falco field of a pod label.
+func falcoPodLabelField(key string) string {
+	return "k8s.pod.label[" + key + "]"
+}
+
+// falcoQuote quotes a string for a Falco condition.
+func falcoQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// falcoList returns a list of quoted strings for the "in" operator of a Falco condition.
+func falcoList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, falcoQuote(value))
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
+// falcoAny returns the condition that matches if any of the given conditions matches.
+func falcoAny(conditions []string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return falcoGroup(strings.Join(conditions, " or "))
+}
+
+// falcoGroup wraps a condition in parentheses.
+func falcoGroup(condition string) string {
+	return "(" + condition + ")"
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+)
+
+var _ = Describe("Falco captors", func() {
+	var (
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		trap            v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/token"},
+			CaptorDeployment:     v1alpha1.CaptorDeployment{Strategy: "falco"},
+			Severity:             "high",
+			MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Namespaces: []string{"default"},
+				Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Containers: &v1alpha1.ContainerSelection{Include: []string{"app", "glob:worker-*"}, Exclude: []string{"regex:debug"}},
+			}}}},
+		}
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-falco", Generation: 3},
+			Spec:       v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{trap}},
+		}
+	})
+
+	Context("When generating Falco rules", func() {
+		generateRule := func(podLabels map[string]string) falcoRule {
+			rulesYAML, err := generateFalcoRules(deceptionPolicy, trap, "koney-tracing-policy-test", podLabels)
+			Expect(err).NotTo(HaveOccurred())
+
+			rules := []falcoRule{}
+			Expect(yaml.Unmarshal(rulesYAML, &rules)).To(Succeed())
+			Expect(rules).To(HaveLen(1))
+			return rules[0]
+		}
+
+		It("should match the monitored paths in the selected containers", func() {
+			rule := generateRule(map[string]string{"koney/node-scope-abc": "true"})
+			Expect(rule.Rule).To(Equal("koney-tracing-policy-test"))
+			Expect(rule.Priority).To(Equal("ERROR"))
+			Expect(rule.Condition).To(Equal(`evt.type in (open, openat, openat2) and evt.dir = < and container.id != host` +
+				` and fd.name in ("/run/secrets/koney/token")` +
+				` and (k8s.ns.name in ("default") and k8s.pod.label[app] = "web"` +
+				` and (container.name = "app" or container.name glob "worker-*")` +
+				` and not (container.name regex ".*(?:debug).*" or container.name = "envoy"` +
+				` or container.name = "istio-proxy" or container.name = "linkerd-proxy"))` +
+				` and k8s.pod.label[koney/node-scope-abc] = "true"`))
+			Expect(rule.Output).To(ContainSubstring("fd_name=%fd.name"))
+			Expect(rule.Output).To(ContainSubstring("proc_pid=%proc.pid"))
+		})
+
+		It("should match path patterns and all containers of any resource filter", func() {
+			trap.FilesystemHoneytoken.MonitorPaths = []string{"/var/backups/*", `*"quoted".kdbx`}
+			trap.MatchResources.Any = append(trap.MatchResources.Any, v1alpha1.ResourceFilter{})
+
+			rule := generateRule(nil)
+			Expect(rule.Condition).To(ContainSubstring(`and (fd.name in ("/run/secrets/koney/token")` +
+				` or fd.name startswith "/var/backups/" or fd.name endswith "\"quoted\".kdbx") and`))
+			Expect(rule.Condition).To(HaveSuffix(` or (not (container.name = "envoy"` +
+				` or container.name = "istio-proxy" or container.name = "linkerd-proxy")))`))
+		})
+
+		It("should store the metadata of the trap in the tags", func() {
+			trap.Alerting = &v1alpha1.Alerting{Severity: "critical"}
+			deceptionPolicy.Spec.Traps = []v1alpha1.Trap{trap}
+
+			rule := generateRule(nil)
+			Expect(rule.Priority).To(Equal("CRITICAL"))
+			Expect(rule.Tags[0]).To(Equal(FalcoTag))
+			Expect(rule.Tags).To(ContainElements(
+				constants.MetadataKeyDeceptionPolicyName+"=deceptionpolicy-falco",
+				constants.MetadataKeyDeceptionPolicyGeneration+"=3",
+				constants.MetadataKeyFilePath+"=/run/secrets/koney/token",
+				constants.MetadataKeySeverity+"=high",
+			))
+		})
+	})
+
+	Context("When managing the Falco rules ConfigMap", func() {
+		var (
+			ctx          context.Context
+			fakeClient   client.Client
+			reconciler   FilesystemHoneytokenReconciler
+			configMapKey client.ObjectKey
+		)
+
+		BeforeEach(func() {
+			GinkgoT().Setenv("KONEY_FALCO_NAMESPACE", "falco")
+			ctx = context.Background()
+			fakeClient = fake.NewClientBuilder().Build()
+			reconciler = FilesystemHoneytokenReconciler{Client: fakeClient}
+			configMapKey = client.ObjectKey{Namespace: "falco", Name: FalcoRulesConfigMapName}
+		})
+
+		getRulesFiles := func() map[string]string {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			return configMap.Data
+		}
+
+		It("should emit an event when a rules file is added, but not when it is unchanged", func() {
+			recorder := record.NewFakeRecorder(10)
+			reconciler.Recorder = recorder
+			reconciler.DeceptionPolicy = deceptionPolicy
+
+			Expect(reconciler.deployCaptorWithFalco(ctx, deceptionPolicy, trap)).To(Succeed())
+			Expect(reconciler.deployCaptorWithFalco(ctx, deceptionPolicy, trap)).To(Succeed())
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(HavePrefix("Normal " + constants.EventReasonCaptorCreated + " Falco rules file"))
+		})
+
+		It("should fail if the namespace of Falco is not configured", func() {
+			GinkgoT().Setenv("KONEY_FALCO_NAMESPACE", "")
+			Expect(reconciler.deployCaptorWithFalco(ctx, deceptionPolicy, trap)).To(MatchError(trapsapi.ErrCaptorBackendMissing))
+		})
+
+		It("should add one rules file per captor and remove it with its trap", func() {
+			Expect(reconciler.deployCaptorWithFalco(ctx, deceptionPolicy, trap)).To(Succeed())
+
+			captorName, err := GenerateCaptorName(deceptionPolicy, trap)
+			Expect(err).NotTo(HaveOccurred())
+			fileName := FalcoRulesFileName(deceptionPolicy.Name, captorName)
+			Expect(getRulesFiles()).To(HaveKey(fileName))
+
+			Expect(RemoveFalcoRules(ctx, fakeClient, fakeClient, deceptionPolicy.Name, []string{captorName})).To(Succeed())
+			Expect(getRulesFiles()).To(HaveKey(fileName))
+
+			Expect(RemoveFalcoRules(ctx, fakeClient, fakeClient, deceptionPolicy.Name, nil)).To(Succeed())
+			Expect(getRulesFiles()).NotTo(HaveKey(fileName))
+		})
+
+		It("should only collect orphaned rules files after the grace period", func() {
+			Expect(reconciler.deployCaptorWithFalco(ctx, deceptionPolicy, trap)).To(Succeed())
+			Expect(getRulesFiles()).To(HaveLen(1))
+
+			Expect(CollectOrphanedFalcoRules(ctx, fakeClient, fakeClient, map[string]map[string]bool{}, time.Now())).To(Succeed())
+			Expect(getRulesFiles()).To(HaveLen(1))
+
+			later := time.Now().Add(constants.OrphanedCaptorGracePeriod + time.Second)
+			Expect(CollectOrphanedFalcoRules(ctx, fakeClient, fakeClient, map[string]map[string]bool{}, later)).To(Succeed())
+			Expect(getRulesFiles()).To(BeEmpty())
+		})
+
+		It("should keep rules files that were not generated by Koney", func() {
+			Expect(fakeClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: configMapKey.Namespace, Name: configMapKey.Name},
+				Data:       map[string]string{"custom_rules.yaml": "[]"},
+			})).To(Succeed())
+
+			Expect(CollectOrphanedFalcoRules(ctx, fakeClient, fakeClient, map[string]map[string]bool{}, time.Now())).To(Succeed())
+			Expect(getRulesFiles()).To(HaveKey("custom_rules.yaml"))
+		})
+	})
+})
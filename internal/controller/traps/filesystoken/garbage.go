@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// CollectOrphanedSecrets deletes the secrets of decoys (labeled with constants.LabelKeyDecoySecret) that no pod and no
+// pod template of any workload mounts anymore. Since the secrets are not used anywhere, it does not matter which DeceptionPolicies
+// own them (the DeceptionPolicies deploy them again if they need them). The mounts are looked up with the reader
+// (i.e., directly from the API server), so that secrets that were mounted just now are not mistaken for orphans.
+// Secrets younger than constants.OrphanedSecretGracePeriod are skipped, because their pods might not exist yet.
+func CollectOrphanedSecrets(ctx context.Context, reader client.Reader, c client.Client, now time.Time) error {
+	log := k8slog.FromContext(ctx)
+	var joinedErrors error
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, client.MatchingLabels{constants.LabelKeyDecoySecret: "true"}); err != nil {
+		return err
+	}
+
+	// Names of the secrets that are mounted in each namespace, looked up lazily
+	mountedSecrets := make(map[string]map[string]bool)
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.DeletionTimestamp != nil || now.Sub(secret.CreationTimestamp.Time) < constants.OrphanedSecretGracePeriod {
+			continue
+		}
+
+		mounted, ok := mountedSecrets[secret.Namespace]
+		if !ok {
+			var err error
+			if mounted, err = listMountedSecrets(reader, ctx, secret.Namespace); err != nil {
+				joinedErrors = errors.Join(joinedErrors, err)
+				continue
+			}
+			mountedSecrets[secret.Namespace] = mounted
+		}
+		if mounted[secret.Name] {
+			continue
+		}
+
+		if err := c.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "unable to delete orphaned secret", "namespace", secret.Namespace, "secret", secret.Name)
+			joinedErrors = errors.Join(joinedErrors, err)
+			continue
+		}
+		log.Info("Deleted orphaned secret", "namespace", secret.Namespace, "secret", secret.Name)
+	}
+
+	return joinedErrors
+}
+
+// CollectOrphanedVolumes removes the decoy volumes (and their mounts, init containers, and sidecars) from deployments
+// if no trap in the annotations of the deployment (of any DeceptionPolicy) accounts for them anymore.
+// Deployments are the only workloads that Koney mounts decoy volumes into, other workloads are never mutated.
+func CollectOrphanedVolumes(ctx context.Context, c client.Client) error {
+	log := k8slog.FromContext(ctx)
+	var joinedErrors error
+
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments); err != nil {
+		return err
+	}
+
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if deployment.DeletionTimestamp != nil {
+			continue
+		}
+
+		orphanedVolumes, err := findOrphanedVolumes(deployment)
+		if err != nil {
+			log.Error(err, "unable to parse annotations of deployment", "namespace", deployment.Namespace, "deployment", deployment.Name)
+			joinedErrors = errors.Join(joinedErrors, err)
+			continue
+		}
+		if len(orphanedVolumes) == 0 {
+			continue
+		}
+
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := c.Get(ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}, deployment); err != nil {
+				return err
+			}
+
+			removeVolumesFromPodTemplate(&deployment.Spec.Template, orphanedVolumes)
+			return c.Update(ctx, deployment)
+		})
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "unable to remove orphaned volumes from deployment", "namespace", deployment.Namespace, "deployment", deployment.Name)
+			joinedErrors = errors.Join(joinedErrors, err)
+			continue
+		}
+		log.Info("Removed orphaned volumes from deployment", "namespace", deployment.Namespace, "deployment", deployment.Name, "volumes", orphanedVolumes)
+
+		if err := utils.ExcludeInjectedContainersFromAutoscalers(c, ctx, deployment); err != nil {
+			log.Error(err, "unable to exclude injected containers from autoscalers", "namespace", deployment.Namespace, "deployment", deployment.Name)
+			joinedErrors = errors.Join(joinedErrors, err)
+		}
+	}
+
+	return joinedErrors
+}
+
+// listMountedSecrets returns the names of the secrets that pods and the pod templates of all workloads in a namespace
+// mount as volumes. Workloads that are scaled down (or jobs that are not running right now) still need their secrets later.
+func listMountedSecrets(r client.Reader, ctx context.Context, namespace string) (map[string]bool, error) {
+	mounted := make(map[string]bool)
+	addVolumes := func(volumes []corev1.Volume) {
+		for _, volume := range volumes {
+			if secretName := volumeSecretName(volume); secretName != "" {
+				mounted[secretName] = true
+			}
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		addVolumes(pod.Spec.Volumes)
+	}
+
+	templates, err := listPodTemplates(r, ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		addVolumes(template.Spec.Volumes)
+	}
+
+	return mounted, nil
+}
+
+// listPodTemplates returns the pod templates of all workloads in a namespace, i.e., of deployments, replica sets,
+// stateful sets, daemon sets, jobs, and cron jobs.
+func listPodTemplates(r client.Reader, ctx context.Context, namespace string) ([]corev1.PodTemplateSpec, error) {
+	templates := []corev1.PodTemplateSpec{}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		templates = append(templates, deployment.Spec.Template)
+	}
+
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := r.List(ctx, replicaSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, replicaSet := range replicaSets.Items {
+		templates = append(templates, replicaSet.Spec.Template)
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		templates = append(templates, statefulSet.Spec.Template)
+	}
+
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := r.List(ctx, daemonSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		templates = append(templates, daemonSet.Spec.Template)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs.Items {
+		templates = append(templates, job.Spec.Template)
+	}
+
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, cronJob := range cronJobs.Items {
+		templates = append(templates, cronJob.Spec.JobTemplate.Spec.Template)
+	}
+
+	return templates, nil
+}
+
+// findOrphanedVolumes returns the names of the decoy volumes of a deployment that no trap in its annotations accounts for.
+func findOrphanedVolumes(deployment *appsv1.Deployment) ([]string, error) {
+	accounted := make(map[string]bool)
+	if existingChanges, ok := deployment.GetAnnotations()[constants.AnnotationKeyChanges]; ok {
+		var changes []v1alpha1.ChangeAnnotation
+		if err := json.Unmarshal([]byte(existingChanges), &changes); err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			for _, trap := range change.Traps {
+				accounted[generateVolumeName(trap.FilesystemHoneytoken.FilePath)] = true
+			}
+		}
+	}
+
+	orphanedVolumes := []string{}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if strings.HasPrefix(volume.Name, volumeNamePrefix) && !accounted[volume.Name] {
+			orphanedVolumes = append(orphanedVolumes, volume.Name)
+		}
+	}
+
+	return orphanedVolumes, nil
+}
+
+// removeVolumesFromPodTemplate removes the given decoy volumes from a pod template,
+// together with their mounts, the init containers that fill them, and the watcher sidecars that monitor them.
+func removeVolumesFromPodTemplate(template *corev1.PodTemplateSpec, volumeNames []string) {
+	initContainerNames := make([]string, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		initContainerNames = append(initContainerNames, initContainerNamePrefix+strings.TrimPrefix(volumeName, volumeNamePrefix))
+	}
+
+	podSpec := &template.Spec
+	podSpec.Volumes = slices.DeleteFunc(podSpec.Volumes, func(volume corev1.Volume) bool {
+		return slices.Contains(volumeNames, volume.Name)
+	})
+	podSpec.InitContainers = slices.DeleteFunc(podSpec.InitContainers, func(container corev1.Container) bool {
+		return slices.Contains(initContainerNames, container.Name)
+	})
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = slices.DeleteFunc(podSpec.Containers[i].VolumeMounts, func(volumeMount corev1.VolumeMount) bool {
+			return slices.Contains(volumeNames, volumeMount.Name)
+		})
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].VolumeMounts = slices.DeleteFunc(podSpec.InitContainers[i].VolumeMounts, func(volumeMount corev1.VolumeMount) bool {
+			return slices.Contains(volumeNames, volumeMount.Name)
+		})
+	}
+
+	utils.UnmarkInjectedContainers(template, initContainerNames)
+	removeCaptorSidecars(template, volumeNames)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("Secret ownership", func() {
+	var (
+		ctx             context.Context
+		fakeClient      client.Client
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		fakeClient = fake.NewClientBuilder().Build()
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample", UID: "uid-1"}}
+	})
+
+	It("should make the DeceptionPolicy an owner of the secrets it creates", func() {
+		data := map[string][]byte{"service_token": []byte("someverysecrettoken")}
+		Expect(createSecret(fakeClient, ctx, deceptionPolicy, v1alpha1.Trap{}, "koney-demo", "koney-secret-abc", data)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "koney-demo", Name: "koney-secret-abc"}, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(isOwnerReferenceTo(secret.OwnerReferences[0], deceptionPolicy)).To(BeTrue())
+		Expect(secret.OwnerReferences[0].Controller).To(BeNil())
+		Expect(secret.Labels).To(HaveKeyWithValue(constants.LabelKeyDecoySecret, "true"))
+
+		By("sharing the secret with another DeceptionPolicy")
+		otherPolicy := &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "uid-2"}}
+		Expect(createSecret(fakeClient, ctx, otherPolicy, v1alpha1.Trap{}, "koney-demo", "koney-secret-abc", data)).To(Succeed())
+		Expect(createSecret(fakeClient, ctx, deceptionPolicy, v1alpha1.Trap{}, "koney-demo", "koney-secret-abc", data)).To(Succeed())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "koney-demo", Name: "koney-secret-abc"}, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(HaveLen(2))
+	})
+
+	It("should preserve the owners when a tampered immutable secret is recreated", func() {
+		otherPolicy := &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "uid-2"}}
+		data := map[string][]byte{"service_token": []byte("someverysecrettoken")}
+		key := client.ObjectKey{Namespace: "koney-demo", Name: "koney-secret-abc"}
+		Expect(ensureImmutableSecret(fakeClient, ctx, otherPolicy, v1alpha1.Trap{}, key.Namespace, key.Name, data)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, key, secret)).To(Succeed())
+		secret.Data = map[string][]byte{"service_token": []byte("tampered")}
+		secret.Immutable = nil
+		Expect(fakeClient.Update(ctx, secret)).To(Succeed())
+
+		Expect(ensureImmutableSecret(fakeClient, ctx, deceptionPolicy, v1alpha1.Trap{}, key.Namespace, key.Name, data)).To(Succeed())
+		Expect(fakeClient.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.Data).To(Equal(data))
+		Expect(secret.OwnerReferences).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("CollectOrphanedSecrets", func() {
+	var (
+		ctx             context.Context
+		now             time.Time
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample", UID: "uid-1"}}
+	})
+
+	newSecret := func(name string, age time.Duration, labeled bool) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "koney-demo",
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}}
+		if labeled {
+			addSecretLabel(secret)
+		}
+		addSecretOwner(secret, deceptionPolicy)
+		return secret
+	}
+
+	secretVolume := func(secretName string) []corev1.Volume {
+		return []corev1.Volume{{
+			Name:         "koney-volume-" + secretName,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}},
+		}}
+	}
+
+	secretExists := func(c client.Client, name string) bool {
+		err := c.Get(ctx, client.ObjectKey{Namespace: "koney-demo", Name: name}, &corev1.Secret{})
+		return err == nil
+	}
+
+	It("should only delete the labeled secrets that no pod or workload mounts anymore", func() {
+		old := 2 * constants.OrphanedSecretGracePeriod
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app"}},
+				Volumes:    secretVolume("koney-secret-mounted"),
+			}}},
+		}
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "koney-demo"},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "db"}},
+				Volumes:    secretVolume("koney-secret-statefulset"),
+			}}},
+		}
+		cronJob := &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "koney-demo"},
+			Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "backup"}},
+				Volumes:    secretVolume("koney-secret-cronjob"),
+			}}}}},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "koney-demo"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app"}},
+				Volumes: []corev1.Volume{{
+					Name: "koney-volume-projected",
+					VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+						Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "koney-secret-projected"}},
+					}}}},
+				}},
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().WithObjects(
+			deployment, statefulSet, cronJob, pod,
+			newSecret("koney-secret-mounted", old, true),
+			newSecret("koney-secret-statefulset", old, true),
+			newSecret("koney-secret-cronjob", old, true),
+			newSecret("koney-secret-projected", old, true),
+			newSecret("koney-secret-orphaned", old, true),
+			newSecret("koney-secret-young", constants.OrphanedSecretGracePeriod/2, true),
+			newSecret("koney-secret-unlabeled", old, false),
+		).Build()
+
+		Expect(CollectOrphanedSecrets(ctx, fakeClient, fakeClient, now)).To(Succeed())
+
+		Expect(secretExists(fakeClient, "koney-secret-mounted")).To(BeTrue())
+		Expect(secretExists(fakeClient, "koney-secret-statefulset")).To(BeTrue())
+		Expect(secretExists(fakeClient, "koney-secret-cronjob")).To(BeTrue())
+		Expect(secretExists(fakeClient, "koney-secret-projected")).To(BeTrue())
+		Expect(secretExists(fakeClient, "koney-secret-orphaned")).To(BeFalse())
+		Expect(secretExists(fakeClient, "koney-secret-young")).To(BeTrue())
+		Expect(secretExists(fakeClient, "koney-secret-unlabeled")).To(BeTrue())
+	})
+})
+
+var _ = Describe("CollectOrphanedVolumes", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	It("should remove the decoy volumes that no trap accounts for anymore", func() {
+		ctx := context.TODO()
+		activeVolume := generateVolumeName(FilePath)
+		orphanedVolume := generateVolumeName("/etc/koney/removed")
+		orphanedInitContainer := generateInitContainerName("/etc/koney/removed")
+
+		changes := `[{"deceptionPolicyName":"deceptionpolicy-sample","traps":[{"filesystemHoneytoken":{"filePath":"` + FilePath + `"}}]}]`
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "api",
+				Namespace:   "koney-demo",
+				Annotations: map[string]string{constants.AnnotationKeyChanges: changes},
+			},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{
+					Name:         orphanedInitContainer,
+					VolumeMounts: []corev1.VolumeMount{{Name: orphanedVolume, MountPath: "/koney"}},
+				}},
+				Containers: []corev1.Container{{
+					Name: "app",
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: activeVolume, MountPath: FilePath},
+						{Name: orphanedVolume, MountPath: "/etc/koney/removed"},
+						{Name: "data", MountPath: "/data"},
+					},
+				}},
+				Volumes: []corev1.Volume{{Name: activeVolume}, {Name: orphanedVolume}, {Name: "data"}},
+			}}},
+		}
+		utils.MarkInjectedContainers(&deployment.Spec.Template, []string{orphanedInitContainer})
+
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment).Build()
+		Expect(CollectOrphanedVolumes(ctx, fakeClient)).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "koney-demo", Name: "api"}, updated)).To(Succeed())
+		podSpec := updated.Spec.Template.Spec
+		Expect(podSpec.Volumes).To(ConsistOf(corev1.Volume{Name: activeVolume}, corev1.Volume{Name: "data"}))
+		Expect(podSpec.InitContainers).To(BeEmpty())
+		Expect(podSpec.Containers[0].VolumeMounts).To(HaveLen(2))
+		Expect(utils.GetInjectedContainers(&updated.Spec.Template)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// GeneratePlacementID derives the placement ID of a honeytoken in a pod, which is unique for every pod and file path.
+// The ID is derived from the UID of the pod, so that it cannot be guessed from the name of the pod alone,
+// and so that every controller replica computes the same ID.
+func GeneratePlacementID(pod corev1.Pod, filePath string) string {
+	digest := sha256.Sum256([]byte(fmt.Sprintf("%s:%s", pod.UID, filePath)))
+	return hex.EncodeToString(digest[:16])
+}
+
+// ResolvePlacementContext returns a copy of the trap whose file content is rendered for the pod in which it is placed,
+// i.e., with the placement context placeholders replaced with the values of the pod. Traps whose content does not
+// reference the placement context are returned unchanged.
+func ResolvePlacementContext(trap v1alpha1.Trap, pod corev1.Pod) v1alpha1.Trap {
+	if !trap.FilesystemHoneytoken.UsesPlacementContext() {
+		return trap
+	}
+
+	replacer := strings.NewReplacer(
+		v1alpha1.PlacementNamespacePlaceholder, pod.Namespace,
+		v1alpha1.PlacementPodNamePlaceholder, pod.Name,
+		v1alpha1.PlacementServiceAccountPlaceholder, pod.Spec.ServiceAccountName,
+		v1alpha1.PlacementNodeNamePlaceholder, pod.Spec.NodeName,
+		v1alpha1.PlacementIDPlaceholder, GeneratePlacementID(pod, trap.FilesystemHoneytoken.FilePath),
+	)
+	trap.FilesystemHoneytoken.FileContent = replacer.Replace(trap.FilesystemHoneytoken.FileContent)
+
+	return trap
+}
+
+// ResolvePlacementContexts calls ResolvePlacementContext for every trap.
+func ResolvePlacementContexts(traps []v1alpha1.Trap, pod corev1.Pod) []v1alpha1.Trap {
+	resolvedTraps := make([]v1alpha1.Trap, 0, len(traps))
+	for _, trap := range traps {
+		resolvedTraps = append(resolvedTraps, ResolvePlacementContext(trap, pod))
+	}
+
+	return resolvedTraps
+}
+
+// BuildTokenPlacement describes the values that a trap got in a pod, so that they can be recorded in the status.
+func BuildTokenPlacement(trap v1alpha1.Trap, pod corev1.Pod) v1alpha1.TokenPlacement {
+	resolvedTrap := ResolvePlacementContext(trap, pod)
+
+	return v1alpha1.TokenPlacement{
+		FilePath:        trap.FilesystemHoneytoken.FilePath,
+		Namespace:       pod.Namespace,
+		Pod:             pod.Name,
+		ServiceAccount:  pod.Spec.ServiceAccountName,
+		Node:            pod.Spec.NodeName,
+		PlacementIDHash: utils.Hash(GeneratePlacementID(pod, trap.FilesystemHoneytoken.FilePath)),
+		FileContentHash: utils.Hash(resolvedTrap.FilesystemHoneytoken.FileContent),
+	}
+}
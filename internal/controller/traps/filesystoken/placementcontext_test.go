@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("Placement context", func() {
+	var (
+		pod  corev1.Pod
+		trap v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		pod = corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api-7d9f", UID: "11111111-1111-1111-1111-111111111111"},
+			Spec:       corev1.PodSpec{ServiceAccountName: "api", NodeName: "node-1"},
+		}
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
+				FilePath: "/run/secrets/koney/aws_credentials",
+				FileContent: "aws_access_key_id = AKIA-" + v1alpha1.PlacementNamespacePlaceholder + "-" + v1alpha1.PlacementIDPlaceholder +
+					"\n# " + v1alpha1.PlacementPodNamePlaceholder + " " + v1alpha1.PlacementServiceAccountPlaceholder + " " + v1alpha1.PlacementNodeNamePlaceholder,
+			},
+		}
+	})
+
+	It("should render the content for the pod", func() {
+		placementID := GeneratePlacementID(pod, trap.FilesystemHoneytoken.FilePath)
+		Expect(placementID).To(HaveLen(32))
+
+		resolvedTrap := ResolvePlacementContext(trap, pod)
+		Expect(resolvedTrap.FilesystemHoneytoken.FileContent).To(Equal("aws_access_key_id = AKIA-payments-" + placementID + "\n# api-7d9f api node-1"))
+		Expect(trap.FilesystemHoneytoken.FileContent).To(ContainSubstring(v1alpha1.PlacementIDPlaceholder))
+	})
+
+	It("should render unique content for every pod", func() {
+		other := pod
+		other.Name = "api-8c2e"
+		other.UID = "22222222-2222-2222-2222-222222222222"
+
+		resolvedTraps := ResolvePlacementContexts([]v1alpha1.Trap{trap, trap}, pod)
+		Expect(resolvedTraps[0]).To(Equal(resolvedTraps[1]))
+		Expect(ResolvePlacementContext(trap, other).FilesystemHoneytoken.FileContent).NotTo(Equal(resolvedTraps[0].FilesystemHoneytoken.FileContent))
+	})
+
+	It("should not change content without placeholders", func() {
+		trap.FilesystemHoneytoken.FileContent = "token=" + v1alpha1.RotationTokenPlaceholder
+		Expect(ResolvePlacementContext(trap, pod)).To(Equal(trap))
+	})
+
+	It("should describe the placement for the status", func() {
+		tokenPlacement := BuildTokenPlacement(trap, pod)
+		Expect(tokenPlacement).To(Equal(v1alpha1.TokenPlacement{
+			FilePath:        trap.FilesystemHoneytoken.FilePath,
+			Namespace:       "payments",
+			Pod:             "api-7d9f",
+			ServiceAccount:  "api",
+			Node:            "node-1",
+			PlacementIDHash: utils.Hash(GeneratePlacementID(pod, trap.FilesystemHoneytoken.FilePath)),
+			FileContentHash: utils.Hash(ResolvePlacementContext(trap, pod).FilesystemHoneytoken.FileContent),
+		}))
+	})
+})
@@ -18,7 +18,10 @@ package filesystoken
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -33,11 +36,14 @@ import (
 
 // RemoveDecoy removes a FilesystemHoneytoken decoy from a resource.
 // The trap is only removed from the resources where the trap is deployed.
-func (r *FilesystemHoneytokenReconciler) RemoveDecoy(ctx context.Context, crdName string, trap v1alpha1.TrapAnnotation, resource client.Object) error {
+// The boolean return type indicates if the removal is still in progress in some containers (e.g., an ephemeral container
+// still removes the file) and this function should be called again later. Until then, the trap stays in the annotations.
+func (r *FilesystemHoneytokenReconciler) RemoveDecoy(ctx context.Context, crdName string, trap v1alpha1.TrapAnnotation, resource client.Object) (bool, error) {
 	log := k8slog.FromContext(ctx)
 
 	var joinedErrors error
 	var removedFromContainers []string
+	var pendingContainers []string
 
 	// Remove the trap from the selected container(s)
 	for _, containerName := range trap.Containers {
@@ -51,7 +57,19 @@ func (r *FilesystemHoneytokenReconciler) RemoveDecoy(ctx context.Context, crdNam
 				removedFromContainers = append(removedFromContainers, containerName)
 			}
 
-		case "volumeMount":
+		case "ephemeralContainer":
+			pod := resource.(*corev1.Pod)
+			if removed, err := r.removeDecoyWithEphemeralContainer(ctx, trap, *pod, containerName); err != nil {
+				log.Error(err, "unable to remove FilesystemHoneytoken trap from container", "container", containerName)
+				joinedErrors = errors.Join(joinedErrors, err)
+			} else if removed {
+				removedFromContainers = append(removedFromContainers, containerName)
+			} else {
+				pendingContainers = append(pendingContainers, containerName)
+			}
+
+		case "volumeMount", "projectedVolume", "imageVolume", "initContainer":
+			// All these strategies mount a volume in the deployment, so they are removed the same way
 			deployment := resource.(*appsv1.Deployment)
 			if err := r.removeDecoyWithVolumeMount(ctx, trap, *deployment, containerName); err != nil {
 				log.Error(err, "unable to remove FilesystemHoneytoken trap from container", "container", containerName)
@@ -60,6 +78,13 @@ func (r *FilesystemHoneytokenReconciler) RemoveDecoy(ctx context.Context, crdNam
 				removedFromContainers = append(removedFromContainers, containerName)
 			}
 
+		case "admission":
+			// Volumes cannot be removed from running pods, so the decoy stays in the pod until it is recreated
+			pod := resource.(*corev1.Pod)
+			log.Info("FilesystemHoneytoken trap cannot be removed from a running pod - it is removed when the pod is recreated",
+				"pod", pod.Name, "container", containerName)
+			removedFromContainers = append(removedFromContainers, containerName)
+
 		case "kyvernoPolicy":
 			log.Info("KyvernoPolicy strategy not implemented yet")
 			joinedErrors = errors.New("KyvernoPolicy strategy not implemented yet")
@@ -67,7 +92,7 @@ func (r *FilesystemHoneytokenReconciler) RemoveDecoy(ctx context.Context, crdNam
 			log.Error(nil, "unknown strategy", "strategy", trap.DeploymentStrategy)
 			joinedErrors = errors.New("unknown strategy")
 
-			return joinedErrors
+			return false, joinedErrors
 		}
 	}
 
@@ -125,7 +150,7 @@ func (r *FilesystemHoneytokenReconciler) RemoveDecoy(ctx context.Context, crdNam
 		}
 	}
 
-	return joinedErrors
+	return len(pendingContainers) > 0, joinedErrors
 }
 
 // removeDecoyWithContainerExec removes a FilesystemHoneytoken trap from a pod using the containerExec strategy.
@@ -134,6 +159,19 @@ func (r *FilesystemHoneytokenReconciler) removeDecoyWithContainerExec(ctx contex
 
 	var joinedErrors error
 
+	if isContainerFilesystemGone(pod, containerName) {
+		log.Info("FilesystemHoneytoken trap removed together with the filesystem of the container", "pod", pod.Name, "container", containerName)
+		return nil
+	}
+
+	// Containers without a shell (and rm) got the decoy with tar, so it is also removed with tar
+	if method, err := r.detectPlacementMethod(ctx, pod, containerName); err != nil {
+		log.Error(err, "unable to detect how to remove FilesystemHoneytoken trap from container", "container", containerName)
+		return err
+	} else if method == placementMethodTar {
+		return r.removeDecoyWithTar(ctx, trap, pod, containerName)
+	}
+
 	// Remove the file (do not fail if the file is already gone)
 	cmd := []string{"rm", "-f", trap.FilesystemHoneytoken.FilePath}
 	output, err := r.executeCommandInContainer(ctx, pod, containerName, cmd)
@@ -160,14 +198,111 @@ func (r *FilesystemHoneytokenReconciler) removeDecoyWithContainerExec(ctx contex
 	return joinedErrors
 }
 
-// removeDecoyWithVolumeMount removes a FilesystemHoneytoken trap a deployment using the volumeMount strategy.
+// removeDecoyWithEphemeralContainer removes a FilesystemHoneytoken trap from a container of a pod using the ephemeralContainer strategy,
+// i.e., by adding another ephemeral container that removes the file from the filesystem of the container.
+// Ephemeral containers run asynchronously, so the first call only adds the ephemeral container, and later calls
+// check if it terminated. The boolean return type indicates if the file was removed.
+func (r *FilesystemHoneytokenReconciler) removeDecoyWithEphemeralContainer(ctx context.Context, trap v1alpha1.TrapAnnotation, pod corev1.Pod, containerName string) (bool, error) {
+	log := k8slog.FromContext(ctx)
+
+	if isContainerFilesystemGone(pod, containerName) {
+		log.Info("FilesystemHoneytoken trap removed together with the filesystem of the container", "pod", pod.Name, "container", containerName)
+		return true, nil
+	}
+
+	// If the file is already being removed (i.e., after it was written the last time), check if the removal finished
+	writePrefix := ephemeralContainerNamePrefix("write", trap.FilesystemHoneytoken.FilePath, containerName)
+	removePrefix := ephemeralContainerNamePrefix("remove", trap.FilesystemHoneytoken.FilePath, containerName)
+	removeName, removedAt, isRemoving := latestEphemeralContainer(pod, removePrefix)
+	if _, writtenAt, isWritten := latestEphemeralContainer(pod, writePrefix); isRemoving && (!isWritten || removedAt >= writtenAt) {
+		return isEphemeralContainerSucceeded(pod, removeName)
+	}
+
+	// The content of the trap is unknown at this point, but the file only needs to be removed,
+	// and we use the same image as the ephemeral container that wrote the file (which might be the only allowed one)
+	decoyTrap := v1alpha1.Trap{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: trap.FilesystemHoneytoken.FilePath}}
+	for _, ephemeralContainer := range pod.Spec.EphemeralContainers {
+		if strings.HasPrefix(ephemeralContainer.Name, writePrefix) {
+			decoyTrap.DecoyDeployment.EphemeralContainer = &v1alpha1.EphemeralContainerDecoy{
+				Image:      ephemeralContainer.Image,
+				PullPolicy: ephemeralContainer.ImagePullPolicy,
+			}
+		}
+	}
+
+	// Remove the file (do not fail if the file is already gone)
+	script := `rm -f "$KONEY_FILE_PATH"`
+
+	name := generateEphemeralContainerName("remove", trap.FilesystemHoneytoken.FilePath, containerName, time.Now())
+	if err := r.addEphemeralContainer(ctx, &pod, buildEphemeralContainer(decoyTrap, pod, containerName, name, script)); err != nil {
+		log.Error(err, "unable to add ephemeral container to pod", "pod", pod.Name, "ephemeralContainer", name)
+		return false, err
+	}
+
+	log.Info("Removing FilesystemHoneytoken trap from container", "container", containerName, "ephemeralContainer", name)
+	return false, nil
+}
+
+// isContainerFilesystemGone returns true if the filesystem of a container (and a decoy that was written into it)
+// is gone or about to be discarded, e.g., because the pod terminates or the container is not running (anymore).
+func isContainerFilesystemGone(pod corev1.Pod, containerName string) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			return status.State.Running == nil
+		}
+	}
+
+	return true
+}
+
+// latestEphemeralContainer returns the name of the ephemeral container with the given prefix that was added last,
+// and when it was added (as encoded in its name by generateEphemeralContainerName).
+func latestEphemeralContainer(pod corev1.Pod, prefix string) (string, int64, bool) {
+	var latestName string
+	var latestAddedAt int64
+	found := false
+	for _, ephemeralContainer := range pod.Spec.EphemeralContainers {
+		suffix, ok := strings.CutPrefix(ephemeralContainer.Name, prefix)
+		if !ok {
+			continue
+		}
+		addedAt, _ := strconv.ParseInt(suffix, 36, 64)
+		if !found || addedAt >= latestAddedAt {
+			latestName, latestAddedAt, found = ephemeralContainer.Name, addedAt, true
+		}
+	}
+
+	return latestName, latestAddedAt, found
+}
+
+// isEphemeralContainerSucceeded returns true if an ephemeral container terminated successfully,
+// false if it still runs (or was not started yet), and an error if it failed.
+func isEphemeralContainerSucceeded(pod corev1.Pod, name string) (bool, error) {
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name != name || status.State.Terminated == nil {
+			continue
+		}
+		if exitCode := status.State.Terminated.ExitCode; exitCode != 0 {
+			return false, fmt.Errorf("ephemeral container %s failed with exit code %d", name, exitCode)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// removeDecoyWithVolumeMount removes a FilesystemHoneytoken trap a deployment using the volumeMount (or projectedVolume, imageVolume, or initContainer) strategy,
+// together with the watcher sidecar of the sidecar captor strategy.
 func (r *FilesystemHoneytokenReconciler) removeDecoyWithVolumeMount(ctx context.Context, trap v1alpha1.TrapAnnotation, deployment appsv1.Deployment, containerName string) error {
 	log := k8slog.FromContext(ctx)
 
 	var joinedErrors error
 
 	volumeName := generateVolumeName(trap.FilesystemHoneytoken.FilePath)
-	secretName := ""
 
 	// Remove the volume mount from the container
 	for i, container := range deployment.Spec.Template.Spec.Containers {
@@ -193,12 +328,27 @@ func (r *FilesystemHoneytokenReconciler) removeDecoyWithVolumeMount(ctx context.
 		if volume.Name != volumeName {
 			newVolumes = append(newVolumes, deployment.Spec.Template.Spec.Volumes[i])
 		} else {
-			secretName = volume.Secret.SecretName
 			log.Info("Removing volume from deployment", "volume", volumeName)
 		}
 	}
 	deployment.Spec.Template.Spec.Volumes = newVolumes
 
+	// Remove the init container that wrote the honeytoken into the volume (only exists for the initContainer strategy)
+	initContainerName := generateInitContainerName(trap.FilesystemHoneytoken.FilePath)
+	newInitContainers := []corev1.Container{}
+	for i, initContainer := range deployment.Spec.Template.Spec.InitContainers {
+		if initContainer.Name != initContainerName {
+			newInitContainers = append(newInitContainers, deployment.Spec.Template.Spec.InitContainers[i])
+		} else {
+			log.Info("Removing init container from deployment", "initContainer", initContainerName)
+			utils.UnmarkInjectedContainers(&deployment.Spec.Template, []string{initContainerName})
+		}
+	}
+	deployment.Spec.Template.Spec.InitContainers = newInitContainers
+
+	// Remove the watcher sidecar that monitored the volume (only exists for the sidecar captor strategy)
+	removeCaptorSidecars(&deployment.Spec.Template, []string{volumeName})
+
 	// Use RetryOnConflict to elegantly avoid conflicts when updating a resource
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// TODO: Can we use patch instead of update to avoid conflicts?
@@ -209,20 +359,13 @@ func (r *FilesystemHoneytokenReconciler) removeDecoyWithVolumeMount(ctx context.
 		joinedErrors = errors.Join(joinedErrors, err)
 	} else {
 		log.Info("FilesystemHoneytoken trap removed from container", "container", containerName)
-	}
-
-	// Delete the secret, if it was created by the trap
-	if secretName != "" {
-		secret := corev1.Secret{}
-		err = r.Get(ctx, client.ObjectKey{Namespace: deployment.Namespace, Name: secretName}, &secret)
-		if err != nil {
-			log.Error(err, "unable to get secret", "secret", secretName)
+		if err := utils.ExcludeInjectedContainersFromAutoscalers(r.Client, ctx, &deployment); err != nil {
+			log.Error(err, "unable to exclude injected containers from autoscalers", "deployment", deployment.Name)
 			joinedErrors = errors.Join(joinedErrors, err)
-		} else {
-			// This might fail if the secret is still being used by another pod, we ignore the error
-			_ = r.Delete(ctx, &secret)
 		}
 	}
 
+	// The secret of the volume (if any) might still be mounted by other resources or in pods that are still running,
+	// so it is deleted by CollectOrphanedSecrets (which runs periodically) once it is not mounted anymore
 	return joinedErrors
 }
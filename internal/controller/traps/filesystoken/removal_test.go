@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+)
+
+var _ = Describe("RemoveDecoy", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx            context.Context
+		deployedAt     time.Time
+		pod            *corev1.Pod
+		annotationTrap v1alpha1.TrapAnnotation
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		deployedAt = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app"}},
+				EphemeralContainers: []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name: generateEphemeralContainerName("write", FilePath, "app", deployedAt),
+				}}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "app",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			},
+		}
+		annotationTrap = v1alpha1.TrapAnnotation{
+			DeploymentStrategy:   "ephemeralContainer",
+			Containers:           []string{"app"},
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{FilePath: FilePath},
+		}
+	})
+
+	// addRemoval adds an ephemeral container that removes the decoy, in the given state, to the pod
+	addRemoval := func(addedAt time.Time, state corev1.ContainerState) {
+		name := generateEphemeralContainerName("remove", FilePath, "app", addedAt)
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: name},
+		})
+		pod.Status.EphemeralContainerStatuses = append(pod.Status.EphemeralContainerStatuses, corev1.ContainerStatus{Name: name, State: state})
+	}
+
+	terminatedWith := func(exitCode int32) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}}
+	}
+
+	Context("with the ephemeralContainer strategy", func() {
+		It("should wait until the ephemeral container removed the file", func() {
+			addRemoval(deployedAt.Add(time.Minute), corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+			r := &FilesystemHoneytokenReconciler{}
+
+			removed, err := r.removeDecoyWithEphemeralContainer(ctx, annotationTrap, *pod, "app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removed).To(BeFalse())
+
+			pod.Status.EphemeralContainerStatuses[0].State = terminatedWith(0)
+			removed, err = r.removeDecoyWithEphemeralContainer(ctx, annotationTrap, *pod, "app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removed).To(BeTrue())
+		})
+
+		It("should report ephemeral containers that failed to remove the file", func() {
+			addRemoval(deployedAt.Add(time.Minute), terminatedWith(1))
+
+			removed, err := (&FilesystemHoneytokenReconciler{}).removeDecoyWithEphemeralContainer(ctx, annotationTrap, *pod, "app")
+			Expect(err).To(HaveOccurred())
+			Expect(removed).To(BeFalse())
+		})
+
+		It("should not count removals from before the file was written again", func() {
+			addRemoval(deployedAt.Add(-time.Minute), terminatedWith(0))
+
+			name, addedAt, found := latestEphemeralContainer(*pod, ephemeralContainerNamePrefix("remove", FilePath, "app"))
+			Expect(found).To(BeTrue())
+			Expect(name).To(Equal(pod.Spec.EphemeralContainers[1].Name))
+			_, writtenAt, _ := latestEphemeralContainer(*pod, ephemeralContainerNamePrefix("write", FilePath, "app"))
+			Expect(addedAt).To(BeNumerically("<", writtenAt))
+		})
+
+		It("should keep the trap in the annotations while the removal is in progress", func() {
+			addRemoval(deployedAt.Add(time.Minute), corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+			Expect(annotations.AddTrapToAnnotations(pod, "deceptionpolicy-sample", v1alpha1.Trap{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath},
+				DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "ephemeralContainer"},
+			}, []string{"app"})).To(Succeed())
+			annotationChange, err := annotations.GetAnnotationChange(pod, "deceptionpolicy-sample")
+			Expect(err).NotTo(HaveOccurred())
+			annotationTrap = annotationChange.Traps[0]
+
+			fakeClient := fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+			r := &FilesystemHoneytokenReconciler{Client: fakeClient}
+
+			pending, err := r.RemoveDecoy(ctx, "deceptionpolicy-sample", annotationTrap, pod)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeTrue())
+
+			updated := &corev1.Pod{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), updated)).To(Succeed())
+			annotationChange, err = annotations.GetAnnotationChange(updated, "deceptionpolicy-sample")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(annotationChange.Traps).To(HaveLen(1))
+			Expect(annotationChange.Traps[0].Containers).To(Equal([]string{"app"}))
+		})
+	})
+
+	It("should consider decoys in terminating pods or stopped containers removed", func() {
+		Expect(isContainerFilesystemGone(*pod, "app")).To(BeFalse())
+		Expect(isContainerFilesystemGone(*pod, "missing")).To(BeTrue())
+
+		stopped := pod.DeepCopy()
+		stopped.Status.ContainerStatuses[0].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+		Expect(isContainerFilesystemGone(*stopped, "app")).To(BeTrue())
+
+		terminating := pod.DeepCopy()
+		terminating.DeletionTimestamp = &metav1.Time{Time: deployedAt}
+		Expect(isContainerFilesystemGone(*terminating, "app")).To(BeTrue())
+
+		removed, err := (&FilesystemHoneytokenReconciler{}).removeDecoyWithEphemeralContainer(ctx, annotationTrap, *terminating, "app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(BeTrue())
+	})
+})
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+// PlacementTarget identifies a container of a resource in which a decoy is placed.
+type PlacementTarget struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Container string
+	FilePath  string
+}
+
+// placementDecision tells if a decoy should be placed in a target now.
+type placementDecision int
+
+const (
+	// placementDue means that the decoy should be placed now, because it was not tried yet or its backoff elapsed.
+	placementDue placementDecision = iota
+	// placementBackoff means that placing the decoy failed recently, so it is tried again later.
+	placementBackoff
+	// placementGaveUp means that placing the decoy failed too often, so it is not tried again.
+	placementGaveUp
+)
+
+// failedAttempts tracks the failed attempts to place a decoy in a target.
+type failedAttempts struct {
+	attempts    int
+	nextAttempt time.Time
+	lastError   string
+}
+
+// policyPlacementRetries tracks the failed placements of a DeceptionPolicy, for the generation in which they failed.
+type policyPlacementRetries struct {
+	generation int64
+	targets    map[PlacementTarget]*failedAttempts
+}
+
+// PlacementRetries tracks the failed placements of decoys per DeceptionPolicy, so that they are retried with exponential backoff,
+// and given up after constants.PlacementMaxAttempts attempts. The failed placements are forgotten when the DeceptionPolicy changes.
+// The zero value is ready to use, and all methods are safe to call on a nil pointer (which retries immediately and forever).
+type PlacementRetries struct {
+	mu       sync.Mutex
+	policies map[string]*policyPlacementRetries
+}
+
+// targetsOf returns the failed placements of the current generation of the DeceptionPolicy. The caller must hold the lock.
+func (p *PlacementRetries) targetsOf(deceptionPolicy *v1alpha1.DeceptionPolicy) map[PlacementTarget]*failedAttempts {
+	if p.policies == nil {
+		p.policies = map[string]*policyPlacementRetries{}
+	}
+
+	retries, ok := p.policies[deceptionPolicy.Name]
+	if !ok || retries.generation != deceptionPolicy.Generation {
+		retries = &policyPlacementRetries{generation: deceptionPolicy.Generation, targets: map[PlacementTarget]*failedAttempts{}}
+		p.policies[deceptionPolicy.Name] = retries
+	}
+	return retries.targets
+}
+
+// next tells if the decoy should be placed in the target now.
+func (p *PlacementRetries) next(deceptionPolicy *v1alpha1.DeceptionPolicy, target PlacementTarget, now time.Time) placementDecision {
+	if p == nil {
+		return placementDue
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failed, ok := p.targetsOf(deceptionPolicy)[target]
+	switch {
+	case !ok:
+		return placementDue
+	case failed.attempts >= constants.PlacementMaxAttempts:
+		return placementGaveUp
+	case now.Before(failed.nextAttempt):
+		return placementBackoff
+	default:
+		return placementDue
+	}
+}
+
+// failed records a failed attempt to place the decoy in the target, and returns true if this attempt was the last one.
+func (p *PlacementRetries) failed(deceptionPolicy *v1alpha1.DeceptionPolicy, target PlacementTarget, err error, now time.Time) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	targets := p.targetsOf(deceptionPolicy)
+	failed, ok := targets[target]
+	if !ok {
+		failed = &failedAttempts{}
+		targets[target] = failed
+	}
+
+	failed.attempts++
+	failed.lastError = err.Error()
+	failed.nextAttempt = now.Add(placementRetryDelay(failed.attempts))
+	return failed.attempts == constants.PlacementMaxAttempts
+}
+
+// succeeded forgets the failed attempts to place the decoy in the target.
+func (p *PlacementRetries) succeeded(deceptionPolicy *v1alpha1.DeceptionPolicy, target PlacementTarget) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.targetsOf(deceptionPolicy), target)
+}
+
+// FailedPlacements returns the placements of decoys at the given file paths that Koney gave up on, sorted by resource and container.
+func (p *PlacementRetries) FailedPlacements(deceptionPolicyName string, filePaths []string) []v1alpha1.FailedPlacement {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	retries, ok := p.policies[deceptionPolicyName]
+	if !ok {
+		return nil
+	}
+
+	var failedPlacements []v1alpha1.FailedPlacement
+	for target, failed := range retries.targets {
+		if failed.attempts < constants.PlacementMaxAttempts || !slices.Contains(filePaths, target.FilePath) {
+			continue
+		}
+		failedPlacements = append(failedPlacements, v1alpha1.FailedPlacement{
+			Kind: target.Kind, Namespace: target.Namespace, Name: target.Name, Container: target.Container, FilePath: target.FilePath,
+			Attempts: failed.attempts, LastError: failed.lastError,
+		})
+	}
+
+	slices.SortFunc(failedPlacements, func(a, b v1alpha1.FailedPlacement) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Container, b.Container), cmp.Compare(a.FilePath, b.FilePath))
+	})
+	return failedPlacements
+}
+
+// Forget forgets all failed placements of a DeceptionPolicy, e.g., because it was deleted.
+func (p *PlacementRetries) Forget(deceptionPolicyName string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.policies, deceptionPolicyName)
+}
+
+// placementRetryDelay returns the delay before the next attempt after the given number of failed attempts.
+func placementRetryDelay(attempts int) time.Duration {
+	delay := constants.PlacementRetryBaseDelay
+	for i := 1; i < attempts && delay < constants.PlacementRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, constants.PlacementRetryMaxDelay)
+}
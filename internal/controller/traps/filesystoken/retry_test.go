@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("PlacementRetries", func() {
+	var (
+		retries         *PlacementRetries
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		target          PlacementTarget
+		now             time.Time
+	)
+
+	BeforeEach(func() {
+		retries = &PlacementRetries{}
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-retries", Generation: 1}}
+		target = PlacementTarget{Kind: "Pod", Namespace: "default", Name: "web", Container: "app", FilePath: "/run/secrets/koney/token"}
+		now = time.Now()
+	})
+
+	It("should back off after a failed placement and retry after the delay", func() {
+		Expect(retries.next(deceptionPolicy, target, now)).To(Equal(placementDue))
+		Expect(retries.failed(deceptionPolicy, target, errors.New("read-only file system"), now)).To(BeFalse())
+
+		Expect(retries.next(deceptionPolicy, target, now.Add(time.Second))).To(Equal(placementBackoff))
+		Expect(retries.next(deceptionPolicy, target, now.Add(constants.PlacementRetryBaseDelay))).To(Equal(placementDue))
+
+		retries.succeeded(deceptionPolicy, target)
+		Expect(retries.next(deceptionPolicy, target, now)).To(Equal(placementDue))
+	})
+
+	It("should give up after the maximum number of attempts and report the failed placement", func() {
+		for attempt := 1; attempt <= constants.PlacementMaxAttempts; attempt++ {
+			lastAttempt := retries.failed(deceptionPolicy, target, errors.New("read-only file system"), now)
+			Expect(lastAttempt).To(Equal(attempt == constants.PlacementMaxAttempts))
+		}
+
+		Expect(retries.next(deceptionPolicy, target, now.Add(time.Hour))).To(Equal(placementGaveUp))
+		Expect(retries.FailedPlacements(deceptionPolicy.Name, []string{"/etc/other"})).To(BeEmpty())
+		Expect(retries.FailedPlacements(deceptionPolicy.Name, []string{target.FilePath})).To(ConsistOf(v1alpha1.FailedPlacement{
+			Kind: "Pod", Namespace: "default", Name: "web", Container: "app", FilePath: target.FilePath,
+			Attempts: constants.PlacementMaxAttempts, LastError: "read-only file system",
+		}))
+
+		By("trying again when the DeceptionPolicy changes")
+		deceptionPolicy.Generation++
+		Expect(retries.next(deceptionPolicy, target, now)).To(Equal(placementDue))
+	})
+
+	It("should only report the placements it gave up on, sorted by resource and container", func() {
+		other := target
+		other.Container = "sidecar"
+		pending := target
+		pending.Name = "api"
+		for range constants.PlacementMaxAttempts {
+			retries.failed(deceptionPolicy, other, errors.New("error"), now)
+			retries.failed(deceptionPolicy, target, errors.New("error"), now)
+		}
+		retries.failed(deceptionPolicy, pending, errors.New("error"), now)
+
+		failedPlacements := retries.FailedPlacements(deceptionPolicy.Name, []string{target.FilePath})
+		Expect(failedPlacements).To(HaveLen(2))
+		Expect(failedPlacements[0].Container).To(Equal("app"))
+		Expect(failedPlacements[1].Container).To(Equal("sidecar"))
+
+		retries.Forget(deceptionPolicy.Name)
+		Expect(retries.FailedPlacements(deceptionPolicy.Name, []string{target.FilePath})).To(BeEmpty())
+	})
+
+	It("should double the delay up to the maximum", func() {
+		Expect(placementRetryDelay(1)).To(Equal(constants.PlacementRetryBaseDelay))
+		Expect(placementRetryDelay(2)).To(Equal(2 * constants.PlacementRetryBaseDelay))
+		Expect(placementRetryDelay(100)).To(Equal(constants.PlacementRetryMaxDelay))
+	})
+
+	It("should retry immediately without tracking", func() {
+		var noRetries *PlacementRetries
+		Expect(noRetries.failed(deceptionPolicy, target, errors.New("error"), now)).To(BeFalse())
+		Expect(noRetries.next(deceptionPolicy, target, now)).To(Equal(placementDue))
+		Expect(noRetries.FailedPlacements(deceptionPolicy.Name, []string{target.FilePath})).To(BeNil())
+	})
+})
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// IsRotating returns true if the trap is a filesystem honeytoken that is rotated periodically.
+func IsRotating(trap v1alpha1.Trap) bool {
+	return trap.TrapType() == v1alpha1.FilesystemHoneytokenTrap &&
+		trap.FilesystemHoneytoken.RotateEvery != nil && trap.FilesystemHoneytoken.RotateEvery.Duration > 0
+}
+
+// RotationGeneration returns the number of rotations of the trap since the deception policy was created,
+// together with the time window during which this generation is active.
+// The generation is always 0 for traps that are not rotating.
+func RotationGeneration(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, now time.Time) (int64, time.Time, time.Time) {
+	start := deceptionPolicy.CreationTimestamp.Time
+	if !IsRotating(trap) {
+		return 0, start, time.Time{}
+	}
+
+	interval := trap.FilesystemHoneytoken.RotateEvery.Duration
+	generation := int64(0)
+	if now.After(start) {
+		generation = int64(now.Sub(start) / interval)
+	}
+	activeFrom := start.Add(time.Duration(generation) * interval)
+
+	return generation, activeFrom, activeFrom.Add(interval)
+}
+
+// GenerateRotationToken derives the token of a rotating honeytoken for the given generation.
+// The token is derived from the UID of the deception policy, so that it cannot be guessed from the
+// policy specification alone, and so that every controller replica computes the same token.
+func GenerateRotationToken(deceptionPolicy *v1alpha1.DeceptionPolicy, filePath string, generation int64) string {
+	digest := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%d", deceptionPolicy.UID, filePath, generation)))
+	return hex.EncodeToString(digest[:16])
+}
+
+// ResolveRotatedTrap returns a copy of the trap whose file content is rendered for the current generation,
+// i.e., with the rotation token placeholder replaced with the current token. Traps that are not rotating
+// are returned unchanged.
+func ResolveRotatedTrap(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, now time.Time) v1alpha1.Trap {
+	if !IsRotating(trap) {
+		return trap
+	}
+
+	generation, _, _ := RotationGeneration(deceptionPolicy, trap, now)
+	token := GenerateRotationToken(deceptionPolicy, trap.FilesystemHoneytoken.FilePath, generation)
+	trap.FilesystemHoneytoken.FileContent = strings.ReplaceAll(trap.FilesystemHoneytoken.FileContent, v1alpha1.RotationTokenPlaceholder, token)
+
+	return trap
+}
+
+// ResolveRotatedTraps calls ResolveRotatedTrap for every trap.
+func ResolveRotatedTraps(deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap, now time.Time) []v1alpha1.Trap {
+	resolvedTraps := make([]v1alpha1.Trap, 0, len(traps))
+	for _, trap := range traps {
+		resolvedTraps = append(resolvedTraps, ResolveRotatedTrap(deceptionPolicy, trap, now))
+	}
+
+	return resolvedTraps
+}
+
+// BuildHoneytokenRotation describes the current generation of a rotating trap, so that it can be recorded in the status.
+func BuildHoneytokenRotation(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, now time.Time) v1alpha1.HoneytokenRotation {
+	generation, activeFrom, activeUntil := RotationGeneration(deceptionPolicy, trap, now)
+	resolvedTrap := ResolveRotatedTrap(deceptionPolicy, trap, now)
+
+	return v1alpha1.HoneytokenRotation{
+		FilePath:        trap.FilesystemHoneytoken.FilePath,
+		Generation:      generation,
+		FileContentHash: utils.Hash(resolvedTrap.FilesystemHoneytoken.FileContent),
+		TokenHash:       utils.Hash(GenerateRotationToken(deceptionPolicy, trap.FilesystemHoneytoken.FilePath, generation)),
+		ActiveFrom:      metav1.NewTime(activeFrom),
+		ActiveUntil:     metav1.NewTime(activeUntil),
+	}
+}
+
+// NextRotation returns the earliest time at which one of the traps must be rotated next.
+// The second return value is false if none of the traps is rotating.
+func NextRotation(deceptionPolicy *v1alpha1.DeceptionPolicy, traps []v1alpha1.Trap, now time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, trap := range traps {
+		if !IsRotating(trap) {
+			continue
+		}
+
+		_, _, activeUntil := RotationGeneration(deceptionPolicy, trap, now)
+		if !found || activeUntil.Before(next) {
+			next = activeUntil
+			found = true
+		}
+	}
+
+	return next, found
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("Honeytoken rotation", func() {
+	var (
+		createdAt       time.Time
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		rotatingTrap    v1alpha1.Trap
+		staticTrap      v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		createdAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-deception-policy",
+				UID:               "00000000-0000-0000-0000-000000000000",
+				CreationTimestamp: metav1.NewTime(createdAt),
+			},
+		}
+		rotatingTrap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
+				FilePath:    "/run/secrets/koney/service_token",
+				FileContent: "token=" + v1alpha1.RotationTokenPlaceholder,
+				RotateEvery: &metav1.Duration{Duration: 24 * time.Hour},
+			},
+		}
+		staticTrap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{
+				FilePath:    "/run/secrets/koney/static_token",
+				FileContent: "token=" + v1alpha1.RotationTokenPlaceholder,
+			},
+		}
+	})
+
+	Context("with a trap that is not rotating", func() {
+		It("should leave the file content unchanged", func() {
+			resolvedTrap := ResolveRotatedTrap(deceptionPolicy, staticTrap, createdAt.Add(48*time.Hour))
+			Expect(resolvedTrap).To(Equal(staticTrap))
+
+			_, isRotating := NextRotation(deceptionPolicy, []v1alpha1.Trap{staticTrap}, createdAt)
+			Expect(isRotating).To(BeFalse())
+		})
+	})
+
+	Context("with a rotating trap", func() {
+		It("should render the same content within a generation", func() {
+			first := ResolveRotatedTrap(deceptionPolicy, rotatingTrap, createdAt.Add(1*time.Hour))
+			second := ResolveRotatedTrap(deceptionPolicy, rotatingTrap, createdAt.Add(23*time.Hour))
+
+			Expect(first.FilesystemHoneytoken.FileContent).To(Equal(second.FilesystemHoneytoken.FileContent))
+			Expect(first.FilesystemHoneytoken.FileContent).NotTo(ContainSubstring(v1alpha1.RotationTokenPlaceholder))
+		})
+
+		It("should render new content in the next generation", func() {
+			first := ResolveRotatedTrap(deceptionPolicy, rotatingTrap, createdAt.Add(1*time.Hour))
+			second := ResolveRotatedTrap(deceptionPolicy, rotatingTrap, createdAt.Add(25*time.Hour))
+
+			Expect(first.FilesystemHoneytoken.FileContent).NotTo(Equal(second.FilesystemHoneytoken.FileContent))
+		})
+
+		It("should not change the spec of the trap", func() {
+			ResolveRotatedTrap(deceptionPolicy, rotatingTrap, createdAt.Add(1*time.Hour))
+			Expect(rotatingTrap.FilesystemHoneytoken.FileContent).To(ContainSubstring(v1alpha1.RotationTokenPlaceholder))
+		})
+
+		It("should schedule the next rotation at the end of the current generation", func() {
+			nextRotation, isRotating := NextRotation(deceptionPolicy, []v1alpha1.Trap{staticTrap, rotatingTrap}, createdAt.Add(25*time.Hour))
+
+			Expect(isRotating).To(BeTrue())
+			Expect(nextRotation).To(Equal(createdAt.Add(48 * time.Hour)))
+		})
+
+		It("should describe the current generation for the status", func() {
+			now := createdAt.Add(25 * time.Hour)
+			rotation := BuildHoneytokenRotation(deceptionPolicy, rotatingTrap, now)
+			resolvedTrap := ResolveRotatedTrap(deceptionPolicy, rotatingTrap, now)
+
+			Expect(rotation.FilePath).To(Equal(rotatingTrap.FilesystemHoneytoken.FilePath))
+			Expect(rotation.Generation).To(Equal(int64(1)))
+			Expect(rotation.FileContentHash).To(Equal(utils.Hash(resolvedTrap.FilesystemHoneytoken.FileContent)))
+			Expect(rotation.ActiveFrom.Time).To(Equal(createdAt.Add(24 * time.Hour)))
+			Expect(rotation.ActiveUntil.Time).To(Equal(createdAt.Add(48 * time.Hour)))
+		})
+	})
+})
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// captorSidecarScript watches the decoy with inotifyd and reports every time it is opened to the alert forwarder.
+// inotify does not tell which process opened the file, so the report only identifies the pod and the trap.
+// The report is built with printf, so that the image only needs a shell, inotifyd, and wget (like busybox).
+const captorSidecarScript = `inotifyd - "$KONEY_WATCH_PATH:r" | while read -r event file; do
+  body=$(printf '{"time":"%s","node":"%s","pod":{"name":"%s","namespace":"%s"},"metadata":%s}' \
+    "$(date -u +%Y-%m-%dT%H:%M:%SZ)" "$KONEY_NODE_NAME" "$KONEY_POD_NAME" "$KONEY_POD_NAMESPACE" "$KONEY_METADATA")
+  wget -q -O /dev/null -T 10 --header "Content-Type: application/json" --post-data "$body" "$KONEY_CALLBACK_URL" ||
+    echo "unable to report access to $file" >&2
+done`
+
+// buildCaptorSidecar builds the watcher sidecar of the sidecar captor strategy, which mounts the volume of a decoy read-only
+// and reports to the alert forwarder when the honeytoken in it is opened. The metadata of the trap is passed in an environment variable.
+func buildCaptorSidecar(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap,
+	volume corev1.Volume, volumeMount corev1.VolumeMount) (corev1.Container, error) {
+	metadata := buildCaptorMetadata(deceptionPolicy, trap)
+	// The generation changes with every edit of the DeceptionPolicy, which would roll out the deployment every time
+	delete(metadata, constants.MetadataKeyDeceptionPolicyGeneration)
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return corev1.Container{}, err
+	}
+
+	return corev1.Container{
+		Name:    generateCaptorSidecarName(trap.FilesystemHoneytoken.FilePath),
+		Image:   constants.DefaultCaptorSidecarImage,
+		Command: []string{"sh", "-c", captorSidecarScript},
+		Env: []corev1.EnvVar{
+			{Name: "KONEY_WATCH_PATH", Value: filepath.Join(constants.CaptorSidecarDecoyDir, volumeMount.SubPath)},
+			{Name: "KONEY_METADATA", Value: string(metadataJSON)},
+			{Name: "KONEY_CALLBACK_URL", Value: buildAlertForwarderUrl("sidecar")},
+			{Name: "KONEY_NODE_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "spec.nodeName"}}},
+			{Name: "KONEY_POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.name"}}},
+			{Name: "KONEY_POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"}}},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: volume.Name, MountPath: constants.CaptorSidecarDecoyDir, ReadOnly: true}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("8Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+		},
+		// inotify only needs read access to the decoy, so the sidecar does not need any privileges
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                &[]int64{constants.CaptorSidecarUser}[0],
+			RunAsNonRoot:             &[]bool{true}[0],
+			AllowPrivilegeEscalation: &[]bool{false}[0],
+			ReadOnlyRootFilesystem:   &[]bool{true}[0],
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}, nil
+}
+
+// upsertCaptorSidecar adds the watcher sidecar to a pod template, or replaces it if the trap or the alert forwarder changed.
+// The function returns true if the pod template was changed.
+func upsertCaptorSidecar(template *corev1.PodTemplateSpec, sidecar corev1.Container) bool {
+	containers := template.Spec.Containers
+	index := slices.IndexFunc(containers, func(container corev1.Container) bool { return container.Name == sidecar.Name })
+	if index < 0 {
+		template.Spec.Containers = append(containers, sidecar)
+		utils.MarkInjectedContainers(template, []string{sidecar.Name})
+		return true
+	}
+
+	// The API server sets defaults for other fields, so only the fields that Koney sets are compared
+	// (the API version of the field references is set explicitly for the same reason)
+	if containers[index].Image == sidecar.Image && slices.Equal(containers[index].Command, sidecar.Command) &&
+		equality.Semantic.DeepEqual(containers[index].Env, sidecar.Env) {
+		return false
+	}
+
+	containers[index] = sidecar
+	return true
+}
+
+// removeCaptorSidecars removes the watcher sidecars of the given decoy volumes from a pod template.
+func removeCaptorSidecars(template *corev1.PodTemplateSpec, volumeNames []string) {
+	sidecarNames := make([]string, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		sidecarNames = append(sidecarNames, constants.CaptorSidecarNamePrefix+strings.TrimPrefix(volumeName, volumeNamePrefix))
+	}
+
+	template.Spec.Containers = slices.DeleteFunc(template.Spec.Containers, func(container corev1.Container) bool {
+		return slices.Contains(sidecarNames, container.Name)
+	})
+	utils.UnmarkInjectedContainers(template, sidecarNames)
+}
+
+// generateCaptorSidecarName generates the name of the watcher sidecar that monitors the honeytoken at the filePath.
+func generateCaptorSidecarName(filePath string) string {
+	return constants.CaptorSidecarNamePrefix + utils.Hash(filePath)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("sidecar captor strategy", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx        context.Context
+		reconciler FilesystemHoneytokenReconciler
+		trap       v1alpha1.Trap
+		deployment *appsv1.Deployment
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "token", ReadOnly: &[]bool{true}[0]},
+			DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "initContainer"},
+			CaptorDeployment:     v1alpha1.CaptorDeployment{Strategy: "sidecar"},
+			Severity:             "high",
+		}
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "worker"}}},
+				},
+			},
+		}
+		reconciler = FilesystemHoneytokenReconciler{
+			Client:          fake.NewClientBuilder().WithObjects(deployment).Build(),
+			DeceptionPolicy: &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sidecar", Generation: 2}},
+		}
+	})
+
+	It("should build an unprivileged sidecar that watches the decoy", func() {
+		_, volume, volumeMount, err := buildInitContainerVolume(trap)
+		Expect(err).ToNot(HaveOccurred())
+
+		sidecar, err := buildCaptorSidecar(reconciler.DeceptionPolicy, trap, volume, volumeMount)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(sidecar.Name).To(Equal(generateCaptorSidecarName(FilePath)))
+		Expect(sidecar.Image).To(Equal(constants.DefaultCaptorSidecarImage))
+		Expect(sidecar.Command[2]).To(ContainSubstring(`inotifyd - "$KONEY_WATCH_PATH:r"`))
+		Expect(sidecar.Env).To(ContainElements(
+			corev1.EnvVar{Name: "KONEY_WATCH_PATH", Value: "/koney/service_token"},
+			corev1.EnvVar{Name: "KONEY_CALLBACK_URL", Value: buildAlertForwarderUrl("sidecar")},
+		))
+		Expect(sidecar.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: volume.Name, MountPath: constants.CaptorSidecarDecoyDir, ReadOnly: true}))
+		Expect(*sidecar.SecurityContext.RunAsNonRoot).To(BeTrue())
+		Expect(*sidecar.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+
+		By("passing the metadata of the trap, except for the generation of the DeceptionPolicy")
+		metadata := map[string]string{}
+		Expect(json.Unmarshal([]byte(sidecar.Env[1].Value), &metadata)).To(Succeed())
+		Expect(metadata).To(HaveKeyWithValue(constants.MetadataKeyDeceptionPolicyName, "deceptionpolicy-sidecar"))
+		Expect(metadata).To(HaveKeyWithValue(constants.MetadataKeyFilePath, FilePath))
+		Expect(metadata).To(HaveKeyWithValue(constants.MetadataKeySeverity, "high"))
+		Expect(metadata).ToNot(HaveKey(constants.MetadataKeyDeceptionPolicyGeneration))
+	})
+
+	It("should only replace the sidecar if it changed", func() {
+		_, volume, volumeMount, err := buildInitContainerVolume(trap)
+		Expect(err).ToNot(HaveOccurred())
+		sidecar, err := buildCaptorSidecar(reconciler.DeceptionPolicy, trap, volume, volumeMount)
+		Expect(err).ToNot(HaveOccurred())
+
+		template := deployment.Spec.Template.DeepCopy()
+		Expect(upsertCaptorSidecar(template, sidecar)).To(BeTrue())
+		Expect(upsertCaptorSidecar(template, sidecar)).To(BeFalse())
+		Expect(template.Spec.Containers).To(HaveLen(3))
+
+		trap.Severity = "critical"
+		sidecar, err = buildCaptorSidecar(reconciler.DeceptionPolicy, trap, volume, volumeMount)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upsertCaptorSidecar(template, sidecar)).To(BeTrue())
+		Expect(template.Spec.Containers).To(HaveLen(3))
+		Expect(template.Spec.Containers[2].Env[1].Value).To(ContainSubstring("critical"))
+	})
+
+	It("should add the sidecar once and remove it with the decoy", func() {
+		Expect(reconciler.deployDecoyWithInitContainer(ctx, trap, *deployment, "app")).To(Succeed())
+		Expect(reconciler.deployDecoyWithInitContainer(ctx, trap, *deployment, "worker")).To(Succeed())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.Containers).To(HaveLen(3))
+		Expect(podSpec.Containers[2].Name).To(Equal(generateCaptorSidecarName(FilePath)))
+		Expect(utils.GetInjectedContainers(&deployment.Spec.Template)).To(ContainElement(podSpec.Containers[2].Name))
+
+		trapAnnotation := v1alpha1.TrapAnnotation{
+			DeploymentStrategy:   "initContainer",
+			Containers:           []string{"app", "worker"},
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{FilePath: FilePath},
+		}
+		Expect(reconciler.removeDecoyWithVolumeMount(ctx, trapAnnotation, *deployment, "app")).To(Succeed())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(2))
+		Expect(utils.GetInjectedContainers(&deployment.Spec.Template)).To(BeEmpty())
+	})
+
+	It("should never select the sidecar for traps", func() {
+		description := v1alpha1.ResourceDescription{}
+		Expect(description.MatchContainer(generateCaptorSidecarName(FilePath))).To(BeFalse())
+		Expect(description.MatchContainer("app")).To(BeTrue())
+	})
+})
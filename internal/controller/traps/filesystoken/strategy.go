@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+// execPermission caches if the controller is allowed to exec into pods, since it is checked for every trap with the "auto" strategy.
+var execPermission struct {
+	sync.Mutex
+	allowed   bool
+	checkedAt time.Time
+}
+
+// ResolveDecoyStrategy returns the trap with the strategy that its "auto" decoy deployment strategy chooses:
+// "containerExec" if the controller is allowed to exec into pods, or "projectedVolume" (which mounts a secret at the file path) otherwise.
+// Traps with other strategies are returned unchanged.
+func ResolveDecoyStrategy(ctx context.Context, c client.Client, trap v1alpha1.Trap) v1alpha1.Trap {
+	if trap.DecoyDeployment.Strategy != "auto" {
+		return trap
+	}
+
+	if canExecIntoPods(ctx, c, time.Now()) {
+		trap.DecoyDeployment.Strategy = "containerExec"
+	} else {
+		trap.DecoyDeployment.Strategy = "projectedVolume"
+	}
+	return trap
+}
+
+// canExecIntoPods checks with a SelfSubjectAccessReview if the controller is allowed to exec into pods in all namespaces.
+// The result is cached for constants.ExecPermissionProbeInterval, so that changed RBAC rules are picked up eventually.
+// If the check fails, exec is assumed to be denied, since the exec-free strategy works either way.
+func canExecIntoPods(ctx context.Context, c client.Client, now time.Time) bool {
+	log := k8slog.FromContext(ctx)
+
+	execPermission.Lock()
+	defer execPermission.Unlock()
+
+	if !execPermission.checkedAt.IsZero() && now.Sub(execPermission.checkedAt) < constants.ExecPermissionProbeInterval {
+		return execPermission.allowed
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "create", Resource: "pods", Subresource: "exec"},
+		},
+	}
+	if err := c.Create(ctx, review); err != nil {
+		log.Error(err, "unable to check if exec into pods is allowed - assuming it is not")
+		return false
+	}
+
+	if review.Status.Allowed != execPermission.allowed || execPermission.checkedAt.IsZero() {
+		log.Info("Checked if exec into pods is allowed for the auto decoy deployment strategy", "allowed", review.Status.Allowed)
+	}
+	execPermission.allowed = review.Status.Allowed
+	execPermission.checkedAt = now
+	return execPermission.allowed
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("auto decoy deployment strategy", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		allowed    bool
+		failure    error
+		numReviews int
+		trap       v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		allowed, failure, numReviews = false, nil, 0
+		execPermission.checkedAt = time.Time{}
+
+		fakeClient = fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+				if !ok {
+					return c.Create(ctx, obj, opts...)
+				}
+				numReviews++
+				Expect(review.Spec.ResourceAttributes.Subresource).To(Equal("exec"))
+				review.Status.Allowed = allowed
+				return failure
+			},
+		}).Build()
+
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/token"},
+			DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "auto"},
+		}
+	})
+
+	It("should exec into pods if it is allowed", func() {
+		allowed = true
+		Expect(ResolveDecoyStrategy(ctx, fakeClient, trap).DecoyDeployment.Strategy).To(Equal("containerExec"))
+		Expect(trap.DecoyDeployment.Strategy).To(Equal("auto"))
+	})
+
+	It("should mount a secret if exec is denied or cannot be checked", func() {
+		Expect(ResolveDecoyStrategy(ctx, fakeClient, trap).DecoyDeployment.Strategy).To(Equal("projectedVolume"))
+
+		execPermission.checkedAt = time.Time{}
+		allowed, failure = true, errors.New("forbidden")
+		Expect(ResolveDecoyStrategy(ctx, fakeClient, trap).DecoyDeployment.Strategy).To(Equal("projectedVolume"))
+	})
+
+	It("should only check the permission again after the probe interval", func() {
+		now := time.Now()
+		Expect(canExecIntoPods(ctx, fakeClient, now)).To(BeFalse())
+		allowed = true
+		Expect(canExecIntoPods(ctx, fakeClient, now.Add(time.Minute))).To(BeFalse())
+		Expect(numReviews).To(Equal(1))
+		Expect(canExecIntoPods(ctx, fakeClient, now.Add(constants.ExecPermissionProbeInterval))).To(BeTrue())
+		Expect(numReviews).To(Equal(2))
+	})
+
+	It("should keep other strategies", func() {
+		trap.DecoyDeployment.Strategy = "volumeMount"
+		Expect(ResolveDecoyStrategy(ctx, fakeClient, trap)).To(Equal(trap))
+		Expect(numReviews).To(BeZero())
+	})
+})
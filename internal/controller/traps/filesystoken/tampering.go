@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+// decoyTamperingRequestTimeout limits how long a report of a tampered decoy to the alert forwarder may take,
+// so that an unresponsive alert forwarder does not block the reconciliation.
+const decoyTamperingRequestTimeout = 10 * time.Second
+
+// defaultDecoyTamperingClient is the HTTP client of an HTTPDecoyTamperingReporter without a client.
+var defaultDecoyTamperingClient = &http.Client{Timeout: decoyTamperingRequestTimeout}
+
+// DecoyTampering is a decoy that was tampered with, i.e., changed or removed from a container, as reported to the alert forwarder.
+type DecoyTampering struct {
+	// Time is when the tampering was detected, in RFC 3339.
+	Time string `json:"time"`
+	// Reason is either DriftReasonContentTampered or DriftReasonFileMissing.
+	Reason string `json:"reason"`
+	// Kind, Namespace, and Name identify the resource in which the decoy was deployed, i.e., a pod or a workload.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Container is the container in which the decoy was tampered with.
+	Container string `json:"container"`
+	// Metadata is the metadata of the trap, with the same keys as captors pass along with their alerts.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// NewDecoyTampering returns the tampering of the decoy at a file path of a trap in a container of a resource.
+// It carries the metadata of the trap, so that the alert forwarder alerts on it like on accesses of the trap.
+func NewDecoyTampering(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, filePath string,
+	resource client.Object, container, reason string, now time.Time) DecoyTampering {
+	metadata := buildCaptorMetadata(deceptionPolicy, trap)
+	metadata[constants.MetadataKeyFilePath] = filePath
+
+	return DecoyTampering{
+		Time:      now.UTC().Format(time.RFC3339Nano),
+		Reason:    reason,
+		Kind:      utils.ResourceKind(resource),
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+		Container: container,
+		Metadata:  metadata,
+	}
+}
+
+// DecoyTamperingReporter reports tampered decoys, so that they are alerted on.
+type DecoyTamperingReporter interface {
+	// ReportTampering reports a tampered decoy, and returns an error if it could not be reported.
+	ReportTampering(ctx context.Context, tampering DecoyTampering) error
+}
+
+// HTTPDecoyTamperingReporter reports tampered decoys to the tampering handler of the alert forwarder, which alerts the sinks.
+type HTTPDecoyTamperingReporter struct {
+	// URL is the URL of the tampering handler (see BuildTamperingHandlerUrl).
+	URL string
+	// Client is the HTTP client that requests the alert forwarder, or a client with a timeout of 10 seconds if nil.
+	Client *http.Client
+}
+
+// ReportTampering posts a tampered decoy to the alert forwarder. It fails if the alert forwarder can neither deliver nor queue the alert.
+func (p *HTTPDecoyTamperingReporter) ReportTampering(ctx context.Context, tampering DecoyTampering) error {
+	body, err := json.Marshal(tampering)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	httpClient := p.Client
+	if httpClient == nil {
+		httpClient = defaultDecoyTamperingClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1<<10))
+		return fmt.Errorf("the alert forwarder responded with status %d: %s", response.StatusCode, responseBody)
+	}
+	return nil
+}
+
+// BuildTamperingHandlerUrl returns the URL of the handler of the alert forwarder that tampered decoys are reported to.
+func BuildTamperingHandlerUrl() string {
+	return buildAlertForwarderUrl("tampering")
+}
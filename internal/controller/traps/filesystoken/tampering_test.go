@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("Decoy tampering", func() {
+	var (
+		server             *httptest.Server
+		reportedTamperings []DecoyTampering
+		responseStatus     int
+		tampering          DecoyTampering
+	)
+
+	BeforeEach(func() {
+		reportedTamperings = nil
+		responseStatus = http.StatusAccepted
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/handlers/tampering"))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			var reported DecoyTampering
+			Expect(json.NewDecoder(r.Body).Decode(&reported)).To(Succeed())
+			reportedTamperings = append(reportedTamperings, reported)
+			w.WriteHeader(responseStatus)
+		}))
+
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-tampering"}}
+		trap := v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/*", FileContent: "token"},
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+		tampering = NewDecoyTampering(deceptionPolicy, trap, "/run/secrets/koney/token", pod, "app",
+			DriftReasonContentTampered, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should carry the metadata of the trap with the file path of the decoy", func() {
+		Expect(tampering.Time).To(Equal("2025-01-02T03:04:05Z"))
+		Expect(tampering.Reason).To(Equal(DriftReasonContentTampered))
+		Expect(tampering.Kind).To(Equal("Pod"))
+		Expect(tampering.Namespace).To(Equal("default"))
+		Expect(tampering.Name).To(Equal("web"))
+		Expect(tampering.Container).To(Equal("app"))
+		Expect(tampering.Metadata).To(HaveKeyWithValue(constants.MetadataKeyDeceptionPolicyName, "deceptionpolicy-tampering"))
+		Expect(tampering.Metadata).To(HaveKeyWithValue(constants.MetadataKeyFilePath, "/run/secrets/koney/token"))
+		Expect(tampering.Metadata).To(HaveKey(constants.MetadataKeyTrapHash))
+	})
+
+	It("should report tamperings to the alert forwarder", func() {
+		reporter := &HTTPDecoyTamperingReporter{URL: server.URL + "/handlers/tampering"}
+		Expect(reporter.ReportTampering(context.Background(), tampering)).To(Succeed())
+		Expect(reportedTamperings).To(ConsistOf(tampering))
+	})
+
+	It("should fail if the alert forwarder can neither deliver nor queue the alert", func() {
+		responseStatus = http.StatusServiceUnavailable
+		reporter := &HTTPDecoyTamperingReporter{URL: server.URL + "/handlers/tampering"}
+		Expect(reporter.ReportTampering(context.Background(), tampering)).To(MatchError(ContainSubstring("status 503")))
+	})
+
+	It("should build the URL of the tampering handler", func() {
+		Expect(BuildTamperingHandlerUrl()).To(HaveSuffix(".svc:8000/handlers/tampering"))
+	})
+})
@@ -0,0 +1,296 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilexec "k8s.io/client-go/util/exec"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+// placementMethod is how the containerExec strategy writes decoys into a container.
+type placementMethod string
+
+const (
+	// placementMethodShell writes the decoys with a shell script (see buildPlacementScript).
+	placementMethodShell placementMethod = "shell"
+	// placementMethodTar streams the decoys as a tar archive to tar via stdin (like kubectl cp), for containers without a shell.
+	placementMethodTar placementMethod = "tar"
+)
+
+// placementMethods caches the placement method per container image, since detecting it takes up to two execs.
+var placementMethods struct {
+	sync.Mutex
+	byImage map[string]placementMethod
+}
+
+// detectPlacementMethod returns the placement method for a container of a pod, which depends on the tools in its image:
+// the shell if there is one, or tar otherwise (e.g., in distroless images that still ship tar).
+// Containers without both cannot be written by exec, so an error is returned.
+func (r *FilesystemHoneytokenReconciler) detectPlacementMethod(ctx context.Context, pod corev1.Pod, containerName string) (placementMethod, error) {
+	log := k8slog.FromContext(ctx)
+
+	image := getContainerImage(pod, containerName)
+	placementMethods.Lock()
+	method, ok := placementMethods.byImage[image]
+	placementMethods.Unlock()
+	if ok {
+		return method, nil
+	}
+
+	method, err := r.probePlacementMethod(ctx, pod, containerName)
+	if err != nil {
+		return "", err
+	}
+
+	placementMethods.Lock()
+	if placementMethods.byImage == nil {
+		placementMethods.byImage = map[string]placementMethod{}
+	}
+	placementMethods.byImage[image] = method
+	placementMethods.Unlock()
+
+	log.Info("Detected how to place decoys in the container image", "image", image, "method", method)
+	return method, nil
+}
+
+// probePlacementMethod checks which tools are available in a container by executing them.
+// Errors that are not caused by a missing executable (e.g., because exec is forbidden) are returned as is.
+func (r *FilesystemHoneytokenReconciler) probePlacementMethod(ctx context.Context, pod corev1.Pod, containerName string) (placementMethod, error) {
+	output, err := r.executeCommandInContainer(ctx, pod, containerName, []string{"sh", "-c", "exit 0"})
+	if err == nil {
+		return placementMethodShell, nil
+	} else if !isExecutableNotFound(err, output) {
+		return "", err
+	}
+
+	output, err = r.executeCommandInContainer(ctx, pod, containerName, []string{"tar", "--version"})
+	if err == nil {
+		return placementMethodTar, nil
+	} else if !isExecutableNotFound(err, output) {
+		return "", err
+	}
+
+	return "", fmt.Errorf("%w, use the ephemeralContainer or projectedVolume decoy deployment strategy instead", trapsapi.ErrNoShell)
+}
+
+// isExecutableNotFound checks if an exec failed because the executable does not exist in the container.
+// Container runtimes report this with exit code 126 or 127, or only with their error message.
+func isExecutableNotFound(err error, output string) bool {
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && (exitErr.ExitStatus() == 126 || exitErr.ExitStatus() == 127) {
+		return true
+	}
+
+	message := err.Error() + " " + output
+	return strings.Contains(message, "executable file not found") || strings.Contains(message, "no such file or directory")
+}
+
+// getContainerImage returns the image of a container of a pod, by its digest if the pod reports it.
+func getContainerImage(pod corev1.Pod, containerName string) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName && status.ImageID != "" {
+			return status.ImageID
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == containerName {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// placeDecoysWithTar places the decoys of the given traps in a container of a pod by extracting a tar archive
+// that is streamed via stdin, and then verifies them by archiving them again. Only tar is needed in the container.
+// It returns the result of each decoy by file path.
+func (r *FilesystemHoneytokenReconciler) placeDecoysWithTar(ctx context.Context, pod corev1.Pod, containerName string, traps []v1alpha1.Trap) map[string]error {
+	log := k8slog.FromContext(ctx)
+
+	results := make(map[string]error, len(traps))
+	failAll := func(err error) map[string]error {
+		for _, trap := range traps {
+			results[trap.FilesystemHoneytoken.FilePath] = err
+		}
+		return results
+	}
+
+	archive, err := buildDecoyArchive(traps, time.Now())
+	if err != nil {
+		return failAll(err)
+	}
+
+	// tar creates missing directories on its own, and -p keeps the modes of the decoys
+	extractCommand := []string{"tar", "-x", "-p", "-f", "-", "-C", "/", tarFingerprintFlag()}
+	if output, err := r.executeCommandInContainerWithStdin(ctx, pod, containerName, extractCommand, bytes.NewReader(archive)); err != nil {
+		log.Error(err, "unable to deploy FilesystemHoneytoken traps to container with tar", "container", containerName, "stderr", output)
+		return failAll(fmt.Errorf("unable to extract the decoys with tar: %w", err))
+	}
+
+	archiveCommand := append([]string{"tar", "-c", "-f", "-", "-C", "/", tarFingerprintFlag()}, getArchivePaths(traps)...)
+	output, err := r.executeCommandInContainer(ctx, pod, containerName, archiveCommand)
+	if err != nil {
+		log.Error(err, "unable to read back FilesystemHoneytoken traps from container with tar", "container", containerName, "stderr", output)
+		return failAll(fmt.Errorf("unable to read the decoys back with tar: %w", err))
+	}
+
+	placedFiles, err := readDecoyArchive([]byte(output))
+	if err != nil {
+		return failAll(err)
+	}
+	for _, trap := range traps {
+		filePath := trap.FilesystemHoneytoken.FilePath
+		err := checkArchivedDecoy(trap, placedFiles)
+		if err != nil {
+			log.Error(err, "unable to deploy FilesystemHoneytoken trap to container", "container", containerName, "filePath", filePath)
+		} else {
+			log.Info("FilesystemHoneytoken trap deployed to container with tar", "container", containerName, "filePath", filePath)
+		}
+		results[filePath] = err
+	}
+	return results
+}
+
+// removeDecoyWithTar removes a decoy from a container of a pod with tar, for containers without a shell (and rm).
+// The decoy is archived to /dev/null with --remove-files, which removes it afterwards (GNU tar).
+func (r *FilesystemHoneytokenReconciler) removeDecoyWithTar(ctx context.Context, trap v1alpha1.TrapAnnotation, pod corev1.Pod, containerName string) error {
+	log := k8slog.FromContext(ctx)
+
+	archivePath := strings.TrimPrefix(trap.FilesystemHoneytoken.FilePath, "/")
+	removeCommand := []string{"tar", "-c", "-f", "/dev/null", "--remove-files", "-C", "/", tarFingerprintFlag(), archivePath}
+	_, removeErr := r.executeCommandInContainer(ctx, pod, containerName, removeCommand)
+
+	// Check if the file was removed, since tar also fails if the file is already gone
+	checkCommand := []string{"tar", "-c", "-f", "/dev/null", "-C", "/", tarFingerprintFlag(), archivePath}
+	output, err := r.executeCommandInContainer(ctx, pod, containerName, checkCommand)
+	if err == nil {
+		log.Error(removeErr, "the file was not removed", "container", containerName)
+		return errors.Join(errors.New("the file was not removed"), removeErr)
+	}
+
+	var exitErr utilexec.ExitError
+	if !errors.As(err, &exitErr) {
+		log.Error(err, "unable to check if the file was removed", "container", containerName, "stderr", output)
+		return err
+	}
+
+	log.Info("FilesystemHoneytoken trap removed from container with tar", "container", containerName)
+	return nil
+}
+
+// tarFingerprintFlag returns an --exclude flag that excludes nothing, but marks the tar commands of Koney
+// with the fingerprint, so that we won't alert on them later (like the echo commands of the placement script).
+func tarFingerprintFlag() string {
+	return "--exclude=" + alerts.EncodeFingerprintInEcho(utils.GetKoneyFingerprint())
+}
+
+// getArchivePaths returns the file paths of the decoys of the given traps, relative to the root directory.
+func getArchivePaths(traps []v1alpha1.Trap) []string {
+	paths := make([]string, 0, len(traps))
+	for _, trap := range traps {
+		paths = append(paths, strings.TrimPrefix(trap.FilesystemHoneytoken.FilePath, "/"))
+	}
+	return paths
+}
+
+// buildDecoyArchive builds a tar archive with the decoys of the given traps, relative to the root directory.
+// Read-only decoys get mode 0444, all others 0644.
+func buildDecoyArchive(traps []v1alpha1.Trap, now time.Time) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	paths := getArchivePaths(traps)
+	for i, trap := range traps {
+		content := []byte(trap.FilesystemHoneytoken.FileContent)
+		mode := int64(0644)
+		if trap.FilesystemHoneytoken.IsReadOnly() {
+			mode = 0444
+		}
+
+		header := &tar.Header{Name: paths[i], Mode: mode, Size: int64(len(content)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// archivedFile is a regular file read from a tar archive.
+type archivedFile struct {
+	content string
+	mode    int64
+}
+
+// readDecoyArchive reads the regular files of a tar archive, by their absolute path.
+func readDecoyArchive(archive []byte) (map[string]archivedFile, error) {
+	files := map[string]archivedFile{}
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to read the decoys back with tar: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the decoys back with tar: %w", err)
+		}
+		files["/"+strings.TrimPrefix(header.Name, "/")] = archivedFile{content: string(content), mode: header.Mode}
+	}
+}
+
+// checkArchivedDecoy returns an error if the decoy of the trap was not placed as expected, judging by the files read back with tar.
+func checkArchivedDecoy(trap v1alpha1.Trap, files map[string]archivedFile) error {
+	file, ok := files[trap.FilesystemHoneytoken.FilePath]
+	if !ok {
+		return errors.New("the file was not found after extracting it")
+	}
+
+	var err error
+	if file.content != trap.FilesystemHoneytoken.FileContent {
+		err = errors.New("the content of the file is not the expected content")
+	}
+	if trap.FilesystemHoneytoken.IsReadOnly() && file.mode&0222 != 0 {
+		err = errors.Join(err, fmt.Errorf("the file is not read-only (mode %o)", file.mode&0777))
+	}
+	return err
+}
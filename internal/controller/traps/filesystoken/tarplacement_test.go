@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	trapsapi "github.com/dynatrace-oss/koney/internal/controller/traps/api"
+)
+
+var _ = Describe("containerExec placement with tar", func() {
+	var (
+		dir   string
+		traps []v1alpha1.Trap
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		traps = []v1alpha1.Trap{
+			{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filepath.Join(dir, "secrets", "token"), FileContent: "some \"token\"\n$(id)", ReadOnly: &[]bool{true}[0]}},
+			{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: filepath.Join(dir, "empty")}},
+		}
+	})
+
+	runTar := func(stdin []byte, args ...string) []byte {
+		cmd := exec.Command("tar", args...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		output, err := cmd.Output()
+		Expect(err).NotTo(HaveOccurred())
+		return output
+	}
+
+	It("should place all decoys by extracting one archive and verify them by archiving them again", func() {
+		archive, err := buildDecoyArchive(traps, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		runTar(archive, "-x", "-p", "-f", "-", "-C", "/", tarFingerprintFlag())
+
+		content, err := os.ReadFile(traps[0].FilesystemHoneytoken.FilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(traps[0].FilesystemHoneytoken.FileContent))
+		info, err := os.Stat(traps[0].FilesystemHoneytoken.FilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0444)))
+
+		output := runTar(nil, append([]string{"-c", "-f", "-", "-C", "/", tarFingerprintFlag()}, getArchivePaths(traps)...)...)
+		files, err := readDecoyArchive(output)
+		Expect(err).NotTo(HaveOccurred())
+		for _, trap := range traps {
+			Expect(checkArchivedDecoy(trap, files)).To(Succeed())
+		}
+	})
+
+	It("should report decoys that are missing, changed, or writable", func() {
+		files := map[string]archivedFile{traps[0].FilesystemHoneytoken.FilePath: {content: "other", mode: 0644}}
+		Expect(checkArchivedDecoy(traps[0], files)).To(MatchError(And(ContainSubstring("not the expected content"), ContainSubstring("not read-only"))))
+		Expect(checkArchivedDecoy(traps[1], files)).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should tell missing executables apart from other exec errors", func() {
+		Expect(isExecutableNotFound(utilexec.CodeExitError{Err: errors.New("command terminated with exit code 127"), Code: 127}, "")).To(BeTrue())
+		Expect(isExecutableNotFound(errors.New("command terminated with exit code 128"),
+			`exec: "sh": executable file not found in $PATH: unknown`)).To(BeTrue())
+		Expect(isExecutableNotFound(utilexec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1}, "")).To(BeFalse())
+		Expect(isExecutableNotFound(errors.New("pods \"web\" is forbidden"), "")).To(BeFalse())
+	})
+
+	It("should classify exec errors that the API server denied", func() {
+		forbidden := apierrors.NewForbidden(corev1.Resource("pods/exec"), "web", errors.New("RBAC denied"))
+		Expect(classifyExecError(forbidden)).To(MatchError(trapsapi.ErrExecForbidden))
+		Expect(classifyExecError(forbidden)).To(MatchError(forbidden))
+
+		exitErr := utilexec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1}
+		Expect(classifyExecError(exitErr)).To(Equal(exitErr))
+		Expect(classifyExecError(nil)).To(Succeed())
+	})
+
+	It("should identify the image of a container by its digest if the pod reports it", func() {
+		pod := corev1.Pod{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:1.0"}, {Name: "worker", Image: "worker:1.0"}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", ImageID: "app@sha256:abc"}}},
+		}
+		Expect(getContainerImage(pod, "app")).To(Equal("app@sha256:abc"))
+		Expect(getContainerImage(pod, "worker")).To(Equal("worker:1.0"))
+	})
+})
@@ -18,44 +18,96 @@ package filesystoken
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
 
 	kivev1 "github.com/San7o/kivebpf/api/v1"
 	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
 	slimv1 "github.com/cilium/tetragon/pkg/k8s/slim/k8s/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
 	"github.com/dynatrace-oss/koney/internal/controller/constants"
 	"github.com/dynatrace-oss/koney/internal/controller/matching"
 	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
 )
 
-// GenerateTetragonTracingPolicyName generates the name of a Tetragon tracing policy based on the trap.
-func GenerateTetragonTracingPolicyName(trap v1alpha1.Trap) (string, error) {
-	trapJSON, err := json.Marshal(trap)
+// CaptorNamePrefix is the prefix of the names of the Tetragon tracing policies and Kive policies that Koney creates.
+const CaptorNamePrefix = "koney-tracing-policy-"
+
+// GenerateCaptorName generates the name of the Tetragon tracing policy or Kive policy of a trap.
+// The name is based on the DeceptionPolicy and the position of the trap in it, but not on the trap spec, so that
+// the captor is updated in place when the trap changes (the trap hash annotation tells which spec it was generated from).
+// Identical traps in the same DeceptionPolicy share the captor of the first one.
+func GenerateCaptorName(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) (string, error) {
+	trapHash, err := TrapHash(trap)
 	if err != nil {
 		return "", err
 	}
 
-	return "koney-tracing-policy-" + utils.Hash(string(trapJSON)), nil
+	for index, specTrap := range deceptionPolicy.Spec.Traps {
+		if specTrapHash, err := TrapHash(specTrap); err == nil && specTrapHash == trapHash {
+			return CaptorNamePrefix + utils.Hash(deceptionPolicy.Name+"/"+strconv.Itoa(index)), nil
+		}
+	}
+
+	return "", fmt.Errorf("trap %s is not part of DeceptionPolicy %s", trapHash, deceptionPolicy.Name)
 }
 
-// Similar to GenerateTetragonTracingPolicyName but used for Kive
-func GenerateKivePolicyName(trap v1alpha1.Trap) (string, error) {
-	// What is irrelevant for the policy should not alter the name, so
-	// that there are no duplicate policies with different names.
-	trap.DecoyDeployment.Strategy = ""
-	trap.FilesystemHoneytoken.FileContent = ""
-	trap.FilesystemHoneytoken.ReadOnly = false
-	return GenerateTetragonTracingPolicyName(trap)
+// GenerateCaptorPolicyName returns the name of the TracingPolicy, KivePolicy, or Falco rule that monitors the trap,
+// depending on its captor deployment strategy. The name is empty if no captor policy is deployed (e.g., for watcher sidecars).
+func GenerateCaptorPolicyName(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) (string, error) {
+	for _, strategy := range trap.CaptorDeployment.AllStrategies() {
+		switch strategy {
+		case "tetragon", "kive", "falco":
+			// All captor policies of a trap share the same name
+			return GenerateCaptorName(deceptionPolicy, trap)
+		}
+	}
+
+	return "", nil
+}
+
+// ExpandFilePaths returns a copy of the traps, where each filesystem honeytoken trap with multiple
+// file paths is replaced by one trap per file path. Decoys are deployed (and tracked in annotations)
+// per file path, while captors are deployed per trap, i.e., for all file paths at once.
+func ExpandFilePaths(traps []v1alpha1.Trap) []v1alpha1.Trap {
+	expandedTraps := make([]v1alpha1.Trap, 0, len(traps))
+	for _, trap := range traps {
+		if trap.TrapType() != v1alpha1.FilesystemHoneytokenTrap || len(trap.FilesystemHoneytoken.FilePaths) == 0 {
+			expandedTraps = append(expandedTraps, trap)
+			continue
+		}
+
+		for _, filePath := range trap.FilesystemHoneytoken.AllFilePaths() {
+			expandedTrap := trap
+			expandedTrap.FilesystemHoneytoken.FilePath = filePath
+			expandedTrap.FilesystemHoneytoken.FilePaths = nil
+			expandedTraps = append(expandedTraps, expandedTrap)
+		}
+	}
+
+	return expandedTraps
 }
 
-// createSecret creates a secret in the same namespace as the resource with the given name and data.
-// The function does nothing if the secret already exists.
-func createSecret(c client.Client, ctx context.Context, namespace, secretName string, data map[string][]byte) error {
+// createSecret creates a secret in the same namespace as the resource with the given name and data, owned by the DeceptionPolicy
+// and annotated with the hash of the trap it was generated from. If the secret already exists, the DeceptionPolicy is added to its owners,
+// since secrets are shared between traps with the same file path and content. If it has other data (e.g., because it was tampered with),
+// its data is restored. Secrets are never updated to another content, because their names are based on the content.
+func createSecret(c client.Client, ctx context.Context, owner *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, namespace, secretName string, data map[string][]byte) error {
 	// Check if the secret already exists
 	secret := corev1.Secret{}
 	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, &secret); err != nil {
@@ -68,20 +120,314 @@ func createSecret(c client.Client, ctx context.Context, namespace, secretName st
 	if secret.Name == "" {
 		secret = corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      secretName,
-				Namespace: namespace,
+				Name:        secretName,
+				Namespace:   namespace,
+				Labels:      map[string]string{constants.LabelKeyDecoySecret: "true"},
+				Annotations: buildSecretAnnotations(trap),
 			},
 			Data: data,
 		}
+		addSecretOwner(&secret, owner)
 
 		return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 			return c.Create(ctx, &secret)
 		})
 	}
 
+	dirty := addSecretOwner(&secret, owner)
+	dirty = addSecretLabel(&secret) || dirty
+	if !reflect.DeepEqual(secret.Data, data) {
+		secret.Data = data
+		dirty = true
+	}
+	if dirty {
+		return c.Update(ctx, &secret)
+	}
+
 	return nil
 }
 
+// ensureImmutableSecret creates an immutable secret in the given namespace with the given name and data, owned by the DeceptionPolicy
+// and annotated with the hash of the trap it was generated from. If the secret already exists, but is mutable or has other data
+// (e.g., because it was tampered with), it is deleted and created again (with the same owners), since immutable secrets cannot be updated.
+func ensureImmutableSecret(c client.Client, ctx context.Context, owner *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, namespace, secretName string, data map[string][]byte) error {
+	var ownerReferences []metav1.OwnerReference
+
+	secret := corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, &secret); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	} else if secret.Immutable != nil && *secret.Immutable && reflect.DeepEqual(secret.Data, data) {
+		// The secret is intact, but the metadata of immutable secrets can still be updated
+		if dirty := addSecretOwner(&secret, owner); addSecretLabel(&secret) || dirty {
+			return c.Update(ctx, &secret)
+		}
+		return nil
+	} else if err := c.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+		return err
+	} else {
+		ownerReferences = secret.OwnerReferences
+	}
+
+	secret = corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secretName,
+			Namespace:       namespace,
+			Labels:          map[string]string{constants.LabelKeyDecoySecret: "true"},
+			Annotations:     buildSecretAnnotations(trap),
+			OwnerReferences: ownerReferences,
+		},
+		Data:      data,
+		Immutable: &[]bool{true}[0],
+	}
+	addSecretOwner(&secret, owner)
+
+	return c.Create(ctx, &secret)
+}
+
+// buildSecretAnnotations returns the annotations of the secret of a trap, which store the hash of the trap spec it was generated from.
+func buildSecretAnnotations(trap v1alpha1.Trap) map[string]string {
+	trapHash, err := TrapHash(trap)
+	if err != nil {
+		return nil
+	}
+
+	return map[string]string{constants.AnnotationKeyTrapHash: trapHash}
+}
+
+// addSecretOwner adds a DeceptionPolicy to the owners of a secret, unless it is already an owner, so that Kubernetes
+// deletes the secret once all DeceptionPolicies that own it are deleted. Secrets are shared between traps (and DeceptionPolicies),
+// so no owner is the controller. The function returns true if the owners were modified as a result of the operation.
+func addSecretOwner(secret *corev1.Secret, owner *v1alpha1.DeceptionPolicy) bool {
+	if owner == nil || slices.ContainsFunc(secret.OwnerReferences, func(ownerReference metav1.OwnerReference) bool {
+		return isOwnerReferenceTo(ownerReference, owner)
+	}) {
+		return false
+	}
+
+	secret.OwnerReferences = append(secret.OwnerReferences, metav1.OwnerReference{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "DeceptionPolicy",
+		Name:       owner.Name,
+		UID:        owner.UID,
+	})
+	return true
+}
+
+// addSecretLabel labels a secret as a secret of decoys, unless it is labeled already (e.g., secrets created by older versions of Koney are not).
+// The function returns true if the labels were modified as a result of the operation.
+func addSecretLabel(secret *corev1.Secret) bool {
+	if secret.Labels[constants.LabelKeyDecoySecret] == "true" {
+		return false
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[constants.LabelKeyDecoySecret] = "true"
+	return true
+}
+
+// isOwnerReferenceTo returns true if an owner reference points to the DeceptionPolicy.
+func isOwnerReferenceTo(ownerReference metav1.OwnerReference, owner *v1alpha1.DeceptionPolicy) bool {
+	return ownerReference.Kind == "DeceptionPolicy" && ownerReference.Name == owner.Name && ownerReference.UID == owner.UID
+}
+
+// buildProjectedVolume builds the volume and volume mount that mount the honeytoken of a trap from a projected volume
+// with an immutable secret as its only source, together with the data of that secret. The secret itself is not created.
+// The volume mount is always read-only, and the file mode of the honeytoken follows the readOnly flag of the trap.
+func buildProjectedVolume(trap v1alpha1.Trap) (corev1.Volume, corev1.VolumeMount, map[string][]byte, error) {
+	volume, volumeMount, data, err := buildSecretVolume(trap)
+	if err != nil {
+		return corev1.Volume{}, corev1.VolumeMount{}, nil, err
+	}
+
+	mode := int32(0644)
+	if trap.FilesystemHoneytoken.IsReadOnly() {
+		mode = 0444
+	}
+
+	volume.VolumeSource = corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{
+			Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: volume.Secret.SecretName}}},
+			},
+			DefaultMode: &mode,
+		},
+	}
+	volumeMount.ReadOnly = true
+
+	return volume, volumeMount, data, nil
+}
+
+// buildSecretVolume builds the volume and volume mount that mount the honeytoken of a trap from a secret,
+// together with the data of that secret. The secret itself is not created.
+func buildSecretVolume(trap v1alpha1.Trap) (corev1.Volume, corev1.VolumeMount, map[string][]byte, error) {
+	_, fileName := filepath.Split(trap.FilesystemHoneytoken.FilePath)
+	if fileName == "" {
+		return corev1.Volume{}, corev1.VolumeMount{}, nil, errors.New("file path must point to a file")
+	}
+
+	data := map[string][]byte{
+		fileName: []byte(trap.FilesystemHoneytoken.FileContent),
+	}
+
+	// The name of the volume is generated based on the trap's file path
+	// For the volume name, we don't need to also consider the content of the file
+	// since there cannot be two volumes mounted to the same path with different content
+	volumeName := generateVolumeName(trap.FilesystemHoneytoken.FilePath)
+
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				// The name of the secret is generated based on the trap's file path and content
+				SecretName: generateSecretName(trap),
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: trap.FilesystemHoneytoken.FilePath,
+		ReadOnly:  trap.FilesystemHoneytoken.IsReadOnly(),
+		SubPath:   fileName,
+	}
+
+	return volume, volumeMount, data, nil
+}
+
+// createSecretVolume creates the secret with the honeytoken of a trap in the given namespace (unless it already exists)
+// and returns the volume and volume mount that mount the honeytoken from that secret.
+func createSecretVolume(c client.Client, ctx context.Context, owner *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap, namespace string) (corev1.Volume, corev1.VolumeMount, error) {
+	volume, volumeMount, data, err := buildSecretVolume(trap)
+	if err != nil {
+		return corev1.Volume{}, corev1.VolumeMount{}, err
+	}
+
+	if err := createSecret(c, ctx, owner, trap, namespace, volume.Secret.SecretName, data); err != nil {
+		return corev1.Volume{}, corev1.VolumeMount{}, err
+	}
+
+	return volume, volumeMount, nil
+}
+
+// buildInitContainerVolume builds the init container that writes the honeytoken of a trap into a shared emptyDir volume,
+// together with that volume and the volume mount that mounts the honeytoken from it into other containers.
+// The content of the honeytoken is passed in an environment variable, so that it does not need to be escaped for the shell.
+func buildInitContainerVolume(trap v1alpha1.Trap) (corev1.Container, corev1.Volume, corev1.VolumeMount, error) {
+	_, fileName := filepath.Split(trap.FilesystemHoneytoken.FilePath)
+	if fileName == "" {
+		return corev1.Container{}, corev1.Volume{}, corev1.VolumeMount{}, errors.New("file path must point to a file")
+	}
+
+	image := constants.DefaultInitContainerImage
+	var pullPolicy corev1.PullPolicy
+	if initContainer := trap.DecoyDeployment.InitContainer; initContainer != nil {
+		if initContainer.Image != "" {
+			image = initContainer.Image
+		}
+		pullPolicy = initContainer.PullPolicy
+	}
+
+	script := `printf '%s' "$KONEY_FILE_CONTENT" > "$KONEY_FILE_PATH"`
+	if trap.FilesystemHoneytoken.IsReadOnly() {
+		script += ` && chmod 0444 "$KONEY_FILE_PATH"`
+	}
+
+	// The volume and the init container are named after the file path, like the volumes of the other strategies
+	volumeName := generateVolumeName(trap.FilesystemHoneytoken.FilePath)
+
+	initContainer := corev1.Container{
+		Name:            generateInitContainerName(trap.FilesystemHoneytoken.FilePath),
+		Image:           image,
+		ImagePullPolicy: pullPolicy,
+		Command:         []string{"sh", "-c", script},
+		Env: []corev1.EnvVar{
+			{Name: "KONEY_FILE_CONTENT", Value: trap.FilesystemHoneytoken.FileContent},
+			{Name: "KONEY_FILE_PATH", Value: filepath.Join(constants.InitContainerDecoyDir, fileName)},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: volumeName, MountPath: constants.InitContainerDecoyDir}},
+		// emptyDir volumes are world-writable, so the init container does not need any privileges
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                &[]int64{constants.InitContainerUser}[0],
+			RunAsNonRoot:             &[]bool{true}[0],
+			AllowPrivilegeEscalation: &[]bool{false}[0],
+			ReadOnlyRootFilesystem:   &[]bool{true}[0],
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: trap.FilesystemHoneytoken.FilePath,
+		ReadOnly:  trap.FilesystemHoneytoken.IsReadOnly(),
+		SubPath:   fileName,
+	}
+
+	return initContainer, volume, volumeMount, nil
+}
+
+// buildEphemeralContainer builds an ephemeral container that runs a script in the process namespace of the target container
+// of a pod, so that the script can access the filesystem of the target container through /proc/1/root, without exec'ing into it.
+// The ephemeral container runs as the same user and group as the target container (if they are set in the pod spec),
+// since processes can only access the filesystem of other processes of the same user.
+func buildEphemeralContainer(trap v1alpha1.Trap, pod corev1.Pod, targetContainerName, name, script string) corev1.EphemeralContainer {
+	image := constants.DefaultEphemeralContainerImage
+	var pullPolicy corev1.PullPolicy
+	if ephemeralContainer := trap.DecoyDeployment.EphemeralContainer; ephemeralContainer != nil {
+		if ephemeralContainer.Image != "" {
+			image = ephemeralContainer.Image
+		}
+		pullPolicy = ephemeralContainer.PullPolicy
+	}
+
+	securityContext := &corev1.SecurityContext{AllowPrivilegeEscalation: &[]bool{false}[0]}
+	if podSecurityContext := pod.Spec.SecurityContext; podSecurityContext != nil {
+		securityContext.RunAsUser = podSecurityContext.RunAsUser
+		securityContext.RunAsGroup = podSecurityContext.RunAsGroup
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == targetContainerName && container.SecurityContext != nil {
+			if container.SecurityContext.RunAsUser != nil {
+				securityContext.RunAsUser = container.SecurityContext.RunAsUser
+			}
+			if container.SecurityContext.RunAsGroup != nil {
+				securityContext.RunAsGroup = container.SecurityContext.RunAsGroup
+			}
+		}
+	}
+
+	// mark the script with a fingerprint so that we won't alert on it later
+	script += " # " + alerts.EncodeFingerprintInEcho(utils.GetKoneyFingerprint())
+
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:            name,
+			Image:           image,
+			ImagePullPolicy: pullPolicy,
+			Command:         []string{"sh", "-c", script},
+			Env: []corev1.EnvVar{
+				{Name: "KONEY_FILE_CONTENT", Value: trap.FilesystemHoneytoken.FileContent},
+				{Name: "KONEY_FILE_PATH", Value: "/proc/1/root" + trap.FilesystemHoneytoken.FilePath},
+			},
+			SecurityContext: securityContext,
+		},
+		TargetContainerName: targetContainerName,
+	}
+}
+
+// Prefixes of the names of the secrets, volumes, and init containers that Koney creates for decoys.
+const (
+	secretNamePrefix        = "koney-secret-"
+	volumeNamePrefix        = "koney-volume-"
+	initContainerNamePrefix = "koney-init-"
+)
+
 // generateSecretName generates the name of a secret based on different
 // fields of a trap, depending on the trap type.
 func generateSecretName(trap v1alpha1.Trap) string {
@@ -98,12 +444,45 @@ func generateSecretName(trap v1alpha1.Trap) string {
 		suffix = ""
 	}
 
-	return "koney-secret-" + suffix
+	return secretNamePrefix + suffix
+}
+
+// imageVolumeSubPathMinVersion is the first Kubernetes version that can mount image volumes with a subPath.
+var imageVolumeSubPathMinVersion = version.MajorMinor(1, 33)
+
+// checkImageVolumeSubPathSupport returns an error if the Kubernetes version (e.g., "v1.32.4") cannot mount image volumes with a subPath.
+func checkImageVolumeSubPathSupport(gitVersion string) error {
+	serverVersion, err := version.ParseGeneric(gitVersion)
+	if err != nil {
+		return fmt.Errorf("version %q of the cluster cannot be parsed: %w", gitVersion, err)
+	}
+	if serverVersion.LessThan(imageVolumeSubPathMinVersion) {
+		return fmt.Errorf("the imageVolume strategy requires Kubernetes %s or newer to mount the decoy file with a subPath, but the cluster runs %s",
+			imageVolumeSubPathMinVersion, gitVersion)
+	}
+	return nil
 }
 
 // generateVolumeName generates the name of a volume based on the filePath.
 func generateVolumeName(filePath string) string {
-	return "koney-volume-" + utils.Hash(filePath)
+	return volumeNamePrefix + utils.Hash(filePath)
+}
+
+// generateInitContainerName generates the name of the init container that writes the honeytoken at the filePath.
+func generateInitContainerName(filePath string) string {
+	return initContainerNamePrefix + utils.Hash(filePath)
+}
+
+// generateEphemeralContainerName generates a unique name for an ephemeral container that performs an action (e.g., "write")
+// for the honeytoken at the filePath in a container. Ephemeral containers cannot be removed from pods, so every action needs a new name.
+func generateEphemeralContainerName(action, filePath, containerName string, now time.Time) string {
+	return ephemeralContainerNamePrefix(action, filePath, containerName) + strconv.FormatInt(now.UnixNano(), 36)
+}
+
+// ephemeralContainerNamePrefix returns the common prefix of the names of all ephemeral containers
+// that perform an action for the honeytoken at the filePath in a container.
+func ephemeralContainerNamePrefix(action, filePath, containerName string) string {
+	return "koney-" + action + "-" + utils.Hash(filePath + ":" + containerName)[:8] + "-"
 }
 
 // generateTetragonTracingPolicy generates a Tetragon tracing policy for a filesystem honeytoken trap.
@@ -169,25 +548,7 @@ func generateTetragonTracingPolicy(deceptionPolicy *v1alpha1.DeceptionPolicy,
 						Type:  "int", // The int return type is used to trace the return value of the function
 					},
 					ReturnArgAction: "Post", // The Post action is used to trace the return value of the function
-					Selectors: []ciliumiov1alpha1.KProbeSelector{
-						{
-							MatchArgs: []ciliumiov1alpha1.ArgSelector{
-								{
-									Index:    0,
-									Operator: "Equal", // The Equal operator is used to match the file path
-									Values: []string{
-										trap.FilesystemHoneytoken.FilePath,
-									},
-								},
-							},
-							MatchActions: []ciliumiov1alpha1.ActionSelector{
-								{
-									Action: "GetUrl",
-									ArgUrl: buildTetragonWebhookUrl(),
-								},
-							},
-						},
-					},
+					Selectors:       buildFilePathSelectors(trap),
 				},
 				{
 					Call:    "security_mmap_file", // The security_mmap_file function is used to trace memory-mapped files
@@ -204,113 +565,343 @@ func generateTetragonTracingPolicy(deceptionPolicy *v1alpha1.DeceptionPolicy,
 						Type:  "int",
 					},
 					ReturnArgAction: "Post",
-					Selectors: []ciliumiov1alpha1.KProbeSelector{
-						{
-							MatchArgs: []ciliumiov1alpha1.ArgSelector{
-								{
-									Index:    0,
-									Operator: "Equal",
-									Values: []string{
-										trap.FilesystemHoneytoken.FilePath,
-									},
-								},
-							},
-							MatchActions: []ciliumiov1alpha1.ActionSelector{
-								{
-									Action: "GetUrl",
-									ArgUrl: buildTetragonWebhookUrl(),
-								},
-							},
-						},
-					},
+					Selectors:       buildFilePathSelectors(trap),
 				},
 			},
 		},
 	}
 
+	// Block or kill writers of the honeytoken. The mask (second argument) tells which access is requested.
+	// Enforcement selectors come first, because Tetragon only applies the actions of the first matching selector.
+	if trap.FilesystemHoneytoken.IsEnforcing() {
+		fileProbe := &tracingPolicy.Spec.KProbes[0]
+		fileProbe.Args = append(fileProbe.Args, ciliumiov1alpha1.KProbeArg{
+			Index: 1,
+			Type:  "int",
+		})
+		fileProbe.Selectors = append(buildEnforcementSelectors(trap), fileProbe.Selectors...)
+	}
+
 	// Add the labels from the trap's MatchResources to the PodSelector
 	for _, resourceFilter := range trap.MatchResources.Any {
+		if resourceFilter.Selector == nil {
+			continue
+		}
 		for key, value := range resourceFilter.Selector.MatchLabels {
 			tracingPolicy.Spec.PodSelector.MatchLabels[key] = value
 		}
 	}
 
-	// Determine how to populate the ContainerSelector:
-	//
-	//  1. If ANY resourceFilter selects all containers (empty, "regex:.*", "glob:*") → leave
-	//     ContainerSelector empty so Tetragon matches every container, no client filtering needed.
-	//
-	//  2. Else if ANY resourceFilter has a wildcard pattern that Tetragon cannot evaluate
-	//     ("regex:<pattern>" or "glob:<pattern>") → also leave ContainerSelector empty but store
-	//     ALL container selectors in an annotation so the alert forwarder can filter client-side.
-	//
-	//  3. Otherwise (all exact container names) → populate ContainerSelector via MatchExpressions
-	//     so Tetragon filters server-side; no annotation needed.
+	addContainerSelectorToTracingPolicy(tracingPolicy, trap)
 
-	hasSelectAll := false
-	for _, resourceFilter := range trap.MatchResources.Any {
-		if matching.ContainerSelectorSelectsAll(resourceFilter.ContainerSelector) {
-			hasSelectAll = true
-			break
+	// Store the alerting configuration so that the alert forwarder can attach it to alerts
+	alertingAnnotations := buildAlertingMetadata(trap, constants.AnnotationKeyAlertSeverity, constants.AnnotationKeyAlertTags)
+	if len(alertingAnnotations) > 0 {
+		if tracingPolicy.Annotations == nil {
+			tracingPolicy.Annotations = make(map[string]string)
+		}
+		for key, value := range alertingAnnotations {
+			tracingPolicy.Annotations[key] = value
 		}
 	}
 
-	if hasSelectAll {
-		// Case 1: leave ContainerSelector empty (TracingPolicy matches all containers).
-		tracingPolicy.Spec.ContainerSelector.MatchExpressions = nil
-	} else {
-		needsClientFiltering := false
-		for _, resourceFilter := range trap.MatchResources.Any {
-			if matching.ContainerSelectorNeedsClientFiltering(resourceFilter.ContainerSelector) {
-				needsClientFiltering = true
-				break
-			}
+	// Store the response actions so that the alert forwarder knows when to request a response
+	for key, value := range buildResponseMetadata(trap, constants.AnnotationKeyResponseActions, constants.AnnotationKeyQuarantineTTL) {
+		if tracingPolicy.Annotations == nil {
+			tracingPolicy.Annotations = make(map[string]string)
 		}
+		tracingPolicy.Annotations[key] = value
+	}
 
-		if needsClientFiltering {
-			// Case 2: leave ContainerSelector empty and annotate for client-side filtering.
-			tracingPolicy.Spec.ContainerSelector.MatchExpressions = nil
+	// Store where the TracingPolicy comes from, for debugging and to enrich alerts
+	if tracingPolicy.Annotations == nil {
+		tracingPolicy.Annotations = make(map[string]string)
+	}
+	for key, value := range buildTrapMetadata(deceptionPolicy, trap, constants.AnnotationKeyTrapHash,
+		constants.AnnotationKeyDeceptionPolicyGeneration, constants.AnnotationKeyTrapDescription) {
+		tracingPolicy.Annotations[key] = value
+	}
+	for key, value := range buildTrapTypeMetadata(trap, constants.AnnotationKeyTrapType, constants.AnnotationKeyFilePath) {
+		tracingPolicy.Annotations[key] = value
+	}
+	if hash := callbackHash(); hash != "" {
+		tracingPolicy.Annotations[constants.AnnotationKeyCallbackHash] = hash
+	}
 
-			allSelectors := make([]string, 0, len(trap.MatchResources.Any))
-			for _, resourceFilter := range trap.MatchResources.Any {
-				allSelectors = append(allSelectors, resourceFilter.ContainerSelector)
-			}
-			if selectorsJSON, err := json.Marshal(allSelectors); err == nil {
-				if tracingPolicy.Annotations == nil {
-					tracingPolicy.Annotations = make(map[string]string)
-				}
-				tracingPolicy.Annotations[constants.AnnotationKeyContainerSelectors] = string(selectorsJSON)
-			}
-		} else {
-			// Case 3: populate ContainerSelector with exact container names for server-side filtering.
-			for _, resourceFilter := range trap.MatchResources.Any {
-				if len(tracingPolicy.Spec.ContainerSelector.MatchExpressions) == 0 {
-					tracingPolicy.Spec.ContainerSelector.MatchExpressions = []slimv1.LabelSelectorRequirement{
-						{
-							Key:      "name",
-							Operator: slimv1.LabelSelectorOpIn,
-							Values:   []string{resourceFilter.ContainerSelector},
-						},
-					}
-				} else if !utils.Contains(tracingPolicy.Spec.ContainerSelector.MatchExpressions[0].Values, resourceFilter.ContainerSelector) {
-					tracingPolicy.Spec.ContainerSelector.MatchExpressions[0].Values = append(
-						tracingPolicy.Spec.ContainerSelector.MatchExpressions[0].Values,
-						resourceFilter.ContainerSelector,
-					)
-				}
-			}
-		}
+	// Tag the TracingPolicy with the MITRE ATT&CK techniques and Engage activities of the trap
+	for key, value := range buildFrameworkLabels(trap) {
+		tracingPolicy.Labels[key] = value
+	}
+	for key, value := range buildFrameworkMetadata(trap, constants.AnnotationKeyAttckTechniques, constants.AnnotationKeyEngageActivities) {
+		tracingPolicy.Annotations[key] = value
+	}
+
+	// Label the TracingPolicy with the severity and confidence of the trap, so that the alert forwarder attaches them to alerts
+	for key, value := range buildClassificationMetadata(trap, constants.LabelKeySeverity, constants.LabelKeyConfidence) {
+		tracingPolicy.Labels[key] = value
 	}
 
 	return tracingPolicy
 }
 
+// buildFilePathSelectors returns the kprobe selectors that match accesses to the monitored paths of a trap.
+// Selectors are OR-ed by Tetragon, so there is one selector per operator (Equal, Prefix, Postfix).
+func buildFilePathSelectors(trap v1alpha1.Trap) []ciliumiov1alpha1.KProbeSelector {
+	exact, prefixes, suffixes := trap.FilesystemHoneytoken.MonitoredPaths()
+
+	selectors := []ciliumiov1alpha1.KProbeSelector{}
+	for _, match := range []struct {
+		operator string
+		values   []string
+	}{
+		{"Equal", exact},
+		{"Prefix", prefixes},
+		{"Postfix", suffixes},
+	} {
+		if len(match.values) == 0 {
+			continue
+		}
+
+		selectors = append(selectors, ciliumiov1alpha1.KProbeSelector{
+			MatchArgs: []ciliumiov1alpha1.ArgSelector{
+				{
+					Index:    0,
+					Operator: match.operator,
+					Values:   match.values,
+				},
+			},
+			MatchActions: buildCallbackActions(trap),
+		})
+	}
+
+	return selectors
+}
+
+// buildCallbackActions returns the kprobe actions that notify the alert forwarder about an access to the trap.
+// Tetragon only runs the GetUrl action for events that it posts, so a rate-limited Post action in front of it
+// also limits the callbacks, e.g., when a process reads the honeytoken in a tight loop.
+func buildCallbackActions(trap v1alpha1.Trap) []ciliumiov1alpha1.ActionSelector {
+	actions := []ciliumiov1alpha1.ActionSelector{}
+	if rateLimit := trap.CaptorDeployment.RateLimit; rateLimit != nil {
+		actions = append(actions, ciliumiov1alpha1.ActionSelector{
+			Action:         "Post",
+			RateLimit:      strconv.FormatInt(int64(rateLimit.Period.Seconds()), 10),
+			RateLimitScope: rateLimit.Scope,
+		})
+	}
+
+	return append(actions, ciliumiov1alpha1.ActionSelector{
+		Action: "GetUrl",
+		ArgUrl: buildTetragonWebhookUrl(),
+	})
+}
+
+// buildEnforcementSelectors returns the kprobe selectors of `security_file_permission` that block (Override)
+// or kill (Sigkill) processes requesting write access (MAY_WRITE) to the honeytoken. Alerts are still sent.
+func buildEnforcementSelectors(trap v1alpha1.Trap) []ciliumiov1alpha1.KProbeSelector {
+	enforcement := ciliumiov1alpha1.ActionSelector{Action: trap.FilesystemHoneytoken.EnforcementAction}
+	if enforcement.Action == v1alpha1.EnforcementActionOverride {
+		enforcement.ArgError = -1 // EPERM
+	}
+
+	return []ciliumiov1alpha1.KProbeSelector{
+		{
+			MatchArgs: []ciliumiov1alpha1.ArgSelector{
+				{
+					Index:    0,
+					Operator: "Equal",
+					Values:   trap.FilesystemHoneytoken.AllFilePaths(),
+				},
+				{
+					Index:    1,
+					Operator: "Mask",
+					Values:   []string{"2"}, // MAY_WRITE
+				},
+			},
+			MatchActions: append([]ciliumiov1alpha1.ActionSelector{enforcement}, buildCallbackActions(trap)...),
+		},
+	}
+}
+
+// buildAlertingMetadata returns the alerting configuration of a trap as key-value pairs,
+// using the given keys for the severity and the (JSON-encoded) tags. Unset values are omitted.
+func buildAlertingMetadata(trap v1alpha1.Trap, severityKey, tagsKey string) map[string]string {
+	metadata := map[string]string{}
+	if trap.Alerting == nil {
+		return metadata
+	}
+
+	if trap.Alerting.Severity != "" {
+		metadata[severityKey] = trap.Alerting.Severity
+	}
+	if len(trap.Alerting.Tags) > 0 {
+		if tagsJSON, err := json.Marshal(trap.Alerting.Tags); err == nil {
+			metadata[tagsKey] = string(tagsJSON)
+		}
+	}
+
+	return metadata
+}
+
+// buildResponseMetadata returns the response actions of a trap (JSON-encoded) and how long it quarantines pods,
+// using the given keys. Nothing is returned if the trap has no response actions.
+func buildResponseMetadata(trap v1alpha1.Trap, responseActionsKey, quarantineTTLKey string) map[string]string {
+	metadata := map[string]string{}
+	responseActions := trap.AllResponseActions()
+	if len(responseActions) == 0 {
+		return metadata
+	}
+
+	if actionsJSON, err := json.Marshal(responseActions); err == nil {
+		metadata[responseActionsKey] = string(actionsJSON)
+	}
+	if trap.Quarantine != nil && trap.Quarantine.TTL != nil {
+		metadata[quarantineTTLKey] = trap.Quarantine.TTL.Duration.String()
+	}
+
+	return metadata
+}
+
+// buildFrameworkLabels returns one label per MITRE ATT&CK technique and MITRE Engage activity of a trap,
+// so that captors can be selected by the techniques they detect or the activities they implement.
+func buildFrameworkLabels(trap v1alpha1.Trap) map[string]string {
+	labels := map[string]string{}
+	for _, technique := range trap.AttckTechniques {
+		labels[constants.LabelKeyPrefixAttckTechnique+technique] = "true"
+	}
+	for _, activity := range trap.EngageActivities {
+		labels[constants.LabelKeyPrefixEngageActivity+activity] = "true"
+	}
+
+	return labels
+}
+
+// buildFrameworkMetadata returns the MITRE ATT&CK techniques and MITRE Engage activities of a trap (JSON-encoded),
+// using the given keys. Empty lists are omitted.
+func buildFrameworkMetadata(trap v1alpha1.Trap, techniquesKey, activitiesKey string) map[string]string {
+	metadata := map[string]string{}
+	if len(trap.AttckTechniques) > 0 {
+		if techniquesJSON, err := json.Marshal(trap.AttckTechniques); err == nil {
+			metadata[techniquesKey] = string(techniquesJSON)
+		}
+	}
+	if len(trap.EngageActivities) > 0 {
+		if activitiesJSON, err := json.Marshal(trap.EngageActivities); err == nil {
+			metadata[activitiesKey] = string(activitiesJSON)
+		}
+	}
+
+	return metadata
+}
+
+// buildClassificationMetadata returns the severity and the confidence of a trap,
+// using the given keys. Unset values are omitted.
+func buildClassificationMetadata(trap v1alpha1.Trap, severityKey, confidenceKey string) map[string]string {
+	metadata := map[string]string{}
+	if trap.Severity != "" {
+		metadata[severityKey] = trap.Severity
+	}
+	if trap.Confidence != "" {
+		metadata[confidenceKey] = trap.Confidence
+	}
+
+	return metadata
+}
+
+// TrapHash returns the hash of a trap spec, which captors store to correlate alerts with the trap.
+func TrapHash(trap v1alpha1.Trap) (string, error) {
+	trapJSON, err := json.Marshal(trap)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.Hash(string(trapJSON)), nil
+}
+
+// buildTrapMetadata returns the origin of a trap as key-value pairs, using the given keys
+// for the hash of the trap spec, the generation of the deception policy, and the trap description.
+// The description is omitted if it is not set.
+func buildTrapMetadata(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap,
+	hashKey, generationKey, descriptionKey string) map[string]string {
+	metadata := map[string]string{
+		generationKey: strconv.FormatInt(deceptionPolicy.Generation, 10),
+	}
+
+	if trapHash, err := TrapHash(trap); err == nil {
+		metadata[hashKey] = trapHash
+	}
+	if trap.Description != "" {
+		metadata[descriptionKey] = trap.Description
+	}
+
+	return metadata
+}
+
+// buildTrapTypeMetadata returns the trap type and the file path of a trap, using the given keys.
+// The alert forwarder uses them to attribute alerts to the exact trap instead of only to the policy.
+func buildTrapTypeMetadata(trap v1alpha1.Trap, trapTypeKey, filePathKey string) map[string]string {
+	metadata := map[string]string{
+		trapTypeKey: string(alerts.FilesystemHoneytokenTrap),
+	}
+	if trap.FilesystemHoneytoken.FilePath != "" {
+		metadata[filePathKey] = trap.FilesystemHoneytoken.FilePath
+	}
+
+	return metadata
+}
+
+// buildCaptorMetadata returns all metadata of a trap as key-value pairs, using the metadata keys of the alert forwarder.
+// It is used by captors that cannot store the metadata in labels or annotations, but pass it along with their alerts.
+func buildCaptorMetadata(deceptionPolicy *v1alpha1.DeceptionPolicy, trap v1alpha1.Trap) map[string]string {
+	metadata := map[string]string{
+		constants.MetadataKeyDeceptionPolicyName: deceptionPolicy.Name,
+	}
+	maps.Copy(metadata, buildAlertingMetadata(trap, constants.MetadataKeyAlertSeverity, constants.MetadataKeyAlertTags))
+	maps.Copy(metadata, buildTrapMetadata(deceptionPolicy, trap, constants.MetadataKeyTrapHash,
+		constants.MetadataKeyDeceptionPolicyGeneration, constants.MetadataKeyTrapDescription))
+	maps.Copy(metadata, buildTrapTypeMetadata(trap, constants.MetadataKeyTrapType, constants.MetadataKeyFilePath))
+	maps.Copy(metadata, buildResponseMetadata(trap, constants.MetadataKeyResponseActions, constants.MetadataKeyQuarantineTTL))
+	maps.Copy(metadata, buildFrameworkMetadata(trap, constants.MetadataKeyAttckTechniques, constants.MetadataKeyEngageActivities))
+	maps.Copy(metadata, buildClassificationMetadata(trap, constants.MetadataKeySeverity, constants.MetadataKeyConfidence))
+
+	return metadata
+}
+
 func buildTetragonWebhookUrl() string {
-	return "http://koney-alert-forwarder-webhook." + utils.GetKoneyNamespace() + ".svc:8000/handlers/tetragon"
+	return buildAlertForwarderUrl("tetragon")
 }
 
 func buildKiveWebhookUrl() string {
-	return "http://koney-alert-forwarder-webhook." + utils.GetKoneyNamespace() + ".svc:8000/handlers/kive"
+	return buildAlertForwarderUrl("kive")
+}
+
+// buildAlertForwarderUrl returns the URL of a handler of the alert forwarder, which captors call when traps are accessed.
+// The URL uses HTTPS and carries the token of the alert forwarder, if they are configured.
+func buildAlertForwarderUrl(handler string) string {
+	alertForwarderUrl := url.URL{
+		Scheme: "http",
+		Host:   "koney-alert-forwarder-webhook." + utils.GetKoneyNamespace() + ".svc:8000",
+		Path:   "/handlers/" + handler,
+	}
+	if utils.IsAlertForwarderTLSEnabled() {
+		alertForwarderUrl.Scheme = "https"
+	}
+	if token := utils.GetAlertForwarderToken(); token != "" {
+		alertForwarderUrl.RawQuery = url.Values{"token": {token}}.Encode()
+	}
+
+	return alertForwarderUrl.String()
+}
+
+// callbackHash returns the hash of the callback URLs of captors, so that captors are updated when the token or TLS
+// configuration of the alert forwarder changes, without storing the token in an annotation.
+// The hash is empty if the alert forwarder uses neither, so that existing captors are not updated needlessly.
+func callbackHash() string {
+	if !utils.IsAlertForwarderTLSEnabled() && utils.GetAlertForwarderToken() == "" {
+		return ""
+	}
+
+	return utils.Hash(buildAlertForwarderUrl(""))
 }
 
 // generateKivePolicy generates a Kive tracing policy for a filesystem honeytoken trap.
@@ -343,58 +934,273 @@ func generateKivePolicy(deceptionPolicy *v1alpha1.DeceptionPolicy,
 	}
 
 	kiveTrap := kivev1.KiveTrap{
-		Path:     trap.FilesystemHoneytoken.FilePath,
 		Callback: buildKiveWebhookUrl(),
 		Metadata: map[string]string{
 			constants.MetadataKeyDeceptionPolicyName: deceptionPolicy.Name,
 		},
 		MatchAny: []kivev1.KiveTrapMatch{},
 	}
+	for key, value := range buildAlertingMetadata(trap, constants.MetadataKeyAlertSeverity, constants.MetadataKeyAlertTags) {
+		kiveTrap.Metadata[key] = value
+	}
+	for key, value := range buildTrapMetadata(deceptionPolicy, trap, constants.MetadataKeyTrapHash,
+		constants.MetadataKeyDeceptionPolicyGeneration, constants.MetadataKeyTrapDescription) {
+		kiveTrap.Metadata[key] = value
+	}
+	for key, value := range buildTrapTypeMetadata(trap, constants.MetadataKeyTrapType, constants.MetadataKeyFilePath) {
+		kiveTrap.Metadata[key] = value
+	}
+	for key, value := range buildResponseMetadata(trap, constants.MetadataKeyResponseActions, constants.MetadataKeyQuarantineTTL) {
+		kiveTrap.Metadata[key] = value
+	}
+	for key, value := range buildFrameworkMetadata(trap, constants.MetadataKeyAttckTechniques, constants.MetadataKeyEngageActivities) {
+		kiveTrap.Metadata[key] = value
+	}
+	for key, value := range buildClassificationMetadata(trap, constants.MetadataKeySeverity, constants.MetadataKeyConfidence) {
+		kiveTrap.Metadata[key] = value
+	}
 	for _, resource := range trap.MatchResources.Any {
 
 		kiveTrapMatches := []kivev1.KiveTrapMatch{}
 
-		// If no namespaces are present, create a KiveTrapMatch anyway
-		// with the other fields
-		if len(resource.Namespaces) == 0 {
-			kiveTrapMatch := kivev1.KiveTrapMatch{
-				ContainerName: resource.ContainerSelector,
-				MatchLabels:   map[string]string{},
-			}
-
-			for _, resourceFilter := range trap.MatchResources.Any {
-				for key, value := range resourceFilter.Selector.MatchLabels {
-					kiveTrapMatch.MatchLabels[key] = value
-				}
-			}
-
-			kiveTrapMatches = append(kiveTrapMatches, kiveTrapMatch)
-
-		} else {
-
-			for _, namespace := range resource.Namespaces {
+		// Kive matches a single container name pattern, so we create one KiveTrapMatch per included pattern.
+		// Kive cannot exclude containers or select them by image, so these containers are monitored anyway.
+		containerNames := resource.ContainerIncludes()
+		if len(containerNames) == 0 {
+			containerNames = []string{""}
+		}
 
+		for _, containerName := range containerNames {
+			// If no namespaces are present, create a KiveTrapMatch anyway
+			// with the other fields
+			if len(resource.Namespaces) == 0 {
 				kiveTrapMatch := kivev1.KiveTrapMatch{
-					Namespace:     namespace,
-					ContainerName: resource.ContainerSelector,
+					ContainerName: containerName,
 					MatchLabels:   map[string]string{},
 				}
 
 				for _, resourceFilter := range trap.MatchResources.Any {
+					if resourceFilter.Selector == nil {
+						continue
+					}
 					for key, value := range resourceFilter.Selector.MatchLabels {
 						kiveTrapMatch.MatchLabels[key] = value
 					}
 				}
 
 				kiveTrapMatches = append(kiveTrapMatches, kiveTrapMatch)
+
+			} else {
+
+				for _, namespace := range resource.Namespaces {
+
+					kiveTrapMatch := kivev1.KiveTrapMatch{
+						Namespace:     namespace,
+						ContainerName: containerName,
+						MatchLabels:   map[string]string{},
+					}
+
+					for _, resourceFilter := range trap.MatchResources.Any {
+						if resourceFilter.Selector == nil {
+							continue
+						}
+						for key, value := range resourceFilter.Selector.MatchLabels {
+							kiveTrapMatch.MatchLabels[key] = value
+						}
+					}
+
+					kiveTrapMatches = append(kiveTrapMatches, kiveTrapMatch)
+				}
 			}
 		}
 
 		kiveTrap.MatchAny = append(kiveTrap.MatchAny, kiveTrapMatches...)
 	}
 
-	kiveTraps := []kivev1.KiveTrap{kiveTrap}
+	// Kive traps monitor a single path each, so we create one per file path.
+	// Kive does not support path prefixes or suffixes, so only exact paths are monitored.
+	exactPaths, _, _ := trap.FilesystemHoneytoken.MonitoredPaths()
+	kiveTraps := []kivev1.KiveTrap{}
+	for _, filePath := range exactPaths {
+		pathTrap := *kiveTrap.DeepCopy()
+		pathTrap.Path = filePath
+		kiveTraps = append(kiveTraps, pathTrap)
+	}
 	tracingPolicy.Spec.Traps = kiveTraps
 
+	// Store where the KivePolicy comes from, so that it is updated when the trap changes
+	tracingPolicy.Annotations = buildTrapMetadata(deceptionPolicy, trap, constants.AnnotationKeyTrapHash,
+		constants.AnnotationKeyDeceptionPolicyGeneration, constants.AnnotationKeyTrapDescription)
+	for key, value := range buildTrapTypeMetadata(trap, constants.AnnotationKeyTrapType, constants.AnnotationKeyFilePath) {
+		tracingPolicy.Annotations[key] = value
+	}
+	if hash := callbackHash(); hash != "" {
+		tracingPolicy.Annotations[constants.AnnotationKeyCallbackHash] = hash
+	}
+	for key, value := range buildFrameworkLabels(trap) {
+		tracingPolicy.Labels[key] = value
+	}
+	for key, value := range buildClassificationMetadata(trap, constants.LabelKeySeverity, constants.LabelKeyConfidence) {
+		tracingPolicy.Labels[key] = value
+	}
+
 	return tracingPolicy
 }
+
+// tetragonCaptor is a cluster-scoped TracingPolicy or a TracingPolicyNamespaced of Tetragon, which share the same spec.
+type tetragonCaptor interface {
+	client.Object
+	TpSpec() *ciliumiov1alpha1.TracingPolicySpec
+}
+
+// newTetragonCaptor returns an empty TracingPolicyNamespaced in the given namespace, or a TracingPolicy if the namespace is empty.
+func newTetragonCaptor(namespace string) tetragonCaptor {
+	if namespace == "" {
+		return &ciliumiov1alpha1.TracingPolicy{}
+	}
+	return &ciliumiov1alpha1.TracingPolicyNamespaced{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}}
+}
+
+// buildTetragonCaptor returns a copy of the tracing policy as a TracingPolicyNamespaced in the given namespace,
+// or the tracing policy itself if the namespace is empty. Namespaced tracing policies only trace the pods in their namespace.
+func buildTetragonCaptor(tracingPolicy *ciliumiov1alpha1.TracingPolicy, namespace string) tetragonCaptor {
+	if namespace == "" {
+		return tracingPolicy
+	}
+
+	namespacedTracingPolicy := &ciliumiov1alpha1.TracingPolicyNamespaced{
+		ObjectMeta: *tracingPolicy.ObjectMeta.DeepCopy(),
+		Spec:       *tracingPolicy.Spec.DeepCopy(),
+	}
+	namespacedTracingPolicy.Namespace = namespace
+	return namespacedTracingPolicy
+}
+
+// addPodLabelsToTracingPolicy restricts a Tetragon tracing policy to pods with the given labels, e.g., the pods of workloads.
+func addPodLabelsToTracingPolicy(tracingPolicy *ciliumiov1alpha1.TracingPolicy, podLabels map[string]string) {
+	maps.Copy(tracingPolicy.Spec.PodSelector.MatchLabels, podLabels)
+}
+
+// resolveCaptorPodLabels returns the pod labels that a captor requires in addition to the labels of the trap's selectors:
+// the labels of the pod selectors of referenced workloads, and the node scope label if the trap has node constraints.
+// Pods only carry the node scope label once a decoy was deployed to them, i.e., if they run on one of the selected nodes.
+func resolveCaptorPodLabels(r client.Reader, ctx context.Context, trap v1alpha1.Trap) (map[string]string, error) {
+	podLabels, err := matching.ResolveWorkloadPodLabels(r, ctx, trap.MatchResources)
+	if err != nil {
+		return nil, err
+	}
+
+	if trap.MatchResources.HasNodeConstraints() {
+		nodeScopeLabel, err := annotations.NodeScopeLabelKey(trap.MatchResources)
+		if err != nil {
+			return nil, err
+		}
+		podLabels[nodeScopeLabel] = "true"
+	}
+
+	return podLabels, nil
+}
+
+// containerFilter is the container selection of a resource filter, as stored in the container selectors annotation of a TracingPolicy.
+type containerFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	Image   string   `json:"image,omitempty"`
+}
+
+// addContainerSelectorToTracingPolicy restricts a Tetragon tracing policy to the containers that the trap selects.
+//
+//  1. If all resource filters select exact container names, or all containers except some exact container names →
+//     populate ContainerSelector with "name In" or "name NotIn" MatchExpressions, so Tetragon filters server-side.
+//
+//  2. Otherwise (some filter has a regex or glob pattern or an image selector that Tetragon cannot evaluate,
+//     or selects no container at all) → leave ContainerSelector empty and store the included and excluded patterns
+//     and the image selectors of ALL resource filters in an annotation, so that the alert forwarder can filter client-side.
+func addContainerSelectorToTracingPolicy(tracingPolicy *ciliumiov1alpha1.TracingPolicy, trap v1alpha1.Trap) {
+	tracingPolicy.Spec.ContainerSelector.MatchExpressions = nil
+	if len(trap.MatchResources.Any) == 0 {
+		return
+	}
+
+	needsClientFiltering := false
+	selectsAllExcept := false
+	includedNames := []string{}
+	excludedNames := []string{} // names that are excluded by all filters that select all containers
+	for _, resourceFilter := range trap.MatchResources.Any {
+		includes := resourceFilter.ContainerIncludes()
+		excludes := resourceFilter.ContainerExcludes()
+		if slices.ContainsFunc(includes, matching.ContainerSelectorNeedsClientFiltering) || resourceFilter.ImageSelector != "" {
+			needsClientFiltering = true
+			break
+		}
+
+		if len(includes) == 0 || slices.ContainsFunc(includes, matching.ContainerSelectorSelectsAll) {
+			if slices.ContainsFunc(excludes, matching.ContainerSelectorIsPattern) {
+				needsClientFiltering = true
+				break
+			}
+			if !selectsAllExcept {
+				excludedNames = excludes
+				selectsAllExcept = true
+			} else {
+				excludedNames = slices.DeleteFunc(slices.Clone(excludedNames), func(name string) bool {
+					return !slices.Contains(excludes, name)
+				})
+			}
+			continue
+		}
+
+		// Excluded patterns can be evaluated here already, since all included containers are known by their names
+		for _, name := range includes {
+			if matched, err := resourceFilter.MatchContainer(name); err == nil && matched && !slices.Contains(includedNames, name) {
+				includedNames = append(includedNames, name)
+			}
+		}
+	}
+
+	if !needsClientFiltering && selectsAllExcept {
+		excludedNames = slices.DeleteFunc(slices.Clone(excludedNames), func(name string) bool {
+			return slices.Contains(includedNames, name)
+		})
+		if len(excludedNames) > 0 {
+			slices.Sort(excludedNames)
+			tracingPolicy.Spec.ContainerSelector.MatchExpressions = []slimv1.LabelSelectorRequirement{
+				{Key: "name", Operator: slimv1.LabelSelectorOpNotIn, Values: excludedNames},
+			}
+		}
+		return
+	}
+	if !needsClientFiltering && len(includedNames) > 0 {
+		tracingPolicy.Spec.ContainerSelector.MatchExpressions = []slimv1.LabelSelectorRequirement{
+			{Key: "name", Operator: slimv1.LabelSelectorOpIn, Values: includedNames},
+		}
+		return
+	}
+
+	filters := make([]containerFilter, 0, len(trap.MatchResources.Any))
+	for _, resourceFilter := range trap.MatchResources.Any {
+		filters = append(filters, containerFilter{
+			Include: resourceFilter.ContainerIncludes(),
+			Exclude: resourceFilter.ContainerExcludes(),
+			Image:   resourceFilter.ImageSelector,
+		})
+	}
+	if filtersJSON, err := json.Marshal(filters); err == nil {
+		if tracingPolicy.Annotations == nil {
+			tracingPolicy.Annotations = make(map[string]string)
+		}
+		tracingPolicy.Annotations[constants.AnnotationKeyContainerSelectors] = string(filtersJSON)
+	}
+}
+
+// addPodLabelsToKivePolicy restricts a Kive policy to pods with the given labels, e.g., the pods of workloads.
+func addPodLabelsToKivePolicy(kivePolicy *kivev1.KivePolicy, podLabels map[string]string) {
+	for i := range kivePolicy.Spec.Traps {
+		for j := range kivePolicy.Spec.Traps[i].MatchAny {
+			if kivePolicy.Spec.Traps[i].MatchAny[j].MatchLabels == nil {
+				kivePolicy.Spec.Traps[i].MatchAny[j].MatchLabels = map[string]string{}
+			}
+			maps.Copy(kivePolicy.Spec.Traps[i].MatchAny[j].MatchLabels, podLabels)
+		}
+	}
+}
@@ -18,11 +18,16 @@ package filesystoken
 import (
 	"context"
 	"encoding/json"
+	"time"
 
+	ciliumiov1alpha1 "github.com/cilium/tetragon/pkg/k8s/apis/cilium.io/v1alpha1"
 	slimv1 "github.com/cilium/tetragon/pkg/k8s/slim/k8s/apis/meta/v1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/dynatrace-oss/koney/api/v1alpha1"
 	"github.com/dynatrace-oss/koney/internal/controller/constants"
@@ -98,16 +103,21 @@ var _ = Describe("generateTetragonTracingPolicy", func() {
 				// Check the container selector
 				for _, resourceFilter := range trap.MatchResources.Any {
 					if matching.ContainerSelectorSelectsAll(resourceFilter.ContainerSelector) {
-						// Case 1: selects all → empty ContainerSelector, no annotation
-						Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(BeEmpty())
+						// Case 1: selects all → ContainerSelector excludes sidecars, no annotation
+						Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(HaveLen(1))
+						Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions[0].Operator).To(Equal(slimv1.LabelSelectorOpNotIn))
+						Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions[0].Values).To(ConsistOf(v1alpha1.SidecarContainerNames))
 						Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyContainerSelectors))
 					} else if matching.ContainerSelectorNeedsClientFiltering(resourceFilter.ContainerSelector) {
 						// Case 2: wildcard pattern → empty ContainerSelector, annotation with all selectors
 						Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(BeEmpty())
 						Expect(tracingPolicy.Annotations).To(HaveKey(constants.AnnotationKeyContainerSelectors))
-						var selectors []string
-						Expect(json.Unmarshal([]byte(tracingPolicy.Annotations[constants.AnnotationKeyContainerSelectors]), &selectors)).To(Succeed())
-						Expect(selectors).To(ContainElement(resourceFilter.ContainerSelector))
+						var filters []containerFilter
+						Expect(json.Unmarshal([]byte(tracingPolicy.Annotations[constants.AnnotationKeyContainerSelectors]), &filters)).To(Succeed())
+						Expect(filters).To(ContainElement(containerFilter{
+							Include: []string{resourceFilter.ContainerSelector},
+							Exclude: v1alpha1.SidecarContainerNames,
+						}))
 					} else {
 						// Case 3: exact name → ContainerSelector with MatchExpressions, no annotation
 						Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(HaveLen(1))
@@ -121,6 +131,454 @@ var _ = Describe("generateTetragonTracingPolicy", func() {
 		})
 	})
 
+	Context("With a trap that selects containers with multiple patterns", func() {
+		It("should exclude exact container names server-side", func() {
+			trap := helpersTraps[0]
+			trap.MatchResources.Any = []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Containers: &v1alpha1.ContainerSelection{Exclude: []string{"debugger"}},
+			}}}
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(ConsistOf(slimv1.LabelSelectorRequirement{
+				Key:      "name",
+				Operator: slimv1.LabelSelectorOpNotIn,
+				Values:   []string{"debugger", "envoy", "istio-proxy", "linkerd-proxy"},
+			}))
+			Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyContainerSelectors))
+		})
+
+		It("should evaluate excluded patterns against included container names", func() {
+			trap := helpersTraps[0]
+			trap.MatchResources.Any = []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Containers: &v1alpha1.ContainerSelection{
+					Include: []string{"app", "app-debug", "istio-proxy"},
+					Exclude: []string{"glob:*-debug"},
+				},
+			}}}
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(ConsistOf(slimv1.LabelSelectorRequirement{
+				Key:      "name",
+				Operator: slimv1.LabelSelectorOpIn,
+				Values:   []string{"app", "istio-proxy"},
+			}))
+		})
+
+		It("should filter client-side if excluded patterns cannot be evaluated", func() {
+			trap := helpersTraps[0]
+			trap.MatchResources.Any = []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Containers: &v1alpha1.ContainerSelection{Exclude: []string{"regex:^debug"}, IncludeSidecars: true},
+			}}}
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(BeEmpty())
+			Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyContainerSelectors, `[{"exclude":["regex:^debug"]}]`))
+		})
+
+		It("should filter client-side by the image selector", func() {
+			trap := helpersTraps[0]
+			trap.MatchResources.Any = []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				ContainerSelector: "db",
+				ImageSelector:     "postgres",
+			}}}
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Spec.ContainerSelector.MatchExpressions).To(BeEmpty())
+			var filters []containerFilter
+			Expect(json.Unmarshal([]byte(tracingPolicy.Annotations[constants.AnnotationKeyContainerSelectors]), &filters)).To(Succeed())
+			Expect(filters).To(ConsistOf(containerFilter{Include: []string{"db"}, Exclude: v1alpha1.SidecarContainerNames, Image: "postgres"}))
+		})
+
+		It("should create one Kive trap match per included pattern", func() {
+			trap := helpersTraps[0]
+			trap.MatchResources.Any = []v1alpha1.ResourceFilter{{ResourceDescription: v1alpha1.ResourceDescription{
+				Containers: &v1alpha1.ContainerSelection{Include: []string{"app", "regex:^worker"}},
+			}}}
+
+			kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+			Expect(kivePolicy.Spec.Traps[0].MatchAny).To(HaveLen(2))
+			Expect(kivePolicy.Spec.Traps[0].MatchAny[0].ContainerName).To(Equal("app"))
+			Expect(kivePolicy.Spec.Traps[0].MatchAny[1].ContainerName).To(Equal("regex:^worker"))
+		})
+	})
+
+	Context("With a trap with multiple file paths", func() {
+		It("should match all file paths in a single TracingPolicy", func() {
+			trap := helpersTraps[0]
+			trap.FilesystemHoneytoken.FilePaths = []string{"/path/to/other/file", "/path/to/file"}
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			for _, kprobe := range tracingPolicy.Spec.KProbes {
+				Expect(kprobe.Selectors[0].MatchArgs[0].Values).To(Equal([]string{"/path/to/file", "/path/to/other/file"}))
+			}
+		})
+
+		It("should create one Kive trap per file path", func() {
+			trap := helpersTraps[0]
+			trap.FilesystemHoneytoken.FilePaths = []string{"/path/to/other/file"}
+
+			kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+			Expect(kivePolicy.Spec.Traps).To(HaveLen(2))
+			Expect(kivePolicy.Spec.Traps[0].Path).To(Equal("/path/to/file"))
+			Expect(kivePolicy.Spec.Traps[1].Path).To(Equal("/path/to/other/file"))
+		})
+	})
+
+	Context("With a trap with monitor path patterns", func() {
+		It("should match prefixes and suffixes with separate selectors", func() {
+			trap := helpersTraps[0]
+			trap.FilesystemHoneytoken.MonitorPaths = []string{"/var/backups/secrets/*", "*.kdbx"}
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			for _, kprobe := range tracingPolicy.Spec.KProbes {
+				Expect(kprobe.Selectors).To(HaveLen(3))
+				Expect(kprobe.Selectors[0].MatchArgs[0].Operator).To(Equal("Equal"))
+				Expect(kprobe.Selectors[0].MatchArgs[0].Values).To(Equal([]string{"/path/to/file"}))
+				Expect(kprobe.Selectors[1].MatchArgs[0].Operator).To(Equal("Prefix"))
+				Expect(kprobe.Selectors[1].MatchArgs[0].Values).To(Equal([]string{"/var/backups/secrets/"}))
+				Expect(kprobe.Selectors[2].MatchArgs[0].Operator).To(Equal("Postfix"))
+				Expect(kprobe.Selectors[2].MatchArgs[0].Values).To(Equal([]string{".kdbx"}))
+			}
+		})
+	})
+
+	Context("With a trap with an enforcement action", func() {
+		It("should block writes to the honeytoken before monitoring it", func() {
+			trap := helpersTraps[0]
+			trap.FilesystemHoneytoken.EnforcementAction = v1alpha1.EnforcementActionOverride
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			fileProbe := tracingPolicy.Spec.KProbes[0]
+			Expect(fileProbe.Args).To(HaveLen(2))
+			Expect(fileProbe.Args[1].Type).To(Equal("int"))
+			Expect(fileProbe.Selectors).To(HaveLen(2))
+			Expect(fileProbe.Selectors[0].MatchArgs[0].Values).To(Equal([]string{"/path/to/file"}))
+			Expect(fileProbe.Selectors[0].MatchArgs[1].Operator).To(Equal("Mask"))
+			Expect(fileProbe.Selectors[0].MatchArgs[1].Values).To(Equal([]string{"2"}))
+			Expect(fileProbe.Selectors[0].MatchActions[0].Action).To(Equal("Override"))
+			Expect(fileProbe.Selectors[0].MatchActions[0].ArgError).To(Equal(int32(-1)))
+			Expect(fileProbe.Selectors[0].MatchActions[1].Action).To(Equal("GetUrl"))
+
+			// Memory-mapped files are only monitored
+			Expect(tracingPolicy.Spec.KProbes[1].Args).To(HaveLen(1))
+			Expect(tracingPolicy.Spec.KProbes[1].Selectors).To(HaveLen(1))
+		})
+
+		It("should kill writers with the Sigkill action", func() {
+			trap := helpersTraps[0]
+			trap.FilesystemHoneytoken.EnforcementAction = v1alpha1.EnforcementActionSigkill
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Spec.KProbes[0].Selectors[0].MatchActions[0].Action).To(Equal("Sigkill"))
+			Expect(tracingPolicy.Spec.KProbes[0].Selectors[0].MatchActions[0].ArgError).To(BeZero())
+		})
+	})
+
+	Context("With a trap with a rate limit", func() {
+		It("should post at most one event per period before calling the alert forwarder", func() {
+			trap := helpersTraps[0]
+			trap.CaptorDeployment.RateLimit = &v1alpha1.CaptorRateLimit{Period: metav1.Duration{Duration: 2 * time.Minute}, Scope: "process"}
+			trap.FilesystemHoneytoken.EnforcementAction = v1alpha1.EnforcementActionOverride
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			for _, kprobe := range tracingPolicy.Spec.KProbes {
+				for _, selector := range kprobe.Selectors {
+					actions := selector.MatchActions
+					Expect(actions[len(actions)-2].Action).To(Equal("Post"))
+					Expect(actions[len(actions)-2].RateLimit).To(Equal("120"))
+					Expect(actions[len(actions)-2].RateLimitScope).To(Equal("process"))
+					Expect(actions[len(actions)-1].Action).To(Equal("GetUrl"))
+				}
+			}
+
+			By("still blocking every write")
+			Expect(tracingPolicy.Spec.KProbes[0].Selectors[0].MatchActions[0].Action).To(Equal("Override"))
+		})
+
+		It("should only monitor the honeytoken with the None action", func() {
+			trap := helpersTraps[0]
+			trap.FilesystemHoneytoken.EnforcementAction = v1alpha1.EnforcementActionNone
+
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Spec.KProbes[0].Args).To(HaveLen(1))
+			Expect(tracingPolicy.Spec.KProbes[0].Selectors).To(HaveLen(1))
+		})
+	})
+
+})
+
+var _ = Describe("ExpandFilePaths", func() {
+	It("should create one trap per file path", func() {
+		trap := helpersTraps[0]
+		trap.FilesystemHoneytoken.FilePaths = []string{"/path/to/other/file"}
+
+		expandedTraps := ExpandFilePaths([]v1alpha1.Trap{trap, helpersTraps[1]})
+		Expect(expandedTraps).To(HaveLen(3))
+		Expect(expandedTraps[0].FilesystemHoneytoken.FilePath).To(Equal("/path/to/file"))
+		Expect(expandedTraps[1].FilesystemHoneytoken.FilePath).To(Equal("/path/to/other/file"))
+		Expect(expandedTraps[2]).To(Equal(helpersTraps[1]))
+		for _, expandedTrap := range expandedTraps {
+			Expect(expandedTrap.FilesystemHoneytoken.FilePaths).To(BeEmpty())
+			Expect(expandedTrap.FilesystemHoneytoken.FileContent).To(Equal(trap.FilesystemHoneytoken.FileContent))
+		}
+	})
+})
+
+var _ = Describe("buildAlertingMetadata", func() {
+	var trap v1alpha1.Trap
+
+	BeforeEach(func() {
+		trap = helpersTraps[0]
+	})
+
+	Context("without alerting configuration", func() {
+		It("should not annotate the Tetragon TracingPolicy", func() {
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyAlertSeverity))
+			Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyAlertTags))
+		})
+	})
+
+	Context("with a severity and tags", func() {
+		BeforeEach(func() {
+			trap.Alerting = &v1alpha1.Alerting{
+				Severity: "CRITICAL",
+				Tags:     map[string]string{"team": "blue", "taxonomy": "T1552"},
+			}
+		})
+
+		It("should annotate the Tetragon TracingPolicy", func() {
+			tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+			Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyAlertSeverity, "CRITICAL"))
+
+			var tags map[string]string
+			Expect(json.Unmarshal([]byte(tracingPolicy.Annotations[constants.AnnotationKeyAlertTags]), &tags)).To(Succeed())
+			Expect(tags).To(Equal(trap.Alerting.Tags))
+		})
+
+		It("should add metadata to the Kive policy", func() {
+			kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+			Expect(kivePolicy.Spec.Traps).To(HaveLen(1))
+			Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyAlertSeverity, "CRITICAL"))
+			Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKey(constants.MetadataKeyAlertTags))
+		})
+	})
+})
+
+var _ = Describe("buildResponseMetadata", func() {
+	It("should not annotate traps without response actions", func() {
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, helpersTraps[0], "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyResponseActions))
+	})
+
+	It("should store the response actions for the alert forwarder", func() {
+		trap := helpersTraps[0]
+		trap.ResponseActions = []string{"labelPod", "killProcess"}
+
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyResponseActions, `["labelPod","killProcess"]`))
+
+		kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyResponseActions, `["labelPod","killProcess"]`))
+	})
+
+	It("should store how long the trap quarantines pods", func() {
+		trap := helpersTraps[0]
+		trap.Quarantine = &v1alpha1.Quarantine{TTL: &metav1.Duration{Duration: time.Hour}}
+
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyResponseActions, `["networkIsolate"]`))
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyQuarantineTTL, "1h0m0s"))
+
+		kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyQuarantineTTL, "1h0m0s"))
+	})
+})
+
+var _ = Describe("buildFrameworkMetadata", func() {
+	It("should not label traps without techniques or activities", func() {
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, helpersTraps[0], "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyAttckTechniques))
+		Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyEngageActivities))
+		Expect(tracingPolicy.Labels).To(HaveLen(1))
+	})
+
+	It("should label and annotate captors with the techniques and activities of the trap", func() {
+		trap := helpersTraps[0]
+		trap.AttckTechniques = []string{"T1552.001", "T1083"}
+		trap.EngageActivities = []string{"EAC0005"}
+
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+		Expect(tracingPolicy.Labels).To(HaveKeyWithValue("koney/attck-T1552.001", "true"))
+		Expect(tracingPolicy.Labels).To(HaveKeyWithValue("koney/attck-T1083", "true"))
+		Expect(tracingPolicy.Labels).To(HaveKeyWithValue("koney/engage-EAC0005", "true"))
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyAttckTechniques, `["T1552.001","T1083"]`))
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyEngageActivities, `["EAC0005"]`))
+
+		kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+		Expect(kivePolicy.Labels).To(HaveKeyWithValue("koney/attck-T1552.001", "true"))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyAttckTechniques, `["T1552.001","T1083"]`))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyEngageActivities, `["EAC0005"]`))
+	})
+})
+
+var _ = Describe("buildClassificationMetadata", func() {
+	It("should label captors with the severity and confidence of the trap", func() {
+		trap := helpersTraps[0]
+		trap.Severity = "high"
+		trap.Confidence = "medium"
+
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-tracing-policy")
+		Expect(tracingPolicy.Labels).To(HaveKeyWithValue(constants.LabelKeySeverity, "high"))
+		Expect(tracingPolicy.Labels).To(HaveKeyWithValue(constants.LabelKeyConfidence, "medium"))
+
+		kivePolicy := generateKivePolicy(&v1alpha1.DeceptionPolicy{}, trap, "test-kive-policy")
+		Expect(kivePolicy.Labels).To(HaveKeyWithValue(constants.LabelKeySeverity, "high"))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeySeverity, "high"))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyConfidence, "medium"))
+	})
+
+	It("should omit an unset severity and confidence", func() {
+		tracingPolicy := generateTetragonTracingPolicy(&v1alpha1.DeceptionPolicy{}, helpersTraps[0], "test-tracing-policy")
+		Expect(tracingPolicy.Labels).NotTo(HaveKey(constants.LabelKeySeverity))
+		Expect(tracingPolicy.Labels).NotTo(HaveKey(constants.LabelKeyConfidence))
+	})
+})
+
+var _ = Describe("buildTrapMetadata", func() {
+	var trap v1alpha1.Trap
+	var deceptionPolicy *v1alpha1.DeceptionPolicy
+
+	BeforeEach(func() {
+		trap = helpersTraps[0]
+		trap.Description = "Fake service token for the payment service"
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	})
+
+	It("should annotate the Tetragon TracingPolicy", func() {
+		tracingPolicy := generateTetragonTracingPolicy(deceptionPolicy, trap, "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyDeceptionPolicyGeneration, "3"))
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyTrapDescription, trap.Description))
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyTrapType, "filesystem_honeytoken"))
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyFilePath, trap.FilesystemHoneytoken.FilePath))
+
+		trapHash, err := TrapHash(trap)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tracingPolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyTrapHash, trapHash))
+	})
+
+	It("should add metadata to the Kive policy", func() {
+		kivePolicy := generateKivePolicy(deceptionPolicy, trap, "test-kive-policy")
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyDeceptionPolicyGeneration, "3"))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyTrapDescription, trap.Description))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKey(constants.MetadataKeyTrapHash))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyTrapType, "filesystem_honeytoken"))
+		Expect(kivePolicy.Spec.Traps[0].Metadata).To(HaveKeyWithValue(constants.MetadataKeyFilePath, trap.FilesystemHoneytoken.FilePath))
+		Expect(kivePolicy.Annotations).To(HaveKey(constants.AnnotationKeyTrapHash))
+		Expect(kivePolicy.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyTrapType, "filesystem_honeytoken"))
+	})
+
+	It("should omit an empty description", func() {
+		trap.Description = ""
+		tracingPolicy := generateTetragonTracingPolicy(deceptionPolicy, trap, "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyTrapDescription))
+	})
+})
+
+var _ = Describe("GenerateCaptorName", func() {
+	var deceptionPolicy *v1alpha1.DeceptionPolicy
+
+	BeforeEach(func() {
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample"},
+			Spec:       v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{helpersTraps[0], helpersTraps[1]}},
+		}
+	})
+
+	It("should keep the name when the trap at the same position changes", func() {
+		captorName, err := GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(captorName).To(HavePrefix(CaptorNamePrefix))
+
+		deceptionPolicy.Spec.Traps[0].FilesystemHoneytoken.FileContent = "someothersecrettoken"
+		changedCaptorName, err := GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changedCaptorName).To(Equal(captorName))
+	})
+
+	It("should generate different names for different positions and DeceptionPolicies", func() {
+		firstCaptorName, err := GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+		secondCaptorName, err := GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[1])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secondCaptorName).NotTo(Equal(firstCaptorName))
+
+		deceptionPolicy.Name = "deceptionpolicy-other"
+		otherCaptorName, err := GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherCaptorName).NotTo(Equal(firstCaptorName))
+	})
+
+	It("should fail for traps that are not part of the DeceptionPolicy", func() {
+		trap := helpersTraps[0]
+		trap.FilesystemHoneytoken.FilePath = "/run/secrets/koney/unknown"
+		_, err := GenerateCaptorName(deceptionPolicy, trap)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only name captor policies of strategies that deploy one", func() {
+		deceptionPolicy.Spec.Traps[0].CaptorDeployment = v1alpha1.CaptorDeployment{Strategies: []string{"sidecar"}}
+		policyName, err := GenerateCaptorPolicyName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policyName).To(BeEmpty())
+
+		deceptionPolicy.Spec.Traps[0].CaptorDeployment = v1alpha1.CaptorDeployment{Strategies: []string{"sidecar", "kive"}}
+		policyName, err = GenerateCaptorPolicyName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policyName).To(HavePrefix(CaptorNamePrefix))
+	})
+})
+
+var _ = Describe("isCaptorOutdated", func() {
+	It("should compare the trap hash annotation with the trap spec", func() {
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{Spec: v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{helpersTraps[0]}}}
+		tracingPolicy := generateTetragonTracingPolicy(deceptionPolicy, helpersTraps[0], "test-tracing-policy")
+		Expect(isCaptorOutdated(tracingPolicy, helpersTraps[0])).To(BeFalse())
+		Expect(isCaptorOutdated(tracingPolicy, helpersTraps[1])).To(BeTrue())
+
+		tracingPolicy.Annotations = nil
+		Expect(isCaptorOutdated(tracingPolicy, helpersTraps[0])).To(BeTrue())
+	})
+
+	It("should detect captors with an outdated callback URL", func() {
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{Spec: v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{helpersTraps[0]}}}
+		tracingPolicy := generateTetragonTracingPolicy(deceptionPolicy, helpersTraps[0], "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).NotTo(HaveKey(constants.AnnotationKeyCallbackHash))
+
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TOKEN", "secret")
+		Expect(isCaptorOutdated(tracingPolicy, helpersTraps[0])).To(BeTrue())
+
+		tracingPolicy = generateTetragonTracingPolicy(deceptionPolicy, helpersTraps[0], "test-tracing-policy")
+		Expect(tracingPolicy.Annotations).To(HaveKey(constants.AnnotationKeyCallbackHash))
+		Expect(isCaptorOutdated(tracingPolicy, helpersTraps[0])).To(BeFalse())
+
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TOKEN", "rotated")
+		Expect(isCaptorOutdated(tracingPolicy, helpersTraps[0])).To(BeTrue())
+	})
+})
+
+var _ = Describe("buildAlertForwarderUrl", func() {
+	It("should use plain HTTP without a token by default", func() {
+		Expect(buildTetragonWebhookUrl()).To(Equal("http://koney-alert-forwarder-webhook.koney-system.svc:8000/handlers/tetragon"))
+		Expect(buildKiveWebhookUrl()).To(Equal("http://koney-alert-forwarder-webhook.koney-system.svc:8000/handlers/kive"))
+	})
+
+	It("should use HTTPS and add the token, if configured", func() {
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TLS", "true")
+		GinkgoT().Setenv("KONEY_ALERT_FORWARDER_TOKEN", "s3cr3t/+")
+		Expect(buildTetragonWebhookUrl()).To(Equal(
+			"https://koney-alert-forwarder-webhook.koney-system.svc:8000/handlers/tetragon?token=s3cr3t%2F%2B"))
+	})
 })
 
 var _ = Describe("DeployCaptor", func() {
@@ -148,4 +606,95 @@ var _ = Describe("DeployCaptor", func() {
 			Expect(result.MissingTetragon).To(BeFalse())
 		})
 	})
+
+	Context("with captor strategy 'tetragon'", func() {
+		It("should update the existing TracingPolicy in place when the trap changes", func() {
+			ctx := context.Background()
+			deceptionPolicy := &v1alpha1.DeceptionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample"},
+				Spec:       v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{helpersTraps[0]}},
+			}
+
+			scheme := runtime.NewScheme()
+			Expect(ciliumiov1alpha1.AddToScheme(scheme)).To(Succeed())
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+			Expect(reconciler.deployCaptorWithTetragon(ctx, deceptionPolicy, deceptionPolicy.Spec.Traps[0])).To(Succeed())
+
+			captorName, err := GenerateCaptorName(deceptionPolicy, deceptionPolicy.Spec.Traps[0])
+			Expect(err).NotTo(HaveOccurred())
+			tracingPolicy := &ciliumiov1alpha1.TracingPolicy{}
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: captorName}, tracingPolicy)).To(Succeed())
+			Expect(isCaptorOutdated(tracingPolicy, deceptionPolicy.Spec.Traps[0])).To(BeFalse())
+
+			deceptionPolicy.Spec.Traps[0].FilesystemHoneytoken.FilePath = "/path/to/other/file"
+			Expect(reconciler.deployCaptorWithTetragon(ctx, deceptionPolicy, deceptionPolicy.Spec.Traps[0])).To(Succeed())
+
+			updatedTracingPolicy := &ciliumiov1alpha1.TracingPolicy{}
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: captorName}, updatedTracingPolicy)).To(Succeed())
+			Expect(isCaptorOutdated(updatedTracingPolicy, deceptionPolicy.Spec.Traps[0])).To(BeFalse())
+			Expect(updatedTracingPolicy.UID).To(Equal(tracingPolicy.UID))
+		})
+
+		It("should deploy namespaced TracingPolicies if the trap only targets specific namespaces", func() {
+			ctx := context.Background()
+			deceptionPolicy := &v1alpha1.DeceptionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample"},
+				Spec:       v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{*helpersTraps[0].DeepCopy()}},
+			}
+			trap := &deceptionPolicy.Spec.Traps[0]
+			trap.MatchResources = v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{
+				{ResourceDescription: v1alpha1.ResourceDescription{Namespaces: []string{"web", "api"}}},
+				{ResourceDescription: v1alpha1.ResourceDescription{Namespaces: []string{"api"}}},
+			}}
+			captorName, err := GenerateCaptorName(deceptionPolicy, *trap)
+			Expect(err).NotTo(HaveOccurred())
+
+			scheme := runtime.NewScheme()
+			Expect(ciliumiov1alpha1.AddToScheme(scheme)).To(Succeed())
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+			listNamespaces := func() []string {
+				tracingPolicies := &ciliumiov1alpha1.TracingPolicyNamespacedList{}
+				Expect(reconciler.List(ctx, tracingPolicies)).To(Succeed())
+				namespaces := []string{}
+				for _, tracingPolicy := range tracingPolicies.Items {
+					Expect(tracingPolicy.Name).To(Equal(captorName))
+					namespaces = append(namespaces, tracingPolicy.Namespace)
+				}
+				return namespaces
+			}
+
+			Expect(reconciler.deployCaptorWithTetragon(ctx, deceptionPolicy, *trap)).To(Succeed())
+			Expect(listNamespaces()).To(ConsistOf("api", "web"))
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: captorName}, &ciliumiov1alpha1.TracingPolicy{})).NotTo(Succeed())
+
+			By("removing the TracingPolicies of namespaces that are not targeted anymore")
+			trap.MatchResources.Any = trap.MatchResources.Any[1:]
+			Expect(reconciler.deployCaptorWithTetragon(ctx, deceptionPolicy, *trap)).To(Succeed())
+			Expect(listNamespaces()).To(ConsistOf("api"))
+
+			By("replacing them with a cluster-scoped TracingPolicy once the trap targets all namespaces")
+			trap.MatchResources.Any = append(trap.MatchResources.Any, v1alpha1.ResourceFilter{})
+			Expect(reconciler.deployCaptorWithTetragon(ctx, deceptionPolicy, *trap)).To(Succeed())
+			Expect(listNamespaces()).To(BeEmpty())
+			Expect(reconciler.Get(ctx, client.ObjectKey{Name: captorName}, &ciliumiov1alpha1.TracingPolicy{})).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("checkImageVolumeSubPathSupport", func() {
+	It("should accept Kubernetes 1.33 and newer", func() {
+		for _, gitVersion := range []string{"v1.33.0", "v1.34.2+k3s1", "v1.35.0-eks-3025e55"} {
+			Expect(checkImageVolumeSubPathSupport(gitVersion)).To(Succeed(), gitVersion)
+		}
+	})
+
+	It("should reject older versions, which cannot mount image volumes with a subPath", func() {
+		err := checkImageVolumeSubPathSupport("v1.32.4")
+		Expect(err).To(MatchError(ContainSubstring("requires Kubernetes 1.33 or newer")))
+		Expect(err).To(MatchError(ContainSubstring("v1.32.4")))
+	})
+
+	It("should reject versions that cannot be parsed", func() {
+		Expect(checkImageVolumeSubPathSupport("unknown")).NotTo(Succeed())
+	})
 })
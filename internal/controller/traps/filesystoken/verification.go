@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	utilexec "k8s.io/client-go/util/exec"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+// Reasons why a decoy drifted, i.e., why it is not in place anymore as it was deployed.
+const (
+	DriftReasonContainerMissing     = "ContainerMissing"
+	DriftReasonContainerRestarted   = "ContainerRestarted"
+	DriftReasonVolumeMissing        = "VolumeMissing"
+	DriftReasonInitContainerMissing = "InitContainerMissing"
+	DriftReasonSecretMissing        = "SecretMissing"
+	DriftReasonContentMismatch      = "ContentMismatch"
+	DriftReasonContentTampered      = "ContentTampered"
+	DriftReasonFileMissing          = "FileMissing"
+)
+
+// DecoyVerification configures how thoroughly VerifyDecoy checks a decoy.
+type DecoyVerification struct {
+	// Checksum is the SHA-256 checksum of the decoy that was recorded in the status when it was placed, if any.
+	// Content that does not match it was tampered with.
+	Checksum string
+	// ReadContainerFiles reads decoys that were written into the filesystem of a container back by exec'ing into
+	// the container, to compare their content with the checksum (otherwise, only restarts of the container are detected).
+	ReadContainerFiles bool
+}
+
+// VerifyDecoy checks if a FilesystemHoneytoken decoy is still in place in the containers of a resource
+// that the trap annotation lists. By default, the check does not exec into containers: it only looks up the spec and the
+// container statuses of the resource and the secret that holds the honeytoken, and compares the hash of the content
+// with the hash in the annotation and the checksum in the status. Decoys that were written into the filesystem of a container (containerExec
+// and ephemeralContainer) are lost when the container restarts, so a restart since the last deployment is a drift.
+// With verification.ReadContainerFiles, decoys that were placed with containerExec are also read back to detect tampering.
+// The function returns the reason of the drift for each container in which the decoy drifted.
+func (r *FilesystemHoneytokenReconciler) VerifyDecoy(ctx context.Context, trap v1alpha1.Trap, annotationTrap v1alpha1.TrapAnnotation,
+	resource client.Object, verification DecoyVerification) (map[string]string, error) {
+	log := k8slog.FromContext(ctx)
+
+	drifts := map[string]string{}
+	for _, containerName := range annotationTrap.Containers {
+		var reason string
+		var err error
+
+		switch annotationTrap.DeploymentStrategy {
+		case "containerExec", "ephemeralContainer":
+			if pod, ok := resource.(*corev1.Pod); ok {
+				reason = verifyDecoyInContainerFilesystem(annotationTrap, pod, containerName)
+				// Ephemeral containers are used when exec is not possible, so only decoys placed with exec are read back
+				if reason == "" && annotationTrap.DeploymentStrategy == "containerExec" && verification.ReadContainerFiles && verification.Checksum != "" {
+					// Content that references the placement context was rendered for this pod when it was placed
+					checksum := verification.Checksum
+					if trap.FilesystemHoneytoken.UsesPlacementContext() {
+						checksum = utils.SHA256(ResolvePlacementContext(trap, *pod).FilesystemHoneytoken.FileContent)
+					}
+					reason = r.verifyDecoyContentInContainer(ctx, *pod, containerName, annotationTrap.FilesystemHoneytoken.FilePath, checksum)
+				}
+			}
+		case "volumeMount", "projectedVolume", "imageVolume", "initContainer":
+			if deployment, ok := resource.(*appsv1.Deployment); ok {
+				reason, err = r.verifyDecoyInPodSpec(ctx, trap, annotationTrap, deployment.Namespace, &deployment.Spec.Template.Spec, containerName, verification.Checksum)
+			}
+		case "admission":
+			if pod, ok := resource.(*corev1.Pod); ok {
+				reason, err = r.verifyDecoyInPodSpec(ctx, trap, annotationTrap, pod.Namespace, &pod.Spec, containerName, verification.Checksum)
+			}
+		}
+
+		if err != nil {
+			log.Error(err, "unable to verify FilesystemHoneytoken decoy", "resource", resource.GetName(), "container", containerName)
+			return nil, err
+		}
+		if reason != "" {
+			log.Info("FilesystemHoneytoken decoy drifted", "resource", resource.GetName(), "container", containerName, "reason", reason)
+			drifts[containerName] = reason
+		}
+	}
+
+	return drifts, nil
+}
+
+// verifyDecoyInContainerFilesystem checks if a decoy that was written into the filesystem of a container of a pod
+// can still be there, i.e., if the container still exists and did not restart since the decoy was last deployed.
+// Containers that are not running are not verified, since decoys cannot be deployed to them anyway.
+// The function returns the reason of the drift, or an empty string if there is no drift.
+func verifyDecoyInContainerFilesystem(annotationTrap v1alpha1.TrapAnnotation, pod *corev1.Pod, containerName string) string {
+	if !slices.ContainsFunc(pod.Spec.Containers, func(container corev1.Container) bool { return container.Name == containerName }) {
+		return DriftReasonContainerMissing
+	}
+
+	// The annotation is refreshed whenever the decoy is deployed, so it tells when the decoy was last known to be in place
+	deployedAt, err := time.Parse(time.RFC3339, annotationTrap.UpdatedAt)
+	if err != nil {
+		if deployedAt, err = time.Parse(time.RFC3339, annotationTrap.CreatedAt); err != nil {
+			return ""
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName && status.State.Running != nil && status.State.Running.StartedAt.After(deployedAt) {
+			return DriftReasonContainerRestarted
+		}
+	}
+
+	return ""
+}
+
+// verifyDecoyContentInContainer reads a decoy back from the filesystem of a running container of a pod, and compares its content
+// with the checksum that was recorded when it was placed. Errors of the exec itself (e.g., because exec is not allowed anymore)
+// are only logged, since the decoy cannot be judged then.
+// The function returns the reason of the drift, or an empty string if there is no drift.
+func (r *FilesystemHoneytokenReconciler) verifyDecoyContentInContainer(ctx context.Context, pod corev1.Pod, containerName, filePath, checksum string) string {
+	log := k8slog.FromContext(ctx)
+
+	if !slices.ContainsFunc(pod.Status.ContainerStatuses, func(status corev1.ContainerStatus) bool {
+		return status.Name == containerName && status.State.Running != nil
+	}) {
+		return ""
+	}
+
+	content, found, err := r.readDecoyInContainer(ctx, pod, containerName, filePath)
+	switch {
+	case err != nil:
+		log.Error(err, "unable to read FilesystemHoneytoken decoy back from container", "pod", pod.Name, "container", containerName)
+		return ""
+	case !found:
+		return DriftReasonFileMissing
+	case utils.SHA256(content) != checksum:
+		return DriftReasonContentTampered
+	default:
+		return ""
+	}
+}
+
+// readDecoyInContainer reads the content of a decoy from the filesystem of a container, with cat (or with tar in containers
+// without a shell), both marked with the fingerprint. The boolean return value is false if the command failed, i.e., if the file
+// cannot be read anymore (e.g., because it was removed).
+func (r *FilesystemHoneytokenReconciler) readDecoyInContainer(ctx context.Context, pod corev1.Pod, containerName, filePath string) (string, bool, error) {
+	method, err := r.detectPlacementMethod(ctx, pod, containerName)
+	if err != nil {
+		return "", false, err
+	}
+
+	var cmd []string
+	if method == placementMethodTar {
+		cmd = []string{"tar", "-c", "-f", "-", "-C", "/", tarFingerprintFlag(), strings.TrimPrefix(filePath, "/")}
+	} else {
+		cmd = append(append([]string{"cat"}, strings.Fields(alerts.EncodeFingerprintInCat(utils.GetKoneyFingerprint()))...), filePath)
+	}
+
+	output, err := r.executeCommandInContainer(ctx, pod, containerName, cmd)
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	if method == placementMethodTar {
+		files, err := readDecoyArchive([]byte(output))
+		if err != nil {
+			return "", false, err
+		}
+		file, ok := files[filePath]
+		return file.content, ok, nil
+	}
+	return output, true, nil
+}
+
+// verifyDecoyInPodSpec checks if a decoy that is mounted from a volume is still mounted in a container of a pod spec
+// (of a pod or of the pod template of a deployment), and if the source of the volume still holds the honeytoken.
+// If a checksum was recorded for the decoy, content that does not match it was tampered with.
+// The content of image volumes cannot be looked up, so only their mounts are verified.
+// The function returns the reason of the drift, or an empty string if there is no drift.
+func (r *FilesystemHoneytokenReconciler) verifyDecoyInPodSpec(ctx context.Context, trap v1alpha1.Trap, annotationTrap v1alpha1.TrapAnnotation,
+	namespace string, podSpec *corev1.PodSpec, containerName, checksum string) (string, error) {
+	containerIndex := slices.IndexFunc(podSpec.Containers, func(container corev1.Container) bool { return container.Name == containerName })
+	if containerIndex < 0 {
+		return DriftReasonContainerMissing, nil
+	}
+
+	volumeName := generateVolumeName(trap.FilesystemHoneytoken.FilePath)
+	volumeIndex := slices.IndexFunc(podSpec.Volumes, func(volume corev1.Volume) bool { return volume.Name == volumeName })
+	if volumeIndex < 0 || !slices.ContainsFunc(podSpec.Containers[containerIndex].VolumeMounts, func(volumeMount corev1.VolumeMount) bool {
+		return volumeMount.Name == volumeName && volumeMount.MountPath == trap.FilesystemHoneytoken.FilePath
+	}) {
+		return DriftReasonVolumeMissing, nil
+	}
+
+	switch annotationTrap.DeploymentStrategy {
+	case "imageVolume":
+		return "", nil
+
+	case "initContainer":
+		initContainerName := generateInitContainerName(trap.FilesystemHoneytoken.FilePath)
+		initContainerIndex := slices.IndexFunc(podSpec.InitContainers, func(container corev1.Container) bool { return container.Name == initContainerName })
+		if initContainerIndex < 0 {
+			return DriftReasonInitContainerMissing, nil
+		}
+		for _, env := range podSpec.InitContainers[initContainerIndex].Env {
+			if env.Name != "KONEY_FILE_CONTENT" {
+				continue
+			}
+			if checksum != "" && utils.SHA256(env.Value) != checksum {
+				return DriftReasonContentTampered, nil
+			} else if utils.Hash(env.Value) != annotationTrap.FilesystemHoneytoken.FileContentHash {
+				return DriftReasonContentMismatch, nil
+			}
+		}
+		return "", nil
+
+	default:
+		// The other strategies mount the honeytoken from a secret, either directly or from a projected volume
+		secretName := generateSecretName(trap)
+		if volumeSecretName(podSpec.Volumes[volumeIndex]) != secretName {
+			return DriftReasonVolumeMissing, nil
+		}
+
+		secret := corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, &secret); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return "", err
+			}
+			return DriftReasonSecretMissing, nil
+		}
+
+		_, fileName := filepath.Split(trap.FilesystemHoneytoken.FilePath)
+		content, ok := secret.Data[fileName]
+		if ok && checksum != "" && utils.SHA256(string(content)) != checksum {
+			return DriftReasonContentTampered, nil
+		} else if !ok || utils.Hash(string(content)) != annotationTrap.FilesystemHoneytoken.FileContentHash {
+			return DriftReasonContentMismatch, nil
+		}
+		return "", nil
+	}
+}
+
+// volumeSecretName returns the name of the secret that a secret volume or a projected volume mounts,
+// or an empty string if the volume does not mount a secret.
+func volumeSecretName(volume corev1.Volume) string {
+	switch {
+	case volume.Secret != nil:
+		return volume.Secret.SecretName
+	case volume.Projected != nil:
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				return source.Secret.Name
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filesystoken
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/utils"
+)
+
+var _ = Describe("VerifyDecoy", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx            context.Context
+		trap           v1alpha1.Trap
+		annotationTrap v1alpha1.TrapAnnotation
+		deployedAt     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		deployedAt = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		trap = v1alpha1.Trap{
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "secret token"},
+		}
+		annotationTrap = v1alpha1.TrapAnnotation{
+			Containers: []string{"app"},
+			CreatedAt:  deployedAt.Add(-time.Hour).Format(time.RFC3339),
+			UpdatedAt:  deployedAt.Format(time.RFC3339),
+			FilesystemHoneytoken: v1alpha1.FilesystemHoneytokenAnnotation{
+				FilePath:        FilePath,
+				FileContentHash: utils.Hash(trap.FilesystemHoneytoken.FileContent),
+			},
+		}
+	})
+
+	Context("with the containerExec strategy", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			trap.DecoyDeployment.Strategy = "containerExec"
+			annotationTrap.DeploymentStrategy = "containerExec"
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			}
+		})
+
+		runningSince := func(startedAt time.Time) []corev1.ContainerStatus {
+			return []corev1.ContainerStatus{{
+				Name:  "app",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+			}}
+		}
+
+		It("should not report a drift if the container did not restart since the decoy was deployed", func() {
+			pod.Status.ContainerStatuses = runningSince(deployedAt.Add(-time.Minute))
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, pod, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(BeEmpty())
+		})
+
+		It("should report a drift if the container restarted since the decoy was deployed", func() {
+			pod.Status.ContainerStatuses = runningSince(deployedAt.Add(time.Minute))
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, pod, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonContainerRestarted}))
+		})
+
+		It("should report a drift if the container does not exist anymore", func() {
+			annotationTrap.Containers = []string{"app", "sidecar"}
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, pod, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"sidecar": DriftReasonContainerMissing}))
+		})
+	})
+
+	Context("with the volumeMount strategy", func() {
+		var (
+			deployment *appsv1.Deployment
+			secret     *corev1.Secret
+		)
+
+		BeforeEach(func() {
+			trap.DecoyDeployment.Strategy = "volumeMount"
+			annotationTrap.DeploymentStrategy = "volumeMount"
+
+			volume, volumeMount, data, err := buildSecretVolume(trap)
+			Expect(err).ToNot(HaveOccurred())
+			deployment = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "app", VolumeMounts: []corev1.VolumeMount{volumeMount}}},
+							Volumes:    []corev1.Volume{volume},
+						},
+					},
+				},
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: volume.Secret.SecretName, Namespace: "koney-demo"},
+				Data:       data,
+			}
+		})
+
+		It("should not report a drift if the secret holds the honeytoken", func() {
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithObjects(secret).Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(BeEmpty())
+		})
+
+		It("should report a drift if the secret was deleted", func() {
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonSecretMissing}))
+		})
+
+		It("should report a drift if the secret was tampered with", func() {
+			secret.Data["service_token"] = []byte("tampered")
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithObjects(secret).Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonContentMismatch}))
+		})
+
+		It("should report tampering if the secret does not match the checksum in the status", func() {
+			secret.Data["service_token"] = []byte("tampered")
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithObjects(secret).Build()}
+			verification := DecoyVerification{Checksum: utils.SHA256(trap.FilesystemHoneytoken.FileContent)}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, verification)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonContentTampered}))
+		})
+
+		It("should report tampering even if the annotation was changed to match the secret", func() {
+			secret.Data["service_token"] = []byte("tampered")
+			annotationTrap.FilesystemHoneytoken.FileContentHash = utils.Hash("tampered")
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithObjects(secret).Build()}
+			verification := DecoyVerification{Checksum: utils.SHA256(trap.FilesystemHoneytoken.FileContent)}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, verification)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonContentTampered}))
+		})
+
+		It("should report a drift if the volume mount was removed", func() {
+			deployment.Spec.Template.Spec.Containers[0].VolumeMounts = nil
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().WithObjects(secret).Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonVolumeMissing}))
+		})
+
+		It("should restore the data of a tampered secret when it is created again", func() {
+			_, _, data, err := buildSecretVolume(trap)
+			Expect(err).ToNot(HaveOccurred())
+			secret.Data = map[string][]byte{"service_token": []byte("tampered")}
+			fakeClient := fake.NewClientBuilder().WithObjects(secret).Build()
+
+			Expect(createSecret(fakeClient, ctx, nil, trap, "koney-demo", secret.Name, data)).To(Succeed())
+
+			restoredSecret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(secret), restoredSecret)).To(Succeed())
+			Expect(restoredSecret.Data).To(Equal(data))
+		})
+	})
+
+	Context("with the initContainer strategy", func() {
+		It("should report a drift if the init container was removed", func() {
+			trap.DecoyDeployment.Strategy = "initContainer"
+			annotationTrap.DeploymentStrategy = "initContainer"
+
+			_, volume, volumeMount, err := buildInitContainerVolume(trap)
+			Expect(err).ToNot(HaveOccurred())
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "app", VolumeMounts: []corev1.VolumeMount{volumeMount}}},
+							Volumes:    []corev1.Volume{volume},
+						},
+					},
+				},
+			}
+			reconciler := FilesystemHoneytokenReconciler{Client: fake.NewClientBuilder().Build()}
+
+			drifts, err := reconciler.VerifyDecoy(ctx, trap, annotationTrap, deployment, DecoyVerification{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(Equal(map[string]string{"app": DriftReasonInitContainerMissing}))
+		})
+	})
+})
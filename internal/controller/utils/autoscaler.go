@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+// verticalPodAutoscalerListGVK is the kind of lists of VerticalPodAutoscalers, which are only available if the VPA is installed.
+var verticalPodAutoscalerListGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscalerList"}
+
+// ExcludeInjectedContainersFromAutoscalers adds a container policy with mode "Off" for each container that Koney injected into
+// a deployment (see MarkInjectedContainers) to the VerticalPodAutoscalers that target the deployment, so that they neither
+// compute recommendations for the injected containers nor resize them. Container policies that Koney added for containers
+// that are not injected anymore are removed again, while container policies of the user are kept.
+// Nothing is done if the VerticalPodAutoscaler is not installed in the cluster.
+func ExcludeInjectedContainersFromAutoscalers(c client.Client, ctx context.Context, deployment *appsv1.Deployment) error {
+	verticalPodAutoscalers := &unstructured.UnstructuredList{}
+	verticalPodAutoscalers.SetGroupVersionKind(verticalPodAutoscalerListGVK)
+	if err := c.List(ctx, verticalPodAutoscalers, client.InNamespace(deployment.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	injected := GetInjectedContainers(&deployment.Spec.Template)
+	for i := range verticalPodAutoscalers.Items {
+		verticalPodAutoscaler := &verticalPodAutoscalers.Items[i]
+		targetKind, _, _ := unstructured.NestedString(verticalPodAutoscaler.Object, "spec", "targetRef", "kind")
+		targetName, _, _ := unstructured.NestedString(verticalPodAutoscaler.Object, "spec", "targetRef", "name")
+		if targetKind != "Deployment" || targetName != deployment.Name {
+			continue
+		}
+
+		if !excludeContainersFromVerticalPodAutoscaler(verticalPodAutoscaler, injected) {
+			continue
+		}
+		if err := c.Update(ctx, verticalPodAutoscaler); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// excludeContainersFromVerticalPodAutoscaler updates the container policies of a VerticalPodAutoscaler (see ExcludeInjectedContainersFromAutoscalers)
+// and records the excluded containers in its annotations. The function returns true if the VerticalPodAutoscaler was changed.
+func excludeContainersFromVerticalPodAutoscaler(verticalPodAutoscaler *unstructured.Unstructured, injected []string) bool {
+	previouslyExcluded := splitContainerList(verticalPodAutoscaler.GetAnnotations()[constants.AnnotationKeyExcludedContainers])
+	containerPolicies, _, _ := unstructured.NestedSlice(verticalPodAutoscaler.Object, "spec", "resourcePolicy", "containerPolicies")
+
+	changed := false
+	newContainerPolicies := []any{}
+	withPolicy := []string{}
+	for _, containerPolicy := range containerPolicies {
+		containerName, _, _ := unstructured.NestedString(containerPolicy.(map[string]any), "containerName")
+		if Contains(previouslyExcluded, containerName) && !Contains(injected, containerName) {
+			changed = true
+			continue
+		}
+		newContainerPolicies = append(newContainerPolicies, containerPolicy)
+		withPolicy = append(withPolicy, containerName)
+	}
+
+	excluded := []string{}
+	for _, containerName := range injected {
+		if !Contains(withPolicy, containerName) {
+			newContainerPolicies = append(newContainerPolicies, map[string]any{"containerName": containerName, "mode": "Off"})
+			changed = true
+		} else if !Contains(previouslyExcluded, containerName) {
+			// The user configured a container policy for this container, which is kept as it is
+			continue
+		}
+		excluded = append(excluded, containerName)
+	}
+	slices.Sort(excluded)
+
+	if !changed && slices.Equal(excluded, previouslyExcluded) {
+		return false
+	}
+
+	_ = unstructured.SetNestedSlice(verticalPodAutoscaler.Object, newContainerPolicies, "spec", "resourcePolicy", "containerPolicies")
+	annotations := verticalPodAutoscaler.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(excluded) == 0 {
+		delete(annotations, constants.AnnotationKeyExcludedContainers)
+	} else {
+		annotations[constants.AnnotationKeyExcludedContainers] = strings.Join(excluded, ",")
+	}
+	verticalPodAutoscaler.SetAnnotations(annotations)
+
+	return true
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("ExcludeInjectedContainersFromAutoscalers", func() {
+	var (
+		ctx        context.Context
+		deployment *appsv1.Deployment
+	)
+
+	verticalPodAutoscalerGVK := schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: "VerticalPodAutoscaler"}
+
+	buildVerticalPodAutoscaler := func(name, targetName string, containerPolicies ...any) *unstructured.Unstructured {
+		verticalPodAutoscaler := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"targetRef": map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": targetName},
+			},
+		}}
+		if len(containerPolicies) > 0 {
+			Expect(unstructured.SetNestedSlice(verticalPodAutoscaler.Object, containerPolicies, "spec", "resourcePolicy", "containerPolicies")).To(Succeed())
+		}
+		verticalPodAutoscaler.SetGroupVersionKind(verticalPodAutoscalerGVK)
+		verticalPodAutoscaler.SetName(name)
+		verticalPodAutoscaler.SetNamespace("default")
+		return verticalPodAutoscaler
+	}
+
+	buildClient := func(objects ...client.Object) client.Client {
+		restMapper := meta.NewDefaultRESTMapper(nil)
+		restMapper.Add(verticalPodAutoscalerGVK, meta.RESTScopeNamespace)
+		return fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithRESTMapper(restMapper).WithObjects(objects...).Build()
+	}
+
+	getContainerPolicies := func(c client.Client, name string) ([]any, map[string]string) {
+		verticalPodAutoscaler := &unstructured.Unstructured{}
+		verticalPodAutoscaler.SetGroupVersionKind(verticalPodAutoscalerGVK)
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, verticalPodAutoscaler)).To(Succeed())
+		containerPolicies, _, _ := unstructured.NestedSlice(verticalPodAutoscaler.Object, "spec", "resourcePolicy", "containerPolicies")
+		return containerPolicies, verticalPodAutoscaler.GetAnnotations()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+		MarkInjectedContainers(&deployment.Spec.Template, []string{"koney-captor-a"})
+	})
+
+	It("should turn off the injected containers in the autoscalers of the deployment", func() {
+		c := buildClient(
+			buildVerticalPodAutoscaler("nginx", "nginx", map[string]any{"containerName": "nginx", "mode": "Auto"}),
+			buildVerticalPodAutoscaler("other", "other"),
+		)
+		Expect(ExcludeInjectedContainersFromAutoscalers(c, ctx, deployment)).To(Succeed())
+
+		containerPolicies, annotations := getContainerPolicies(c, "nginx")
+		Expect(containerPolicies).To(Equal([]any{
+			map[string]any{"containerName": "nginx", "mode": "Auto"},
+			map[string]any{"containerName": "koney-captor-a", "mode": "Off"},
+		}))
+		Expect(annotations).To(HaveKeyWithValue(constants.AnnotationKeyExcludedContainers, "koney-captor-a"))
+
+		containerPolicies, annotations = getContainerPolicies(c, "other")
+		Expect(containerPolicies).To(BeEmpty())
+		Expect(annotations).NotTo(HaveKey(constants.AnnotationKeyExcludedContainers))
+	})
+
+	It("should only remove the container policies that Koney added", func() {
+		c := buildClient(buildVerticalPodAutoscaler("nginx", "nginx", map[string]any{"containerName": "koney-captor-b", "mode": "Auto"}))
+		MarkInjectedContainers(&deployment.Spec.Template, []string{"koney-captor-b"})
+		Expect(ExcludeInjectedContainersFromAutoscalers(c, ctx, deployment)).To(Succeed())
+
+		// The container policy of the user for koney-captor-b is kept as it is
+		containerPolicies, annotations := getContainerPolicies(c, "nginx")
+		Expect(containerPolicies).To(ConsistOf(
+			map[string]any{"containerName": "koney-captor-b", "mode": "Auto"},
+			map[string]any{"containerName": "koney-captor-a", "mode": "Off"},
+		))
+		Expect(annotations).To(HaveKeyWithValue(constants.AnnotationKeyExcludedContainers, "koney-captor-a"))
+
+		UnmarkInjectedContainers(&deployment.Spec.Template, []string{"koney-captor-a", "koney-captor-b"})
+		Expect(ExcludeInjectedContainersFromAutoscalers(c, ctx, deployment)).To(Succeed())
+
+		containerPolicies, annotations = getContainerPolicies(c, "nginx")
+		Expect(containerPolicies).To(Equal([]any{map[string]any{"containerName": "koney-captor-b", "mode": "Auto"}}))
+		Expect(annotations).NotTo(HaveKey(constants.AnnotationKeyExcludedContainers))
+	})
+
+	It("should do nothing if the Vertical Pod Autoscaler is not installed", func() {
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithRESTMapper(meta.NewDefaultRESTMapper(nil)).Build()
+		Expect(ExcludeInjectedContainersFromAutoscalers(c, ctx, deployment)).To(Succeed())
+	})
+})
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ForEachConcurrently", func() {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	It("should process all items without exceeding the concurrency", func() {
+		var running, maxRunning, processed atomic.Int32
+		err := ForEachConcurrently(items, 3, func(int) error {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				previous := maxRunning.Load()
+				if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			processed.Add(1)
+			return nil
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed.Load()).To(BeEquivalentTo(len(items)))
+		Expect(maxRunning.Load()).To(BeNumerically("<=", 3))
+		Expect(maxRunning.Load()).To(BeNumerically(">", 1))
+	})
+
+	It("should join the errors of all failed items", func() {
+		var processed atomic.Int32
+		err := ForEachConcurrently(items, 2, func(item int) error {
+			processed.Add(1)
+			if item%4 == 0 {
+				return fmt.Errorf("item %d failed", item)
+			}
+			return nil
+		})
+
+		Expect(processed.Load()).To(BeEquivalentTo(len(items)))
+		Expect(err).To(MatchError(ContainSubstring("item 4 failed")))
+		Expect(err).To(MatchError(ContainSubstring("item 8 failed")))
+		Expect(errors.Unwrap(err)).To(BeNil()) // joined errors unwrap to a list
+	})
+
+	It("should process items sequentially with a concurrency below one", func() {
+		order := []int{}
+		Expect(ForEachConcurrently(items, 0, func(item int) error {
+			order = append(order, item)
+			return nil
+		})).To(Succeed())
+		Expect(order).To(Equal(items))
+	})
+})
@@ -15,13 +15,125 @@
 
 package utils
 
-import "os"
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+// DefaultDeceptionAlertRetention is how long DeceptionAlerts are kept if no retention is configured.
+const DefaultDeceptionAlertRetention = 7 * 24 * time.Hour
+
+// DefaultPlacementConcurrency is how many resources the controller places the decoys of a trap in at the same time if nothing is configured.
+const DefaultPlacementConcurrency = 10
 
 // GetKoneyNamespace retrieves the namespace where Koney is installed.
 func GetKoneyNamespace() string {
 	return GetEnv("KONEY_NAMESPACE", "koney-system")
 }
 
+// GetWatchNamespaces retrieves the namespaces that Koney watches and deploys traps in, sorted and without duplicates.
+// No namespaces means that Koney watches all namespaces of the cluster.
+func GetWatchNamespaces() []string {
+	namespaces := []string{}
+	for _, namespace := range strings.Split(GetEnv("KONEY_WATCH_NAMESPACES", ""), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" && !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	slices.Sort(namespaces)
+	return namespaces
+}
+
+// IsWatchedNamespace returns true if Koney watches the given namespace, which is always the case if Koney watches all namespaces.
+func IsWatchedNamespace(namespace string) bool {
+	namespaces := GetWatchNamespaces()
+	return len(namespaces) == 0 || slices.Contains(namespaces, namespace)
+}
+
+// GetAlertForwarderToken retrieves the token that captors use to authenticate with the alert forwarder.
+// An empty token means that the alert forwarder does not require authentication.
+func GetAlertForwarderToken() string {
+	return GetEnv("KONEY_ALERT_FORWARDER_TOKEN", "")
+}
+
+// IsAlertForwarderTLSEnabled returns true if the alert forwarder serves its handlers with TLS.
+func IsAlertForwarderTLSEnabled() bool {
+	return GetEnv("KONEY_ALERT_FORWARDER_TLS", "false") == "true"
+}
+
+// GetAlertForwarderTokenSecretName retrieves the name of the secret in the Koney namespace with the token of the alert forwarder.
+func GetAlertForwarderTokenSecretName() string {
+	return GetEnv("KONEY_ALERT_FORWARDER_TOKEN_SECRET", "koney-alert-forwarder-token")
+}
+
+// GetAlertForwarderCertSecretName retrieves the name of the secret in the Koney namespace with the TLS certificate of the alert forwarder.
+func GetAlertForwarderCertSecretName() string {
+	return GetEnv("KONEY_ALERT_FORWARDER_CERT_SECRET", "koney-alert-forwarder-cert")
+}
+
+// GetSelfTestImage retrieves the image of the test pods that run the self-tests of DeceptionPolicies.
+func GetSelfTestImage() string {
+	return GetEnv("KONEY_SELF_TEST_IMAGE", constants.DefaultSelfTestImage)
+}
+
+// GetCanaryTokensURL retrieves the base URL of the canarytokens-compatible service that canary tokens are provisioned from.
+// An empty URL means that canary tokens cannot be provisioned.
+func GetCanaryTokensURL() string {
+	return GetEnv("KONEY_CANARYTOKENS_URL", "")
+}
+
+// GetCanaryTokensWebhookURL retrieves the URL that the canarytokens-compatible service reports triggered canary tokens to,
+// i.e., the canary token handler of the alert forwarder, as the service reaches it.
+func GetCanaryTokensWebhookURL() string {
+	return GetEnv("KONEY_CANARYTOKENS_WEBHOOK_URL", "")
+}
+
+// GetFalcoNamespace retrieves the namespace of Falco, where the ConfigMap with the Falco rules of the captors is stored.
+// An empty namespace means that captors cannot be deployed with Falco.
+func GetFalcoNamespace() string {
+	return GetEnv("KONEY_FALCO_NAMESPACE", "")
+}
+
+// IsFalcoEnabled returns true if captors can be deployed with Falco, i.e., if the namespace of Falco is known.
+func IsFalcoEnabled() bool {
+	return GetFalcoNamespace() != ""
+}
+
+// GetTetragonNamespace retrieves the namespace of Tetragon, where the controller checks that its DaemonSet is ready.
+func GetTetragonNamespace() string {
+	return GetEnv("KONEY_TETRAGON_NAMESPACE", "kube-system")
+}
+
+// GetTetragonDaemonSetName retrieves the name of the DaemonSet of Tetragon.
+func GetTetragonDaemonSetName() string {
+	return GetEnv("KONEY_TETRAGON_DAEMONSET", "tetragon")
+}
+
+// GetDeceptionAlertRetention retrieves how long DeceptionAlerts are kept before the controller deletes them.
+// A retention of zero keeps them forever. Invalid values fall back to DefaultDeceptionAlertRetention.
+func GetDeceptionAlertRetention() time.Duration {
+	retention, err := time.ParseDuration(GetEnv("KONEY_DECEPTION_ALERT_RETENTION", ""))
+	if err != nil || retention < 0 {
+		return DefaultDeceptionAlertRetention
+	}
+	return retention
+}
+
+// GetPlacementConcurrency retrieves how many resources the controller places the decoys of a trap in at the same time.
+// Invalid values and values below one fall back to DefaultPlacementConcurrency.
+func GetPlacementConcurrency() int {
+	concurrency, err := strconv.Atoi(GetEnv("KONEY_PLACEMENT_CONCURRENCY", ""))
+	if err != nil || concurrency < 1 {
+		return DefaultPlacementConcurrency
+	}
+	return concurrency
+}
+
 // GetEnv retrieves the value of the environment variable named by the key.
 // If the variable is present in the environment the value (which may be empty) is returned.
 // Otherwise the fallback value is returned.
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetWatchNamespaces", func() {
+	It("should watch all namespaces if nothing is configured", func() {
+		GinkgoT().Setenv("KONEY_WATCH_NAMESPACES", "")
+		Expect(GetWatchNamespaces()).To(BeEmpty())
+		Expect(IsWatchedNamespace("any")).To(BeTrue())
+	})
+
+	It("should parse a comma-separated list of namespaces", func() {
+		GinkgoT().Setenv("KONEY_WATCH_NAMESPACES", " team-b,team-a,, team-b ")
+		Expect(GetWatchNamespaces()).To(Equal([]string{"team-a", "team-b"}))
+		Expect(IsWatchedNamespace("team-a")).To(BeTrue())
+		Expect(IsWatchedNamespace("team-c")).To(BeFalse())
+	})
+})
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecuteCommandInContainer executes a command in a container. If the command
+// is successful, the function returns the stdout output. If the command
+// fails, the function returns the stderr output and an error.
+func ExecuteCommandInContainer(ctx context.Context, clientset kubernetes.Interface, config *rest.Config,
+	pod corev1.Pod, containerName string, cmd []string) (string, error) {
+	return ExecuteCommandInContainerWithStdin(ctx, clientset, config, pod, containerName, cmd, nil)
+}
+
+// ExecuteCommandInContainerWithStdin executes a command in a container like ExecuteCommandInContainer,
+// and streams stdin to the command, if it is not nil (e.g., to pass files to commands like tar).
+func ExecuteCommandInContainerWithStdin(ctx context.Context, clientset kubernetes.Interface, config *rest.Config,
+	pod corev1.Pod, containerName string, cmd []string, stdin io.Reader) (string, error) {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Command:   cmd,
+			Container: containerName,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       false,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", err
+	}
+
+	// Create new buffers for the output
+	var stdout, stderr bytes.Buffer
+
+	// Execute the command
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return stderr.String(), err
+	}
+
+	return stdout.String(), nil
+}
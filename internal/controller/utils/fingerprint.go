@@ -15,41 +15,68 @@
 
 package utils
 
-import "fmt"
-
-// TODO: Randomize on startup and sync with alerting system
-const KoneyFingerprint = 1337
-
-// EncodeFingerprintInEcho encodes a fingerprint in a call to `echo`, to be
-// used, e.g. in a call such as `echo -e "foobar\c KONEY_FINGERPRINT_123"` after
-// the `\c` escape sequence. Everything after the `\c` escape sequence is
-// ignored by `echo` when `-e` is used, thus, the command will still work as
-// expected. This is useful to mark `echo` calls from Koney, so that we won't
-// alert on them later.
-func EncodeFingerprintInEcho(code int) string {
-	return fmt.Sprintf("KONEY_FINGERPRINT_%d", code)
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+// koneyFingerprint is loaded once on startup, see LoadKoneyFingerprint.
+var koneyFingerprint atomic.Int64
+
+// GetKoneyFingerprint returns the fingerprint that marks commands issued by Koney itself.
+func GetKoneyFingerprint() int {
+	return int(koneyFingerprint.Load())
 }
 
-// EncodeFingerprintInCat encodes a fingerprint in a call to `cat`, to be used,
-// e.g. in a call such as `cat -u -uu -u -u -uu /foo/bar` where the `-u` flag is
-// used to binary-encode the fingerprint (`-u` is 0, `-uu` is 1). The `-u` flag
-// is ignored by `cat`, thus, the command will still work as expected. This is
-// useful to mark `cat` calls from Koney, so that we won't alert on them later.
-func EncodeFingerprintInCat(code int) string {
-	binaryCode := fmt.Sprintf("%b", code)
-
-	// iterate and replace 0 with -u and 1 with -uu
-	cipher := ""
-	for _, bit := range binaryCode {
-		if bit == '0' {
-			cipher += "-u "
-		} else {
-			cipher += "-uu "
+// SetKoneyFingerprint sets the fingerprint that marks commands issued by Koney itself.
+func SetKoneyFingerprint(code int) {
+	koneyFingerprint.Store(int64(code))
+}
+
+// LoadKoneyFingerprint reads the fingerprint from its secret in the Koney namespace, which the alert forwarder reads, too.
+// If the secret does not exist yet (e.g., when Koney was not installed with Helm), it is created with a random fingerprint.
+func LoadKoneyFingerprint(ctx context.Context, reader client.Reader, writer client.Writer) (int, error) {
+	key := types.NamespacedName{Namespace: GetKoneyNamespace(), Name: alerts.FingerprintSecretName}
+
+	secret := &corev1.Secret{}
+	err := reader.Get(ctx, key, secret)
+	if apierrors.IsNotFound(err) {
+		code, err := alerts.NewFingerprint()
+		if err != nil {
+			return 0, err
 		}
-	}
 
-	// remove the trailing space
-	cipher = cipher[:len(cipher)-1]
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			StringData: map[string]string{alerts.FingerprintSecretKey: strconv.Itoa(code)},
+		}
+		if err := writer.Create(ctx, secret); err == nil {
+			SetKoneyFingerprint(code)
+			return code, nil
+		} else if !apierrors.IsAlreadyExists(err) {
+			return 0, err
+		}
 
-	return cipher
+		// another replica created the secret in the meantime
+		err = reader.Get(ctx, key, secret)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	code, err := alerts.ParseFingerprint(string(secret.Data[alerts.FingerprintSecretKey]))
+	if err != nil {
+		return 0, err
+	}
+	SetKoneyFingerprint(code)
+	return code, nil
 }
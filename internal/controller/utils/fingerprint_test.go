@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+var _ = Describe("LoadKoneyFingerprint", func() {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: GetKoneyNamespace(), Name: alerts.FingerprintSecretName}
+
+	It("should read the fingerprint from the secret", func() {
+		fakeClient := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Data:       map[string][]byte{alerts.FingerprintSecretKey: []byte("424242")},
+		}).Build()
+
+		code, err := LoadKoneyFingerprint(ctx, fakeClient, fakeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(424242))
+		Expect(GetKoneyFingerprint()).To(Equal(424242))
+	})
+
+	It("should create the secret with a random fingerprint if it does not exist", func() {
+		fakeClient := fake.NewClientBuilder().Build()
+
+		code, err := LoadKoneyFingerprint(ctx, fakeClient, fakeClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(GetKoneyFingerprint()).To(Equal(code))
+
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.StringData).To(HaveKeyWithValue(alerts.FingerprintSecretKey, strconv.Itoa(code)))
+	})
+
+	It("should fail on an invalid fingerprint", func() {
+		fakeClient := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Data:       map[string][]byte{alerts.FingerprintSecretKey: []byte("not-a-number")},
+		}).Build()
+
+		_, err := LoadKoneyFingerprint(ctx, fakeClient, fakeClient)
+		Expect(err).To(HaveOccurred())
+	})
+})
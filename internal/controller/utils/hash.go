@@ -17,6 +17,7 @@ package utils
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
 )
 
@@ -25,3 +26,9 @@ func Hash(input string) string {
 	hash := md5.Sum([]byte(input))
 	return fmt.Sprintf("%x", hash)
 }
+
+// SHA256 returns the SHA-256 checksum of the input string in hexadecimal format.
+func SHA256(input string) string {
+	checksum := sha256.Sum256([]byte(input))
+	return fmt.Sprintf("%x", checksum)
+}
@@ -49,3 +49,25 @@ func MatchContainerName(pattern string, containerName string) (bool, error) {
 	// Direct comparison
 	return pattern == containerName, nil
 }
+
+// MatchContainerImage reports whether the image reference of a container matches the pattern, with the same syntax as MatchContainerName.
+// Regex patterns are searched in the image reference and glob patterns are matched against the full image reference,
+// where a "*" does not match the "/" between the path components of the image.
+// Exact patterns match the full image reference, or its repository without the tag and digest (e.g., "postgres" matches "postgres:16").
+func MatchContainerImage(pattern string, image string) (bool, error) {
+	if strings.HasPrefix(pattern, "regex:") || strings.HasPrefix(pattern, "glob:") {
+		return MatchContainerName(pattern, image)
+	}
+
+	return pattern == "" || pattern == image || pattern == ImageRepository(image), nil
+}
+
+// ImageRepository returns the repository of an image reference, i.e., the reference without its tag and digest.
+func ImageRepository(image string) string {
+	repository, _, _ := strings.Cut(image, "@")
+	// A colon after the last slash separates the tag, other colons separate the port of the registry
+	if index := strings.LastIndex(repository, ":"); index > strings.LastIndex(repository, "/") {
+		repository = repository[:index]
+	}
+	return repository
+}
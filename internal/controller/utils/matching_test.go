@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MatchContainerImage", func() {
+	It("should match the image reference or its repository exactly", func() {
+		Expect(MatchContainerImage("postgres", "postgres:16")).To(BeTrue())
+		Expect(MatchContainerImage("postgres:16", "postgres:16")).To(BeTrue())
+		Expect(MatchContainerImage("postgres", "docker.io/library/postgres:16")).To(BeFalse())
+		Expect(MatchContainerImage("registry:5000/db/postgres", "registry:5000/db/postgres@sha256:abc")).To(BeTrue())
+		Expect(MatchContainerImage("postgres", "postgres-exporter:1")).To(BeFalse())
+	})
+
+	It("should match regex and glob patterns", func() {
+		Expect(MatchContainerImage("regex:(^|/)postgres(:|@|$)", "docker.io/library/postgres:16")).To(BeTrue())
+		Expect(MatchContainerImage("glob:*/*/postgres:*", "docker.io/library/postgres:16")).To(BeTrue())
+		Expect(MatchContainerImage("glob:*/*/postgres:*", "docker.io/library/redis:7")).To(BeFalse())
+		Expect(MatchContainerImage("glob:*/postgres:*", "docker.io/library/postgres:16")).To(BeFalse())
+	})
+
+	It("should match all images without a pattern", func() {
+		Expect(MatchContainerImage("", "redis:7")).To(BeTrue())
+	})
+})
+
+var _ = Describe("ImageRepository", func() {
+	It("should strip the tag and digest", func() {
+		Expect(ImageRepository("nginx")).To(Equal("nginx"))
+		Expect(ImageRepository("nginx:1.27")).To(Equal("nginx"))
+		Expect(ImageRepository("ghcr.io/org/app@sha256:abc")).To(Equal("ghcr.io/org/app"))
+		Expect(ImageRepository("localhost:5000/app:v1")).To(Equal("localhost:5000/app"))
+		Expect(ImageRepository("localhost:5000/app")).To(Equal("localhost:5000/app"))
+	})
+})
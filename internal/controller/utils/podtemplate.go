@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+// MarkInjectedContainers records the given containers, which were injected by Koney, in the injected containers annotation
+// of a pod template, so that autoscalers and other tooling can exclude them from the host workload (see ExcludeInjectedContainersFromAutoscalers).
+func MarkInjectedContainers(template *corev1.PodTemplateSpec, containerNames []string) {
+	if len(containerNames) == 0 {
+		return
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+
+	injected := GetInjectedContainers(template)
+	for _, containerName := range containerNames {
+		if !Contains(injected, containerName) {
+			injected = append(injected, containerName)
+		}
+	}
+	slices.Sort(injected)
+	template.Annotations[constants.AnnotationKeyInjectedContainers] = strings.Join(injected, ",")
+}
+
+// UnmarkInjectedContainers reverts MarkInjectedContainers for the given containers,
+// e.g., after Koney removed the injected containers again.
+func UnmarkInjectedContainers(template *corev1.PodTemplateSpec, containerNames []string) {
+	injected := []string{}
+	for _, containerName := range GetInjectedContainers(template) {
+		if !Contains(containerNames, containerName) {
+			injected = append(injected, containerName)
+		}
+	}
+
+	if len(injected) == 0 {
+		delete(template.Annotations, constants.AnnotationKeyInjectedContainers)
+	} else {
+		template.Annotations[constants.AnnotationKeyInjectedContainers] = strings.Join(injected, ",")
+	}
+}
+
+// GetInjectedContainers returns the names of the containers that Koney injected into a pod template.
+func GetInjectedContainers(template *corev1.PodTemplateSpec) []string {
+	return splitContainerList(template.Annotations[constants.AnnotationKeyInjectedContainers])
+}
+
+func splitContainerList(value string) []string {
+	containerNames := []string{}
+	for _, containerName := range strings.Split(value, ",") {
+		if containerName = strings.TrimSpace(containerName); containerName != "" {
+			containerNames = append(containerNames, containerName)
+		}
+	}
+	return containerNames
+}
+
+// ResourceKind returns the kind of a resource that Koney places traps in (e.g., Pod or Deployment).
+func ResourceKind(resource client.Object) string {
+	switch resource.(type) {
+	case *corev1.Pod:
+		return "Pod"
+	case *appsv1.Deployment:
+		return "Deployment"
+	default:
+		return resource.GetObjectKind().GroupVersionKind().Kind
+	}
+}
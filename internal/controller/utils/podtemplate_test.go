@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("MarkInjectedContainers", func() {
+	var template corev1.PodTemplateSpec
+
+	BeforeEach(func() {
+		template = corev1.PodTemplateSpec{}
+	})
+
+	It("should record the injected containers", func() {
+		MarkInjectedContainers(&template, []string{"koney-b", "koney-a"})
+		Expect(template.Annotations).To(HaveKeyWithValue(constants.AnnotationKeyInjectedContainers, "koney-a,koney-b"))
+		Expect(GetInjectedContainers(&template)).To(Equal([]string{"koney-a", "koney-b"}))
+	})
+
+	It("should merge with previously injected containers", func() {
+		MarkInjectedContainers(&template, []string{"koney-a"})
+		MarkInjectedContainers(&template, []string{"koney-b", "koney-a"})
+		Expect(GetInjectedContainers(&template)).To(Equal([]string{"koney-a", "koney-b"}))
+	})
+
+	It("should keep the other annotations of the pod template", func() {
+		template.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{"team": "web"}}
+		MarkInjectedContainers(&template, []string{"koney-a"})
+		Expect(template.Annotations).To(Equal(map[string]string{"team": "web", constants.AnnotationKeyInjectedContainers: "koney-a"}))
+	})
+
+	It("should remove the annotation when all injected containers are unmarked", func() {
+		MarkInjectedContainers(&template, []string{"koney-a", "koney-b"})
+		UnmarkInjectedContainers(&template, []string{"koney-a"})
+		Expect(GetInjectedContainers(&template)).To(Equal([]string{"koney-b"}))
+		UnmarkInjectedContainers(&template, []string{"koney-b"})
+		Expect(template.Annotations).NotTo(HaveKey(constants.AnnotationKeyInjectedContainers))
+	})
+})
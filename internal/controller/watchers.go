@@ -19,12 +19,16 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+	"github.com/dynatrace-oss/koney/internal/controller/matching"
+	"github.com/dynatrace-oss/koney/internal/controller/templates"
 )
 
 func HandleWatchEvent(r client.Reader, ctx context.Context, obj client.Object) []reconcile.Request {
@@ -59,11 +63,136 @@ func HandleWatchEvent(r client.Reader, ctx context.Context, obj client.Object) [
 	return reconcileRequests
 }
 
+// HandleTrapTemplateEvent maps a TrapTemplate to the DeceptionPolicies with traps that reference it.
+func HandleTrapTemplateEvent(r client.Reader, ctx context.Context, obj client.Object) []reconcile.Request {
+	log := k8slog.FromContext(ctx)
+
+	deceptionPolicies, err := listAllDeceptionPolicies(r, ctx)
+	if err != nil {
+		log.Error(err, "Unable to list DeceptionPolicies while watching TrapTemplate changes")
+		return []reconcile.Request{}
+	}
+
+	reconcileRequests := []reconcile.Request{}
+	for _, deceptionPolicy := range deceptionPolicies {
+		if !templates.ReferencesTrapTemplate(&deceptionPolicy, obj.GetName()) {
+			continue
+		}
+
+		policyName := types.NamespacedName{Name: deceptionPolicy.Name, Namespace: deceptionPolicy.Namespace}
+		reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: policyName})
+		log.Info(fmt.Sprintf("Sending reconcile request to %v (triggered by watching TrapTemplate %s) ...", deceptionPolicy.Name, obj.GetName()))
+	}
+
+	return reconcileRequests
+}
+
+// HandlePodEvent maps a pod to the DeceptionPolicies that need to be reconciled because of it, i.e., the ones with traps
+// that are deployed into the containers of pods (rather than into the pod templates of deployments) and that match the pod,
+// and the ones that already placed decoys in the pod. Paused DeceptionPolicies are skipped, since they would not change anything.
+func HandlePodEvent(r client.Reader, ctx context.Context, obj client.Object) []reconcile.Request {
+	log := k8slog.FromContext(ctx)
+	resourceName := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.GetDeletionTimestamp() != nil {
+		// Ignore objects that are about to be deleted
+		return []reconcile.Request{}
+	}
+
+	deceptionPolicies, err := listAllDeceptionPolicies(r, ctx)
+	if err != nil {
+		log.Error(err, "Unable to list DeceptionPolicies while watching pod changes")
+		return []reconcile.Request{}
+	}
+
+	reconcileRequests := []reconcile.Request{}
+	for _, deceptionPolicy := range deceptionPolicies {
+		if deceptionPolicy.IsPaused() {
+			continue
+		}
+
+		matched, err := podNeedsDeceptionPolicy(r, ctx, pod, &deceptionPolicy)
+		if err != nil {
+			log.Error(err, "Unable to match pod against DeceptionPolicy", "DeceptionPolicy", deceptionPolicy.Name, "pod", resourceName)
+			matched = true // Rather reconcile once too often than missing a pod
+		}
+		if !matched {
+			continue
+		}
+
+		policyName := types.NamespacedName{Name: deceptionPolicy.Name, Namespace: deceptionPolicy.Namespace}
+		reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: policyName})
+		log.Info(fmt.Sprintf("Sending reconcile request to %v (triggered by watching pod %s) ...", deceptionPolicy.Name, resourceName))
+	}
+
+	return reconcileRequests
+}
+
+// podNeedsDeceptionPolicy returns true if a DeceptionPolicy already placed decoys in a pod,
+// or if any of its traps that are deployed into the containers of pods matches the pod.
+// Node constraints are not considered, so pods on other nodes might match as well.
+func podNeedsDeceptionPolicy(r client.Reader, ctx context.Context, pod *corev1.Pod, deceptionPolicy *v1alpha1.DeceptionPolicy) (bool, error) {
+	annotationChange, err := annotations.GetAnnotationChange(pod, deceptionPolicy.Name)
+	if err != nil {
+		return false, err
+	} else if len(annotationChange.Traps) > 0 {
+		return true, nil
+	}
+
+	// Traps that reference a TrapTemplate only tell their strategy once they are expanded
+	// (traps that cannot be expanded are invalid and would not be deployed anyway, so the error can be ignored)
+	expandedPolicy := deceptionPolicy.DeepCopy()
+	_, _ = templates.ExpandTrapTemplates(r, ctx, expandedPolicy)
+
+	for _, trap := range expandedPolicy.Spec.Traps {
+		switch trap.DecoyDeployment.Strategy {
+		case "containerExec", "ephemeralContainer", "admission", "auto":
+			containers, err := matching.GetMatchingContainersOfPod(r, ctx, pod, trap.MatchResources)
+			if err != nil {
+				return false, err
+			} else if len(containers) > 0 {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// containersBecameReady returns true if a container of a pod became ready or restarted between two versions of the pod,
+// e.g., because the pod just started, so that decoys can (or need to) be deployed into the container now.
+func containersBecameReady(oldPod, newPod *corev1.Pod) bool {
+	for _, newStatus := range newPod.Status.ContainerStatuses {
+		if !newStatus.Ready || newStatus.State.Running == nil {
+			continue
+		}
+
+		wasReady := false
+		for _, oldStatus := range oldPod.Status.ContainerStatuses {
+			if oldStatus.Name == newStatus.Name {
+				wasReady = oldStatus.Ready && oldStatus.RestartCount == newStatus.RestartCount
+				break
+			}
+		}
+		if !wasReady {
+			return true
+		}
+	}
+
+	return false
+}
+
 func listAllDeceptionPolicies(r client.Reader, ctx context.Context) ([]v1alpha1.DeceptionPolicy, error) {
 	deceptionPolicyList := v1alpha1.DeceptionPolicyList{}
 	if err := r.List(ctx, &deceptionPolicyList); err != nil {
 		return nil, err
 	}
 
+	// Policies that were not admitted by the defaulting webhook lack the defaults, which are only applied in memory
+	for i := range deceptionPolicyList.Items {
+		deceptionPolicyList.Items[i].ApplyDefaults()
+	}
+
 	return deceptionPolicyList.Items, nil
 }
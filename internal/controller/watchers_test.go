@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+)
+
+var _ = Describe("Pod watches", func() {
+	withContainerStatus := func(ready bool, restartCount int32) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo"}}
+		status := corev1.ContainerStatus{Name: "app", Ready: ready, RestartCount: restartCount}
+		if ready {
+			status.State.Running = &corev1.ContainerStateRunning{}
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{status}
+		return pod
+	}
+
+	Context("When pods change", func() {
+		It("should detect containers that became ready", func() {
+			Expect(containersBecameReady(&corev1.Pod{}, withContainerStatus(true, 0))).To(BeTrue())
+			Expect(containersBecameReady(withContainerStatus(false, 0), withContainerStatus(true, 0))).To(BeTrue())
+		})
+
+		It("should detect containers that restarted", func() {
+			Expect(containersBecameReady(withContainerStatus(true, 0), withContainerStatus(true, 1))).To(BeTrue())
+		})
+
+		It("should ignore containers that did not change or are not ready", func() {
+			Expect(containersBecameReady(withContainerStatus(true, 1), withContainerStatus(true, 1))).To(BeFalse())
+			Expect(containersBecameReady(withContainerStatus(true, 0), withContainerStatus(false, 1))).To(BeFalse())
+		})
+	})
+
+	Context("When mapping pods to deception policies", func() {
+		var (
+			ctx             context.Context
+			pod             *corev1.Pod
+			deceptionPolicy *v1alpha1.DeceptionPolicy
+		)
+
+		BeforeEach(func() {
+			ctx = context.TODO()
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "koney-demo", Labels: map[string]string{"app": "api"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			}
+			deceptionPolicy = &v1alpha1.DeceptionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-sample"},
+				Spec: v1alpha1.DeceptionPolicySpec{
+					Traps: []v1alpha1.Trap{{
+						FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/service_token", FileContent: "token"},
+						DecoyDeployment:      v1alpha1.DecoyDeployment{Strategy: "containerExec"},
+						MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{{
+							ResourceDescription: v1alpha1.ResourceDescription{Namespaces: []string{"koney-demo"}},
+						}}},
+					}},
+				},
+			}
+		})
+
+		It("should map pods that match traps deployed into pods", func() {
+			matched, err := podNeedsDeceptionPolicy(fake.NewClientBuilder().Build(), ctx, pod, deceptionPolicy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matched).To(BeTrue())
+		})
+
+		It("should not map pods for traps deployed into deployments", func() {
+			deceptionPolicy.Spec.Traps[0].DecoyDeployment.Strategy = "volumeMount"
+
+			matched, err := podNeedsDeceptionPolicy(fake.NewClientBuilder().Build(), ctx, pod, deceptionPolicy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matched).To(BeFalse())
+		})
+
+		It("should map pods that already have decoys of the deception policy", func() {
+			trap := deceptionPolicy.Spec.Traps[0]
+			deceptionPolicy.Spec.Traps = nil
+			Expect(annotations.AddTrapToAnnotations(pod, deceptionPolicy.Name, trap, []string{"app"})).To(Succeed())
+
+			matched, err := podNeedsDeceptionPolicy(fake.NewClientBuilder().Build(), ctx, pod, deceptionPolicy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matched).To(BeTrue())
+		})
+	})
+})
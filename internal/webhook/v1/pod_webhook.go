@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/matching"
+	"github.com/dynatrace-oss/koney/internal/controller/templates"
+	"github.com/dynatrace-oss/koney/internal/controller/traps/filesystoken"
+)
+
+// SetupPodWebhookWithManager registers the webhook for Pods in the manager.
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Pod{}).
+		WithDefaulter(&PodCustomDefaulter{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=NoneOnDryRun,groups="",resources=pods,verbs=create,versions=v1,name=mpod-v1.kb.io,admissionReviewVersions=v1
+
+// PodCustomDefaulter injects the decoys of all traps with the admission strategy into pods when they are created.
+// Errors never reject pods, because traps should not interfere with the workloads that they protect.
+type PodCustomDefaulter struct {
+	Client client.Client
+}
+
+var _ webhook.CustomDefaulter = &PodCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Pod kind.
+func (d *PodCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	log := k8slog.FromContext(ctx)
+
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+
+	dryRun := false
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		// Pods created by controllers usually have no namespace set when they are admitted
+		if pod.Namespace == "" {
+			pod.Namespace = req.Namespace
+		}
+		dryRun = req.DryRun != nil && *req.DryRun
+	}
+
+	deceptionPolicies := &v1alpha1.DeceptionPolicyList{}
+	if err := d.Client.List(ctx, deceptionPolicies); err != nil {
+		log.Error(err, "unable to list DeceptionPolicies - admitting pod without decoys")
+		return nil
+	}
+
+	now := time.Now()
+	for i := range deceptionPolicies.Items {
+		deceptionPolicy := &deceptionPolicies.Items[i]
+		if deceptionPolicy.DeletionTimestamp != nil || deceptionPolicy.IsPaused() || deceptionPolicy.IsDryRun() {
+			continue
+		}
+
+		// Defaults are never persisted in the spec, so we apply them in memory like the controller does
+		deceptionPolicy.ApplyDefaults()
+
+		// Traps that reference a TrapTemplate are expanded like the controller does, the others are still injected
+		if _, err := templates.ExpandTrapTemplates(d.Client, ctx, deceptionPolicy); err != nil {
+			log.Error(err, "unable to expand TrapTemplates", "DeceptionPolicy", deceptionPolicy.Name)
+		}
+
+		for _, trap := range admissionTraps(ctx, d.Client, deceptionPolicy, now) {
+			containers, err := matching.GetMatchingContainersOfPod(d.Client, ctx, pod, trap.MatchResources)
+			if err != nil {
+				log.Error(err, "unable to match pod", "DeceptionPolicy", deceptionPolicy.Name)
+				continue
+			} else if len(containers) == 0 {
+				continue
+			}
+
+			injectedContainers, err := filesystoken.InjectDecoyIntoPod(d.Client, ctx, deceptionPolicy, trap, pod, containers, dryRun)
+			if err != nil {
+				log.Error(err, "unable to inject FilesystemHoneytoken trap into pod", "DeceptionPolicy", deceptionPolicy.Name)
+			} else if len(injectedContainers) > 0 {
+				log.Info("FilesystemHoneytoken trap injected into pod", "DeceptionPolicy", deceptionPolicy.Name,
+					"namespace", pod.Namespace, "containers", injectedContainers, "filePath", trap.FilesystemHoneytoken.FilePath)
+			}
+		}
+	}
+
+	return nil
+}
+
+// admissionTraps returns the valid and unexpired traps of a DeceptionPolicy with the admission strategy,
+// expanded per file path and with the content of the current rotation generation and the provisioned canary tokens,
+// like the controller deploys them.
+func admissionTraps(ctx context.Context, c client.Reader, deceptionPolicy *v1alpha1.DeceptionPolicy, now time.Time) []v1alpha1.Trap {
+	traps := []v1alpha1.Trap{}
+	for _, trap := range deceptionPolicy.Spec.Traps {
+		if trap.DecoyDeployment.Strategy != "admission" || trap.TrapType() != v1alpha1.FilesystemHoneytokenTrap {
+			continue
+		}
+		if trap.IsValid() != nil {
+			continue
+		}
+		if trapHash, err := filesystoken.TrapHash(trap); err == nil && trap.IsExpired(deceptionPolicy.Status.TrapDeployedAt(trapHash), now) {
+			continue
+		}
+		traps = append(traps, trap)
+	}
+
+	// Decoys whose canary token the controller did not provision yet would embed the placeholders instead
+	resolvedTraps := []v1alpha1.Trap{}
+	for _, trap := range filesystoken.ExpandFilePaths(traps) {
+		resolvedTrap, err := filesystoken.ResolveCanaryToken(c, ctx, deceptionPolicy, trap)
+		if err != nil {
+			continue
+		}
+		resolvedTraps = append(resolvedTraps, resolvedTrap)
+	}
+
+	return filesystoken.ResolveRotatedTraps(deceptionPolicy, resolvedTraps, now)
+}
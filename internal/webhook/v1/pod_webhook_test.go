@@ -0,0 +1,208 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/internal/controller/annotations"
+	"github.com/dynatrace-oss/koney/internal/controller/constants"
+)
+
+var _ = Describe("PodCustomDefaulter", func() {
+	const FilePath = "/run/secrets/koney/service_token"
+
+	var (
+		ctx             context.Context
+		scheme          *runtime.Scheme
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+		pod             *corev1.Pod
+	)
+
+	newDefaulter := func(funcs interceptor.Funcs) *PodCustomDefaulter {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deceptionPolicy).WithInterceptorFuncs(funcs).Build()
+		return &PodCustomDefaulter{Client: fakeClient}
+	}
+
+	listSecrets := func(defaulter *PodCustomDefaulter) []corev1.Secret {
+		secrets := &corev1.SecretList{}
+		Expect(defaulter.Client.List(ctx, secrets)).To(Succeed())
+		return secrets.Items
+	}
+
+	expectInjected := func(pod *corev1.Pod) {
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(HaveLen(1))
+		Expect(pod.Spec.Containers[0].VolumeMounts[0].MountPath).To(Equal(FilePath))
+
+		changes, err := annotations.GetAnnotationChange(pod, deceptionPolicy.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes.Traps).To(HaveLen(1))
+		Expect(changes.Traps[0].Containers).To(Equal([]string{"app"}))
+	}
+
+	expectUnchanged := func(pod *corev1.Pod) {
+		Expect(pod.Spec.Volumes).To(BeEmpty())
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(BeEmpty())
+		Expect(pod.Annotations).To(BeEmpty())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-admission"},
+			Spec: v1alpha1.DeceptionPolicySpec{Traps: []v1alpha1.Trap{{
+				FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: FilePath, FileContent: "someverysecrettoken"},
+				MatchResources: v1alpha1.MatchResources{Any: []v1alpha1.ResourceFilter{{
+					ResourceDescription: v1alpha1.ResourceDescription{Namespaces: []string{"koney-demo"}},
+				}}},
+				DecoyDeployment: v1alpha1.DecoyDeployment{Strategy: "admission"},
+			}}},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "koney-demo", Name: "web"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		}
+	})
+
+	Context("when a trap with the admission strategy matches the pod", func() {
+		It("should inject the decoy and create its secret", func() {
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+
+			expectInjected(pod)
+			secrets := listSecrets(defaulter)
+			Expect(secrets).To(HaveLen(1))
+			Expect(secrets[0].Namespace).To(Equal("koney-demo"))
+			Expect(secrets[0].Data).To(HaveKeyWithValue("service_token", []byte("someverysecrettoken")))
+		})
+
+		It("should take the namespace from the admission request if the pod has none", func() {
+			pod.Namespace = ""
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Namespace: "koney-demo"}}
+
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(admission.NewContextWithRequest(ctx, req), pod)).To(Succeed())
+
+			Expect(pod.Namespace).To(Equal("koney-demo"))
+			expectInjected(pod)
+		})
+
+		It("should not create the secret for dry-run requests", func() {
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Namespace: "koney-demo", DryRun: &[]bool{true}[0]}}
+
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(admission.NewContextWithRequest(ctx, req), pod)).To(Succeed())
+
+			expectInjected(pod)
+			Expect(listSecrets(defaulter)).To(BeEmpty())
+		})
+	})
+
+	Context("when no trap should be injected", func() {
+		It("should skip pods that no trap matches", func() {
+			pod.Namespace = "other"
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should skip traps with another decoy deployment strategy", func() {
+			deceptionPolicy.Spec.Traps[0].DecoyDeployment.Strategy = "volumeMount"
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should skip expired traps", func() {
+			deceptionPolicy.Spec.Traps[0].ExpiresAt = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should skip paused deception policies", func() {
+			deceptionPolicy.Annotations = map[string]string{v1alpha1.PausedAnnotation: "true"}
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should skip deception policies in dry-run mode", func() {
+			deceptionPolicy.Spec.DryRun = &[]bool{true}[0]
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should skip deception policies that are being deleted", func() {
+			deceptionPolicy.Finalizers = []string{constants.FinalizerName}
+			deceptionPolicy.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+	})
+
+	Context("when something fails", func() {
+		It("should admit the pod unchanged if the deception policies cannot be listed", func() {
+			defaulter := newDefaulter(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					return errors.New("unavailable")
+				},
+			})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should admit the pod unchanged if the secret cannot be created", func() {
+			defaulter := newDefaulter(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*corev1.Secret); ok {
+						return errors.New("forbidden")
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectUnchanged(pod)
+		})
+
+		It("should reject objects that are no pods", func() {
+			defaulter := newDefaulter(interceptor.Funcs{})
+			Expect(defaulter.Default(ctx, &corev1.Secret{})).ToNot(Succeed())
+		})
+	})
+})
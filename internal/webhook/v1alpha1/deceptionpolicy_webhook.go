@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8slog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// SetupDeceptionPolicyWebhookWithManager registers the webhook for DeceptionPolicy in the manager.
+func SetupDeceptionPolicyWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&v1alpha1.DeceptionPolicy{}).
+		WithDefaulter(&DeceptionPolicyCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-research-dynatrace-com-v1alpha1-deceptionpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=research.dynatrace.com,resources=deceptionpolicies,verbs=create;update,versions=v1alpha1,name=mdeceptionpolicy-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// DeceptionPolicyCustomDefaulter sets default values on DeceptionPolicy resources when they are created or updated.
+// The TrapDefaults of the policy cascade to all traps, followed by the built-in defaults.
+type DeceptionPolicyCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &DeceptionPolicyCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the DeceptionPolicy kind.
+func (d *DeceptionPolicyCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	deceptionPolicy, ok := obj.(*v1alpha1.DeceptionPolicy)
+	if !ok {
+		return fmt.Errorf("expected a DeceptionPolicy object but got %T", obj)
+	}
+
+	if deceptionPolicy.ApplyDefaults() {
+		log := k8slog.FromContext(ctx)
+		log.Info("Applied trap defaults", "DeceptionPolicy", deceptionPolicy.Name)
+	}
+
+	return nil
+}
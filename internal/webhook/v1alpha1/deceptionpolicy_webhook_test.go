@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+var _ = Describe("DeceptionPolicy Webhook", func() {
+	var (
+		ctx             context.Context
+		defaulter       *DeceptionPolicyCustomDefaulter
+		deceptionPolicy *v1alpha1.DeceptionPolicy
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		defaulter = &DeceptionPolicyCustomDefaulter{}
+		deceptionPolicy = &v1alpha1.DeceptionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deceptionpolicy-webhook"},
+			Spec: v1alpha1.DeceptionPolicySpec{
+				Traps: []v1alpha1.Trap{
+					{FilesystemHoneytoken: v1alpha1.FilesystemHoneytoken{FilePath: "/run/secrets/koney/service_token"}},
+				},
+			},
+		}
+	})
+
+	It("should apply the built-in defaults", func() {
+		Expect(defaulter.Default(ctx, deceptionPolicy)).To(Succeed())
+
+		trap := deceptionPolicy.Spec.Traps[0]
+		Expect(trap.DecoyDeployment.Strategy).To(Equal(v1alpha1.DefaultDecoyDeploymentStrategy))
+		Expect(trap.CaptorDeployment.Strategy).To(Equal(v1alpha1.DefaultCaptorDeploymentStrategy))
+		Expect(trap.FilesystemHoneytoken.ReadOnly).To(Equal(&[]bool{true}[0]))
+	})
+
+	It("should cascade the trap defaults", func() {
+		deceptionPolicy.Spec.TrapDefaults = &v1alpha1.TrapDefaults{
+			DecoyDeployment:      &v1alpha1.DecoyDeployment{Strategy: "containerExec"},
+			FilesystemHoneytoken: &v1alpha1.FilesystemHoneytokenDefaults{ReadOnly: &[]bool{false}[0]},
+			Alerting:             &v1alpha1.Alerting{Severity: "HIGH"},
+		}
+
+		Expect(defaulter.Default(ctx, deceptionPolicy)).To(Succeed())
+
+		trap := deceptionPolicy.Spec.Traps[0]
+		Expect(trap.DecoyDeployment.Strategy).To(Equal("containerExec"))
+		Expect(trap.FilesystemHoneytoken.IsReadOnly()).To(BeFalse())
+		Expect(trap.Alerting.Severity).To(Equal("HIGH"))
+	})
+
+	It("should keep the values that are set in the traps", func() {
+		deceptionPolicy.Spec.TrapDefaults = &v1alpha1.TrapDefaults{
+			DecoyDeployment:      &v1alpha1.DecoyDeployment{Strategy: "containerExec"},
+			FilesystemHoneytoken: &v1alpha1.FilesystemHoneytokenDefaults{ReadOnly: &[]bool{false}[0]},
+		}
+		deceptionPolicy.Spec.Traps[0].DecoyDeployment.Strategy = "volumeMount"
+		deceptionPolicy.Spec.Traps[0].FilesystemHoneytoken.ReadOnly = &[]bool{true}[0]
+
+		Expect(defaulter.Default(ctx, deceptionPolicy)).To(Succeed())
+
+		trap := deceptionPolicy.Spec.Traps[0]
+		Expect(trap.DecoyDeployment.Strategy).To(Equal("volumeMount"))
+		Expect(trap.FilesystemHoneytoken.IsReadOnly()).To(BeTrue())
+	})
+
+	It("should not default traps that reference a TrapTemplate", func() {
+		deceptionPolicy.Spec.Traps = []v1alpha1.Trap{
+			{TemplateRef: &v1alpha1.TrapTemplateReference{Name: "aws-credentials"}},
+		}
+
+		Expect(defaulter.Default(ctx, deceptionPolicy)).To(Succeed())
+		Expect(deceptionPolicy.Spec.Traps[0].DecoyDeployment.Strategy).To(BeEmpty())
+	})
+
+	It("should fail for objects that are not a DeceptionPolicy", func() {
+		Expect(defaulter.Default(ctx, &corev1.Pod{})).To(MatchError(ContainSubstring("expected a DeceptionPolicy")))
+	})
+})
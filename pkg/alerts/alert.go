@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package alerts defines the alerts that Koney emits when a trap is accessed.
+// The types are aliases of the latest version of the alert format, so that consumers
+// always decode the alerts of the Koney version they are built with. Consumers that must
+// keep working across upgrades of Koney should import a versioned package (e.g., v1) instead.
+package alerts
+
+import (
+	v1 "github.com/dynatrace-oss/koney/pkg/alerts/v1"
+)
+
+// SchemaVersion is the version of the latest alert format.
+const SchemaVersion = v1.SchemaVersion
+
+// TrapType is the type of trap that an alert was raised for.
+type TrapType = v1.TrapType
+
+const (
+	UnknownTrap              = v1.UnknownTrap
+	FilesystemHoneytokenTrap = v1.FilesystemHoneytokenTrap
+	HttpEndpointTrap         = v1.HttpEndpointTrap
+	HttpPayloadTrap          = v1.HttpPayloadTrap
+)
+
+// Severity is the severity of an alert.
+type Severity = v1.Severity
+
+const (
+	SeverityCritical = v1.SeverityCritical
+	SeverityHigh     = v1.SeverityHigh
+	SeverityMedium   = v1.SeverityMedium
+	SeverityLow      = v1.SeverityLow
+	SeverityInfo     = v1.SeverityInfo
+)
+
+// Confidence is how likely the access that raised an alert is malicious.
+type Confidence = v1.Confidence
+
+const (
+	ConfidenceHigh   = v1.ConfidenceHigh
+	ConfidenceMedium = v1.ConfidenceMedium
+	ConfidenceLow    = v1.ConfidenceLow
+)
+
+// The alert and its parts, see package v1 for their documentation.
+type (
+	KoneyAlert              = v1.KoneyAlert
+	ResponseMetadata        = v1.ResponseMetadata
+	TrapMetadata            = v1.TrapMetadata
+	ContainerMetadata       = v1.ContainerMetadata
+	OwnerMetadata           = v1.OwnerMetadata
+	PodMetadata             = v1.PodMetadata
+	CloudMetadata           = v1.CloudMetadata
+	NodeMetadata            = v1.NodeMetadata
+	ProcessMetadata         = v1.ProcessMetadata
+	CredentialsMetadata     = v1.CredentialsMetadata
+	NamespaceMetadata       = v1.NamespaceMetadata
+	AncestorProcessMetadata = v1.AncestorProcessMetadata
+	ClusterMetadata         = v1.ClusterMetadata
+)
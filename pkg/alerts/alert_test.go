@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// forwarderSourceDir is where the sources of the alert forwarder live.
+// The forwarder is written in Python, so we check that it did not drift from this package.
+var forwarderSourceDir = filepath.Join("..", "..", "alert-forwarder", "forwarder")
+
+var _ = Describe("KoneyAlert", func() {
+	It("should decode an alert emitted by the alert forwarder", func() {
+		alertJSON := `{
+			"schema_version": "1.1",
+			"timestamp": "2025-01-03T18:47:56Z",
+			"deception_policy_name": "deceptionpolicy-servicetoken",
+			"trap_type": "filesystem_honeytoken",
+			"severity": "CRITICAL",
+			"confidence": "high",
+			"tags": {"team": "blue"},
+			"trap": {"hash": "0b4a1bd5", "deception_policy_generation": 2, "description": null, "attck_techniques": ["T1552.001"], "engage_activities": ["EAC0005"]},
+			"metadata": {"file_path": "/run/secrets/koney/service_token"},
+			"pod": {"name": "nginx-1", "namespace": "koney-demo", "container": {"id": "e19c", "name": "nginx", "image_digest": "sha256:5f4e"}, "owner": {"kind": "Deployment", "name": "nginx"}, "labels": {"app": "nginx"}},
+			"node": {"name": "minikube", "cloud": {"provider": "aws", "region": "eu-west-1", "zone": null, "instance_type": "m5.large"}},
+			"process": {"uid": 0, "pid": 148373, "cwd": "/", "binary": "/usr/bin/cat", "arguments": "/run/secrets/koney/service_token", "exec_id": "bWluaWt1YmU6MTQ4Mzcz", "credentials": {"uid": 1000, "gid": 1000, "euid": 0, "egid": 0}, "capabilities": ["CAP_SYS_ADMIN"], "namespaces": {"pid": {"inum": 4026531836, "is_host": true}}, "ancestors": [{"pid": 148370, "binary": "/bin/sh", "arguments": "", "exec_id": null}]},
+			"response": {"actions": ["networkIsolate"], "quarantine_ttl": "1h0m0s"},
+			"cluster": {"name": "prod-eu"}
+		}`
+
+		var alert KoneyAlert
+		Expect(json.Unmarshal([]byte(alertJSON), &alert)).To(Succeed())
+		Expect(alert.SchemaVersion).To(Equal(SchemaVersion))
+		Expect(*alert.DeceptionPolicyName).To(Equal("deceptionpolicy-servicetoken"))
+		Expect(alert.TrapType).To(Equal(FilesystemHoneytokenTrap))
+		Expect(*alert.Severity).To(Equal(SeverityCritical))
+		Expect(*alert.Confidence).To(Equal(ConfidenceHigh))
+		Expect(*alert.Trap.DeceptionPolicyGeneration).To(Equal(int64(2)))
+		Expect(alert.Trap.Description).To(BeNil())
+		Expect(alert.Trap.AttckTechniques).To(Equal([]string{"T1552.001"}))
+		Expect(alert.Trap.EngageActivities).To(Equal([]string{"EAC0005"}))
+		Expect(alert.Pod.Container.Name).To(Equal("nginx"))
+		Expect(alert.Pod.Container.Image).To(BeNil())
+		Expect(*alert.Pod.Container.ImageDigest).To(Equal("sha256:5f4e"))
+		Expect(*alert.Pod.Owner).To(Equal(OwnerMetadata{Kind: "Deployment", Name: "nginx"}))
+		Expect(alert.Pod.Labels).To(HaveKeyWithValue("app", "nginx"))
+		Expect(*alert.Node.Cloud.Region).To(Equal("eu-west-1"))
+		Expect(alert.Node.Cloud.Zone).To(BeNil())
+		Expect(alert.Process.PID).To(Equal(148373))
+		Expect(*alert.Process.ExecID).To(Equal("bWluaWt1YmU6MTQ4Mzcz"))
+		Expect(*alert.Process.Credentials.EUID).To(Equal(0))
+		Expect(alert.Process.Capabilities).To(Equal([]string{"CAP_SYS_ADMIN"}))
+		Expect(alert.Process.Namespaces["pid"].IsHost).To(BeTrue())
+		Expect(alert.Process.Ancestors).To(HaveLen(1))
+		Expect(alert.Process.Ancestors[0].Binary).To(Equal("/bin/sh"))
+		Expect(alert.Process.Ancestors[0].ExecID).To(BeNil())
+		Expect(alert.Response.Actions).To(Equal([]string{"networkIsolate"}))
+		Expect(*alert.Response.QuarantineTTL).To(Equal("1h0m0s"))
+		Expect(alert.Cluster.Name).To(Equal("prod-eu"))
+
+		filePath, ok := alert.FilePath()
+		Expect(ok).To(BeTrue())
+		Expect(filePath).To(Equal("/run/secrets/koney/service_token"))
+	})
+})
+
+var _ = Describe("Alert forwarder", func() {
+	readSource := func(name string) string {
+		source, err := os.ReadFile(filepath.Join(forwarderSourceDir, name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(source)
+	}
+
+	It("should emit the same schema version", func() {
+		source := readSource("types.py")
+		Expect(source).To(ContainSubstring(fmt.Sprintf("SCHEMA_VERSION = %q", SchemaVersion)))
+	})
+
+	It("should use the same trap types", func() {
+		source := readSource("types.py")
+		for _, trapType := range []TrapType{UnknownTrap, FilesystemHoneytokenTrap, HttpEndpointTrap, HttpPayloadTrap} {
+			Expect(source).To(ContainSubstring(fmt.Sprintf("%q", trapType)))
+		}
+	})
+
+	It("should use the same severities", func() {
+		source := readSource("types.py")
+		for _, severity := range []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo} {
+			Expect(source).To(ContainSubstring(fmt.Sprintf("%q", severity)))
+		}
+	})
+
+	It("should use the same confidences", func() {
+		source := readSource("types.py")
+		for _, confidence := range []Confidence{ConfidenceHigh, ConfidenceMedium, ConfidenceLow} {
+			Expect(source).To(ContainSubstring(fmt.Sprintf("%q", confidence)))
+		}
+	})
+
+	It("should read the same fingerprint secret", func() {
+		source := readSource("fingerprint.py")
+		Expect(source).To(ContainSubstring(fmt.Sprintf("FINGERPRINT_SECRET_NAME = %q", FingerprintSecretName)))
+		Expect(source).To(ContainSubstring(fmt.Sprintf("FINGERPRINT_SECRET_KEY = %q", FingerprintSecretKey)))
+	})
+})
+
+var _ = Describe("Fingerprint encoding", func() {
+	It("should encode the fingerprint in echo", func() {
+		Expect(EncodeFingerprintInEcho(1337)).To(Equal("KONEY_FINGERPRINT_1337"))
+	})
+
+	It("should encode the fingerprint in cat", func() {
+		Expect(EncodeFingerprintInCat(5)).To(Equal("-uu -u -uu"))
+	})
+
+	It("should generate random fingerprints", func() {
+		code, err := NewFingerprint()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(BeNumerically(">=", minFingerprint))
+		Expect(code).To(BeNumerically("<", maxFingerprint))
+
+		parsed, err := ParseFingerprint(fmt.Sprintf(" %d\n", code))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(code))
+	})
+
+	It("should reject invalid fingerprints", func() {
+		_, err := ParseFingerprint("1337abc")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseFingerprint("-1")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package alerts
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FingerprintSecretName is the name of the secret in the Koney namespace that stores the fingerprint,
+	// i.e., the code that marks commands issued by Koney itself, so that the alert forwarder can filter them.
+	// The fingerprint is random per installation, so that attackers cannot learn it from the source code.
+	FingerprintSecretName = "koney-fingerprint"
+
+	// FingerprintSecretKey is the key of the fingerprint in the secret.
+	FingerprintSecretKey = "fingerprint"
+
+	// minFingerprint and maxFingerprint bound random fingerprints, so that they are hard to guess
+	// but short enough to be encoded in the arguments of commands.
+	minFingerprint = 1 << 16
+	maxFingerprint = 1 << 31
+)
+
+// NewFingerprint returns a random fingerprint.
+func NewFingerprint() (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(maxFingerprint-minFingerprint))
+	if err != nil {
+		return 0, err
+	}
+	return minFingerprint + int(n.Int64()), nil
+}
+
+// ParseFingerprint parses a fingerprint, as stored in the secret.
+func ParseFingerprint(value string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid fingerprint: %w", err)
+	}
+	if code <= 0 {
+		return 0, fmt.Errorf("invalid fingerprint: %d is not positive", code)
+	}
+	return code, nil
+}
+
+// EncodeFingerprintInEcho encodes a fingerprint in a call to `echo`, to be
+// used, e.g. in a call such as `echo -e "foobar\c KONEY_FINGERPRINT_123"` after
+// the `\c` escape sequence. Everything after the `\c` escape sequence is
+// ignored by `echo` when `-e` is used, thus, the command will still work as
+// expected. This is useful to mark `echo` calls from Koney, so that we won't
+// alert on them later.
+func EncodeFingerprintInEcho(code int) string {
+	return fmt.Sprintf("KONEY_FINGERPRINT_%d", code)
+}
+
+// EncodeFingerprintInCat encodes a fingerprint in a call to `cat`, to be used,
+// e.g. in a call such as `cat -u -uu -u -u -uu /foo/bar` where the `-u` flag is
+// used to binary-encode the fingerprint (`-u` is 0, `-uu` is 1). The `-u` flag
+// is ignored by `cat`, thus, the command will still work as expected. This is
+// useful to mark `cat` calls from Koney, so that we won't alert on them later.
+func EncodeFingerprintInCat(code int) string {
+	binaryCode := fmt.Sprintf("%b", code)
+
+	// iterate and replace 0 with -u and 1 with -uu
+	cipher := ""
+	for _, bit := range binaryCode {
+		if bit == '0' {
+			cipher += "-u "
+		} else {
+			cipher += "-uu "
+		}
+	}
+
+	// remove the trailing space
+	cipher = cipher[:len(cipher)-1]
+
+	return cipher
+}
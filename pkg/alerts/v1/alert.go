@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package v1 defines version 1 of the alerts that Koney emits when a trap is accessed.
+// The alert forwarder emits alerts in exactly this format, so that other controllers
+// and tools can consume them without depending on the forwarder implementation.
+// Consumers that must keep working across upgrades of Koney can import this package
+// to pin the alert format, and validate alerts with the JSON Schema (see JSONSchema).
+package v1
+
+// SchemaVersion is the version of the alert format that this package describes, as emitted in the
+// schema_version field of alerts. Within the same major version, minor versions only add optional fields,
+// so that consumers of an older minor version can still decode the alerts. Breaking changes are
+// published in a new package with the next major version.
+const SchemaVersion = "1.1"
+
+// TrapType is the type of trap that an alert was raised for.
+type TrapType string
+
+const (
+	// UnknownTrap is used if the type of the trap could not be determined.
+	UnknownTrap TrapType = "unknown"
+
+	// FilesystemHoneytokenTrap is a filesystem honeytoken trap.
+	FilesystemHoneytokenTrap TrapType = "filesystem_honeytoken"
+
+	// HttpEndpointTrap is an HTTP endpoint trap.
+	HttpEndpointTrap TrapType = "http_endpoint"
+
+	// HttpPayloadTrap is an HTTP payload trap.
+	HttpPayloadTrap TrapType = "http_payload"
+)
+
+// Severity is the severity of an alert.
+type Severity string
+
+const (
+	SeverityCritical Severity = "CRITICAL"
+	SeverityHigh     Severity = "HIGH"
+	SeverityMedium   Severity = "MEDIUM"
+	SeverityLow      Severity = "LOW"
+	SeverityInfo     Severity = "INFO"
+)
+
+// Confidence is how likely the access that raised an alert is malicious.
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// KoneyAlert is an alert that is emitted when a trap is accessed.
+type KoneyAlert struct {
+	// SchemaVersion is the version of the alert format (see the SchemaVersion constant).
+	// It is empty for alerts that were emitted before the alert format was versioned.
+	SchemaVersion string `json:"schema_version"`
+
+	// Timestamp is the time when the trap was accessed (ISO 8601).
+	Timestamp string `json:"timestamp"`
+
+	// DeceptionPolicyName is the name of the deception policy that created the trap, if it could be resolved.
+	DeceptionPolicyName *string `json:"deception_policy_name"`
+
+	// TrapType is the type of the trap that was accessed.
+	TrapType TrapType `json:"trap_type"`
+
+	// Severity is the severity of the trap, if any. The severity override in the alerting configuration takes precedence.
+	Severity *Severity `json:"severity"`
+
+	// Confidence is the confidence of the trap, if any.
+	Confidence *Confidence `json:"confidence"`
+
+	// Tags are the custom tags of the trap.
+	Tags map[string]string `json:"tags"`
+
+	// Trap is the origin of the trap, as recorded by the controller, if known.
+	Trap *TrapMetadata `json:"trap"`
+
+	// Metadata holds trap-specific metadata, such as the file path for filesystem honeytokens.
+	Metadata map[string]any `json:"metadata"`
+
+	// Pod is the pod in which the trap was accessed, if known.
+	Pod *PodMetadata `json:"pod"`
+
+	// Node is the node on which the trap was accessed, if known.
+	Node *NodeMetadata `json:"node"`
+
+	// Process is the process that accessed the trap, if known.
+	Process *ProcessMetadata `json:"process"`
+
+	// Response is the automated response that was requested for the alert, if the trap has response actions.
+	Response *ResponseMetadata `json:"response"`
+
+	// Cluster is the cluster in which the trap was accessed, if the alert was forwarded by the alert forwarder of another cluster.
+	// It is added in version 1.1 of the alert format.
+	Cluster *ClusterMetadata `json:"cluster,omitempty"`
+}
+
+// ClusterMetadata describes the cluster in which a trap was accessed.
+type ClusterMetadata struct {
+	// Name is the name of the cluster, as configured for its token in the aggregating alert forwarder.
+	Name string `json:"name"`
+}
+
+// ResponseMetadata describes the automated response to an alert.
+// The controller only takes the response actions if the trap still matches the accessing pod.
+type ResponseMetadata struct {
+	// Actions are the response actions of the trap, e.g., "networkIsolate".
+	Actions []string `json:"actions"`
+
+	// QuarantineTTL is how long the pod is quarantined (e.g., "1h0m0s"), if the quarantine is lifted automatically.
+	QuarantineTTL *string `json:"quarantine_ttl"`
+}
+
+// TrapMetadata describes where the trap of an alert comes from.
+type TrapMetadata struct {
+	// Hash is the hash of the originating trap spec.
+	Hash *string `json:"hash"`
+
+	// DeceptionPolicyGeneration is the generation of the deception policy that the captor was deployed from.
+	DeceptionPolicyGeneration *int64 `json:"deception_policy_generation"`
+
+	// Description is the human-readable description of the trap.
+	Description *string `json:"description"`
+
+	// AttckTechniques are the MITRE ATT&CK techniques that the trap detects (e.g., "T1552.001").
+	AttckTechniques []string `json:"attck_techniques"`
+
+	// EngageActivities are the MITRE Engage activities that the trap implements (e.g., "EAC0005").
+	EngageActivities []string `json:"engage_activities"`
+}
+
+// ContainerMetadata describes the container in which a trap was accessed.
+type ContainerMetadata struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Image is the image of the container (e.g., "docker.io/library/nginx:1.27"), if the alert was enriched with it.
+	Image *string `json:"image,omitempty"`
+
+	// ImageDigest is the digest of the image that the container runs (e.g., "sha256:5f4e0b1c..."), if the alert was enriched with it.
+	ImageDigest *string `json:"image_digest,omitempty"`
+}
+
+// OwnerMetadata describes the top-level controller of a pod, e.g., a Deployment instead of its ReplicaSet.
+type OwnerMetadata struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// PodMetadata describes the pod in which a trap was accessed.
+type PodMetadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Container ContainerMetadata `json:"container"`
+
+	// Owner is the top-level controller of the pod, if the alert was enriched with it.
+	Owner *OwnerMetadata `json:"owner,omitempty"`
+
+	// Labels are the labels of the pod, if the alert was enriched with them.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are the annotations of the pod, if the alert was enriched with them.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CloudMetadata describes where a node runs, as far as its cloud provider labeled it.
+type CloudMetadata struct {
+	Provider     *string `json:"provider"`
+	Region       *string `json:"region"`
+	Zone         *string `json:"zone"`
+	InstanceType *string `json:"instance_type"`
+}
+
+// NodeMetadata describes the node on which a trap was accessed.
+type NodeMetadata struct {
+	Name string `json:"name"`
+
+	// Cloud is the cloud metadata of the node, if the alert was enriched with it.
+	Cloud *CloudMetadata `json:"cloud,omitempty"`
+}
+
+// ProcessMetadata describes the process that accessed a trap.
+type ProcessMetadata struct {
+	UID       int    `json:"uid"`
+	PID       int    `json:"pid"`
+	Cwd       string `json:"cwd"`
+	Binary    string `json:"binary"`
+	Arguments string `json:"arguments"`
+
+	// ExecID is the unique id of the process in Tetragon, if known.
+	ExecID *string `json:"exec_id"`
+
+	// Credentials are the user and group ids of the process, if Tetragon runs with --enable-process-cred.
+	Credentials *CredentialsMetadata `json:"credentials"`
+
+	// Capabilities are the effective capabilities of the process (e.g., "CAP_SYS_ADMIN"), if Tetragon reports them.
+	Capabilities []string `json:"capabilities"`
+
+	// Namespaces are the Linux namespaces of the process by their names (e.g., "pid"), if Tetragon runs with --enable-process-ns.
+	Namespaces map[string]NamespaceMetadata `json:"namespaces"`
+
+	// Ancestors is the parent process chain, from the parent to the oldest known ancestor,
+	// if the ProcessAncestryEnrichment feature is enabled.
+	Ancestors []AncestorProcessMetadata `json:"ancestors,omitempty"`
+}
+
+// CredentialsMetadata describes the user and group ids of a process.
+type CredentialsMetadata struct {
+	UID *int `json:"uid"`
+	GID *int `json:"gid"`
+
+	// EUID is the effective user id, which decides about file access.
+	EUID *int `json:"euid"`
+	EGID *int `json:"egid"`
+}
+
+// NamespaceMetadata describes a Linux namespace of a process.
+type NamespaceMetadata struct {
+	// Inum is the inode number of the namespace.
+	Inum *int64 `json:"inum"`
+
+	// IsHost tells whether the process shares the namespace with the host.
+	IsHost bool `json:"is_host"`
+}
+
+// AncestorProcessMetadata describes an ancestor of the process that accessed a trap.
+type AncestorProcessMetadata struct {
+	PID       int     `json:"pid"`
+	Binary    string  `json:"binary"`
+	Arguments string  `json:"arguments"`
+	ExecID    *string `json:"exec_id"`
+}
+
+// FilePath returns the path of the accessed file, if the alert was raised for a filesystem honeytoken.
+func (a *KoneyAlert) FilePath() (string, bool) {
+	filePath, ok := a.Metadata["file_path"].(string)
+	return filePath, ok
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the dialect of the JSON Schema of the alerts.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// enumValues are the allowed values of the string types of the alert format.
+var enumValues = map[reflect.Type][]any{
+	reflect.TypeOf(TrapType("")):   {UnknownTrap, FilesystemHoneytokenTrap, HttpEndpointTrap, HttpPayloadTrap},
+	reflect.TypeOf(Severity("")):   {SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo},
+	reflect.TypeOf(Confidence("")): {ConfidenceHigh, ConfidenceMedium, ConfidenceLow},
+}
+
+// JSONSchema returns the JSON Schema of KoneyAlert, so that consumers in other languages can validate alerts.
+// The schema is derived from the types of this package and published in docs/schemas of the repository.
+// Fields without omitempty are required, fields that can be null in Go can be null in the schema, too,
+// and unknown fields are allowed, since minor versions of the alert format may add optional fields.
+func JSONSchema() ([]byte, error) {
+	defs := map[string]any{}
+	schema, err := structSchema(reflect.TypeOf(KoneyAlert{}), defs)
+	if err != nil {
+		return nil, err
+	}
+
+	// alerts of this package have the same major version, but might have a newer minor version
+	major, _, _ := strings.Cut(SchemaVersion, ".")
+	schema["properties"].(map[string]any)["schema_version"] = map[string]any{
+		"type":    "string",
+		"pattern": fmt.Sprintf(`^%s\.[0-9]+$`, major),
+	}
+
+	schema["$schema"] = JSONSchemaDraft
+	schema["title"] = "KoneyAlert"
+	schema["description"] = fmt.Sprintf("An alert that Koney emits when a trap is accessed (alert format %s).", SchemaVersion)
+	schema["$defs"] = defs
+
+	bytes, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes, '\n'), nil
+}
+
+// structSchema returns the schema of an object with the JSON fields of a struct type.
+// The schemas of nested struct types are added to the definitions and referenced.
+func structSchema(t reflect.Type, defs map[string]any) (map[string]any, error) {
+	properties := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return nil, fmt.Errorf("field %s.%s has no JSON name", t.Name(), field.Name)
+		}
+
+		fieldSchema, err := typeSchema(field.Type, defs)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = fieldSchema
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{"type": "object", "properties": properties, "required": required}, nil
+}
+
+// typeSchema returns the schema of a value of the given type.
+func typeSchema(t reflect.Type, defs map[string]any) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema, err := typeSchema(t.Elem(), defs)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(schema), nil
+
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			schema, err := structSchema(t, defs)
+			if err != nil {
+				return nil, err
+			}
+			defs[t.Name()] = schema
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}, nil
+
+	case reflect.Slice, reflect.Map:
+		elemSchema, err := typeSchema(t.Elem(), defs)
+		if err != nil {
+			return nil, err
+		}
+		// nil slices and maps are encoded as null
+		if t.Kind() == reflect.Slice {
+			return nullable(map[string]any{"type": "array", "items": elemSchema}), nil
+		}
+		return nullable(map[string]any{"type": "object", "additionalProperties": elemSchema}), nil
+
+	case reflect.Interface:
+		return map[string]any{}, nil
+
+	case reflect.String:
+		schema := map[string]any{"type": "string"}
+		if values, ok := enumValues[t]; ok {
+			schema["enum"] = values
+		}
+		return schema, nil
+
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}, nil
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+
+	default:
+		return nil, fmt.Errorf("type %s is not supported in the JSON Schema", t)
+	}
+}
+
+// nullable returns a schema that also allows null.
+func nullable(schema map[string]any) map[string]any {
+	if _, ok := schema["$ref"]; ok {
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+
+	schema["type"] = []any{schema["type"], "null"}
+	if values, ok := schema["enum"].([]any); ok {
+		schema["enum"] = append(values, nil)
+	}
+	return schema
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// publishedSchemaPath is where the JSON Schema is published for consumers.
+// Run `make generate` to update it after changing the alert format.
+var publishedSchemaPath = filepath.Join("..", "..", "..", "docs", "schemas", "koney-alert.v1.schema.json")
+
+var _ = Describe("JSONSchema", func() {
+	var schema map[string]any
+
+	BeforeEach(func() {
+		schemaJSON, err := JSONSchema()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(json.Unmarshal(schemaJSON, &schema)).To(Succeed())
+	})
+
+	It("should match the published schema", func() {
+		schemaJSON, err := JSONSchema()
+		Expect(err).NotTo(HaveOccurred())
+
+		published, err := os.ReadFile(publishedSchemaPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(published)).To(Equal(string(schemaJSON)), "run 'make generate' to update the published schema")
+	})
+
+	It("should pin the major schema version", func() {
+		properties := schema["properties"].(map[string]any)
+		Expect(properties["schema_version"]).To(HaveKeyWithValue("pattern", `^1\.[0-9]+$`))
+		Expect(schema["required"]).To(ContainElement("schema_version"))
+	})
+
+	It("should require fields without omitempty only", func() {
+		defs := schema["$defs"].(map[string]any)
+		container := defs["ContainerMetadata"].(map[string]any)
+		Expect(container["required"]).To(ContainElement("name"))
+		Expect(container["required"]).NotTo(ContainElement("image"))
+	})
+
+	It("should allow null for optional values", func() {
+		properties := schema["properties"].(map[string]any)
+		Expect(properties["severity"]).To(HaveKeyWithValue("type", ConsistOf("string", "null")))
+		Expect(properties["severity"]).To(HaveKeyWithValue("enum", ContainElements(string(SeverityCritical), nil)))
+		Expect(properties["trap_type"]).To(HaveKeyWithValue("enum", ContainElement(string(FilesystemHoneytokenTrap))))
+		Expect(properties["pod"]).To(HaveKey("anyOf"))
+	})
+})
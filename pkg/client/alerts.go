@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+const (
+	// DefaultAlertsPodLabelSelector selects the pods that run the alert forwarder.
+	DefaultAlertsPodLabelSelector = "control-plane=controller-manager"
+
+	// DefaultAlertsContainerName is the container of the alert forwarder that writes alerts to stdout.
+	DefaultAlertsContainerName = "alerts"
+)
+
+// AlertStreamOptions configures where alerts are read from.
+type AlertStreamOptions struct {
+	// Namespace is the namespace where Koney is installed. Defaults to DefaultKoneyNamespace.
+	Namespace string
+	// LabelSelector selects the pods that run the alert forwarder. Defaults to DefaultAlertsPodLabelSelector.
+	LabelSelector string
+	// ContainerName is the container that writes alerts to stdout. Defaults to DefaultAlertsContainerName.
+	ContainerName string
+	// Since also delivers alerts that were emitted within this duration before subscribing.
+	// If zero, only new alerts are delivered.
+	Since time.Duration
+}
+
+// SubscribeAlerts streams the alerts that the alert forwarder emits.
+// The alert forwarder pods are resolved once; the returned channels are closed
+// when the context is cancelled or when all streams ended (e.g., because the pods were restarted).
+// Errors that occur while streaming are sent on the error channel, which must be drained.
+func SubscribeAlerts(ctx context.Context, clientset kubernetes.Interface, opts AlertStreamOptions) (<-chan alerts.KoneyAlert, <-chan error, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultKoneyNamespace
+	}
+	if opts.LabelSelector == "" {
+		opts.LabelSelector = DefaultAlertsPodLabelSelector
+	}
+	if opts.ContainerName == "" {
+		opts.ContainerName = DefaultAlertsContainerName
+	}
+
+	pods, err := clientset.CoreV1().Pods(opts.Namespace).List(ctx, metav1.ListOptions{LabelSelector: opts.LabelSelector})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logOptions := &corev1.PodLogOptions{Container: opts.ContainerName, Follow: true}
+	if opts.Since > 0 {
+		sinceSeconds := int64(opts.Since.Seconds())
+		logOptions.SinceSeconds = &sinceSeconds
+	} else {
+		tailLines := int64(0)
+		logOptions.TailLines = &tailLines
+	}
+
+	alertsChan := make(chan alerts.KoneyAlert)
+	errorsChan := make(chan error)
+
+	var wg sync.WaitGroup
+	for _, pod := range pods.Items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			stream, err := clientset.CoreV1().Pods(opts.Namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+			if err != nil {
+				sendOrDone(ctx, errorsChan, err)
+				return
+			}
+			defer func() { _ = stream.Close() }()
+
+			if err := DecodeAlerts(ctx, stream, alertsChan); err != nil && ctx.Err() == nil {
+				sendOrDone(ctx, errorsChan, err)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(alertsChan)
+		close(errorsChan)
+	}()
+
+	return alertsChan, errorsChan, nil
+}
+
+// DecodeAlerts reads the output of the alert forwarder line by line and sends every alert to the channel.
+// Lines that are not alerts (e.g., log messages) are skipped. It returns when the reader is exhausted
+// or when the context is cancelled.
+func DecodeAlerts(ctx context.Context, r io.Reader, out chan<- alerts.KoneyAlert) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var alert alerts.KoneyAlert
+		if err := json.Unmarshal([]byte(line), &alert); err != nil || alert.Timestamp == "" {
+			continue
+		}
+
+		if !sendOrDone(ctx, out, alert) {
+			return ctx.Err()
+		}
+	}
+
+	return scanner.Err()
+}
+
+// sendOrDone sends the value to the channel, unless the context is cancelled first.
+func sendOrDone[T any](ctx context.Context, ch chan<- T, value T) bool {
+	select {
+	case ch <- value:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
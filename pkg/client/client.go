@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+// Package client provides typed access to Koney resources and alerts,
+// so that other controllers and tools can build on Koney programmatically.
+package client
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// DefaultKoneyNamespace is the namespace where Koney is installed by default.
+const DefaultKoneyNamespace = "koney-system"
+
+// NewScheme returns a scheme with the Kubernetes built-in types and the Koney types registered.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	return scheme, nil
+}
+
+// Client provides typed access to Koney resources.
+type Client struct {
+	client ctrlclient.WithWatch
+}
+
+// New creates a Client for the cluster of the given config.
+func New(config *rest.Config) (*Client, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := ctrlclient.NewWithWatch(config, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewForClient(c), nil
+}
+
+// NewForClient creates a Client from an existing controller-runtime client.
+// The scheme of the client must have the Koney types registered (see NewScheme).
+func NewForClient(c ctrlclient.WithWatch) *Client {
+	return &Client{client: c}
+}
+
+// DeceptionPolicies returns an interface to DeceptionPolicy resources, which are cluster-scoped.
+func (c *Client) DeceptionPolicies() *DeceptionPolicyClient {
+	return &DeceptionPolicyClient{client: c.client}
+}
+
+// DeceptionAlertSinks returns an interface to DeceptionAlertSink resources in the given namespace.
+// Koney only reads alert sinks from its own namespace (see DefaultKoneyNamespace).
+func (c *Client) DeceptionAlertSinks(namespace string) *DeceptionAlertSinkClient {
+	return &DeceptionAlertSinkClient{client: c.client, namespace: namespace}
+}
+
+// DeceptionAlerts returns an interface to DeceptionAlert resources in the given namespace.
+// The alert forwarder records alerts in the Koney namespace (see DefaultKoneyNamespace).
+func (c *Client) DeceptionAlerts(namespace string) *DeceptionAlertClient {
+	return &DeceptionAlertClient{client: c.client, namespace: namespace}
+}
+
+// DeceptionPolicyClient provides typed access to DeceptionPolicy resources.
+type DeceptionPolicyClient struct {
+	client ctrlclient.WithWatch
+}
+
+// Get returns the DeceptionPolicy with the given name.
+func (c *DeceptionPolicyClient) Get(ctx context.Context, name string) (*v1alpha1.DeceptionPolicy, error) {
+	deceptionPolicy := &v1alpha1.DeceptionPolicy{}
+	if err := c.client.Get(ctx, ctrlclient.ObjectKey{Name: name}, deceptionPolicy); err != nil {
+		return nil, err
+	}
+	return deceptionPolicy, nil
+}
+
+// List returns all DeceptionPolicy resources that match the given options.
+func (c *DeceptionPolicyClient) List(ctx context.Context, opts ...ctrlclient.ListOption) (*v1alpha1.DeceptionPolicyList, error) {
+	deceptionPolicies := &v1alpha1.DeceptionPolicyList{}
+	if err := c.client.List(ctx, deceptionPolicies, opts...); err != nil {
+		return nil, err
+	}
+	return deceptionPolicies, nil
+}
+
+// Create creates the given DeceptionPolicy.
+func (c *DeceptionPolicyClient) Create(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, opts ...ctrlclient.CreateOption) error {
+	return c.client.Create(ctx, deceptionPolicy, opts...)
+}
+
+// Update updates the spec of the given DeceptionPolicy.
+func (c *DeceptionPolicyClient) Update(ctx context.Context, deceptionPolicy *v1alpha1.DeceptionPolicy, opts ...ctrlclient.UpdateOption) error {
+	return c.client.Update(ctx, deceptionPolicy, opts...)
+}
+
+// Delete deletes the DeceptionPolicy with the given name. Koney removes its traps before the resource is gone.
+func (c *DeceptionPolicyClient) Delete(ctx context.Context, name string, opts ...ctrlclient.DeleteOption) error {
+	deceptionPolicy := &v1alpha1.DeceptionPolicy{}
+	deceptionPolicy.Name = name
+	return c.client.Delete(ctx, deceptionPolicy, opts...)
+}
+
+// Watch watches DeceptionPolicy resources that match the given options.
+func (c *DeceptionPolicyClient) Watch(ctx context.Context, opts ...ctrlclient.ListOption) (watch.Interface, error) {
+	return c.client.Watch(ctx, &v1alpha1.DeceptionPolicyList{}, opts...)
+}
+
+// DeceptionAlertSinkClient provides typed access to DeceptionAlertSink resources in a namespace.
+type DeceptionAlertSinkClient struct {
+	client    ctrlclient.WithWatch
+	namespace string
+}
+
+// Get returns the DeceptionAlertSink with the given name.
+func (c *DeceptionAlertSinkClient) Get(ctx context.Context, name string) (*v1alpha1.DeceptionAlertSink, error) {
+	alertSink := &v1alpha1.DeceptionAlertSink{}
+	if err := c.client.Get(ctx, ctrlclient.ObjectKey{Namespace: c.namespace, Name: name}, alertSink); err != nil {
+		return nil, err
+	}
+	return alertSink, nil
+}
+
+// List returns all DeceptionAlertSink resources in the namespace that match the given options.
+func (c *DeceptionAlertSinkClient) List(ctx context.Context, opts ...ctrlclient.ListOption) (*v1alpha1.DeceptionAlertSinkList, error) {
+	alertSinks := &v1alpha1.DeceptionAlertSinkList{}
+	if err := c.client.List(ctx, alertSinks, append(opts, ctrlclient.InNamespace(c.namespace))...); err != nil {
+		return nil, err
+	}
+	return alertSinks, nil
+}
+
+// Create creates the given DeceptionAlertSink in the namespace.
+func (c *DeceptionAlertSinkClient) Create(ctx context.Context, alertSink *v1alpha1.DeceptionAlertSink, opts ...ctrlclient.CreateOption) error {
+	alertSink.Namespace = c.namespace
+	return c.client.Create(ctx, alertSink, opts...)
+}
+
+// Update updates the given DeceptionAlertSink.
+func (c *DeceptionAlertSinkClient) Update(ctx context.Context, alertSink *v1alpha1.DeceptionAlertSink, opts ...ctrlclient.UpdateOption) error {
+	return c.client.Update(ctx, alertSink, opts...)
+}
+
+// Delete deletes the DeceptionAlertSink with the given name.
+func (c *DeceptionAlertSinkClient) Delete(ctx context.Context, name string, opts ...ctrlclient.DeleteOption) error {
+	alertSink := &v1alpha1.DeceptionAlertSink{}
+	alertSink.Namespace = c.namespace
+	alertSink.Name = name
+	return c.client.Delete(ctx, alertSink, opts...)
+}
+
+// Watch watches DeceptionAlertSink resources in the namespace that match the given options.
+func (c *DeceptionAlertSinkClient) Watch(ctx context.Context, opts ...ctrlclient.ListOption) (watch.Interface, error) {
+	return c.client.Watch(ctx, &v1alpha1.DeceptionAlertSinkList{}, append(opts, ctrlclient.InNamespace(c.namespace))...)
+}
+
+// DeceptionAlertClient provides typed access to DeceptionAlert resources in a namespace.
+type DeceptionAlertClient struct {
+	client    ctrlclient.WithWatch
+	namespace string
+}
+
+// Get returns the DeceptionAlert with the given name.
+func (c *DeceptionAlertClient) Get(ctx context.Context, name string) (*v1alpha1.DeceptionAlert, error) {
+	alert := &v1alpha1.DeceptionAlert{}
+	if err := c.client.Get(ctx, ctrlclient.ObjectKey{Namespace: c.namespace, Name: name}, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// List returns all DeceptionAlert resources in the namespace that match the given options.
+func (c *DeceptionAlertClient) List(ctx context.Context, opts ...ctrlclient.ListOption) (*v1alpha1.DeceptionAlertList, error) {
+	alerts := &v1alpha1.DeceptionAlertList{}
+	if err := c.client.List(ctx, alerts, append(opts, ctrlclient.InNamespace(c.namespace))...); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Delete deletes the DeceptionAlert with the given name. Koney also deletes alerts after their retention period.
+func (c *DeceptionAlertClient) Delete(ctx context.Context, name string, opts ...ctrlclient.DeleteOption) error {
+	alert := &v1alpha1.DeceptionAlert{}
+	alert.Namespace = c.namespace
+	alert.Name = name
+	return c.client.Delete(ctx, alert, opts...)
+}
+
+// Watch watches DeceptionAlert resources in the namespace that match the given options.
+func (c *DeceptionAlertClient) Watch(ctx context.Context, opts ...ctrlclient.ListOption) (watch.Interface, error) {
+	return c.client.Watch(ctx, &v1alpha1.DeceptionAlertList{}, append(opts, ctrlclient.InNamespace(c.namespace))...)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package client
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+	"github.com/dynatrace-oss/koney/pkg/alerts"
+)
+
+var _ = Describe("Client", func() {
+	var c *Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme, err := NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		c = NewForClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+	})
+
+	It("should manage deception policies", func() {
+		deceptionPolicy := &v1alpha1.DeceptionPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test-policy"}}
+		Expect(c.DeceptionPolicies().Create(ctx, deceptionPolicy)).To(Succeed())
+
+		fetched, err := c.DeceptionPolicies().Get(ctx, "test-policy")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fetched.Name).To(Equal("test-policy"))
+
+		list, err := c.DeceptionPolicies().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+
+		Expect(c.DeceptionPolicies().Delete(ctx, "test-policy")).To(Succeed())
+		_, err = c.DeceptionPolicies().Get(ctx, "test-policy")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should manage alert sinks in a single namespace", func() {
+		alertSink := &v1alpha1.DeceptionAlertSink{ObjectMeta: metav1.ObjectMeta{Name: "test-sink"}}
+		Expect(c.DeceptionAlertSinks(DefaultKoneyNamespace).Create(ctx, alertSink)).To(Succeed())
+		Expect(alertSink.Namespace).To(Equal(DefaultKoneyNamespace))
+
+		list, err := c.DeceptionAlertSinks(DefaultKoneyNamespace).List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+
+		list, err = c.DeceptionAlertSinks("other-namespace").List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(BeEmpty())
+	})
+
+	It("should read and delete recorded alerts", func() {
+		alert := &v1alpha1.DeceptionAlert{ObjectMeta: metav1.ObjectMeta{Name: "alert-1", Namespace: DefaultKoneyNamespace}}
+		Expect(c.client.Create(ctx, alert)).To(Succeed())
+
+		got, err := c.DeceptionAlerts(DefaultKoneyNamespace).Get(ctx, "alert-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Name).To(Equal("alert-1"))
+
+		list, err := c.DeceptionAlerts("other-namespace").List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(BeEmpty())
+
+		Expect(c.DeceptionAlerts(DefaultKoneyNamespace).Delete(ctx, "alert-1")).To(Succeed())
+		_, err = c.DeceptionAlerts(DefaultKoneyNamespace).Get(ctx, "alert-1")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("DecodeAlerts", func() {
+	It("should decode alerts and skip other lines", func() {
+		output := strings.Join([]string{
+			`Transforming 2 alerts for policy koney-tracing-policy-abc`,
+			`{"timestamp": "2025-01-03T18:47:56Z", "trap_type": "filesystem_honeytoken", "metadata": {"file_path": "/a"}}`,
+			`{"not": "an alert"}`,
+			`{"timestamp": "2025-01-03T18:47:57Z", "trap_type": "filesystem_honeytoken", "metadata": {"file_path": "/b"}}`,
+		}, "\n")
+
+		out := make(chan alerts.KoneyAlert, 10)
+		Expect(DecodeAlerts(context.Background(), strings.NewReader(output), out)).To(Succeed())
+		close(out)
+
+		var filePaths []string
+		for alert := range out {
+			filePath, _ := alert.FilePath()
+			filePaths = append(filePaths, filePath)
+		}
+		Expect(filePaths).To(Equal([]string{"/a", "/b"}))
+	})
+
+	It("should stop when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		out := make(chan alerts.KoneyAlert)
+		err := DecodeAlerts(ctx, strings.NewReader(`{"timestamp": "2025-01-03T18:47:56Z"}`), out)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Dynatrace LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+package client
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dynatrace-oss/koney/api/v1alpha1"
+)
+
+// Informers provides shared informers for Koney resources, backed by a controller-runtime cache.
+type Informers struct {
+	cache cache.Cache
+}
+
+// NewInformers creates informers for the cluster of the given config.
+// Alert sinks and recorded alerts are only watched in the Koney namespace. Call Start to start the informers.
+func NewInformers(config *rest.Config, koneyNamespace string, resync time.Duration) (*Informers, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cache.New(config, cache.Options{
+		Scheme:     scheme,
+		SyncPeriod: &resync,
+		ByObject: map[ctrlclient.Object]cache.ByObject{
+			&v1alpha1.DeceptionAlertSink{}: {Namespaces: map[string]cache.Config{koneyNamespace: {}}},
+			&v1alpha1.DeceptionAlert{}:     {Namespaces: map[string]cache.Config{koneyNamespace: {}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Informers{cache: c}, nil
+}
+
+// Start starts the informers and blocks until the context is cancelled.
+func (i *Informers) Start(ctx context.Context) error {
+	return i.cache.Start(ctx)
+}
+
+// WaitForCacheSync waits until all informers are synced. It returns false if the context is cancelled first.
+func (i *Informers) WaitForCacheSync(ctx context.Context) bool {
+	return i.cache.WaitForCacheSync(ctx)
+}
+
+// Reader returns a reader that serves resources from the informers' cache.
+func (i *Informers) Reader() ctrlclient.Reader {
+	return i.cache
+}
+
+// DeceptionPolicies returns the shared informer for DeceptionPolicy resources.
+func (i *Informers) DeceptionPolicies(ctx context.Context) (cache.Informer, error) {
+	return i.cache.GetInformer(ctx, &v1alpha1.DeceptionPolicy{})
+}
+
+// DeceptionAlertSinks returns the shared informer for DeceptionAlertSink resources.
+func (i *Informers) DeceptionAlertSinks(ctx context.Context) (cache.Informer, error) {
+	return i.cache.GetInformer(ctx, &v1alpha1.DeceptionAlertSink{})
+}
+
+// DeceptionAlerts returns the shared informer for DeceptionAlert resources.
+func (i *Informers) DeceptionAlerts(ctx context.Context) (cache.Informer, error) {
+	return i.cache.GetInformer(ctx, &v1alpha1.DeceptionAlert{})
+}
+
+// OnDeceptionPolicy registers typed handlers for DeceptionPolicy events. Handlers that are nil are not called.
+func (i *Informers) OnDeceptionPolicy(ctx context.Context, onAdd, onDelete func(*v1alpha1.DeceptionPolicy),
+	onUpdate func(oldObj, newObj *v1alpha1.DeceptionPolicy)) error {
+	informer, err := i.DeceptionPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			if deceptionPolicy, ok := obj.(*v1alpha1.DeceptionPolicy); ok && onAdd != nil {
+				onAdd(deceptionPolicy)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldPolicy, oldOk := oldObj.(*v1alpha1.DeceptionPolicy)
+			newPolicy, newOk := newObj.(*v1alpha1.DeceptionPolicy)
+			if oldOk && newOk && onUpdate != nil {
+				onUpdate(oldPolicy, newPolicy)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if deceptionPolicy, ok := obj.(*v1alpha1.DeceptionPolicy); ok && onDelete != nil {
+				onDelete(deceptionPolicy)
+			}
+		},
+	})
+
+	return err
+}